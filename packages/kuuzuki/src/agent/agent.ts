@@ -5,6 +5,9 @@ import { Provider } from "../provider/provider"
 import { generateObject, type ModelMessage } from "ai"
 import PROMPT_GENERATE from "./generate.txt"
 import { SystemPrompt } from "../session/system"
+import { Global } from "../global"
+import path from "path"
+import matter from "gray-matter"
 
 export namespace Agent {
   export const Info = z
@@ -17,6 +20,7 @@ export namespace Agent {
         })
         .optional(),
       description: z.string(),
+      mode: z.enum(["subagent", "primary", "all"]),
       prompt: z.string().optional(),
       tools: z.record(z.boolean()),
     })
@@ -29,6 +33,7 @@ export namespace Agent {
     const result: Record<string, Info> = {
       general: {
         name: "general",
+        mode: "subagent",
         description:
           "General-purpose agent for researching complex questions, searching for code, and executing multi-step tasks. When you are searching for a keyword or file and are not confident that you will find the right match in the first few tries use this agent to perform the search for you.",
         tools: {
@@ -45,14 +50,16 @@ export namespace Agent {
         prompt?: string
         tools?: Record<string, boolean>
         description?: string
+        mode?: Info["mode"]
       }
-      
+
       if (agentValue.disable) continue
       let item = result[key]
       if (!item)
         item = result[key] = {
           name: key,
           description: "",
+          mode: "all",
           tools: {
             todowrite: false,
             todoread: false,
@@ -67,6 +74,7 @@ export namespace Agent {
           ...agentValue.tools,
         }
       if (agentValue.description) item.description = agentValue.description
+      if (agentValue.mode) item.mode = agentValue.mode
     }
     return result
   })
@@ -79,6 +87,51 @@ export namespace Agent {
     return state().then((x) => Object.values(x))
   }
 
+  export const Input = z
+    .object({
+      name: z.string().regex(/^[a-z0-9][a-z0-9_-]*$/, "lowercase letters, digits, - and _ only"),
+      description: z.string().min(1),
+      prompt: z.string().optional(),
+      model: z.string().optional().describe("Default model as provider/model"),
+      mode: Info.shape.mode.optional(),
+      tools: z.record(z.boolean()).optional(),
+      scope: z.enum(["global", "project"]).optional(),
+    })
+    .openapi({
+      ref: "AgentInput",
+    })
+  export type Input = z.infer<typeof Input>
+
+  function file(name: string, scope: "global" | "project") {
+    const root = scope === "global" ? Global.Path.config : path.join(App.info().path.root, ".kuuzuki")
+    return path.join(root, "agent", `${name}.md`)
+  }
+
+  /**
+   * Writes an agent as markdown, the same format `kuuzuki agent create`
+   * produces, and reloads the agents so the change applies immediately
+   */
+  export async function save(input: Input) {
+    let scope = input.scope
+    if (!scope) {
+      const project = Bun.file(file(input.name, "project"))
+      scope = App.info().git && (await project.exists()) ? "project" : "global"
+    }
+    const frontmatter: Record<string, unknown> = {
+      description: input.description,
+      mode: input.mode ?? "all",
+    }
+    if (input.model) frontmatter.model = input.model
+    // every override is kept, enabling a tool matters for the ones custom
+    // agents start without
+    if (input.tools && Object.keys(input.tools).length > 0) frontmatter.tools = input.tools
+    await Bun.write(file(input.name, scope), matter.stringify(input.prompt ?? "", frontmatter))
+    App.reset("config")
+    App.reset("agent")
+    App.reset("mode")
+    return get(input.name)
+  }
+
   export async function generate(input: { description: string }) {
     const defaultModel = await Provider.defaultModel()
     const model = await Provider.getModel(defaultModel.providerID, defaultModel.modelID)
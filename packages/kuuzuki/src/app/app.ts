@@ -136,6 +136,13 @@ export namespace App {
     };
   }
 
+  /**
+   * Drops a service's state so the next use initializes it again
+   */
+  export function reset(key: any) {
+    ctx.use().services.delete(key)
+  }
+
   export function info() {
     return ctx.use().info
   }
@@ -2,6 +2,7 @@ import { App } from "../app/app";
 import { ConfigHooks } from "../config/hooks";
 import { Format } from "../format";
 import { LSP } from "../lsp";
+import { PermissionNotify } from "../permission/notify";
 import { Plugin } from "../plugin";
 import { Share } from "../share/share";
 import { Snapshot } from "../snapshot";
@@ -18,6 +19,7 @@ export async function bootstrap<T>(
     LSP.init();
     Snapshot.init();
     Plugin.init();
+    PermissionNotify.init();
     Session.initializeSystem(); // Clean up stale session locks
 
     return cb(app);
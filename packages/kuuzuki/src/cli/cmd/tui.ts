@@ -4,7 +4,9 @@ import { bootstrap } from "../bootstrap";
 import { UI } from "../ui";
 import { cmd } from "./cmd";
 import path from "path";
+import os from "os";
 import fs from "fs/promises";
+import { existsSync } from "fs";
 import { spawn } from "child_process";
 import { Installation } from "../../installation";
 import { Config } from "../../config/config";
@@ -109,6 +111,39 @@ export const TuiCommand = cmd({
         alias: ["s"],
         type: "string",
         describe: "session ID to resume",
+      })
+      .option("projects", {
+        type: "boolean",
+        describe: "start on the recent projects launcher",
+      })
+      .option("readonly", {
+        type: "boolean",
+        describe: "observe sessions without sending prompts, tool requests are rejected",
+      })
+      .option("record", {
+        type: "string",
+        describe: "record the events and keystrokes of the session to a file",
+      })
+      .option("replay", {
+        type: "string",
+        describe: "play a recording back, read-only",
+      })
+      .option("speed", {
+        type: "number",
+        describe: "speed of --replay, 2 plays twice as fast",
+      })
+      .option("doctor", {
+        type: "boolean",
+        describe: "check the terminal, server, git and editor, print a report and exit",
+      })
+      .option("fancy", {
+        type: "boolean",
+        default: true,
+        describe: "unicode borders in truecolor, --no-fancy draws ASCII borders in 16 colors",
+      })
+      .option("screen-reader", {
+        type: "boolean",
+        describe: "print the conversation as plain lines for screen readers, without spinners or overlays",
       }),
   handler: async (args) => {
     // Enable debug logging if requested
@@ -116,7 +151,11 @@ export const TuiCommand = cmd({
       process.env.KUUZUKI_DEBUG = "true";
     }
 
+    // The TUI writes the project picked in its launcher here before exiting
+    const projectFile = path.join(os.tmpdir(), `kuuzuki-project-${process.pid}.json`);
+
     while (true) {
+      await fs.rm(projectFile, { force: true });
       const cwd = args.project ? path.resolve(args.project) : process.cwd();
       try {
         process.chdir(cwd);
@@ -368,6 +407,14 @@ export const TuiCommand = cmd({
               ...(args.mode ? ["--mode", args.mode] : []),
               ...(args.command ? ["--command", args.command] : []),
               ...(sessionID ? ["--session", sessionID] : []),
+              ...(args.projects ? ["--projects"] : []),
+              ...(args.readonly ? ["--readonly"] : []),
+              ...(args.record ? ["--record", path.resolve(args.record)] : []),
+              ...(args.replay ? ["--replay", path.resolve(args.replay)] : []),
+              ...(args.speed ? ["--speed", String(args.speed)] : []),
+              ...(args.doctor ? ["--doctor"] : []),
+              ...(args.fancy === false ? ["--no-fancy"] : []),
+              ...(args.screenReader ? ["--screen-reader"] : []),
             ]);
 
           proc = spawn(cmd[0], tuiArgs, {
@@ -379,6 +426,7 @@ export const TuiCommand = cmd({
               KUUZUKI_SERVER: serverUrl,
               KUUZUKI_APP_INFO: JSON.stringify(app),
               KUUZUKI_MODES: JSON.stringify(await Mode.list()),
+              KUUZUKI_PROJECT_FILE: projectFile,
             },
           });
 
@@ -396,6 +444,8 @@ export const TuiCommand = cmd({
               });
             }
             server.stop();
+            // Switching projects, the loop below restarts in the new project
+            if (existsSync(projectFile)) return;
             // Force exit to prevent terminal lock
             process.exit(exitCode || 0);
           });
@@ -519,9 +569,43 @@ export const TuiCommand = cmd({
           clearServerInfo(),
         );
 
+        const next = await fs
+          .readFile(projectFile, "utf8")
+          .then((text) => JSON.parse(text) as { path: string; session?: string; restart?: boolean })
+          .catch(() => undefined);
+        if (next?.path) {
+          args.project = next.path;
+          args.session = next.session;
+          // Restarting after an update, a new kuuzuki has to run it
+          if (next.restart) return "restart";
+          args.continue = false;
+          args.prompt = undefined;
+          args.command = undefined;
+          args.projects = false;
+          return "switch";
+        }
+
         return "done";
       });
       if (result === "done") break;
+      if (result === "restart") {
+        const restarted = (await spawnAsync(
+          getOpencodeCommand()[0],
+          [
+            ...getOpencodeCommand().slice(1),
+            "tui",
+            args.project!,
+            ...(args.session ? ["--session", args.session] : []),
+          ],
+          {
+            cwd: args.project,
+            stdout: "inherit",
+            stderr: "inherit",
+            stdin: "inherit",
+          },
+        )) as any;
+        process.exit(restarted.exitCode ?? 0);
+      }
       if (result === "needs_provider") {
         UI.empty();
         UI.println(UI.logo("   "));
@@ -308,6 +308,26 @@ export namespace Config {
         .optional()
         .default("<leader>h")
         .describe("Show help dialog"),
+      command_repeat: z
+        .string()
+        .optional()
+        .default("<leader>.")
+        .describe("Repeat the last command"),
+      command_history: z
+        .string()
+        .optional()
+        .default("<leader>,")
+        .describe("List recent commands"),
+      toast_action: z
+        .string()
+        .optional()
+        .default("<leader>A")
+        .describe("Run the action of the latest toast"),
+      notification_list: z
+        .string()
+        .optional()
+        .default("<leader>N")
+        .describe("List recent notifications"),
       switch_mode: z.string().optional().default("tab").describe("Next mode"),
       switch_mode_reverse: z
         .string()
@@ -324,6 +344,11 @@ export namespace Config {
         .optional()
         .default("<leader>x")
         .describe("Export session to editor"),
+      sandbox_finish: z
+        .string()
+        .optional()
+        .default("<leader>z")
+        .describe("Merge back or discard the sandbox branch"),
       session_new: z
         .string()
         .optional()
@@ -359,6 +384,11 @@ export namespace Config {
         .optional()
         .default("<leader>d")
         .describe("Toggle tool details"),
+      tool_output_attach: z
+        .string()
+        .optional()
+        .default("<leader>b")
+        .describe("Attach the last tool output to the prompt"),
       model_list: z
         .string()
         .optional()
@@ -385,6 +415,121 @@ export namespace Config {
         .optional()
         .default("<leader>v")
         .describe("Split/unified diff"),
+      file_focus: z
+        .string()
+        .optional()
+        .default("<leader>w")
+        .describe("Move focus between the messages and file panes"),
+      file_grow: z
+        .string()
+        .optional()
+        .default("<leader>]")
+        .describe("Widen the file pane"),
+      file_shrink: z
+        .string()
+        .optional()
+        .default("<leader>[")
+        .describe("Narrow the file pane"),
+      file_line_up: z
+        .string()
+        .optional()
+        .default("up,k")
+        .describe("Scroll the focused file up by one line"),
+      file_line_down: z
+        .string()
+        .optional()
+        .default("down,j")
+        .describe("Scroll the focused file down by one line"),
+      file_page_up: z
+        .string()
+        .optional()
+        .default("pgup,b")
+        .describe("Scroll the focused file up by one page"),
+      file_page_down: z
+        .string()
+        .optional()
+        .default("pgdown,f,space")
+        .describe("Scroll the focused file down by one page"),
+      file_half_page_up: z
+        .string()
+        .optional()
+        .default("ctrl+u,u")
+        .describe("Scroll the focused file up by half page"),
+      file_half_page_down: z
+        .string()
+        .optional()
+        .default("ctrl+d,d")
+        .describe("Scroll the focused file down by half page"),
+      file_top: z
+        .string()
+        .optional()
+        .default("home,g")
+        .describe("Scroll to the top of the focused file"),
+      file_bottom: z
+        .string()
+        .optional()
+        .default("end,G")
+        .describe("Scroll to the bottom of the focused file"),
+      file_hunk_next: z
+        .string()
+        .optional()
+        .default("]")
+        .describe("Select the next hunk in the file viewer"),
+      file_hunk_previous: z
+        .string()
+        .optional()
+        .default("[")
+        .describe("Select the previous hunk in the file viewer"),
+      file_hunk_stage: z
+        .string()
+        .optional()
+        .default("s")
+        .describe("Stage or unstage the selected hunk in the file viewer"),
+      file_definition: z
+        .string()
+        .optional()
+        .default("enter")
+        .describe("Go to the definition of the clicked name in the file viewer"),
+      file_find: z
+        .string()
+        .optional()
+        .default("/")
+        .describe("Search the text of the file"),
+      file_find_next: z
+        .string()
+        .optional()
+        .default("n")
+        .describe("Next match in the file"),
+      file_find_previous: z
+        .string()
+        .optional()
+        .default("N")
+        .describe("Previous match in the file"),
+      file_goto_line: z
+        .string()
+        .optional()
+        .default(":")
+        .describe("Go to a line of the file"),
+      file_back: z
+        .string()
+        .optional()
+        .default("left,h")
+        .describe("Open the file viewed before"),
+      file_forward: z
+        .string()
+        .optional()
+        .default("right,l")
+        .describe("Open the file viewed after going back"),
+      file_viewed: z
+        .string()
+        .optional()
+        .default("tab")
+        .describe("List the files viewed recently"),
+      file_edit: z
+        .string()
+        .optional()
+        .default("e")
+        .describe("Open the file in the IDE or EDITOR at the line in view"),
       project_init: z
         .string()
         .optional()
@@ -400,6 +545,16 @@ export namespace Config {
         .optional()
         .default("ctrl+v")
         .describe("Paste from clipboard"),
+      input_open_attachment: z
+        .string()
+        .optional()
+        .default("ctrl+o")
+        .describe("Open the attachment under the cursor in the file viewer"),
+      input_preview: z
+        .string()
+        .optional()
+        .default("alt+o")
+        .describe("Preview the attachment at the cursor"),
       input_submit: z
         .string()
         .optional()
@@ -450,6 +605,26 @@ export namespace Config {
         .optional()
         .default("ctrl+alt+g")
         .describe("Navigate to last message"),
+      messages_line_up: z
+        .string()
+        .optional()
+        .default("up,k")
+        .describe("Scroll focused messages up by one line"),
+      messages_line_down: z
+        .string()
+        .optional()
+        .default("down,j")
+        .describe("Scroll focused messages down by one line"),
+      messages_top: z
+        .string()
+        .optional()
+        .default("home,g")
+        .describe("Scroll focused messages to the top"),
+      messages_bottom: z
+        .string()
+        .optional()
+        .default("end,G")
+        .describe("Scroll focused messages to the bottom"),
       messages_layout_toggle: z
         .string()
         .optional()
@@ -475,6 +650,31 @@ export namespace Config {
         .optional()
         .default("<leader>r")
         .describe("Redo message"),
+      messages_retry: z
+        .string()
+        .optional()
+        .default("<leader>g")
+        .describe("Retry last message"),
+      messages_scroll_lock: z
+        .string()
+        .optional()
+        .default("<leader>k")
+        .describe("Toggle following new output"),
+      scratchpad_toggle: z
+        .string()
+        .optional()
+        .default("<leader>o")
+        .describe("Toggle scratchpad"),
+      shell_output: z
+        .string()
+        .optional()
+        .default("<leader>!")
+        .describe("Show the output of the last shell command"),
+      shell_mode: z
+        .string()
+        .optional()
+        .default("<leader>$")
+        .describe("Toggle shell mode"),
       app_exit: z
         .string()
         .optional()
@@ -542,6 +742,12 @@ export namespace Config {
           "Small model to use for tasks like summarization and title generation in the format of provider/model",
         )
         .optional(),
+      transcription_model: z
+        .string()
+        .describe(
+          "Model to transcribe audio attachments with in the format of provider/model, eg openai/whisper-1",
+        )
+        .optional(),
       username: z
         .string()
         .optional()
@@ -617,6 +823,9 @@ export namespace Config {
             .optional(),
         })
         .optional(),
+      tui: ConfigSchema.TuiConfig.optional().describe(
+        "Terminal UI configuration",
+      ),
     })
     .strict()
     .openapi({
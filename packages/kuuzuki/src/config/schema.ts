@@ -51,10 +51,15 @@ export namespace ConfigSchema {
     keybinds: {
       leader: "ctrl+x",
       app_help: "<leader>h",
+      command_repeat: "<leader>.",
+      command_history: "<leader>,",
+      toast_action: "<leader>A",
+      notification_list: "<leader>N",
       switch_mode: "tab",
       switch_mode_reverse: "shift+tab",
       editor_open: "<leader>e",
       session_export: "<leader>x",
+      sandbox_finish: "<leader>z",
       session_new: "<leader>n",
       session_list: "<leader>l",
       session_share: "<leader>s",
@@ -62,15 +67,42 @@ export namespace ConfigSchema {
       session_interrupt: "esc",
       session_compact: "<leader>c",
       tool_details: "<leader>d",
+      tool_output_attach: "<leader>b",
       model_list: "<leader>m",
       theme_list: "<leader>t",
       file_list: "<leader>f",
+      file_tree: "<leader>f",
       file_close: "esc",
       file_search: "<leader>/",
       file_diff_toggle: "<leader>v",
+      file_focus: "<leader>w",
+      file_grow: "<leader>]",
+      file_shrink: "<leader>[",
+      file_line_up: "up,k",
+      file_line_down: "down,j",
+      file_page_up: "pgup,b",
+      file_page_down: "pgdown,f,space",
+      file_half_page_up: "ctrl+u,u",
+      file_half_page_down: "ctrl+d,d",
+      file_top: "home,g",
+      file_bottom: "end,G",
+      file_hunk_next: "]",
+      file_hunk_previous: "[",
+      file_hunk_stage: "s",
+      file_definition: "enter",
+      file_find: "/",
+      file_find_next: "n",
+      file_find_previous: "N",
+      file_goto_line: ":",
+      file_back: "left,h",
+      file_forward: "right,l",
+      file_viewed: "tab",
+      file_edit: "e",
       project_init: "<leader>i",
       input_clear: "ctrl+c",
       input_paste: "ctrl+v",
+      input_open_attachment: "ctrl+o",
+      input_preview: "alt+o",
       input_submit: "enter",
       input_newline: "shift+enter,ctrl+j",
       messages_page_up: "pgup",
@@ -81,10 +113,19 @@ export namespace ConfigSchema {
       messages_next: "ctrl+down",
       messages_first: "ctrl+g",
       messages_last: "ctrl+alt+g",
+      messages_line_up: "up,k",
+      messages_line_down: "down,j",
+      messages_top: "home,g",
+      messages_bottom: "end,G",
       messages_layout_toggle: "<leader>p",
       messages_copy: "<leader>y",
       messages_undo: "<leader>u",
       messages_redo: "<leader>r",
+      messages_retry: "<leader>g",
+      messages_scroll_lock: "<leader>k",
+      scratchpad_toggle: "<leader>o",
+      shell_output: "<leader>!",
+      shell_mode: "<leader>$",
       app_exit: "ctrl+c,<leader>q",
     },
     layout: "stretch" as const,
@@ -220,6 +261,22 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.app_help)
         .describe("Show help dialog"),
+      command_repeat: z
+        .string()
+        .default(DEFAULTS.keybinds.command_repeat)
+        .describe("Repeat the last command"),
+      command_history: z
+        .string()
+        .default(DEFAULTS.keybinds.command_history)
+        .describe("List recent commands"),
+      toast_action: z
+        .string()
+        .default(DEFAULTS.keybinds.toast_action)
+        .describe("Run the action of the latest toast"),
+      notification_list: z
+        .string()
+        .default(DEFAULTS.keybinds.notification_list)
+        .describe("List recent notifications"),
       switch_mode: z
         .string()
         .default(DEFAULTS.keybinds.switch_mode)
@@ -236,6 +293,10 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.session_export)
         .describe("Export session to editor"),
+      sandbox_finish: z
+        .string()
+        .default(DEFAULTS.keybinds.sandbox_finish)
+        .describe("Merge back or discard the sandbox branch"),
       session_new: z
         .string()
         .default(DEFAULTS.keybinds.session_new)
@@ -264,6 +325,10 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.tool_details)
         .describe("Toggle tool details"),
+      tool_output_attach: z
+        .string()
+        .default(DEFAULTS.keybinds.tool_output_attach)
+        .describe("Attach the last tool output to the prompt"),
       model_list: z
         .string()
         .default(DEFAULTS.keybinds.model_list)
@@ -276,6 +341,10 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.file_list)
         .describe("List files"),
+      file_tree: z
+        .string()
+        .default(DEFAULTS.keybinds.file_tree)
+        .describe("Browse the file tree"),
       file_close: z
         .string()
         .default(DEFAULTS.keybinds.file_close)
@@ -288,6 +357,98 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.file_diff_toggle)
         .describe("Split/unified diff"),
+      file_focus: z
+        .string()
+        .default(DEFAULTS.keybinds.file_focus)
+        .describe("Move focus between the messages and file panes"),
+      file_grow: z
+        .string()
+        .default(DEFAULTS.keybinds.file_grow)
+        .describe("Widen the file pane"),
+      file_shrink: z
+        .string()
+        .default(DEFAULTS.keybinds.file_shrink)
+        .describe("Narrow the file pane"),
+      file_line_up: z
+        .string()
+        .default(DEFAULTS.keybinds.file_line_up)
+        .describe("Scroll the focused file up by one line"),
+      file_line_down: z
+        .string()
+        .default(DEFAULTS.keybinds.file_line_down)
+        .describe("Scroll the focused file down by one line"),
+      file_page_up: z
+        .string()
+        .default(DEFAULTS.keybinds.file_page_up)
+        .describe("Scroll the focused file up by one page"),
+      file_page_down: z
+        .string()
+        .default(DEFAULTS.keybinds.file_page_down)
+        .describe("Scroll the focused file down by one page"),
+      file_half_page_up: z
+        .string()
+        .default(DEFAULTS.keybinds.file_half_page_up)
+        .describe("Scroll the focused file up by half page"),
+      file_half_page_down: z
+        .string()
+        .default(DEFAULTS.keybinds.file_half_page_down)
+        .describe("Scroll the focused file down by half page"),
+      file_top: z
+        .string()
+        .default(DEFAULTS.keybinds.file_top)
+        .describe("Scroll to the top of the focused file"),
+      file_bottom: z
+        .string()
+        .default(DEFAULTS.keybinds.file_bottom)
+        .describe("Scroll to the bottom of the focused file"),
+      file_hunk_next: z
+        .string()
+        .default(DEFAULTS.keybinds.file_hunk_next)
+        .describe("Select the next hunk in the file viewer"),
+      file_hunk_previous: z
+        .string()
+        .default(DEFAULTS.keybinds.file_hunk_previous)
+        .describe("Select the previous hunk in the file viewer"),
+      file_hunk_stage: z
+        .string()
+        .default(DEFAULTS.keybinds.file_hunk_stage)
+        .describe("Stage or unstage the selected hunk in the file viewer"),
+      file_definition: z
+        .string()
+        .default(DEFAULTS.keybinds.file_definition)
+        .describe("Go to the definition of the clicked name in the file viewer"),
+      file_find: z
+        .string()
+        .default(DEFAULTS.keybinds.file_find)
+        .describe("Search the text of the file"),
+      file_find_next: z
+        .string()
+        .default(DEFAULTS.keybinds.file_find_next)
+        .describe("Next match in the file"),
+      file_find_previous: z
+        .string()
+        .default(DEFAULTS.keybinds.file_find_previous)
+        .describe("Previous match in the file"),
+      file_goto_line: z
+        .string()
+        .default(DEFAULTS.keybinds.file_goto_line)
+        .describe("Go to a line of the file"),
+      file_back: z
+        .string()
+        .default(DEFAULTS.keybinds.file_back)
+        .describe("Open the file viewed before"),
+      file_forward: z
+        .string()
+        .default(DEFAULTS.keybinds.file_forward)
+        .describe("Open the file viewed after going back"),
+      file_viewed: z
+        .string()
+        .default(DEFAULTS.keybinds.file_viewed)
+        .describe("List the files viewed recently"),
+      file_edit: z
+        .string()
+        .default(DEFAULTS.keybinds.file_edit)
+        .describe("Open the file in the IDE or EDITOR at the line in view"),
       project_init: z
         .string()
         .default(DEFAULTS.keybinds.project_init)
@@ -300,6 +461,14 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.input_paste)
         .describe("Paste from clipboard"),
+      input_open_attachment: z
+        .string()
+        .default(DEFAULTS.keybinds.input_open_attachment)
+        .describe("Open the attachment under the cursor in the file viewer"),
+      input_preview: z
+        .string()
+        .default(DEFAULTS.keybinds.input_preview)
+        .describe("Preview the attachment at the cursor"),
       input_submit: z
         .string()
         .default(DEFAULTS.keybinds.input_submit)
@@ -340,6 +509,22 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.messages_last)
         .describe("Navigate to last message"),
+      messages_line_up: z
+        .string()
+        .default(DEFAULTS.keybinds.messages_line_up)
+        .describe("Scroll focused messages up by one line"),
+      messages_line_down: z
+        .string()
+        .default(DEFAULTS.keybinds.messages_line_down)
+        .describe("Scroll focused messages down by one line"),
+      messages_top: z
+        .string()
+        .default(DEFAULTS.keybinds.messages_top)
+        .describe("Scroll focused messages to the top"),
+      messages_bottom: z
+        .string()
+        .default(DEFAULTS.keybinds.messages_bottom)
+        .describe("Scroll focused messages to the bottom"),
       messages_layout_toggle: z
         .string()
         .default(DEFAULTS.keybinds.messages_layout_toggle)
@@ -360,6 +545,26 @@ export namespace ConfigSchema {
         .string()
         .default(DEFAULTS.keybinds.messages_redo)
         .describe("Redo message"),
+      messages_retry: z
+        .string()
+        .default(DEFAULTS.keybinds.messages_retry)
+        .describe("Retry last message"),
+      messages_scroll_lock: z
+        .string()
+        .default(DEFAULTS.keybinds.messages_scroll_lock)
+        .describe("Toggle following new output"),
+      scratchpad_toggle: z
+        .string()
+        .default(DEFAULTS.keybinds.scratchpad_toggle)
+        .describe("Toggle scratchpad"),
+      shell_output: z
+        .string()
+        .default(DEFAULTS.keybinds.shell_output)
+        .describe("Show the output of the last shell command"),
+      shell_mode: z
+        .string()
+        .default(DEFAULTS.keybinds.shell_mode)
+        .describe("Toggle shell mode"),
       app_exit: z
         .string()
         .default(DEFAULTS.keybinds.app_exit)
@@ -567,6 +772,312 @@ export namespace ConfigSchema {
     })
     .strict();
 
+  // TUI Configuration
+  export const TuiConfig = z
+    .object({
+      budget: z
+        .object({
+          session: z
+            .number()
+            .positive()
+            .optional()
+            .describe("Ask for confirmation before sending prompts once a session has cost this many dollars"),
+          daily: z
+            .number()
+            .positive()
+            .optional()
+            .describe("Ask for confirmation before sending prompts once today's sessions have cost this many dollars"),
+          warn_at: z
+            .number()
+            .min(1)
+            .max(100)
+            .optional()
+            .describe("Percentage of a budget at which to warn that it is running out (default 80)"),
+        })
+        .strict()
+        .optional()
+        .describe("Cost budgets per session and per day"),
+      clipboard: z
+        .enum(["auto", "native", "osc52", "both"])
+        .optional()
+        .describe(
+          "How copied text reaches the clipboard: the system clipboard, OSC 52 through the terminal, or both. auto uses the system clipboard when there is one and OSC 52 as well (default auto)",
+        ),
+      commands: z
+        .record(
+          z.string(),
+          z
+            .object({
+              description: z
+                .string()
+                .optional()
+                .describe("Description shown next to the command in completions"),
+              prompt: z
+                .string()
+                .optional()
+                .describe(
+                  "Prompt sent to the agent, with $ARGUMENTS replaced by the text after the command. It is placed in the editor instead when edit is set or it needs arguments none were given",
+                ),
+              shell: z
+                .string()
+                .optional()
+                .describe("Shell command to run, with $ARGUMENTS replaced by the text after the command"),
+              run: z
+                .string()
+                .optional()
+                .describe("TUI command to run, by its keybind name, e.g. tool_details"),
+              edit: z
+                .boolean()
+                .optional()
+                .describe("Place the prompt in the editor instead of sending it"),
+              keybind: z
+                .string()
+                .optional()
+                .describe("Keybind that runs the command"),
+            })
+            .strict(),
+        )
+        .optional()
+        .describe("Custom slash commands that send a prompt, run a shell command or run a TUI command"),
+      compact_threshold: z
+        .number()
+        .min(1)
+        .max(100)
+        .optional()
+        .describe("Context window usage percentage at which to suggest compacting the session (default 80)"),
+      completions: z
+        .record(
+          z.string().length(1),
+          z
+            .object({
+              providers: z
+                .array(z.string())
+                .describe(
+                  "Providers of the suggestions: commands, files, symbols, agents, git, resources, shell, or the name of a provider in completion_providers. Empty turns the trigger off",
+                ),
+              position: z
+                .enum(["line", "word", "anywhere"])
+                .optional()
+                .describe(
+                  "Where the trigger opens the completion: in an empty editor, at the start of a word, or anywhere (default word)",
+                ),
+              spaces: z
+                .boolean()
+                .optional()
+                .describe("Keep the completion open when a space is typed, for completing whole commands"),
+            })
+            .strict(),
+        )
+        .optional()
+        .describe(
+          "Completions opened by typing a trigger character, replacing the default for the triggers set. Defaults: / commands, @ files, symbols, agents and git, # resources, ! shell",
+        ),
+      completion_providers: z
+        .record(
+          z.string(),
+          z
+            .object({
+              shell: z
+                .string()
+                .describe(
+                  "Shell command printing a suggestion a line, optionally followed by a tab and a description. The text typed after the trigger is in $QUERY",
+                ),
+            })
+            .strict(),
+        )
+        .optional()
+        .describe("Custom completion providers listing the output of a shell command"),
+      icons: z
+        .enum(["emoji", "nerd_font", "ascii"])
+        .optional()
+        .describe(
+          "Icons drawn by the TUI: emoji, the glyphs of a Nerd Font, or plain ASCII for fonts without either (default emoji)",
+        ),
+      images: z
+        .object({
+          max_dimension: z
+            .number()
+            .int()
+            .positive()
+            .optional()
+            .describe("Longest side in pixels of attached images, larger ones are scaled down (default 1568)"),
+          max_bytes: z
+            .number()
+            .int()
+            .positive()
+            .optional()
+            .describe("Size in bytes of attached images, larger ones are scaled down (default 3750000)"),
+        })
+        .strict()
+        .optional()
+        .describe("Limits above which attached images are scaled down before sending"),
+      latency_budget: z
+        .number()
+        .positive()
+        .optional()
+        .describe("Milliseconds from a key press to the next render above which input latency is logged (default 16)"),
+      locale: z
+        .string()
+        .optional()
+        .describe(
+          "Language of the TUI, like de or de-AT. Defaults to KUUZUKI_LOCALE, then LC_ALL, LC_MESSAGES and LANG, falling back to English",
+        ),
+      model_fallback: z
+        .object({
+          models: z
+            .array(z.string())
+            .describe("Models to fall back to in order, in the format provider/model"),
+          auto: z
+            .boolean()
+            .optional()
+            .describe("Retry with the next fallback model without asking first"),
+        })
+        .strict()
+        .optional()
+        .describe("Fallback models to retry with when the provider rejects a request"),
+      notifications: z
+        .object({
+          complete: z
+            .enum(["off", "bell", "desktop", "both"])
+            .optional()
+            .describe("How to notify that a long request finished while the terminal is unfocused (default desktop)"),
+          permission: z
+            .enum(["off", "bell", "desktop", "both"])
+            .optional()
+            .describe("How to notify that a tool needs approval while the terminal is unfocused (default desktop)"),
+          error: z
+            .enum(["off", "bell", "desktop", "both"])
+            .optional()
+            .describe("How to notify that a request failed while the terminal is unfocused (default desktop)"),
+          min_seconds: z
+            .number()
+            .min(0)
+            .optional()
+            .describe("Seconds a request must run before its completion is notified (default 10)"),
+        })
+        .strict()
+        .optional()
+        .describe("Desktop notifications and terminal bells for events while the terminal is unfocused"),
+      sandbox: z
+        .boolean()
+        .optional()
+        .describe(
+          "Move the agent's edits to a sandbox branch, created with the session's first prompt, to merge back or discard when done",
+        ),
+      screen_reader: z
+        .object({
+          enabled: z
+            .boolean()
+            .optional()
+            .describe(
+              "Print new messages, tool calls and notifications as plain lines for screen readers, without spinners or overlays",
+            ),
+          alt_screen: z
+            .boolean()
+            .optional()
+            .describe(
+              "Keep the alternate screen, by default the TUI runs in the terminal's own screen so what it prints stays in the scrollback",
+            ),
+        })
+        .strict()
+        .optional()
+        .describe("Screen reader friendly output"),
+      session_retention: z
+        .object({
+          max_age_days: z
+            .number()
+            .int()
+            .min(1)
+            .optional()
+            .describe("Delete sessions that have not been updated in this many days"),
+          max_count: z
+            .number()
+            .int()
+            .min(1)
+            .optional()
+            .describe("Keep at most this many sessions, deleting the oldest first"),
+          prune_on_startup: z
+            .boolean()
+            .optional()
+            .describe("Automatically delete expired sessions when the TUI starts"),
+        })
+        .strict()
+        .optional()
+        .describe("Retention policy for old sessions"),
+      reduce_motion: z
+        .boolean()
+        .optional()
+        .describe("Show spinners still and cursors without blinking, for motion sensitivity and slow connections"),
+      run_limits: z
+        .object({
+          max_tool_calls: z
+            .number()
+            .int()
+            .min(1)
+            .optional()
+            .describe("Pause the session after this many tool calls in a single turn"),
+          max_seconds: z
+            .number()
+            .int()
+            .min(1)
+            .optional()
+            .describe("Pause the session after a single turn has run for this many seconds"),
+          max_cost: z
+            .number()
+            .positive()
+            .optional()
+            .describe("Pause the session after a single turn has cost this many dollars"),
+        })
+        .strict()
+        .optional()
+        .describe("Guard limits that pause runaway agent turns"),
+      status_line: z
+        .string()
+        .optional()
+        .describe(
+          "Status bar segments separated by spaces, with | between the left and right sides. Segments: logo, cwd, branch, keymap, agent, model, usage, context, budget, fallback, scroll_lock, follow, clock, health, tool. A branch right after cwd renders as cwd:branch",
+        ),
+      status_usage: z
+        .enum(["tokens", "cost", "both"])
+        .optional()
+        .describe("Show the session's token usage, cost, or both in the status bar"),
+      templates: z
+        .record(
+          z.string(),
+          z
+            .object({
+              description: z
+                .string()
+                .optional()
+                .describe("Short description shown in the template picker"),
+              system: z
+                .string()
+                .optional()
+                .describe("Additional system prompt for sessions started from this template"),
+              prompt: z
+                .string()
+                .optional()
+                .describe("Initial prompt placed in the editor"),
+              files: z
+                .array(z.string())
+                .optional()
+                .describe("Files attached to the initial prompt"),
+              agent: z
+                .string()
+                .optional()
+                .describe("Agent to switch to"),
+              model: z
+                .string()
+                .optional()
+                .describe("Model to use in the format of provider/model"),
+            })
+            .strict(),
+        )
+        .optional()
+        .describe("Named session templates for recurring workflows"),
+    })
+    .strict();
+
   // Main Configuration Schema
   export const Config = z
     .object({
@@ -603,6 +1114,12 @@ export namespace ConfigSchema {
         .describe(
           "Small model to use for tasks like summarization and title generation",
         ),
+      transcription_model: z
+        .string()
+        .optional()
+        .describe(
+          "Model to transcribe audio attachments with in the format of provider/model, eg openai/whisper-1",
+        ),
 
       // Feature Configuration
       share: Share.describe(
@@ -685,6 +1202,8 @@ export namespace ConfigSchema {
         "Experimental features and configurations",
       ),
 
+      tui: TuiConfig.optional().describe("Terminal UI configuration"),
+
       // Permission Configuration - Enhanced format supporting agent-level permissions
       permission: z
         .union([
@@ -775,6 +1294,36 @@ export namespace ConfigSchema {
           "Permission configuration supporting simple array format, OpenCode compatibility, agent-level permissions, and advanced wildcard pattern matching. Environment variable OPENCODE_PERMISSION can override this setting.",
         ),
 
+      permission_notify: z
+        .object({
+          url: z
+            .string()
+            .url()
+            .describe("Webhook, ntfy topic or Slack incoming webhook URL to send permission requests to"),
+          format: z
+            .enum(["webhook", "ntfy", "slack"])
+            .default("webhook")
+            .describe("Payload format: JSON for webhooks, ntfy actions or a Slack message"),
+          headers: z
+            .record(z.string(), z.string())
+            .optional()
+            .describe("Extra request headers, e.g. an authorization header"),
+          public_url: z
+            .string()
+            .url()
+            .optional()
+            .describe("URL the server is reachable at from the target, used for the approve and deny links"),
+          delay: z
+            .number()
+            .int()
+            .min(0)
+            .optional()
+            .describe("Seconds to wait for an answer in the terminal before notifying"),
+        })
+        .strict()
+        .optional()
+        .describe("Send permission requests to a remote target so they can be answered away from the terminal"),
+
       // Plugin Configuration
       plugin: z
         .array(z.string())
@@ -7,6 +7,7 @@ import * as git from "isomorphic-git"
 import { App } from "../app/app"
 import fs from "fs"
 import { Log } from "../util/log"
+import { NamedError } from "../util/error"
 
 export namespace File {
   const log = Log.create({ service: "file" })
@@ -29,10 +30,37 @@ export namespace File {
       "file.edited",
       z.object({
         file: z.string(),
+        sessionID: z.string().optional(),
       }),
     ),
   }
 
+  export const ApplyFailed = NamedError.create(
+    "FileApplyFailed",
+    z.object({
+      message: z.string(),
+    }),
+  )
+
+  /**
+   * Applies a unified diff to the working tree, all of it or none, and
+   * returns the paths it changed
+   */
+  export async function apply(patch: string) {
+    const app = App.info()
+    const numstat = await $`git apply --numstat < ${new Response(patch)}`.cwd(app.path.cwd).quiet().nothrow()
+    if (numstat.exitCode !== 0) throw new ApplyFailed({ message: numstat.stderr.toString().trim() || "Not a patch" })
+    const result = await $`git apply --whitespace=nowarn < ${new Response(patch)}`.cwd(app.path.cwd).quiet().nothrow()
+    if (result.exitCode !== 0) throw new ApplyFailed({ message: result.stderr.toString().trim() })
+    const files = numstat
+      .text()
+      .split("\n")
+      .map((line) => line.split("\t")[2])
+      .filter((file): file is string => !!file)
+    log.info("applied patch", { files })
+    return files
+  }
+
   export async function status() {
     const app = App.info()
     if (!app.git) return []
@@ -95,10 +123,63 @@ export namespace File {
     }))
   }
 
-  export async function read(file: string) {
+  // where each line of a paged file starts, so a page reads only its own
+  // bytes instead of the whole file
+  type LineIndex = { mtime: number; size: number; starts: number[] }
+  const lineIndexes = new Map<string, LineIndex>()
+  const MAX_LINE_INDEXES = 8
+
+  async function lineIndex(full: string): Promise<LineIndex | undefined> {
+    const stat = await fs.promises.stat(full).catch(() => undefined)
+    if (!stat?.isFile()) return
+    const cached = lineIndexes.get(full)
+    if (cached && cached.mtime === stat.mtimeMs && cached.size === stat.size) return cached
+
+    const starts = [0]
+    let position = 0
+    for await (const chunk of Bun.file(full).stream()) {
+      for (let i = chunk.indexOf(10); i !== -1; i = chunk.indexOf(10, i + 1)) {
+        starts.push(position + i + 1)
+      }
+      position += chunk.length
+    }
+    // a trailing newline doesn't start another line
+    if (starts.length > 1 && starts[starts.length - 1] === stat.size) starts.pop()
+
+    const index = { mtime: stat.mtimeMs, size: stat.size, starts }
+    lineIndexes.delete(full)
+    lineIndexes.set(full, index)
+    if (lineIndexes.size > MAX_LINE_INDEXES) lineIndexes.delete(lineIndexes.keys().next().value!)
+    return index
+  }
+
+  // readPage returns the lines of a file from offset, or nothing when the
+  // whole file fits on the first page
+  async function readPage(full: string, range: { offset: number; limit: number }) {
+    const index = await lineIndex(full)
+    if (!index) return
+    const total = index.starts.length
+    if (range.offset === 0 && total <= range.limit) return
+    const start = index.starts[Math.min(range.offset, total)] ?? index.size
+    const end = index.starts[range.offset + range.limit] ?? index.size
+    const content = await Bun.file(full).slice(start, end).text()
+    return {
+      type: "raw" as const,
+      content: content.replace(/\r?\n$/, ""),
+      offset: range.offset,
+      total,
+    }
+  }
+
+  export async function read(file: string, range?: { offset: number; limit: number }) {
     using _ = log.time("read", { file })
     const app = App.info()
     const full = path.join(app.path.cwd, file)
+    if (range) {
+      // only page large files, smaller ones still get their diff
+      const page = await readPage(full, range)
+      if (page) return page
+    }
     const content = await Bun.file(full)
       .text()
       .catch(() => "")
@@ -333,7 +333,8 @@ export namespace Ripgrep {
       args.push(`--max-count=${input.limit}`)
     }
 
-    args.push(input.pattern)
+    // the command runs as raw shell, so the pattern is quoted to keep its spaces
+    args.push($.escape(input.pattern))
 
     const command = args.join(" ")
     const result = await $`${{ raw: command }}`.cwd(input.cwd).quiet().nothrow()
@@ -8,6 +8,7 @@ export namespace Identifier {
     user: "usr",
     part: "prt",
     permission: "prm",
+    input: "inp",
   } as const;
 
   export function schema(prefix: keyof typeof prefixes) {
@@ -24,14 +24,17 @@ export namespace LSP {
     });
   export type Range = z.infer<typeof Range>;
 
+  export const Location = z.object({
+    uri: z.string(),
+    range: Range,
+  });
+  export type Location = z.infer<typeof Location>;
+
   export const Symbol = z
     .object({
       name: z.string(),
       kind: z.number(),
-      location: z.object({
-        uri: z.string(),
-        range: Range,
-      }),
+      location: Location,
     })
     .openapi({
       ref: "Symbol",
@@ -149,6 +152,39 @@ export namespace LSP {
     });
   }
 
+  export async function definition(input: {
+    file: string;
+    line: number;
+    character: number;
+  }) {
+    // the document has to be open for the server to resolve names in it
+    await touchFile(input.file);
+    return run((client) =>
+      client.connection
+        .sendRequest("textDocument/definition", {
+          textDocument: {
+            uri: `file://${input.file}`,
+          },
+          position: {
+            line: input.line,
+            character: input.character,
+          },
+        })
+        .catch(() => null),
+    ).then((result) =>
+      result
+        .flatMap((x: any) => (x ? (Array.isArray(x) ? x : [x]) : []))
+        .filter(Boolean)
+        .map(
+          (x: any): Location =>
+            // a LocationLink, from servers that support them
+            "targetUri" in x
+              ? { uri: x.targetUri, range: x.targetSelectionRange ?? x.targetRange }
+              : { uri: x.uri, range: x.range },
+        ),
+    );
+  }
+
   enum SymbolKind {
     File = 1,
     Module = 2,
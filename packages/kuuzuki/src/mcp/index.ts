@@ -1,4 +1,6 @@
-import { experimental_createMCPClient, type Tool } from "ai";
+import { jsonSchema, tool, type Tool } from "ai";
+import { Client } from "@modelcontextprotocol/sdk/client/index.js";
+import { CallToolResultSchema } from "@modelcontextprotocol/sdk/types.js";
 import { StreamableHTTPClientTransport } from "@modelcontextprotocol/sdk/client/streamableHttp.js";
 import { SSEClientTransport } from "@modelcontextprotocol/sdk/client/sse.js";
 import { StdioClientTransport } from "@modelcontextprotocol/sdk/client/stdio.js";
@@ -9,6 +11,7 @@ import { NamedError } from "../util/error";
 import { z } from "zod";
 import { Session } from "../session";
 import { Bus } from "../bus";
+import { Installation } from "../installation";
 
 export namespace MCP {
   const log = Log.create({ service: "mcp" });
@@ -20,14 +23,30 @@ export namespace MCP {
     }),
   );
 
+  export const Resource = z
+    .object({
+      server: z.string(),
+      uri: z.string(),
+      name: z.string(),
+      description: z.string().optional(),
+      mimeType: z.string().optional(),
+    })
+    .openapi({
+      ref: "McpResource",
+    });
+  export type Resource = z.infer<typeof Resource>;
+
+  // One client per server serves both its tools and its resources
+  function create(name: string) {
+    return new Client({ name, version: Installation.VERSION });
+  }
+
   const state = App.state(
     "mcp",
     async () => {
       const cfg = await Config.get();
       const clients: {
-        [name: string]: Awaited<
-          ReturnType<typeof experimental_createMCPClient>
-        >;
+        [name: string]: Client;
       } = {};
       for (const [key, mcpConfig] of Object.entries(cfg.mcp ?? {})) {
         // Type assertion to ensure mcpConfig conforms to expected MCP config structure
@@ -59,11 +78,12 @@ export namespace MCP {
             }),
           ];
           for (const transport of transports) {
-            const client = await experimental_createMCPClient({
-              name: key,
-              transport,
-            }).catch(() => {});
-            if (!client) continue;
+            const client = create(key);
+            const connected = await client
+              .connect(transport)
+              .then(() => true)
+              .catch(() => false);
+            if (!connected) continue;
             clients[key] = client;
             break;
           }
@@ -90,10 +110,10 @@ export namespace MCP {
           });
 
           try {
-            const client = await Promise.race([
-              experimental_createMCPClient({
-                name: key,
-                transport: new StdioClientTransport({
+            const client = create(key);
+            await Promise.race([
+              client.connect(
+                new StdioClientTransport({
                   stderr: "ignore",
                   command: cmd,
                   args,
@@ -103,7 +123,7 @@ export namespace MCP {
                     ...mcp.environment,
                   },
                 }),
-              }),
+              ),
               timeoutPromise,
             ]);
 
@@ -145,16 +165,70 @@ export namespace MCP {
   export async function tools() {
     const result: Record<string, Tool> = {};
     for (const [clientName, client] of Object.entries(await clients())) {
-      for (const [toolName, tool] of Object.entries(await client.tools())) {
+      if (!client.getServerCapabilities()?.tools) continue;
+      const listed = await client.listTools();
+      for (const item of listed.tools) {
         const sanitizedClientName = clientName.replace(/\s+/g, "_");
-        const sanitizedToolName = toolName.replace(/[-\s]+/g, "_");
+        const sanitizedToolName = item.name.replace(/[-\s]+/g, "_");
         const fullToolName = `${sanitizedClientName}_${sanitizedToolName}`;
-        result[fullToolName] = tool;
+        result[fullToolName] = tool({
+          description: item.description,
+          inputSchema: jsonSchema({
+            ...item.inputSchema,
+            properties: item.inputSchema.properties ?? {},
+          } as any),
+          execute: (args, options) =>
+            client.callTool(
+              { name: item.name, arguments: args as Record<string, unknown> },
+              CallToolResultSchema,
+              { signal: options.abortSignal },
+            ),
+        });
       }
     }
     return result;
   }
 
+  /**
+   * The resources every connected server exposes, servers without
+   * resources or failing to list them are left out
+   */
+  export async function resources() {
+    const result: Resource[] = [];
+    for (const [server, client] of Object.entries(await clients())) {
+      if (!client.getServerCapabilities()?.resources) continue;
+      const listed = await client.listResources().catch((error) => {
+        log.warn("failed to list resources", { server, error: error.message });
+      });
+      for (const resource of listed?.resources ?? []) {
+        result.push({
+          server,
+          uri: resource.uri,
+          name: resource.name,
+          description: resource.description,
+          mimeType: resource.mimeType,
+        });
+      }
+    }
+    return result;
+  }
+
+  /**
+   * The text of a resource, binary contents are named rather than included
+   */
+  export async function read(server: string, uri: string) {
+    const client = (await clients())[server];
+    if (!client) throw new Failed({ name: server });
+    const result = await client.readResource({ uri });
+    return result.contents
+      .map((content) =>
+        "text" in content && typeof content.text === "string"
+          ? content.text
+          : `[${content.mimeType ?? "binary"} content of ${content.uri}]`,
+      )
+      .join("\n\n");
+  }
+
   /**
    * Sanitize tool names for consistency and compatibility
    * - Replace spaces and special characters with underscores
@@ -68,6 +68,25 @@ export namespace Permission {
   export const Event = {
     Updated: Bus.event("permission.updated", Info),
     TuiUpdated: Bus.event("permission.tui.updated", Info),
+    // A pending request was answered, by the user, by an "always" answer
+    // covering it, or by timing out
+    Replied: Bus.event(
+      "permission.replied",
+      z.object({
+        sessionID: z.string(),
+        permissionID: z.string(),
+        response: z.string(),
+        source: z.enum(["local", "remote"]).optional(),
+      }),
+    ),
+  };
+
+  // An "always" answer, standing for the rest of the session
+  export type Approval = {
+    type: string;
+    pattern?: string;
+    // exact approvals take the pattern literally rather than as a glob
+    exact?: boolean;
   };
 
   const state = App.state(
@@ -85,7 +104,7 @@ export namespace Permission {
 
       const approved: {
         [sessionID: string]: {
-          [patternOrType: string]: boolean;
+          [patternOrType: string]: Approval;
         };
       } = {};
 
@@ -316,7 +335,7 @@ export namespace Permission {
 
     // Check if this pattern/type was previously approved
     const approvalKey = input.pattern ?? input.type;
-    if (approved[input.sessionID]?.[approvalKey]) {
+    if (approvals(input.sessionID).some((approval) => covers(approval, input))) {
       log.info("previously approved", {
         sessionID: input.sessionID,
         type: input.type,
@@ -343,9 +362,8 @@ export namespace Permission {
         pattern: input.pattern,
         agentName: input.agentName,
       });
-      // Mark as approved for future use
-      approved[input.sessionID] = approved[input.sessionID] || {};
-      approved[input.sessionID][approvalKey] = true;
+      // Not remembered as a session approval, so edits to the permission
+      // rules apply to the next call
       return;
     } else if (permissionResult === "deny") {
       log.info("automatically denied by permission configuration", {
@@ -387,7 +405,7 @@ export namespace Permission {
       });
       // Mark as approved for future use
       approved[input.sessionID] = approved[input.sessionID] || {};
-      approved[input.sessionID][approvalKey] = true;
+      approved[input.sessionID][approvalKey] = { type: input.type, pattern: input.pattern };
       return;
     } else if (permissionDecision.status === "deny") {
       log.info("Plugin denied permission", {
@@ -408,6 +426,11 @@ export namespace Permission {
         // Clean up pending request
         if (pending[input.sessionID] && pending[input.sessionID][info.id]) {
           delete pending[input.sessionID][info.id];
+          Bus.publish(Event.Replied, {
+            sessionID: input.sessionID,
+            permissionID: info.id,
+            response: "reject",
+          });
           log.warn("Permission request timed out", {
             sessionID: input.sessionID,
             permissionID: info.id,
@@ -437,16 +460,53 @@ export namespace Permission {
   export const Response = z.enum(["once", "always", "reject"]);
   export type Response = z.infer<typeof Response>;
 
+  // The scope of an "always" answer: a pattern narrows it to matching
+  // commands, project and global scopes are written to the permission config
+  export const ResponseRule = z.object({
+    pattern: z.string().optional(),
+    scope: z.enum(["session", "project", "global"]),
+    exact: z.boolean().optional().describe("Match the pattern literally, for a single command"),
+  });
+  export type ResponseRule = z.infer<typeof ResponseRule>;
+
+  // Whether an approval covers a request. An approval without a pattern
+  // covers every call of the tool. An exact approval only covers the very
+  // command it was given for, so a command chained after it isn't let
+  // through. Other patterns are matched against the request pattern and the
+  // full command.
+  export function covers(
+    approval: Approval,
+    input: { type: string; pattern?: string; metadata?: Record<string, any> },
+  ) {
+    if (approval.type !== input.type) return false;
+    if (!approval.pattern) return true;
+    const command = input.metadata?.command;
+    if (approval.exact) {
+      return typeof command === "string" ? approval.pattern === command : approval.pattern === input.pattern;
+    }
+    if (approval.pattern === input.pattern) return true;
+    return typeof command === "string" && Wildcard.match(approval.pattern, command);
+  }
+
   export function respond(input: {
     sessionID: Info["sessionID"];
     permissionID: Info["id"];
     response: Response;
-  }) {
+    rule?: ResponseRule;
+    // remote answers come through a notification
+    source?: "local" | "remote";
+  }): Info | undefined {
     log.info("response", input);
-    const { pending, approved } = state();
+    const { pending } = state();
     const match = pending[input.sessionID]?.[input.permissionID];
     if (!match) return;
     delete pending[input.sessionID][input.permissionID];
+    Bus.publish(Event.Replied, {
+      sessionID: input.sessionID,
+      permissionID: input.permissionID,
+      response: input.response,
+      source: input.source ?? "local",
+    });
     if (input.response === "reject") {
       match.reject(
         new RejectedError(
@@ -455,27 +515,52 @@ export namespace Permission {
           match.info.callID,
         ),
       );
-      return;
+      return match.info;
     }
     match.resolve();
     if (input.response === "always") {
-      approved[input.sessionID] = approved[input.sessionID] || {};
-      const approvalKey = match.info.pattern ?? match.info.type;
-      approved[input.sessionID][approvalKey] = true;
+      const approval: Approval = {
+        type: match.info.type,
+        pattern: input.rule ? input.rule.pattern : match.info.pattern,
+        exact: input.rule?.exact,
+      };
+      // project and global rules are written to the config by the caller
+      if (!input.rule || input.rule.scope === "session") {
+        approve(input.sessionID, approval);
+      }
 
-      // Auto-approve any other pending requests with the same pattern/type
+      // Auto-approve any other pending requests the approval covers
       // Collect items to approve first to avoid modifying collection during iteration
       const itemsToApprove = Object.values(pending[input.sessionID]).filter(item => {
-        const itemApprovalKey = item.info.pattern ?? item.info.type;
-        return itemApprovalKey === approvalKey && item.info.id !== input.permissionID;
+        return covers(approval, item.info) && item.info.id !== input.permissionID;
       });
       
       // Approve collected items without recursion
       for (const item of itemsToApprove) {
         delete pending[input.sessionID][item.info.id];
         item.resolve();
+        Bus.publish(Event.Replied, {
+          sessionID: input.sessionID,
+          permissionID: item.info.id,
+          response: "always",
+        });
       }
     }
+    return match.info;
+  }
+
+  export function approvals(sessionID: string): Approval[] {
+    return Object.values(state().approved[sessionID] ?? {});
+  }
+
+  export function approve(sessionID: string, approval: Approval) {
+    const { approved } = state();
+    approved[sessionID] = approved[sessionID] || {};
+    approved[sessionID][approval.pattern ?? approval.type] = approval;
+  }
+
+  export function revoke(sessionID: string, approval: Approval) {
+    delete state().approved[sessionID]?.[approval.pattern ?? approval.type];
   }
 
   export class RejectedError extends Error {
@@ -0,0 +1,119 @@
+import { Bus } from "../bus";
+import { Config } from "../config/config";
+import { Log } from "../util/log";
+import { Permission } from "./index";
+
+/**
+ * Sends permission requests to a webhook, ntfy topic or Slack so they can be
+ * answered away from the terminal. Each request gets a one-off token that
+ * the remote answer has to carry.
+ */
+export namespace PermissionNotify {
+  const log = Log.create({ service: "permission.notify" });
+
+  // permission id → token of its remote answer and the session it belongs to
+  const tokens = new Map<string, { token: string; sessionID: string }>();
+
+  export function init() {
+    log.info("init");
+
+    Bus.subscribe(Permission.Event.Updated, async (payload) => {
+      const cfg = await Config.get();
+      const notify = cfg.permission_notify;
+      if (!notify) return;
+      const info = payload.properties;
+      const token = crypto.randomUUID();
+      tokens.set(info.id, { token, sessionID: info.sessionID });
+
+      if (notify.delay) {
+        await Bun.sleep(notify.delay * 1000);
+        // answered locally in the meantime
+        if (!tokens.has(info.id)) return;
+      }
+      await send(notify, info, token).catch((error) => {
+        log.error("failed to send permission notification", {
+          permissionID: info.id,
+          error: error instanceof Error ? error.message : String(error),
+        });
+      });
+    });
+
+    Bus.subscribe(Permission.Event.Replied, async (payload) => {
+      tokens.delete(payload.properties.permissionID);
+    });
+  }
+
+  // valid checks the token of a remote answer without using it up
+  export function valid(permissionID: string, sessionID: string, token: string) {
+    const expected = tokens.get(permissionID);
+    return !!expected && expected.token === token && expected.sessionID === sessionID;
+  }
+
+  // verify checks the token of a remote answer, it can only be used once. A
+  // wrong session leaves the token intact.
+  export function verify(permissionID: string, sessionID: string, token: string) {
+    if (!valid(permissionID, sessionID, token)) return false;
+    tokens.delete(permissionID);
+    return true;
+  }
+
+  type Target = NonNullable<Config.Info["permission_notify"]>;
+
+  function links(target: Target, info: Permission.Info, token: string) {
+    if (!target.public_url) return;
+    const base = target.public_url.replace(/\/$/, "");
+    const link = (response: Permission.Response) =>
+      `${base}/permission/${info.id}/remote?sessionID=${info.sessionID}&response=${response}&token=${token}`;
+    return {
+      once: link("once"),
+      always: link("always"),
+      reject: link("reject"),
+    };
+  }
+
+  async function send(target: Target, info: Permission.Info, token: string) {
+    const urls = links(target, info, token);
+    const command = typeof info.metadata?.command === "string" ? info.metadata.command : undefined;
+    const text = command ? `${info.title}\n${command}` : info.title;
+    const headers: Record<string, string> = { ...target.headers };
+    let body: string;
+
+    switch (target.format) {
+      case "ntfy":
+        headers["Title"] = "kuuzuki needs permission";
+        headers["Tags"] = "lock";
+        if (urls) {
+          headers["Actions"] = [
+            `http, Approve, ${urls.once}, method=POST, clear=true`,
+            `http, Always, ${urls.always}, method=POST, clear=true`,
+            `http, Deny, ${urls.reject}, method=POST, clear=true`,
+          ].join("; ");
+        }
+        body = text;
+        break;
+      case "slack":
+        // the links open a confirmation page, so link previews can't answer
+        headers["Content-Type"] = "application/json";
+        body = JSON.stringify({
+          text: urls
+            ? `${text}\n<${urls.once}|Approve> · <${urls.always}|Always> · <${urls.reject}|Deny>`
+            : text,
+        });
+        break;
+      default:
+        headers["Content-Type"] = "application/json";
+        body = JSON.stringify({
+          type: "permission.requested",
+          permission: info,
+          token,
+          respond: urls,
+        });
+    }
+
+    const response = await fetch(target.url, { method: "POST", headers, body });
+    if (!response.ok) {
+      throw new Error(`${target.url} answered ${response.status}`);
+    }
+    log.info("sent permission notification", { permissionID: info.id, format: target.format });
+  }
+}
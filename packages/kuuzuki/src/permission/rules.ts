@@ -0,0 +1,181 @@
+import path from "path";
+import { z } from "zod";
+import { applyEdits, modify, parse as parseJsonc } from "jsonc-parser";
+import { App } from "../app/app";
+import { Config } from "../config/config";
+import { Global } from "../global";
+import { Filesystem } from "../util/filesystem";
+import { NamedError } from "../util/error";
+import { Permission } from "./index";
+
+/**
+ * Standing permission rules: the "always" approvals of a session, and the
+ * allow/ask/deny entries of the project and global permission config
+ */
+export namespace PermissionRule {
+  export const Info = z
+    .object({
+      tool: z.string(),
+      pattern: z.string().optional(),
+      action: z.enum(["ask", "allow", "deny"]),
+      scope: z.enum(["session", "project", "global"]),
+    })
+    .openapi({
+      ref: "PermissionRule",
+    });
+  export type Info = z.infer<typeof Info>;
+
+  export const InvalidError = NamedError.create(
+    "PermissionRuleInvalidError",
+    z.object({
+      message: z.string(),
+    }),
+  );
+
+  // tools with their own key in the permission config, any other tool is
+  // listed under `tools`
+  const CONFIG_TOOLS = ["edit", "bash", "webfetch", "write", "read"];
+
+  async function configFile(scope: "project" | "global") {
+    if (scope === "global") {
+      for (const name of ["kuuzuki.json", "config.json"]) {
+        const file = path.join(Global.Path.config, name);
+        if (await Bun.file(file).exists()) return file;
+      }
+      return path.join(Global.Path.config, "kuuzuki.json");
+    }
+    const app = App.info();
+    for (const name of ["kuuzuki.jsonc", "kuuzuki.json"]) {
+      const [closest] = await Filesystem.findUp(name, app.path.cwd, app.path.root);
+      if (closest) return closest;
+    }
+    return path.join(app.path.root, "kuuzuki.json");
+  }
+
+  async function read(file: string) {
+    const text = await Bun.file(file)
+      .text()
+      .catch(() => "");
+    return text.trim() ? text : "{}";
+  }
+
+  function fromConfig(permission: any, scope: Info["scope"]): Info[] {
+    if (!permission || typeof permission !== "object" || Array.isArray(permission)) return [];
+    const rules: Info[] = [];
+    for (const tool of CONFIG_TOOLS) {
+      const value = permission[tool];
+      if (typeof value === "string") {
+        rules.push({ tool, action: value as Info["action"], scope });
+        continue;
+      }
+      if (tool === "bash" && value && typeof value === "object") {
+        for (const [pattern, action] of Object.entries(value)) {
+          rules.push({ tool, pattern, action: action as Info["action"], scope });
+        }
+      }
+    }
+    for (const [tool, action] of Object.entries(permission.tools ?? {})) {
+      rules.push({ tool, action: action as Info["action"], scope });
+    }
+    return rules;
+  }
+
+  export async function list(sessionID?: string): Promise<Info[]> {
+    const rules: Info[] = [];
+    if (sessionID) {
+      for (const approval of Permission.approvals(sessionID)) {
+        rules.push({
+          tool: approval.type,
+          pattern: approval.pattern,
+          action: "allow",
+          scope: "session",
+        });
+      }
+    }
+    for (const scope of ["project", "global"] as const) {
+      const text = await read(await configFile(scope));
+      rules.push(...fromConfig(parseJsonc(text)?.permission, scope));
+    }
+    return rules;
+  }
+
+  export async function set(rule: Info, sessionID?: string) {
+    if (rule.scope === "session") {
+      if (!sessionID) throw new InvalidError({ message: "Session rules need a session" });
+      if (rule.action !== "allow") {
+        throw new InvalidError({ message: "Session rules can only allow" });
+      }
+      Permission.approve(sessionID, { type: rule.tool, pattern: rule.pattern });
+      return;
+    }
+    await update(rule.scope, (text, permission) => {
+      if (rule.pattern) {
+        if (rule.tool !== "bash") {
+          throw new InvalidError({
+            message: "Only bash rules take a pattern in the permission config",
+          });
+        }
+        if (typeof permission.bash === "string") {
+          text = edit(text, ["permission", "bash"], { "*": permission.bash });
+        }
+        return edit(text, ["permission", "bash", rule.pattern], rule.action);
+      }
+      // a pattern map keeps its other patterns, the rule becomes its catch-all
+      if (rule.tool === "bash" && typeof permission.bash === "object") {
+        return edit(text, ["permission", "bash", "*"], rule.action);
+      }
+      if (CONFIG_TOOLS.includes(rule.tool)) {
+        return edit(text, ["permission", rule.tool], rule.action);
+      }
+      return edit(text, ["permission", "tools", rule.tool], rule.action);
+    });
+  }
+
+  export async function remove(rule: Info, sessionID?: string) {
+    if (rule.scope === "session") {
+      if (sessionID) Permission.revoke(sessionID, { type: rule.tool, pattern: rule.pattern });
+      return;
+    }
+    await update(rule.scope, (text, permission) => {
+      if (rule.pattern) return edit(text, ["permission", "bash", rule.pattern], undefined);
+      if (typeof permission[rule.tool] === "string") {
+        text = edit(text, ["permission", rule.tool], undefined);
+      }
+      if (permission.tools?.[rule.tool] !== undefined) {
+        text = edit(text, ["permission", "tools", rule.tool], undefined);
+      }
+      return text;
+    });
+  }
+
+  function edit(text: string, jsonPath: string[], value: unknown) {
+    return applyEdits(
+      text,
+      modify(text, jsonPath, value, {
+        formattingOptions: { insertSpaces: true, tabSize: 2 },
+      }),
+    );
+  }
+
+  async function update(
+    scope: "project" | "global",
+    fn: (text: string, permission: any) => string,
+  ) {
+    const file = await configFile(scope);
+    const text = await read(file);
+    const permission = parseJsonc(text)?.permission ?? {};
+    if (Array.isArray(permission)) {
+      throw new InvalidError({
+        message: `${file} lists permissions as an array, use the object format to manage rules`,
+      });
+    }
+    const updated = fn(text, permission);
+    await Bun.write(file, updated);
+    if (scope === "global") {
+      // the global config is read once per process, keep it in step
+      const global = await Config.global();
+      global.permission = parseJsonc(updated)?.permission;
+    }
+    App.reset("config");
+  }
+}
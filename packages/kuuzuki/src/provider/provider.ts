@@ -209,6 +209,13 @@ export namespace Provider {
     },
   }
 
+  /**
+   * Reloads the providers on next use, e.g. after their credentials changed
+   */
+  export function reset() {
+    App.reset("provider")
+  }
+
   const state = App.state("provider", async () => {
     const config = await Config.get()
     const database = await ModelsDev.get()
@@ -459,6 +466,15 @@ export namespace Provider {
     }
   }
 
+  export async function getTranscriptionModel(providerID: string, modelID: string) {
+    const provider = await state().then((state) => state.providers[providerID])
+    if (!provider) throw new ModelNotFoundError({ providerID, modelID })
+    const sdk = await getSDK(provider.info)
+    if (!sdk.transcriptionModel) throw new ModelNotFoundError({ providerID, modelID })
+    log.info("getTranscriptionModel", { providerID, modelID })
+    return sdk.transcriptionModel(modelID)
+  }
+
   export async function getSmallModel(providerID: string) {
     const cfg = await Config.get()
 
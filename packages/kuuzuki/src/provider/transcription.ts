@@ -0,0 +1,39 @@
+import z from "zod"
+import { experimental_transcribe } from "ai"
+import { Config } from "../config/config"
+import { NamedError } from "../util/error"
+import { Log } from "../util/log"
+import { Provider } from "./provider"
+
+export namespace Transcription {
+  const log = Log.create({ service: "transcription" })
+
+  const DEFAULT_MODEL = "openai/whisper-1"
+
+  export const FailedError = NamedError.create(
+    "TranscriptionFailedError",
+    z.object({
+      model: z.string(),
+      message: z.string(),
+    }),
+  )
+
+  /**
+   * Transcribes base64 encoded audio with the configured transcription
+   * model, Whisper when there is none
+   */
+  export async function transcribe(input: { audio: string; mime: string }) {
+    const cfg = await Config.get()
+    const model = cfg.transcription_model ?? DEFAULT_MODEL
+    const { providerID, modelID } = Provider.parseModel(model)
+    const transcription = await Provider.getTranscriptionModel(providerID, modelID)
+    using _ = log.time("transcribe", { model, mime: input.mime })
+    const result = await experimental_transcribe({
+      model: transcription,
+      audio: Buffer.from(input.audio, "base64"),
+    }).catch((e) => {
+      throw new FailedError({ model, message: e instanceof Error ? e.message : String(e) }, { cause: e })
+    })
+    return result.text
+  }
+}
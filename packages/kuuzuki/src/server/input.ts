@@ -0,0 +1,83 @@
+import { z } from "zod";
+import { Bus } from "../bus";
+import { Identifier } from "../id/id";
+import { Log } from "../util/log";
+
+/**
+ * Text input requested from the TUI, e.g. a value a tool or plugin needs
+ * from the user. The request goes out as an event and the TUI answers
+ * through respond, or cancels it.
+ */
+export namespace TuiInput {
+  const log = Log.create({ service: "tui.input" });
+
+  // how long a request waits for an answer unless it says otherwise
+  const TIMEOUT = 5 * 60 * 1000;
+
+  export const Request = z.object({
+    sessionID: z.string().optional(),
+    prompt: z.string(),
+    placeholder: z.string().optional(),
+    masked: z.boolean().optional().describe("Hide the typed value, for secrets"),
+  });
+  export type Request = z.infer<typeof Request>;
+
+  export const Answer = z
+    .object({
+      value: z.string().optional(),
+      cancelled: z.boolean(),
+    })
+    .openapi({
+      ref: "TuiInputAnswer",
+    });
+  export type Answer = z.infer<typeof Answer>;
+
+  export const Event = {
+    Requested: Bus.event(
+      "tui.input.requested",
+      Request.extend({
+        id: z.string(),
+      }),
+    ),
+    // answered, cancelled or timed out, so every TUI can close the input
+    Replied: Bus.event(
+      "tui.input.replied",
+      z.object({
+        id: z.string(),
+        cancelled: z.boolean(),
+      }),
+    ),
+  };
+
+  const pending = new Map<string, (answer: Answer) => void>();
+
+  export async function request(input: Request, options?: { timeout?: number; signal?: AbortSignal }) {
+    const id = Identifier.ascending("input");
+    const answer = new Promise<Answer>((resolve) => {
+      const finish = (answer: Answer) => {
+        clearTimeout(timeout);
+        options?.signal?.removeEventListener("abort", cancel);
+        pending.delete(id);
+        Bus.publish(Event.Replied, { id, cancelled: answer.cancelled });
+        resolve(answer);
+      };
+      const cancel = () => finish({ cancelled: true });
+      const timeout = setTimeout(() => {
+        log.warn("input request timed out", { id });
+        cancel();
+      }, options?.timeout ?? TIMEOUT);
+      options?.signal?.addEventListener("abort", cancel, { once: true });
+      pending.set(id, finish);
+    });
+    Bus.publish(Event.Requested, { ...input, id });
+    return answer;
+  }
+
+  // respond answers a pending request, false when it is no longer pending
+  export function respond(id: string, answer: Answer) {
+    const finish = pending.get(id);
+    if (!finish) return false;
+    finish(answer.cancelled ? { cancelled: true } : answer);
+    return true;
+  }
+}
@@ -6,7 +6,10 @@ import { streamSSE } from "hono/streaming";
 import { Session } from "../session";
 import { resolver, validator as zValidator } from "hono-openapi/zod";
 import { z } from "zod";
+import path from "path";
 import { Provider } from "../provider/provider";
+import { Transcription } from "../provider/transcription";
+import { Auth } from "../auth";
 import { App } from "../app/app";
 import { mapValues } from "remeda";
 import { NamedError } from "../util/error";
@@ -15,12 +18,18 @@ import { Ripgrep } from "../file/ripgrep";
 import { Config } from "../config/config";
 import { File } from "../file";
 import { LSP } from "../lsp";
+import { MCP } from "../mcp";
 import { MessageV2 } from "../session/message-v2";
+import { SessionChanges } from "../session/changes";
 import { Mode } from "../session/mode";
-import { callTui, TuiRoute } from "./tui";
+import { Agent } from "../agent/agent";
+import { askTui, callTui, TuiRoute } from "./tui";
+import { TuiInput } from "./input";
 import { Monitor, Cache } from "../performance";
 import { webhookHandler } from "./billing";
 import { Permission } from "../permission";
+import { PermissionRule } from "../permission/rules";
+import { PermissionNotify } from "../permission/notify";
 import { Plugin } from "../plugin";
 
 const ERRORS = {
@@ -130,7 +139,10 @@ export namespace Server {
           log.info("event connected");
           return streamSSE(c, async (stream) => {
             stream.writeSSE({
-              data: JSON.stringify({}),
+              data: JSON.stringify({
+                type: "server.connected",
+                properties: {},
+              }),
             });
             const unsub = Bus.subscribeAll(async (event) => {
               await stream.writeSSE({
@@ -696,6 +708,131 @@ export namespace Server {
           return c.json(session);
         },
       )
+      .get(
+        "/session/:id/changes",
+        describeRoute({
+          description: "List the files the session changed, with their diff since before the session changed them",
+          operationId: "session.changes",
+          responses: {
+            200: {
+              description: "Changed files",
+              content: {
+                "application/json": {
+                  schema: resolver(SessionChanges.Change.array()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id;
+          return c.json(await SessionChanges.list(id));
+        },
+      )
+      .post(
+        "/session/:id/changes/revert",
+        describeRoute({
+          description: "Put a file back as it was before the session changed it",
+          operationId: "session.revertFile",
+          responses: {
+            200: {
+              description: "Whether the session had changed the file",
+              content: {
+                "application/json": {
+                  schema: resolver(z.boolean()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            path: z.string().describe("File path relative to the working directory"),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id;
+          return c.json(await SessionChanges.revert(id, c.req.valid("json").path));
+        },
+      )
+      .post(
+        "/session/:id/pin",
+        describeRoute({
+          description: "Pin or unpin a file, sending its content with every prompt of the session",
+          operationId: "session.pin",
+          responses: {
+            200: {
+              description: "The updated session",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            path: z.string().describe("File path relative to the working directory"),
+            pinned: z.boolean(),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id;
+          const body = c.req.valid("json");
+          return c.json(await Session.pin(id, body.path, body.pinned));
+        },
+      )
+      .post(
+        "/session/:id/message/:messageID/part/:partID/revert",
+        describeRoute({
+          description: "Undo the change a single edit or write made, keeping later changes",
+          operationId: "session.revertPart",
+          responses: {
+            200: {
+              description: "The reverted file, relative to the working directory",
+              content: {
+                "application/json": {
+                  schema: resolver(z.string()),
+                },
+              },
+            },
+            ...ERRORS,
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+            messageID: z.string(),
+            partID: z.string(),
+          }),
+        ),
+        async (c) => {
+          const params = c.req.valid("param");
+          return c.json(await SessionChanges.revertPart(params.id, params.messageID, params.partID));
+        },
+      )
       .post(
         "/session/:id/unrevert",
         describeRoute({
@@ -762,7 +899,7 @@ export namespace Server {
           description: "Respond to a permission request",
           responses: {
             200: {
-              description: "Permission processed successfully",
+              description: "Whether the request was still pending and got the answer",
               content: {
                 "application/json": {
                   schema: resolver(z.boolean()),
@@ -778,17 +915,211 @@ export namespace Server {
             permissionID: z.string(),
           }),
         ),
-        zValidator("json", z.object({ response: Permission.Response })),
+        zValidator(
+          "json",
+          z.object({
+            response: Permission.Response,
+            rule: Permission.ResponseRule.optional(),
+          }),
+        ),
         async (c) => {
           const params = c.req.valid("param");
           const id = params.id;
           const permissionID = params.permissionID;
-          Permission.respond({
+          const { response, rule } = c.req.valid("json");
+          const info = Permission.respond({
             sessionID: id,
             permissionID,
-            response: c.req.valid("json").response,
+            response,
+            rule,
           });
-          return c.json(true);
+          if (info && response === "always" && rule && rule.scope !== "session") {
+            await PermissionRule.set(
+              {
+                tool: info.type,
+                pattern: rule.pattern,
+                action: "allow",
+                scope: rule.scope,
+              },
+              id,
+            );
+          }
+          return c.json(info !== undefined);
+        },
+      )
+      .get(
+        "/permission/:permissionID/remote",
+        describeRoute({
+          description: "Show a page confirming the answer of a remote notification link",
+          operationId: "permission.remote.confirm",
+          responses: {
+            200: {
+              description: "A confirmation page posting the answer",
+              content: {
+                "text/html": {
+                  schema: resolver(z.string()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            permissionID: z.string(),
+          }),
+        ),
+        zValidator(
+          "query",
+          z.object({
+            sessionID: z.string(),
+            response: Permission.Response,
+            token: z.string(),
+          }),
+        ),
+        async (c) => {
+          const { permissionID } = c.req.valid("param");
+          const { sessionID, response, token } = c.req.valid("query");
+          // a GET never answers, link previews and scanners open links too
+          if (!PermissionNotify.valid(permissionID, sessionID, token)) {
+            return c.html("<p>This permission request was already answered or has expired.</p>", 410);
+          }
+          const info = Permission.getPendingForSession(sessionID).find((item) => item.id === permissionID);
+          const escape = (text: string) =>
+            text.replace(/[&<>"']/g, (char) => `&#${char.charCodeAt(0)};`);
+          const label = { once: "Approve", always: "Always allow", reject: "Deny" }[response];
+          return c.html(
+            `<!doctype html><meta name="viewport" content="width=device-width">` +
+              `<p>kuuzuki asks: ${escape(info?.title ?? permissionID)}</p>` +
+              `<form method="post"><button type="submit">${label}</button></form>`,
+          );
+        },
+      )
+      .post(
+        "/permission/:permissionID/remote",
+        describeRoute({
+          description: "Answer a permission request from a remote notification",
+          operationId: "permission.remote",
+          responses: {
+            200: {
+              description: "Whether the answer was accepted",
+              content: {
+                "application/json": {
+                  schema: resolver(z.boolean()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            permissionID: z.string(),
+          }),
+        ),
+        zValidator(
+          "query",
+          z.object({
+            sessionID: z.string(),
+            response: Permission.Response,
+            token: z.string(),
+          }),
+        ),
+        async (c) => {
+          const { permissionID } = c.req.valid("param");
+          const { sessionID, response, token } = c.req.valid("query");
+          if (!PermissionNotify.verify(permissionID, sessionID, token)) {
+            return c.json(false, 403);
+          }
+          const info = Permission.respond({
+            sessionID,
+            permissionID,
+            response,
+            source: "remote",
+          });
+          return c.json(info !== undefined);
+        },
+      )
+      .get(
+        "/permission/rule",
+        describeRoute({
+          description: "List the standing permission rules",
+          operationId: "permission.rules",
+          responses: {
+            200: {
+              description: "Session approvals, then project and global rules",
+              content: {
+                "application/json": {
+                  schema: resolver(PermissionRule.Info.array()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "query",
+          z.object({
+            sessionID: z.string().optional(),
+          }),
+        ),
+        async (c) => {
+          return c.json(await PermissionRule.list(c.req.valid("query").sessionID));
+        },
+      )
+      .put(
+        "/permission/rule",
+        describeRoute({
+          description: "Add or change a permission rule",
+          operationId: "permission.setRule",
+          responses: {
+            200: {
+              description: "The permission rules after the change",
+              content: {
+                "application/json": {
+                  schema: resolver(PermissionRule.Info.array()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "json",
+          PermissionRule.Info.extend({
+            sessionID: z.string().optional(),
+          }),
+        ),
+        async (c) => {
+          const { sessionID, ...rule } = c.req.valid("json");
+          await PermissionRule.set(rule, sessionID);
+          return c.json(await PermissionRule.list(sessionID));
+        },
+      )
+      .delete(
+        "/permission/rule",
+        describeRoute({
+          description: "Revoke a permission rule",
+          operationId: "permission.removeRule",
+          responses: {
+            200: {
+              description: "The permission rules after the change",
+              content: {
+                "application/json": {
+                  schema: resolver(PermissionRule.Info.array()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "json",
+          PermissionRule.Info.extend({
+            sessionID: z.string().optional(),
+          }),
+        ),
+        async (c) => {
+          const { sessionID, ...rule } = c.req.valid("json");
+          await PermissionRule.remove(rule, sessionID);
+          return c.json(await PermissionRule.list(sessionID));
         },
       )
       .get(
@@ -915,6 +1246,90 @@ export namespace Server {
           return c.json(result);
         },
       )
+      .get(
+        "/find/definition",
+        describeRoute({
+          description: "Find the definition of the symbol at a position in a file",
+          operationId: "find.definition",
+          responses: {
+            200: {
+              description: "Locations of the definition",
+              content: {
+                "application/json": {
+                  schema: resolver(LSP.Location.array()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "query",
+          z.object({
+            path: z.string(),
+            line: z.coerce.number().int().min(0),
+            character: z.coerce.number().int().min(0),
+          }),
+        ),
+        async (c) => {
+          const { path: file, line, character } = c.req.valid("query");
+          const app = App.info();
+          const result = await LSP.definition({
+            file: path.isAbsolute(file) ? file : path.join(app.path.cwd, file),
+            line,
+            character,
+          });
+          return c.json(result);
+        },
+      )
+      .get(
+        "/mcp/resource",
+        describeRoute({
+          description: "List the resources MCP servers expose",
+          operationId: "mcp.resources",
+          responses: {
+            200: {
+              description: "MCP resources",
+              content: {
+                "application/json": {
+                  schema: resolver(MCP.Resource.array()),
+                },
+              },
+            },
+          },
+        }),
+        async (c) => {
+          return c.json(await MCP.resources());
+        },
+      )
+      .get(
+        "/mcp/resource/read",
+        describeRoute({
+          description: "Read the text of an MCP resource",
+          operationId: "mcp.read",
+          responses: {
+            200: {
+              description: "Resource text",
+              content: {
+                "application/json": {
+                  schema: resolver(z.string()),
+                },
+              },
+            },
+            ...ERRORS,
+          },
+        }),
+        zValidator(
+          "query",
+          z.object({
+            server: z.string(),
+            uri: z.string(),
+          }),
+        ),
+        async (c) => {
+          const { server, uri } = c.req.valid("query");
+          return c.json(await MCP.read(server, uri));
+        },
+      )
       .get(
         "/file",
         describeRoute({
@@ -928,6 +1343,8 @@ export namespace Server {
                     z.object({
                       type: z.enum(["raw", "patch"]),
                       content: z.string(),
+                      offset: z.number().optional(),
+                      total: z.number().optional(),
                     }),
                   ),
                 },
@@ -939,11 +1356,16 @@ export namespace Server {
           "query",
           z.object({
             path: z.string(),
+            offset: z.coerce.number().int().min(0).optional(),
+            limit: z.coerce.number().int().min(1).optional(),
           }),
         ),
         async (c) => {
-          const path = c.req.valid("query").path;
-          const content = await File.read(path);
+          const { path, offset, limit } = c.req.valid("query");
+          const content = await File.read(
+            path,
+            limit ? { offset: offset ?? 0, limit } : undefined,
+          );
           log.info("read file", {
             path,
             content: content.content,
@@ -971,6 +1393,61 @@ export namespace Server {
           return c.json(content);
         },
       )
+      .post(
+        "/file/patch",
+        describeRoute({
+          description: "Apply a unified diff to the working tree",
+          operationId: "file.patch",
+          responses: {
+            200: {
+              description: "The files the patch changed",
+              content: {
+                "application/json": {
+                  schema: resolver(z.string().array()),
+                },
+              },
+            },
+            ...ERRORS,
+          },
+        }),
+        zValidator(
+          "json",
+          z.object({
+            patch: z.string(),
+          }),
+        ),
+        async (c) => {
+          return c.json(await File.apply(c.req.valid("json").patch));
+        },
+      )
+      .post(
+        "/file/transcribe",
+        describeRoute({
+          description: "Transcribe an audio file with the transcription model",
+          operationId: "file.transcribe",
+          responses: {
+            200: {
+              description: "The text of the audio",
+              content: {
+                "application/json": {
+                  schema: resolver(z.string()),
+                },
+              },
+            },
+            ...ERRORS,
+          },
+        }),
+        zValidator(
+          "json",
+          z.object({
+            audio: z.string().describe("The audio, base64 encoded"),
+            mime: z.string(),
+          }),
+        ),
+        async (c) => {
+          return c.json(await Transcription.transcribe(c.req.valid("json")));
+        },
+      )
       .post(
         "/log",
         describeRoute({
@@ -1027,6 +1504,7 @@ export namespace Server {
         "/mode",
         describeRoute({
           description: "List all modes",
+          operationId: "app.modes",
           responses: {
             200: {
               description: "List of modes",
@@ -1045,6 +1523,48 @@ export namespace Server {
           });
         },
       )
+      .get(
+        "/agent",
+        describeRoute({
+          description: "List all agents",
+          operationId: "app.agents",
+          responses: {
+            200: {
+              description: "List of agents",
+              content: {
+                "application/json": {
+                  schema: resolver(Agent.Info.array()),
+                },
+              },
+            },
+          },
+        }),
+        async (c) => {
+          return c.json(await Agent.list());
+        },
+      )
+      .post(
+        "/agent",
+        describeRoute({
+          description: "Create or update a custom agent",
+          operationId: "app.saveAgent",
+          responses: {
+            200: {
+              description: "Saved agent",
+              content: {
+                "application/json": {
+                  schema: resolver(Agent.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator("json", Agent.Input),
+        async (c) => {
+          const agent = await Agent.save(c.req.valid("json"));
+          return c.json(agent);
+        },
+      )
       .post(
         "/tui/append-prompt",
         describeRoute({
@@ -1085,7 +1605,207 @@ export namespace Server {
         }),
         async (c) => c.json(await callTui(c)),
       )
+      .post(
+        "/tui/ask",
+        describeRoute({
+          description: "Ask the user a question with several answers in the TUI",
+          operationId: "tui.ask",
+          responses: {
+            200: {
+              description: "The picked answer, index is -1 when the question was dismissed",
+              content: {
+                "application/json": {
+                  schema: resolver(
+                    z.object({
+                      index: z.number(),
+                      choice: z
+                        .object({
+                          key: z.string().optional(),
+                          label: z.string(),
+                          description: z.string().optional(),
+                        })
+                        .optional(),
+                    }),
+                  ),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "json",
+          z.object({
+            question: z.string(),
+            choices: z
+              .array(
+                z.object({
+                  key: z.string().optional(),
+                  label: z.string(),
+                  description: z.string().optional(),
+                }),
+              )
+              .min(1),
+            default: z.number().int().min(0).optional(),
+            timeout: z.number().positive().optional().describe("Seconds to wait for an answer, 300 by default"),
+          }),
+        ),
+        async (c) => c.json(await askTui(c)),
+      )
+      .post(
+        "/tui/input",
+        describeRoute({
+          description: "Ask the user to type a value in the TUI and wait for it",
+          operationId: "tui.input",
+          responses: {
+            200: {
+              description: "The typed value, or cancelled",
+              content: {
+                "application/json": {
+                  schema: resolver(TuiInput.Answer),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "json",
+          TuiInput.Request.extend({
+            timeout: z.number().positive().optional().describe("Seconds to wait for an answer, 300 by default"),
+          }),
+        ),
+        async (c) => {
+          const { timeout, ...input } = c.req.valid("json");
+          const answer = await TuiInput.request(input, {
+            timeout: timeout ? timeout * 1000 : undefined,
+            signal: c.req.raw.signal,
+          });
+          return c.json(answer);
+        },
+      )
+      .post(
+        "/tui/input/:inputID",
+        describeRoute({
+          description: "Answer or cancel a text input request",
+          operationId: "tui.input.respond",
+          responses: {
+            200: {
+              description: "Whether the request was still pending",
+              content: {
+                "application/json": {
+                  schema: resolver(z.boolean()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            inputID: z.string(),
+          }),
+        ),
+        zValidator("json", TuiInput.Answer),
+        async (c) => {
+          const { inputID } = c.req.valid("param");
+          return c.json(TuiInput.respond(inputID, c.req.valid("json")));
+        },
+      )
       .route("/tui/control", TuiRoute)
+      .get(
+        "/auth",
+        describeRoute({
+          description: "List the providers with stored credentials and their type",
+          operationId: "auth.list",
+          responses: {
+            200: {
+              description: "Credential type by provider ID",
+              content: {
+                "application/json": {
+                  schema: resolver(z.record(z.string(), z.enum(["api", "oauth", "wellknown"]))),
+                },
+              },
+            },
+          },
+        }),
+        async (c) => {
+          const all = await Auth.all();
+          return c.json(mapValues(all, (info) => info.type));
+        },
+      )
+      .put(
+        "/auth/:id",
+        describeRoute({
+          description: "Set the API key of a provider",
+          operationId: "auth.set",
+          responses: {
+            200: {
+              description: "API key stored",
+              content: {
+                "application/json": {
+                  schema: resolver(z.boolean()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            key: z.string().min(1),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id;
+          const { key } = c.req.valid("json");
+          // keys are only stored for providers that can use them
+          const database = await ModelsDev.get();
+          const config = await Config.get();
+          if (!database[id] && !config.provider?.[id]) {
+            return c.json({ error: `Unknown provider: ${id}` }, 400);
+          }
+          await Auth.set(id, { type: "api", key });
+          Provider.reset();
+          return c.json(true);
+        },
+      )
+      .delete(
+        "/auth/:id",
+        describeRoute({
+          description: "Remove the stored credentials of a provider",
+          operationId: "auth.remove",
+          responses: {
+            200: {
+              description: "Credentials removed",
+              content: {
+                "application/json": {
+                  schema: resolver(z.boolean()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id;
+          if (!(await Auth.get(id))) {
+            return c.json({ error: `No credentials stored for ${id}` }, 404);
+          }
+          await Auth.remove(id);
+          Provider.reset();
+          return c.json(true);
+        },
+      )
       .post(
         "/billing/webhook",
         describeRoute({
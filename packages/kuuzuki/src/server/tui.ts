@@ -19,6 +19,50 @@ export async function callTui(ctx: Context) {
   return response.next();
 }
 
+// id of a question asked with askTui → resolver of its answer
+const answers = new Map<string, (answer: any) => void>();
+
+// how long askTui waits for an answer unless the request says otherwise
+const ASK_TIMEOUT = 5 * 60 * 1000;
+
+/**
+ * Asks the TUI a question with several answers. The TUI acknowledges the
+ * request right away and sends the answer to /answer/:id once picked. The
+ * question counts as dismissed when it times out or the caller goes away.
+ */
+export async function askTui(ctx: Context) {
+  const body = await ctx.req.json();
+  const id = crypto.randomUUID();
+  const dismissed = { index: -1 };
+  const answer = new Promise<any>((resolve) => {
+    const timeout = setTimeout(() => {
+      answers.delete(id);
+      resolve(dismissed);
+    }, body.timeout ? body.timeout * 1000 : ASK_TIMEOUT);
+    const abort = () => {
+      clearTimeout(timeout);
+      answers.delete(id);
+      resolve(dismissed);
+    };
+    ctx.req.raw.signal?.addEventListener("abort", abort, { once: true });
+    answers.set(id, (value) => {
+      clearTimeout(timeout);
+      ctx.req.raw.signal?.removeEventListener("abort", abort);
+      resolve(value);
+    });
+  });
+  request.push({
+    path: ctx.req.path,
+    body: { ...body, id },
+  });
+  const shown = await response.next();
+  if (!shown) {
+    answers.get(id)?.(dismissed);
+    answers.delete(id);
+  }
+  return answer;
+}
+
 export const TuiRoute = new Hono()
   .get("/next", async (c) => {
     const req = await request.next();
@@ -29,6 +73,13 @@ export const TuiRoute = new Hono()
     response.push(body);
     return c.json(true);
   })
+  .post("/answer/:id", async (c) => {
+    const resolve = answers.get(c.req.param("id"));
+    if (!resolve) return c.json(false);
+    answers.delete(c.req.param("id"));
+    resolve(await c.req.json());
+    return c.json(true);
+  })
   .get("/permissions/:sessionID", async (c) => {
     const sessionID = c.req.param("sessionID");
     const pending = Permission.getPendingForSession(sessionID);
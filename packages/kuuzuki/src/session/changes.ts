@@ -0,0 +1,144 @@
+import fs from "fs/promises";
+import path from "path";
+import { z } from "zod";
+import { structuredPatch, createPatch, applyPatch } from "diff";
+import { App } from "../app/app";
+import { Snapshot } from "../snapshot";
+import { Log } from "../util/log";
+import { Identifier } from "../id/id";
+import { MessageV2 } from "./message-v2";
+import { Session } from ".";
+import { replace } from "../tool/edit";
+
+// SessionChanges lists the files a session changed, each from before the
+// session first touched it to now, using the snapshots taken at every step
+export namespace SessionChanges {
+  const log = Log.create({ service: "session.changes" });
+
+  export const Change = z
+    .object({
+      path: z.string().describe("File path relative to the working directory"),
+      patch: z.string(),
+      additions: z.number(),
+      deletions: z.number(),
+    })
+    .openapi({
+      ref: "SessionChange",
+    });
+  export type Change = z.output<typeof Change>;
+
+  // before returns the snapshot each changed file was first seen in, by
+  // absolute path
+  async function before(sessionID: string) {
+    const result = new Map<string, string>();
+    for (const msg of await Session.messages(sessionID)) {
+      for (const part of msg.parts) {
+        if (part.type !== "patch") continue;
+        for (const file of part.files) {
+          if (!result.has(file)) result.set(file, part.hash);
+        }
+      }
+    }
+    return result;
+  }
+
+  async function current(file: string) {
+    const handle = Bun.file(file);
+    if (!(await handle.exists())) return "";
+    return handle.text();
+  }
+
+  export async function list(sessionID: string): Promise<Change[]> {
+    const app = App.info();
+    const result: Change[] = [];
+    for (const [file, hash] of await before(sessionID)) {
+      const original = (await Snapshot.read(hash, file)) ?? "";
+      const now = await current(file);
+      if (original === now) continue;
+      const rel = path.relative(app.path.cwd, file);
+      const counts = structuredPatch(rel, rel, original, now).hunks.flatMap((hunk) => hunk.lines);
+      result.push({
+        path: rel,
+        patch: createPatch(rel, original, now),
+        additions: counts.filter((line) => line.startsWith("+")).length,
+        deletions: counts.filter((line) => line.startsWith("-")).length,
+      });
+    }
+    return result.sort((a, b) => a.path.localeCompare(b.path));
+  }
+
+  // revert puts the file back as it was before the session changed it
+  export async function revert(sessionID: string, file: string) {
+    const full = path.resolve(App.info().path.cwd, file);
+    const hash = (await before(sessionID)).get(full);
+    if (!hash) return false;
+    log.info("reverting", { sessionID, file });
+    await Snapshot.revert([{ hash, files: [full] }]);
+    return true;
+  }
+
+  // revertPart undoes the change a single edit or write tool call made,
+  // leaving the other changes to the file alone, and records the revert as a
+  // patch part of the message like the changes of tools. It returns the
+  // reverted file.
+  export async function revertPart(sessionID: string, messageID: string, partID: string) {
+    const parts = (await Session.messages(sessionID)).flatMap((msg) => msg.parts);
+    const index = parts.findIndex((part) => part.id === partID && part.messageID === messageID);
+    const part = parts[index];
+    if (!part || part.type !== "tool" || !["edit", "write"].includes(part.tool) || part.state.status !== "completed") {
+      throw new Error("Only a completed edit or write can be reverted");
+    }
+    const input = part.state.input as {
+      filePath?: string;
+      content?: string;
+      oldString?: string;
+      newString?: string;
+      replaceAll?: boolean;
+    };
+    if (!input.filePath) throw new Error("The tool call has no file");
+    const file = path.resolve(App.info().path.cwd, input.filePath);
+    const rel = path.relative(App.info().path.cwd, file);
+
+    // the snapshot taken right before the call, older sessions didn't note
+    // the call and have the first one after it touching the file
+    const patches = parts
+      .slice(index + 1)
+      .filter((item): item is MessageV2.PatchPart => item.type === "patch" && item.files.includes(file));
+    const patch = patches.find((item) => item.callID === part.callID) ?? patches.find((item) => !item.callID);
+    if (!patch) throw new Error("No snapshot was taken before the change");
+
+    // the file before and after the call alone, replaying it on the snapshot
+    const before = await Snapshot.read(patch.hash, file);
+    let after: string;
+    if (part.tool === "write" || !input.oldString) {
+      after = (part.tool === "write" ? input.content : input.newString) ?? "";
+    } else {
+      after = replace(before ?? "", input.oldString, input.newString ?? "", input.replaceAll);
+    }
+    const now = await current(file);
+
+    const hash = await Snapshot.track();
+    if (before === undefined) {
+      // the file was created by the call, it is removed if left untouched since
+      if (now !== after) throw new Error("The file was changed since it was created");
+      await fs.unlink(file);
+    } else {
+      const reverted = applyPatch(now, createPatch(rel, after, before));
+      if (reverted === false) throw new Error("The change no longer applies, the file was changed since");
+      await Bun.write(file, reverted);
+    }
+    log.info("reverted part", { sessionID, messageID, partID, file });
+
+    if (hash) {
+      await Session.updatePart({
+        id: Identifier.ascending("part"),
+        messageID,
+        sessionID,
+        type: "patch",
+        hash,
+        files: [file],
+      });
+    }
+    return rel;
+  }
+}
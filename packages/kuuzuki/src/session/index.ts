@@ -2,6 +2,7 @@ import path from "path";
 import { Decimal } from "decimal.js";
 import { z, ZodSchema } from "zod";
 import {
+  APICallError,
   generateText,
   LoadAPIKeyError,
   NoSuchToolError,
@@ -40,7 +41,9 @@ import { Snapshot } from "../snapshot";
 import { Storage } from "../storage/storage";
 import { Log } from "../util/log";
 import { NamedError } from "../util/error";
+import { Filesystem } from "../util/filesystem";
 import { SystemPrompt } from "./system";
+import { SessionShell } from "./shell";
 import { FileTime } from "../file/time";
 import { MessageV2 } from "./message-v2";
 import { Mode } from "./mode";
@@ -295,6 +298,7 @@ export namespace Session {
           snapshot: z.string().optional(),
         })
         .optional(),
+      pinned: z.array(z.string()).optional(),
     })
     .openapi({
       ref: "Session",
@@ -525,6 +529,20 @@ export namespace Session {
     await Share.remove(id, share.secret);
   }
 
+  // pin keeps a file's current content in the system prompt of every chat
+  // request of the session
+  export async function pin(id: string, file: string, pinned: boolean) {
+    const { cwd } = App.info().path;
+    if (!Filesystem.contains(cwd, path.resolve(cwd, file))) {
+      throw new Error(`Can only pin files in the working directory: ${file}`);
+    }
+    return update(id, (draft) => {
+      const paths = (draft.pinned ?? []).filter((p) => p !== file);
+      if (pinned) paths.push(file);
+      draft.pinned = paths.length > 0 ? paths : undefined;
+    });
+  }
+
   export async function update(id: string, editor: (session: Info) => void) {
     const { sessions } = state();
     const session = await get(id);
@@ -838,7 +856,10 @@ export namespace Session {
                     sessionID: input.sessionID,
                     type: "text",
                     synthetic: true,
-                    text: Buffer.from(part.url, "base64url").toString(),
+                    text: Buffer.from(
+                      part.url.slice(part.url.indexOf(",") + 1),
+                      "base64",
+                    ).toString(),
                   },
                   {
                     ...part,
@@ -854,7 +875,14 @@ export namespace Session {
               // Decode the pathname since URL constructor doesn't automatically decode it
               const pathname = decodeURIComponent(url.pathname);
               const relativePath = pathname.replace(app.path.cwd, ".");
-              const filePath = path.join(app.path.cwd, relativePath);
+              const filePath = path.resolve(app.path.cwd, relativePath);
+              // files outside the project, like a Windows drive under WSL,
+              // are sent inline by the TUI, the server only reads the project
+              if (!Filesystem.contains(app.path.cwd, filePath)) {
+                throw new Error(
+                  `Can only attach files in the working directory by path: ${pathname}`,
+                );
+              }
 
               if (part.mime === "text/plain") {
                 let offset: number | undefined = undefined;
@@ -1446,6 +1474,7 @@ export namespace Session {
     );
     system.push(...(await SystemPrompt.environment()));
     system.push(...(await SystemPrompt.custom()));
+    system.push(...(await SystemPrompt.pinned(session.pinned ?? [])));
 
     // max 2 system prompt messages for caching purposes
     const [first, ...rest] = system;
@@ -2563,6 +2592,7 @@ export namespace Session {
                         type: "patch",
                         hash: patch.hash,
                         files: patch.files,
+                        callID: value.toolCallId,
                       });
                     }
                   }
@@ -2599,6 +2629,7 @@ export namespace Session {
                       type: "patch",
                       hash: patch.hash,
                       files: patch.files,
+                      callID: value.toolCallId,
                     });
                   }
                 }
@@ -2792,6 +2823,16 @@ export namespace Session {
               }
               break;
             }
+            case APICallError.isInstance(e):
+              // the status code lets clients tell throttling from other failures
+              assistantMsg.error = new NamedError.Unknown(
+                {
+                  message: (e as APICallError).toString(),
+                  statusCode: (e as APICallError).statusCode,
+                },
+                { cause: e },
+              ).toObject();
+              break;
             case e instanceof Error:
               assistantMsg.error = new NamedError.Unknown(
                 { message: e.toString() },
@@ -4128,9 +4169,19 @@ export namespace Session {
   export const ShellInput = z.object({
     sessionID: z.string(),
     command: z.string(),
+    persistent: z
+      .boolean()
+      .optional()
+      .describe("Run in the session's shell, keeping its directory and variables between commands"),
   });
   export type ShellInput = z.infer<typeof ShellInput>;
 
+  // stripAnsi drops the escape sequences of shell output before the model
+  // sees it
+  function stripAnsi(text: string) {
+    return text.replace(/\x1b\[[0-9;?]*[ -\/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)/g, "");
+  }
+
   export async function shell(input: ShellInput) {
     using abort = lock(input.sessionID);
     const msg: MessageV2.Assistant = {
@@ -4165,6 +4216,38 @@ export namespace Session {
 
     await updatePart(part);
 
+    const finish = (output: string, exitCode: number | null) => {
+      part.state = {
+        status: "completed",
+        input: {
+          command: input.command,
+        },
+        output: stripAnsi(output),
+        metadata: {
+          exitCode,
+          output,
+        },
+        time: {
+          start: part.state.time!.start,
+          end: Date.now(),
+        },
+      };
+      return updatePart(part);
+    };
+
+    if (input.persistent) {
+      const result = await SessionShell.run({
+        sessionID: input.sessionID,
+        command: input.command,
+        abort: abort.signal,
+        onOutput: (output) => {
+          part.state.metadata = { output };
+          updatePart(part);
+        },
+      });
+      await finish(result.output, result.exitCode);
+      return msg;
+    }
 
     const app = App.info();
     
@@ -4189,6 +4272,9 @@ export namespace Session {
       env: {
         ...process.env,
         TERM: "dumb",
+        // the TUI shows the output with its colors as it streams
+        FORCE_COLOR: "1",
+        CLICOLOR_FORCE: "1",
       },
     });
 
@@ -4216,21 +4302,7 @@ export namespace Session {
 
     await new Promise<void>((resolve) => {
       proc.on("close", (code) => {
-        part.state = {
-          status: "completed",
-          input: {
-            command: input.command,
-          },
-          output,
-          metadata: {
-            exitCode: code,
-          },
-          time: {
-            start: part.state.time!.start,
-            end: Date.now(),
-          },
-        };
-        updatePart(part);
+        finish(output, code);
         resolve();
       });
     });
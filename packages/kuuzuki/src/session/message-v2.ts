@@ -99,6 +99,7 @@ export namespace MessageV2 {
     type: z.literal("patch"),
     hash: z.string(),
     files: z.string().array(),
+    callID: z.string().optional().describe("The tool call the snapshot was taken before"),
   }).openapi({
     ref: "PatchPart",
   })
@@ -103,6 +103,27 @@ export namespace Mode {
         };
     }
 
+    // Agents that can act as primary are selectable like modes
+    for (const [key, value] of Object.entries(cfg.agent ?? {})) {
+      const agentValue = value as {
+        disable?: boolean;
+        mode?: "subagent" | "primary" | "all";
+        model?: string;
+        prompt?: string;
+        temperature?: number;
+        tools?: Record<string, boolean>;
+      };
+      if (agentValue.disable || result[key]) continue;
+      if (agentValue.mode !== "primary" && agentValue.mode !== "all") continue;
+      result[key] = {
+        name: key,
+        model: agentValue.model ? Provider.parseModel(agentValue.model) : model,
+        prompt: agentValue.prompt,
+        temperature: agentValue.temperature,
+        tools: agentValue.tools ?? {},
+      };
+    }
+
     return result;
   });
 
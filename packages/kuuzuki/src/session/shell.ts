@@ -0,0 +1,116 @@
+import { spawn, type ChildProcessWithoutNullStreams } from "child_process";
+import { App } from "../app/app";
+import { Identifier } from "../id/id";
+import { Log } from "../util/log";
+
+// SessionShell keeps a bash process running per session, so commands run in
+// the TUI's shell mode share their directory, variables and functions
+export namespace SessionShell {
+  const log = Log.create({ service: "session.shell" });
+
+  const state = App.state(
+    "session.shell",
+    () => new Map<string, ChildProcessWithoutNullStreams>(),
+    async (shells) => {
+      for (const proc of shells.values()) proc.kill();
+    },
+  );
+
+  function start(sessionID: string) {
+    const existing = state().get(sessionID);
+    if (existing && existing.exitCode === null && !existing.killed) {
+      return existing;
+    }
+
+    const proc = spawn("bash", ["-l"], {
+      cwd: App.info().path.cwd,
+      env: {
+        ...process.env,
+        TERM: "dumb",
+        FORCE_COLOR: "1",
+        CLICOLOR_FORCE: "1",
+      },
+    });
+    proc.stdin.write(
+      "[[ -f ~/.bashrc ]] && source ~/.bashrc >/dev/null 2>&1 || true\nexec 2>&1\n",
+    );
+    proc.on("exit", (code) => {
+      log.info("shell exited", { sessionID, code });
+      if (state().get(sessionID) === proc) state().delete(sessionID);
+    });
+    state().set(sessionID, proc);
+    return proc;
+  }
+
+  // check parses the script with bash -n, returning what bash reports. An
+  // unclosed quote or here-document would swallow the lines after it in the
+  // session's shell, the marker included, and never finish.
+  function check(script: string) {
+    return new Promise<string>((resolve) => {
+      const proc = spawn("bash", ["-n"]);
+      let errors = "";
+      proc.stdout.on("data", (chunk: Buffer) => (errors += chunk.toString()));
+      proc.stderr.on("data", (chunk: Buffer) => (errors += chunk.toString()));
+      proc.on("error", (error) => resolve(error.message));
+      // bash only warns about an unterminated here-document
+      proc.on("close", (code) => {
+        if (code === 0 && !errors) resolve("");
+        else resolve(errors || `bash -n exited with ${code}`);
+      });
+      proc.stdin.end(script);
+    });
+  }
+
+  // run runs the command in the session's shell, reporting its output as it
+  // comes. Commands don't read the shell's stdin, which carries the commands.
+  // Commands that don't parse are reported without reaching the shell.
+  export async function run(input: {
+    sessionID: string;
+    command: string;
+    abort: AbortSignal;
+    onOutput: (output: string) => void;
+  }) {
+    const script = `{\n${input.command}\n} </dev/null\n`;
+    const errors = await check(script);
+    if (errors) {
+      log.info("syntax error", { sessionID: input.sessionID });
+      input.onOutput(errors);
+      return { output: errors, exitCode: 2 };
+    }
+
+    const proc = start(input.sessionID);
+    const marker = `__kuuzuki_${Identifier.ascending("call")}__`;
+    const done = new RegExp(`\\n?${marker}(\\d+)\\n`);
+
+    return new Promise<{ output: string; exitCode: number | null }>((resolve) => {
+      let output = "";
+      const finish = (exitCode: number | null) => {
+        proc.stdout.off("data", onData);
+        proc.off("exit", onExit);
+        input.abort.removeEventListener("abort", onAbort);
+        resolve({ output, exitCode });
+      };
+      const onData = (chunk: Buffer) => {
+        output += chunk.toString();
+        const match = output.match(done);
+        if (!match) {
+          input.onOutput(output);
+          return;
+        }
+        output = output.slice(0, match.index);
+        finish(parseInt(match[1], 10));
+      };
+      const onExit = (code: number | null) => finish(code);
+      // the shell goes with the command, the next one starts a new shell
+      const onAbort = () => {
+        proc.kill();
+        finish(null);
+      };
+
+      proc.stdout.on("data", onData);
+      proc.on("exit", onExit);
+      input.abort.addEventListener("abort", onAbort);
+      proc.stdin.write(`${script}printf '\\n%s%d\\n' '${marker}' $?\n`);
+    });
+  }
+}
@@ -104,6 +104,28 @@ export namespace SystemPrompt {
     ]
   }
 
+  // pinned files are cut short so one large file can't fill the context
+  const MAX_PINNED_FILE_SIZE = 100 * 1024
+
+  export async function pinned(paths: string[]) {
+    if (paths.length === 0) return []
+    const { cwd } = App.info().path
+    const files = await Promise.all(
+      paths.map(async (file) => {
+        const content = await Bun.file(path.resolve(cwd, file))
+          .text()
+          .then((text) =>
+            text.length > MAX_PINNED_FILE_SIZE ? text.slice(0, MAX_PINNED_FILE_SIZE) + "\n[truncated]" : text,
+          )
+          .catch((e) => `[could not be read: ${e instanceof Error ? e.message : e}]`)
+        return `\n<pinned_file path=${JSON.stringify(file)}>\n${content}\n</pinned_file>`
+      }),
+    )
+    return [
+      "The user pinned the following files so their current content is always available:\n" + files.join("\n"),
+    ]
+  }
+
   const CUSTOM_FILES = [
     ".agentrc",
     "AGENTS.md", // legacy support
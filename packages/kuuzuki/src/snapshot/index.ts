@@ -70,6 +70,17 @@ export namespace Snapshot {
     }
   }
 
+  // read returns the file as it was in the snapshot, undefined when it didn't
+  // exist yet
+  export async function read(hash: string, file: string) {
+    const app = App.info()
+    const git = gitdir()
+    const rel = path.relative(app.path.root, file)
+    const result = await $`git --git-dir ${git} show ${hash}:${rel}`.quiet().cwd(app.path.root).nothrow()
+    if (result.exitCode !== 0) return undefined
+    return result.text()
+  }
+
   export async function restore(snapshot: string) {
     log.info("restore", { commit: snapshot })
     const app = App.info()
@@ -86,6 +86,7 @@ export const EditTool = Tool.define("edit", {
         await Bun.write(filepath, params.newString);
         await Bus.publish(File.Event.Edited, {
           file: filepath,
+          sessionID: ctx.sessionID,
         });
         return;
       }
@@ -110,6 +111,7 @@ export const EditTool = Tool.define("edit", {
       await file.write(contentNew);
       await Bus.publish(File.Event.Edited, {
         file: filepath,
+        sessionID: ctx.sessionID,
       });
       contentNew = await file.text();
     })();
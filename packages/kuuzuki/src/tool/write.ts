@@ -68,6 +68,7 @@ export const WriteTool = Tool.define("write", {
     await Bun.write(filepath, params.content);
     await Bus.publish(File.Event.Edited, {
       file: filepath,
+      sessionID: ctx.sessionID,
     });
     FileTime.read(ctx.sessionID, filepath);
 
@@ -48,6 +48,7 @@ export abstract class NamedError extends Error {
     "UnknownError",
     z.object({
       message: z.string(),
+      statusCode: z.number().optional(),
     }),
   )
 }
@@ -0,0 +1,59 @@
+import { describe, expect, test } from "bun:test"
+import { App } from "../../src/app/app"
+import { File } from "../../src/file"
+import * as fs from "fs"
+import * as os from "os"
+import * as path from "path"
+
+function lines(count: number, prefix = "line") {
+  return Array.from({ length: count }, (_, i) => `${prefix} ${i}`).join("\n") + "\n"
+}
+
+describe("File.read paging", () => {
+  test("reads one page of a large file", async () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), "kuuzuki-paging-"))
+    fs.writeFileSync(path.join(dir, "big.txt"), lines(250))
+    await App.provide({ cwd: dir }, async () => {
+      const first = await File.read("big.txt", { offset: 0, limit: 100 })
+      expect(first).toMatchObject({ type: "raw", offset: 0, total: 250 })
+      expect(first.content.split("\n")).toHaveLength(100)
+      expect(first.content.startsWith("line 0\n")).toBe(true)
+
+      const last = await File.read("big.txt", { offset: 200, limit: 100 })
+      expect(last).toMatchObject({ type: "raw", offset: 200, total: 250 })
+      expect(last.content.split("\n")).toEqual(Array.from({ length: 50 }, (_, i) => `line ${200 + i}`))
+
+      const past = await File.read("big.txt", { offset: 300, limit: 100 })
+      expect(past).toMatchObject({ content: "", total: 250 })
+    })
+    fs.rmSync(dir, { recursive: true, force: true })
+  })
+
+  test("reads small files whole", async () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), "kuuzuki-paging-"))
+    fs.writeFileSync(path.join(dir, "small.txt"), lines(10))
+    await App.provide({ cwd: dir }, async () => {
+      const result = await File.read("small.txt", { offset: 0, limit: 100 })
+      expect(result.type).toBe("raw")
+      expect(result).not.toHaveProperty("total")
+      expect(result.content).toBe(lines(10).trim())
+    })
+    fs.rmSync(dir, { recursive: true, force: true })
+  })
+
+  test("pages the new content after the file changes", async () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), "kuuzuki-paging-"))
+    const file = path.join(dir, "big.txt")
+    fs.writeFileSync(file, lines(250))
+    await App.provide({ cwd: dir }, async () => {
+      await File.read("big.txt", { offset: 100, limit: 10 })
+      fs.writeFileSync(file, lines(300, "changed"))
+      fs.utimesSync(file, new Date(), new Date(Date.now() + 1000))
+
+      const page = await File.read("big.txt", { offset: 100, limit: 10 })
+      expect(page).toMatchObject({ total: 300 })
+      expect(page.content.startsWith("changed 100\n")).toBe(true)
+    })
+    fs.rmSync(dir, { recursive: true, force: true })
+  })
+})
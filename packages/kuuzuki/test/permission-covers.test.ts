@@ -0,0 +1,52 @@
+import { describe, test, expect } from "bun:test";
+import { Permission } from "../src/permission";
+
+describe("Permission.covers", () => {
+  test("an exact approval doesn't cover a chained command", () => {
+    const approval = { type: "bash", pattern: "git status", exact: true };
+    expect(
+      Permission.covers(approval, {
+        type: "bash",
+        pattern: "git status",
+        metadata: { command: "git status" },
+      }),
+    ).toBe(true);
+    expect(
+      Permission.covers(approval, {
+        type: "bash",
+        pattern: "git status",
+        metadata: { command: "git status && rm -rf ." },
+      }),
+    ).toBe(false);
+  });
+
+  test("a pattern approval covers matching commands", () => {
+    const approval = { type: "bash", pattern: "git *" };
+    expect(
+      Permission.covers(approval, {
+        type: "bash",
+        pattern: "git *",
+        metadata: { command: "git log" },
+      }),
+    ).toBe(true);
+    expect(
+      Permission.covers(approval, {
+        type: "bash",
+        pattern: "npm *",
+        metadata: { command: "git diff" },
+      }),
+    ).toBe(true);
+    expect(
+      Permission.covers(approval, {
+        type: "bash",
+        pattern: "npm *",
+        metadata: { command: "npm install" },
+      }),
+    ).toBe(false);
+  });
+
+  test("an approval without a pattern covers the whole tool", () => {
+    expect(Permission.covers({ type: "edit" }, { type: "edit", pattern: "src/index.ts" })).toBe(true);
+    expect(Permission.covers({ type: "edit" }, { type: "bash", pattern: "ls" })).toBe(false);
+  });
+});
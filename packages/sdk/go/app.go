@@ -63,6 +63,14 @@ func (r *AppService) Log(ctx context.Context, body AppLogParams, opts ...option.
 	return
 }
 
+// List all modes, the agents that can act as the primary one
+func (r *AppService) Modes(ctx context.Context, opts ...option.RequestOption) (res *[]Agent, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "mode"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, nil, &res, opts...)
+	return
+}
+
 // List all providers
 func (r *AppService) Providers(ctx context.Context, opts ...option.RequestOption) (res *AppProvidersResponse, err error) {
 	opts = append(r.Options[:], opts...)
@@ -71,6 +79,14 @@ func (r *AppService) Providers(ctx context.Context, opts ...option.RequestOption
 	return
 }
 
+// Create or update a custom agent
+func (r *AppService) SaveAgent(ctx context.Context, body AppSaveAgentParams, opts ...option.RequestOption) (res *Agent, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "agent"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 type Agent struct {
 	Mode        AgentMode       `json:"mode,required"`
 	Name        string          `json:"name,required"`
@@ -388,3 +404,33 @@ func (r AppLogParamsLevel) IsKnown() bool {
 	}
 	return false
 }
+
+type AppSaveAgentParams struct {
+	Description param.Field[string]    `json:"description,required"`
+	Name        param.Field[string]    `json:"name,required"`
+	Mode        param.Field[AgentMode] `json:"mode"`
+	// Default model as provider/model
+	Model  param.Field[string]                  `json:"model"`
+	Prompt param.Field[string]                  `json:"prompt"`
+	Scope  param.Field[AppSaveAgentParamsScope] `json:"scope"`
+	Tools  param.Field[map[string]bool]         `json:"tools"`
+}
+
+func (r AppSaveAgentParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
+type AppSaveAgentParamsScope string
+
+const (
+	AppSaveAgentParamsScopeGlobal  AppSaveAgentParamsScope = "global"
+	AppSaveAgentParamsScopeProject AppSaveAgentParamsScope = "project"
+)
+
+func (r AppSaveAgentParamsScope) IsKnown() bool {
+	switch r {
+	case AppSaveAgentParamsScopeGlobal, AppSaveAgentParamsScopeProject:
+		return true
+	}
+	return false
+}
@@ -35,6 +35,28 @@ func TestAppAgents(t *testing.T) {
 	}
 }
 
+func TestAppModes(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.App.Modes(context.TODO())
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
+
 func TestAppGet(t *testing.T) {
 	t.Skip("skipped: tests are disabled for the time being")
 	baseURL := "http://localhost:4010"
@@ -129,3 +151,35 @@ func TestAppProviders(t *testing.T) {
 		t.Fatalf("err should be nil: %s", err.Error())
 	}
 }
+
+func TestAppSaveAgentWithOptionalParams(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.App.SaveAgent(context.TODO(), kuuzuki.AppSaveAgentParams{
+		Description: kuuzuki.F("description"),
+		Name:        kuuzuki.F("name"),
+		Mode:        kuuzuki.F(kuuzuki.AgentModeAll),
+		Model:       kuuzuki.F("model"),
+		Prompt:      kuuzuki.F("prompt"),
+		Scope:       kuuzuki.F(kuuzuki.AppSaveAgentParamsScopeGlobal),
+		Tools: kuuzuki.F(map[string]bool{
+			"foo": true,
+		}),
+	})
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
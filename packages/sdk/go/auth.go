@@ -0,0 +1,90 @@
+// File generated from our OpenAPI spec by Stainless. See CONTRIBUTING.md for details.
+
+package kuuzuki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sst/opencode-sdk-go/internal/apijson"
+	"github.com/sst/opencode-sdk-go/internal/param"
+	"github.com/sst/opencode-sdk-go/internal/requestconfig"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+// AuthService contains methods and other services that help with interacting with
+// the kuuzuki API.
+//
+// Note, unlike clients, this service does not read variables from the environment
+// automatically. You should not instantiate this service directly, and instead use
+// the [NewAuthService] method instead.
+type AuthService struct {
+	Options []option.RequestOption
+}
+
+// NewAuthService generates a new service that applies the given options to each
+// request. These options are applied after the parent client's options (if there
+// is one), and before any request-specific options.
+func NewAuthService(opts ...option.RequestOption) (r *AuthService) {
+	r = &AuthService{}
+	r.Options = opts
+	return
+}
+
+// List the providers with stored credentials and their type
+func (r *AuthService) List(ctx context.Context, opts ...option.RequestOption) (res *map[string]AuthListResponse, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "auth"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, nil, &res, opts...)
+	return
+}
+
+// Set the API key of a provider
+func (r *AuthService) Set(ctx context.Context, id string, body AuthSetParams, opts ...option.RequestOption) (res *bool, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("auth/%s", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPut, path, body, &res, opts...)
+	return
+}
+
+// Remove the stored credentials of a provider
+func (r *AuthService) Remove(ctx context.Context, id string, opts ...option.RequestOption) (res *bool, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("auth/%s", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodDelete, path, nil, &res, opts...)
+	return
+}
+
+type AuthListResponse string
+
+const (
+	AuthListResponseAPI       AuthListResponse = "api"
+	AuthListResponseOauth     AuthListResponse = "oauth"
+	AuthListResponseWellknown AuthListResponse = "wellknown"
+)
+
+func (r AuthListResponse) IsKnown() bool {
+	switch r {
+	case AuthListResponseAPI, AuthListResponseOauth, AuthListResponseWellknown:
+		return true
+	}
+	return false
+}
+
+type AuthSetParams struct {
+	Key param.Field[string] `json:"key,required"`
+}
+
+func (r AuthSetParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
@@ -15,14 +15,17 @@ import (
 // interacting with the kuuzuki API. You should not instantiate this client
 // directly, and instead use the [NewClient] method instead.
 type Client struct {
-	Options []option.RequestOption
-	Event   *EventService
-	App     *AppService
-	Find    *FindService
-	File    *FileService
-	Config  *ConfigService
-	Session *SessionService
-	Tui     *TuiService
+	Options    []option.RequestOption
+	Event      *EventService
+	App        *AppService
+	Find       *FindService
+	File       *FileService
+	Config     *ConfigService
+	Session    *SessionService
+	Tui        *TuiService
+	Auth       *AuthService
+	Permission *PermissionService
+	Mcp        *McpService
 }
 
 // DefaultClientOptions read from the environment (OPENCODE_BASE_URL). This should
@@ -51,6 +54,9 @@ func NewClient(opts ...option.RequestOption) (r *Client) {
 	r.Config = NewConfigService(opts...)
 	r.Session = NewSessionService(opts...)
 	r.Tui = NewTuiService(opts...)
+	r.Auth = NewAuthService(opts...)
+	r.Permission = NewPermissionService(opts...)
+	r.Mcp = NewMcpService(opts...)
 
 	return
 }
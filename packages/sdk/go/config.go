@@ -79,6 +79,11 @@ type Config struct {
 	SmallModel string `json:"small_model"`
 	// Theme name to use for the interface
 	Theme string `json:"theme"`
+	// Model to transcribe audio attachments with in the format of
+	// provider/model, eg openai/whisper-1
+	TranscriptionModel string `json:"transcription_model"`
+	// Terminal UI configuration
+	Tui ConfigTui `json:"tui"`
 	// Custom username to display in conversations instead of system username
 	Username string     `json:"username"`
 	JSON     configJSON `json:"-"`
@@ -86,29 +91,31 @@ type Config struct {
 
 // configJSON contains the JSON metadata for the struct [Config]
 type configJSON struct {
-	Schema            apijson.Field
-	Agent             apijson.Field
-	Autoshare         apijson.Field
-	Autoupdate        apijson.Field
-	DisabledProviders apijson.Field
-	Experimental      apijson.Field
-	Formatter         apijson.Field
-	Instructions      apijson.Field
-	Keybinds          apijson.Field
-	Layout            apijson.Field
-	Lsp               apijson.Field
-	Mcp               apijson.Field
-	Mode              apijson.Field
-	Model             apijson.Field
-	Permission        apijson.Field
-	Plugin            apijson.Field
-	Provider          apijson.Field
-	Share             apijson.Field
-	SmallModel        apijson.Field
-	Theme             apijson.Field
-	Username          apijson.Field
-	raw               string
-	ExtraFields       map[string]apijson.Field
+	Schema             apijson.Field
+	Agent              apijson.Field
+	Autoshare          apijson.Field
+	Autoupdate         apijson.Field
+	DisabledProviders  apijson.Field
+	Experimental       apijson.Field
+	Formatter          apijson.Field
+	Instructions       apijson.Field
+	Keybinds           apijson.Field
+	Layout             apijson.Field
+	Lsp                apijson.Field
+	Mcp                apijson.Field
+	Mode               apijson.Field
+	Model              apijson.Field
+	Permission         apijson.Field
+	Plugin             apijson.Field
+	Provider           apijson.Field
+	Share              apijson.Field
+	SmallModel         apijson.Field
+	Theme              apijson.Field
+	TranscriptionModel apijson.Field
+	Tui                apijson.Field
+	Username           apijson.Field
+	raw                string
+	ExtraFields        map[string]apijson.Field
 }
 
 func (r *Config) UnmarshalJSON(data []byte) (err error) {
@@ -1039,31 +1046,647 @@ func (r ConfigShare) IsKnown() bool {
 	return false
 }
 
+// Terminal UI configuration
+type ConfigTui struct {
+	// Cost budgets per session and per day
+	Budget ConfigTuiBudget `json:"budget"`
+	// How copied text reaches the clipboard: the system clipboard, OSC 52 through the
+	// terminal, or both. auto uses the system clipboard when there is one and OSC 52
+	// as well (default auto)
+	Clipboard ConfigTuiClipboard `json:"clipboard"`
+	// Custom slash commands that send a prompt, run a shell command or run a TUI
+	// command
+	Commands map[string]ConfigTuiCommand `json:"commands"`
+	// Context window usage percentage at which to suggest compacting the session
+	// (default 80)
+	CompactThreshold float64 `json:"compact_threshold"`
+	// Custom completion providers listing the output of a shell command
+	CompletionProviders map[string]ConfigTuiCompletionProvider `json:"completion_providers"`
+	// Completions opened by typing a trigger character, replacing the default for
+	// the triggers set. Defaults: / commands, @ files, symbols, agents and git, #
+	// resources, ! shell
+	Completions map[string]ConfigTuiCompletion `json:"completions"`
+	// Icons drawn by the TUI: emoji, the glyphs of a Nerd Font, or plain ASCII for
+	// fonts without either (default emoji)
+	Icons ConfigTuiIcons `json:"icons"`
+	// Limits above which attached images are scaled down before sending
+	Images ConfigTuiImages `json:"images"`
+	// Milliseconds from a key press to the next render above which input latency is
+	// logged (default 16)
+	LatencyBudget float64 `json:"latency_budget"`
+	// Language of the TUI, like de or de-AT. Defaults to KUUZUKI_LOCALE, then LC_ALL,
+	// LC_MESSAGES and LANG, falling back to English
+	Locale string `json:"locale"`
+	// Fallback models to retry with when the provider rejects a request
+	ModelFallback ConfigTuiModelFallback `json:"model_fallback"`
+	// Desktop notifications and terminal bells for events while the terminal is
+	// unfocused
+	Notifications ConfigTuiNotifications `json:"notifications"`
+	// Show spinners still and cursors without blinking, for motion sensitivity and
+	// slow connections
+	ReduceMotion bool `json:"reduce_motion"`
+	// Guard limits that pause runaway agent turns
+	RunLimits ConfigTuiRunLimits `json:"run_limits"`
+	// Move the agent's edits to a sandbox branch, created with the session's first
+	// prompt, to merge back or discard when done
+	Sandbox bool `json:"sandbox"`
+	// Screen reader friendly output
+	ScreenReader ConfigTuiScreenReader `json:"screen_reader"`
+	// Retention policy for old sessions
+	SessionRetention ConfigTuiSessionRetention `json:"session_retention"`
+	// Status bar segments separated by spaces, with | between the left and right
+	// sides. Segments: logo, cwd, branch, keymap, agent, model, usage, context,
+	// budget, fallback, scroll_lock, follow, clock, health, tool. A branch right
+	// after cwd renders as cwd:branch
+	StatusLine string `json:"status_line"`
+	// Show the session's token usage, cost, or both in the status bar
+	StatusUsage ConfigTuiStatusUsage `json:"status_usage"`
+	// Named session templates for recurring workflows
+	Templates map[string]ConfigTuiTemplate `json:"templates"`
+	JSON      configTuiJSON                `json:"-"`
+}
+
+// configTuiJSON contains the JSON metadata for the struct [ConfigTui]
+type configTuiJSON struct {
+	Budget              apijson.Field
+	Clipboard           apijson.Field
+	Commands            apijson.Field
+	CompactThreshold    apijson.Field
+	CompletionProviders apijson.Field
+	Completions         apijson.Field
+	Icons               apijson.Field
+	Images              apijson.Field
+	LatencyBudget       apijson.Field
+	Locale              apijson.Field
+	ModelFallback       apijson.Field
+	Notifications       apijson.Field
+	ReduceMotion        apijson.Field
+	RunLimits           apijson.Field
+	Sandbox             apijson.Field
+	ScreenReader        apijson.Field
+	SessionRetention    apijson.Field
+	StatusLine          apijson.Field
+	StatusUsage         apijson.Field
+	Templates           apijson.Field
+	raw                 string
+	ExtraFields         map[string]apijson.Field
+}
+
+func (r *ConfigTui) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiJSON) RawJSON() string {
+	return r.raw
+}
+
+// Cost budgets per session and per day
+type ConfigTuiBudget struct {
+	// Ask for confirmation before sending prompts once today's sessions have cost
+	// this many dollars
+	Daily float64 `json:"daily"`
+	// Ask for confirmation before sending prompts once a session has cost this many
+	// dollars
+	Session float64 `json:"session"`
+	// Percentage of a budget at which to warn that it is running out (default 80)
+	WarnAt float64             `json:"warn_at"`
+	JSON   configTuiBudgetJSON `json:"-"`
+}
+
+// configTuiBudgetJSON contains the JSON metadata for the struct
+// [ConfigTuiBudget]
+type configTuiBudgetJSON struct {
+	Daily       apijson.Field
+	Session     apijson.Field
+	WarnAt      apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiBudget) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiBudgetJSON) RawJSON() string {
+	return r.raw
+}
+
+// How copied text reaches the clipboard: the system clipboard, OSC 52 through the
+// terminal, or both. auto uses the system clipboard when there is one and OSC 52
+// as well (default auto)
+type ConfigTuiClipboard string
+
+const (
+	ConfigTuiClipboardAuto   ConfigTuiClipboard = "auto"
+	ConfigTuiClipboardNative ConfigTuiClipboard = "native"
+	ConfigTuiClipboardOsc52  ConfigTuiClipboard = "osc52"
+	ConfigTuiClipboardBoth   ConfigTuiClipboard = "both"
+)
+
+func (r ConfigTuiClipboard) IsKnown() bool {
+	switch r {
+	case ConfigTuiClipboardAuto, ConfigTuiClipboardNative, ConfigTuiClipboardOsc52, ConfigTuiClipboardBoth:
+		return true
+	}
+	return false
+}
+
+// Icons drawn by the TUI: emoji, the glyphs of a Nerd Font, or plain ASCII for
+// fonts without either (default emoji)
+type ConfigTuiIcons string
+
+const (
+	ConfigTuiIconsEmoji    ConfigTuiIcons = "emoji"
+	ConfigTuiIconsNerdFont ConfigTuiIcons = "nerd_font"
+	ConfigTuiIconsASCII    ConfigTuiIcons = "ascii"
+)
+
+func (r ConfigTuiIcons) IsKnown() bool {
+	switch r {
+	case ConfigTuiIconsEmoji, ConfigTuiIconsNerdFont, ConfigTuiIconsASCII:
+		return true
+	}
+	return false
+}
+
+// Limits above which attached images are scaled down before sending
+type ConfigTuiImages struct {
+	// Size in bytes of attached images, larger ones are scaled down (default
+	// 3750000)
+	MaxBytes int64 `json:"max_bytes"`
+	// Longest side in pixels of attached images, larger ones are scaled down
+	// (default 1568)
+	MaxDimension int64               `json:"max_dimension"`
+	JSON         configTuiImagesJSON `json:"-"`
+}
+
+// configTuiImagesJSON contains the JSON metadata for the struct
+// [ConfigTuiImages]
+type configTuiImagesJSON struct {
+	MaxBytes     apijson.Field
+	MaxDimension apijson.Field
+	raw          string
+	ExtraFields  map[string]apijson.Field
+}
+
+func (r *ConfigTuiImages) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiImagesJSON) RawJSON() string {
+	return r.raw
+}
+
+// Fallback models to retry with when the provider rejects a request
+type ConfigTuiModelFallback struct {
+	// Retry with the next fallback model without asking first
+	Auto bool `json:"auto"`
+	// Models to fall back to in order, in the format provider/model
+	Models []string                   `json:"models,required"`
+	JSON   configTuiModelFallbackJSON `json:"-"`
+}
+
+// configTuiModelFallbackJSON contains the JSON metadata for the struct
+// [ConfigTuiModelFallback]
+type configTuiModelFallbackJSON struct {
+	Auto        apijson.Field
+	Models      apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiModelFallback) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiModelFallbackJSON) RawJSON() string {
+	return r.raw
+}
+
+// Desktop notifications and terminal bells for events while the terminal is
+// unfocused
+type ConfigTuiNotifications struct {
+	// How to notify that a long request finished while the terminal is unfocused
+	// (default desktop)
+	Complete ConfigTuiNotificationsComplete `json:"complete"`
+	// How to notify that a request failed while the terminal is unfocused (default
+	// desktop)
+	Error ConfigTuiNotificationsError `json:"error"`
+	// Seconds a request must run before its completion is notified (default 10)
+	MinSeconds float64 `json:"min_seconds"`
+	// How to notify that a tool needs approval while the terminal is unfocused
+	// (default desktop)
+	Permission ConfigTuiNotificationsPermission `json:"permission"`
+	JSON       configTuiNotificationsJSON       `json:"-"`
+}
+
+// configTuiNotificationsJSON contains the JSON metadata for the struct
+// [ConfigTuiNotifications]
+type configTuiNotificationsJSON struct {
+	Complete    apijson.Field
+	Error       apijson.Field
+	MinSeconds  apijson.Field
+	Permission  apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiNotifications) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiNotificationsJSON) RawJSON() string {
+	return r.raw
+}
+
+// How to notify that a long request finished while the terminal is unfocused (default desktop)
+type ConfigTuiNotificationsComplete string
+
+const (
+	ConfigTuiNotificationsCompleteOff     ConfigTuiNotificationsComplete = "off"
+	ConfigTuiNotificationsCompleteBell    ConfigTuiNotificationsComplete = "bell"
+	ConfigTuiNotificationsCompleteDesktop ConfigTuiNotificationsComplete = "desktop"
+	ConfigTuiNotificationsCompleteBoth    ConfigTuiNotificationsComplete = "both"
+)
+
+func (r ConfigTuiNotificationsComplete) IsKnown() bool {
+	switch r {
+	case ConfigTuiNotificationsCompleteOff, ConfigTuiNotificationsCompleteBell, ConfigTuiNotificationsCompleteDesktop, ConfigTuiNotificationsCompleteBoth:
+		return true
+	}
+	return false
+}
+
+// How to notify that a request failed while the terminal is unfocused (default desktop)
+type ConfigTuiNotificationsError string
+
+const (
+	ConfigTuiNotificationsErrorOff     ConfigTuiNotificationsError = "off"
+	ConfigTuiNotificationsErrorBell    ConfigTuiNotificationsError = "bell"
+	ConfigTuiNotificationsErrorDesktop ConfigTuiNotificationsError = "desktop"
+	ConfigTuiNotificationsErrorBoth    ConfigTuiNotificationsError = "both"
+)
+
+func (r ConfigTuiNotificationsError) IsKnown() bool {
+	switch r {
+	case ConfigTuiNotificationsErrorOff, ConfigTuiNotificationsErrorBell, ConfigTuiNotificationsErrorDesktop, ConfigTuiNotificationsErrorBoth:
+		return true
+	}
+	return false
+}
+
+// How to notify that a tool needs approval while the terminal is unfocused (default desktop)
+type ConfigTuiNotificationsPermission string
+
+const (
+	ConfigTuiNotificationsPermissionOff     ConfigTuiNotificationsPermission = "off"
+	ConfigTuiNotificationsPermissionBell    ConfigTuiNotificationsPermission = "bell"
+	ConfigTuiNotificationsPermissionDesktop ConfigTuiNotificationsPermission = "desktop"
+	ConfigTuiNotificationsPermissionBoth    ConfigTuiNotificationsPermission = "both"
+)
+
+func (r ConfigTuiNotificationsPermission) IsKnown() bool {
+	switch r {
+	case ConfigTuiNotificationsPermissionOff, ConfigTuiNotificationsPermissionBell, ConfigTuiNotificationsPermissionDesktop, ConfigTuiNotificationsPermissionBoth:
+		return true
+	}
+	return false
+}
+
+// Guard limits that pause runaway agent turns
+type ConfigTuiRunLimits struct {
+	// Pause the session after a single turn has cost this many dollars
+	MaxCost float64 `json:"max_cost"`
+	// Pause the session after a single turn has run for this many seconds
+	MaxSeconds int64 `json:"max_seconds"`
+	// Pause the session after this many tool calls in a single turn
+	MaxToolCalls int64                  `json:"max_tool_calls"`
+	JSON         configTuiRunLimitsJSON `json:"-"`
+}
+
+// configTuiRunLimitsJSON contains the JSON metadata for the struct
+// [ConfigTuiRunLimits]
+type configTuiRunLimitsJSON struct {
+	MaxCost      apijson.Field
+	MaxSeconds   apijson.Field
+	MaxToolCalls apijson.Field
+	raw          string
+	ExtraFields  map[string]apijson.Field
+}
+
+func (r *ConfigTuiRunLimits) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiRunLimitsJSON) RawJSON() string {
+	return r.raw
+}
+
+// Screen reader friendly output
+type ConfigTuiScreenReader struct {
+	// Keep the alternate screen, by default the TUI runs in the terminal's own screen
+	// so what it prints stays in the scrollback
+	AltScreen bool `json:"alt_screen"`
+	// Print new messages, tool calls and notifications as plain lines for screen
+	// readers, without spinners or overlays
+	Enabled bool                      `json:"enabled"`
+	JSON    configTuiScreenReaderJSON `json:"-"`
+}
+
+// configTuiScreenReaderJSON contains the JSON metadata for the struct
+// [ConfigTuiScreenReader]
+type configTuiScreenReaderJSON struct {
+	AltScreen   apijson.Field
+	Enabled     apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiScreenReader) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiScreenReaderJSON) RawJSON() string {
+	return r.raw
+}
+
+// Retention policy for old sessions
+type ConfigTuiSessionRetention struct {
+	// Delete sessions that have not been updated in this many days
+	MaxAgeDays int64 `json:"max_age_days"`
+	// Keep at most this many sessions, deleting the oldest first
+	MaxCount int64 `json:"max_count"`
+	// Automatically delete expired sessions when the TUI starts
+	PruneOnStartup bool                          `json:"prune_on_startup"`
+	JSON           configTuiSessionRetentionJSON `json:"-"`
+}
+
+// configTuiSessionRetentionJSON contains the JSON metadata for the struct
+// [ConfigTuiSessionRetention]
+type configTuiSessionRetentionJSON struct {
+	MaxAgeDays     apijson.Field
+	MaxCount       apijson.Field
+	PruneOnStartup apijson.Field
+	raw            string
+	ExtraFields    map[string]apijson.Field
+}
+
+func (r *ConfigTuiSessionRetention) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiSessionRetentionJSON) RawJSON() string {
+	return r.raw
+}
+
+// Show the session's token usage, cost, or both in the status bar
+type ConfigTuiStatusUsage string
+
+const (
+	ConfigTuiStatusUsageTokens ConfigTuiStatusUsage = "tokens"
+	ConfigTuiStatusUsageCost   ConfigTuiStatusUsage = "cost"
+	ConfigTuiStatusUsageBoth   ConfigTuiStatusUsage = "both"
+)
+
+func (r ConfigTuiStatusUsage) IsKnown() bool {
+	switch r {
+	case ConfigTuiStatusUsageTokens, ConfigTuiStatusUsageCost, ConfigTuiStatusUsageBoth:
+		return true
+	}
+	return false
+}
+
+type ConfigTuiCommand struct {
+	// Description shown next to the command in completions
+	Description string `json:"description"`
+	// Place the prompt in the editor instead of sending it
+	Edit bool `json:"edit"`
+	// Keybind that runs the command
+	Keybind string `json:"keybind"`
+	// Prompt sent to the agent, with $ARGUMENTS replaced by the text after the
+	// command. It is placed in the editor instead when edit is set or it needs
+	// arguments none were given
+	Prompt string `json:"prompt"`
+	// TUI command to run, by its keybind name, e.g. tool_details
+	Run string `json:"run"`
+	// Shell command to run, with $ARGUMENTS replaced by the text after the command
+	Shell string               `json:"shell"`
+	JSON  configTuiCommandJSON `json:"-"`
+}
+
+// configTuiCommandJSON contains the JSON metadata for the struct
+// [ConfigTuiCommand]
+type configTuiCommandJSON struct {
+	Description apijson.Field
+	Edit        apijson.Field
+	Keybind     apijson.Field
+	Prompt      apijson.Field
+	Run         apijson.Field
+	Shell       apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiCommand) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiCommandJSON) RawJSON() string {
+	return r.raw
+}
+
+type ConfigTuiCompletion struct {
+	// Providers of the suggestions: commands, files, symbols, agents, git, resources,
+	// shell, or the name of a provider in completion_providers. Empty turns the
+	// trigger off
+	Providers []string `json:"providers,required"`
+	// Where the trigger opens the completion: in an empty editor, at the start of a
+	// word, or anywhere (default word)
+	Position ConfigTuiCompletionsPosition `json:"position"`
+	// Keep the completion open when a space is typed, for completing whole commands
+	Spaces bool                    `json:"spaces"`
+	JSON   configTuiCompletionJSON `json:"-"`
+}
+
+// configTuiCompletionJSON contains the JSON metadata for the struct
+// [ConfigTuiCompletion]
+type configTuiCompletionJSON struct {
+	Providers   apijson.Field
+	Position    apijson.Field
+	Spaces      apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiCompletion) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiCompletionJSON) RawJSON() string {
+	return r.raw
+}
+
+// Where the trigger opens the completion: in an empty editor, at the start of a
+// word, or anywhere (default word)
+type ConfigTuiCompletionsPosition string
+
+const (
+	ConfigTuiCompletionsPositionLine     ConfigTuiCompletionsPosition = "line"
+	ConfigTuiCompletionsPositionWord     ConfigTuiCompletionsPosition = "word"
+	ConfigTuiCompletionsPositionAnywhere ConfigTuiCompletionsPosition = "anywhere"
+)
+
+func (r ConfigTuiCompletionsPosition) IsKnown() bool {
+	switch r {
+	case ConfigTuiCompletionsPositionLine, ConfigTuiCompletionsPositionWord, ConfigTuiCompletionsPositionAnywhere:
+		return true
+	}
+	return false
+}
+
+type ConfigTuiCompletionProvider struct {
+	// Shell command printing a suggestion a line, optionally followed by a tab and a
+	// description. The text typed after the trigger is in $QUERY
+	Shell string                          `json:"shell,required"`
+	JSON  configTuiCompletionProviderJSON `json:"-"`
+}
+
+// configTuiCompletionProviderJSON contains the JSON metadata for the struct
+// [ConfigTuiCompletionProvider]
+type configTuiCompletionProviderJSON struct {
+	Shell       apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiCompletionProvider) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiCompletionProviderJSON) RawJSON() string {
+	return r.raw
+}
+
+type ConfigTuiTemplate struct {
+	// Agent to switch to
+	Agent string `json:"agent"`
+	// Short description shown in the template picker
+	Description string `json:"description"`
+	// Files attached to the initial prompt
+	Files []string `json:"files"`
+	// Model to use in the format of provider/model
+	Model string `json:"model"`
+	// Initial prompt placed in the editor
+	Prompt string `json:"prompt"`
+	// Additional system prompt for sessions started from this template
+	System string                `json:"system"`
+	JSON   configTuiTemplateJSON `json:"-"`
+}
+
+// configTuiTemplateJSON contains the JSON metadata for the struct
+// [ConfigTuiTemplate]
+type configTuiTemplateJSON struct {
+	Agent       apijson.Field
+	Description apijson.Field
+	Files       apijson.Field
+	Model       apijson.Field
+	Prompt      apijson.Field
+	System      apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigTuiTemplate) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configTuiTemplateJSON) RawJSON() string {
+	return r.raw
+}
+
 type KeybindsConfig struct {
 	// Exit the application
 	AppExit string `json:"app_exit,required"`
 	// Show help dialog
 	AppHelp string `json:"app_help,required"`
+	// List recent commands
+	CommandHistory string `json:"command_history,required"`
+	// Repeat the last command
+	CommandRepeat string `json:"command_repeat,required"`
 	// Open external editor
 	EditorOpen string `json:"editor_open,required"`
+	// Open the file viewed before
+	FileBack string `json:"file_back,required"`
+	// Scroll to the bottom of the focused file
+	FileBottom string `json:"file_bottom,required"`
 	// Close file
 	FileClose string `json:"file_close,required"`
+	// Go to the definition of the clicked name in the file viewer
+	FileDefinition string `json:"file_definition,required"`
 	// Split/unified diff
 	FileDiffToggle string `json:"file_diff_toggle,required"`
+	// Open the file in the IDE or EDITOR at the line in view
+	FileEdit string `json:"file_edit,required"`
+	// Search the text of the file
+	FileFind string `json:"file_find,required"`
+	// Next match in the file
+	FileFindNext string `json:"file_find_next,required"`
+	// Previous match in the file
+	FileFindPrevious string `json:"file_find_previous,required"`
+	// Move focus between the messages and file panes
+	FileFocus string `json:"file_focus,required"`
+	// Open the file viewed after going back
+	FileForward string `json:"file_forward,required"`
+	// Go to a line of the file
+	FileGotoLine string `json:"file_goto_line,required"`
+	// Widen the file pane
+	FileGrow string `json:"file_grow,required"`
+	// Scroll the focused file down by half page
+	FileHalfPageDown string `json:"file_half_page_down,required"`
+	// Scroll the focused file up by half page
+	FileHalfPageUp string `json:"file_half_page_up,required"`
+	// Select the next hunk in the file viewer
+	FileHunkNext string `json:"file_hunk_next,required"`
+	// Select the previous hunk in the file viewer
+	FileHunkPrevious string `json:"file_hunk_previous,required"`
+	// Stage or unstage the selected hunk in the file viewer
+	FileHunkStage string `json:"file_hunk_stage,required"`
+	// Scroll the focused file down by one line
+	FileLineDown string `json:"file_line_down,required"`
+	// Scroll the focused file up by one line
+	FileLineUp string `json:"file_line_up,required"`
 	// List files
 	FileList string `json:"file_list,required"`
+	// Scroll the focused file down by one page
+	FilePageDown string `json:"file_page_down,required"`
+	// Scroll the focused file up by one page
+	FilePageUp string `json:"file_page_up,required"`
 	// Search file
 	FileSearch string `json:"file_search,required"`
+	// Narrow the file pane
+	FileShrink string `json:"file_shrink,required"`
+	// Scroll to the top of the focused file
+	FileTop string `json:"file_top,required"`
+	// Browse the file tree
+	FileTree string `json:"file_tree,required"`
+	// List the files viewed recently
+	FileViewed string `json:"file_viewed,required"`
 	// Clear input field
 	InputClear string `json:"input_clear,required"`
 	// Insert newline in input
 	InputNewline string `json:"input_newline,required"`
+	// Open the attachment under the cursor in the file viewer
+	InputOpenAttachment string `json:"input_open_attachment,required"`
 	// Paste from clipboard
 	InputPaste string `json:"input_paste,required"`
+	// Preview the attachment at the cursor
+	InputPreview string `json:"input_preview,required"`
 	// Submit input
 	InputSubmit string `json:"input_submit,required"`
 	// Leader key for keybind combinations
 	Leader string `json:"leader,required"`
+	// Scroll focused messages to the bottom
+	MessagesBottom string `json:"messages_bottom,required"`
 	// Copy message
 	MessagesCopy string `json:"messages_copy,required"`
 	// Navigate to first message
@@ -1076,6 +1699,10 @@ type KeybindsConfig struct {
 	MessagesLast string `json:"messages_last,required"`
 	// Toggle layout
 	MessagesLayoutToggle string `json:"messages_layout_toggle,required"`
+	// Scroll focused messages down by one line
+	MessagesLineDown string `json:"messages_line_down,required"`
+	// Scroll focused messages up by one line
+	MessagesLineUp string `json:"messages_line_up,required"`
 	// Navigate to next message
 	MessagesNext string `json:"messages_next,required"`
 	// Scroll messages down by one page
@@ -1086,14 +1713,26 @@ type KeybindsConfig struct {
 	MessagesPrevious string `json:"messages_previous,required"`
 	// Redo message
 	MessagesRedo string `json:"messages_redo,required"`
+	// Retry last message
+	MessagesRetry string `json:"messages_retry,required"`
 	// @deprecated use messages_undo. Revert message
 	MessagesRevert string `json:"messages_revert,required"`
+	// Toggle following new output
+	MessagesScrollLock string `json:"messages_scroll_lock,required"`
+	// Scroll focused messages to the top
+	MessagesTop string `json:"messages_top,required"`
 	// Undo message
 	MessagesUndo string `json:"messages_undo,required"`
 	// List available models
 	ModelList string `json:"model_list,required"`
+	// List recent notifications
+	NotificationList string `json:"notification_list,required"`
 	// Create/update AGENTS.md
 	ProjectInit string `json:"project_init,required"`
+	// Merge back or discard the sandbox branch
+	SandboxFinish string `json:"sandbox_finish,required"`
+	// Toggle scratchpad
+	ScratchpadToggle string `json:"scratchpad_toggle,required"`
 	// Compact the session
 	SessionCompact string `json:"session_compact,required"`
 	// Export session to editor
@@ -1108,6 +1747,10 @@ type KeybindsConfig struct {
 	SessionShare string `json:"session_share,required"`
 	// Unshare current session
 	SessionUnshare string `json:"session_unshare,required"`
+	// Toggle shell mode
+	ShellMode string `json:"shell_mode,required"`
+	// Show the output of the last shell command
+	ShellOutput string `json:"shell_output,required"`
 	// Next agent
 	SwitchAgent string `json:"switch_agent,required"`
 	// Previous agent
@@ -1118,40 +1761,81 @@ type KeybindsConfig struct {
 	SwitchModeReverse string `json:"switch_mode_reverse,required"`
 	// List available themes
 	ThemeList string `json:"theme_list,required"`
+	// Run the action of the latest toast
+	ToastAction string `json:"toast_action,required"`
 	// Toggle tool details
-	ToolDetails string             `json:"tool_details,required"`
-	JSON        keybindsConfigJSON `json:"-"`
+	ToolDetails string `json:"tool_details,required"`
+	// Attach the last tool output to the prompt
+	ToolOutputAttach string             `json:"tool_output_attach,required"`
+	JSON             keybindsConfigJSON `json:"-"`
 }
 
 // keybindsConfigJSON contains the JSON metadata for the struct [KeybindsConfig]
 type keybindsConfigJSON struct {
 	AppExit              apijson.Field
 	AppHelp              apijson.Field
+	CommandHistory       apijson.Field
+	CommandRepeat        apijson.Field
 	EditorOpen           apijson.Field
+	FileBack             apijson.Field
+	FileBottom           apijson.Field
 	FileClose            apijson.Field
+	FileDefinition       apijson.Field
 	FileDiffToggle       apijson.Field
+	FileEdit             apijson.Field
+	FileFind             apijson.Field
+	FileFindNext         apijson.Field
+	FileFindPrevious     apijson.Field
+	FileFocus            apijson.Field
+	FileForward          apijson.Field
+	FileGotoLine         apijson.Field
+	FileGrow             apijson.Field
+	FileHalfPageDown     apijson.Field
+	FileHalfPageUp       apijson.Field
+	FileHunkNext         apijson.Field
+	FileHunkPrevious     apijson.Field
+	FileHunkStage        apijson.Field
+	FileLineDown         apijson.Field
+	FileLineUp           apijson.Field
 	FileList             apijson.Field
+	FilePageDown         apijson.Field
+	FilePageUp           apijson.Field
 	FileSearch           apijson.Field
+	FileShrink           apijson.Field
+	FileTop              apijson.Field
+	FileTree             apijson.Field
+	FileViewed           apijson.Field
 	InputClear           apijson.Field
 	InputNewline         apijson.Field
+	InputOpenAttachment  apijson.Field
 	InputPaste           apijson.Field
+	InputPreview         apijson.Field
 	InputSubmit          apijson.Field
 	Leader               apijson.Field
+	MessagesBottom       apijson.Field
 	MessagesCopy         apijson.Field
 	MessagesFirst        apijson.Field
 	MessagesHalfPageDown apijson.Field
 	MessagesHalfPageUp   apijson.Field
 	MessagesLast         apijson.Field
 	MessagesLayoutToggle apijson.Field
+	MessagesLineDown     apijson.Field
+	MessagesLineUp       apijson.Field
 	MessagesNext         apijson.Field
 	MessagesPageDown     apijson.Field
 	MessagesPageUp       apijson.Field
 	MessagesPrevious     apijson.Field
 	MessagesRedo         apijson.Field
+	MessagesRetry        apijson.Field
 	MessagesRevert       apijson.Field
+	MessagesScrollLock   apijson.Field
+	MessagesTop          apijson.Field
 	MessagesUndo         apijson.Field
 	ModelList            apijson.Field
+	NotificationList     apijson.Field
 	ProjectInit          apijson.Field
+	SandboxFinish        apijson.Field
+	ScratchpadToggle     apijson.Field
 	SessionCompact       apijson.Field
 	SessionExport        apijson.Field
 	SessionInterrupt     apijson.Field
@@ -1159,12 +1843,16 @@ type keybindsConfigJSON struct {
 	SessionNew           apijson.Field
 	SessionShare         apijson.Field
 	SessionUnshare       apijson.Field
+	ShellMode            apijson.Field
+	ShellOutput          apijson.Field
 	SwitchAgent          apijson.Field
 	SwitchAgentReverse   apijson.Field
 	SwitchMode           apijson.Field
 	SwitchModeReverse    apijson.Field
 	ThemeList            apijson.Field
+	ToastAction          apijson.Field
 	ToolDetails          apijson.Field
+	ToolOutputAttach     apijson.Field
 	raw                  string
 	ExtraFields          map[string]apijson.Field
 }
@@ -62,7 +62,9 @@ type EventListResponse struct {
 	// [EventListResponseEventSessionIdleProperties],
 	// [EventListResponseEventSessionErrorProperties],
 	// [EventListResponseEventFileWatcherUpdatedProperties],
-	// [EventListResponseEventIdeInstalledProperties].
+	// [EventListResponseEventIdeInstalledProperties],
+	// [EventListResponseEventTuiInputRequestedProperties],
+	// [EventListResponseEventTuiInputRepliedProperties].
 	Properties interface{}           `json:"properties,required"`
 	Type       EventListResponseType `json:"type,required"`
 	JSON       eventListResponseJSON `json:"-"`
@@ -107,7 +109,9 @@ func (r *EventListResponse) UnmarshalJSON(data []byte) (err error) {
 // [EventListResponseEventSessionUpdated], [EventListResponseEventSessionDeleted],
 // [EventListResponseEventSessionIdle], [EventListResponseEventSessionError],
 // [EventListResponseEventFileWatcherUpdated],
-// [EventListResponseEventIdeInstalled].
+// [EventListResponseEventIdeInstalled],
+// [EventListResponseEventTuiInputRequested],
+// [EventListResponseEventTuiInputReplied].
 func (r EventListResponse) AsUnion() EventListResponseUnion {
 	return r.union
 }
@@ -123,8 +127,10 @@ func (r EventListResponse) AsUnion() EventListResponseUnion {
 // [EventListResponseEventPermissionReplied],
 // [EventListResponseEventSessionUpdated], [EventListResponseEventSessionDeleted],
 // [EventListResponseEventSessionIdle], [EventListResponseEventSessionError],
-// [EventListResponseEventFileWatcherUpdated] or
-// [EventListResponseEventIdeInstalled].
+// [EventListResponseEventFileWatcherUpdated],
+// [EventListResponseEventIdeInstalled],
+// [EventListResponseEventTuiInputRequested] or
+// [EventListResponseEventTuiInputReplied].
 type EventListResponseUnion interface {
 	implementsEventListResponse()
 }
@@ -218,6 +224,16 @@ func init() {
 			Type:               reflect.TypeOf(EventListResponseEventIdeInstalled{}),
 			DiscriminatorValue: "ide.installed",
 		},
+		apijson.UnionVariant{
+			TypeFilter:         gjson.JSON,
+			Type:               reflect.TypeOf(EventListResponseEventTuiInputRequested{}),
+			DiscriminatorValue: "tui.input.requested",
+		},
+		apijson.UnionVariant{
+			TypeFilter:         gjson.JSON,
+			Type:               reflect.TypeOf(EventListResponseEventTuiInputReplied{}),
+			DiscriminatorValue: "tui.input.replied",
+		},
 	)
 }
 
@@ -677,14 +693,16 @@ func (r eventListResponseEventFileEditedJSON) RawJSON() string {
 func (r EventListResponseEventFileEdited) implementsEventListResponse() {}
 
 type EventListResponseEventFileEditedProperties struct {
-	File string                                         `json:"file,required"`
-	JSON eventListResponseEventFileEditedPropertiesJSON `json:"-"`
+	File      string                                         `json:"file,required"`
+	SessionID string                                         `json:"sessionID"`
+	JSON      eventListResponseEventFileEditedPropertiesJSON `json:"-"`
 }
 
 // eventListResponseEventFileEditedPropertiesJSON contains the JSON metadata for
 // the struct [EventListResponseEventFileEditedProperties]
 type eventListResponseEventFileEditedPropertiesJSON struct {
 	File        apijson.Field
+	SessionID   apijson.Field
 	raw         string
 	ExtraFields map[string]apijson.Field
 }
@@ -815,10 +833,11 @@ func (r eventListResponseEventPermissionRepliedJSON) RawJSON() string {
 func (r EventListResponseEventPermissionReplied) implementsEventListResponse() {}
 
 type EventListResponseEventPermissionRepliedProperties struct {
-	PermissionID string                                                `json:"permissionID,required"`
-	Response     string                                                `json:"response,required"`
-	SessionID    string                                                `json:"sessionID,required"`
-	JSON         eventListResponseEventPermissionRepliedPropertiesJSON `json:"-"`
+	PermissionID string                                                  `json:"permissionID,required"`
+	Response     string                                                  `json:"response,required"`
+	SessionID    string                                                  `json:"sessionID,required"`
+	Source       EventListResponseEventPermissionRepliedPropertiesSource `json:"source"`
+	JSON         eventListResponseEventPermissionRepliedPropertiesJSON   `json:"-"`
 }
 
 // eventListResponseEventPermissionRepliedPropertiesJSON contains the JSON metadata
@@ -827,6 +846,7 @@ type eventListResponseEventPermissionRepliedPropertiesJSON struct {
 	PermissionID apijson.Field
 	Response     apijson.Field
 	SessionID    apijson.Field
+	Source       apijson.Field
 	raw          string
 	ExtraFields  map[string]apijson.Field
 }
@@ -839,6 +859,21 @@ func (r eventListResponseEventPermissionRepliedPropertiesJSON) RawJSON() string
 	return r.raw
 }
 
+type EventListResponseEventPermissionRepliedPropertiesSource string
+
+const (
+	EventListResponseEventPermissionRepliedPropertiesSourceLocal  EventListResponseEventPermissionRepliedPropertiesSource = "local"
+	EventListResponseEventPermissionRepliedPropertiesSourceRemote EventListResponseEventPermissionRepliedPropertiesSource = "remote"
+)
+
+func (r EventListResponseEventPermissionRepliedPropertiesSource) IsKnown() bool {
+	switch r {
+	case EventListResponseEventPermissionRepliedPropertiesSourceLocal, EventListResponseEventPermissionRepliedPropertiesSourceRemote:
+		return true
+	}
+	return false
+}
+
 type EventListResponseEventPermissionRepliedType string
 
 const (
@@ -1366,6 +1401,136 @@ func (r EventListResponseEventIdeInstalledType) IsKnown() bool {
 	return false
 }
 
+type EventListResponseEventTuiInputRequested struct {
+	Properties EventListResponseEventTuiInputRequestedProperties `json:"properties,required"`
+	Type       EventListResponseEventTuiInputRequestedType       `json:"type,required"`
+	JSON       eventListResponseEventTuiInputRequestedJSON       `json:"-"`
+}
+
+// eventListResponseEventTuiInputRequestedJSON contains the JSON metadata for the
+// struct [EventListResponseEventTuiInputRequested]
+type eventListResponseEventTuiInputRequestedJSON struct {
+	Properties  apijson.Field
+	Type        apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *EventListResponseEventTuiInputRequested) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r eventListResponseEventTuiInputRequestedJSON) RawJSON() string {
+	return r.raw
+}
+
+func (r EventListResponseEventTuiInputRequested) implementsEventListResponse() {}
+
+type EventListResponseEventTuiInputRequestedProperties struct {
+	ID          string                                                `json:"id,required"`
+	Prompt      string                                                `json:"prompt,required"`
+	Masked      bool                                                  `json:"masked"`
+	Placeholder string                                                `json:"placeholder"`
+	SessionID   string                                                `json:"sessionID"`
+	JSON        eventListResponseEventTuiInputRequestedPropertiesJSON `json:"-"`
+}
+
+// eventListResponseEventTuiInputRequestedPropertiesJSON contains the JSON metadata
+// for the struct [EventListResponseEventTuiInputRequestedProperties]
+type eventListResponseEventTuiInputRequestedPropertiesJSON struct {
+	ID          apijson.Field
+	Prompt      apijson.Field
+	Masked      apijson.Field
+	Placeholder apijson.Field
+	SessionID   apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *EventListResponseEventTuiInputRequestedProperties) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r eventListResponseEventTuiInputRequestedPropertiesJSON) RawJSON() string {
+	return r.raw
+}
+
+type EventListResponseEventTuiInputRequestedType string
+
+const (
+	EventListResponseEventTuiInputRequestedTypeTuiInputRequested EventListResponseEventTuiInputRequestedType = "tui.input.requested"
+)
+
+func (r EventListResponseEventTuiInputRequestedType) IsKnown() bool {
+	switch r {
+	case EventListResponseEventTuiInputRequestedTypeTuiInputRequested:
+		return true
+	}
+	return false
+}
+
+type EventListResponseEventTuiInputReplied struct {
+	Properties EventListResponseEventTuiInputRepliedProperties `json:"properties,required"`
+	Type       EventListResponseEventTuiInputRepliedType       `json:"type,required"`
+	JSON       eventListResponseEventTuiInputRepliedJSON       `json:"-"`
+}
+
+// eventListResponseEventTuiInputRepliedJSON contains the JSON metadata for the
+// struct [EventListResponseEventTuiInputReplied]
+type eventListResponseEventTuiInputRepliedJSON struct {
+	Properties  apijson.Field
+	Type        apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *EventListResponseEventTuiInputReplied) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r eventListResponseEventTuiInputRepliedJSON) RawJSON() string {
+	return r.raw
+}
+
+func (r EventListResponseEventTuiInputReplied) implementsEventListResponse() {}
+
+type EventListResponseEventTuiInputRepliedProperties struct {
+	Cancelled bool                                                `json:"cancelled,required"`
+	ID        string                                              `json:"id,required"`
+	JSON      eventListResponseEventTuiInputRepliedPropertiesJSON `json:"-"`
+}
+
+// eventListResponseEventTuiInputRepliedPropertiesJSON contains the JSON metadata
+// for the struct [EventListResponseEventTuiInputRepliedProperties]
+type eventListResponseEventTuiInputRepliedPropertiesJSON struct {
+	Cancelled   apijson.Field
+	ID          apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *EventListResponseEventTuiInputRepliedProperties) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r eventListResponseEventTuiInputRepliedPropertiesJSON) RawJSON() string {
+	return r.raw
+}
+
+type EventListResponseEventTuiInputRepliedType string
+
+const (
+	EventListResponseEventTuiInputRepliedTypeTuiInputReplied EventListResponseEventTuiInputRepliedType = "tui.input.replied"
+)
+
+func (r EventListResponseEventTuiInputRepliedType) IsKnown() bool {
+	switch r {
+	case EventListResponseEventTuiInputRepliedTypeTuiInputReplied:
+		return true
+	}
+	return false
+}
+
 type EventListResponseType string
 
 const (
@@ -1386,11 +1551,13 @@ const (
 	EventListResponseTypeSessionError         EventListResponseType = "session.error"
 	EventListResponseTypeFileWatcherUpdated   EventListResponseType = "file.watcher.updated"
 	EventListResponseTypeIdeInstalled         EventListResponseType = "ide.installed"
+	EventListResponseTypeTuiInputRequested    EventListResponseType = "tui.input.requested"
+	EventListResponseTypeTuiInputReplied      EventListResponseType = "tui.input.replied"
 )
 
 func (r EventListResponseType) IsKnown() bool {
 	switch r {
-	case EventListResponseTypeInstallationUpdated, EventListResponseTypeLspClientDiagnostics, EventListResponseTypeMessageUpdated, EventListResponseTypeMessageRemoved, EventListResponseTypeMessagePartUpdated, EventListResponseTypeMessagePartRemoved, EventListResponseTypeStorageWrite, EventListResponseTypeFileEdited, EventListResponseTypeServerConnected, EventListResponseTypePermissionUpdated, EventListResponseTypePermissionReplied, EventListResponseTypeSessionUpdated, EventListResponseTypeSessionDeleted, EventListResponseTypeSessionIdle, EventListResponseTypeSessionError, EventListResponseTypeFileWatcherUpdated, EventListResponseTypeIdeInstalled:
+	case EventListResponseTypeInstallationUpdated, EventListResponseTypeLspClientDiagnostics, EventListResponseTypeMessageUpdated, EventListResponseTypeMessageRemoved, EventListResponseTypeMessagePartUpdated, EventListResponseTypeMessagePartRemoved, EventListResponseTypeStorageWrite, EventListResponseTypeFileEdited, EventListResponseTypeServerConnected, EventListResponseTypePermissionUpdated, EventListResponseTypePermissionReplied, EventListResponseTypeSessionUpdated, EventListResponseTypeSessionDeleted, EventListResponseTypeSessionIdle, EventListResponseTypeSessionError, EventListResponseTypeFileWatcherUpdated, EventListResponseTypeIdeInstalled, EventListResponseTypeTuiInputRequested, EventListResponseTypeTuiInputReplied:
 		return true
 	}
 	return false
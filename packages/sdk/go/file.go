@@ -41,6 +41,22 @@ func (r *FileService) Read(ctx context.Context, query FileReadParams, opts ...op
 	return
 }
 
+// Apply a unified diff to the working tree
+func (r *FileService) Patch(ctx context.Context, body FilePatchParams, opts ...option.RequestOption) (res *[]string, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "file/patch"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
+// Transcribe an audio file with the transcription model
+func (r *FileService) Transcribe(ctx context.Context, body FileTranscribeParams, opts ...option.RequestOption) (res *string, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "file/transcribe"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 // Get file status
 func (r *FileService) Status(ctx context.Context, opts ...option.RequestOption) (res *[]File, err error) {
 	opts = append(r.Options[:], opts...)
@@ -94,7 +110,11 @@ func (r FileStatus) IsKnown() bool {
 type FileReadResponse struct {
 	Content string               `json:"content,required"`
 	Type    FileReadResponseType `json:"type,required"`
-	JSON    fileReadResponseJSON `json:"-"`
+	// First line of the content, for paged reads
+	Offset int64 `json:"offset"`
+	// Number of lines in the file, for paged reads
+	Total int64                `json:"total"`
+	JSON  fileReadResponseJSON `json:"-"`
 }
 
 // fileReadResponseJSON contains the JSON metadata for the struct
@@ -102,6 +122,8 @@ type FileReadResponse struct {
 type fileReadResponseJSON struct {
 	Content     apijson.Field
 	Type        apijson.Field
+	Offset      apijson.Field
+	Total       apijson.Field
 	raw         string
 	ExtraFields map[string]apijson.Field
 }
@@ -131,6 +153,10 @@ func (r FileReadResponseType) IsKnown() bool {
 
 type FileReadParams struct {
 	Path param.Field[string] `query:"path,required"`
+	// Maximum number of lines to read, large files are read a page at a time
+	Limit param.Field[int64] `query:"limit"`
+	// Line to start reading from when paging
+	Offset param.Field[int64] `query:"offset"`
 }
 
 // URLQuery serializes [FileReadParams]'s query parameters as `url.Values`.
@@ -140,3 +166,21 @@ func (r FileReadParams) URLQuery() (v url.Values) {
 		NestedFormat: apiquery.NestedQueryFormatBrackets,
 	})
 }
+
+type FilePatchParams struct {
+	Patch param.Field[string] `json:"patch,required"`
+}
+
+func (r FilePatchParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
+type FileTranscribeParams struct {
+	// The audio, base64 encoded
+	Audio param.Field[string] `json:"audio,required"`
+	Mime  param.Field[string] `json:"mime,required"`
+}
+
+func (r FileTranscribeParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
@@ -37,6 +37,30 @@ func TestFileRead(t *testing.T) {
 	}
 }
 
+func TestFilePatch(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.File.Patch(context.TODO(), kuuzuki.FilePatchParams{
+		Patch: kuuzuki.F("patch"),
+	})
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
+
 func TestFileStatus(t *testing.T) {
 	t.Skip("skipped: tests are disabled for the time being")
 	baseURL := "http://localhost:4010"
@@ -58,3 +82,28 @@ func TestFileStatus(t *testing.T) {
 		t.Fatalf("err should be nil: %s", err.Error())
 	}
 }
+
+func TestFileTranscribe(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.File.Transcribe(context.TODO(), kuuzuki.FileTranscribeParams{
+		Audio: kuuzuki.F("audio"),
+		Mime:  kuuzuki.F("mime"),
+	})
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
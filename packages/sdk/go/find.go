@@ -33,6 +33,14 @@ func NewFindService(opts ...option.RequestOption) (r *FindService) {
 	return
 }
 
+// Find the definition of the symbol at a position in a file
+func (r *FindService) Definition(ctx context.Context, query FindDefinitionParams, opts ...option.RequestOption) (res *[]SymbolLocation, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "find/definition"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, query, &res, opts...)
+	return
+}
+
 // Find files
 func (r *FindService) Files(ctx context.Context, query FindFilesParams, opts ...option.RequestOption) (res *[]string, err error) {
 	opts = append(r.Options[:], opts...)
@@ -289,6 +297,21 @@ func (r findTextResponseSubmatchesMatchJSON) RawJSON() string {
 	return r.raw
 }
 
+type FindDefinitionParams struct {
+	// Character is the 0-based UTF-16 offset in the line
+	Character param.Field[int64]  `query:"character,required"`
+	Line      param.Field[int64]  `query:"line,required"`
+	Path      param.Field[string] `query:"path,required"`
+}
+
+// URLQuery serializes [FindDefinitionParams]'s query parameters as `url.Values`.
+func (r FindDefinitionParams) URLQuery() (v url.Values) {
+	return apiquery.MarshalWithSettings(r, apiquery.QuerySettings{
+		ArrayFormat:  apiquery.ArrayQueryFormatComma,
+		NestedFormat: apiquery.NestedQueryFormatBrackets,
+	})
+}
+
 type FindFilesParams struct {
 	Query param.Field[string] `query:"query,required"`
 }
@@ -0,0 +1,91 @@
+// File generated from our OpenAPI spec by Stainless. See CONTRIBUTING.md for details.
+
+package kuuzuki
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/sst/opencode-sdk-go/internal/apijson"
+	"github.com/sst/opencode-sdk-go/internal/apiquery"
+	"github.com/sst/opencode-sdk-go/internal/param"
+	"github.com/sst/opencode-sdk-go/internal/requestconfig"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+// McpService contains methods and other services that help with interacting with
+// the kuuzuki API.
+//
+// Note, unlike clients, this service does not read variables from the environment
+// automatically. You should not instantiate this service directly, and instead use
+// the [NewMcpService] method instead.
+type McpService struct {
+	Options []option.RequestOption
+}
+
+// NewMcpService generates a new service that applies the given options to each
+// request. These options are applied after the parent client's options (if there
+// is one), and before any request-specific options.
+func NewMcpService(opts ...option.RequestOption) (r *McpService) {
+	r = &McpService{}
+	r.Options = opts
+	return
+}
+
+// List the resources MCP servers expose
+func (r *McpService) Resources(ctx context.Context, opts ...option.RequestOption) (res *[]McpResource, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "mcp/resource"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, nil, &res, opts...)
+	return
+}
+
+// Read the text of an MCP resource
+func (r *McpService) Read(ctx context.Context, query McpReadParams, opts ...option.RequestOption) (res *string, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "mcp/resource/read"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, query, &res, opts...)
+	return
+}
+
+type McpResource struct {
+	Name        string          `json:"name,required"`
+	Server      string          `json:"server,required"`
+	Uri         string          `json:"uri,required"`
+	Description string          `json:"description"`
+	MimeType    string          `json:"mimeType"`
+	JSON        mcpResourceJSON `json:"-"`
+}
+
+// mcpResourceJSON contains the JSON metadata for the struct [McpResource]
+type mcpResourceJSON struct {
+	Name        apijson.Field
+	Server      apijson.Field
+	Uri         apijson.Field
+	Description apijson.Field
+	MimeType    apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *McpResource) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r mcpResourceJSON) RawJSON() string {
+	return r.raw
+}
+
+type McpReadParams struct {
+	Server param.Field[string] `query:"server,required"`
+	Uri    param.Field[string] `query:"uri,required"`
+}
+
+// URLQuery serializes [McpReadParams]'s query parameters as `url.Values`.
+func (r McpReadParams) URLQuery() (v url.Values) {
+	return apiquery.MarshalWithSettings(r, apiquery.QuerySettings{
+		ArrayFormat:  apiquery.ArrayQueryFormatComma,
+		NestedFormat: apiquery.NestedQueryFormatBrackets,
+	})
+}
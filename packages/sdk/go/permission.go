@@ -0,0 +1,140 @@
+// File generated from our OpenAPI spec by Stainless. See CONTRIBUTING.md for details.
+
+package kuuzuki
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/sst/opencode-sdk-go/internal/apijson"
+	"github.com/sst/opencode-sdk-go/internal/apiquery"
+	"github.com/sst/opencode-sdk-go/internal/param"
+	"github.com/sst/opencode-sdk-go/internal/requestconfig"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+// PermissionService contains methods and other services that help with
+// interacting with the kuuzuki API.
+//
+// Note, unlike clients, this service does not read variables from the environment
+// automatically. You should not instantiate this service directly, and instead use
+// the [NewPermissionService] method instead.
+type PermissionService struct {
+	Options []option.RequestOption
+}
+
+// NewPermissionService generates a new service that applies the given options to
+// each request. These options are applied after the parent client's options (if
+// there is one), and before any request-specific options.
+func NewPermissionService(opts ...option.RequestOption) (r *PermissionService) {
+	r = &PermissionService{}
+	r.Options = opts
+	return
+}
+
+// List the standing permission rules
+func (r *PermissionService) Rules(ctx context.Context, query PermissionRulesParams, opts ...option.RequestOption) (res *[]PermissionRule, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "permission/rule"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, query, &res, opts...)
+	return
+}
+
+// Add or change a permission rule
+func (r *PermissionService) SetRule(ctx context.Context, body PermissionRuleParams, opts ...option.RequestOption) (res *[]PermissionRule, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "permission/rule"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPut, path, body, &res, opts...)
+	return
+}
+
+// Revoke a permission rule
+func (r *PermissionService) RemoveRule(ctx context.Context, body PermissionRuleParams, opts ...option.RequestOption) (res *[]PermissionRule, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "permission/rule"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodDelete, path, body, &res, opts...)
+	return
+}
+
+type PermissionRule struct {
+	Action  PermissionRuleAction `json:"action,required"`
+	Scope   PermissionRuleScope  `json:"scope,required"`
+	Tool    string               `json:"tool,required"`
+	Pattern string               `json:"pattern"`
+	JSON    permissionRuleJSON   `json:"-"`
+}
+
+// permissionRuleJSON contains the JSON metadata for the struct [PermissionRule]
+type permissionRuleJSON struct {
+	Action      apijson.Field
+	Scope       apijson.Field
+	Tool        apijson.Field
+	Pattern     apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *PermissionRule) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r permissionRuleJSON) RawJSON() string {
+	return r.raw
+}
+
+type PermissionRuleAction string
+
+const (
+	PermissionRuleActionAsk   PermissionRuleAction = "ask"
+	PermissionRuleActionAllow PermissionRuleAction = "allow"
+	PermissionRuleActionDeny  PermissionRuleAction = "deny"
+)
+
+func (r PermissionRuleAction) IsKnown() bool {
+	switch r {
+	case PermissionRuleActionAsk, PermissionRuleActionAllow, PermissionRuleActionDeny:
+		return true
+	}
+	return false
+}
+
+type PermissionRuleScope string
+
+const (
+	PermissionRuleScopeSession PermissionRuleScope = "session"
+	PermissionRuleScopeProject PermissionRuleScope = "project"
+	PermissionRuleScopeGlobal  PermissionRuleScope = "global"
+)
+
+func (r PermissionRuleScope) IsKnown() bool {
+	switch r {
+	case PermissionRuleScopeSession, PermissionRuleScopeProject, PermissionRuleScopeGlobal:
+		return true
+	}
+	return false
+}
+
+type PermissionRulesParams struct {
+	SessionID param.Field[string] `query:"sessionID"`
+}
+
+// URLQuery serializes [PermissionRulesParams]'s query parameters as `url.Values`.
+func (r PermissionRulesParams) URLQuery() (v url.Values) {
+	return apiquery.MarshalWithSettings(r, apiquery.QuerySettings{
+		ArrayFormat:  apiquery.ArrayQueryFormatComma,
+		NestedFormat: apiquery.NestedQueryFormatBrackets,
+	})
+}
+
+type PermissionRuleParams struct {
+	Action    param.Field[PermissionRuleAction] `json:"action,required"`
+	Scope     param.Field[PermissionRuleScope]  `json:"scope,required"`
+	Tool      param.Field[string]               `json:"tool,required"`
+	Pattern   param.Field[string]               `json:"pattern"`
+	SessionID param.Field[string]               `json:"sessionID"`
+}
+
+func (r PermissionRuleParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
@@ -0,0 +1,94 @@
+// File generated from our OpenAPI spec by Stainless. See CONTRIBUTING.md for details.
+
+package kuuzuki_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/internal/testutil"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+func TestPermissionRulesWithOptionalParams(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.Permission.Rules(context.TODO(), kuuzuki.PermissionRulesParams{
+		SessionID: kuuzuki.F("sessionID"),
+	})
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
+
+func TestPermissionSetRuleWithOptionalParams(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.Permission.SetRule(context.TODO(), kuuzuki.PermissionRuleParams{
+		Action:    kuuzuki.F(kuuzuki.PermissionRuleActionAllow),
+		Scope:     kuuzuki.F(kuuzuki.PermissionRuleScopeSession),
+		Tool:      kuuzuki.F("tool"),
+		Pattern:   kuuzuki.F("pattern"),
+		SessionID: kuuzuki.F("sessionID"),
+	})
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
+
+func TestPermissionRemoveRuleWithOptionalParams(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.Permission.RemoveRule(context.TODO(), kuuzuki.PermissionRuleParams{
+		Action:    kuuzuki.F(kuuzuki.PermissionRuleActionAllow),
+		Scope:     kuuzuki.F(kuuzuki.PermissionRuleScopeSession),
+		Tool:      kuuzuki.F("tool"),
+		Pattern:   kuuzuki.F("pattern"),
+		SessionID: kuuzuki.F("sessionID"),
+	})
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
@@ -226,6 +226,63 @@ func (r *SessionService) Shell(ctx context.Context, id string, body SessionShell
 	return
 }
 
+// List the files the session changed, with their diff since before the session
+// changed them
+func (r *SessionService) Changes(ctx context.Context, id string, opts ...option.RequestOption) (res *[]SessionChange, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/changes", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, nil, &res, opts...)
+	return
+}
+
+// Undo the change a single edit or write made, keeping later changes
+func (r *SessionService) RevertPart(ctx context.Context, id string, messageID string, partID string, opts ...option.RequestOption) (res *string, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	if messageID == "" {
+		err = errors.New("missing required messageID parameter")
+		return
+	}
+	if partID == "" {
+		err = errors.New("missing required partID parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/message/%s/part/%s/revert", id, messageID, partID)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, nil, &res, opts...)
+	return
+}
+
+// Pin or unpin a file, sending its content with every prompt of the session
+func (r *SessionService) Pin(ctx context.Context, id string, body SessionPinParams, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/pin", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
+// Put a file back as it was before the session changed it
+func (r *SessionService) RevertFile(ctx context.Context, id string, body SessionRevertFileParams, opts ...option.RequestOption) (res *bool, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/changes/revert", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 type AgentPart struct {
 	ID        string          `json:"id,required"`
 	MessageID string          `json:"messageID,required"`
@@ -1127,7 +1184,9 @@ type PartPatchPart struct {
 	MessageID string            `json:"messageID,required"`
 	SessionID string            `json:"sessionID,required"`
 	Type      PartPatchPartType `json:"type,required"`
-	JSON      partPatchPartJSON `json:"-"`
+	// The tool call the snapshot was taken before
+	CallID string            `json:"callID"`
+	JSON   partPatchPartJSON `json:"-"`
 }
 
 // partPatchPartJSON contains the JSON metadata for the struct [PartPatchPart]
@@ -1138,6 +1197,7 @@ type partPatchPartJSON struct {
 	MessageID   apijson.Field
 	SessionID   apijson.Field
 	Type        apijson.Field
+	CallID      apijson.Field
 	raw         string
 	ExtraFields map[string]apijson.Field
 }
@@ -1193,6 +1253,7 @@ type Session struct {
 	Title    string        `json:"title,required"`
 	Version  string        `json:"version,required"`
 	ParentID string        `json:"parentID"`
+	Pinned   []string      `json:"pinned"`
 	Revert   SessionRevert `json:"revert"`
 	Share    SessionShare  `json:"share"`
 	JSON     sessionJSON   `json:"-"`
@@ -1205,6 +1266,7 @@ type sessionJSON struct {
 	Title       apijson.Field
 	Version     apijson.Field
 	ParentID    apijson.Field
+	Pinned      apijson.Field
 	Revert      apijson.Field
 	Share       apijson.Field
 	raw         string
@@ -2182,6 +2244,33 @@ func (r userMessageTimeJSON) RawJSON() string {
 	return r.raw
 }
 
+type SessionChange struct {
+	Additions float64 `json:"additions,required"`
+	Deletions float64 `json:"deletions,required"`
+	Patch     string  `json:"patch,required"`
+	// File path relative to the working directory
+	Path string            `json:"path,required"`
+	JSON sessionChangeJSON `json:"-"`
+}
+
+// sessionChangeJSON contains the JSON metadata for the struct [SessionChange]
+type sessionChangeJSON struct {
+	Additions   apijson.Field
+	Deletions   apijson.Field
+	Patch       apijson.Field
+	Path        apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *SessionChange) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r sessionChangeJSON) RawJSON() string {
+	return r.raw
+}
+
 type SessionMessageResponse struct {
 	Info  Message                    `json:"info,required"`
 	Parts []Part                     `json:"parts,required"`
@@ -2302,6 +2391,25 @@ func (r SessionRevertParams) MarshalJSON() (data []byte, err error) {
 	return apijson.MarshalRoot(r)
 }
 
+type SessionPinParams struct {
+	// File path relative to the working directory
+	Path   param.Field[string] `json:"path,required"`
+	Pinned param.Field[bool]   `json:"pinned,required"`
+}
+
+func (r SessionPinParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
+type SessionRevertFileParams struct {
+	// File path relative to the working directory
+	Path param.Field[string] `json:"path,required"`
+}
+
+func (r SessionRevertFileParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
 type SessionSummarizeParams struct {
 	ModelID    param.Field[string] `json:"modelID,required"`
 	ProviderID param.Field[string] `json:"providerID,required"`
@@ -2321,6 +2429,9 @@ func (r SessionUpdateParams) MarshalJSON() (data []byte, err error) {
 
 type SessionShellParams struct {
 	Command param.Field[string] `json:"command,required"`
+	// Run in the session's shell, keeping its directory and variables between
+	// commands
+	Persistent param.Field[bool] `json:"persistent"`
 }
 
 func (r SessionShellParams) MarshalJSON() (data []byte, err error) {
@@ -107,12 +107,24 @@ func (r permissionTimeJSON) RawJSON() string {
 
 type SessionPermissionRespondParams struct {
 	Response param.Field[SessionPermissionRespondParamsResponse] `json:"response,required"`
+	Rule     param.Field[SessionPermissionRespondParamsRule]     `json:"rule"`
 }
 
 func (r SessionPermissionRespondParams) MarshalJSON() (data []byte, err error) {
 	return apijson.MarshalRoot(r)
 }
 
+type SessionPermissionRespondParamsRule struct {
+	Scope   param.Field[PermissionRuleScope] `json:"scope,required"`
+	Pattern param.Field[string]              `json:"pattern"`
+	// Match the pattern literally, for a single command
+	Exact param.Field[bool] `json:"exact"`
+}
+
+func (r SessionPermissionRespondParamsRule) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
 type SessionPermissionRespondParamsResponse string
 
 const (
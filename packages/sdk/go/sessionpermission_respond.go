@@ -0,0 +1,54 @@
+package kuuzuki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+// PermissionResponse is the answer to a permission request.
+type PermissionResponse = SessionPermissionRespondParamsResponse
+
+const (
+	// PermissionResponseOnce allows the request a single time.
+	PermissionResponseOnce = SessionPermissionRespondParamsResponseOnce
+	// PermissionResponseAlways allows the request and every matching one after
+	// it, scoped by the rule sent with the answer.
+	PermissionResponseAlways = SessionPermissionRespondParamsResponseAlways
+	// PermissionResponseReject denies the request.
+	PermissionResponseReject = SessionPermissionRespondParamsResponseReject
+)
+
+var (
+	// ErrPermissionNotPending is returned when the permission request was
+	// already answered, timed out or belongs to another session.
+	ErrPermissionNotPending = errors.New("permission request is not pending")
+	// ErrUnknownPermissionResponse is returned for a response other than
+	// once, always or reject.
+	ErrUnknownPermissionResponse = errors.New("unknown permission response")
+)
+
+// RespondPermission answers a permission request of a session. rule scopes an
+// always answer and is ignored otherwise, nil keeps the server default of the
+// rest of the session.
+func (r *SessionService) RespondPermission(ctx context.Context, id string, permissionID string, response PermissionResponse, rule *SessionPermissionRespondParamsRule, opts ...option.RequestOption) error {
+	if !response.IsKnown() {
+		return fmt.Errorf("%w: %q", ErrUnknownPermissionResponse, response)
+	}
+	params := SessionPermissionRespondParams{
+		Response: F(response),
+	}
+	if rule != nil && response == PermissionResponseAlways {
+		params.Rule = F(*rule)
+	}
+	res, err := r.Permissions.Respond(ctx, id, permissionID, params, opts...)
+	if err != nil {
+		return err
+	}
+	if res != nil && !*res {
+		return ErrPermissionNotPending
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package kuuzuki_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+// respondClient answers every request with body and records the request body
+func respondClient(body string, sent *string) *kuuzuki.Client {
+	return kuuzuki.NewClient(
+		option.WithHTTPClient(&http.Client{
+			Transport: &closureTransport{
+				fn: func(req *http.Request) (*http.Response, error) {
+					if req.Body != nil {
+						data, _ := io.ReadAll(req.Body)
+						*sent = string(data)
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{"Content-Type": []string{"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(body)),
+					}, nil
+				},
+			},
+		}),
+	)
+}
+
+func TestSessionRespondPermission(t *testing.T) {
+	var sent string
+	client := respondClient("true", &sent)
+	err := client.Session.RespondPermission(
+		context.Background(),
+		"ses",
+		"per",
+		kuuzuki.PermissionResponseAlways,
+		&kuuzuki.SessionPermissionRespondParamsRule{
+			Scope:   kuuzuki.F(kuuzuki.PermissionRuleScopeProject),
+			Pattern: kuuzuki.F("git *"),
+		},
+	)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !strings.Contains(sent, `"response":"always"`) || !strings.Contains(sent, `"scope":"project"`) {
+		t.Errorf("expected the answer and its rule to be sent, got %s", sent)
+	}
+
+	// a rule only scopes an always answer
+	err = client.Session.RespondPermission(
+		context.Background(),
+		"ses",
+		"per",
+		kuuzuki.PermissionResponseReject,
+		&kuuzuki.SessionPermissionRespondParamsRule{
+			Scope: kuuzuki.F(kuuzuki.PermissionRuleScopeProject),
+		},
+	)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if strings.Contains(sent, "rule") {
+		t.Errorf("expected no rule with a reject, got %s", sent)
+	}
+}
+
+func TestSessionRespondPermissionNotPending(t *testing.T) {
+	var sent string
+	client := respondClient("false", &sent)
+	err := client.Session.RespondPermission(context.Background(), "ses", "per", kuuzuki.PermissionResponseOnce, nil)
+	if !errors.Is(err, kuuzuki.ErrPermissionNotPending) {
+		t.Errorf("expected ErrPermissionNotPending, got %v", err)
+	}
+}
+
+func TestSessionRespondPermissionUnknown(t *testing.T) {
+	var sent string
+	client := respondClient("true", &sent)
+	err := client.Session.RespondPermission(context.Background(), "ses", "per", kuuzuki.PermissionResponse("maybe"), nil)
+	if !errors.Is(err, kuuzuki.ErrUnknownPermissionResponse) {
+		t.Errorf("expected ErrUnknownPermissionResponse, got %v", err)
+	}
+	if sent != "" {
+		t.Error("expected an unknown response not to be sent")
+	}
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/sst/opencode-sdk-go/option"
 )
 
-func TestSessionPermissionRespond(t *testing.T) {
+func TestSessionPermissionRespondWithOptionalParams(t *testing.T) {
 	t.Skip("skipped: tests are disabled for the time being")
 	baseURL := "http://localhost:4010"
 	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
@@ -31,6 +31,11 @@ func TestSessionPermissionRespond(t *testing.T) {
 		"permissionID",
 		kuuzuki.SessionPermissionRespondParams{
 			Response: kuuzuki.F(kuuzuki.SessionPermissionRespondParamsResponseOnce),
+			Rule: kuuzuki.F(kuuzuki.SessionPermissionRespondParamsRule{
+				Scope:   kuuzuki.F(kuuzuki.PermissionRuleScopeSession),
+				Pattern: kuuzuki.F("pattern"),
+				Exact:   kuuzuki.F(true),
+			}),
 		},
 	)
 	if err != nil {
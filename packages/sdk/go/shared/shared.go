@@ -138,14 +138,16 @@ func (r UnknownError) ImplementsEventListResponseEventSessionErrorPropertiesErro
 func (r UnknownError) ImplementsAssistantMessageError() {}
 
 type UnknownErrorData struct {
-	Message string               `json:"message,required"`
-	JSON    unknownErrorDataJSON `json:"-"`
+	Message    string               `json:"message,required"`
+	StatusCode float64              `json:"statusCode"`
+	JSON       unknownErrorDataJSON `json:"-"`
 }
 
 // unknownErrorDataJSON contains the JSON metadata for the struct
 // [UnknownErrorData]
 type unknownErrorDataJSON struct {
 	Message     apijson.Field
+	StatusCode  apijson.Field
 	raw         string
 	ExtraFields map[string]apijson.Field
 }
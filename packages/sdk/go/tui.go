@@ -4,6 +4,8 @@ package kuuzuki
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/sst/opencode-sdk-go/internal/apijson"
@@ -55,6 +57,14 @@ func (r *TuiService) ExecuteCommand(ctx context.Context, body TuiExecuteCommandP
 	return
 }
 
+// Ask the user to type a value in the TUI and wait for it
+func (r *TuiService) Input(ctx context.Context, body TuiInputParams, opts ...option.RequestOption) (res *TuiInputAnswer, err error) {
+	opts = append(r.Options[:], opts...)
+	path := "tui/input"
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 // Open the help dialog
 func (r *TuiService) OpenHelp(ctx context.Context, opts ...option.RequestOption) (res *bool, err error) {
 	opts = append(r.Options[:], opts...)
@@ -87,6 +97,18 @@ func (r *TuiService) OpenThemes(ctx context.Context, opts ...option.RequestOptio
 	return
 }
 
+// Answer or cancel a text input request
+func (r *TuiService) RespondInput(ctx context.Context, inputID string, body TuiRespondInputParams, opts ...option.RequestOption) (res *bool, err error) {
+	opts = append(r.Options[:], opts...)
+	if inputID == "" {
+		err = errors.New("missing required inputID parameter")
+		return
+	}
+	path := fmt.Sprintf("tui/input/%s", inputID)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 // Submit the prompt
 func (r *TuiService) SubmitPrompt(ctx context.Context, opts ...option.RequestOption) (res *bool, err error) {
 	opts = append(r.Options[:], opts...)
@@ -95,6 +117,28 @@ func (r *TuiService) SubmitPrompt(ctx context.Context, opts ...option.RequestOpt
 	return
 }
 
+type TuiInputAnswer struct {
+	Cancelled bool               `json:"cancelled,required"`
+	Value     string             `json:"value"`
+	JSON      tuiInputAnswerJSON `json:"-"`
+}
+
+// tuiInputAnswerJSON contains the JSON metadata for the struct [TuiInputAnswer]
+type tuiInputAnswerJSON struct {
+	Cancelled   apijson.Field
+	Value       apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *TuiInputAnswer) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r tuiInputAnswerJSON) RawJSON() string {
+	return r.raw
+}
+
 type TuiAppendPromptParams struct {
 	Text param.Field[string] `json:"text,required"`
 }
@@ -110,3 +154,26 @@ type TuiExecuteCommandParams struct {
 func (r TuiExecuteCommandParams) MarshalJSON() (data []byte, err error) {
 	return apijson.MarshalRoot(r)
 }
+
+type TuiInputParams struct {
+	Prompt param.Field[string] `json:"prompt,required"`
+	// Hide the typed value, for secrets
+	Masked      param.Field[bool]   `json:"masked"`
+	Placeholder param.Field[string] `json:"placeholder"`
+	SessionID   param.Field[string] `json:"sessionID"`
+	// Seconds to wait for an answer, 300 by default
+	Timeout param.Field[float64] `json:"timeout"`
+}
+
+func (r TuiInputParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
+type TuiRespondInputParams struct {
+	Cancelled param.Field[bool]   `json:"cancelled,required"`
+	Value     param.Field[string] `json:"value"`
+}
+
+func (r TuiRespondInputParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
@@ -83,6 +83,34 @@ func TestTuiExecuteCommand(t *testing.T) {
 	}
 }
 
+func TestTuiInputWithOptionalParams(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.Tui.Input(context.TODO(), kuuzuki.TuiInputParams{
+		Prompt:      kuuzuki.F("prompt"),
+		Masked:      kuuzuki.F(true),
+		Placeholder: kuuzuki.F("placeholder"),
+		SessionID:   kuuzuki.F("sessionID"),
+		Timeout:     kuuzuki.F(0.000000),
+	})
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
+
 func TestTuiOpenHelp(t *testing.T) {
 	t.Skip("skipped: tests are disabled for the time being")
 	baseURL := "http://localhost:4010"
@@ -171,6 +199,35 @@ func TestTuiOpenThemes(t *testing.T) {
 	}
 }
 
+func TestTuiRespondInputWithOptionalParams(t *testing.T) {
+	t.Skip("skipped: tests are disabled for the time being")
+	baseURL := "http://localhost:4010"
+	if envURL, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = envURL
+	}
+	if !testutil.CheckTestServer(t, baseURL) {
+		return
+	}
+	client := kuuzuki.NewClient(
+		option.WithBaseURL(baseURL),
+	)
+	_, err := client.Tui.RespondInput(
+		context.TODO(),
+		"inputID",
+		kuuzuki.TuiRespondInputParams{
+			Cancelled: kuuzuki.F(true),
+			Value:     kuuzuki.F("value"),
+		},
+	)
+	if err != nil {
+		var apierr *kuuzuki.Error
+		if errors.As(err, &apierr) {
+			t.Log(string(apierr.DumpRequest(true)))
+		}
+		t.Fatalf("err should be nil: %s", err.Error())
+	}
+}
+
 func TestTuiSubmitPrompt(t *testing.T) {
 	t.Skip("skipped: tests are disabled for the time being")
 	baseURL := "http://localhost:4010"
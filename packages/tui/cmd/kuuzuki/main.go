@@ -0,0 +1,479 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/colorprofile"
+	flag "github.com/spf13/pflag"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+	"github.com/sst/opencode/internal/api"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/clipboard"
+	"github.com/sst/opencode/internal/components/status"
+	"github.com/sst/opencode/internal/doctor"
+	"github.com/sst/opencode/internal/headless"
+	"github.com/sst/opencode/internal/i18n"
+	"github.com/sst/opencode/internal/launch"
+	"github.com/sst/opencode/internal/recording"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/tui"
+	"github.com/sst/opencode/internal/util"
+)
+
+var Version = "dev"
+
+// a dropped event stream is reopened with exponential backoff, from
+// reconnectDelay up to maxReconnectDelay, and the server is reported offline
+// after maxReconnectAttempts while the attempts go on
+const (
+	maxReconnectAttempts = 5
+	reconnectDelay       = time.Second
+	maxReconnectDelay    = 30 * time.Second
+)
+
+// reconnectBackoff is the delay before the given reconnection attempt,
+// counting from zero
+func reconnectBackoff(attempt int) time.Duration {
+	if attempt >= 16 {
+		return maxReconnectDelay
+	}
+	return min(reconnectDelay<<attempt, maxReconnectDelay)
+}
+
+// the terminal title is saved on the terminal's title stack before the TUI
+// retitles it and restored on exit (XTWINOPS 22 and 23), terminals without
+// the stack ignore them
+const (
+	pushTitle = "\x1b[22;0t"
+	popTitle  = "\x1b[23;0t"
+)
+
+func main() {
+	start := time.Now()
+	version := Version
+	if version != "dev" && !strings.HasPrefix(Version, "v") {
+		version = "v" + Version
+	}
+
+	var model *string = flag.String("model", "", "model to begin with")
+	var prompt *string = flag.String("prompt", "", "prompt to begin with")
+	var mode *string = flag.String("mode", "", "mode to begin with")
+	var command *string = flag.String("command", "", "command to run after starting")
+	var session *string = flag.String("session", "", "session ID to resume")
+	var projects *bool = flag.Bool("projects", false, "start on the recent projects launcher")
+	var printAnswer *bool = flag.Bool("print", false, "print the answer to the prompt and exit, without the TUI")
+	var output *string = flag.String("output", "", "format of --print, text or json lines, implies --print")
+	var batch *string = flag.String("batch", "", "run the prompts of a file, separated by --- lines, and exit")
+	var transcript *string = flag.String("transcript", "", "where --batch writes its transcript, <batch>.transcript.json by default")
+	var approve *string = flag.String("approve", "reject", "answer to permission requests without the TUI, reject, once or always")
+	var cwdDir *string = flag.String("cwd", "", "directory to start in instead of the current one")
+	var project *string = flag.String("project", "", "recent project to open, by name or path, resuming its latest session")
+	var readonly *bool = flag.Bool("readonly", false, "observe sessions without sending prompts, tool requests are rejected")
+	var record *string = flag.String("record", "", "record the events and keystrokes of the session to a file")
+	var replay *string = flag.String("replay", "", "play a recording back, read-only")
+	var speed *float64 = flag.Float64("speed", 1, "speed of --replay, 2 plays twice as fast")
+	var diagnose *bool = flag.Bool("doctor", false, "check the terminal, server, git and editor, print a report and exit")
+	var noFancy *bool = flag.Bool("no-fancy", false, "draw ASCII borders in 16 colors, as in terminals without unicode or truecolor")
+	var screenReader *bool = flag.Bool("screen-reader", false, "print the conversation as plain lines for screen readers, without spinners or overlays")
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the server, app info and modes come from the environment when spawned
+	// by `kuuzuki tui`, they describe its directory and not another one
+	url := os.Getenv("KUUZUKI_SERVER")
+	appInfoStr := os.Getenv("KUUZUKI_APP_INFO")
+	modesStr := os.Getenv("KUUZUKI_MODES")
+	if dir, resume := startDir(*cwdDir, *project); dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			slog.Error("Failed to change directory", "dir", dir, "error", err)
+			os.Exit(1)
+		}
+		if *session == "" {
+			session = &resume
+		}
+		url, appInfoStr, modesStr = "", "", ""
+	}
+
+	if *diagnose {
+		// the clipboard logs its own warning, the report covers it
+		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		cwd, _ := os.Getwd()
+		checks := doctor.Run(ctx, doctor.Options{
+			ServerURL: url,
+			Cwd:       cwd,
+			Terminal:  doctor.NewTerminal(os.Stdin, os.Stdout),
+		})
+		fmt.Printf("kuuzuki %s\n", version)
+		doctor.Report(os.Stdout, checks)
+		if doctor.Failed(checks) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// run on its own, the TUI finds or starts the server of the directory
+	var server *launch.Server
+	if url == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			slog.Error("Failed to get the working directory", "error", err)
+			os.Exit(1)
+		}
+		server, err = launch.Connect(ctx, cwd)
+		if err != nil {
+			slog.Error("Failed to connect to a kuuzuki server", "error", err)
+			os.Exit(1)
+		}
+		defer server.Close()
+		url = server.URL
+		slog.Debug("Using kuuzuki server", "url", url, "started", server.Started())
+	}
+
+	httpClient := opencode.NewClient(
+		option.WithBaseURL(url),
+	)
+
+	// app info and modes come from the server when not in the environment
+	var appInfo opencode.App
+	if appInfoStr == "" {
+		info, err := httpClient.App.Get(ctx)
+		if err != nil {
+			slog.Error("Failed to get app info", "error", err)
+			os.Exit(1)
+		}
+		appInfo = *info
+		appInfoStr = info.JSON.RawJSON()
+	} else if err := json.Unmarshal([]byte(appInfoStr), &appInfo); err != nil {
+		slog.Error("Failed to unmarshal app info", "error", err)
+		os.Exit(1)
+	}
+
+	var modes []opencode.Agent
+	if modesStr == "" {
+		// the modes, the same list the launcher passes, so --agent resolves
+		// against primary agents only
+		list, err := httpClient.App.Modes(ctx)
+		if err != nil {
+			slog.Error("Failed to list modes", "error", err)
+			os.Exit(1)
+		}
+		modes = *list
+	} else if err := json.Unmarshal([]byte(modesStr), &modes); err != nil {
+		slog.Error("Failed to unmarshal modes", "error", err)
+		os.Exit(1)
+	}
+
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		slog.Error("Failed to stat stdin", "error", err)
+		os.Exit(1)
+	}
+
+	// Check if there's data piped to stdin
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		stdin, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			slog.Error("Failed to read stdin", "error", err)
+			os.Exit(1)
+		}
+		stdinContent := strings.TrimSpace(string(stdin))
+		if stdinContent != "" {
+			if prompt == nil || *prompt == "" {
+				prompt = &stdinContent
+			} else {
+				combined := *prompt + "\n" + stdinContent
+				prompt = &combined
+			}
+		}
+	}
+
+	apiHandler := util.NewAPILogHandler(ctx, httpClient, "tui", slog.LevelDebug)
+	logger := slog.New(apiHandler)
+	slog.SetDefault(logger)
+
+	if *printAnswer || *output != "" || *batch != "" {
+		runCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		options := headless.Options{
+			Client:    httpClient,
+			Prompt:    *prompt,
+			Model:     *model,
+			Agent:     *mode,
+			SessionID: *session,
+			Format:    headless.Format(*output),
+			Approval:  opencode.PermissionResponse(*approve),
+			Stdout:    os.Stdout,
+			Stderr:    os.Stderr,
+		}
+		var code int
+		if *batch != "" {
+			code = runBatch(runCtx, options, *batch, *transcript)
+		} else {
+			code = headless.Run(runCtx, options)
+		}
+		stop()
+		// deferred calls are skipped by os.Exit
+		if server != nil {
+			server.Close()
+		}
+		os.Exit(code)
+	}
+
+	slog.Debug("TUI launched", "app", appInfoStr, "modes", len(modes))
+
+	go func() {
+		err = clipboard.Init()
+		if err != nil {
+			slog.Error("Failed to initialize clipboard", "error", err)
+		}
+	}()
+
+	var replayed []recording.Entry
+	if *replay != "" {
+		file, err := os.Open(*replay)
+		if err != nil {
+			slog.Error("Failed to open the recording", "error", err)
+			os.Exit(1)
+		}
+		replayed, err = recording.Read(file)
+		file.Close()
+		if err != nil {
+			slog.Error("Failed to read the recording", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Create main context for the application
+	app_, err := app.New(ctx, version, appInfo, modes, httpClient, model, prompt, mode, session)
+	if err != nil {
+		panic(err)
+	}
+
+	app_.ShowLauncher = *projects
+	// replayed keystrokes must not prompt or run commands again
+	app_.Replaying = *replay != ""
+	app_.ReadOnly = *readonly || app_.Replaying
+	app_.ScreenReader = *screenReader || app_.Config.Tui.ScreenReader.Enabled
+	app_.Inline = app_.ScreenReader && !app_.Config.Tui.ScreenReader.AltScreen
+	styles.ReduceMotion = app_.ScreenReader || app_.Config.Tui.ReduceMotion
+	if icons := app_.Config.Tui.Icons; icons != "" {
+		if set, ok := styles.ParseIconSet(string(icons)); ok {
+			styles.Icons = set
+		} else {
+			slog.Warn("Unknown icon set", "icons", icons)
+		}
+	}
+	locale := app_.Config.Tui.Locale
+	if locale == "" {
+		locale = i18n.Detect(os.Getenv)
+	}
+	if _, err := i18n.SetLocale(locale); err != nil {
+		slog.Error("Failed to load the locale", "locale", locale, "error", err)
+	}
+	slog.Debug("App initialized", "elapsed", time.Since(start))
+
+	// Store command line arguments for later use
+	if session != nil && *session != "" {
+		slog.Info("Session argument provided", "sessionID", *session)
+		// Session loading will be handled by the TUI after initialization
+	}
+
+	if command != nil && *command != "" {
+		slog.Info("Command argument provided", "command", *command)
+		// Command execution will be handled by the TUI after initialization
+	}
+
+	tuiModel := tui.NewModel(app_)
+	var options []tea.ProgramOption
+	// inline, what is printed stays in the scrollback and the terminal's
+	// own selection works
+	if !app_.Inline {
+		options = append(options, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	}
+	styles.Fancy = !*noFancy && styles.DetectFancy(os.Getenv, runtime.GOOS)
+	if !styles.Fancy {
+		options = append(options, tea.WithColorProfile(colorprofile.ANSI))
+	}
+	var recorder *recording.Recorder
+	if *record != "" {
+		recorder, err = recording.Create(*record)
+		if err != nil {
+			slog.Error("Failed to create the recording", "error", err)
+			os.Exit(1)
+		}
+		defer recorder.Close()
+		options = append(options, tea.WithFilter(recordInput(recorder)))
+	}
+	program := tea.NewProgram(tuiModel, options...)
+
+	// Set up signal handling for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+	// a replay stands in for the server events and control requests
+	if *replay != "" {
+		go recording.Play(ctx, replayed, *speed, program.Send)
+	} else {
+		go streamEvents(ctx, httpClient, program, recorder)
+		go api.Start(ctx, program, httpClient)
+	}
+
+	// Handle signals in a separate goroutine
+	go func() {
+		sig := <-sigChan
+		slog.Info("Received signal, shutting down gracefully", "signal", sig)
+		program.Quit()
+	}()
+
+	// Run the TUI
+	os.Stdout.WriteString(pushTitle)
+	result, err := program.Run()
+	os.Stdout.WriteString(popTitle)
+	if err != nil {
+		slog.Error("TUI error", "error", err)
+	}
+
+	slog.Info("TUI exited", "result", result)
+}
+
+// runBatch runs the prompts of a batch file, writing the transcript next to
+// it unless given a path
+func runBatch(ctx context.Context, options headless.Options, batch, transcript string) int {
+	file, err := os.Open(batch)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		return headless.ExitUsage
+	}
+	prompts, err := headless.ReadBatch(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: failed to read the batch: "+err.Error())
+		return headless.ExitUsage
+	}
+	if transcript == "" {
+		transcript = strings.TrimSuffix(batch, filepath.Ext(batch)) + ".transcript.json"
+	}
+	out, err := os.Create(transcript)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		return headless.ExitUsage
+	}
+	defer out.Close()
+	return headless.RunBatch(ctx, options, prompts, out)
+}
+
+// startDir resolves --cwd and --project to the directory to start in, and
+// the session of the project to resume. Both are empty without the flags.
+func startDir(cwd, project string) (string, string) {
+	if project != "" {
+		state, err := app.LoadState(filepath.Join(launch.StateDir(), "tui"))
+		if err != nil {
+			state = app.NewState()
+		}
+		if recent, ok := state.FindRecentProject(project); ok {
+			return recent.Root, recent.SessionID
+		}
+		if info, err := os.Stat(project); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "error: %q is neither a recent project nor a directory\n", project)
+			os.Exit(1)
+		}
+		cwd = project
+	}
+	if cwd == "" {
+		return "", ""
+	}
+	dir, err := filepath.Abs(cwd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		os.Exit(1)
+	}
+	return dir, ""
+}
+
+// streamEvents sends the server events to the program, reconnecting when the
+// stream drops, and records them when recording
+func streamEvents(ctx context.Context, httpClient *opencode.Client, program *tea.Program, recorder *recording.Recorder) {
+	// events may have been missed once the first stream ended
+	dropped := false
+	for attempt := 0; ; attempt++ {
+		stream := httpClient.Event.ListStreaming(ctx)
+		connected := false
+		for stream.Next() {
+			// the first event, server.connected from current servers,
+			// proves the stream is up and earns a fresh set of attempts
+			if !connected {
+				connected = true
+				attempt = 0
+				program.Send(status.ServerConnectionMsg{
+					State:       status.ConnectionConnected,
+					Reconnected: dropped,
+				})
+			}
+			evt := stream.Current().AsUnion()
+			if _, ok := evt.(opencode.EventListResponseEventStorageWrite); ok {
+				continue
+			}
+			if recorder != nil {
+				recorder.Event(stream.Current().JSON.RawJSON())
+			}
+			program.Send(evt)
+		}
+		if err := stream.Err(); err != nil {
+			slog.Error("Error streaming events", "error", err)
+			program.Send(err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		dropped = true
+		state := status.ConnectionReconnecting
+		if attempt >= maxReconnectAttempts {
+			state = status.ConnectionDisconnected
+		}
+		program.Send(status.ServerConnectionMsg{State: state, Attempt: attempt + 1})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectBackoff(attempt)):
+		}
+	}
+}
+
+// recordInput records the keystrokes, pastes and session switches of the
+// program, leaving out what is typed into masked inputs
+func recordInput(recorder *recording.Recorder) func(tea.Model, tea.Msg) tea.Msg {
+	return func(m tea.Model, msg tea.Msg) tea.Msg {
+		if model, ok := m.(interface{ CapturingSecret() bool }); ok && model.CapturingSecret() {
+			return msg
+		}
+		switch msg := msg.(type) {
+		case tea.KeyPressMsg:
+			recorder.Key(msg)
+		case tea.PasteMsg:
+			recorder.Paste(string(msg))
+		case app.SessionSelectedMsg:
+			if msg != nil {
+				recorder.Session(*msg)
+			}
+		case app.SessionCreatedMsg:
+			if msg.Session != nil {
+				recorder.Session(*msg.Session)
+			}
+		}
+		return msg
+	}
+}
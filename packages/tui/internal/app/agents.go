@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// AgentSavedMsg is sent once a custom agent was written by the server
+type AgentSavedMsg struct {
+	Agent opencode.Agent
+}
+
+// SaveAgent creates or updates a custom agent on the server
+func (a *App) SaveAgent(params opencode.AppSaveAgentParams) tea.Cmd {
+	return func() tea.Msg {
+		agent, err := a.Client.App.SaveAgent(context.Background(), params)
+		if err != nil {
+			slog.Error("Failed to save agent", "error", err)
+			return toast.NewErrorToast("Failed to save the agent: " + err.Error())()
+		}
+		if agent == nil {
+			return nil
+		}
+		return AgentSavedMsg{Agent: *agent}
+	}
+}
+
+// UpdateAgent replaces the agent of the same name with a saved one, or adds
+// it when it can act as a primary agent. Subagents are only run through the
+// task tool, so they aren't listed for switching.
+func (a *App) UpdateAgent(agent opencode.Agent) {
+	for i := range a.Agents {
+		if a.Agents[i].Name != agent.Name {
+			continue
+		}
+		a.Agents[i] = agent
+		if a.Agent != nil && a.Agent.Name == agent.Name {
+			a.Agent = &a.Agents[i]
+		}
+		return
+	}
+	if agent.Mode == opencode.AgentModeSubagent {
+		return
+	}
+	current := a.AgentIndex
+	a.Agents = append(a.Agents, agent)
+	// appending may have moved the slice
+	if current >= 0 && current < len(a.Agents) {
+		a.Agent = &a.Agents[current]
+	}
+}
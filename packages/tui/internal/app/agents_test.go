@@ -0,0 +1,30 @@
+package app
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestUpdateAgent(t *testing.T) {
+	a := &App{Agents: []opencode.Agent{{Name: "build"}, {Name: "plan"}}, AgentIndex: 1}
+	a.Agent = &a.Agents[1]
+
+	a.UpdateAgent(opencode.Agent{Name: "plan", Prompt: "think first"})
+	if len(a.Agents) != 2 || a.Agent.Prompt != "think first" {
+		t.Errorf("current agent not replaced: %+v", a.Agents)
+	}
+
+	a.UpdateAgent(opencode.Agent{Name: "reviewer", Mode: opencode.AgentModeSubagent})
+	if len(a.Agents) != 2 {
+		t.Errorf("subagent listed for switching: %+v", a.Agents)
+	}
+
+	a.UpdateAgent(opencode.Agent{Name: "docs", Mode: opencode.AgentModeAll})
+	if len(a.Agents) != 3 || a.Agents[2].Name != "docs" {
+		t.Errorf("agent not added: %+v", a.Agents)
+	}
+	if a.Agent != &a.Agents[1] {
+		t.Error("current agent doesn't point into the agent list")
+	}
+}
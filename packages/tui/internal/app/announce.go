@@ -0,0 +1,68 @@
+package app
+
+import (
+	"strings"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// Announcer turns the parts of the messages into plain lines for screen
+// readers, printed one after the other as the conversation goes on instead
+// of redrawn in place. Its zero value is ready to use.
+type Announcer struct {
+	// announced is what was last announced per part, a tool's status or
+	// "text" once a text was
+	announced map[string]string
+}
+
+// Part returns the lines announcing what is new in the part of a message of
+// the role, nothing while the text of an answer is still being written
+func (a *Announcer) Part(part opencode.PartUnion, role opencode.MessageRole) []string {
+	if a.announced == nil {
+		a.announced = map[string]string{}
+	}
+	switch part := part.(type) {
+	case opencode.TextPart:
+		if part.Synthetic || strings.TrimSpace(part.Text) == "" || a.announced[part.ID] != "" {
+			return nil
+		}
+		speaker := "You:"
+		if role == opencode.MessageRoleAssistant {
+			if part.Time.End == 0 {
+				return nil
+			}
+			speaker = "Assistant:"
+		}
+		a.announced[part.ID] = "text"
+		return append([]string{speaker}, strings.Split(strings.TrimSpace(part.Text), "\n")...)
+	case opencode.ToolPart:
+		status := part.State.Status
+		if status == opencode.ToolPartStateStatusPending || a.announced[part.ID] == string(status) {
+			return nil
+		}
+		a.announced[part.ID] = string(status)
+		switch status {
+		case opencode.ToolPartStateStatusRunning:
+			return []string{strings.TrimSpace("Running " + part.Tool + " " + part.State.Title)}
+		case opencode.ToolPartStateStatusError:
+			return []string{"Tool " + part.Tool + " failed: " + part.State.Error}
+		}
+	}
+	return nil
+}
+
+// Messages returns the lines announcing the messages, like those of a
+// session as it is opened
+func (a *Announcer) Messages(messages []Message) []string {
+	var lines []string
+	for _, message := range messages {
+		role := opencode.MessageRoleUser
+		if _, ok := message.Info.(opencode.AssistantMessage); ok {
+			role = opencode.MessageRoleAssistant
+		}
+		for _, part := range message.Parts {
+			lines = append(lines, a.Part(part, role)...)
+		}
+	}
+	return lines
+}
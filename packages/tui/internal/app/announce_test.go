@@ -0,0 +1,46 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestAnnouncer(t *testing.T) {
+	a := &Announcer{}
+	assistant := opencode.MessageRoleAssistant
+
+	writing := opencode.TextPart{ID: "text", Text: "Hello", Time: opencode.TextPartTime{Start: 1}}
+	if lines := a.Part(writing, assistant); lines != nil {
+		t.Errorf("announced a text being written: %q", lines)
+	}
+	done := opencode.TextPart{ID: "text", Text: "Hello\nthere\n", Time: opencode.TextPartTime{Start: 1, End: 2}}
+	if lines := a.Part(done, assistant); !slices.Equal(lines, []string{"Assistant:", "Hello", "there"}) {
+		t.Errorf("got %q", lines)
+	}
+	if lines := a.Part(done, assistant); lines != nil {
+		t.Errorf("announced a text twice: %q", lines)
+	}
+
+	prompt := opencode.TextPart{ID: "prompt", Text: "Fix it"}
+	if lines := a.Part(prompt, opencode.MessageRoleUser); !slices.Equal(lines, []string{"You:", "Fix it"}) {
+		t.Errorf("got %q", lines)
+	}
+	if lines := a.Part(opencode.TextPart{ID: "file", Text: "contents", Synthetic: true}, opencode.MessageRoleUser); lines != nil {
+		t.Errorf("announced a synthetic text: %q", lines)
+	}
+
+	tool := func(status opencode.ToolPartStateStatus) opencode.ToolPart {
+		return opencode.ToolPart{ID: "tool", Tool: "bash", State: opencode.ToolPartState{Status: status, Title: "go test", Error: "exit 1"}}
+	}
+	if lines := a.Part(tool(opencode.ToolPartStateStatusRunning), assistant); !slices.Equal(lines, []string{"Running bash go test"}) {
+		t.Errorf("got %q", lines)
+	}
+	if lines := a.Part(tool(opencode.ToolPartStateStatusRunning), assistant); lines != nil {
+		t.Errorf("announced a running tool twice: %q", lines)
+	}
+	if lines := a.Part(tool(opencode.ToolPartStateStatusError), assistant); !slices.Equal(lines, []string{"Tool bash failed: exit 1"}) {
+		t.Errorf("got %q", lines)
+	}
+}
@@ -5,14 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"log/slog"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 	opencode "github.com/sst/opencode-sdk-go"
-	"github.com/sst/opencode/internal/clipboard"
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/components/toast"
 	"github.com/sst/opencode/internal/id"
@@ -26,28 +27,60 @@ type Message struct {
 	Parts []opencode.PartUnion
 }
 
+// ID returns the ID of the user or assistant message
+func (m Message) ID() string {
+	switch casted := m.Info.(type) {
+	case opencode.UserMessage:
+		return casted.ID
+	case opencode.AssistantMessage:
+		return casted.ID
+	}
+	return ""
+}
+
 type App struct {
-	Info             opencode.App
-	Agents           []opencode.Agent
-	Providers        []opencode.Provider
-	Version          string
-	StatePath        string
-	Config           *opencode.Config
-	Client           *opencode.Client
-	State            *State
-	AgentIndex       int
-	Agent            *opencode.Agent
-	Provider         *opencode.Provider
-	Model            *opencode.Model
-	Session          *opencode.Session
-	Messages         []Message
-	Commands         commands.CommandRegistry
-	InitialModel     *string
-	InitialPrompt    *string
-	InitialAgent     *string
-	InitialSession   *string
+	Info           opencode.App
+	Agents         []opencode.Agent
+	Providers      []opencode.Provider
+	Version        string
+	StatePath      string
+	Config         *opencode.Config
+	Client         *opencode.Client
+	State          *State
+	AgentIndex     int
+	Agent          *opencode.Agent
+	Provider       *opencode.Provider
+	Model          *opencode.Model
+	Session        *opencode.Session
+	Messages       []Message
+	Evicted        Evicted
+	Commands       commands.CommandRegistry
+	InitialModel   *string
+	InitialPrompt  *string
+	InitialAgent   *string
+	InitialSession *string
+	PendingSystem  string
+	PendingPins    []string
+	Fallback       *Fallback
+	ShowLauncher   bool
+	// ReadOnly observes the sessions without prompting or approving tools
+	ReadOnly bool
+	// Replaying plays a recording back, read-only, answering nothing to the
+	// server and keeping the state as it was
+	Replaying bool
+	// ScreenReader drops the overlays, reduces motion and with Inline prints
+	// the conversation as plain lines
+	ScreenReader bool
+	// Inline runs in the terminal's own screen instead of the alternate one
+	Inline           bool
 	compactCancel    context.CancelFunc
+	dailySpend       *DailySpendLoadedMsg
 	IsLeaderSequence bool
+	// KeyDisambiguation is set once the terminal reports it tells apart keys
+	// like shift+enter and enter
+	KeyDisambiguation bool
+	// Keymap is the keymap of the focused pane
+	Keymap commands.Keymap
 }
 
 type SessionCreatedMsg = struct {
@@ -69,15 +102,36 @@ type ModelSelectedMsg struct {
 type SessionClearedMsg struct{}
 type CompactSessionMsg struct{}
 type SendPrompt = Prompt
+
+// ReadOnlyMessage explains why nothing can be sent in read-only mode
+const ReadOnlyMessage = "Read-only mode, prompts and commands are not sent"
+
 type SetEditorContentMsg struct {
 	Text string
 }
 type FileRenderedMsg struct {
 	FilePath string
 }
+
+// OpenFileMsg opens a file in the file viewer, scrolled to a line
+type OpenFileMsg struct {
+	Path string
+	// Line is 0-based, like the ranges of symbols
+	Line int
+}
+
+// EditFileMsg opens a file at a line in the IDE kuuzuki runs in, or in
+// $EDITOR
+type EditFileMsg struct {
+	Path string
+	// Line is 0-based, like the ranges of symbols
+	Line int
+}
 type ExecuteShellCommand struct {
 	SessionID string
 	Command   string
+	// Persistent runs the command in the session's shell, as shell mode does
+	Persistent bool
 }
 
 func New(
@@ -94,18 +148,48 @@ func New(
 	util.RootPath = appInfo.Path.Root
 	util.CwdPath = appInfo.Path.Cwd
 
-	configInfo, err := httpClient.Config.Get(ctx)
-	if err != nil {
-		return nil, err
+	start := time.Now()
+	appStatePath := filepath.Join(appInfo.Path.State, "tui")
+
+	// the config, the state file and the custom themes don't depend on each
+	// other, so they're loaded concurrently
+	var (
+		wg         sync.WaitGroup
+		configInfo *opencode.Config
+		configErr  error
+		appState   *State
+		stateErr   error
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		configInfo, configErr = httpClient.Config.Get(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		appState, stateErr = LoadState(appStatePath)
+	}()
+	go func() {
+		defer wg.Done()
+		if err := theme.LoadThemesFromDirectories(
+			appInfo.Path.Config,
+			appInfo.Path.Root,
+			appInfo.Path.Cwd,
+		); err != nil {
+			slog.Warn("Failed to load themes from directories", "error", err)
+		}
+	}()
+	wg.Wait()
+
+	if configErr != nil {
+		return nil, configErr
 	}
 
 	if configInfo.Keybinds.Leader == "" {
 		configInfo.Keybinds.Leader = "ctrl+x"
 	}
 
-	appStatePath := filepath.Join(appInfo.Path.State, "tui")
-	appState, err := LoadState(appStatePath)
-	if err != nil {
+	if stateErr != nil {
 		appState = NewState()
 		SaveState(appStatePath, appState)
 	}
@@ -114,6 +198,14 @@ func New(
 		appState.ModeModel = make(map[string]ModeModel)
 	}
 
+	if appState.SessionSystem == nil {
+		appState.SessionSystem = make(map[string]string)
+	}
+
+	if appState.Scratchpads == nil {
+		appState.Scratchpads = make(map[string]string)
+	}
+
 	if configInfo.Theme != "" {
 		appState.Theme = configInfo.Theme
 	}
@@ -147,14 +239,6 @@ func New(
 		}
 	}
 
-	if err := theme.LoadThemesFromDirectories(
-		appInfo.Path.Config,
-		appInfo.Path.Root,
-		appInfo.Path.Cwd,
-	); err != nil {
-		slog.Warn("Failed to load themes from directories", "error", err)
-	}
-
 	if appState.Theme != "" {
 		if appState.Theme == "system" && styles.Terminal != nil {
 			theme.UpdateSystemTheme(
@@ -165,7 +249,7 @@ func New(
 		theme.SetTheme(appState.Theme)
 	}
 
-	slog.Debug("Loaded config", "config", configInfo)
+	slog.Debug("Loaded config", "config", configInfo, "elapsed", time.Since(start))
 
 	app := &App{
 		Info:           appInfo,
@@ -197,6 +281,14 @@ func New(
 func (a *App) Keybind(commandName commands.CommandName) string {
 	command := a.Commands[commandName]
 	kb := command.Keybindings[0]
+	// show a key the terminal can send, e.g. ctrl+j rather than shift+enter
+	if !a.KeyDisambiguation {
+		if i := slices.IndexFunc(command.Keybindings, func(k commands.Keybinding) bool {
+			return !k.NeedsKeyDisambiguation()
+		}); i >= 0 {
+			kb = command.Keybindings[i]
+		}
+	}
 	key := kb.Key
 	if kb.RequiresLeader {
 		key = a.Config.Keybinds.Leader + " " + kb.Key
@@ -217,17 +309,6 @@ func (a *App) Key(commandName commands.CommandName) string {
 	return base(key) + muted(" "+command.Description)
 }
 
-func SetClipboard(text string) tea.Cmd {
-	var cmds []tea.Cmd
-	cmds = append(cmds, func() tea.Msg {
-		clipboard.Write(clipboard.FmtText, []byte(text))
-		return nil
-	})
-	// try to set the clipboard using OSC52 for terminals that support it
-	cmds = append(cmds, tea.SetClipboard(text))
-	return tea.Sequence(cmds...)
-}
-
 func (a *App) cycleMode(forward bool) (*App, tea.Cmd) {
 	if forward {
 		a.AgentIndex++
@@ -240,6 +321,12 @@ func (a *App) cycleMode(forward bool) (*App, tea.Cmd) {
 			a.AgentIndex = len(a.Agents) - 1
 		}
 	}
+	return a.selectAgent(a.AgentIndex)
+}
+
+// selectAgent makes the agent at index current, switching to its preferred model
+func (a *App) selectAgent(index int) (*App, tea.Cmd) {
+	a.AgentIndex = index
 	a.Agent = &a.Agents[a.AgentIndex]
 
 	modelID := a.Agent.Model.ModelID
@@ -278,13 +365,29 @@ func (a *App) SwitchAgentReverse() (*App, tea.Cmd) {
 	return a.cycleMode(false)
 }
 
+// ProvidersLoadedMsg carries the providers fetched at startup
+type ProvidersLoadedMsg struct {
+	Response *opencode.AppProvidersResponse
+}
+
+// InitializeProvider fetches the providers in the background, so the first
+// frame doesn't wait on the server
 func (a *App) InitializeProvider() tea.Cmd {
-	providersResponse, err := a.Client.App.Providers(context.Background())
-	if err != nil {
-		slog.Error("Failed to list providers", "error", err)
-		// TODO: notify user
-		return nil
+	return func() tea.Msg {
+		start := time.Now()
+		providersResponse, err := a.Client.App.Providers(context.Background())
+		if err != nil {
+			slog.Error("Failed to list providers", "error", err)
+			return toast.NewErrorToast("Failed to load the providers: " + err.Error())()
+		}
+		slog.Debug("Loaded providers", "elapsed", time.Since(start))
+		return ProvidersLoadedMsg{Response: providersResponse}
 	}
+}
+
+// SetProviders selects the initial model among the fetched providers, then
+// loads the initial session and sends the initial prompt
+func (a *App) SetProviders(providersResponse *opencode.AppProvidersResponse) tea.Cmd {
 	providers := providersResponse.Providers
 	var defaultProvider *opencode.Provider
 	var defaultModel *opencode.Model
@@ -418,6 +521,73 @@ func (a *App) InitializeProvider() tea.Cmd {
 	return tea.Sequence(cmds...)
 }
 
+type modelMatch struct {
+	provider opencode.Provider
+	model    opencode.Model
+	rank     int
+}
+
+// FindModel resolves a model reference in the format of provider/model
+func (a *App) FindModel(ref string) (*opencode.Provider, *opencode.Model) {
+	providerID, modelID, ok := strings.Cut(ref, "/")
+	if !ok {
+		return nil, nil
+	}
+	for _, provider := range a.Providers {
+		if provider.ID != providerID {
+			continue
+		}
+		for _, model := range provider.Models {
+			if model.ID == modelID {
+				return &provider, &model
+			}
+		}
+	}
+	return nil, nil
+}
+
+// MatchModel resolves a model typed by the user by "provider/model", exact
+// model ID, or a partial match on the model ID or name, preferring the closest
+// match. It returns nil when nothing matches.
+func (a *App) MatchModel(query string) (*opencode.Provider, *opencode.Model) {
+	query = strings.ToLower(query)
+	var matches []modelMatch
+	for _, provider := range a.Providers {
+		for _, model := range provider.Models {
+			id := strings.ToLower(model.ID)
+			name := strings.ToLower(model.Name)
+			rank := -1
+			switch {
+			case strings.ToLower(provider.ID)+"/"+id == query:
+				rank = 0
+			case id == query:
+				rank = 1
+			case strings.HasPrefix(id, query):
+				rank = 2
+			case strings.Contains(id, query), strings.Contains(name, query):
+				rank = 3
+			}
+			if rank >= 0 {
+				matches = append(matches, modelMatch{provider, model, rank})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	slices.SortFunc(matches, func(a, b modelMatch) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		if len(a.model.ID) != len(b.model.ID) {
+			return len(a.model.ID) - len(b.model.ID)
+		}
+		return strings.Compare(a.provider.ID+"/"+a.model.ID, b.provider.ID+"/"+b.model.ID)
+	})
+	return &matches[0].provider, &matches[0].model
+}
+
 func getDefaultModel(
 	response *opencode.AppProvidersResponse,
 	provider opencode.Provider,
@@ -445,6 +615,9 @@ func (a *App) IsBusy() bool {
 }
 
 func (a *App) SaveState() tea.Cmd {
+	if a.Replaying {
+		return nil
+	}
 	return func() tea.Msg {
 		err := SaveState(a.StatePath, a.State)
 		if err != nil {
@@ -482,6 +655,9 @@ func (a *App) InitializeProject(ctx context.Context) tea.Cmd {
 }
 
 func (a *App) CompactSession(ctx context.Context) tea.Cmd {
+	if a.ReadOnly {
+		return toast.NewInfoToast(ReadOnlyMessage)
+	}
 	if a.compactCancel != nil {
 		a.compactCancel()
 	}
@@ -530,6 +706,15 @@ func (a *App) CreateSession(ctx context.Context) (*opencode.Session, error) {
 
 func (a *App) SendPrompt(ctx context.Context, prompt Prompt) (*App, tea.Cmd) {
 	var cmds []tea.Cmd
+	// a leading @model:<name> sends just this prompt with another model
+	provider, model, prompt, err := a.PromptModel(prompt)
+	if err != nil {
+		return a, toast.NewErrorToast(err.Error())
+	}
+	if prompt.ModelID == "" {
+		a.Fallback = nil
+	}
+
 	if a.Session.ID == "" {
 		session, err := a.CreateSession(ctx)
 		if err != nil {
@@ -537,6 +722,21 @@ func (a *App) SendPrompt(ctx context.Context, prompt Prompt) (*App, tea.Cmd) {
 		}
 		a.Session = session
 		cmds = append(cmds, util.CmdHandler(SessionCreatedMsg{Session: session}))
+		if a.PendingSystem != "" {
+			a.State.SessionSystem[session.ID] = a.PendingSystem
+			a.PendingSystem = ""
+			cmds = append(cmds, a.SaveState())
+		}
+		if err := a.pinPendingFiles(ctx); err != nil {
+			cmds = append(cmds, toast.NewErrorToast("Failed to pin files: "+err.Error()))
+		}
+	}
+	// the sandbox branch is switched to before the agent can edit anything
+	if a.sandboxNeeded() {
+		if err := a.StartSandbox(); err != nil {
+			return a, toast.NewErrorToast("Failed to start the sandbox: " + err.Error())
+		}
+		cmds = append(cmds, a.SaveState())
 	}
 
 	messageID := id.Ascending(id.Message)
@@ -544,14 +744,19 @@ func (a *App) SendPrompt(ctx context.Context, prompt Prompt) (*App, tea.Cmd) {
 
 	a.Messages = append(a.Messages, message)
 
+	params := opencode.SessionChatParams{
+		ProviderID: opencode.F(provider.ID),
+		ModelID:    opencode.F(model.ID),
+		Agent:      opencode.F(a.Agent.Name),
+		MessageID:  opencode.F(messageID),
+		Parts:      opencode.F(message.ToSessionChatParams()),
+	}
+	if system, ok := a.SystemPrompt(); ok {
+		params.System = opencode.F(system)
+	}
+
 	cmds = append(cmds, func() tea.Msg {
-		_, err := a.Client.Session.Chat(ctx, a.Session.ID, opencode.SessionChatParams{
-			ProviderID: opencode.F(a.Provider.ID),
-			ModelID:    opencode.F(a.Model.ID),
-			Agent:      opencode.F(a.Agent.Name),
-			MessageID:  opencode.F(messageID),
-			Parts:      opencode.F(message.ToSessionChatParams()),
-		})
+		_, err := a.Client.Session.Chat(ctx, a.Session.ID, params)
 		if err != nil {
 			errormsg := fmt.Sprintf("failed to send message: %v", err)
 			slog.Error(errormsg)
@@ -662,9 +867,10 @@ func (a *App) ListProviders(ctx context.Context) ([]opencode.Provider, error) {
 	return providers.Providers, nil
 }
 
-func (a *App) ExecuteShellCommand(ctx context.Context, sessionID string, command string) (*opencode.AssistantMessage, error) {
+func (a *App) ExecuteShellCommand(ctx context.Context, sessionID string, command string, persistent bool) (*opencode.AssistantMessage, error) {
 	response, err := a.Client.Session.Shell(ctx, sessionID, opencode.SessionShellParams{
-		Command: opencode.F(command),
+		Command:    opencode.F(command),
+		Persistent: opencode.F(persistent),
 	})
 	if err != nil {
 		slog.Error("Failed to execute shell command", "error", err, "command", command)
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// ProviderKeyRequestedMsg asks for the API key of a provider
+type ProviderKeyRequestedMsg struct {
+	ProviderID string
+	Name       string
+}
+
+// ProviderAuthUpdatedMsg is sent once a provider's API key was stored or
+// removed, with the providers as reloaded by the server
+type ProviderAuthUpdatedMsg struct {
+	ProviderID string
+	Removed    bool
+	Providers  []opencode.Provider
+}
+
+// ListProviderAuth returns the type of the credentials stored for each
+// provider, providers configured through the environment aren't included
+func (a *App) ListProviderAuth(ctx context.Context) (map[string]opencode.AuthListResponse, error) {
+	response, err := a.Client.Auth.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return map[string]opencode.AuthListResponse{}, nil
+	}
+	return *response, nil
+}
+
+// SetProviderKey stores the API key of a provider, replacing any credentials
+// it had
+func (a *App) SetProviderKey(providerID, key string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if _, err := a.Client.Auth.Set(ctx, providerID, opencode.AuthSetParams{Key: opencode.F(key)}); err != nil {
+			slog.Error("Failed to set provider key", "provider", providerID, "error", err)
+			return toast.NewErrorToast("Failed to save the API key: " + err.Error())()
+		}
+		return a.providerAuthUpdated(ctx, providerID, false)
+	}
+}
+
+// RemoveProviderKey removes the credentials stored for a provider
+func (a *App) RemoveProviderKey(providerID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if _, err := a.Client.Auth.Remove(ctx, providerID); err != nil {
+			slog.Error("Failed to remove provider key", "provider", providerID, "error", err)
+			return toast.NewErrorToast("Failed to remove the API key: " + err.Error())()
+		}
+		return a.providerAuthUpdated(ctx, providerID, true)
+	}
+}
+
+func (a *App) providerAuthUpdated(ctx context.Context, providerID string, removed bool) tea.Msg {
+	providers, err := a.ListProviders(ctx)
+	if err != nil {
+		slog.Error("Failed to reload providers", "error", err)
+	}
+	return ProviderAuthUpdatedMsg{
+		ProviderID: providerID,
+		Removed:    removed,
+		Providers:  providers,
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+const defaultBudgetWarnAt = 80
+
+// Budget caps the cost of the current session and of all of today's sessions.
+// Spending WarnAt percent of a cap warns, spending all of it asks for
+// confirmation before each prompt. A zero cap disables it.
+type Budget struct {
+	Session float64
+	Daily   float64
+	WarnAt  float64
+}
+
+func (b Budget) Enabled() bool {
+	return b.Session > 0 || b.Daily > 0
+}
+
+type BudgetLevel int
+
+const (
+	BudgetOK BudgetLevel = iota
+	BudgetWarning
+	BudgetExceeded
+)
+
+// BudgetStatus describes the budget closest to running out
+type BudgetStatus struct {
+	Level BudgetLevel
+	// Cap is "session" or "daily"
+	Cap       string
+	Limit     float64
+	Spent     float64
+	Remaining float64
+}
+
+func (s BudgetStatus) String() string {
+	return fmt.Sprintf("%s budget: $%.2f of $%.2f spent", s.Cap, s.Spent, s.Limit)
+}
+
+// Check returns the status of the budget with the largest share spent, and
+// false if no budget is set
+func (b Budget) Check(sessionSpend, dailySpend float64) (BudgetStatus, bool) {
+	var status BudgetStatus
+	found := false
+	for _, candidate := range []struct {
+		name  string
+		limit float64
+		spent float64
+	}{
+		{"session", b.Session, sessionSpend},
+		{"daily", b.Daily, dailySpend},
+	} {
+		if candidate.limit <= 0 {
+			continue
+		}
+		if found && candidate.spent/candidate.limit <= status.Spent/status.Limit {
+			continue
+		}
+		found = true
+		status = BudgetStatus{
+			Cap:       candidate.name,
+			Limit:     candidate.limit,
+			Spent:     candidate.spent,
+			Remaining: max(candidate.limit-candidate.spent, 0),
+		}
+		switch {
+		case candidate.spent >= candidate.limit:
+			status.Level = BudgetExceeded
+		case candidate.spent >= candidate.limit*b.WarnAt/100:
+			status.Level = BudgetWarning
+		default:
+			status.Level = BudgetOK
+		}
+	}
+	return status, found
+}
+
+// Budget returns the cost budgets from the tui config
+func (a *App) Budget() Budget {
+	budget := a.Config.Tui.Budget
+	warnAt := budget.WarnAt
+	if warnAt <= 0 {
+		warnAt = defaultBudgetWarnAt
+	}
+	return Budget{
+		Session: budget.Session,
+		Daily:   budget.Daily,
+		WarnAt:  warnAt,
+	}
+}
+
+// DailySpendLoadedMsg carries what today's sessions other than the current
+// one have cost
+type DailySpendLoadedMsg struct {
+	Day       string
+	SessionID string
+	Cost      float64
+}
+
+// SpendSince returns the cost of the assistant messages created since the
+// given time
+func SpendSince(messages []Message, since time.Time) float64 {
+	cost := float64(0)
+	for _, message := range messages {
+		assistant, ok := message.Info.(opencode.AssistantMessage)
+		if ok && int64(assistant.Time.Created) >= since.UnixMilli() {
+			cost += assistant.Cost
+		}
+	}
+	return cost
+}
+
+func startOfDay(now time.Time) time.Time {
+	year, month, day := now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+// LoadDailySpend adds up the cost of today's sessions other than the current
+// one, which is tracked as its messages arrive
+func (a *App) LoadDailySpend() tea.Cmd {
+	if a.Budget().Daily <= 0 {
+		return nil
+	}
+	sessionID := a.Session.ID
+	return func() tea.Msg {
+		ctx := context.Background()
+		since := startOfDay(time.Now())
+		sessions, err := a.ListSessions(ctx)
+		if err != nil {
+			slog.Error("Failed to list sessions for the daily budget", "error", err)
+			return nil
+		}
+		cost := float64(0)
+		for _, session := range sessions {
+			if session.ID == sessionID || int64(session.Time.Updated) < since.UnixMilli() {
+				continue
+			}
+			messages, err := a.ListMessages(ctx, session.ID)
+			if err != nil {
+				slog.Error("Failed to list messages for the daily budget", "error", err)
+				return nil
+			}
+			cost += SpendSince(messages, since)
+		}
+		return DailySpendLoadedMsg{
+			Day:       since.Format(time.DateOnly),
+			SessionID: sessionID,
+			Cost:      cost,
+		}
+	}
+}
+
+// SetDailySpend records the cost of today's other sessions
+func (a *App) SetDailySpend(msg DailySpendLoadedMsg) {
+	a.dailySpend = &msg
+}
+
+// BudgetStatus returns the status of the budget closest to running out, and
+// false if no budget is set
+func (a *App) BudgetStatus(now time.Time) (BudgetStatus, bool) {
+	budget := a.Budget()
+	if !budget.Enabled() {
+		return BudgetStatus{}, false
+	}
+	since := startOfDay(now)
+	daily := SpendSince(a.Messages, since) + a.Evicted.spendSince(since)
+	if spend := a.dailySpend; spend != nil &&
+		spend.Day == since.Format(time.DateOnly) &&
+		// a session created since was not part of the other sessions
+		(spend.SessionID == a.Session.ID || spend.SessionID == "") {
+		daily += spend.Cost
+	}
+	return budget.Check(a.SessionUsage().Cost, daily)
+}
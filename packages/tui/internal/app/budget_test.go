@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestBudgetCheck(t *testing.T) {
+	budget := Budget{Session: 10, Daily: 50, WarnAt: 80}
+
+	tests := []struct {
+		name    string
+		session float64
+		daily   float64
+		cap     string
+		level   BudgetLevel
+	}{
+		{"under both", 2, 5, "session", BudgetOK},
+		{"session nearly spent", 8.5, 20, "session", BudgetWarning},
+		{"daily closest to running out", 5, 45, "daily", BudgetWarning},
+		{"session spent", 10, 20, "session", BudgetExceeded},
+		{"daily spent", 5, 60, "daily", BudgetExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, ok := budget.Check(tt.session, tt.daily)
+			if !ok {
+				t.Fatal("budget not found")
+			}
+			if status.Cap != tt.cap || status.Level != tt.level {
+				t.Errorf("got %s at level %d, want %s at level %d", status.Cap, status.Level, tt.cap, tt.level)
+			}
+		})
+	}
+
+	if status, _ := budget.Check(12, 0); status.Remaining != 0 {
+		t.Errorf("remaining = %v, want 0 once spent", status.Remaining)
+	}
+	if _, ok := (Budget{WarnAt: 80}).Check(100, 100); ok {
+		t.Error("found a budget when none is set")
+	}
+}
+
+func TestSpendSince(t *testing.T) {
+	since := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	message := func(created time.Time, cost float64) Message {
+		return Message{Info: opencode.AssistantMessage{
+			Cost: cost,
+			Time: opencode.AssistantMessageTime{Created: float64(created.UnixMilli())},
+		}}
+	}
+	messages := []Message{
+		message(since.Add(-time.Hour), 1),
+		{Info: opencode.UserMessage{}},
+		message(since.Add(time.Hour), 0.25),
+		message(since.Add(2*time.Hour), 0.5),
+	}
+	if spent := SpendSince(messages, since); spent != 0.75 {
+		t.Errorf("spent = %v, want 0.75", spent)
+	}
+}
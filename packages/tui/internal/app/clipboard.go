@@ -0,0 +1,58 @@
+package app
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/clipboard"
+)
+
+// clipboardTargets picks where copied text goes for the configured mode,
+// auto uses the system clipboard when there is one and OSC 52 as well, which
+// reaches the local machine's clipboard over SSH
+func clipboardTargets(mode opencode.ConfigTuiClipboard, native bool) (useNative, useOSC52 bool) {
+	switch mode {
+	case opencode.ConfigTuiClipboardNative:
+		return true, false
+	case opencode.ConfigTuiClipboardOsc52:
+		return false, true
+	case opencode.ConfigTuiClipboardBoth:
+		return true, true
+	}
+	return native, true
+}
+
+// osc52 is the escape sequence that sets the clipboard of the terminal to
+// text, wrapped for tmux to pass it on to the outer terminal
+func osc52(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if !tmux {
+		return seq
+	}
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// SetClipboard copies text to the system clipboard, or to the clipboard of the
+// terminal with OSC 52, as configured
+func (a *App) SetClipboard(text string) tea.Cmd {
+	var mode opencode.ConfigTuiClipboard
+	if a.Config != nil {
+		mode = a.Config.Tui.Clipboard
+	}
+	useNative, useOSC52 := clipboardTargets(mode, clipboard.Init() == nil)
+
+	var cmds []tea.Cmd
+	if useNative {
+		cmds = append(cmds, func() tea.Msg {
+			clipboard.Write(clipboard.FmtText, []byte(text))
+			return nil
+		})
+	}
+	if useOSC52 {
+		cmds = append(cmds, tea.Raw(osc52(text, os.Getenv("TMUX") != "")))
+	}
+	return tea.Sequence(cmds...)
+}
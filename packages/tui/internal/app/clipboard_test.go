@@ -0,0 +1,41 @@
+package app
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestClipboardTargets(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       opencode.ConfigTuiClipboard
+		native     bool
+		wantNative bool
+		wantOSC52  bool
+	}{
+		{"auto", "", true, true, true},
+		{"auto set", opencode.ConfigTuiClipboardAuto, true, true, true},
+		{"auto without a system clipboard", "", false, false, true},
+		{"native", opencode.ConfigTuiClipboardNative, false, true, false},
+		{"osc52", opencode.ConfigTuiClipboardOsc52, true, false, true},
+		{"both", opencode.ConfigTuiClipboardBoth, true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useNative, useOSC52 := clipboardTargets(tt.mode, tt.native)
+			if useNative != tt.wantNative || useOSC52 != tt.wantOSC52 {
+				t.Errorf("clipboardTargets() = %v, %v, want %v, %v", useNative, useOSC52, tt.wantNative, tt.wantOSC52)
+			}
+		})
+	}
+}
+
+func TestOSC52(t *testing.T) {
+	if got := osc52("hi", false); got != "\x1b]52;c;aGk=\x07" {
+		t.Errorf("osc52() = %q", got)
+	}
+	if got := osc52("hi", true); got != "\x1bPtmux;\x1b\x1b]52;c;aGk=\x07\x1b\\" {
+		t.Errorf("osc52() in tmux = %q", got)
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/git"
+)
+
+const commitMessagePrompt = `You write git commit messages. Reply with the commit message only, no
+preamble and no code fences: a summary line of at most 72 characters in the
+imperative mood, then, if the change needs it, a blank line and a short body
+saying why.`
+
+// commitMessageTools are turned off while the message is written, the model
+// only has to read the diff it is given
+var commitMessageTools = map[string]bool{
+	"bash": false, "edit": false, "write": false, "patch": false,
+	"task": false, "todowrite": false, "webfetch": false,
+}
+
+// CommitMessageMsg is the commit message the model wrote for the changes
+type CommitMessageMsg struct {
+	Message string
+	Err     error
+}
+
+// WriteCommitMessage asks the current model for a commit message for the
+// changes, in a throwaway session so the conversation is left alone
+func (a *App) WriteCommitMessage(changes git.Changes) tea.Cmd {
+	if a.Provider == nil || a.Model == nil {
+		return func() tea.Msg {
+			return CommitMessageMsg{Err: errors.New("no model to write the message")}
+		}
+	}
+	providerID, modelID := a.Provider.ID, a.Model.ID
+	return func() tea.Msg {
+		ctx := context.Background()
+		session, err := a.Client.Session.New(ctx)
+		if err != nil {
+			return CommitMessageMsg{Err: err}
+		}
+		defer func() {
+			if _, err := a.Client.Session.Delete(ctx, session.ID); err != nil {
+				slog.Warn("Failed to delete the commit message session", "error", err)
+			}
+		}()
+
+		message, err := a.Client.Session.Chat(ctx, session.ID, opencode.SessionChatParams{
+			ProviderID: opencode.F(providerID),
+			ModelID:    opencode.F(modelID),
+			System:     opencode.F(commitMessagePrompt),
+			Tools:      opencode.F(commitMessageTools),
+			Parts: opencode.F([]opencode.SessionChatParamsPartUnion{
+				opencode.TextPartInputParam{
+					Type: opencode.F(opencode.TextPartInputTypeText),
+					Text: opencode.F(commitMessageRequest(changes)),
+				},
+			}),
+		})
+		if err != nil {
+			return CommitMessageMsg{Err: err}
+		}
+		full, err := a.Client.Session.Message(ctx, session.ID, message.ID)
+		if err != nil {
+			return CommitMessageMsg{Err: err}
+		}
+		var text strings.Builder
+		for _, part := range full.Parts {
+			if part.Type == opencode.PartTypeText {
+				text.WriteString(part.Text)
+			}
+		}
+		written := cleanCommitMessage(text.String())
+		if written == "" {
+			return CommitMessageMsg{Err: errors.New("the model wrote no message")}
+		}
+		return CommitMessageMsg{Message: written}
+	}
+}
+
+// Commit commits the changes with the message in the project
+func (a *App) Commit(changes git.Changes, message string) tea.Cmd {
+	root := a.Info.Path.Cwd
+	return func() tea.Msg {
+		summary, err := changes.Commit(root, message)
+		if err != nil {
+			slog.Error("Failed to commit", "error", err)
+			return toast.NewErrorToast("Failed to commit: " + err.Error())()
+		}
+		summary, _, _ = strings.Cut(summary, "\n")
+		return toast.NewSuccessToast(summary)()
+	}
+}
+
+// commitMessageRequest describes the changes for the model, the file list
+// first since the diff may be cut
+func commitMessageRequest(changes git.Changes) string {
+	var b strings.Builder
+	b.WriteString("Write the commit message for these changes.\n\nFiles:\n")
+	for _, file := range changes.Files {
+		switch {
+		case file.Untracked:
+			fmt.Fprintf(&b, "  %s (new)\n", file.Path)
+		case file.Binary:
+			fmt.Fprintf(&b, "  %s (binary)\n", file.Path)
+		default:
+			fmt.Fprintf(&b, "  %s +%d -%d\n", file.Path, file.Added, file.Removed)
+		}
+	}
+	b.WriteString("\nDiff:\n")
+	b.WriteString(changes.Patch)
+	return b.String()
+}
+
+// cleanCommitMessage drops the code fence models wrap messages in despite
+// being told not to
+func cleanCommitMessage(text string) string {
+	text = strings.TrimSpace(text)
+	if rest, ok := strings.CutPrefix(text, "```"); ok {
+		// the fence may name a language on its first line
+		if _, body, ok := strings.Cut(rest, "\n"); ok {
+			text = strings.TrimSuffix(strings.TrimSpace(body), "```")
+		}
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sst/opencode/internal/git"
+)
+
+func TestCleanCommitMessage(t *testing.T) {
+	tests := map[string]string{
+		"Fix the parser\n":                         "Fix the parser",
+		"```\nFix the parser\n\nWhy it broke\n```": "Fix the parser\n\nWhy it broke",
+		"```text\nAdd a flag\n```\n":               "Add a flag",
+		"  \n":                                     "",
+	}
+	for text, want := range tests {
+		if got := cleanCommitMessage(text); got != want {
+			t.Errorf("cleanCommitMessage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestCommitMessageRequest(t *testing.T) {
+	request := commitMessageRequest(git.Changes{
+		Files: []git.FileStat{
+			{Path: "main.go", Added: 3, Removed: 1},
+			{Path: "logo.png", Binary: true},
+			{Path: "notes.md", Untracked: true},
+		},
+		Patch: "+fmt.Println()\n",
+	})
+	for _, want := range []string{"main.go +3 -1", "logo.png (binary)", "notes.md (new)", "+fmt.Println()"} {
+		if !strings.Contains(request, want) {
+			t.Errorf("commitMessageRequest() = %q, want it to contain %q", request, want)
+		}
+	}
+}
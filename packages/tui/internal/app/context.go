@@ -0,0 +1,270 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+type ContextItemKind string
+
+const (
+	ContextSystem  ContextItemKind = "system"
+	ContextPinned  ContextItemKind = "pinned"
+	ContextMessage ContextItemKind = "message"
+	ContextFile    ContextItemKind = "file"
+	// ContextEvicted sums up the messages evicted from memory
+	ContextEvicted ContextItemKind = "evicted"
+)
+
+// ContextItem is one piece of what the next prompt will send to the model,
+// with a rough token estimate
+type ContextItem struct {
+	Kind   ContextItemKind
+	Label  string
+	Tokens int
+	// MessageID is the message the item belongs to, for messages and files
+	MessageID string
+	// Text is the content pinning the item keeps in context
+	Text string
+	// Pin is the index of a pinned item in the session's pins
+	Pin int
+	// Path is the pinned file, for pinned files
+	Path string
+}
+
+// ContextPin is a piece of context kept in the system prompt of a session,
+// so it survives compaction and dropped messages
+type ContextPin struct {
+	Label string `toml:"label"`
+	Text  string `toml:"text"`
+}
+
+const pinnedPreamble = "The user pinned the following context to keep it available for the rest of the session:\n"
+
+// ContextItems breaks down what the current session sends to the model: the
+// system prompt of the latest response, pinned context, and the messages
+// since the last compaction that haven't been reverted
+func (a *App) ContextItems() []ContextItem {
+	providerID := ""
+	if a.Provider != nil {
+		providerID = a.Provider.ID
+	}
+
+	messages := a.contextMessages()
+	var items []ContextItem
+
+	// the session's own system prompt and pins are listed separately below,
+	// as they may have changed since the latest response
+	sessionSystem, hasSessionSystem := a.State.SessionSystem[a.Session.ID]
+	for i := len(messages) - 1; i >= 0; i-- {
+		if assistant, ok := messages[i].Info.(opencode.AssistantMessage); ok && len(assistant.System) > 0 {
+			for _, system := range assistant.System {
+				if strings.Contains(system, pinnedPreamble) ||
+					strings.Contains(system, pinnedFilesPreamble) ||
+					(hasSessionSystem && system == sessionSystem) {
+					continue
+				}
+				items = append(items, ContextItem{
+					Kind:   ContextSystem,
+					Label:  firstLine(system),
+					Tokens: attachment.EstimateTextTokens(system),
+				})
+			}
+			break
+		}
+	}
+
+	if hasSessionSystem {
+		items = append(items, ContextItem{
+			Kind:   ContextSystem,
+			Label:  "session system prompt: " + firstLine(sessionSystem),
+			Tokens: attachment.EstimateTextTokens(sessionSystem),
+		})
+	}
+
+	for i, pin := range a.State.ContextPins[a.Session.ID] {
+		items = append(items, ContextItem{
+			Kind:   ContextPinned,
+			Label:  pin.Label,
+			Tokens: attachment.EstimateTextTokens(pin.Text),
+			Text:   pin.Text,
+			Pin:    i,
+		})
+	}
+
+	for _, file := range a.PinnedFiles() {
+		items = append(items, ContextItem{
+			Kind:   ContextPinned,
+			Label:  "file: " + file.Path,
+			Tokens: file.Tokens,
+			Path:   file.Path,
+		})
+	}
+
+	// evicted messages are still in context unless a loaded one summarized them
+	summarized := slices.ContainsFunc(a.Messages, func(message Message) bool {
+		assistant, ok := message.Info.(opencode.AssistantMessage)
+		return ok && assistant.Summary
+	})
+	if evicted, ok := a.Evicted.contextItem(); ok && !summarized {
+		items = append(items, evicted)
+	}
+
+	for _, message := range messages {
+		items = append(items, messageContextItems(message, providerID)...)
+	}
+	return items
+}
+
+// contextMessages returns the messages the model still sees, starting at the
+// latest compaction summary and stopping at the reverted message
+func (a *App) contextMessages() []Message {
+	start := 0
+	for i, message := range a.Messages {
+		if assistant, ok := message.Info.(opencode.AssistantMessage); ok && assistant.Summary {
+			start = i
+		}
+	}
+	revertID := a.Session.Revert.MessageID
+
+	var messages []Message
+	for _, message := range a.Messages[start:] {
+		if revertID != "" && message.ID() >= revertID {
+			break
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func messageContextItems(message Message, providerID string) []ContextItem {
+	id := message.ID()
+	role := "you"
+	if _, ok := message.Info.(opencode.AssistantMessage); ok {
+		role = "assistant"
+	}
+
+	var text strings.Builder
+	tokens := 0
+	tools := 0
+	var files []ContextItem
+	for _, part := range message.Parts {
+		switch p := part.(type) {
+		case opencode.TextPart:
+			text.WriteString(p.Text + "\n")
+			tokens += attachment.EstimateTextTokens(p.Text)
+		case opencode.ToolPart:
+			tools++
+			if state, ok := p.State.AsUnion().(opencode.ToolStateCompleted); ok {
+				input, _ := json.Marshal(state.Input)
+				tokens += attachment.EstimateTextTokens(string(input)) + attachment.EstimateTextTokens(state.Output)
+			}
+		case opencode.FilePart:
+			files = append(files, ContextItem{
+				Kind:      ContextFile,
+				Label:     p.Filename,
+				Tokens:    attachment.EstimateTokens(filePartAttachment(p), providerID),
+				MessageID: id,
+			})
+		}
+	}
+
+	label := firstLine(text.String())
+	if tools > 0 {
+		calls := fmt.Sprintf("%d tool calls", tools)
+		if tools == 1 {
+			calls = "1 tool call"
+		}
+		if label == "" {
+			label = calls
+		} else {
+			label += " (" + calls + ")"
+		}
+	}
+	item := ContextItem{
+		Kind:      ContextMessage,
+		Label:     role + ": " + label,
+		Tokens:    tokens,
+		MessageID: id,
+		Text:      strings.TrimSpace(text.String()),
+	}
+	return append([]ContextItem{item}, files...)
+}
+
+func filePartAttachment(p opencode.FilePart) *attachment.Attachment {
+	source := &attachment.FileSource{Path: p.Source.Path, Mime: p.Mime}
+	if header, data, ok := strings.Cut(p.URL, ","); ok && strings.HasPrefix(header, "data:") {
+		if decoded, err := base64.StdEncoding.DecodeString(data); err == nil {
+			source.Data = decoded
+		}
+	}
+	return &attachment.Attachment{
+		Type:      "file",
+		Filename:  p.Filename,
+		MediaType: p.Mime,
+		URL:       p.URL,
+		Source:    source,
+	}
+}
+
+func firstLine(text string) string {
+	for line := range strings.SplitSeq(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// PinContext keeps the text in the system prompt of the current session
+func (a *App) PinContext(label, text string) {
+	if a.State.ContextPins == nil {
+		a.State.ContextPins = make(map[string][]ContextPin)
+	}
+	a.State.ContextPins[a.Session.ID] = append(
+		a.State.ContextPins[a.Session.ID],
+		ContextPin{Label: label, Text: text},
+	)
+}
+
+// UnpinContext removes a pinned item from the current session
+func (a *App) UnpinContext(index int) {
+	pins := a.State.ContextPins[a.Session.ID]
+	if index < 0 || index >= len(pins) {
+		return
+	}
+	pins = append(pins[:index], pins[index+1:]...)
+	if len(pins) == 0 {
+		delete(a.State.ContextPins, a.Session.ID)
+		return
+	}
+	a.State.ContextPins[a.Session.ID] = pins
+}
+
+// SystemPrompt returns the extra system prompt sent with prompts of the
+// current session, made of its custom system prompt, pinned context and the
+// content of pinned files
+func (a *App) SystemPrompt() (string, bool) {
+	var sections []string
+	if system, ok := a.State.SessionSystem[a.Session.ID]; ok {
+		sections = append(sections, system)
+	}
+	if pins := a.State.ContextPins[a.Session.ID]; len(pins) > 0 {
+		var pinned strings.Builder
+		pinned.WriteString(pinnedPreamble)
+		for _, pin := range pins {
+			fmt.Fprintf(&pinned, "\n<pinned label=%q>\n%s\n</pinned>\n", pin.Label, pin.Text)
+		}
+		sections = append(sections, pinned.String())
+	}
+	if len(sections) == 0 {
+		return "", false
+	}
+	return strings.Join(sections, "\n\n"), true
+}
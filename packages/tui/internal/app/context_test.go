@@ -0,0 +1,62 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestContextItems(t *testing.T) {
+	a := &App{
+		State:   NewState(),
+		Session: &opencode.Session{ID: "ses_1"},
+		Messages: []Message{
+			userMessage("msg_1", "old question"),
+			{Info: opencode.AssistantMessage{ID: "msg_2", Summary: true}, Parts: []opencode.PartUnion{
+				opencode.TextPart{Text: "summary of the conversation"},
+			}},
+			userMessage("msg_3", "explain the parser"),
+			{Info: opencode.AssistantMessage{ID: "msg_4", System: []string{"You are kuuzuki.\nBe concise."}}, Parts: []opencode.PartUnion{
+				opencode.TextPart{Text: "The parser reads tokens."},
+			}},
+			userMessage("msg_5", "reverted question"),
+		},
+	}
+	a.Session.Revert.MessageID = "msg_5"
+	a.PinContext("you: keep this", "keep this")
+
+	var labels []string
+	for _, item := range a.ContextItems() {
+		labels = append(labels, string(item.Kind)+" "+item.Label)
+	}
+	want := []string{
+		"system You are kuuzuki.",
+		"pinned you: keep this",
+		"message assistant: summary of the conversation",
+		"message you: explain the parser",
+		"message assistant: The parser reads tokens.",
+	}
+	if strings.Join(labels, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ContextItems() =\n%s\nwant\n%s", strings.Join(labels, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSystemPrompt(t *testing.T) {
+	a := &App{State: NewState(), Session: &opencode.Session{ID: "ses_1"}}
+	if _, ok := a.SystemPrompt(); ok {
+		t.Fatal("SystemPrompt() ok = true without a system prompt or pins")
+	}
+
+	a.State.SessionSystem["ses_1"] = "Answer in French."
+	a.PinContext("notes", "use tabs")
+	system, ok := a.SystemPrompt()
+	if !ok || !strings.HasPrefix(system, "Answer in French.") || !strings.Contains(system, "use tabs") {
+		t.Errorf("SystemPrompt() = %q, want the session system prompt and the pin", system)
+	}
+
+	a.UnpinContext(0)
+	if system, _ := a.SystemPrompt(); system != "Answer in French." {
+		t.Errorf("SystemPrompt() after unpinning = %q, want %q", system, "Answer in French.")
+	}
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// MaxLoadedMessages caps the messages of the current session kept in memory,
+// the oldest are evicted to disk and reloaded when scrolled back to
+const MaxLoadedMessages = 300
+
+// earlierMessagesPage is the number of evicted messages reloaded at a time
+const earlierMessagesPage = 100
+
+// Evicted tracks the oldest messages of the current session dropped from
+// memory
+type Evicted struct {
+	// Count is the number of messages before the loaded ones
+	Count int
+	// Usage aggregates the usage of the evicted messages, so totals still
+	// cover the whole session
+	Usage Usage
+	// spill keeps every message evicted so far as a line of JSON, so they
+	// are reloaded without refetching the whole session
+	spill *os.File
+	size  int64
+	// messages describe the messages in spill, by position in the session
+	messages []evictedMessage
+}
+
+// evictedMessage is what is kept in memory of an evicted message
+type evictedMessage struct {
+	// offset is where the message starts in the spill file
+	offset int64
+	usage  Usage
+	// created and cost are set for assistant messages, for the daily budget
+	created time.Time
+	cost    float64
+	// tokens is the estimate of what the message adds to the context
+	tokens  int
+	summary bool
+}
+
+// spilledMessage is the line of JSON a message is spilled as, the same shape
+// the server lists messages in
+type spilledMessage struct {
+	Info  opencode.MessageUnion `json:"info"`
+	Parts []opencode.PartUnion  `json:"parts"`
+}
+
+// EarlierMessagesLoadedMsg carries evicted messages reloaded from disk
+type EarlierMessagesLoadedMsg struct {
+	SessionID string
+	// Count is the number of evicted messages when the load was requested
+	Count    int
+	Messages []Message
+	// Err is set when the messages could not be reloaded
+	Err error
+}
+
+// reset forgets the evicted messages, closing their spill file
+func (e *Evicted) reset() {
+	if e.spill != nil {
+		e.spill.Close()
+		os.Remove(e.spill.Name())
+	}
+	*e = Evicted{}
+}
+
+// write spills the messages at position from onwards, skipping the ones
+// spilled before and reloaded since
+func (e *Evicted) write(from int, messages []Message, providerID string) error {
+	if skip := len(e.messages) - from; skip > 0 {
+		messages = messages[min(skip, len(messages)):]
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	if e.spill == nil {
+		spill, err := os.CreateTemp("", "kuuzuki-evicted-*.jsonl")
+		if err != nil {
+			return err
+		}
+		// unlinked right away, the file goes with the process where the
+		// system allows it
+		os.Remove(spill.Name())
+		e.spill = spill
+	}
+
+	var buf bytes.Buffer
+	var described []evictedMessage
+	for _, message := range messages {
+		// the role tells the messages apart when they're read back
+		info := message.Info
+		switch casted := info.(type) {
+		case opencode.UserMessage:
+			casted.Role = opencode.UserMessageRoleUser
+			info = casted
+		case opencode.AssistantMessage:
+			casted.Role = opencode.AssistantMessageRoleAssistant
+			info = casted
+		}
+		line, err := json.Marshal(spilledMessage{Info: info, Parts: message.Parts})
+		if err != nil {
+			return err
+		}
+		described = append(described, describeEvicted(message, providerID, e.size+int64(buf.Len())))
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if _, err := e.spill.WriteAt(buf.Bytes(), e.size); err != nil {
+		return err
+	}
+	e.size += int64(buf.Len())
+	e.messages = append(e.messages, described...)
+	return nil
+}
+
+func describeEvicted(message Message, providerID string, offset int64) evictedMessage {
+	described := evictedMessage{offset: offset, usage: ComputeUsage([]Message{message})}
+	described.usage.Sessions = 0
+	if assistant, ok := message.Info.(opencode.AssistantMessage); ok {
+		described.created = time.UnixMilli(int64(assistant.Time.Created))
+		described.cost = assistant.Cost
+		described.summary = assistant.Summary
+	}
+	for _, item := range messageContextItems(message, providerID) {
+		described.tokens += item.Tokens
+	}
+	return described
+}
+
+// usage adds up the usage of the messages still evicted
+func (e *Evicted) usage() Usage {
+	usage := Usage{}
+	for _, message := range e.messages[:e.Count] {
+		usage.Add(message.usage)
+	}
+	return usage
+}
+
+// spendSince returns the cost of the evicted assistant messages created
+// since the given time
+func (e *Evicted) spendSince(since time.Time) float64 {
+	cost := float64(0)
+	for _, message := range e.messages[:e.Count] {
+		if !message.created.Before(since) {
+			cost += message.cost
+		}
+	}
+	return cost
+}
+
+// contextItem sums up the evicted messages since the latest compaction
+// summary, which the model still sees, and false if there are none
+func (e *Evicted) contextItem() (ContextItem, bool) {
+	start := 0
+	for i, message := range e.messages[:e.Count] {
+		if message.summary {
+			start = i
+		}
+	}
+	count := e.Count - start
+	if count <= 0 {
+		return ContextItem{}, false
+	}
+	item := ContextItem{Kind: ContextEvicted, Label: fmt.Sprintf("%d earlier messages, not loaded", count)}
+	if count == 1 {
+		item.Label = "1 earlier message, not loaded"
+	}
+	for _, message := range e.messages[start:e.Count] {
+		item.Tokens += message.tokens
+	}
+	return item, true
+}
+
+// SetMessages replaces the messages of the current session, evicting the
+// oldest ones past the cap
+func (a *App) SetMessages(messages []Message) {
+	a.Messages = messages
+	a.Evicted.reset()
+	a.EvictMessages()
+}
+
+// EvictMessages drops the oldest messages past MaxLoadedMessages to disk,
+// returning whether any were evicted
+func (a *App) EvictMessages() bool {
+	excess := len(a.Messages) - MaxLoadedMessages
+	if excess <= 0 {
+		return false
+	}
+	providerID := ""
+	if a.Provider != nil {
+		providerID = a.Provider.ID
+	}
+	if err := a.Evicted.write(a.Evicted.Count, a.Messages[:excess], providerID); err != nil {
+		// keep them in memory rather than lose them
+		slog.Error("Failed to evict messages", "error", err)
+		return false
+	}
+	for _, message := range a.Evicted.messages[a.Evicted.Count : a.Evicted.Count+excess] {
+		a.Evicted.Usage.Add(message.usage)
+	}
+	a.Evicted.Count += excess
+	a.Messages = append([]Message{}, a.Messages[excess:]...)
+	return true
+}
+
+// LoadEarlierMessages reloads the page of evicted messages right before the
+// loaded ones
+func (a *App) LoadEarlierMessages() tea.Cmd {
+	if a.Evicted.Count == 0 {
+		return nil
+	}
+	sessionID := a.Session.ID
+	count := a.Evicted.Count
+	start := max(count-earlierMessagesPage, 0)
+	spill := a.Evicted.spill
+	from, to := a.Evicted.messages[start].offset, a.Evicted.size
+	if count < len(a.Evicted.messages) {
+		to = a.Evicted.messages[count].offset
+	}
+	return func() tea.Msg {
+		messages, err := readSpilled(io.NewSectionReader(spill, from, to-from))
+		if err != nil {
+			slog.Error("Failed to reload earlier messages", "error", err)
+		}
+		return EarlierMessagesLoadedMsg{SessionID: sessionID, Count: count, Messages: messages, Err: err}
+	}
+}
+
+func readSpilled(r io.Reader) ([]Message, error) {
+	var messages []Message
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var response opencode.SessionMessagesResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			return nil, err
+		}
+		message := Message{Info: response.Info.AsUnion(), Parts: []opencode.PartUnion{}}
+		for _, part := range response.Parts {
+			message.Parts = append(message.Parts, part.AsUnion())
+		}
+		messages = append(messages, message)
+	}
+	return messages, scanner.Err()
+}
+
+// RestoreEarlierMessages prepends reloaded messages, returning false if they
+// failed to load or the session or its loaded messages changed since they
+// were requested
+func (a *App) RestoreEarlierMessages(msg EarlierMessagesLoadedMsg) bool {
+	if msg.Err != nil || msg.SessionID != a.Session.ID || msg.Count != a.Evicted.Count {
+		return false
+	}
+	if len(msg.Messages) == 0 {
+		return false
+	}
+	a.Messages = append(append([]Message{}, msg.Messages...), a.Messages...)
+	a.Evicted.Count -= len(msg.Messages)
+	a.Evicted.Usage = a.Evicted.usage()
+	return true
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func numberedMessages(n int) []Message {
+	messages := make([]Message, n)
+	for i := range messages {
+		message := assistant(10, 5, 0.01, time.Second)
+		info := message.Info.(opencode.AssistantMessage)
+		info.ID = fmt.Sprintf("msg_%04d", i)
+		message.Info = info
+		messages[i] = message
+	}
+	return messages
+}
+
+func TestSetMessagesEvictsOldest(t *testing.T) {
+	a := &App{Session: &opencode.Session{ID: "ses"}}
+	a.SetMessages(numberedMessages(MaxLoadedMessages + 50))
+	t.Cleanup(a.Evicted.reset)
+
+	if len(a.Messages) != MaxLoadedMessages {
+		t.Fatalf("loaded %d messages, want %d", len(a.Messages), MaxLoadedMessages)
+	}
+	if a.Evicted.Count != 50 {
+		t.Errorf("evicted %d messages, want 50", a.Evicted.Count)
+	}
+	if a.Messages[0].ID() != "msg_0050" {
+		t.Errorf("first loaded message = %s, want msg_0050", a.Messages[0].ID())
+	}
+
+	usage := a.SessionUsage()
+	if usage.AssistantMessages != MaxLoadedMessages+50 || usage.Sessions != 1 {
+		t.Errorf("usage covers %d messages of %d sessions, want %d of 1", usage.AssistantMessages, usage.Sessions, MaxLoadedMessages+50)
+	}
+
+	a.SetMessages(numberedMessages(3))
+	if a.Evicted.Count != 0 || a.Evicted.Usage.AssistantMessages != 0 || a.Evicted.spill != nil {
+		t.Errorf("evicted state not reset: %+v", a.Evicted)
+	}
+}
+
+func TestRestoreEarlierMessages(t *testing.T) {
+	all := numberedMessages(MaxLoadedMessages + 150)
+	a := &App{Session: &opencode.Session{ID: "ses"}}
+	a.SetMessages(all)
+	t.Cleanup(a.Evicted.reset)
+
+	// the page is read back from disk
+	page, ok := a.LoadEarlierMessages()().(EarlierMessagesLoadedMsg)
+	if !ok || page.Err != nil || len(page.Messages) != 100 || page.Messages[0].ID() != "msg_0050" {
+		t.Fatalf("LoadEarlierMessages() = %+v, want msg_0050 to msg_0149", page)
+	}
+	if page.Messages[0].Info.(opencode.AssistantMessage).Cost != 0.01 {
+		t.Errorf("reloaded message lost its cost: %+v", page.Messages[0].Info)
+	}
+
+	if a.RestoreEarlierMessages(EarlierMessagesLoadedMsg{SessionID: "other", Count: 150, Messages: page.Messages}) {
+		t.Error("restored messages of another session")
+	}
+	if a.RestoreEarlierMessages(EarlierMessagesLoadedMsg{SessionID: "ses", Count: 150, Err: errors.New("offline")}) {
+		t.Error("restored a page that failed to load")
+	}
+	if !a.RestoreEarlierMessages(page) {
+		t.Fatal("didn't restore the page")
+	}
+	if a.Evicted.Count != 50 || a.Messages[0].ID() != "msg_0050" {
+		t.Errorf("evicted %d, first message %s, want 50 and msg_0050", a.Evicted.Count, a.Messages[0].ID())
+	}
+	if a.Evicted.Usage.AssistantMessages != 50 {
+		t.Errorf("evicted usage covers %d messages, want 50", a.Evicted.Usage.AssistantMessages)
+	}
+	if a.RestoreEarlierMessages(page) {
+		t.Error("restored a stale page twice")
+	}
+
+	// back at the bottom, the cap applies again without spilling twice
+	size := a.Evicted.size
+	if !a.EvictMessages() || a.Evicted.Count != 150 || len(a.Messages) != MaxLoadedMessages {
+		t.Fatalf("evicted %d, loaded %d, want 150 and %d", a.Evicted.Count, len(a.Messages), MaxLoadedMessages)
+	}
+	if a.Evicted.size != size {
+		t.Errorf("spill grew from %d to %d, want the reloaded messages reused", size, a.Evicted.size)
+	}
+	if usage := a.SessionUsage(); usage.AssistantMessages != MaxLoadedMessages+150 {
+		t.Errorf("usage covers %d messages, want %d", usage.AssistantMessages, MaxLoadedMessages+150)
+	}
+}
+
+func TestEvictedBudgetAndContext(t *testing.T) {
+	a := &App{State: NewState(), Session: &opencode.Session{ID: "ses"}}
+	a.SetMessages(numberedMessages(MaxLoadedMessages + 20))
+	t.Cleanup(a.Evicted.reset)
+
+	// the messages were created at 1000ms past the epoch
+	if spend := a.Evicted.spendSince(time.UnixMilli(0)); spend < 0.199 || spend > 0.201 {
+		t.Errorf("evicted spend = %v, want 0.20", spend)
+	}
+	if spend := a.Evicted.spendSince(time.UnixMilli(2000)); spend != 0 {
+		t.Errorf("evicted spend since later = %v, want 0", spend)
+	}
+
+	items := a.ContextItems()
+	if len(items) == 0 || items[0].Kind != ContextEvicted || items[0].Label != "20 earlier messages, not loaded" {
+		t.Errorf("ContextItems() = %+v, want the evicted messages first", items)
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// Fallback records a prompt being retried with a fallback model after the
+// original model failed
+type Fallback struct {
+	// From and To are in the format provider/model
+	From   string
+	To     string
+	Reason string
+}
+
+// rateLimitStatusCodes are the HTTP statuses of a throttled request: 429 and
+// the 529 Anthropic answers with when overloaded
+var rateLimitStatusCodes = []int{429, 529}
+
+// rateLimitMarkers are looked for in the message of errors that didn't come
+// with a status code
+var rateLimitMarkers = []string{
+	"rate limit",
+	"rate_limit",
+	"ratelimit",
+	"too many requests",
+	"overloaded",
+}
+
+// IsRateLimitError reports whether a provider error was the request being
+// throttled, by its status code when the server passed one on
+func IsRateLimitError(statusCode int, message string) bool {
+	if statusCode != 0 {
+		return slices.Contains(rateLimitStatusCodes, statusCode)
+	}
+	message = strings.ToLower(message)
+	for _, marker := range rateLimitMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextFallbackModel returns the first model of the fallback chain that hasn't
+// been tried yet and doesn't belong to the excluded provider
+func NextFallbackModel(
+	providers []opencode.Provider,
+	chain []string,
+	tried map[string]bool,
+	excludeProvider string,
+) (*opencode.Provider, *opencode.Model, bool) {
+	for _, entry := range chain {
+		if tried[entry] {
+			continue
+		}
+		providerID, modelID, ok := strings.Cut(entry, "/")
+		if !ok || providerID == excludeProvider {
+			continue
+		}
+		for _, provider := range providers {
+			if provider.ID != providerID {
+				continue
+			}
+			if model, ok := provider.Models[modelID]; ok {
+				return &provider, &model, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// LastModel returns the provider/model that produced the latest response,
+// or the selected model if there is none
+func (a *App) LastModel() string {
+	for i := len(a.Messages) - 1; i >= 0; i-- {
+		if assistant, ok := a.Messages[i].Info.(opencode.AssistantMessage); ok {
+			return assistant.ProviderID + "/" + assistant.ModelID
+		}
+	}
+	if a.Provider == nil || a.Model == nil {
+		return ""
+	}
+	return a.Provider.ID + "/" + a.Model.ID
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestIsRateLimitError(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		message    string
+		want       bool
+	}{
+		{429, "Too Many Requests", true},
+		{529, "Overloaded", true},
+		{400, "prompt is 4290 tokens over the rate limit of the context", false},
+		{401, "Invalid API key", false},
+		{0, "Rate limit exceeded, please retry later", true},
+		{0, "Overloaded", true},
+		{0, "file has 429 lines", false},
+		{0, "context length exceeded", false},
+	}
+	for _, tt := range tests {
+		if got := IsRateLimitError(tt.statusCode, tt.message); got != tt.want {
+			t.Errorf("IsRateLimitError(%d, %q) = %v, want %v", tt.statusCode, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestNextFallbackModel(t *testing.T) {
+	providers := []opencode.Provider{
+		{ID: "anthropic", Models: map[string]opencode.Model{
+			"claude-sonnet-4": {ID: "claude-sonnet-4"},
+		}},
+		{ID: "openai", Models: map[string]opencode.Model{
+			"gpt-4o": {ID: "gpt-4o"},
+		}},
+	}
+	chain := []string{"missing/model", "anthropic/claude-sonnet-4", "openai/gpt-4o"}
+
+	provider, model, ok := NextFallbackModel(providers, chain, map[string]bool{}, "")
+	if !ok || provider.ID != "anthropic" || model.ID != "claude-sonnet-4" {
+		t.Fatalf("NextFallbackModel() = %v, %v, %v, want anthropic/claude-sonnet-4", provider, model, ok)
+	}
+
+	provider, model, ok = NextFallbackModel(providers, chain, map[string]bool{}, "anthropic")
+	if !ok || provider.ID != "openai" || model.ID != "gpt-4o" {
+		t.Fatalf("NextFallbackModel() excluding anthropic = %v, %v, %v, want openai/gpt-4o", provider, model, ok)
+	}
+
+	tried := map[string]bool{"anthropic/claude-sonnet-4": true, "openai/gpt-4o": true}
+	if _, _, ok := NextFallbackModel(providers, chain, tried, ""); ok {
+		t.Error("NextFallbackModel() found a model after the chain was exhausted")
+	}
+}
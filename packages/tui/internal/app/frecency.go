@@ -0,0 +1,103 @@
+package app
+
+import (
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/git"
+)
+
+// maxRecentFiles caps the files remembered for each project
+const maxRecentFiles = 200
+
+// RecentFile is a file of a project used recently, by opening or attaching
+// it, or by the agent editing it
+type RecentFile struct {
+	Path     string    `toml:"path"`
+	Count    int       `toml:"count"`
+	LastUsed time.Time `toml:"last_used"`
+}
+
+// Frecency scores how often and how recently the file was used. A file used
+// in the last minutes ranks above every other one.
+func (f RecentFile) Frecency(now time.Time) float64 {
+	age := now.Sub(f.LastUsed)
+	weight := 10.0
+	switch {
+	case age < 15*time.Minute:
+		weight = 10000
+	case age < 4*time.Hour:
+		weight = 100
+	case age < 24*time.Hour:
+		weight = 70
+	case age < 7*24*time.Hour:
+		weight = 50
+	case age < 30*24*time.Hour:
+		weight = 30
+	}
+	return weight * float64(min(f.Count, 10))
+}
+
+// TouchFile records a use of a file relative to dir, forgetting the least
+// used files past maxRecentFiles
+func (s *State) TouchFile(dir, path string, now time.Time) {
+	if s.RecentFiles == nil {
+		s.RecentFiles = make(map[string][]RecentFile)
+	}
+	files := s.RecentFiles[dir]
+	index := slices.IndexFunc(files, func(f RecentFile) bool { return f.Path == path })
+	if index < 0 {
+		files = append(files, RecentFile{Path: path})
+		index = len(files) - 1
+	}
+	files[index].Count++
+	files[index].LastUsed = now
+	slices.SortStableFunc(files, func(a, b RecentFile) int {
+		return int(b.Frecency(now) - a.Frecency(now))
+	})
+	if len(files) > maxRecentFiles {
+		files = files[:maxRecentFiles]
+	}
+	s.RecentFiles[dir] = files
+}
+
+// RecentFiles returns the files of the working directory used recently,
+// ranked by frecency
+func (a *App) RecentFiles() []RecentFile {
+	now := time.Now()
+	files := slices.Clone(a.State.RecentFiles[a.Info.Path.Cwd])
+	slices.SortStableFunc(files, func(a, b RecentFile) int {
+		return int(b.Frecency(now) - a.Frecency(now))
+	})
+	return files
+}
+
+// FileTouchedMsg records a use of a file once git was asked whether it
+// ignores it
+type FileTouchedMsg struct {
+	Path string
+	time time.Time
+}
+
+// TouchFile records a use of a file relative to the working directory, so
+// the file completion ranks it higher. Files git ignores are left out, like
+// the completion leaves them out. Asking git happens off the UI goroutine,
+// the use is recorded when the FileTouchedMsg comes back.
+func (a *App) TouchFile(path string) tea.Cmd {
+	cwd, isGit := a.Info.Path.Cwd, a.Info.Git
+	now := time.Now()
+	return func() tea.Msg {
+		if isGit && git.Ignored(cwd, path) {
+			return nil
+		}
+		return FileTouchedMsg{Path: path, time: now}
+	}
+}
+
+// RecordTouchedFile records the use of a file TouchFile found git doesn't
+// ignore and saves the state
+func (a *App) RecordTouchedFile(msg FileTouchedMsg) tea.Cmd {
+	a.State.TouchFile(a.Info.Path.Cwd, msg.Path, msg.time)
+	return a.SaveState()
+}
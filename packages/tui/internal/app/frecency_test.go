@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTouchFile(t *testing.T) {
+	now := time.Now()
+	state := NewState()
+	for range 5 {
+		state.TouchFile("/repo", "often.go", now.Add(-2*time.Hour))
+	}
+	state.TouchFile("/repo", "once.go", now.Add(-time.Minute))
+	state.TouchFile("/other", "other.go", now)
+
+	files := state.RecentFiles["/repo"]
+	if len(files) != 2 {
+		t.Fatalf("len(files) = %d, want 2", len(files))
+	}
+	if files[0].Path != "once.go" {
+		t.Errorf("files[0] = %q, want the file touched a minute ago first", files[0].Path)
+	}
+	if files[1].Count != 5 {
+		t.Errorf("files[1].Count = %d, want 5", files[1].Count)
+	}
+
+	for i := range maxRecentFiles + 10 {
+		state.TouchFile("/repo", fmt.Sprintf("file%d.go", i), now)
+	}
+	if got := len(state.RecentFiles["/repo"]); got != maxRecentFiles {
+		t.Errorf("len(files) = %d, want %d", got, maxRecentFiles)
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Now()
+	recent := RecentFile{Count: 1, LastUsed: now.Add(-time.Minute)}
+	frequent := RecentFile{Count: 100, LastUsed: now.Add(-time.Hour)}
+	old := RecentFile{Count: 3, LastUsed: now.Add(-60 * 24 * time.Hour)}
+	if recent.Frecency(now) <= frequent.Frecency(now) {
+		t.Errorf("a file used a minute ago should rank above one used often an hour ago")
+	}
+	if frequent.Frecency(now) <= old.Frecency(now) {
+		t.Errorf("a file used often an hour ago should rank above an old one")
+	}
+}
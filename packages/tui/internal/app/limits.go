@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// RunLimits are per-turn guard limits after which a running session is paused.
+// A zero value for any limit disables it.
+type RunLimits struct {
+	MaxToolCalls int     `toml:"max_tool_calls"`
+	MaxSeconds   int     `toml:"max_seconds"`
+	MaxCost      float64 `toml:"max_cost"`
+}
+
+func (l RunLimits) Enabled() bool {
+	return l.MaxToolCalls > 0 || l.MaxSeconds > 0 || l.MaxCost > 0
+}
+
+func (l RunLimits) MaxDuration() time.Duration {
+	return time.Duration(l.MaxSeconds) * time.Second
+}
+
+// Exceeded describes the first limit reached by a turn with the given usage
+// and elapsed time, or returns an empty string if none was reached
+func (l RunLimits) Exceeded(usage Usage, elapsed time.Duration) string {
+	if l.MaxToolCalls > 0 && usage.TotalToolCalls() >= l.MaxToolCalls {
+		return fmt.Sprintf("%d tool calls", l.MaxToolCalls)
+	}
+	if l.MaxSeconds > 0 && elapsed >= l.MaxDuration() {
+		return l.MaxDuration().String() + " of run time"
+	}
+	if l.MaxCost > 0 && usage.Cost >= l.MaxCost {
+		return fmt.Sprintf("$%.2f of cost", l.MaxCost)
+	}
+	return ""
+}
+
+// RunLimits returns the run limits set in the TUI, falling back to the limits
+// from the tui config
+func (a *App) RunLimits() RunLimits {
+	if a.State.RunLimits != nil {
+		return *a.State.RunLimits
+	}
+	limits := a.Config.Tui.RunLimits
+	return RunLimits{
+		MaxToolCalls: int(limits.MaxToolCalls),
+		MaxSeconds:   int(limits.MaxSeconds),
+		MaxCost:      limits.MaxCost,
+	}
+}
+
+// CurrentTurn returns the last user message along with the usage of the
+// assistant messages that followed it
+func CurrentTurn(messages []Message) (*opencode.UserMessage, Usage) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if user, ok := messages[i].Info.(opencode.UserMessage); ok {
+			return &user, ComputeUsage(messages[i+1:])
+		}
+	}
+	return nil, Usage{}
+}
+
+// TurnInProgress reports whether the last prompt is still being answered
+func (a *App) TurnInProgress() bool {
+	if len(a.Messages) == 0 {
+		return false
+	}
+	if _, ok := a.Messages[len(a.Messages)-1].Info.(opencode.UserMessage); ok {
+		return true
+	}
+	return a.IsBusy()
+}
+
+// CheckRunLimits returns the ID of the running turn and the limit it reached,
+// if any
+func (a *App) CheckRunLimits(now time.Time) (string, string) {
+	limits := a.RunLimits()
+	if !limits.Enabled() || !a.TurnInProgress() {
+		return "", ""
+	}
+	user, usage := CurrentTurn(a.Messages)
+	if user == nil {
+		return "", ""
+	}
+	elapsed := now.Sub(time.UnixMilli(int64(user.Time.Created)))
+	return user.ID, limits.Exceeded(usage, elapsed)
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestRunLimitsExceeded(t *testing.T) {
+	usage := ComputeUsage([]Message{
+		assistant(100, 10, 0.40, time.Second, "read", "bash"),
+		assistant(100, 10, 0.60, time.Second, "edit"),
+	})
+
+	tests := []struct {
+		name    string
+		limits  RunLimits
+		elapsed time.Duration
+		want    string
+	}{
+		{"disabled", RunLimits{}, time.Hour, ""},
+		{"under all limits", RunLimits{MaxToolCalls: 5, MaxSeconds: 60, MaxCost: 2}, 10 * time.Second, ""},
+		{"tool calls", RunLimits{MaxToolCalls: 3}, 0, "3 tool calls"},
+		{"run time", RunLimits{MaxSeconds: 30}, 45 * time.Second, "30s of run time"},
+		{"cost", RunLimits{MaxCost: 1}, 0, "$1.00 of cost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.Exceeded(usage, tt.elapsed); got != tt.want {
+				t.Errorf("Exceeded() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentTurn(t *testing.T) {
+	messages := []Message{
+		{Info: opencode.UserMessage{ID: "first"}},
+		assistant(100, 10, 1, time.Second, "read", "read"),
+		{Info: opencode.UserMessage{ID: "second"}},
+		assistant(100, 10, 0.5, time.Second, "bash"),
+	}
+
+	user, usage := CurrentTurn(messages)
+	if user == nil || user.ID != "second" {
+		t.Fatalf("CurrentTurn() user = %v, want second", user)
+	}
+	if usage.TotalToolCalls() != 1 || usage.Cost != 0.5 {
+		t.Errorf("CurrentTurn() usage = %d calls/$%v, want 1 call/$0.5", usage.TotalToolCalls(), usage.Cost)
+	}
+
+	if user, _ := CurrentTurn(nil); user != nil {
+		t.Errorf("CurrentTurn(nil) user = %v, want nil", user)
+	}
+}
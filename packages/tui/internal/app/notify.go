@@ -0,0 +1,79 @@
+package app
+
+import (
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/util"
+)
+
+// NotifyEvent is something worth a notification while the terminal is
+// unfocused
+type NotifyEvent string
+
+const (
+	// NotifyComplete is a long request that finished
+	NotifyComplete NotifyEvent = "complete"
+	// NotifyPermission is a tool waiting for approval
+	NotifyPermission NotifyEvent = "permission"
+	// NotifyError is a request that failed
+	NotifyError NotifyEvent = "error"
+)
+
+// NotifyMethod is how an event is notified
+type NotifyMethod string
+
+const (
+	NotifyOff     NotifyMethod = "off"
+	NotifyBell    NotifyMethod = "bell"
+	NotifyDesktop NotifyMethod = "desktop"
+	NotifyBoth    NotifyMethod = "both"
+)
+
+const defaultNotifyMinDuration = 10 * time.Second
+
+// NotifyMethod returns how the event is notified, desktop unless configured
+func (a *App) NotifyMethod(event NotifyEvent) NotifyMethod {
+	config := a.Config.Tui.Notifications
+	var method string
+	switch event {
+	case NotifyComplete:
+		method = string(config.Complete)
+	case NotifyPermission:
+		method = string(config.Permission)
+	case NotifyError:
+		method = string(config.Error)
+	}
+	if method == "" {
+		return NotifyDesktop
+	}
+	return NotifyMethod(method)
+}
+
+// NotifyMinDuration returns how long a request must run before its
+// completion is notified
+func (a *App) NotifyMinDuration() time.Duration {
+	if seconds := a.Config.Tui.Notifications.MinSeconds; seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return defaultNotifyMinDuration
+}
+
+// Notify notifies the event with its configured method
+func (a *App) Notify(event NotifyEvent, title, body string) tea.Cmd {
+	method := a.NotifyMethod(event)
+	var cmds []tea.Cmd
+	if method == NotifyBell || method == NotifyBoth {
+		cmds = append(cmds, tea.Raw("\a"))
+	}
+	if method == NotifyDesktop || method == NotifyBoth {
+		cmds = append(cmds, func() tea.Msg {
+			if err := util.Notify(title, body); err != nil {
+				slog.Debug("Failed to show a desktop notification", "error", err)
+			}
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestNotifyMethod(t *testing.T) {
+	a := &App{Config: &opencode.Config{}}
+	for _, event := range []NotifyEvent{NotifyComplete, NotifyPermission, NotifyError} {
+		if got := a.NotifyMethod(event); got != NotifyDesktop {
+			t.Errorf("NotifyMethod(%s) = %s, want desktop by default", event, got)
+		}
+	}
+	if got := a.NotifyMinDuration(); got != 10*time.Second {
+		t.Errorf("NotifyMinDuration() = %s, want 10s by default", got)
+	}
+
+	a.Config.Tui.Notifications = opencode.ConfigTuiNotifications{
+		Complete:   opencode.ConfigTuiNotificationsCompleteBell,
+		Permission: opencode.ConfigTuiNotificationsPermissionBoth,
+		Error:      opencode.ConfigTuiNotificationsErrorOff,
+		MinSeconds: 2.5,
+	}
+	tests := map[NotifyEvent]NotifyMethod{
+		NotifyComplete:   NotifyBell,
+		NotifyPermission: NotifyBoth,
+		NotifyError:      NotifyOff,
+	}
+	for event, want := range tests {
+		if got := a.NotifyMethod(event); got != want {
+			t.Errorf("NotifyMethod(%s) = %s, want %s", event, got, want)
+		}
+	}
+	if got := a.NotifyMinDuration(); got != 2500*time.Millisecond {
+		t.Errorf("NotifyMinDuration() = %s, want 2.5s", got)
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// ToolOutput is what a tool call printed, to attach it to a prompt
+type ToolOutput struct {
+	// Tool is the name of the tool, e.g. bash
+	Tool string
+	Text string
+}
+
+// LastToolOutput returns the output of the most recent finished tool call
+// that printed anything, led by the command for shell commands
+func LastToolOutput(messages []Message) (ToolOutput, bool) {
+	for _, message := range slices.Backward(messages) {
+		for _, part := range slices.Backward(message.Parts) {
+			tool, ok := part.(opencode.ToolPart)
+			if !ok {
+				continue
+			}
+			var output string
+			switch tool.State.Status {
+			case opencode.ToolPartStateStatusCompleted:
+				output = tool.State.Output
+			case opencode.ToolPartStateStatusError:
+				output = tool.State.Error
+			}
+			output = strings.TrimSpace(ansi.Strip(output))
+			if output == "" {
+				continue
+			}
+			if input, ok := tool.State.Input.(map[string]any); ok {
+				if command, ok := input["command"].(string); ok && command != "" {
+					output = "$ " + command + "\n" + output
+				}
+			}
+			return ToolOutput{Tool: tool.Tool, Text: output}, true
+		}
+	}
+	return ToolOutput{}, false
+}
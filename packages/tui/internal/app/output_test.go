@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestLastToolOutput(t *testing.T) {
+	tool := func(name string, state opencode.ToolPartState) opencode.ToolPart {
+		return opencode.ToolPart{Tool: name, State: state}
+	}
+	messages := []Message{
+		{Parts: []opencode.PartUnion{
+			tool("bash", opencode.ToolPartState{
+				Status: opencode.ToolPartStateStatusError,
+				Input:  map[string]any{"command": "go test ./..."},
+				Error:  "\x1b[31mFAIL\x1b[0m TestLogin",
+			}),
+			tool("read", opencode.ToolPartState{
+				Status: opencode.ToolPartStateStatusCompleted,
+				Output: "package main",
+			}),
+		}},
+		{Parts: []opencode.PartUnion{
+			tool("bash", opencode.ToolPartState{Status: opencode.ToolPartStateStatusRunning}),
+			tool("edit", opencode.ToolPartState{Status: opencode.ToolPartStateStatusCompleted}),
+		}},
+	}
+
+	output, ok := LastToolOutput(messages)
+	if !ok || output.Tool != "read" || output.Text != "package main" {
+		t.Errorf("LastToolOutput() = %+v, %v, want the read output", output, ok)
+	}
+
+	messages[0].Parts = messages[0].Parts[:1]
+	output, _ = LastToolOutput(messages[:1])
+	if output.Text != "$ go test ./...\nFAIL TestLogin" {
+		t.Errorf("LastToolOutput() = %q, want the failed command and its error", output.Text)
+	}
+
+	if _, ok := LastToolOutput(nil); ok {
+		t.Error("LastToolOutput(nil) found an output")
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+var modelOverridePattern = regexp.MustCompile(`^\s*@model:(\S+)\s*`)
+
+// ModelOverride splits a leading `@model:<name>` off the prompt, returning the
+// requested model and the prompt without the prefix
+func (p Prompt) ModelOverride() (string, Prompt, bool) {
+	match := modelOverridePattern.FindStringSubmatchIndex(p.Text)
+	if match == nil {
+		return "", p, false
+	}
+	query := p.Text[match[2]:match[3]]
+	offset := match[1]
+
+	trimmed := Prompt{Text: p.Text[offset:]}
+	for _, att := range p.Attachments {
+		shifted := *att
+		shifted.StartIndex = max(att.StartIndex-offset, 0)
+		shifted.EndIndex = max(att.EndIndex-offset, 0)
+		trimmed.Attachments = append(trimmed.Attachments, &shifted)
+	}
+	if trimmed.Attachments == nil {
+		trimmed.Attachments = []*attachment.Attachment{}
+	}
+	return query, trimmed, true
+}
+
+// TypingModelOverride reports whether text is a leading `@model:` whose name
+// is still being typed, so the @ file completion can stay closed
+func TypingModelOverride(text string) bool {
+	rest, ok := strings.CutPrefix(strings.TrimLeft(text, " \t\n"), "@model:")
+	return ok && !strings.ContainsAny(rest, " \t\n")
+}
+
+// PromptModel returns the provider and model a prompt will be sent with,
+// honoring the model set on the prompt or a leading `@model:<name>` override
+func (a *App) PromptModel(prompt Prompt) (*opencode.Provider, *opencode.Model, Prompt, error) {
+	if prompt.ModelID != "" {
+		ref := prompt.ProviderID + "/" + prompt.ModelID
+		provider, model := a.FindModel(ref)
+		if model == nil {
+			return nil, nil, prompt, fmt.Errorf("no model matches %q", ref)
+		}
+		return provider, model, prompt, nil
+	}
+	query, trimmed, ok := prompt.ModelOverride()
+	if !ok {
+		return a.Provider, a.Model, prompt, nil
+	}
+	provider, model := a.MatchModel(query)
+	if model == nil {
+		return nil, nil, prompt, fmt.Errorf("no model matches %q", query)
+	}
+	return provider, model, trimmed, nil
+}
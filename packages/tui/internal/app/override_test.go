@@ -0,0 +1,103 @@
+package app
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+func TestPromptModelOverride(t *testing.T) {
+	prompt := Prompt{
+		Text: "@model:haiku explain @main.go",
+		Attachments: []*attachment.Attachment{
+			{Display: "@main.go", StartIndex: 21, EndIndex: 29},
+		},
+	}
+
+	query, trimmed, ok := prompt.ModelOverride()
+	if !ok || query != "haiku" {
+		t.Fatalf("ModelOverride() = %q, %v, want haiku, true", query, ok)
+	}
+	if trimmed.Text != "explain @main.go" {
+		t.Errorf("ModelOverride() text = %q, want %q", trimmed.Text, "explain @main.go")
+	}
+	att := trimmed.Attachments[0]
+	if got := trimmed.Text[att.StartIndex:att.EndIndex]; got != "@main.go" {
+		t.Errorf("ModelOverride() attachment covers %q, want @main.go", got)
+	}
+	if prompt.Attachments[0].StartIndex != 21 {
+		t.Errorf("ModelOverride() modified the original attachment")
+	}
+
+	if _, _, ok := (Prompt{Text: "use @model:haiku later"}).ModelOverride(); ok {
+		t.Error("ModelOverride() matched a prefix in the middle of the prompt")
+	}
+}
+
+func TestMatchModel(t *testing.T) {
+	providers := []opencode.Provider{
+		{ID: "anthropic", Models: map[string]opencode.Model{
+			"claude-3-5-haiku-latest": {ID: "claude-3-5-haiku-latest", Name: "Claude Haiku 3.5"},
+			"claude-sonnet-4":         {ID: "claude-sonnet-4", Name: "Claude Sonnet 4"},
+		}},
+		{ID: "openai", Models: map[string]opencode.Model{
+			"gpt-4o":      {ID: "gpt-4o", Name: "GPT-4o"},
+			"gpt-4o-mini": {ID: "gpt-4o-mini", Name: "GPT-4o mini"},
+		}},
+	}
+
+	a := &App{Providers: providers}
+
+	tests := []struct {
+		query    string
+		provider string
+		model    string
+	}{
+		{"openai/gpt-4o-mini", "openai", "gpt-4o-mini"},
+		{"gpt-4o", "openai", "gpt-4o"},
+		{"claude-sonnet", "anthropic", "claude-sonnet-4"},
+		{"Haiku", "anthropic", "claude-3-5-haiku-latest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			provider, model := a.MatchModel(tt.query)
+			if model == nil {
+				t.Fatal("MatchModel() found no model")
+			}
+			if provider.ID != tt.provider || model.ID != tt.model {
+				t.Errorf("MatchModel() = %s/%s, want %s/%s", provider.ID, model.ID, tt.provider, tt.model)
+			}
+		})
+	}
+
+	if _, model := a.MatchModel("llama"); model != nil {
+		t.Errorf("MatchModel(llama) = %s, want nil", model.ID)
+	}
+
+	// configured and template models are looked up exactly
+	if _, model := a.FindModel("openai/gpt-4o"); model == nil || model.ID != "gpt-4o" {
+		t.Errorf("FindModel(openai/gpt-4o) = %v, want gpt-4o", model)
+	}
+	for _, ref := range []string{"gpt-4o", "openai/gpt-4", "anthropic/Haiku"} {
+		if _, model := a.FindModel(ref); model != nil {
+			t.Errorf("FindModel(%s) = %s, want nil", ref, model.ID)
+		}
+	}
+}
+
+func TestTypingModelOverride(t *testing.T) {
+	tests := map[string]bool{
+		"@model:":              true,
+		"  @model:hai":         true,
+		"@model:haiku ":        false,
+		"@model:haiku explain": false,
+		"@main.go":             false,
+		"explain @model:haiku": false,
+	}
+	for text, want := range tests {
+		if got := TypingModelOverride(text); got != want {
+			t.Errorf("TypingModelOverride(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
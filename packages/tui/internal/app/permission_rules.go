@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// PermissionRulesMsg carries the standing permission rules after a change
+type PermissionRulesMsg struct {
+	Rules []opencode.PermissionRule
+}
+
+// ListPermissionRules returns the "always" approvals of the current session,
+// followed by the project and global permission config
+func (a *App) ListPermissionRules(ctx context.Context) ([]opencode.PermissionRule, error) {
+	rules, err := a.Client.Permission.Rules(ctx, opencode.PermissionRulesParams{
+		SessionID: opencode.F(a.Session.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		return []opencode.PermissionRule{}, nil
+	}
+	return *rules, nil
+}
+
+// SetPermissionRule stores a rule. When it replaces a rule with another tool,
+// pattern or scope, previous is revoked first.
+func (a *App) SetPermissionRule(rule opencode.PermissionRule, previous *opencode.PermissionRule) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if previous != nil && !sameRule(*previous, rule) {
+			if _, err := a.Client.Permission.RemoveRule(ctx, a.permissionRuleParams(*previous)); err != nil {
+				slog.Error("Failed to revoke permission rule", "error", err)
+				return toast.NewErrorToast("Failed to update the rule: " + err.Error())()
+			}
+		}
+		rules, err := a.Client.Permission.SetRule(ctx, a.permissionRuleParams(rule))
+		if err != nil {
+			slog.Error("Failed to set permission rule", "error", err)
+			return toast.NewErrorToast("Failed to save the rule: " + err.Error())()
+		}
+		return PermissionRulesMsg{Rules: *rules}
+	}
+}
+
+// RemovePermissionRule revokes a rule
+func (a *App) RemovePermissionRule(rule opencode.PermissionRule) tea.Cmd {
+	return func() tea.Msg {
+		rules, err := a.Client.Permission.RemoveRule(context.Background(), a.permissionRuleParams(rule))
+		if err != nil {
+			slog.Error("Failed to revoke permission rule", "error", err)
+			return toast.NewErrorToast("Failed to revoke the rule: " + err.Error())()
+		}
+		return PermissionRulesMsg{Rules: *rules}
+	}
+}
+
+func (a *App) permissionRuleParams(rule opencode.PermissionRule) opencode.PermissionRuleParams {
+	params := opencode.PermissionRuleParams{
+		Action: opencode.F(rule.Action),
+		Scope:  opencode.F(rule.Scope),
+		Tool:   opencode.F(rule.Tool),
+	}
+	if rule.Pattern != "" {
+		params.Pattern = opencode.F(rule.Pattern)
+	}
+	if a.Session.ID != "" {
+		params.SessionID = opencode.F(a.Session.ID)
+	}
+	return params
+}
+
+// sameRule reports whether two rules have the same key, so storing one
+// overwrites the other
+func sameRule(a, b opencode.PermissionRule) bool {
+	return a.Tool == b.Tool && a.Pattern == b.Pattern && a.Scope == b.Scope
+}
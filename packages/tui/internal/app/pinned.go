@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+// maxPinnedFileSize caps how much of a pinned file is sent with every prompt
+const maxPinnedFileSize = 100 * 1024
+
+// pinnedFilesPreamble starts the system prompt section the server renders
+// the pinned files into
+const pinnedFilesPreamble = "The user pinned the following files so their current content is always available:\n"
+
+// PinnedFile is a file sent with every prompt of the session
+type PinnedFile struct {
+	Path   string
+	Tokens int
+	Err    error
+}
+
+// PinnedFiles returns the files pinned to the current session, along with
+// the estimated tokens of their current content
+func (a *App) PinnedFiles() []PinnedFile {
+	var files []PinnedFile
+	for _, path := range a.pinnedPaths() {
+		content, err := a.readPinnedFile(path)
+		files = append(files, PinnedFile{
+			Path:   path,
+			Tokens: attachment.EstimateTextTokens(content),
+			Err:    err,
+		})
+	}
+	return files
+}
+
+// pinnedPaths are the files the server pins to the session, or the ones
+// waiting for the session to be created
+func (a *App) pinnedPaths() []string {
+	if a.Session.ID == "" {
+		return a.PendingPins
+	}
+	return a.Session.Pinned
+}
+
+// IsFilePinned reports whether the file is pinned to the current session
+func (a *App) IsFilePinned(path string) bool {
+	return slices.Contains(a.pinnedPaths(), path)
+}
+
+// TogglePinnedFile pins or unpins the file, returning whether it is pinned.
+// The server keeps the pins of a session, so that they are sent with every
+// prompt whichever client sends it.
+func (a *App) TogglePinnedFile(path string) (bool, error) {
+	pinned := !a.IsFilePinned(path)
+	if a.Session.ID == "" {
+		if pinned {
+			a.PendingPins = append(a.PendingPins, path)
+		} else {
+			a.PendingPins = slices.DeleteFunc(a.PendingPins, func(p string) bool { return p == path })
+		}
+		return pinned, nil
+	}
+	session, err := a.Client.Session.Pin(context.Background(), a.Session.ID, opencode.SessionPinParams{
+		Path:   opencode.F(path),
+		Pinned: opencode.F(pinned),
+	})
+	if err != nil {
+		return !pinned, err
+	}
+	a.Session = session
+	return pinned, nil
+}
+
+// pinPendingFiles pins the files pinned before the session existed
+func (a *App) pinPendingFiles(ctx context.Context) error {
+	for _, path := range a.PendingPins {
+		session, err := a.Client.Session.Pin(ctx, a.Session.ID, opencode.SessionPinParams{
+			Path:   opencode.F(path),
+			Pinned: opencode.F(true),
+		})
+		if err != nil {
+			return err
+		}
+		a.Session = session
+	}
+	a.PendingPins = nil
+	return nil
+}
+
+func (a *App) readPinnedFile(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(a.Info.Path.Cwd, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxPinnedFileSize {
+		return string(data[:maxPinnedFileSize]) + "\n[truncated]", nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+// fakePinServer answers /session/:id/pin, keeping the pins like the server
+func fakePinServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var pinned []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/ses_1/pin" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Path   string `json:"path"`
+			Pinned bool   `json:"pinned"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		pinned = slices.DeleteFunc(pinned, func(p string) bool { return p == body.Path })
+		if body.Pinned {
+			pinned = append(pinned, body.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      "ses_1",
+			"title":   "pins",
+			"version": "0",
+			"time":    map[string]int{"created": 1, "updated": 1},
+			"pinned":  pinned,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPinnedFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("use tabs, not spaces"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := fakePinServer(t)
+	a := &App{
+		State:   NewState(),
+		Session: &opencode.Session{},
+		Client:  opencode.NewClient(option.WithBaseURL(server.URL)),
+	}
+	a.Info.Path.Cwd = dir
+
+	// pinned before the session exists, then sent once it's created
+	if pinned, err := a.TogglePinnedFile("notes.md"); err != nil || !pinned {
+		t.Fatalf("TogglePinnedFile() = %v, %v, want pinned", pinned, err)
+	}
+	a.TogglePinnedFile("missing.md")
+	a.Session.ID = "ses_1"
+	if err := a.pinPendingFiles(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.PendingPins) != 0 || !slices.Equal(a.Session.Pinned, []string{"notes.md", "missing.md"}) {
+		t.Fatalf("Session.Pinned = %v, pending %v, want both files on the server", a.Session.Pinned, a.PendingPins)
+	}
+
+	files := a.PinnedFiles()
+	if len(files) != 2 || files[0].Tokens != 5 || files[0].Err != nil || files[1].Err == nil {
+		t.Fatalf("PinnedFiles() = %+v, want notes.md with 5 tokens and an unreadable missing.md", files)
+	}
+
+	// the pins reach the server rather than the system prompt
+	if _, ok := a.SystemPrompt(); ok {
+		t.Error("SystemPrompt() ok = true, want the pinned files left to the server")
+	}
+
+	if pinned, err := a.TogglePinnedFile("notes.md"); err != nil || pinned {
+		t.Fatalf("TogglePinnedFile() = %v, %v, want unpinned", pinned, err)
+	}
+	if a.IsFilePinned("notes.md") || !a.IsFilePinned("missing.md") {
+		t.Errorf("Session.Pinned = %v, want only missing.md", a.Session.Pinned)
+	}
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+// Progress describes the response the agent is working on
+type Progress struct {
+	Elapsed time.Duration
+	// Tokens is the output generated so far, as reported by the server once a
+	// step finishes or estimated from the streamed text before that
+	Tokens float64
+	// Rate is the output tokens per second, zero until a second has passed
+	Rate float64
+}
+
+// ResponseProgress returns the progress of the response in flight, and false
+// if the agent isn't working
+func (a *App) ResponseProgress(now time.Time) (Progress, bool) {
+	if !a.IsBusy() {
+		return Progress{}, false
+	}
+	message := a.Messages[len(a.Messages)-1]
+	assistant := message.Info.(opencode.AssistantMessage)
+
+	estimated := 0
+	for _, part := range message.Parts {
+		if text, ok := part.(opencode.TextPart); ok {
+			estimated += attachment.EstimateTextTokens(text.Text)
+		}
+	}
+	progress := Progress{
+		Elapsed: max(now.Sub(time.UnixMilli(int64(assistant.Time.Created))), 0),
+		Tokens:  max(assistant.Tokens.Output+assistant.Tokens.Reasoning, float64(estimated)),
+	}
+	if progress.Elapsed >= time.Second {
+		progress.Rate = progress.Tokens / progress.Elapsed.Seconds()
+	}
+	return progress, true
+}
+
+// RunningTool returns the most recent tool call of the response in flight
+// that is still running
+func (a *App) RunningTool() (opencode.ToolPart, bool) {
+	if !a.IsBusy() {
+		return opencode.ToolPart{}, false
+	}
+	parts := a.Messages[len(a.Messages)-1].Parts
+	for i := len(parts) - 1; i >= 0; i-- {
+		if tool, ok := parts[i].(opencode.ToolPart); ok &&
+			tool.State.Status == opencode.ToolPartStateStatusRunning {
+			return tool, true
+		}
+	}
+	return opencode.ToolPart{}, false
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestResponseProgress(t *testing.T) {
+	start := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	a := &App{Messages: []Message{{
+		Info: opencode.AssistantMessage{
+			Time: opencode.AssistantMessageTime{Created: float64(start.UnixMilli())},
+		},
+		Parts: []opencode.PartUnion{opencode.TextPart{Text: string(make([]byte, 400))}},
+	}}}
+
+	progress, ok := a.ResponseProgress(start.Add(4 * time.Second))
+	if !ok {
+		t.Fatal("no progress while working")
+	}
+	if progress.Elapsed != 4*time.Second || progress.Tokens != 100 || progress.Rate != 25 {
+		t.Errorf("got %+v, want 4s, 100 tokens at 25/s", progress)
+	}
+
+	if progress, _ := a.ResponseProgress(start.Add(500 * time.Millisecond)); progress.Rate != 0 {
+		t.Errorf("rate = %v before a second passed, want 0", progress.Rate)
+	}
+
+	a.Messages[0].Info = opencode.AssistantMessage{
+		Time: opencode.AssistantMessageTime{Created: float64(start.UnixMilli()), Completed: float64(start.UnixMilli())},
+	}
+	if _, ok := a.ResponseProgress(start); ok {
+		t.Error("progress after the response completed")
+	}
+}
+
+func TestRunningTool(t *testing.T) {
+	tool := func(name string, status opencode.ToolPartStateStatus) opencode.ToolPart {
+		return opencode.ToolPart{Tool: name, State: opencode.ToolPartState{Status: status}}
+	}
+	a := &App{Messages: []Message{{
+		Info: opencode.AssistantMessage{},
+		Parts: []opencode.PartUnion{
+			tool("read", opencode.ToolPartStateStatusRunning),
+			tool("bash", opencode.ToolPartStateStatusRunning),
+			tool("edit", opencode.ToolPartStateStatusCompleted),
+		},
+	}}}
+	if running, ok := a.RunningTool(); !ok || running.Tool != "bash" {
+		t.Errorf("running tool = %q, want bash", running.Tool)
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+)
+
+// projectSwitchEnv names the file the cli reads after the TUI exits to find
+// out which project to reopen in
+const projectSwitchEnv = "KUUZUKI_PROJECT_FILE"
+
+// IsKnownProject reports whether the current directory is a git repository
+// or a project kuuzuki has been used in before
+func (a *App) IsKnownProject() bool {
+	return a.Info.Git || slices.ContainsFunc(a.State.RecentProjects, func(p RecentProject) bool {
+		return p.Root == a.Info.Path.Root
+	})
+}
+
+// ShouldShowLauncher reports whether to start on the project launcher
+func (a *App) ShouldShowLauncher() bool {
+	if a.ShowLauncher {
+		return true
+	}
+	return !a.IsKnownProject() && len(a.State.RecentProjects) > 0
+}
+
+// RecordProject remembers the current session as the latest one of the
+// project, reporting whether the recent projects changed
+func (a *App) RecordProject() bool {
+	if a.Session == nil || a.Session.ID == "" {
+		return false
+	}
+	if len(a.State.RecentProjects) > 0 {
+		latest := a.State.RecentProjects[0]
+		if latest.Root == a.Info.Path.Root &&
+			latest.SessionID == a.Session.ID &&
+			latest.SessionTitle == a.Session.Title {
+			return false
+		}
+	}
+	a.State.UpdateRecentProject(a.Info.Path.Root, a.Session.ID, a.Session.Title)
+	return true
+}
+
+// OpenProject asks the cli to restart kuuzuki in the project, resuming its
+// latest session. The TUI has to quit for the switch to happen.
+func (a *App) OpenProject(project RecentProject) error {
+	return writeProjectFile(project.Root, project.SessionID, false)
+}
+
+// Restart asks the cli to start kuuzuki anew in the current directory,
+// resuming the session, so that an update installed takes effect. The TUI
+// has to quit for the restart to happen.
+func (a *App) Restart() error {
+	sessionID := ""
+	if a.Session != nil {
+		sessionID = a.Session.ID
+	}
+	return writeProjectFile(a.Info.Path.Cwd, sessionID, true)
+}
+
+// writeProjectFile tells the cli where to start kuuzuki again once the TUI
+// exits, and whether to run a new kuuzuki rather than reopen in its process
+func writeProjectFile(path, sessionID string, restart bool) error {
+	file := os.Getenv(projectSwitchEnv)
+	if file == "" {
+		return errors.New("switching projects and restarting only work when started from the kuuzuki cli")
+	}
+	data, err := json.Marshal(struct {
+		Path    string `json:"path"`
+		Session string `json:"session,omitempty"`
+		Restart bool   `json:"restart,omitempty"`
+	}{path, sessionID, restart})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0600)
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestUpdateRecentProject(t *testing.T) {
+	state := NewState()
+	state.UpdateRecentProject("/work/api", "ses_1", "Fix login")
+	state.UpdateRecentProject("/work/web", "ses_2", "Add dark mode")
+	state.UpdateRecentProject("/work/api", "ses_3", "Rate limiting")
+
+	if len(state.RecentProjects) != 2 {
+		t.Fatalf("len(RecentProjects) = %d, want 2", len(state.RecentProjects))
+	}
+	latest := state.RecentProjects[0]
+	if latest.Root != "/work/api" || latest.SessionID != "ses_3" || latest.SessionTitle != "Rate limiting" {
+		t.Errorf("RecentProjects[0] = %+v, want /work/api with ses_3", latest)
+	}
+	if state.RecentProjects[1].Root != "/work/web" {
+		t.Errorf("RecentProjects[1].Root = %q, want /work/web", state.RecentProjects[1].Root)
+	}
+}
+
+func TestRecordProject(t *testing.T) {
+	a := &App{
+		State:   NewState(),
+		Session: &opencode.Session{ID: "ses_1", Title: "Fix login"},
+	}
+	a.Info.Path.Root = "/work/api"
+
+	if !a.RecordProject() {
+		t.Fatal("RecordProject() = false for a new project")
+	}
+	if a.RecordProject() {
+		t.Error("RecordProject() = true for an unchanged session")
+	}
+	if !a.IsKnownProject() {
+		t.Error("IsKnownProject() = false after recording the project")
+	}
+
+	a.Session.Title = "Fix login redirect"
+	if !a.RecordProject() {
+		t.Error("RecordProject() = false after the session title changed")
+	}
+}
+
+func TestFindRecentProject(t *testing.T) {
+	state := NewState()
+	state.UpdateRecentProject("/work/old/api", "ses_1", "")
+	state.UpdateRecentProject("/work/api", "ses_2", "")
+	state.UpdateRecentProject("/work/web", "ses_3", "")
+
+	if project, ok := state.FindRecentProject("/work/old/api"); !ok || project.SessionID != "ses_1" {
+		t.Errorf("FindRecentProject(path) = %+v, %v, want ses_1", project, ok)
+	}
+	if project, ok := state.FindRecentProject("API"); !ok || project.Root != "/work/api" {
+		t.Errorf("FindRecentProject(name) = %+v, %v, want the latest /work/api", project, ok)
+	}
+	if _, ok := state.FindRecentProject("docs"); ok {
+		t.Error("FindRecentProject found a project that was never opened")
+	}
+}
+
+func TestRestart(t *testing.T) {
+	a := &App{Session: &opencode.Session{ID: "ses_1"}}
+	a.Info.Path.Cwd = "/work/api/server"
+
+	t.Setenv(projectSwitchEnv, "")
+	if err := a.Restart(); err == nil {
+		t.Error("Restart() succeeded without the cli")
+	}
+
+	file := filepath.Join(t.TempDir(), "project.json")
+	t.Setenv(projectSwitchEnv, file)
+	if err := a.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"path":"/work/api/server","session":"ses_1","restart":true}`; got != want {
+		t.Errorf("project file = %s, want %s", got, want)
+	}
+}
@@ -3,6 +3,8 @@ package app
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	opencode "github.com/sst/opencode-sdk-go"
@@ -13,6 +15,10 @@ import (
 type Prompt struct {
 	Text        string                   `toml:"text"`
 	Attachments []*attachment.Attachment `toml:"attachments"`
+	// ProviderID and ModelID send this prompt with a specific model, e.g.
+	// when retrying with a fallback model
+	ProviderID string `toml:"-"`
+	ModelID    string `toml:"-"`
 }
 
 func (p Prompt) ToMessage(
@@ -185,6 +191,27 @@ func (m Message) ToPrompt() (*Prompt, error) {
 	return nil, errors.New("unknown message type")
 }
 
+// LastPrompt rebuilds the most recent user prompt that hasn't been reverted,
+// with its attachments, so it can be sent again
+func LastPrompt(messages []Message, revertMessageID string) (*Prompt, error) {
+	for _, message := range slices.Backward(messages) {
+		user, ok := message.Info.(opencode.UserMessage)
+		if !ok {
+			continue
+		}
+		if revertMessageID != "" && user.ID >= revertMessageID {
+			continue
+		}
+		prompt, err := message.ToPrompt()
+		if err != nil {
+			return nil, err
+		}
+		prompt.Text = strings.TrimSpace(prompt.Text)
+		return prompt, nil
+	}
+	return nil, errors.New("no previous message")
+}
+
 func (m Message) ToSessionChatParams() []opencode.SessionChatParamsPartUnion {
 	parts := []opencode.SessionChatParamsPartUnion{}
 	for _, part := range m.Parts {
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func userMessage(id, text string, parts ...opencode.PartUnion) Message {
+	parts = append([]opencode.PartUnion{opencode.TextPart{Text: text}}, parts...)
+	return Message{
+		Info:  opencode.UserMessage{ID: id},
+		Parts: parts,
+	}
+}
+
+func TestLastPrompt(t *testing.T) {
+	image := opencode.FilePart{
+		ID:       "part",
+		Mime:     "image/png",
+		URL:      "data:image/png;base64,AAAA",
+		Filename: "screenshot.png",
+		Source: opencode.FilePartSource{
+			Type: "file",
+			Path: "/tmp/screenshot.png",
+			Text: opencode.FilePartSourceText{Value: "[Image #1]"},
+		},
+	}
+	messages := []Message{
+		userMessage("msg_1", "first"),
+		assistant(100, 10, 0, 0),
+		userMessage("msg_3", "second", image),
+		assistant(100, 10, 0, 0),
+	}
+
+	prompt, err := LastPrompt(messages, "")
+	if err != nil {
+		t.Fatalf("LastPrompt() error = %v", err)
+	}
+	if prompt.Text != "second" {
+		t.Errorf("LastPrompt() text = %q, want %q", prompt.Text, "second")
+	}
+	if len(prompt.Attachments) != 1 || prompt.Attachments[0].URL != image.URL {
+		t.Errorf("LastPrompt() attachments = %v, want the image", prompt.Attachments)
+	}
+
+	prompt, err = LastPrompt(messages, "msg_3")
+	if err != nil || prompt.Text != "first" {
+		t.Errorf("LastPrompt() after revert = %v, %v, want first", prompt, err)
+	}
+
+	if _, err := LastPrompt(nil, ""); err == nil {
+		t.Error("LastPrompt(nil) error = nil, want error")
+	}
+}
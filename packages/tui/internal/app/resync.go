@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// SessionResyncedMsg carries the messages of the current session refetched
+// after the event stream reconnected
+type SessionResyncedMsg struct {
+	SessionID string
+	Messages  []Message
+	// Err is set when the messages could not be refetched
+	Err error
+}
+
+// ResyncSession refetches the messages of the current session, which may
+// have changed while the event stream was down
+func (a *App) ResyncSession() tea.Cmd {
+	if a.Session == nil || a.Session.ID == "" {
+		return nil
+	}
+	sessionID := a.Session.ID
+	return func() tea.Msg {
+		messages, err := a.ListMessages(context.Background(), sessionID)
+		if err != nil {
+			slog.Error("Failed to resync session messages", "error", err)
+		}
+		return SessionResyncedMsg{SessionID: sessionID, Messages: messages, Err: err}
+	}
+}
+
+// ApplyResync replaces the messages of the current session with refetched
+// ones, returning false if they failed to load or the session changed since
+// they were requested
+func (a *App) ApplyResync(msg SessionResyncedMsg) bool {
+	if msg.Err != nil || a.Session == nil || msg.SessionID != a.Session.ID {
+		return false
+	}
+	a.SetMessages(msg.Messages)
+	return true
+}
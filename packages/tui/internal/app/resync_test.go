@@ -0,0 +1,30 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestApplyResync(t *testing.T) {
+	a := &App{Session: &opencode.Session{ID: "ses"}}
+	a.SetMessages(numberedMessages(2))
+
+	if a.ApplyResync(SessionResyncedMsg{SessionID: "other", Messages: numberedMessages(5)}) {
+		t.Error("applied messages of another session")
+	}
+	if a.ApplyResync(SessionResyncedMsg{SessionID: "ses", Err: errors.New("offline")}) {
+		t.Error("applied a failed resync")
+	}
+	if len(a.Messages) != 2 {
+		t.Fatalf("messages changed to %d without a resync", len(a.Messages))
+	}
+
+	if !a.ApplyResync(SessionResyncedMsg{SessionID: "ses", Messages: numberedMessages(MaxLoadedMessages + 1)}) {
+		t.Fatal("expected the resync to apply")
+	}
+	if len(a.Messages) != MaxLoadedMessages || a.Evicted.Count != 1 {
+		t.Errorf("loaded %d messages and evicted %d, want %d and 1", len(a.Messages), a.Evicted.Count, MaxLoadedMessages)
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// RetentionPolicy describes which sessions are old enough to be deleted.
+// A zero value for either limit disables that limit.
+type RetentionPolicy struct {
+	MaxAge   time.Duration
+	MaxCount int
+}
+
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxCount > 0
+}
+
+// RetentionPolicy returns the session retention policy from the tui config
+func (a *App) RetentionPolicy() RetentionPolicy {
+	retention := a.Config.Tui.SessionRetention
+	return RetentionPolicy{
+		MaxAge:   time.Duration(retention.MaxAgeDays) * 24 * time.Hour,
+		MaxCount: int(retention.MaxCount),
+	}
+}
+
+// SessionsToPrune returns the top-level sessions that fall outside the policy,
+// most recently updated first. The session in keepID is never selected.
+func SessionsToPrune(
+	sessions []opencode.Session,
+	policy RetentionPolicy,
+	now time.Time,
+	keepID string,
+) []opencode.Session {
+	if !policy.Enabled() {
+		return nil
+	}
+
+	var roots []opencode.Session
+	kept := 0
+	for _, session := range sessions {
+		if session.ParentID != "" {
+			continue
+		}
+		// the kept session always takes one of the available slots
+		if session.ID == keepID {
+			kept++
+			continue
+		}
+		roots = append(roots, session)
+	}
+	slices.SortFunc(roots, func(a, b opencode.Session) int {
+		if a.Time.Updated > b.Time.Updated {
+			return -1
+		}
+		if a.Time.Updated < b.Time.Updated {
+			return 1
+		}
+		return 0
+	})
+
+	var prune []opencode.Session
+	for _, session := range roots {
+		updated := time.UnixMilli(int64(session.Time.Updated))
+		expired := policy.MaxAge > 0 && now.Sub(updated) > policy.MaxAge
+		overflow := policy.MaxCount > 0 && kept >= policy.MaxCount
+		if expired || overflow {
+			prune = append(prune, session)
+			continue
+		}
+		kept++
+	}
+	return prune
+}
+
+// ListPrunableSessions returns the sessions the configured retention policy
+// would delete
+func (a *App) ListPrunableSessions(ctx context.Context) ([]opencode.Session, error) {
+	sessions, err := a.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keepID := ""
+	if a.Session != nil {
+		keepID = a.Session.ID
+	}
+	// don't prune the session we were asked to resume on startup
+	if keepID == "" && a.InitialSession != nil {
+		keepID = *a.InitialSession
+	}
+	return SessionsToPrune(sessions, a.RetentionPolicy(), time.Now(), keepID), nil
+}
+
+// PruneSessions deletes the given sessions and returns how many were removed
+func (a *App) PruneSessions(ctx context.Context, sessions []opencode.Session) (int, error) {
+	deleted := 0
+	for _, session := range sessions {
+		if err := a.DeleteSession(ctx, session.ID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	slog.Info("Pruned sessions", "count", deleted)
+	return deleted, nil
+}
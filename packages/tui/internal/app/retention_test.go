@@ -0,0 +1,90 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func session(id string, updated time.Time) opencode.Session {
+	return opencode.Session{
+		ID: id,
+		Time: opencode.SessionTime{
+			Created: float64(updated.UnixMilli()),
+			Updated: float64(updated.UnixMilli()),
+		},
+	}
+}
+
+func ids(sessions []opencode.Session) []string {
+	var result []string
+	for _, s := range sessions {
+		result = append(result, s.ID)
+	}
+	return result
+}
+
+func TestSessionsToPrune(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	sessions := []opencode.Session{
+		session("old", now.Add(-40*day)),
+		session("recent", now.Add(-1*day)),
+		session("older", now.Add(-60*day)),
+		session("new", now),
+	}
+	child := session("child", now.Add(-90*day))
+	child.ParentID = "new"
+	sessions = append(sessions, child)
+
+	tests := []struct {
+		name     string
+		policy   RetentionPolicy
+		keepID   string
+		expected []string
+	}{
+		{
+			name:     "disabled policy prunes nothing",
+			policy:   RetentionPolicy{},
+			expected: nil,
+		},
+		{
+			name:     "max age",
+			policy:   RetentionPolicy{MaxAge: 30 * day},
+			expected: []string{"old", "older"},
+		},
+		{
+			name:     "max count keeps most recent",
+			policy:   RetentionPolicy{MaxCount: 2},
+			expected: []string{"old", "older"},
+		},
+		{
+			name:     "current session is always kept",
+			policy:   RetentionPolicy{MaxAge: 30 * day},
+			keepID:   "older",
+			expected: []string{"old"},
+		},
+		{
+			name:     "current session counts towards max count",
+			policy:   RetentionPolicy{MaxCount: 1},
+			keepID:   "older",
+			expected: []string{"new", "recent", "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ids(SessionsToPrune(sessions, tt.policy, now, tt.keepID))
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/git"
+)
+
+// Sandbox returns the sandbox running in the repository
+func (a *App) Sandbox() (git.Sandbox, bool) {
+	sandbox, ok := a.State.Sandboxes[a.Info.Path.Root]
+	return sandbox, ok
+}
+
+// sandboxNeeded reports whether a prompt should start a sandbox first, when
+// tui.sandbox is set and none is running
+func (a *App) sandboxNeeded() bool {
+	if !a.Config.Tui.Sandbox || !a.Info.Git {
+		return false
+	}
+	_, ok := a.Sandbox()
+	return !ok
+}
+
+// StartSandbox moves the working tree to a sandbox branch for the session
+func (a *App) StartSandbox() error {
+	// named after the session, or when it was started before the session
+	name := time.Now().Format("20060102-150405")
+	if a.Session.ID != "" {
+		name = strings.ToLower(strings.TrimPrefix(a.Session.ID, "ses_"))
+	}
+	sandbox, err := git.StartSandbox(a.Info.Path.Cwd, name)
+	if err != nil {
+		return err
+	}
+	if a.State.Sandboxes == nil {
+		a.State.Sandboxes = map[string]git.Sandbox{}
+	}
+	a.State.Sandboxes[a.Info.Path.Root] = sandbox
+	return nil
+}
+
+// FinishSandbox merges the sandbox back into the branch it started from, or
+// discards it
+func (a *App) FinishSandbox(merge bool) tea.Cmd {
+	sandbox, ok := a.Sandbox()
+	if !ok {
+		return toast.NewInfoToast("No sandbox is running")
+	}
+	if !sandbox.Exists(a.Info.Path.Cwd) {
+		delete(a.State.Sandboxes, a.Info.Path.Root)
+		return tea.Batch(a.SaveState(), toast.NewInfoToast("The sandbox branch "+sandbox.Branch+" is gone"))
+	}
+	finish, done := sandbox.Discard, "Discarded the sandbox, back on "+sandbox.Base
+	if merge {
+		finish, done = sandbox.Merge, "Merged the sandbox into "+sandbox.Base
+	}
+	if err := finish(a.Info.Path.Cwd); err != nil {
+		return toast.NewErrorToast(err.Error(), toast.WithTitle("Sandbox"))
+	}
+	delete(a.State.Sandboxes, a.Info.Path.Root)
+	return tea.Batch(a.SaveState(), toast.NewSuccessToast(done))
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/sst/opencode/internal/git"
 )
 
 type ModelUsage struct {
@@ -21,26 +24,49 @@ type ModeModel struct {
 	ModelID    string `toml:"model_id"`
 }
 
+type RecentProject struct {
+	Root         string    `toml:"root"`
+	SessionID    string    `toml:"session_id"`
+	SessionTitle string    `toml:"session_title"`
+	LastUsed     time.Time `toml:"last_used"`
+}
+
 type State struct {
-	Theme                string               `toml:"theme"`
-	ScrollSpeed          *int                 `toml:"scroll_speed"`
-	ModeModel            map[string]ModeModel `toml:"mode_model"`
-	Provider             string               `toml:"provider"`
-	Model                string               `toml:"model"`
-	Mode                 string               `toml:"mode"`
-	RecentlyUsedModels   []ModelUsage         `toml:"recently_used_models"`
-	MessagesRight        bool                 `toml:"messages_right"`
-	SplitDiff            bool                 `toml:"split_diff"`
-	MessageHistory       []Prompt             `toml:"message_history"`
+	Theme              string                  `toml:"theme"`
+	ScrollSpeed        *int                    `toml:"scroll_speed"`
+	ModeModel          map[string]ModeModel    `toml:"mode_model"`
+	Provider           string                  `toml:"provider"`
+	Model              string                  `toml:"model"`
+	Mode               string                  `toml:"mode"`
+	RecentlyUsedModels []ModelUsage            `toml:"recently_used_models"`
+	MessagesRight      bool                    `toml:"messages_right"`
+	SplitDiff          bool                    `toml:"split_diff"`
+	SplitRatio         int                     `toml:"split_ratio"`
+	ScrollLock         bool                    `toml:"scroll_lock"`
+	FollowEdits        bool                    `toml:"follow_edits"`
+	HideAttribution    bool                    `toml:"hide_attribution"`
+	MessageHistory     []Prompt                `toml:"message_history"`
+	ShellHistory       []string                `toml:"shell_history"`
+	SessionSystem      map[string]string       `toml:"session_system"`
+	Scratchpads        map[string]string       `toml:"scratchpads"`
+	RunLimits          *RunLimits              `toml:"run_limits"`
+	RecentProjects     []RecentProject         `toml:"recent_projects"`
+	ContextPins        map[string][]ContextPin `toml:"context_pins"`
+	// RecentFiles are the files used recently by working directory
+	RecentFiles map[string][]RecentFile `toml:"recent_files"`
+	// Sandboxes are the running sandboxes by repository root
+	Sandboxes map[string]git.Sandbox `toml:"sandboxes"`
 }
 
 func NewState() *State {
 	return &State{
-		Theme:                "kuuzuki",
-		Mode:                 "build",
-		ModeModel:            make(map[string]ModeModel),
-		RecentlyUsedModels:   make([]ModelUsage, 0),
-		MessageHistory:       make([]Prompt, 0),
+		Theme:              "kuuzuki",
+		Mode:               "build",
+		ModeModel:          make(map[string]ModeModel),
+		RecentlyUsedModels: make([]ModelUsage, 0),
+		MessageHistory:     make([]Prompt, 0),
+		SessionSystem:      make(map[string]string),
+		Scratchpads:        make(map[string]string),
 	}
 }
 
@@ -81,6 +107,45 @@ func (s *State) RemoveModelFromRecentlyUsed(providerID, modelID string) {
 	}
 }
 
+// UpdateRecentProject moves the project to the front of the recent projects,
+// recording its latest session
+func (s *State) UpdateRecentProject(root, sessionID, sessionTitle string) {
+	project := RecentProject{
+		Root:         root,
+		SessionID:    sessionID,
+		SessionTitle: sessionTitle,
+		LastUsed:     time.Now(),
+	}
+	for i, recent := range s.RecentProjects {
+		if recent.Root == root {
+			s.RecentProjects = append(s.RecentProjects[:i], s.RecentProjects[i+1:]...)
+			break
+		}
+	}
+
+	s.RecentProjects = append([]RecentProject{project}, s.RecentProjects...)
+	if len(s.RecentProjects) > 20 {
+		s.RecentProjects = s.RecentProjects[:20]
+	}
+}
+
+// FindRecentProject returns the most recent project whose root is the path
+// or whose directory has the name, ignoring case
+func (s *State) FindRecentProject(query string) (RecentProject, bool) {
+	path, err := filepath.Abs(query)
+	for _, project := range s.RecentProjects {
+		if err == nil && project.Root == path {
+			return project, true
+		}
+	}
+	for _, project := range s.RecentProjects {
+		if strings.EqualFold(filepath.Base(project.Root), query) {
+			return project, true
+		}
+	}
+	return RecentProject{}, false
+}
+
 func (s *State) AddPromptToHistory(prompt Prompt) {
 	s.MessageHistory = append([]Prompt{prompt}, s.MessageHistory...)
 	if len(s.MessageHistory) > 50 {
@@ -88,6 +153,18 @@ func (s *State) AddPromptToHistory(prompt Prompt) {
 	}
 }
 
+// AddShellCommandToHistory records a command run in shell mode, apart from
+// the prompts
+func (s *State) AddShellCommandToHistory(command string) {
+	if len(s.ShellHistory) > 0 && s.ShellHistory[0] == command {
+		return
+	}
+	s.ShellHistory = append([]string{command}, s.ShellHistory...)
+	if len(s.ShellHistory) > 50 {
+		s.ShellHistory = s.ShellHistory[:50]
+	}
+}
+
 // SaveState writes the provided Config struct to the specified TOML file.
 // It will create the file if it doesn't exist, or overwrite it if it does.
 func SaveState(filePath string, state *State) error {
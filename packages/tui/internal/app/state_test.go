@@ -0,0 +1,20 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAddShellCommandToHistory(t *testing.T) {
+	state := NewState()
+	state.AddShellCommandToHistory("ls")
+	state.AddShellCommandToHistory("git status")
+	state.AddShellCommandToHistory("git status")
+
+	if want := []string{"git status", "ls"}; !slices.Equal(state.ShellHistory, want) {
+		t.Errorf("ShellHistory = %v, want %v", state.ShellHistory, want)
+	}
+	if len(state.MessageHistory) != 0 {
+		t.Errorf("len(MessageHistory) = %d, want shell commands kept apart", len(state.MessageHistory))
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/util"
+)
+
+// Template is a named session template from the tui config
+type Template struct {
+	Name string
+	opencode.ConfigTuiTemplate
+}
+
+// Templates returns the configured session templates sorted by name
+func (a *App) Templates() []Template {
+	var templates []Template
+	for name, template := range a.Config.Tui.Templates {
+		templates = append(templates, Template{Name: name, ConfigTuiTemplate: template})
+	}
+	slices.SortFunc(templates, func(a, b Template) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return templates
+}
+
+// InitialPrompt returns the editor content for the template, referencing the
+// pre-attached files with @ so the editor turns them into attachments
+func (t Template) InitialPrompt() string {
+	text := t.Prompt
+	for _, file := range t.Files {
+		if text != "" && !strings.HasSuffix(text, " ") && !strings.HasSuffix(text, "\n") {
+			text += " "
+		}
+		text += "@" + file
+	}
+	return text
+}
+
+// StartFromTemplate clears the current session and prepares a new one with the
+// template's agent, model and system prompt. The session itself is created
+// when the first prompt is sent.
+func (a *App) StartFromTemplate(template Template) (*App, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	a.Session = &opencode.Session{}
+	a.SetMessages([]Message{})
+	a.PendingSystem = template.System
+	cmds = append(cmds, util.CmdHandler(SessionClearedMsg{}))
+
+	if template.Agent != "" {
+		index := slices.IndexFunc(a.Agents, func(agent opencode.Agent) bool {
+			return agent.Name == template.Agent
+		})
+		if index == -1 {
+			cmds = append(cmds, toast.NewWarningToast("Template agent not found: "+template.Agent))
+		} else {
+			var cmd tea.Cmd
+			a, cmd = a.selectAgent(index)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	if template.Model != "" {
+		provider, model := a.FindModel(template.Model)
+		if model == nil {
+			cmds = append(cmds, toast.NewWarningToast("Template model not found: "+template.Model))
+		} else {
+			cmds = append(cmds, util.CmdHandler(ModelSelectedMsg{
+				Provider: *provider,
+				Model:    *model,
+			}))
+		}
+	}
+
+	if prompt := template.InitialPrompt(); prompt != "" {
+		cmds = append(cmds, util.CmdHandler(SetEditorContentMsg{Text: prompt}))
+	}
+
+	return a, tea.Sequence(cmds...)
+}
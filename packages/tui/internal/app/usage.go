@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// Usage aggregates token, cost and tool usage over a set of messages
+type Usage struct {
+	Sessions          int
+	UserMessages      int
+	AssistantMessages int
+	InputTokens       float64
+	OutputTokens      float64
+	ReasoningTokens   float64
+	CacheReadTokens   float64
+	CacheWriteTokens  float64
+	Cost              float64
+	ToolCalls         map[string]int
+	// AgentTime is the time spent generating responses
+	AgentTime time.Duration
+}
+
+func (u Usage) TotalTokens() float64 {
+	return u.InputTokens + u.OutputTokens + u.ReasoningTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+func (u Usage) TotalToolCalls() int {
+	total := 0
+	for _, count := range u.ToolCalls {
+		total += count
+	}
+	return total
+}
+
+// ToolNames returns the called tools ordered by call count, most used first
+func (u Usage) ToolNames() []string {
+	var names []string
+	for name := range u.ToolCalls {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if u.ToolCalls[a] != u.ToolCalls[b] {
+			return u.ToolCalls[b] - u.ToolCalls[a]
+		}
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	})
+	return names
+}
+
+// Add merges other into u
+func (u *Usage) Add(other Usage) {
+	if u.ToolCalls == nil {
+		u.ToolCalls = make(map[string]int)
+	}
+	u.Sessions += other.Sessions
+	u.UserMessages += other.UserMessages
+	u.AssistantMessages += other.AssistantMessages
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.ReasoningTokens += other.ReasoningTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+	u.Cost += other.Cost
+	u.AgentTime += other.AgentTime
+	for name, count := range other.ToolCalls {
+		u.ToolCalls[name] += count
+	}
+}
+
+// ComputeUsage aggregates the usage recorded in the assistant message metadata
+func ComputeUsage(messages []Message) Usage {
+	usage := Usage{
+		Sessions:  1,
+		ToolCalls: make(map[string]int),
+	}
+	for _, message := range messages {
+		switch info := message.Info.(type) {
+		case opencode.UserMessage:
+			usage.UserMessages++
+		case opencode.AssistantMessage:
+			usage.AssistantMessages++
+			usage.InputTokens += info.Tokens.Input
+			usage.OutputTokens += info.Tokens.Output
+			usage.ReasoningTokens += info.Tokens.Reasoning
+			usage.CacheReadTokens += info.Tokens.Cache.Read
+			usage.CacheWriteTokens += info.Tokens.Cache.Write
+			usage.Cost += info.Cost
+			if info.Time.Completed > info.Time.Created {
+				usage.AgentTime += time.Duration(info.Time.Completed-info.Time.Created) * time.Millisecond
+			}
+		}
+		for _, part := range message.Parts {
+			if tool, ok := part.(opencode.ToolPart); ok {
+				usage.ToolCalls[tool.Tool]++
+			}
+		}
+	}
+	return usage
+}
+
+// ContextTokens returns the number of tokens the last response used, which is
+// how much of the context window the session currently occupies
+func ContextTokens(messages []Message) float64 {
+	tokens := float64(0)
+	for _, message := range messages {
+		if assistant, ok := message.Info.(opencode.AssistantMessage); ok {
+			usage := assistant.Tokens
+			if usage.Output > 0 {
+				if assistant.Summary {
+					tokens = usage.Output
+					continue
+				}
+				tokens = (usage.Input +
+					usage.Cache.Write +
+					usage.Cache.Read +
+					usage.Output +
+					usage.Reasoning)
+			}
+		}
+	}
+	return tokens
+}
+
+// ContextUsage returns the tokens used by the current session and the context
+// window of the selected model
+func (a *App) ContextUsage() (float64, float64) {
+	if a.Model == nil {
+		return ContextTokens(a.Messages), 0
+	}
+	return ContextTokens(a.Messages), a.Model.Limit.Context
+}
+
+const defaultCompactThreshold = 80
+
+// CompactThreshold returns the context window usage percentage at which
+// compacting the session is suggested
+func (a *App) CompactThreshold() float64 {
+	if a.Config.Tui.CompactThreshold > 0 {
+		return a.Config.Tui.CompactThreshold
+	}
+	return defaultCompactThreshold
+}
+
+// ContextPercent returns how full the context window is, from 0 to 100, or -1
+// if the model's context window is unknown
+func (a *App) ContextPercent() float64 {
+	tokens, window := a.ContextUsage()
+	if window <= 0 {
+		return -1
+	}
+	return min(tokens/window*100, 100)
+}
+
+// SessionUsage returns the usage of the current session, including the
+// messages evicted from memory
+func (a *App) SessionUsage() Usage {
+	usage := ComputeUsage(a.Messages)
+	usage.Add(a.Evicted.Usage)
+	return usage
+}
+
+// AllSessionsUsage loads every top-level session and aggregates its usage
+func (a *App) AllSessionsUsage(ctx context.Context) (Usage, error) {
+	total := Usage{ToolCalls: make(map[string]int)}
+	sessions, err := a.ListSessions(ctx)
+	if err != nil {
+		return total, err
+	}
+	for _, session := range sessions {
+		if session.ParentID != "" {
+			continue
+		}
+		messages, err := a.ListMessages(ctx, session.ID)
+		if err != nil {
+			return total, err
+		}
+		total.Add(ComputeUsage(messages))
+	}
+	return total, nil
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func assistant(input, output, cost float64, duration time.Duration, tools ...string) Message {
+	var parts []opencode.PartUnion
+	for _, tool := range tools {
+		parts = append(parts, opencode.ToolPart{Tool: tool})
+	}
+	return Message{
+		Info: opencode.AssistantMessage{
+			Cost: cost,
+			Time: opencode.AssistantMessageTime{
+				Created:   1000,
+				Completed: 1000 + float64(duration.Milliseconds()),
+			},
+			Tokens: opencode.AssistantMessageTokens{
+				Input:  input,
+				Output: output,
+				Cache:  opencode.AssistantMessageTokensCache{Read: 10},
+			},
+		},
+		Parts: parts,
+	}
+}
+
+func TestComputeUsage(t *testing.T) {
+	messages := []Message{
+		{Info: opencode.UserMessage{}},
+		assistant(100, 50, 0.01, 2*time.Second, "read", "bash", "read"),
+		{Info: opencode.UserMessage{}},
+		assistant(200, 25, 0.02, 3*time.Second, "edit"),
+		// still streaming, no completion time yet
+		{Info: opencode.AssistantMessage{Time: opencode.AssistantMessageTime{Created: 5000}}},
+	}
+
+	usage := ComputeUsage(messages)
+
+	if usage.UserMessages != 2 || usage.AssistantMessages != 3 {
+		t.Errorf("messages = %d/%d, want 2/3", usage.UserMessages, usage.AssistantMessages)
+	}
+	if usage.InputTokens != 300 || usage.OutputTokens != 75 || usage.CacheReadTokens != 20 {
+		t.Errorf("tokens = %v/%v/%v, want 300/75/20", usage.InputTokens, usage.OutputTokens, usage.CacheReadTokens)
+	}
+	if usage.TotalTokens() != 395 {
+		t.Errorf("TotalTokens() = %v, want 395", usage.TotalTokens())
+	}
+	if usage.Cost < 0.0299 || usage.Cost > 0.0301 {
+		t.Errorf("Cost = %v, want 0.03", usage.Cost)
+	}
+	if usage.AgentTime != 5*time.Second {
+		t.Errorf("AgentTime = %v, want 5s", usage.AgentTime)
+	}
+	if usage.TotalToolCalls() != 4 {
+		t.Errorf("TotalToolCalls() = %d, want 4", usage.TotalToolCalls())
+	}
+	if got, want := usage.ToolNames(), []string{"read", "bash", "edit"}; !slices.Equal(got, want) {
+		t.Errorf("ToolNames() = %v, want %v", got, want)
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	var total Usage
+	total.Add(ComputeUsage([]Message{assistant(100, 10, 0.5, time.Second, "read")}))
+	total.Add(ComputeUsage([]Message{assistant(50, 5, 0.25, time.Second, "read", "grep")}))
+
+	if total.Sessions != 2 {
+		t.Errorf("Sessions = %d, want 2", total.Sessions)
+	}
+	if total.InputTokens != 150 || total.Cost != 0.75 {
+		t.Errorf("InputTokens/Cost = %v/%v, want 150/0.75", total.InputTokens, total.Cost)
+	}
+	if total.ToolCalls["read"] != 2 || total.ToolCalls["grep"] != 1 {
+		t.Errorf("ToolCalls = %v", total.ToolCalls)
+	}
+}
+
+func TestContextTokens(t *testing.T) {
+	summary := assistant(0, 300, 0, time.Second)
+	info := summary.Info.(opencode.AssistantMessage)
+	info.Summary = true
+	summary.Info = info
+
+	tests := []struct {
+		name     string
+		messages []Message
+		want     float64
+	}{
+		{"empty", nil, 0},
+		{"last response including cache reads", []Message{assistant(100, 10, 0, time.Second), assistant(500, 50, 0, time.Second)}, 560},
+		{"after summary", []Message{assistant(5000, 10, 0, time.Second), summary}, 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContextTokens(tt.messages); got != tt.want {
+				t.Errorf("ContextTokens() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/sst/opencode/internal/styles"
 )
 
 type TextSource struct {
@@ -82,7 +83,7 @@ func (a *Attachment) GetSymbolSource() (*SymbolSource, bool) {
 // GetFileIcon returns an appropriate icon for the file type
 func (a *Attachment) GetFileIcon() string {
 	if a.Type != "file" {
-		return "📄"
+		return styles.IconFile.String()
 	}
 
 	// Get file extension
@@ -97,48 +98,48 @@ func (a *Attachment) GetFileIcon() string {
 	// Return appropriate icon based on file type
 	switch ext {
 	case "go":
-		return "🐹"
+		return styles.IconFileGo.String()
 	case "js", "ts", "jsx", "tsx":
-		return "📜"
+		return styles.IconFileScript.String()
 	case "py":
-		return "🐍"
+		return styles.IconFilePython.String()
 	case "rs":
-		return "🦀"
+		return styles.IconFileRust.String()
 	case "java":
-		return "☕"
+		return styles.IconFileJava.String()
 	case "cpp", "c", "cc", "cxx":
-		return "⚙️"
+		return styles.IconFileC.String()
 	case "html", "htm":
-		return "🌐"
+		return styles.IconFileHTML.String()
 	case "css", "scss", "sass":
-		return "🎨"
+		return styles.IconFileCSS.String()
 	case "json":
-		return "📋"
+		return styles.IconFileJSON.String()
 	case "md", "markdown":
-		return "📝"
+		return styles.IconFileMarkdown.String()
 	case "txt":
-		return "📄"
+		return styles.IconFile.String()
 	case "pdf":
-		return "📕"
+		return styles.IconFilePDF.String()
 	case "png", "jpg", "jpeg", "gif", "svg":
-		return "🖼️"
+		return styles.IconFileImage.String()
 	case "zip", "tar", "gz", "rar":
-		return "📦"
+		return styles.IconFileArchive.String()
 	default:
-		return "📄"
+		return styles.IconFile.String()
 	}
 }
 
 // GetFormattedSize returns human-readable file size
 func (a *Attachment) GetFormattedSize() string {
 	if fs, ok := a.GetFileSource(); ok && len(fs.Data) > 0 {
-		return formatBytes(int64(len(fs.Data)))
+		return FormatBytes(int64(len(fs.Data)))
 	}
 	return ""
 }
 
-// formatBytes formats byte count into human readable format
-func formatBytes(bytes int64) string {
+// FormatBytes formats byte count into human readable format
+func FormatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
@@ -0,0 +1,123 @@
+package attachment
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Defaults for ImageLimits, the longest side models see images at before
+// scaling them down themselves, and a size that is 5MB once base64 encoded
+const (
+	DefaultMaxImageDimension = 1568
+	DefaultMaxImageBytes     = 3_750_000
+)
+
+// maxDownscaleAttempts bounds how many times an image that is still too
+// large once encoded is shrunk further
+const maxDownscaleAttempts = 6
+
+// ImageLimits are the largest images attached as they are, larger ones are
+// scaled down to fit
+type ImageLimits struct {
+	// MaxDimension is the longest side in pixels
+	MaxDimension int
+	// MaxBytes is the size of the encoded image
+	MaxBytes int
+}
+
+// Downscaled is an image scaled down to fit ImageLimits
+type Downscaled struct {
+	Data      []byte
+	MediaType string
+	Width     int
+	Height    int
+}
+
+// Label describes the downscaled image for its attachment
+func (d *Downscaled) Label() string {
+	return fmt.Sprintf("downscaled to %dx%d, %s", d.Width, d.Height, FormatBytes(int64(len(d.Data))))
+}
+
+// Filename renames the file of the image for its media type, a WebP turned
+// JPEG ends in .jpg
+func (d *Downscaled) Filename(name string) string {
+	if d.MediaType != "image/jpeg" {
+		return name
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return name
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".jpg"
+}
+
+// DownscaleImage scales the image down to fit the limits, as a PNG if it
+// was one, a JPEG otherwise. It returns nil when the image fits already or
+// is not one it can scale, like an SVG or a GIF, which may be animated.
+func DownscaleImage(data []byte, mediaType string, limits ImageLimits) *Downscaled {
+	if limits.MaxDimension <= 0 {
+		limits.MaxDimension = DefaultMaxImageDimension
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultMaxImageBytes
+	}
+	switch mediaType {
+	case "image/png", "image/jpeg", "image/webp":
+	default:
+		return nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	longest := max(config.Width, config.Height)
+	if longest <= limits.MaxDimension && len(data) <= limits.MaxBytes {
+		return nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	outType := "image/jpeg"
+	if mediaType == "image/png" {
+		outType = "image/png"
+	}
+	scale := min(1, float64(limits.MaxDimension)/float64(longest))
+	var result *Downscaled
+	for range maxDownscaleAttempts {
+		width := max(int(float64(config.Width)*scale), 1)
+		height := max(int(float64(config.Height)*scale), 1)
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		if outType == "image/jpeg" {
+			// transparent pixels would turn black without an alpha channel
+			draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+		}
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+		var buf bytes.Buffer
+		if outType == "image/png" {
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return nil
+		}
+		result = &Downscaled{Data: buf.Bytes(), MediaType: outType, Width: width, Height: height}
+		if buf.Len() <= limits.MaxBytes {
+			break
+		}
+		scale *= 0.75
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package attachment
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		for y := range height {
+			img.Set(x, y, color.RGBA{uint8(x * 7), uint8(y * 13), uint8(x * y), 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownscaleImage(t *testing.T) {
+	data := encodePNG(t, 400, 200)
+
+	if got := DownscaleImage(data, "image/png", ImageLimits{MaxDimension: 400}); got != nil {
+		t.Errorf("DownscaleImage() = %dx%d, want the image kept as it is", got.Width, got.Height)
+	}
+
+	got := DownscaleImage(data, "image/png", ImageLimits{MaxDimension: 100})
+	if got == nil {
+		t.Fatal("DownscaleImage() = nil, want the image scaled down")
+	}
+	if got.Width != 100 || got.Height != 50 || got.MediaType != "image/png" {
+		t.Errorf("DownscaleImage() = %dx%d %s, want 100x50 image/png", got.Width, got.Height, got.MediaType)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(got.Data))
+	if err != nil || config.Width != 100 || config.Height != 50 {
+		t.Errorf("DownscaleImage() data is %dx%d (%v), want 100x50", config.Width, config.Height, err)
+	}
+
+	// too many bytes shrinks the image until it fits
+	got = DownscaleImage(data, "image/png", ImageLimits{MaxDimension: 400, MaxBytes: len(data) / 2})
+	if got == nil || len(got.Data) > len(data)/2 || got.Width >= 400 {
+		t.Errorf("DownscaleImage() did not shrink the image under %d bytes", len(data)/2)
+	}
+
+	if got := DownscaleImage([]byte("<svg/>"), "image/svg+xml", ImageLimits{MaxDimension: 1, MaxBytes: 1}); got != nil {
+		t.Error("DownscaleImage() scaled an SVG")
+	}
+}
+
+func TestDownscaledFilename(t *testing.T) {
+	jpeg := &Downscaled{MediaType: "image/jpeg"}
+	for name, want := range map[string]string{
+		"shots/screen.webp": "shots/screen.jpg",
+		"photo.JPEG":        "photo.JPEG",
+		"photo.jpg":         "photo.jpg",
+	} {
+		if got := jpeg.Filename(name); got != want {
+			t.Errorf("Filename(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := (&Downscaled{MediaType: "image/png"}).Filename("diagram.png"); got != "diagram.png" {
+		t.Errorf("Filename(%q) = %q, want it kept", "diagram.png", got)
+	}
+}
@@ -0,0 +1,108 @@
+package attachment
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// charsPerToken is the rough number of characters per token for text
+	charsPerToken = 4
+	// tokensPerSymbolLine is the rough number of tokens per line of code
+	tokensPerSymbolLine = 12
+	// defaultImageTokens is used when the image dimensions can't be read
+	defaultImageTokens = 1000
+)
+
+// EstimateTokens returns a rough estimate of the tokens the attachment will
+// add to the prompt when sent to the given provider
+func EstimateTokens(a *Attachment, providerID string) int {
+	switch a.Type {
+	case "text":
+		if ts, ok := a.GetTextSource(); ok {
+			return textTokens(len(ts.Value))
+		}
+	case "symbol":
+		if ss, ok := a.GetSymbolSource(); ok {
+			lines := ss.Range.End.Line - ss.Range.Start.Line + 1
+			return max(lines, 1) * tokensPerSymbolLine
+		}
+	case "file":
+		fs, ok := a.GetFileSource()
+		if !ok {
+			return 0
+		}
+		if strings.HasPrefix(fs.Mime, "image/") {
+			return imageTokens(fs.Data, providerID)
+		}
+		if len(fs.Data) > 0 {
+			return textTokens(len(fs.Data))
+		}
+		if size, ok := fileSize(fs.Path); ok {
+			return textTokens(int(size))
+		}
+	}
+	return 0
+}
+
+// fileSizes caches the size of attached files, as the estimate is shown on
+// every render of the editor
+var fileSizes sync.Map
+
+// fileSize returns the size of the file at path, statting it only once
+func fileSize(path string) (int64, bool) {
+	if size, ok := fileSizes.Load(path); ok {
+		return size.(int64), true
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return 0, false
+	}
+	fileSizes.Store(path, info.Size())
+	return info.Size(), true
+}
+
+// EstimateTextTokens returns a rough estimate of the tokens in the text
+func EstimateTextTokens(text string) int {
+	return textTokens(len(text))
+}
+
+func textTokens(chars int) int {
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// imageTokens applies each provider's published image sizing rules
+func imageTokens(data []byte, providerID string) int {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width == 0 || config.Height == 0 {
+		return defaultImageTokens
+	}
+	width, height := float64(config.Width), float64(config.Height)
+
+	switch providerID {
+	case "openai", "azure":
+		// fit within 2048x2048, scale the shortest side down to 768, then
+		// 170 tokens per 512px tile plus a fixed 85
+		scale := min(1, 2048/max(width, height))
+		width, height = width*scale, height*scale
+		scale = min(1, 768/min(width, height))
+		width, height = width*scale, height*scale
+		tiles := math.Ceil(width/512) * math.Ceil(height/512)
+		return int(85 + 170*tiles)
+	case "google", "google-vertex":
+		return 258
+	default:
+		// anthropic: images are resized to at most 1568px on the long edge,
+		// and cost roughly width*height/750 tokens
+		scale := min(1, 1568/max(width, height))
+		width, height = width*scale, height*scale
+		return int(math.Ceil(width * height / 750))
+	}
+}
@@ -0,0 +1,97 @@
+package attachment
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func pngBytes(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEstimateTokens(t *testing.T) {
+	image := pngBytes(t, 1000, 750)
+
+	tests := []struct {
+		name       string
+		attachment *Attachment
+		provider   string
+		want       int
+	}{
+		{
+			"pasted text",
+			&Attachment{Type: "text", Source: &TextSource{Value: strings.Repeat("a", 401)}},
+			"anthropic",
+			101,
+		},
+		{
+			"symbol",
+			&Attachment{Type: "symbol", Source: &SymbolSource{Range: SymbolRange{
+				Start: Position{Line: 10},
+				End:   Position{Line: 19},
+			}}},
+			"anthropic",
+			120,
+		},
+		{
+			"anthropic image",
+			&Attachment{Type: "file", Source: &FileSource{Mime: "image/png", Data: image}},
+			"anthropic",
+			1000,
+		},
+		{
+			"openai image",
+			&Attachment{Type: "file", Source: &FileSource{Mime: "image/png", Data: image}},
+			"openai",
+			85 + 170*4,
+		},
+		{
+			"unreadable image",
+			&Attachment{Type: "file", Source: &FileSource{Mime: "image/png", Data: []byte("nope")}},
+			"anthropic",
+			defaultImageTokens,
+		},
+		{
+			"missing file",
+			&Attachment{Type: "file", Source: &FileSource{Mime: "text/plain", Path: "/does/not/exist"}},
+			"anthropic",
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateTokens(tt.attachment, tt.provider); got != tt.want {
+				t.Errorf("EstimateTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateTokensCachesFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("a", 400)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	att := &Attachment{Type: "file", Source: &FileSource{Mime: "text/plain", Path: path}}
+	if got := EstimateTokens(att, "anthropic"); got != 100 {
+		t.Fatalf("EstimateTokens() = %d, want 100", got)
+	}
+
+	// a render after the first doesn't stat the file again
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := EstimateTokens(att, "anthropic"); got != 100 {
+		t.Errorf("EstimateTokens() after removal = %d, want the cached 100", got)
+	}
+}
@@ -80,6 +80,8 @@ const (
 	FmtText Format = iota
 	// FmtImage indicates image/png clipboard format
 	FmtImage
+	// FmtHTML indicates text/html clipboard format, it can only be read
+	FmtHTML
 )
 
 var (
@@ -33,6 +33,8 @@ func read(t Format) (buf []byte, err error) {
 		return readText()
 	case FmtImage:
 		return readImage()
+	case FmtHTML:
+		return readHTML()
 	default:
 		return nil, errUnsupported
 	}
@@ -91,6 +93,26 @@ func readImage() ([]byte, error) {
 	end try
 	`
 
+	return readData(script, "PNGf")
+}
+
+// readHTML reads the HTML of the clipboard, copied from a browser or a
+// document
+func readHTML() ([]byte, error) {
+	script := `
+	try
+		set theData to the clipboard as «class HTML»
+		return theData
+	on error
+		return ""
+	end try
+	`
+	return readData(script, "HTML")
+}
+
+// readData runs the script returning the clipboard as the class and decodes
+// the data it prints
+func readData(script, class string) ([]byte, error) {
 	cmd := exec.Command("osascript", "-e", script)
 	out, err := cmd.Output()
 	if err != nil {
@@ -106,12 +128,12 @@ func readImage() ([]byte, error) {
 	// The output is in hex format (e.g., «data PNGf89504E...»)
 	// We need to extract and convert it
 	outStr := string(out)
-	if !strings.HasPrefix(outStr, "«data PNGf") || !strings.HasSuffix(outStr, "»") {
+	if !strings.HasPrefix(outStr, "«data "+class) || !strings.HasSuffix(outStr, "»") {
 		return nil, errUnavailable
 	}
 
 	// Extract hex data
-	hexData := strings.TrimPrefix(outStr, "«data PNGf")
+	hexData := strings.TrimPrefix(outStr, "«data "+class)
 	hexData = strings.TrimSuffix(hexData, "»")
 
 	// Convert hex to bytes
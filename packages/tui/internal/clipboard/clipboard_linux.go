@@ -128,6 +128,8 @@ func read(t Format) (buf []byte, err error) {
 		return readText(tool)
 	case FmtImage:
 		return readImage(tool)
+	case FmtHTML:
+		return readHTML(tool.name)
 	default:
 		return nil, errUnsupported
 	}
@@ -189,6 +191,25 @@ func readImage(tool struct {
 	return out, nil
 }
 
+// readHTML reads the HTML of the clipboard, copied from a browser or a
+// document, when the tool can ask for it
+func readHTML(name string) ([]byte, error) {
+	var cmd *exec.Cmd
+	switch name {
+	case "xclip":
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "text/html", "-o")
+	case "wl-copy":
+		cmd = exec.Command("wl-paste", "-t", "text/html", "-n")
+	default:
+		return nil, errUnsupported
+	}
+	out, err := cmd.Output()
+	if err != nil || len(out) == 0 {
+		return nil, errUnavailable
+	}
+	return out, nil
+}
+
 func write(t Format, buf []byte) (<-chan struct{}, error) {
 	// Ensure clipboard is initialized before attempting to write
 	if err := initialize(); err != nil {
@@ -61,6 +61,32 @@ func readText() (buf []byte, err error) {
 	return []byte(string(utf16.Decode(s))), nil
 }
 
+// readHTML reads the HTML the clipboard holds in the registered format,
+// without the header describing its offsets. The caller is responsible for
+// opening/closing the clipboard before calling this function.
+func readHTML(format uintptr) ([]byte, error) {
+	hMem, _, err := getClipboardData.Call(format)
+	if hMem == 0 {
+		return nil, err
+	}
+	p, _, err := gLock.Call(hMem)
+	if p == 0 {
+		return nil, err
+	}
+	defer gUnlock.Call(hMem)
+
+	// The data is UTF-8 and NUL terminated
+	var buf []byte
+	for ptr := unsafe.Pointer(p); *(*byte)(ptr) != 0; ptr = unsafe.Pointer(uintptr(ptr) + 1) {
+		buf = append(buf, *(*byte)(ptr))
+	}
+	start := bytes.IndexByte(buf, '<')
+	if start == -1 {
+		return nil, errUnavailable
+	}
+	return buf[start:], nil
+}
+
 // writeText writes given data to the clipboard. It is the caller's
 // responsibility for opening/closing the clipboard before calling
 // this function.
@@ -305,6 +331,12 @@ func read(t Format) (buf []byte, err error) {
 	switch t {
 	case FmtImage:
 		format = cFmtDIBV5
+	case FmtHTML:
+		name, _ := syscall.BytePtrFromString("HTML Format")
+		format, _, _ = registerClipboardFormatA.Call(uintptr(unsafe.Pointer(name)))
+		if format == 0 {
+			return nil, errUnavailable
+		}
 	case FmtText:
 		fallthrough
 	default:
@@ -327,6 +359,9 @@ func read(t Format) (buf []byte, err error) {
 	}
 	defer closeClipboard.Call()
 
+	if t == FmtHTML {
+		return readHTML(format)
+	}
 	switch format {
 	case cFmtDIBV5:
 		return readImage()
@@ -357,6 +392,10 @@ func write(t Format, buf []byte) (<-chan struct{}, error) {
 
 		// var param uintptr
 		switch t {
+		case FmtHTML:
+			errch <- errUnsupported
+			closeClipboard.Call()
+			return
 		case FmtImage:
 			err := writeImage(buf)
 			if err != nil {
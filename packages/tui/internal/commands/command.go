@@ -24,12 +24,57 @@ func (k Keybinding) Matches(msg tea.KeyPressMsg, leader bool) bool {
 	return key == msg.String() && (k.RequiresLeader == leader)
 }
 
+// NeedsKeyDisambiguation reports whether terminals send the key the same as
+// another one, e.g. shift+enter as enter, unless they speak the kitty keyboard
+// protocol or xterm's modifyOtherKeys
+func (k Keybinding) NeedsKeyDisambiguation() bool {
+	parts := strings.Split(strings.TrimSpace(k.Key), "+")
+	key, modifiers := parts[len(parts)-1], parts[:len(parts)-1]
+	ctrl := slices.Contains(modifiers, "ctrl")
+	shift := slices.Contains(modifiers, "shift")
+	for _, modifier := range []string{"super", "hyper", "meta"} {
+		if slices.Contains(modifiers, modifier) {
+			return true
+		}
+	}
+	switch key {
+	case "enter", "backspace", "esc", "escape":
+		return ctrl || shift
+	case "space":
+		return shift
+	case "tab", "i", "m", "[":
+		return ctrl
+	}
+	if ctrl && len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+		return true
+	}
+	return ctrl && shift
+}
+
+// Keymap names where keybindings apply, following the focused pane
+type Keymap string
+
+const (
+	// KeymapGlobal bindings apply wherever the focus is, unless the focused
+	// pane's keymap binds the same key
+	KeymapGlobal     Keymap = ""
+	KeymapEditor     Keymap = "editor"
+	KeymapMessages   Keymap = "messages"
+	KeymapFileViewer Keymap = "fileviewer"
+)
+
 type CommandName string
 type Command struct {
 	Name        CommandName
 	Description string
 	Keybindings []Keybinding
 	Trigger     []string
+	// Keymap is where the keybindings apply
+	Keymap Keymap
+	// Custom is what a command defined in the tui config does
+	Custom *opencode.ConfigTuiCommand
+	// Arguments is the text typed after a custom command's trigger
+	Arguments string
 }
 
 func (c Command) Keys() []string {
@@ -55,6 +100,33 @@ func (c Command) MatchesTrigger(trigger string) bool {
 	return slices.Contains(c.Trigger, trigger)
 }
 
+// mutatingCommands change the session, the workspace or the server, which
+// read-only mode doesn't allow
+var mutatingCommands = []CommandName{
+	AgentEditCommand,
+	SessionShareCommand,
+	SessionUnshareCommand,
+	SessionInterruptCommand,
+	SessionCompactCommand,
+	SessionCleanupCommand,
+	SnapshotRestoreCommand,
+	GitCommitCommand,
+	SandboxStartCommand,
+	SandboxFinishCommand,
+	FilePinCommand,
+	FileHunkStageCommand,
+	ProjectInitCommand,
+	MessagesUndoCommand,
+	MessagesRedoCommand,
+	MessagesRetryCommand,
+}
+
+// Mutates reports whether the command changes the session, the workspace or
+// the server
+func (c Command) Mutates() bool {
+	return slices.Contains(mutatingCommands, c.Name)
+}
+
 type CommandRegistry map[CommandName]Command
 
 func (r CommandRegistry) Sorted() []Command {
@@ -94,41 +166,107 @@ func (r CommandRegistry) Sorted() []Command {
 	})
 	return commands
 }
-func (r CommandRegistry) Matches(msg tea.KeyPressMsg, leader bool) []Command {
-	var matched []Command
+
+// Matches returns the commands bound to the key in the keymap, or the global
+// ones when the keymap doesn't bind it
+func (r CommandRegistry) Matches(msg tea.KeyPressMsg, leader bool, keymap Keymap) []Command {
+	var matched, global []Command
 	for _, command := range r.Sorted() {
-		if command.Matches(msg, leader) {
+		if !command.Matches(msg, leader) {
+			continue
+		}
+		switch command.Keymap {
+		case keymap:
 			matched = append(matched, command)
+		case KeymapGlobal:
+			global = append(global, command)
 		}
 	}
-	return matched
+	if len(matched) > 0 {
+		return matched
+	}
+	return global
 }
 
 const (
 	AppHelpCommand              CommandName = "app_help"
+	AppPerformanceCommand       CommandName = "app_performance"
+	CommandRepeatCommand        CommandName = "command_repeat"
+	CommandHistoryCommand       CommandName = "command_history"
+	ToastActionCommand          CommandName = "toast_action"
+	NotificationListCommand     CommandName = "notification_list"
 	SwitchAgentCommand          CommandName = "switch_mode"
 	SwitchModeReverseCommand    CommandName = "switch_mode_reverse"
 	AgentListCommand            CommandName = "agent_list"
+	AgentEditCommand            CommandName = "agent_edit"
 	EditorOpenCommand           CommandName = "editor_open"
 	SessionNewCommand           CommandName = "session_new"
 	SessionListCommand          CommandName = "session_list"
+	SessionTemplateCommand      CommandName = "session_template"
 	SessionShareCommand         CommandName = "session_share"
 	SessionUnshareCommand       CommandName = "session_unshare"
 	SessionInterruptCommand     CommandName = "session_interrupt"
 	SessionCompactCommand       CommandName = "session_compact"
+	SessionCleanupCommand       CommandName = "session_cleanup"
+	SessionUsageCommand         CommandName = "session_usage"
+	SessionContextCommand       CommandName = "session_context"
+	SessionLimitsCommand        CommandName = "session_limits"
 	SessionExportCommand        CommandName = "session_export"
+	SnapshotRestoreCommand      CommandName = "snapshot_restore"
+	GitCommitCommand            CommandName = "git_commit"
+	SessionReviewCommand        CommandName = "session_review"
+	SandboxStartCommand         CommandName = "sandbox_start"
+	SandboxFinishCommand        CommandName = "sandbox_finish"
 	ToolDetailsCommand          CommandName = "tool_details"
+	MessagesAttributionCommand  CommandName = "messages_attribution"
 	ModelListCommand            CommandName = "model_list"
+	ProviderAuthCommand         CommandName = "provider_auth"
+	PermissionListCommand       CommandName = "permission_list"
 	ThemeListCommand            CommandName = "theme_list"
 	FileListCommand             CommandName = "file_list"
+	FileTreeCommand             CommandName = "file_tree"
 	FileCloseCommand            CommandName = "file_close"
 	FileSearchCommand           CommandName = "file_search"
 	FileDiffToggleCommand       CommandName = "file_diff_toggle"
+	FileFocusCommand            CommandName = "file_focus"
+	FileGrowCommand             CommandName = "file_grow"
+	FileShrinkCommand           CommandName = "file_shrink"
+	FilePinCommand              CommandName = "file_pin"
+	FileLineUpCommand           CommandName = "file_line_up"
+	FileLineDownCommand         CommandName = "file_line_down"
+	FilePageUpCommand           CommandName = "file_page_up"
+	FilePageDownCommand         CommandName = "file_page_down"
+	FileHalfPageUpCommand       CommandName = "file_half_page_up"
+	FileHalfPageDownCommand     CommandName = "file_half_page_down"
+	FileTopCommand              CommandName = "file_top"
+	FileBottomCommand           CommandName = "file_bottom"
+	FileHunkNextCommand         CommandName = "file_hunk_next"
+	FileHunkPreviousCommand     CommandName = "file_hunk_previous"
+	FileHunkStageCommand        CommandName = "file_hunk_stage"
+	FileDefinitionCommand       CommandName = "file_definition"
+	FileFindCommand             CommandName = "file_find"
+	FileFindNextCommand         CommandName = "file_find_next"
+	FileFindPreviousCommand     CommandName = "file_find_previous"
+	FileGotoLineCommand         CommandName = "file_goto_line"
+	FileBackCommand             CommandName = "file_back"
+	FileForwardCommand          CommandName = "file_forward"
+	FileViewedCommand           CommandName = "file_viewed"
+	FileFollowCommand           CommandName = "file_follow"
+	FileEditCommand             CommandName = "file_edit"
+	SymbolListCommand           CommandName = "symbol_list"
+	FileGrepCommand             CommandName = "file_grep"
 	ProjectInitCommand          CommandName = "project_init"
+	ProjectListCommand          CommandName = "project_list"
 	InputClearCommand           CommandName = "input_clear"
 	InputPasteCommand           CommandName = "input_paste"
+	InputOpenAttachmentCommand  CommandName = "input_open_attachment"
+	InputPreviewCommand         CommandName = "input_preview"
 	InputSubmitCommand          CommandName = "input_submit"
 	InputNewlineCommand         CommandName = "input_newline"
+	MessagesLineUpCommand       CommandName = "messages_line_up"
+	MessagesLineDownCommand     CommandName = "messages_line_down"
+	MessagesTopCommand          CommandName = "messages_top"
+	MessagesBottomCommand       CommandName = "messages_bottom"
 	MessagesPageUpCommand       CommandName = "messages_page_up"
 	MessagesPageDownCommand     CommandName = "messages_page_down"
 	MessagesHalfPageUpCommand   CommandName = "messages_half_page_up"
@@ -138,9 +276,15 @@ const (
 	MessagesFirstCommand        CommandName = "messages_first"
 	MessagesLastCommand         CommandName = "messages_last"
 	MessagesLayoutToggleCommand CommandName = "messages_layout_toggle"
+	MessagesScrollLockCommand   CommandName = "messages_scroll_lock"
 	MessagesCopyCommand         CommandName = "messages_copy"
 	MessagesUndoCommand         CommandName = "messages_undo"
 	MessagesRedoCommand         CommandName = "messages_redo"
+	MessagesRetryCommand        CommandName = "messages_retry"
+	ScratchpadToggleCommand     CommandName = "scratchpad_toggle"
+	ShellOutputCommand          CommandName = "shell_output"
+	ShellModeCommand            CommandName = "shell_mode"
+	ToolOutputAttachCommand     CommandName = "tool_output_attach"
 	AppExitCommand              CommandName = "app_exit"
 )
 
@@ -153,6 +297,20 @@ func (k Command) Matches(msg tea.KeyPressMsg, leader bool) bool {
 	return false
 }
 
+// NeedingKeyDisambiguation returns the commands whose keybindings all need
+// the terminal to tell apart keys it otherwise sends the same
+func (r CommandRegistry) NeedingKeyDisambiguation() []Command {
+	var needing []Command
+	for _, command := range r.Sorted() {
+		if len(command.Keybindings) > 0 && !slices.ContainsFunc(command.Keybindings, func(k Keybinding) bool {
+			return !k.NeedsKeyDisambiguation()
+		}) {
+			needing = append(needing, command)
+		}
+	}
+	return needing
+}
+
 func parseBindings(bindings ...string) []Keybinding {
 	var parsedBindings []Keybinding
 	for _, binding := range bindings {
@@ -177,6 +335,33 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>h"),
 			Trigger:     []string{"help"},
 		},
+		{
+			Name:        AppPerformanceCommand,
+			Description: "toggle performance overlay",
+			Trigger:     []string{"performance"},
+		},
+		{
+			Name:        CommandRepeatCommand,
+			Description: "repeat last command",
+			Keybindings: parseBindings("<leader>."),
+		},
+		{
+			Name:        CommandHistoryCommand,
+			Description: "recent commands",
+			Keybindings: parseBindings("<leader>,"),
+			Trigger:     []string{"recent"},
+		},
+		{
+			Name:        ToastActionCommand,
+			Description: "run toast action",
+			Keybindings: parseBindings("<leader>A"),
+		},
+		{
+			Name:        NotificationListCommand,
+			Description: "notifications",
+			Keybindings: parseBindings("<leader>N"),
+			Trigger:     []string{"notifications"},
+		},
 		{
 			Name:        SwitchAgentCommand,
 			Description: "next mode",
@@ -193,6 +378,11 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>a"),
 			Trigger:     []string{"agents"},
 		},
+		{
+			Name:        AgentEditCommand,
+			Description: "create an agent",
+			Trigger:     []string{"agent-new"},
+		},
 		{
 			Name:        EditorOpenCommand,
 			Description: "open editor",
@@ -217,6 +407,11 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>l"),
 			Trigger:     []string{"sessions", "resume", "continue"},
 		},
+		{
+			Name:        SessionTemplateCommand,
+			Description: "new session from template",
+			Trigger:     []string{"template"},
+		},
 		{
 			Name:        SessionShareCommand,
 			Description: "share session",
@@ -240,24 +435,91 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>c"),
 			Trigger:     []string{"compact", "summarize"},
 		},
+		{
+			Name:        SessionCleanupCommand,
+			Description: "clean up old sessions",
+			Trigger:     []string{"cleanup", "prune"},
+		},
+		{
+			Name:        SessionUsageCommand,
+			Description: "show token, cost and tool usage",
+			Trigger:     []string{"usage", "cost"},
+		},
+		{
+			Name:        SessionContextCommand,
+			Description: "inspect context window",
+			Trigger:     []string{"context"},
+		},
+		{
+			Name:        SessionLimitsCommand,
+			Description: "configure run limits",
+			Trigger:     []string{"limits"},
+		},
+		{
+			Name:        SnapshotRestoreCommand,
+			Description: "restore workspace snapshot",
+			Trigger:     []string{"restore", "snapshots"},
+		},
+		{
+			Name:        GitCommitCommand,
+			Description: "commit changes",
+			Trigger:     []string{"commit"},
+		},
+		{
+			Name:        SessionReviewCommand,
+			Description: "review session changes",
+			Trigger:     []string{"review"},
+		},
+		{
+			Name:        SandboxStartCommand,
+			Description: "start sandbox branch",
+			Trigger:     []string{"sandbox"},
+		},
+		{
+			Name:        SandboxFinishCommand,
+			Description: "merge or discard sandbox",
+			Keybindings: parseBindings("<leader>z"),
+			Trigger:     []string{"sandbox-finish"},
+		},
 		{
 			Name:        ToolDetailsCommand,
 			Description: "toggle tool details",
 			Keybindings: parseBindings("<leader>d"),
 			Trigger:     []string{"details"},
 		},
+		{
+			Name:        MessagesAttributionCommand,
+			Description: "toggle model and agent captions",
+			Trigger:     []string{"attribution"},
+		},
 		{
 			Name:        ModelListCommand,
 			Description: "list models",
 			Keybindings: parseBindings("<leader>m", "f2"),
 			Trigger:     []string{"models"},
 		},
+		{
+			Name:        ProviderAuthCommand,
+			Description: "manage provider API keys",
+			Trigger:     []string{"auth", "login"},
+		},
+		{
+			Name:        PermissionListCommand,
+			Description: "manage permission rules",
+			Trigger:     []string{"permissions"},
+		},
 		{
 			Name:        ThemeListCommand,
 			Description: "list themes",
 			Keybindings: parseBindings("<leader>t"),
 			Trigger:     []string{"themes"},
 		},
+		{
+			Name:        FileTreeCommand,
+			Description: "browse files",
+			Keybindings: parseBindings("<leader>f"),
+			Trigger:     []string{"tree"},
+		},
 		// {
 		// 	Name:        FileListCommand,
 		// 	Description: "list files",
@@ -274,17 +536,177 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "search file",
 			Keybindings: parseBindings("<leader>/"),
 		},
+		{
+			Name:        FileGrepCommand,
+			Description: "search text in files",
+			Trigger:     []string{"grep"},
+		},
+		{
+			Name:        SymbolListCommand,
+			Description: "browse symbols",
+			Trigger:     []string{"symbols"},
+		},
 		{
 			Name:        FileDiffToggleCommand,
 			Description: "split/unified diff",
 			Keybindings: parseBindings("<leader>v"),
 		},
+		{
+			Name:        FileFocusCommand,
+			Description: "focus file/messages",
+			Keybindings: parseBindings("<leader>w"),
+		},
+		{
+			Name:        FileGrowCommand,
+			Description: "widen file pane",
+			Keybindings: parseBindings("<leader>]"),
+		},
+		{
+			Name:        FileShrinkCommand,
+			Description: "narrow file pane",
+			Keybindings: parseBindings("<leader>["),
+		},
+		{
+			Name:        FileFollowCommand,
+			Description: "toggle following edits",
+			Trigger:     []string{"follow"},
+		},
+		{
+			Name:        FilePinCommand,
+			Description: "pin file to session context",
+			Trigger:     []string{"pin"},
+		},
+		{
+			Name:        FileLineUpCommand,
+			Description: "scroll file up",
+			Keybindings: parseBindings("up", "k"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileLineDownCommand,
+			Description: "scroll file down",
+			Keybindings: parseBindings("down", "j"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FilePageUpCommand,
+			Description: "file page up",
+			Keybindings: parseBindings("pgup", "b"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FilePageDownCommand,
+			Description: "file page down",
+			Keybindings: parseBindings("pgdown", "f", "space"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileHalfPageUpCommand,
+			Description: "file half page up",
+			Keybindings: parseBindings("ctrl+u", "u"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileHalfPageDownCommand,
+			Description: "file half page down",
+			Keybindings: parseBindings("ctrl+d", "d"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileTopCommand,
+			Description: "go to top of file",
+			Keybindings: parseBindings("home", "g"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileBottomCommand,
+			Description: "go to bottom of file",
+			Keybindings: parseBindings("end", "G"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileHunkNextCommand,
+			Description: "next hunk",
+			Keybindings: parseBindings("]"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileHunkPreviousCommand,
+			Description: "previous hunk",
+			Keybindings: parseBindings("["),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileHunkStageCommand,
+			Description: "stage/unstage hunk",
+			Keybindings: parseBindings("s"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileDefinitionCommand,
+			Description: "go to definition",
+			Keybindings: parseBindings("enter", "ctrl+]"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileFindCommand,
+			Description: "find in file",
+			Keybindings: parseBindings("/"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileFindNextCommand,
+			Description: "next match",
+			Keybindings: parseBindings("n"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileFindPreviousCommand,
+			Description: "previous match",
+			Keybindings: parseBindings("N"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileGotoLineCommand,
+			Description: "go to line",
+			Keybindings: parseBindings(":"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileBackCommand,
+			Description: "previous file",
+			Keybindings: parseBindings("left", "h"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileForwardCommand,
+			Description: "next file",
+			Keybindings: parseBindings("right", "l"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileViewedCommand,
+			Description: "viewed files",
+			Keybindings: parseBindings("tab"),
+			Keymap:      KeymapFileViewer,
+		},
+		{
+			Name:        FileEditCommand,
+			Description: "open in editor",
+			Keybindings: parseBindings("e"),
+			Keymap:      KeymapFileViewer,
+		},
 		{
 			Name:        ProjectInitCommand,
 			Description: "create/update .agentrc",
 			Keybindings: parseBindings("<leader>i"),
 			Trigger:     []string{"init"},
 		},
+		{
+			Name:        ProjectListCommand,
+			Description: "open recent project",
+			Trigger:     []string{"projects"},
+		},
 		{
 			Name:        InputClearCommand,
 			Description: "clear input",
@@ -295,6 +717,16 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "paste content",
 			Keybindings: parseBindings("ctrl+v", "super+v"),
 		},
+		{
+			Name:        InputOpenAttachmentCommand,
+			Description: "open attachment",
+			Keybindings: parseBindings("ctrl+o"),
+		},
+		{
+			Name:        InputPreviewCommand,
+			Description: "preview attachment",
+			Keybindings: parseBindings("alt+o"),
+		},
 		{
 			Name:        InputSubmitCommand,
 			Description: "submit message",
@@ -305,6 +737,30 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "insert newline",
 			Keybindings: parseBindings("shift+enter", "ctrl+j"),
 		},
+		{
+			Name:        MessagesLineUpCommand,
+			Description: "scroll up",
+			Keybindings: parseBindings("up", "k"),
+			Keymap:      KeymapMessages,
+		},
+		{
+			Name:        MessagesLineDownCommand,
+			Description: "scroll down",
+			Keybindings: parseBindings("down", "j"),
+			Keymap:      KeymapMessages,
+		},
+		{
+			Name:        MessagesTopCommand,
+			Description: "scroll to top",
+			Keybindings: parseBindings("home", "g"),
+			Keymap:      KeymapMessages,
+		},
+		{
+			Name:        MessagesBottomCommand,
+			Description: "scroll to bottom",
+			Keybindings: parseBindings("end", "G"),
+			Keymap:      KeymapMessages,
+		},
 		{
 			Name:        MessagesPageUpCommand,
 			Description: "page up",
@@ -350,6 +806,12 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "toggle layout",
 			Keybindings: parseBindings("<leader>p"),
 		},
+		{
+			Name:        MessagesScrollLockCommand,
+			Description: "toggle auto-scroll",
+			Keybindings: parseBindings("<leader>k"),
+			Trigger:     []string{"scroll"},
+		},
 		{
 			Name:        MessagesCopyCommand,
 			Description: "copy message",
@@ -367,6 +829,36 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>r"),
 			Trigger:     []string{"redo"},
 		},
+		{
+			Name:        MessagesRetryCommand,
+			Description: "retry last message",
+			Keybindings: parseBindings("<leader>g"),
+			Trigger:     []string{"retry"},
+		},
+		{
+			Name:        ScratchpadToggleCommand,
+			Description: "toggle scratchpad",
+			Keybindings: parseBindings("<leader>o"),
+			Trigger:     []string{"scratchpad", "notes"},
+		},
+		{
+			Name:        ShellOutputCommand,
+			Description: "show shell output",
+			Keybindings: parseBindings("<leader>!"),
+			Trigger:     []string{"output"},
+		},
+		{
+			Name:        ShellModeCommand,
+			Description: "toggle shell mode",
+			Keybindings: parseBindings("<leader>$"),
+			Trigger:     []string{"shell"},
+		},
+		{
+			Name:        ToolOutputAttachCommand,
+			Description: "attach last tool output",
+			Keybindings: parseBindings("<leader>b"),
+			Trigger:     []string{"attach-output"},
+		},
 		{
 			Name:        AppExitCommand,
 			Description: "exit the app",
@@ -392,5 +884,6 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 		}
 		registry[command.Name] = command
 	}
+	registry.loadCustom(config)
 	return registry
 }
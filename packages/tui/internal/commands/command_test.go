@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func TestNeedsKeyDisambiguation(t *testing.T) {
+	tests := map[string]bool{
+		"enter":        false,
+		"shift+enter":  true,
+		"ctrl+enter":   true,
+		"alt+enter":    false,
+		"shift+tab":    false,
+		"ctrl+i":       true,
+		"ctrl+j":       false,
+		"ctrl+x":       false,
+		"ctrl+shift+x": true,
+		"ctrl+1":       true,
+		"super+v":      true,
+		"space":        false,
+		"shift+space":  true,
+	}
+	for key, expected := range tests {
+		if got := (Keybinding{Key: key}).NeedsKeyDisambiguation(); got != expected {
+			t.Errorf("NeedsKeyDisambiguation(%q) = %v, want %v", key, got, expected)
+		}
+	}
+}
+
+func TestNeedingKeyDisambiguation(t *testing.T) {
+	registry := CommandRegistry{
+		InputNewlineCommand: {Name: InputNewlineCommand, Keybindings: parseBindings("shift+enter", "ctrl+j")},
+		InputSubmitCommand:  {Name: InputSubmitCommand, Keybindings: parseBindings("ctrl+enter")},
+	}
+	needing := registry.NeedingKeyDisambiguation()
+	if len(needing) != 1 || needing[0].Name != InputSubmitCommand {
+		t.Errorf("expected only the submit command to need key disambiguation, got %v", needing)
+	}
+}
+
+func TestMatchesKeymap(t *testing.T) {
+	registry := CommandRegistry{
+		MessagesPageUpCommand: {Name: MessagesPageUpCommand, Keybindings: parseBindings("pgup")},
+		FilePageUpCommand:     {Name: FilePageUpCommand, Keybindings: parseBindings("pgup"), Keymap: KeymapFileViewer},
+	}
+	pgup := tea.KeyPressMsg{Code: tea.KeyPgUp}
+
+	tests := map[Keymap]CommandName{
+		KeymapEditor:     MessagesPageUpCommand,
+		KeymapMessages:   MessagesPageUpCommand,
+		KeymapFileViewer: FilePageUpCommand,
+	}
+	for keymap, expected := range tests {
+		matches := registry.Matches(pgup, false, keymap)
+		if len(matches) != 1 || matches[0].Name != expected {
+			t.Errorf("expected pgup to run %s in the %s keymap, got %v", expected, keymap, matches)
+		}
+	}
+}
+
+func TestMutates(t *testing.T) {
+	for _, name := range []CommandName{MessagesUndoCommand, SessionInterruptCommand, SessionShareCommand, GitCommitCommand} {
+		if !(Command{Name: name}).Mutates() {
+			t.Errorf("%s should mutate", name)
+		}
+	}
+	for _, name := range []CommandName{SessionListCommand, FileFindCommand, MessagesCopyCommand} {
+		if (Command{Name: name}).Mutates() {
+			t.Errorf("%s should not mutate", name)
+		}
+	}
+}
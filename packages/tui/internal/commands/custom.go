@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// argumentsPlaceholder is replaced by the text typed after a custom command
+const argumentsPlaceholder = "$ARGUMENTS"
+
+// CustomCommandName names the command defined in the tui config as name
+func CustomCommandName(name string) CommandName {
+	return CommandName("custom:" + name)
+}
+
+// Expand replaces $ARGUMENTS in a custom command's prompt or shell command
+// with the text typed after it
+func (c Command) Expand(template string) string {
+	return strings.ReplaceAll(template, argumentsPlaceholder, c.Arguments)
+}
+
+// NeedsArguments reports whether the custom command's prompt takes text typed
+// after it that wasn't given
+func (c Command) NeedsArguments() bool {
+	return c.Custom != nil && c.Arguments == "" && strings.Contains(c.Custom.Prompt, argumentsPlaceholder)
+}
+
+// ByTrigger returns the command run by the trigger
+func (r CommandRegistry) ByTrigger(trigger string) (Command, bool) {
+	for _, command := range r {
+		if command.MatchesTrigger(trigger) {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// firstLine returns the first line of the first non-empty text
+func firstLine(texts ...string) string {
+	for _, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			line, _, _ := strings.Cut(text, "\n")
+			return line
+		}
+	}
+	return ""
+}
+
+// loadCustom adds the commands defined in the tui config, leaving out those
+// whose name is already a built-in trigger
+func (r CommandRegistry) loadCustom(config *opencode.Config) {
+	var names []string
+	for name := range config.Tui.Commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		custom := config.Tui.Commands[name]
+		if _, ok := r.ByTrigger(name); ok {
+			slog.Warn("Custom command shadows a built-in command", "command", name)
+			continue
+		}
+		description := custom.Description
+		if description == "" {
+			description = firstLine(custom.Prompt, custom.Shell, custom.Run)
+		}
+		command := Command{
+			Name:        CustomCommandName(name),
+			Description: description,
+			Trigger:     []string{name},
+			Custom:      &custom,
+		}
+		if custom.Keybind != "" && custom.Keybind != "none" {
+			command.Keybindings = parseBindings(custom.Keybind)
+		}
+		r[command.Name] = command
+	}
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestLoadCustom(t *testing.T) {
+	config := &opencode.Config{}
+	config.Tui.Commands = map[string]opencode.ConfigTuiCommand{
+		"fix":  {Prompt: "Fix $ARGUMENTS\nand run the tests"},
+		"test": {Description: "run the tests", Shell: "go test ./..."},
+		"help": {Run: "tool_details"},
+	}
+	registry := LoadFromConfig(config)
+
+	fix, ok := registry.ByTrigger("fix")
+	if !ok || fix.Name != CustomCommandName("fix") {
+		t.Fatalf("expected /fix to run the custom command, got %v", fix.Name)
+	}
+	if fix.Description != "Fix $ARGUMENTS" {
+		t.Errorf("expected the prompt's first line as description, got %q", fix.Description)
+	}
+	if !fix.NeedsArguments() {
+		t.Error("expected /fix without arguments to need them")
+	}
+	fix.Arguments = "the login test"
+	if got := fix.Expand(fix.Custom.Prompt); got != "Fix the login test\nand run the tests" {
+		t.Errorf("unexpected expanded prompt %q", got)
+	}
+
+	if test := registry[CustomCommandName("test")]; test.Description != "run the tests" {
+		t.Errorf("expected the configured description, got %q", test.Description)
+	}
+	if _, ok := registry[CustomCommandName("help")]; ok {
+		t.Error("expected the custom /help to leave the built-in one alone")
+	}
+}
@@ -0,0 +1,70 @@
+package commands
+
+import "slices"
+
+// maxHistory is how many distinct commands the history keeps
+const maxHistory = 20
+
+// History is the commands run lately, most recent first and each once
+type History struct {
+	names []CommandName
+}
+
+// unrepeatable are the commands left out of the history: scrolling, typing,
+// the history's own commands and acting on whichever toast is showing
+var unrepeatable = map[CommandName]bool{
+	CommandRepeatCommand:        true,
+	CommandHistoryCommand:       true,
+	ToastActionCommand:          true,
+	AppExitCommand:              true,
+	InputClearCommand:           true,
+	InputPasteCommand:           true,
+	InputSubmitCommand:          true,
+	InputNewlineCommand:         true,
+	SessionInterruptCommand:     true,
+	FileLineUpCommand:           true,
+	FileLineDownCommand:         true,
+	FilePageUpCommand:           true,
+	FilePageDownCommand:         true,
+	FileHalfPageUpCommand:       true,
+	FileHalfPageDownCommand:     true,
+	FileTopCommand:              true,
+	FileBottomCommand:           true,
+	MessagesLineUpCommand:       true,
+	MessagesLineDownCommand:     true,
+	MessagesTopCommand:          true,
+	MessagesBottomCommand:       true,
+	MessagesPageUpCommand:       true,
+	MessagesPageDownCommand:     true,
+	MessagesHalfPageUpCommand:   true,
+	MessagesHalfPageDownCommand: true,
+	MessagesPreviousCommand:     true,
+	MessagesNextCommand:         true,
+	MessagesFirstCommand:        true,
+	MessagesLastCommand:         true,
+}
+
+// Add records the command as the most recent one
+func (h *History) Add(name CommandName) {
+	if unrepeatable[name] {
+		return
+	}
+	h.names = slices.DeleteFunc(h.names, func(n CommandName) bool { return n == name })
+	h.names = slices.Insert(h.names, 0, name)
+	if len(h.names) > maxHistory {
+		h.names = h.names[:maxHistory]
+	}
+}
+
+// Last returns the most recent command
+func (h History) Last() (CommandName, bool) {
+	if len(h.names) == 0 {
+		return "", false
+	}
+	return h.names[0], true
+}
+
+// Names returns the commands, most recent first
+func (h History) Names() []CommandName {
+	return slices.Clone(h.names)
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	var history History
+	if _, ok := history.Last(); ok {
+		t.Error("expected an empty history to have no last command")
+	}
+
+	history.Add(ToolDetailsCommand)
+	history.Add(MessagesPageUpCommand)
+	history.Add(ThemeListCommand)
+	history.Add(CommandRepeatCommand)
+	history.Add(ToolDetailsCommand)
+
+	expected := []CommandName{ToolDetailsCommand, ThemeListCommand}
+	if got := history.Names(); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if last, _ := history.Last(); last != ToolDetailsCommand {
+		t.Errorf("expected the last command to be %s, got %s", ToolDetailsCommand, last)
+	}
+
+	for i := range maxHistory + 5 {
+		history.Add(CommandName(string(rune('a' + i))))
+	}
+	if got := len(history.Names()); got != maxHistory {
+		t.Errorf("expected the history to keep %d commands, got %d", maxHistory, got)
+	}
+}
@@ -0,0 +1,95 @@
+package completions
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// maxCustomSuggestions caps the lines of a custom provider's output listed
+const maxCustomSuggestions = 100
+
+// customTimeout stops a slow command from holding up the completion
+const customTimeout = 2 * time.Second
+
+// customContextGroup lists the output of a shell command from the config, a
+// suggestion a line
+type customContextGroup struct {
+	id    string
+	shell string
+	dir   string
+}
+
+func (cg *customContextGroup) GetId() string {
+	return cg.id
+}
+
+func (cg *customContextGroup) GetEmptyMessage() string {
+	return "no matching suggestions"
+}
+
+// GetChildEntries runs the command with the text typed in $QUERY
+func (cg *customContextGroup) GetChildEntries(
+	query string,
+) ([]CompletionSuggestion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), customTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", cg.shell)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", cg.shell)
+	}
+	cmd.Dir = cg.dir
+	cmd.Env = append(os.Environ(), "QUERY="+strings.TrimSpace(query))
+	output, err := cmd.Output()
+	if err != nil {
+		slog.Error("Failed to run completion provider", "provider", cg.id, "error", err)
+		return []CompletionSuggestion{}, err
+	}
+	return parseCustomSuggestions(cg.id, string(output)), nil
+}
+
+// parseCustomSuggestions reads a suggestion a line, a tab splitting the
+// value from its description
+func parseCustomSuggestions(id, output string) []CompletionSuggestion {
+	items := make([]CompletionSuggestion, 0)
+	for line := range strings.SplitSeq(output, "\n") {
+		if len(items) >= maxCustomSuggestions {
+			break
+		}
+		value, description, _ := strings.Cut(strings.TrimRight(line, "\r"), "\t")
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		items = append(items, CompletionSuggestion{
+			Display: func(s styles.Style) string {
+				if description == "" {
+					return s.Render(value)
+				}
+				t := theme.CurrentTheme()
+				return s.Render(value) + s.Foreground(t.TextMuted()).Render(" "+description)
+			},
+			Value:       value,
+			Description: description,
+			ProviderID:  id,
+			RawData:     value,
+		})
+	}
+	return items
+}
+
+// NewCustomContextGroup creates a provider listing the output of a shell
+// command run in dir
+func NewCustomContextGroup(id, shell, dir string) CompletionProvider {
+	return &customContextGroup{
+		id:    id,
+		shell: shell,
+		dir:   dir,
+	}
+}
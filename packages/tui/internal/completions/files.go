@@ -1,21 +1,40 @@
 package completions
 
 import (
+	"cmp"
 	"context"
 	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/lithammer/fuzzysearch/fuzzy"
 	opencode "github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/git"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 )
 
+// maxRecentSuggestions caps the files used recently listed ahead of the
+// others
+const maxRecentSuggestions = 10
+
+// maxChildSuggestions caps the entries of a directory listed, huge ones would
+// hold up the completion
+const maxChildSuggestions = 100
+
 type filesContextGroup struct {
 	app      *app.App
 	gitFiles []CompletionSuggestion
+	// gitOnce loads the changed files on first use rather than at startup
+	gitOnce sync.Once
 }
 
 func (cg *filesContextGroup) GetId() string {
@@ -63,14 +82,125 @@ func (cg *filesContextGroup) getGitFiles() []CompletionSuggestion {
 	return items
 }
 
+// recentFiles returns the files used recently matching query, ranked by
+// frecency, leaving out the ones deleted since
+func (cg *filesContextGroup) recentFiles(query string) []CompletionSuggestion {
+	items := make([]CompletionSuggestion, 0)
+	now := time.Now()
+	for _, file := range cg.app.RecentFiles() {
+		if len(items) >= maxRecentSuggestions {
+			break
+		}
+		if query != "" && !fuzzy.MatchFold(query, file.Path) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(cg.app.Info.Path.Cwd, file.Path)); err != nil {
+			continue
+		}
+		name := file.Path
+		items = append(items, CompletionSuggestion{
+			Display:    func(s styles.Style) string { return s.Render(name) },
+			Value:      name,
+			ProviderID: cg.GetId(),
+			Boost:      file.Frecency(now),
+			RawData:    name,
+		})
+	}
+	return items
+}
+
+// children lists the entries of the directory the query is in matching the
+// rest of it, so a directory selected is descended into. At the top of the
+// project only directories are listed, the server finds the files.
+func (cg *filesContextGroup) children(query string) []CompletionSuggestion {
+	dir, rest := path.Split(query)
+	entries, err := os.ReadDir(filepath.Join(cg.app.Info.Path.Cwd, filepath.FromSlash(dir)))
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.Name() == ".git" || (dir == "" && !entry.IsDir()) {
+			continue
+		}
+		if rest != "" && !fuzzy.MatchFold(rest, entry.Name()) {
+			continue
+		}
+		name := dir + entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		paths = append(paths, name)
+	}
+	if cg.app.Info.Git {
+		ignored := git.IgnoredPaths(cg.app.Info.Path.Cwd, paths)
+		paths = slices.DeleteFunc(paths, func(name string) bool {
+			return ignored[name] || ignored[strings.TrimSuffix(name, "/")]
+		})
+	}
+	// directories first, each group stays sorted by name
+	files := func(name string) int {
+		if strings.HasSuffix(name, "/") {
+			return 0
+		}
+		return 1
+	}
+	slices.SortStableFunc(paths, func(a, b string) int {
+		return cmp.Compare(files(a), files(b))
+	})
+
+	items := make([]CompletionSuggestion, 0, min(len(paths), maxChildSuggestions))
+	for _, name := range paths[:min(len(paths), maxChildSuggestions)] {
+		items = append(items, CompletionSuggestion{
+			Display:    func(s styles.Style) string { return s.Render(name) },
+			Value:      name,
+			ProviderID: cg.GetId(),
+			Descend:    strings.HasSuffix(name, "/"),
+			RawData:    name,
+		})
+	}
+	return items
+}
+
+// GetChildEntries lists the files used recently first, then the changed
+// files and the files the server finds. The server leaves out the files git
+// ignores and caps the results, so huge repositories stay responsive. Once a
+// directory is descended into its entries come first.
 func (cg *filesContextGroup) GetChildEntries(
 	query string,
 ) ([]CompletionSuggestion, error) {
-	items := make([]CompletionSuggestion, 0)
+	cg.gitOnce.Do(func() {
+		cg.gitFiles = cg.getGitFiles()
+	})
 
 	query = strings.TrimSpace(query)
+	items := make([]CompletionSuggestion, 0)
+	seen := map[string]bool{}
+	add := func(item CompletionSuggestion) {
+		if !seen[item.Value] {
+			seen[item.Value] = true
+			items = append(items, item)
+		}
+	}
+
+	children := cg.children(query)
+	if strings.Contains(query, "/") {
+		for _, item := range children {
+			add(item)
+		}
+	}
+	for _, item := range cg.recentFiles(query) {
+		add(item)
+	}
+	for _, item := range children {
+		add(item)
+	}
+
 	if query == "" {
-		items = append(items, cg.gitFiles...)
+		for _, item := range cg.gitFiles {
+			add(item)
+		}
 	}
 
 	files, err := cg.app.Client.Find.Files(
@@ -86,41 +216,26 @@ func (cg *filesContextGroup) GetChildEntries(
 	}
 
 	for _, file := range *files {
-		exists := false
-		for _, existing := range cg.gitFiles {
-			if existing.Value == file {
-				if query != "" {
-					items = append(items, existing)
-				}
-				exists = true
-			}
-		}
-		if !exists {
-			displayFunc := func(s styles.Style) string {
-				// t := theme.CurrentTheme()
-				// return s.Foreground(t.Text()).Render(file)
-				return s.Render(file)
-			}
-
-			item := CompletionSuggestion{
-				Display:    displayFunc,
-				Value:      file,
-				ProviderID: cg.GetId(),
-				RawData:    file,
-			}
-			items = append(items, item)
+		index := slices.IndexFunc(cg.gitFiles, func(item CompletionSuggestion) bool {
+			return item.Value == file
+		})
+		if index >= 0 {
+			add(cg.gitFiles[index])
+			continue
 		}
+		add(CompletionSuggestion{
+			Display:    func(s styles.Style) string { return s.Render(file) },
+			Value:      file,
+			ProviderID: cg.GetId(),
+			RawData:    file,
+		})
 	}
 
 	return items, nil
 }
 
 func NewFileContextGroup(app *app.App) CompletionProvider {
-	cg := &filesContextGroup{
+	return &filesContextGroup{
 		app: app,
 	}
-	go func() {
-		cg.gitFiles = cg.getGitFiles()
-	}()
-	return cg
 }
@@ -0,0 +1,87 @@
+package completions
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/git"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// gitContextGroup offers the changes of the working tree, branches and
+// recent commits for attaching as diffs
+type gitContextGroup struct {
+	app *app.App
+}
+
+func (cg *gitContextGroup) GetId() string {
+	return "git"
+}
+
+func (cg *gitContextGroup) GetEmptyMessage() string {
+	return "no matching branches or commits"
+}
+
+// GetChildEntries lists the working tree diff first. Branches and commits
+// are only listed once something is typed, they would bury the files.
+func (cg *gitContextGroup) GetChildEntries(
+	query string,
+) ([]CompletionSuggestion, error) {
+	items := make([]CompletionSuggestion, 0)
+	if !cg.app.Info.Git {
+		return items, nil
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" || fuzzy.MatchFold(query, "diff") {
+		items = append(items, cg.suggestion("diff", "working tree changes", git.Ref{}))
+	}
+	if query == "" {
+		return items, nil
+	}
+
+	branches, err := git.Branches(cg.app.Info.Path.Cwd)
+	if err != nil {
+		slog.Error("Failed to list branches", "error", err)
+		return items, err
+	}
+	commits, err := git.Commits(cg.app.Info.Path.Cwd)
+	if err != nil {
+		slog.Error("Failed to list commits", "error", err)
+		return items, err
+	}
+	for _, ref := range append(branches, commits...) {
+		value := "commit:" + ref.Name
+		if ref.Branch {
+			value = "branch:" + ref.Name
+		}
+		if !fuzzy.MatchFold(query, value) && !fuzzy.MatchFold(query, ref.Subject) {
+			continue
+		}
+		items = append(items, cg.suggestion(value, ref.Subject, ref))
+	}
+	return items, nil
+}
+
+func (cg *gitContextGroup) suggestion(value, description string, ref git.Ref) CompletionSuggestion {
+	return CompletionSuggestion{
+		Display: func(s styles.Style) string {
+			t := theme.CurrentTheme()
+			muted := s.Foreground(t.TextMuted()).Render
+			return s.Render(value) + muted(" "+description)
+		},
+		Value:       value,
+		Description: description,
+		ProviderID:  cg.GetId(),
+		RawData:     ref,
+	}
+}
+
+func NewGitContextGroup(app *app.App) CompletionProvider {
+	return &gitContextGroup{
+		app: app,
+	}
+}
@@ -6,3 +6,10 @@ type CompletionProvider interface {
 	GetChildEntries(query string) ([]CompletionSuggestion, error)
 	GetEmptyMessage() string
 }
+
+// Snapshotter is a provider that copies what it reads of the app's state
+// when its completion opens, on the UI goroutine, since GetChildEntries runs
+// off it while the state may change
+type Snapshotter interface {
+	Snapshot()
+}
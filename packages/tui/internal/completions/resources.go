@@ -0,0 +1,79 @@
+package completions
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// resourcesContextGroup offers the resources MCP servers expose for
+// attaching their content
+type resourcesContextGroup struct {
+	app *app.App
+	// resources are listed again each time the completion opens, the
+	// servers are asked once rather than on every key
+	resources []opencode.McpResource
+	mu        sync.Mutex
+}
+
+func (cg *resourcesContextGroup) GetId() string {
+	return "resources"
+}
+
+func (cg *resourcesContextGroup) GetEmptyMessage() string {
+	return "no matching resources"
+}
+
+func (cg *resourcesContextGroup) GetChildEntries(
+	query string,
+) ([]CompletionSuggestion, error) {
+	items := make([]CompletionSuggestion, 0)
+
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	query = strings.TrimSpace(query)
+	if query == "" || cg.resources == nil {
+		resources, err := cg.app.Client.Mcp.Resources(context.Background())
+		if err != nil {
+			slog.Error("Failed to list MCP resources", "error", err)
+			return items, err
+		}
+		cg.resources = []opencode.McpResource{}
+		if resources != nil {
+			cg.resources = *resources
+		}
+	}
+
+	for _, resource := range cg.resources {
+		text := resource.Server + " " + resource.Name + " " + resource.Uri
+		if query != "" && !fuzzy.MatchFold(query, text) {
+			continue
+		}
+		displayFunc := func(s styles.Style) string {
+			t := theme.CurrentTheme()
+			muted := s.Foreground(t.TextMuted()).Render
+			return s.Render(resource.Name) + muted(" "+resource.Server+" "+resource.Uri)
+		}
+		items = append(items, CompletionSuggestion{
+			Display:     displayFunc,
+			Value:       resource.Name,
+			Description: resource.Description,
+			ProviderID:  cg.GetId(),
+			RawData:     resource,
+		})
+	}
+	return items, nil
+}
+
+func NewResourcesContextGroup(app *app.App) CompletionProvider {
+	return &resourcesContextGroup{
+		app: app,
+	}
+}
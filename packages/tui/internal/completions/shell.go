@@ -0,0 +1,194 @@
+package completions
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// maxShellSuggestions caps the commands listed, shell histories run long
+const maxShellSuggestions = 100
+
+// shellHistoryBytes is how much of the end of the shell's history file is
+// read, the recent commands are all that matter
+const shellHistoryBytes = 256 * 1024
+
+// shellContextGroup offers the commands run before, those run from kuuzuki
+// first and then those of the user's shell history
+type shellContextGroup struct {
+	app *app.App
+	// history is the shell's history, read again each time the completion
+	// opens
+	history []string
+	// run are the commands run from kuuzuki, copied when the completion opens
+	run []string
+	mu  sync.Mutex
+}
+
+// Snapshot copies the commands run from kuuzuki, which submitting a command
+// changes on the UI goroutine
+func (cg *shellContextGroup) Snapshot() {
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	cg.run = slices.Clone(cg.app.State.ShellHistory)
+}
+
+func (cg *shellContextGroup) GetId() string {
+	return "shell"
+}
+
+func (cg *shellContextGroup) GetEmptyMessage() string {
+	return "no matching commands"
+}
+
+func (cg *shellContextGroup) GetChildEntries(
+	query string,
+) ([]CompletionSuggestion, error) {
+	items := make([]CompletionSuggestion, 0)
+
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	if query == "" || cg.history == nil {
+		home, _ := os.UserHomeDir()
+		cg.history = shellHistory(home, os.Getenv("HISTFILE"), os.Getenv("SHELL"))
+	}
+
+	query = strings.TrimSpace(query)
+	lower := strings.ToLower(query)
+	seen := map[string]bool{}
+	run := len(cg.run)
+	for i, command := range slices.Concat(cg.run, cg.history) {
+		if len(items) >= maxShellSuggestions {
+			break
+		}
+		if seen[command] || !strings.Contains(strings.ToLower(command), lower) {
+			continue
+		}
+		seen[command] = true
+		fromShell := i >= run
+		items = append(items, CompletionSuggestion{
+			Display: func(s styles.Style) string {
+				if !fromShell {
+					return s.Render(command)
+				}
+				t := theme.CurrentTheme()
+				return s.Render(command) + s.Foreground(t.TextMuted()).Render(" (shell)")
+			},
+			Value:      command,
+			ProviderID: cg.GetId(),
+			RawData:    command,
+		})
+	}
+	// the command typed exactly runs with enter rather than a longer one
+	exact := slices.IndexFunc(items, func(item CompletionSuggestion) bool {
+		return item.Value == query
+	})
+	if exact > 0 {
+		item := items[exact]
+		items = slices.Insert(slices.Delete(items, exact, exact+1), 0, item)
+	}
+	return items, nil
+}
+
+// shellHistory reads the history of the user's shell, the most recent
+// command first. histfile is used when set, otherwise the history of the
+// shell named by shell, or of the first shell with one.
+func shellHistory(home, histfile, shell string) []string {
+	candidates := []string{
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".local", "share", "fish", "fish_history"),
+	}
+	switch filepath.Base(shell) {
+	case "bash":
+		candidates[0], candidates[1] = candidates[1], candidates[0]
+	case "fish":
+		candidates[0], candidates[2] = candidates[2], candidates[0]
+	}
+	if histfile != "" {
+		candidates = append([]string{histfile}, candidates...)
+	}
+
+	for _, path := range candidates {
+		content, err := readTail(path, shellHistoryBytes)
+		if err != nil {
+			continue
+		}
+		commands := parseShellHistory(content)
+		slices.Reverse(commands)
+		return commands
+	}
+	return nil
+}
+
+// readTail reads at most the last n bytes of a file
+func readTail(path string, n int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := max(info.Size()-n, 0)
+	content, err := io.ReadAll(io.NewSectionReader(file, offset, info.Size()-offset))
+	if err != nil {
+		return "", err
+	}
+	if offset > 0 {
+		// the first line is likely cut
+		_, rest, _ := strings.Cut(string(content), "\n")
+		return rest, nil
+	}
+	return string(content), nil
+}
+
+// parseShellHistory reads the commands of a bash, zsh or fish history, the
+// oldest first. Timestamps are dropped and commands spanning lines are
+// left out.
+func parseShellHistory(content string) []string {
+	var commands []string
+	continued := false
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		wasContinued := continued
+		continued = strings.HasSuffix(line, "\\")
+		if wasContinued || continued {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "- cmd: "):
+			// fish
+			line = strings.TrimPrefix(line, "- cmd: ")
+		case strings.HasPrefix(line, "  "), strings.HasPrefix(line, "#"):
+			// fish metadata, bash timestamps
+			continue
+		case strings.HasPrefix(line, ": "):
+			// zsh extended history, ": <start>:<elapsed>;<command>"
+			_, command, ok := strings.Cut(line, ";")
+			if !ok {
+				continue
+			}
+			line = command
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			commands = append(commands, line)
+		}
+	}
+	return commands
+}
+
+func NewShellContextGroup(app *app.App) CompletionProvider {
+	return &shellContextGroup{
+		app: app,
+	}
+}
@@ -0,0 +1,75 @@
+package completions
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseShellHistory(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []string
+	}{
+		"bash": {
+			content: "ls\n#1700000000\ngit status\n",
+			want:    []string{"ls", "git status"},
+		},
+		"zsh": {
+			content: ": 1700000000:0;npm test -- --watch=false\n: 1700000001:0;make build\n",
+			want:    []string{"npm test -- --watch=false", "make build"},
+		},
+		"fish": {
+			content: "- cmd: go test ./...\n  when: 1700000000\n- cmd: ls\n  when: 1700000001\n",
+			want:    []string{"go test ./...", "ls"},
+		},
+		"continued lines": {
+			content: "docker run \\\n  --rm image\nls\n",
+			want:    []string{"ls"},
+		},
+	}
+	for name, tt := range tests {
+		if got := parseShellHistory(tt.content); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: parseShellHistory() = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestShellHistory(t *testing.T) {
+	home := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(home, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".zsh_history", ": 1:0;zsh one\n: 2:0;zsh two\n")
+	write(".bash_history", "bash one\n")
+
+	if got, want := shellHistory(home, "", "/bin/zsh"), []string{"zsh two", "zsh one"}; !slices.Equal(got, want) {
+		t.Errorf("shellHistory() = %q, want %q, the most recent first", got, want)
+	}
+	if got, want := shellHistory(home, "", "/usr/bin/bash"), []string{"bash one"}; !slices.Equal(got, want) {
+		t.Errorf("shellHistory() = %q, want the history of bash %q", got, want)
+	}
+	histfile := filepath.Join(home, "custom")
+	write("custom", "custom\n")
+	if got := shellHistory(home, histfile, "/bin/zsh"); !slices.Equal(got, []string{"custom"}) {
+		t.Errorf("shellHistory() = %q, want HISTFILE read first", got)
+	}
+	if got := shellHistory(t.TempDir(), "", "/bin/zsh"); len(got) != 0 {
+		t.Errorf("shellHistory() = %q, want none without a history", got)
+	}
+
+	write("long", strings.Repeat("x", shellHistoryBytes)+"\nlast\n")
+	content, err := readTail(filepath.Join(home, "long"), shellHistoryBytes)
+	if err != nil || content != "last\n" {
+		t.Errorf("readTail() = %q, %v, want the cut first line dropped", content, err)
+	}
+}
@@ -18,6 +18,14 @@ type CompletionSuggestion struct {
 	// The ID of the provider that generated this suggestion.
 	ProviderID string
 
+	// Boost ranks the suggestion ahead of those matching the query as well
+	// or better, highest first. Files used recently are boosted by frecency.
+	Boost float64
+
+	// Descend lists the children of the suggestion when it's completed
+	// rather than completing it, like a directory does.
+	Descend bool
+
 	// The raw, underlying data object (e.g., opencode.Symbol, commands.Command).
 	// This allows the selection handler to perform rich actions.
 	RawData any
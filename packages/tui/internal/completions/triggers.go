@@ -0,0 +1,92 @@
+package completions
+
+import (
+	"log/slog"
+	"maps"
+	"strings"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+// Trigger is a completion opened by typing its character in the editor
+type Trigger struct {
+	Providers []CompletionProvider
+	Position  opencode.ConfigTuiCompletionsPosition
+	// Spaces keeps the completion open when a space is typed
+	Spaces bool
+}
+
+// Opens reports whether typing the trigger after value, the text in the
+// editor, opens the completion
+func (t Trigger) Opens(value string) bool {
+	if len(t.Providers) == 0 {
+		return false
+	}
+	switch t.Position {
+	case opencode.ConfigTuiCompletionsPositionLine:
+		return value == ""
+	case opencode.ConfigTuiCompletionsPositionAnywhere:
+		return true
+	default:
+		return value == "" || strings.HasSuffix(value, " ") || strings.HasSuffix(value, "\n")
+	}
+}
+
+// defaultTriggers are the completions opened when the config sets none
+var defaultTriggers = map[string]opencode.ConfigTuiCompletion{
+	"/": {Providers: []string{"commands"}, Position: opencode.ConfigTuiCompletionsPositionLine},
+	"@": {Providers: []string{"files", "symbols", "agents", "git"}, Position: opencode.ConfigTuiCompletionsPositionAnywhere},
+	"#": {Providers: []string{"resources"}, Position: opencode.ConfigTuiCompletionsPositionWord},
+	"!": {Providers: []string{"shell"}, Position: opencode.ConfigTuiCompletionsPositionLine, Spaces: true},
+}
+
+// Providers returns the built-in providers by their ID
+func Providers(app *app.App) map[string]CompletionProvider {
+	providers := map[string]CompletionProvider{}
+	for _, provider := range []CompletionProvider{
+		NewCommandCompletionProvider(app),
+		NewFileContextGroup(app),
+		NewSymbolsContextGroup(app),
+		NewAgentsContextGroup(app),
+		NewGitContextGroup(app),
+		NewResourcesContextGroup(app),
+		NewShellContextGroup(app),
+	} {
+		providers[provider.GetId()] = provider
+	}
+	return providers
+}
+
+// Triggers returns the completion each trigger character opens: the
+// defaults, with those of the config replacing them. Providers are the
+// built-in ones and the config's custom ones.
+func Triggers(app *app.App, providers map[string]CompletionProvider) map[string]Trigger {
+	providers = maps.Clone(providers)
+	configs := maps.Clone(defaultTriggers)
+	if app.Config != nil {
+		for id, custom := range app.Config.Tui.CompletionProviders {
+			if _, ok := providers[id]; ok {
+				slog.Warn("Custom completion provider named like a built-in one", "provider", id)
+				continue
+			}
+			providers[id] = NewCustomContextGroup(id, custom.Shell, app.Info.Path.Cwd)
+		}
+		maps.Copy(configs, app.Config.Tui.Completions)
+	}
+
+	triggers := map[string]Trigger{}
+	for char, config := range configs {
+		trigger := Trigger{Position: config.Position, Spaces: config.Spaces}
+		for _, id := range config.Providers {
+			provider, ok := providers[id]
+			if !ok {
+				slog.Warn("Unknown completion provider", "trigger", char, "provider", id)
+				continue
+			}
+			trigger.Providers = append(trigger.Providers, provider)
+		}
+		triggers[char] = trigger
+	}
+	return triggers
+}
@@ -0,0 +1,88 @@
+package completions
+
+import (
+	"runtime"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+func TestTriggerOpens(t *testing.T) {
+	providers := []CompletionProvider{NewCustomContextGroup("test", "true", "")}
+	tests := []struct {
+		position opencode.ConfigTuiCompletionsPosition
+		value    string
+		want     bool
+	}{
+		{opencode.ConfigTuiCompletionsPositionLine, "", true},
+		{opencode.ConfigTuiCompletionsPositionLine, "fix ", false},
+		{opencode.ConfigTuiCompletionsPositionWord, "fix ", true},
+		{opencode.ConfigTuiCompletionsPositionWord, "issue", false},
+		{"", "line\n", true},
+		{opencode.ConfigTuiCompletionsPositionAnywhere, "issue", true},
+	}
+	for _, tt := range tests {
+		trigger := Trigger{Providers: providers, Position: tt.position}
+		if got := trigger.Opens(tt.value); got != tt.want {
+			t.Errorf("Opens(%q) at %q = %v, want %v", tt.value, tt.position, got, tt.want)
+		}
+	}
+	if (Trigger{Position: opencode.ConfigTuiCompletionsPositionAnywhere}).Opens("") {
+		t.Errorf("Opens() = true, want a trigger without providers off")
+	}
+}
+
+func TestTriggers(t *testing.T) {
+	a := &app.App{Config: &opencode.Config{Tui: opencode.ConfigTui{
+		Completions: map[string]opencode.ConfigTuiCompletion{
+			"#": {},
+			"@": {Providers: []string{"files", "missing"}},
+			"$": {Providers: []string{"tags", "commands"}, Spaces: true},
+		},
+		CompletionProviders: map[string]opencode.ConfigTuiCompletionProvider{
+			"tags":  {Shell: "git tag"},
+			"files": {Shell: "ls"},
+		},
+	}}}
+	providers := Providers(a)
+	triggers := Triggers(a, providers)
+
+	if len(triggers["/"].Providers) != 1 || triggers["/"].Providers[0].GetId() != "commands" {
+		t.Errorf("/ = %+v, want the default commands", triggers["/"])
+	}
+	if triggers["#"].Opens("") {
+		t.Errorf("# opens, want it turned off")
+	}
+	if at := triggers["@"].Providers; len(at) != 1 || at[0] != providers["files"] {
+		t.Errorf("@ = %+v, want only the built-in files provider", at)
+	}
+	dollar := triggers["$"]
+	if len(dollar.Providers) != 2 || dollar.Providers[0].GetId() != "tags" || !dollar.Spaces {
+		t.Errorf("$ = %+v, want the custom provider then commands", dollar)
+	}
+}
+
+func TestCustomProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("runs sh")
+	}
+	provider := NewCustomContextGroup("tags", `printf 'v1.0\tfirst\nv2.0-%s\n\n' "$QUERY"`, t.TempDir())
+	items, err := provider.GetChildEntries("rc")
+	if err != nil {
+		t.Fatalf("GetChildEntries() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(items))
+	}
+	if items[0].Value != "v1.0" || items[0].Description != "first" || items[0].ProviderID != "tags" {
+		t.Errorf("items[0] = %+v, want v1.0 described as first", items[0])
+	}
+	if items[1].Value != "v2.0-rc" {
+		t.Errorf("items[1].Value = %q, want the query passed in $QUERY", items[1].Value)
+	}
+
+	if _, err := NewCustomContextGroup("fails", "exit 1", "").GetChildEntries(""); err == nil {
+		t.Errorf("GetChildEntries() error = nil, want the command's failure")
+	}
+}
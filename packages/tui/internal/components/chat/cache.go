@@ -1,22 +1,41 @@
 package chat
 
 import (
+	"container/list"
 	"encoding/hex"
 	"fmt"
 	"hash/fnv"
 	"sync"
 )
 
+// maxCachedParts caps the rendered parts kept, the least recently used are
+// evicted and re-rendered when scrolled back to
+const maxCachedParts = 2000
+
+type cacheEntry struct {
+	key     string
+	content string
+}
+
 // PartCache caches rendered messages to avoid re-rendering
 type PartCache struct {
-	mu    sync.RWMutex
-	cache map[string]string
+	mu    sync.Mutex
+	cache map[string]*list.Element
+	// order lists the entries from most to least recently used
+	order *list.List
+	max   int
 }
 
 // NewPartCache creates a new message cache
 func NewPartCache() *PartCache {
+	return newPartCache(maxCachedParts)
+}
+
+func newPartCache(size int) *PartCache {
 	return &PartCache{
-		cache: make(map[string]string),
+		cache: make(map[string]*list.Element),
+		order: list.New(),
+		max:   size,
 	}
 }
 
@@ -31,18 +50,33 @@ func (c *PartCache) GenerateKey(params ...any) string {
 
 // Get retrieves a cached rendered message
 func (c *PartCache) Get(key string) (string, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	content, exists := c.cache[key]
-	return content, exists
+	element, exists := c.cache[key]
+	if !exists {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).content, true
 }
 
 // Set stores a rendered message in the cache
 func (c *PartCache) Set(key string, content string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.cache[key] = content
+
+	if element, exists := c.cache[key]; exists {
+		element.Value.(*cacheEntry).content = content
+		c.order.MoveToFront(element)
+		return
+	}
+	c.cache[key] = c.order.PushFront(&cacheEntry{key: key, content: content})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.cache, oldest.Value.(*cacheEntry).key)
+	}
 }
 
 // Clear removes all entries from the cache
@@ -50,13 +84,14 @@ func (c *PartCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.cache = make(map[string]string)
+	c.cache = make(map[string]*list.Element)
+	c.order.Init()
 }
 
 // Size returns the number of cached entries
 func (c *PartCache) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	return len(c.cache)
 }
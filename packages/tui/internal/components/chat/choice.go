@@ -0,0 +1,196 @@
+package chat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// Choice is one option of a ChoiceMessage
+type Choice struct {
+	// Key picks the choice directly, the choice's number does too
+	Key         string `json:"key,omitempty"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+}
+
+// ChoiceMessage represents a question with several answers in the chat
+type ChoiceMessage struct {
+	ID       string
+	Question string
+	Choices  []Choice
+	Selected int
+	Answered bool
+	// Answer is the index of the chosen option, -1 when dismissed
+	Answer int
+}
+
+// ChoiceMsg is sent when a question with several answers is needed
+type ChoiceMsg struct {
+	ID       string
+	Question string
+	Choices  []Choice
+	// Default is the index selected at first
+	Default int
+}
+
+// ChoiceAnswerMsg is sent when the user picks an option or dismisses the
+// question, Index is -1 then
+type ChoiceAnswerMsg struct {
+	ID     string
+	Index  int
+	Choice Choice
+}
+
+// NewChoiceMessage creates a new choice message
+func NewChoiceMessage(id, question string, choices []Choice, defaultIndex int) *ChoiceMessage {
+	if defaultIndex < 0 || defaultIndex >= len(choices) {
+		defaultIndex = 0
+	}
+	return &ChoiceMessage{
+		ID:       id,
+		Question: question,
+		Choices:  choices,
+		Selected: defaultIndex,
+		Answer:   -1,
+	}
+}
+
+func (c *ChoiceMessage) answer(index int) tea.Cmd {
+	c.Answered = true
+	c.Answer = index
+	answer := ChoiceAnswerMsg{ID: c.ID, Index: index}
+	if index >= 0 {
+		answer.Choice = c.Choices[index]
+	}
+	return func() tea.Msg {
+		return answer
+	}
+}
+
+// ChoiceZone is the clickable zone of the option at index
+func ChoiceZone(index int) string {
+	return "choice:" + strconv.Itoa(index)
+}
+
+// Click picks the clicked option
+func (c *ChoiceMessage) Click(zone string) (*ChoiceMessage, tea.Cmd) {
+	if c.Answered {
+		return c, nil
+	}
+	for i := range c.Choices {
+		if zone == ChoiceZone(i) {
+			c.Selected = i
+			return c, c.answer(i)
+		}
+	}
+	return c, nil
+}
+
+// Update handles input for the choice
+func (c *ChoiceMessage) Update(msg tea.Msg) (*ChoiceMessage, tea.Cmd) {
+	if c.Answered || len(c.Choices) == 0 {
+		return c, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "shift+tab"))):
+			c.Selected = (c.Selected + len(c.Choices) - 1) % len(c.Choices)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "tab"))):
+			c.Selected = (c.Selected + 1) % len(c.Choices)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			return c, c.answer(c.Selected)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			return c, c.answer(-1)
+		default:
+			pressed := msg.String()
+			for i, choice := range c.Choices {
+				if (choice.Key != "" && strings.EqualFold(choice.Key, pressed)) ||
+					(i < 9 && pressed == strconv.Itoa(i+1)) {
+					c.Selected = i
+					return c, c.answer(i)
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+// View renders the choice message
+func (c *ChoiceMessage) View(width int) string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.NewStyle().Foreground(t.Text())
+
+	// Question
+	questionStyle := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Padding(1, 2)
+	question := questionStyle.Render(c.Question)
+
+	if c.Answered {
+		// Show the answer
+		answerText := "Dismissed"
+		if c.Answer >= 0 {
+			answerText = c.Choices[c.Answer].Label
+		}
+		answerStyle := baseStyle.
+			Foreground(t.TextMuted()).
+			Padding(0, 2, 1, 2)
+		answer := answerStyle.Render(fmt.Sprintf("Answer: %s", answerText))
+		return lipgloss.JoinVertical(lipgloss.Left, question, answer)
+	}
+
+	// Options, one per line with their key
+	var options []string
+	for i, choice := range c.Choices {
+		shortcut := strconv.Itoa(i + 1)
+		if choice.Key != "" {
+			shortcut = choice.Key
+		}
+		optionStyle := baseStyle
+		marker := "  "
+		if i == c.Selected {
+			optionStyle = optionStyle.
+				Foreground(t.Primary()).
+				Bold(true)
+			marker = "> "
+		}
+		option := optionStyle.Render(fmt.Sprintf("%s[%s] %s", marker, shortcut, choice.Label))
+		if choice.Description != "" {
+			option += baseStyle.Foreground(t.TextMuted()).Render("  " + choice.Description)
+		}
+		options = append(options, layout.Mark(ChoiceZone(i), option))
+	}
+	optionsContainer := baseStyle.Padding(0, 2, 1, 2).Render(strings.Join(options, "\n"))
+
+	// Help text
+	helpStyle := baseStyle.Foreground(t.TextMuted()).Italic(true)
+	help := helpStyle.Padding(0, 2).Render("Use ↑/↓ or Tab to select, Enter to confirm, a key to pick directly, Esc to dismiss")
+
+	// Combine all parts
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		question,
+		optionsContainer,
+		help,
+	)
+
+	// Add a border around the whole thing
+	borderStyle := baseStyle.
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderActive()).
+		Width(width-4).
+		Margin(1, 2)
+
+	return borderStyle.Render(content)
+}
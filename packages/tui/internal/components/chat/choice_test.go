@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+var testChoices = []Choice{
+	{Key: "y", Label: "Yes"},
+	{Key: "n", Label: "No"},
+	{Label: "Later", Description: "ask again next time"},
+}
+
+func answerOf(t *testing.T, cmd tea.Cmd) ChoiceAnswerMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected an answer command")
+	}
+	answer, ok := cmd().(ChoiceAnswerMsg)
+	if !ok {
+		t.Fatal("expected a ChoiceAnswerMsg")
+	}
+	return answer
+}
+
+func TestChoiceDefaultIndex(t *testing.T) {
+	c := NewChoiceMessage("q", "Continue?", testChoices, 2)
+	if c.Selected != 2 {
+		t.Errorf("expected the default to be selected, got %d", c.Selected)
+	}
+
+	c = NewChoiceMessage("q", "Continue?", testChoices, 7)
+	if c.Selected != 0 {
+		t.Errorf("expected an out of range default to select the first choice, got %d", c.Selected)
+	}
+
+	_, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if answer := answerOf(t, cmd); answer.Index != 0 || answer.Choice.Label != "Yes" {
+		t.Errorf("expected enter to pick the selected choice, got %+v", answer)
+	}
+}
+
+func TestChoiceNavigation(t *testing.T) {
+	c := NewChoiceMessage("q", "Continue?", testChoices, 0)
+	c, _ = c.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if c.Selected != 2 {
+		t.Errorf("expected up to wrap to the last choice, got %d", c.Selected)
+	}
+	c, _ = c.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if c.Selected != 0 {
+		t.Errorf("expected down to wrap to the first choice, got %d", c.Selected)
+	}
+}
+
+func TestChoiceKey(t *testing.T) {
+	c := NewChoiceMessage("q", "Continue?", testChoices, 0)
+	c, cmd := c.Update(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	answer := answerOf(t, cmd)
+	if answer.ID != "q" || answer.Index != 1 || answer.Choice.Label != "No" {
+		t.Errorf("expected n to pick No, got %+v", answer)
+	}
+	if !c.Answered || c.Answer != 1 {
+		t.Error("expected the message to be answered")
+	}
+
+	// answered messages ignore further input
+	if _, cmd := c.Update(tea.KeyPressMsg{Code: 'y', Text: "y"}); cmd != nil {
+		t.Error("expected no answer after the message was answered")
+	}
+}
+
+func TestChoiceNumber(t *testing.T) {
+	c := NewChoiceMessage("q", "Continue?", testChoices, 0)
+	_, cmd := c.Update(tea.KeyPressMsg{Code: '3', Text: "3"})
+	if answer := answerOf(t, cmd); answer.Index != 2 || answer.Choice.Label != "Later" {
+		t.Errorf("expected 3 to pick the third choice, got %+v", answer)
+	}
+
+	c = NewChoiceMessage("q", "Continue?", testChoices, 0)
+	if _, cmd := c.Update(tea.KeyPressMsg{Code: '4', Text: "4"}); cmd != nil {
+		t.Error("expected a number past the choices to do nothing")
+	}
+}
+
+func TestChoiceDismiss(t *testing.T) {
+	c := NewChoiceMessage("q", "Continue?", testChoices, 1)
+	c, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	answer := answerOf(t, cmd)
+	if answer.Index != -1 || answer.Choice != (Choice{}) {
+		t.Errorf("expected esc to dismiss, got %+v", answer)
+	}
+	if !c.Answered || c.Answer != -1 {
+		t.Error("expected the message to be dismissed")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/sst/opencode/internal/layout"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 )
@@ -31,6 +32,12 @@ type ConfirmationAnswerMsg struct {
 	Answer bool
 }
 
+// Clickable zones of the confirmation buttons
+const (
+	ZoneYes = "confirm:yes"
+	ZoneNo  = "confirm:no"
+)
+
 // NewConfirmationMessage creates a new confirmation message
 func NewConfirmationMessage(id, question string) *ConfirmationMessage {
 	return &ConfirmationMessage{
@@ -79,6 +86,17 @@ func (c *ConfirmationMessage) Update(msg tea.Msg) (*ConfirmationMessage, tea.Cmd
 	return c, nil
 }
 
+// Click answers with the clicked button
+func (c *ConfirmationMessage) Click(zone string) (*ConfirmationMessage, tea.Cmd) {
+	switch zone {
+	case ZoneYes:
+		return c.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	case ZoneNo:
+		return c.Update(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	}
+	return c, nil
+}
+
 // View renders the confirmation message
 func (c *ConfirmationMessage) View(width int) string {
 	t := theme.CurrentTheme()
@@ -124,8 +142,8 @@ func (c *ConfirmationMessage) View(width int) string {
 			Foreground(t.Primary())
 	}
 
-	yes := yesStyle.Padding(0, 3).Render("Yes")
-	no := noStyle.Padding(0, 3).Render("No")
+	yes := layout.Mark(ZoneYes, yesStyle.Padding(0, 3).Render("Yes"))
+	no := layout.Mark(ZoneNo, noStyle.Padding(0, 3).Render("No"))
 
 	buttons := lipgloss.JoinHorizontal(lipgloss.Left, yes, baseStyle.Render("  "), no)
 	buttonsContainer := baseStyle.Padding(0, 2, 1, 2).Render(buttons)
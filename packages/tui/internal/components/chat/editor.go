@@ -1,6 +1,7 @@
 package chat
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"log/slog"
@@ -9,11 +10,14 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/v2/spinner"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/google/uuid"
 	opencode "github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
@@ -23,6 +27,8 @@ import (
 	"github.com/sst/opencode/internal/components/dialog"
 	"github.com/sst/opencode/internal/components/textarea"
 	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/git"
+	"github.com/sst/opencode/internal/i18n"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
@@ -30,6 +36,39 @@ import (
 
 type AttachmentInsertedMsg struct{}
 
+// AudioPastedMsg is sent when the path of an audio file is pasted or
+// dropped, asking whether to attach or transcribe it
+type AudioPastedMsg struct {
+	Path      string
+	MediaType string
+}
+
+// PatchPastedMsg is sent when the pasted text is a unified diff, asking
+// what to do with it
+type PatchPastedMsg struct {
+	Text string
+}
+
+// htmlPastedMsg carries pasted text and the Markdown of the HTML copy the
+// system clipboard holds of it, empty when it holds none
+type htmlPastedMsg struct {
+	raw      string
+	text     string
+	markdown string
+}
+
+// clipboardHasHTML reports whether the system clipboard may hold the HTML of
+// pasted text. Over SSH the text comes from the local terminal while the
+// clipboard read is the remote machine's.
+func clipboardHasHTML() bool {
+	for _, name := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if os.Getenv(name) != "" {
+			return false
+		}
+	}
+	return true
+}
+
 // unescapeClipboardText trims surrounding quotes from clipboard text and returns the inner content.
 // It avoids interpreting backslash escape sequences unless the text is explicitly quoted.
 func (m *editorComponent) unescapeClipboardText(s string) string {
@@ -38,6 +77,11 @@ func (m *editorComponent) unescapeClipboardText(s string) string {
 		first := t[0]
 		last := t[len(t)-1]
 		if (first == '"' && last == '"') || (first == '\'' && last == '\'') {
+			// Explorer's "Copy as path" quotes Windows paths, their
+			// backslashes are separators
+			if util.IsWindowsPath(t[1 : len(t)-1]) {
+				return t[1 : len(t)-1]
+			}
 			if u, err := strconv.Unquote(t); err == nil {
 				return u
 			}
@@ -76,6 +120,7 @@ type EditorComponent interface {
 	tea.Model
 	tea.ViewModel
 	Content() string
+	Thumbnails() string
 	Lines() int
 	Value() string
 	Length() int
@@ -88,9 +133,18 @@ type EditorComponent interface {
 	Newline() (tea.Model, tea.Cmd)
 	SetValue(value string)
 	SetValueWithAttachments(value string)
+	AttachText(name string, text string)
+	InsertText(text string)
+	AttachFile(filePath string)
+	AttachFileData(filePath string)
+	AttachSymbol(symbol opencode.Symbol)
+	AttachmentAtCursor() *attachment.Attachment
 	SetInterruptKeyInDebounce(inDebounce bool)
 	SetExitKeyInDebounce(inDebounce bool)
 	RestoreFromHistory(index int)
+	ShellMode() bool
+	ToggleShellMode()
+	RestoreFromPrompt(prompt app.Prompt)
 	SetFocusState(hasFocus bool, focusSupported bool)
 }
 
@@ -101,10 +155,17 @@ type editorComponent struct {
 	spinner                spinner.Model
 	interruptKeyInDebounce bool
 	exitKeyInDebounce      bool
-	historyIndex           int    // -1 means current (not in history)
-	currentText            string // Store current text when navigating history
-	pasteCounter           int
-	reverted               bool
+	historyIndex           int // -1 means current (not in history)
+	// lines run in the session's shell instead of being sent, with their own
+	// history
+	shellMode    bool
+	currentText  string // Store current text when navigating history
+	pasteCounter int
+	reverted     bool
+	// rendered image previews by attachment ID
+	thumbnails map[string]string
+	// the model last resolved for a @model:<name> override
+	override modelOverrideCache
 	// Focus state for multi-instance drag-and-drop filtering
 	hasFocus       bool
 	focusSupported bool
@@ -113,7 +174,23 @@ type editorComponent struct {
 func (m *editorComponent) Init() tea.Cmd {
 	// Focus the textarea
 	focusCmd := m.textarea.Focus()
-	return tea.Batch(focusCmd, m.spinner.Tick, tea.EnableReportFocus)
+	return tea.Batch(focusCmd, m.spinnerTick(), tea.EnableReportFocus)
+}
+
+// spinnerTick starts the spinner, unless it is shown still
+func (m *editorComponent) spinnerTick() tea.Cmd {
+	if styles.ReduceMotion {
+		return nil
+	}
+	return m.spinner.Tick
+}
+
+// spinnerView is the spinner, or an ellipsis where it is shown still
+func (m *editorComponent) spinnerView() string {
+	if styles.ReduceMotion {
+		return m.spinner.Style.Render("...")
+	}
+	return m.spinner.View()
 }
 
 func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -133,14 +210,14 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "up", "ctrl+p":
 			// Only navigate history if cursor is at the first line and column (for arrow keys)
 			// or allow ctrl+p from anywhere
-			if (msg.String() == "ctrl+p" || (m.textarea.Line() == 0 && m.textarea.CursorColumn() == 0)) && len(m.app.State.MessageHistory) > 0 {
+			if (msg.String() == "ctrl+p" || (m.textarea.Line() == 0 && m.textarea.CursorColumn() == 0)) && m.historyLength() > 0 {
 				if m.historyIndex == -1 {
 					// Save current text before entering history
 					m.currentText = m.textarea.Value()
 					m.textarea.MoveToBegin()
 				}
 				// Move up in history (older messages)
-				if m.historyIndex < len(m.app.State.MessageHistory)-1 {
+				if m.historyIndex < m.historyLength()-1 {
 					m.historyIndex++
 					m.RestoreFromHistory(m.historyIndex)
 					m.textarea.MoveToBegin()
@@ -214,118 +291,32 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		textRaw := string(msg)
 		text := m.unescapeClipboardText(textRaw)
 
-		// Case 1: pasted content contains one or more inline @paths -> insert attachments inline
-		// We scan the raw pasted text to preserve original content around attachments.
-		if strings.Contains(textRaw, "@") {
-			last := 0
-			idx := 0
-			inserted := 0
-			for idx < len(textRaw) {
-				r, size := utf8.DecodeRuneInString(textRaw[idx:])
-				if r != '@' {
-					idx += size
-					continue
-				}
-
-				// Insert preceding chunk before attempting to consume a path
-				if idx > last {
-					m.textarea.InsertRunesFromUserInput([]rune(textRaw[last:idx]))
-				}
-
-				// Extract candidate path after '@' up to whitespace
-				start := idx + size
-				end := start
-				for end < len(textRaw) {
-					nr, ns := utf8.DecodeRuneInString(textRaw[end:])
-					if nr == ' ' || nr == '\t' || nr == '\n' || nr == '\r' {
-						break
-					}
-					end += ns
-				}
-
-				if end > start {
-					raw := textRaw[start:end]
-					// Trim common trailing punctuation that may follow paths in prose
-					trimmed := strings.TrimRight(raw, ",.;:)]}\\\"'?!")
-					suffix := raw[len(trimmed):]
-					p := filepath.Clean(trimmed)
-					if m.pathExists(p) {
-						att := m.createAttachmentFromPath(p)
-						if att != nil {
-							m.textarea.InsertAttachment(att)
-							if suffix != "" {
-								m.textarea.InsertRunesFromUserInput([]rune(suffix))
-							}
-							// Insert a trailing space only if the next rune isn't already whitespace
-							insertSpace := true
-							if end < len(textRaw) {
-								nr, _ := utf8.DecodeRuneInString(textRaw[end:])
-								if nr == ' ' || nr == '\t' || nr == '\n' || nr == '\r' {
-									insertSpace = false
-								}
-							}
-							if insertSpace {
-								m.textarea.InsertString(" ")
-							}
-							inserted++
-							last = end
-							idx = end
-							continue
-						}
-					}
-				}
-
-				// No valid path -> keep the '@' literally
-				m.textarea.InsertRune('@')
-				last = start
-				idx = start
-			}
-			// Insert any trailing content after the last processed segment
-			if last < len(textRaw) {
-				m.textarea.InsertRunesFromUserInput([]rune(textRaw[last:]))
-			}
-			if inserted > 0 {
-				return m, util.CmdHandler(AttachmentInsertedMsg{})
-			}
+		// Case 0: a diff, its hunk headers would be taken for @paths
+		if git.IsPatch(text) {
+			return m, util.CmdHandler(PatchPastedMsg{Text: text})
 		}
 
-		// Case 2: user typed '@' and then pasted a valid path -> replace '@' with attachment
-		at := m.textarea.LastRuneIndex('@')
-		if at != -1 && at == m.textarea.CursorColumn()-1 {
-			p := filepath.Clean(text)
-			if m.pathExists(p) {
-				cur := m.textarea.CursorColumn()
-				m.textarea.ReplaceRange(at, cur, "")
-				att := m.createAttachmentFromPath(p)
-				if att != nil {
-					m.textarea.InsertAttachment(att)
-					m.textarea.InsertString(" ")
-					return m, util.CmdHandler(AttachmentInsertedMsg{})
-				}
+		// Case 0b: copied from a page or a document, paste its formatting.
+		// The clipboard is read off the UI goroutine, the text is pasted as
+		// it is when it holds no such copy.
+		if clipboardHasHTML() && strings.TrimSpace(text) != "" {
+			return m, func() tea.Msg {
+				markdown, _ := clipboardMarkdown(text)
+				return htmlPastedMsg{raw: textRaw, text: text, markdown: markdown}
 			}
 		}
 
-		// Case 3: plain path pasted (e.g., drag-and-drop) -> attach if image or PDF
-		{
-			p := filepath.Clean(text)
-			if m.pathExists(p) {
-				mime := getMediaTypeFromExtension(strings.ToLower(filepath.Ext(p)))
-				if strings.HasPrefix(mime, "image/") || mime == "application/pdf" {
-					if att := m.createAttachmentFromFile(p); att != nil {
-						m.textarea.InsertAttachment(att)
-						m.textarea.InsertString(" ")
-						return m, util.CmdHandler(AttachmentInsertedMsg{})
-					}
-				}
-			}
+		return m, m.pasteText(textRaw, text)
+	case htmlPastedMsg:
+		if msg.markdown == "" {
+			return m, m.pasteText(msg.raw, msg.text)
 		}
-
-		// Case 4: fallback to regular text handling
-		if m.shouldSummarizePastedText(text) {
-			m.handleLongPaste(text)
+		if m.shouldSummarizePastedText(msg.markdown) {
+			m.handleLongPaste(msg.markdown)
 		} else {
-			m.textarea.InsertRunesFromUserInput([]rune(text))
+			m.textarea.InsertRunesFromUserInput([]rune(msg.markdown))
 		}
+		return m, nil
 	case tea.ClipboardMsg:
 		// Filter clipboard events based on focus state for multi-instance drag-and-drop
 		if m.focusSupported && !m.hasFocus {
@@ -343,8 +334,20 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dialog.ThemeSelectedMsg:
 		m.textarea = updateTextareaStyles(m.textarea)
 		m.spinner = createSpinner()
-		return m, tea.Batch(m.textarea.Focus(), m.spinner.Tick)
+		return m, tea.Batch(m.textarea.Focus(), m.spinnerTick())
+	case resourceReadMsg:
+		m.AttachText(msg.name, msg.text)
+		return m, nil
+	case dialog.CompletionDescendMsg:
+		atIndex := m.textarea.LastRuneIndex(msg.Trigger)
+		if atIndex == -1 {
+			return m, nil
+		}
+		m.textarea.ReplaceRange(atIndex+1, m.textarea.CursorColumn(), msg.Value)
+		return m, nil
 	case dialog.CompletionSelectedMsg:
+		// the character that opened the completion, triggers are configurable
+		trigger, _ := utf8.DecodeRuneInString(msg.SearchString)
 		switch msg.Item.ProviderID {
 		case "commands":
 			commandName := strings.TrimPrefix(msg.Item.Value, "/")
@@ -354,7 +357,7 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, util.CmdHandler(commands.ExecuteCommandMsg(m.app.Commands[commands.CommandName(commandName)])))
 			return m, tea.Batch(cmds...)
 		case "files":
-			atIndex := m.textarea.LastRuneIndex('@')
+			atIndex := m.textarea.LastRuneIndex(trigger)
 			if atIndex == -1 {
 				// Should not happen, but as a fallback, just insert.
 				m.textarea.InsertString(msg.Item.Value + " ")
@@ -372,9 +375,9 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			attachment := m.createAttachmentFromPath(filePath)
 			m.textarea.InsertAttachment(attachment)
 			m.textarea.InsertString(" ")
-			return m, nil
+			return m, m.app.TouchFile(filePath)
 		case "symbols":
-			atIndex := m.textarea.LastRuneIndex('@')
+			atIndex := m.textarea.LastRuneIndex(trigger)
 			if atIndex == -1 {
 				// Should not happen, but as a fallback, just insert.
 				m.textarea.InsertString(msg.Item.Value + " ")
@@ -384,37 +387,43 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cursorCol := m.textarea.CursorColumn()
 			m.textarea.ReplaceRange(atIndex, cursorCol, "")
 
-			symbol := msg.Item.RawData.(opencode.Symbol)
-			parts := strings.Split(symbol.Name, ".")
-			lastPart := parts[len(parts)-1]
-			attachment := &attachment.Attachment{
-				ID:        uuid.NewString(),
-				Type:      "symbol",
-				Display:   "@" + lastPart,
-				URL:       msg.Item.Value,
-				Filename:  lastPart,
-				MediaType: "text/plain",
-				Source: &attachment.SymbolSource{
-					Path: symbol.Location.Uri,
-					Name: symbol.Name,
-					Kind: int(symbol.Kind),
-					Range: attachment.SymbolRange{
-						Start: attachment.Position{
-							Line: int(symbol.Location.Range.Start.Line),
-							Char: int(symbol.Location.Range.Start.Character),
-						},
-						End: attachment.Position{
-							Line: int(symbol.Location.Range.End.Line),
-							Char: int(symbol.Location.Range.End.Character),
-						},
-					},
-				},
+			m.AttachSymbol(msg.Item.RawData.(opencode.Symbol))
+			return m, nil
+		case "resources":
+			triggerIndex := m.textarea.LastRuneIndex(trigger)
+			if triggerIndex != -1 {
+				m.textarea.ReplaceRange(triggerIndex, m.textarea.CursorColumn(), "")
 			}
-			m.textarea.InsertAttachment(attachment)
-			m.textarea.InsertString(" ")
+			return m, m.readResource(msg.Item.RawData.(opencode.McpResource))
+		case "shell":
+			m.textarea.SetValue("!" + msg.Item.Value)
+			return m, nil
+		case "git":
+			atIndex := m.textarea.LastRuneIndex(trigger)
+			if atIndex != -1 {
+				m.textarea.ReplaceRange(atIndex, m.textarea.CursorColumn(), "")
+			}
+
+			ref := msg.Item.RawData.(git.Ref)
+			patch, err := ref.Patch(m.app.Info.Path.Cwd)
+			if err != nil {
+				return m, toast.NewErrorToast(err.Error())
+			}
+			if strings.TrimSpace(patch) == "" {
+				return m, toast.NewInfoToast("No changes to attach")
+			}
+			m.AttachText(ref.Label(), patch)
 			return m, nil
 		default:
-			slog.Debug("Unknown provider", "provider", msg.Item.ProviderID)
+			// custom providers put the suggestion in place of the trigger and
+			// the text typed after it
+			cursorCol := m.textarea.CursorColumn()
+			start := cursorCol - len([]rune(msg.SearchString))
+			if start < 0 {
+				m.textarea.InsertString(msg.Item.Value)
+				return m, nil
+			}
+			m.textarea.ReplaceRange(start, cursorCol, msg.Item.Value)
 			return m, nil
 		}
 	}
@@ -437,10 +446,14 @@ func (m *editorComponent) Content() string {
 	t := theme.CurrentTheme()
 	base := styles.NewStyle().Foreground(t.Text()).Background(t.Background()).Render
 	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
-	promptStyle := styles.NewStyle().Foreground(t.Primary()).
+	promptColor, promptText := t.Primary(), ">"
+	if m.shellMode {
+		promptColor, promptText = t.Warning(), "$"
+	}
+	promptStyle := styles.NewStyle().Foreground(promptColor).
 		Padding(0, 0, 0, 1).
 		Bold(true)
-	prompt := promptStyle.Render(">")
+	prompt := promptStyle.Render(promptText)
 
 	m.textarea.SetWidth(width - 6)
 	textarea := lipgloss.JoinHorizontal(
@@ -449,6 +462,9 @@ func (m *editorComponent) Content() string {
 		m.textarea.View(),
 	)
 	borderForeground := t.Border()
+	if m.shellMode {
+		borderForeground = t.Warning()
+	}
 	if m.app.IsLeaderSequence {
 		borderForeground = t.Accent()
 	}
@@ -464,31 +480,28 @@ func (m *editorComponent) Content() string {
 		BorderRight(true).
 		Render(textarea)
 
-	hint := base(m.getSubmitKeyText()) + muted(" send   ") + muted("!cmd") + muted(" shell")
+	hint := base(m.getSubmitKeyText()) + muted(i18n.T(" send   ")) + muted("!cmd") + muted(i18n.T(" shell"))
+	if m.shellMode {
+		hint = base(m.getSubmitKeyText()) + muted(i18n.T(" run   ")) + base("exit") + muted(i18n.T(" leave shell mode"))
+	}
 	if m.exitKeyInDebounce {
 		keyText := m.getExitKeyText()
-		hint = base(keyText+" again") + muted(" to exit")
+		hint = base(i18n.T("%s again", keyText)) + muted(i18n.T(" to exit"))
 	} else if m.app.IsBusy() {
 		keyText := m.getInterruptKeyText()
 		if m.interruptKeyInDebounce {
-			hint = muted(
-				"working",
-			) + m.spinner.View() + muted(
-				"  ",
-			) + base(
-				keyText+" again",
-			) + muted(
-				" interrupt",
-			)
-		} else {
-			hint = muted("working") + m.spinner.View() + muted("  ") + base(keyText) + muted(" interrupt")
+			keyText = i18n.T("%s again", keyText)
 		}
+		hint = muted(i18n.T("working")) + m.spinnerView() + m.progress() + muted("  ") + base(keyText) + muted(i18n.T(" interrupt"))
 	}
 
 	model := ""
 	if m.app.Model != nil {
 		model = muted(m.app.Provider.Name) + base(" "+m.app.Model.Name)
 	}
+	if override, ok := m.modelOverride(); ok {
+		model = override + muted(" (this prompt)")
+	}
 
 	space := width - 2 - lipgloss.Width(model) - lipgloss.Width(hint)
 	spacer := styles.NewStyle().Background(t.Background()).Width(space).Render("")
@@ -496,10 +509,150 @@ func (m *editorComponent) Content() string {
 	info := hint + spacer + model
 	info = styles.NewStyle().Background(t.Background()).Padding(0, 1).Render(info)
 
-	content := strings.Join([]string{"", textarea, info}, "\n")
+	content := strings.Join([]string{m.attachmentCosts(width), textarea, info}, "\n")
 	return content
 }
 
+// progress renders how long the agent has been working and roughly how fast
+// it is generating, so a slow response can be told apart from a hang
+func (m *editorComponent) progress() string {
+	progress, ok := m.app.ResponseProgress(time.Now())
+	if !ok {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
+	text := " " + progress.Elapsed.Truncate(time.Second).String()
+	if progress.Rate > 0 {
+		text += fmt.Sprintf(" · %.0f tok/s", progress.Rate)
+	}
+	return muted(text)
+}
+
+type modelOverrideCache struct {
+	query     string
+	providers int
+	provider  *opencode.Provider
+	model     *opencode.Model
+}
+
+// modelOverride returns the model named by a leading @model:<name> in the
+// editor, rendered for the info line. The model is only looked up again when
+// the name or the providers change.
+func (m *editorComponent) modelOverride() (string, bool) {
+	query, _, ok := app.Prompt{Text: m.textarea.Value()}.ModelOverride()
+	if !ok {
+		return "", false
+	}
+	if query != m.override.query || len(m.app.Providers) != m.override.providers {
+		provider, model := m.app.MatchModel(query)
+		m.override = modelOverrideCache{query, len(m.app.Providers), provider, model}
+	}
+	provider, model := m.override.provider, m.override.model
+	if model == nil || (m.app.Model != nil && model.ID == m.app.Model.ID) {
+		return "", false
+	}
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.Background()).Render
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
+	return muted(provider.Name) + base(" "+model.Name), true
+}
+
+const (
+	thumbnailWidth  = 16
+	thumbnailHeight = 6
+)
+
+// Thumbnails renders a small preview of each image attachment, or an empty
+// string when there are none
+func (m *editorComponent) Thumbnails() string {
+	t := theme.CurrentTheme()
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement())
+
+	attachments := m.textarea.GetAttachments()
+	if len(attachments) == 0 {
+		clear(m.thumbnails)
+		return ""
+	}
+
+	var previews []string
+	for _, att := range attachments {
+		fs, ok := att.GetFileSource()
+		if !ok || !strings.HasPrefix(fs.Mime, "image/") || len(fs.Data) == 0 {
+			continue
+		}
+		thumbnail, ok := m.thumbnails[att.ID]
+		if !ok {
+			thumbnail, _ = util.RenderThumbnail(fs.Data, thumbnailWidth, thumbnailHeight)
+			m.thumbnails[att.ID] = thumbnail
+		}
+		if thumbnail == "" {
+			continue
+		}
+		label := muted.Render(ansi.Truncate(att.Display, thumbnailWidth, "…"))
+		previews = append(previews, lipgloss.JoinVertical(lipgloss.Left, thumbnail, label))
+	}
+	if len(previews) == 0 {
+		return ""
+	}
+
+	gap := muted.Render("  ")
+	strip := previews[0]
+	for _, preview := range previews[1:] {
+		strip = lipgloss.JoinHorizontal(lipgloss.Bottom, strip, gap, preview)
+	}
+
+	width := m.width
+	if m.app.Session.ID == "" {
+		width = min(width, 80)
+	}
+	return styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Width(width).
+		Padding(1, 2, 0, 2).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderForeground(t.Border()).
+		BorderBackground(t.Background()).
+		BorderLeft(true).
+		BorderRight(true).
+		Render(strip)
+}
+
+// attachmentCosts renders the estimated token cost of each attachment and
+// their total, or an empty line when there are no attachments
+func (m *editorComponent) attachmentCosts(width int) string {
+	attachments := m.textarea.GetAttachments()
+	if len(attachments) == 0 {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.Background()).Render
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
+
+	providerID := ""
+	if m.app.Provider != nil {
+		providerID = m.app.Provider.ID
+	}
+
+	total := 0
+	var chips []string
+	for _, att := range attachments {
+		tokens := attachment.EstimateTokens(att, providerID)
+		total += tokens
+		chips = append(chips, base(att.Display)+muted(" ~"+util.FormatTokens(float64(tokens))))
+	}
+	costs := muted("total ~") + base(util.FormatTokens(float64(total))) + muted(" tokens")
+	if len(attachments) > 1 {
+		costs = strings.Join(chips, muted("  ")) + muted("  ·  ") + costs
+	} else {
+		costs = chips[0] + muted(" tokens")
+	}
+
+	costs = ansi.Truncate(costs, width-2, "…")
+	return styles.NewStyle().Background(t.Background()).Padding(0, 1).Render(costs)
+}
+
 func (m *editorComponent) View() string {
 	width := m.width
 	if m.app.Session.ID == "" {
@@ -549,16 +702,33 @@ func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.shellMode {
+		return m.runShell(value)
+	}
+
 	switch value {
 	case "exit", "quit", "q", ":q":
 		return m, tea.Quit
 	}
 
+	// Run a custom command typed with its arguments, e.g. /fix the login test
+	if strings.HasPrefix(value, "/") {
+		trigger, arguments, _ := strings.Cut(value[1:], " ")
+		if command, ok := m.app.Commands.ByTrigger(trigger); ok && command.Custom != nil {
+			command.Arguments = strings.TrimSpace(arguments)
+			updated, cmd := m.Clear()
+			m = updated.(*editorComponent)
+			return m, tea.Batch(cmd, util.CmdHandler(commands.ExecuteCommandMsg(command)))
+		}
+	}
+
 	// Check for !shell command
 	if strings.HasPrefix(value, "!") && len(value) > 1 {
 		command := strings.TrimSpace(value[1:]) // Remove the ! prefix
 		if command != "" {
 			var cmds []tea.Cmd
+			m.app.State.AddShellCommandToHistory(command)
+			cmds = append(cmds, m.app.SaveState())
 
 			// Clear the editor
 			updated, cmd := m.Clear()
@@ -586,6 +756,10 @@ func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
 	attachments := m.textarea.GetAttachments()
 
 	prompt := app.Prompt{Text: value, Attachments: attachments}
+	// keep the text in the editor so a mistyped model override can be fixed
+	if _, _, _, err := m.app.PromptModel(prompt); err != nil {
+		return m, toast.NewErrorToast(err.Error())
+	}
 	m.app.State.AddPromptToHistory(prompt)
 	cmds = append(cmds, m.app.SaveState())
 
@@ -597,6 +771,37 @@ func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// runShell runs the line in the session's shell, exit leaving shell mode
+func (m *editorComponent) runShell(command string) (tea.Model, tea.Cmd) {
+	updated, cmd := m.Clear()
+	m = updated.(*editorComponent)
+	if command == "exit" {
+		m.shellMode = false
+		return m, cmd
+	}
+	m.app.State.AddShellCommandToHistory(command)
+	return m, tea.Batch(
+		cmd,
+		m.app.SaveState(),
+		util.CmdHandler(app.ExecuteShellCommand{
+			SessionID:  m.app.Session.ID,
+			Command:    command,
+			Persistent: true,
+		}),
+	)
+}
+
+func (m *editorComponent) ShellMode() bool {
+	return m.shellMode
+}
+
+// ToggleShellMode switches between sending prompts and running shell commands
+func (m *editorComponent) ToggleShellMode() {
+	m.shellMode = !m.shellMode
+	m.historyIndex = -1
+	m.currentText = ""
+}
+
 func (m *editorComponent) Clear() (tea.Model, tea.Cmd) {
 	m.textarea.Reset()
 	m.historyIndex = -1
@@ -610,12 +815,17 @@ func (m *editorComponent) Paste() (tea.Model, tea.Cmd) {
 	if imageBytes != nil {
 		attachmentCount := len(m.textarea.GetAttachments())
 		attachmentIndex := attachmentCount + 1
+		display := fmt.Sprintf("[Image #%d]", attachmentIndex)
+		if downscaled := attachment.DownscaleImage(imageBytes, "image/png", m.imageLimits()); downscaled != nil {
+			imageBytes = downscaled.Data
+			display = fmt.Sprintf("[Image #%d %s]", attachmentIndex, downscaled.Label())
+		}
 		base64EncodedFile := base64.StdEncoding.EncodeToString(imageBytes)
 		attachment := &attachment.Attachment{
 			ID:        uuid.NewString(),
 			Type:      "file",
 			MediaType: "image/png",
-			Display:   fmt.Sprintf("[Image #%d]", attachmentIndex),
+			Display:   display,
 			Filename:  fmt.Sprintf("image-%d.png", attachmentIndex),
 			URL:       fmt.Sprintf("data:image/png;base64,%s", base64EncodedFile),
 			Source: &attachment.FileSource{
@@ -632,6 +842,9 @@ func (m *editorComponent) Paste() (tea.Model, tea.Cmd) {
 	textBytes := clipboard.Read(clipboard.FmtText)
 	if textBytes != nil {
 		text := string(textBytes)
+		if markdown, ok := clipboardMarkdown(text); ok {
+			text = markdown
+		}
 		// Check if the pasted text is long and should be summarized
 		if m.shouldSummarizePastedText(text) {
 			m.handleLongPaste(text)
@@ -713,6 +926,154 @@ func (m *editorComponent) getExitKeyText() string {
 	return m.app.Commands[commands.AppExitCommand].Keys()[0]
 }
 
+// pasteText inserts pasted text, attaching the files of the paths in it
+func (m *editorComponent) pasteText(textRaw, text string) tea.Cmd {
+	// Case 1: pasted content contains one or more inline @paths -> insert attachments inline
+	// We scan the raw pasted text to preserve original content around attachments.
+	if strings.Contains(textRaw, "@") {
+		last := 0
+		idx := 0
+		inserted := 0
+		for idx < len(textRaw) {
+			r, size := utf8.DecodeRuneInString(textRaw[idx:])
+			if r != '@' {
+				idx += size
+				continue
+			}
+
+			// Insert preceding chunk before attempting to consume a path
+			if idx > last {
+				m.textarea.InsertRunesFromUserInput([]rune(textRaw[last:idx]))
+			}
+
+			// Extract candidate path after '@' up to whitespace
+			start := idx + size
+			end := start
+			for end < len(textRaw) {
+				nr, ns := utf8.DecodeRuneInString(textRaw[end:])
+				if nr == ' ' || nr == '\t' || nr == '\n' || nr == '\r' {
+					break
+				}
+				end += ns
+			}
+
+			if end > start {
+				raw := textRaw[start:end]
+				// Trim common trailing punctuation that may follow paths in prose
+				trimmed := strings.TrimRight(raw, ",.;:)]}\\\"'?!")
+				suffix := raw[len(trimmed):]
+				p := util.NormalizePastedPath(trimmed)
+				if m.pathExists(p) {
+					att := m.createAttachmentFromPath(p)
+					if att != nil {
+						m.textarea.InsertAttachment(att)
+						if suffix != "" {
+							m.textarea.InsertRunesFromUserInput([]rune(suffix))
+						}
+						// Insert a trailing space only if the next rune isn't already whitespace
+						insertSpace := true
+						if end < len(textRaw) {
+							nr, _ := utf8.DecodeRuneInString(textRaw[end:])
+							if nr == ' ' || nr == '\t' || nr == '\n' || nr == '\r' {
+								insertSpace = false
+							}
+						}
+						if insertSpace {
+							m.textarea.InsertString(" ")
+						}
+						inserted++
+						last = end
+						idx = end
+						continue
+					}
+				}
+			}
+
+			// No valid path -> keep the '@' literally
+			m.textarea.InsertRune('@')
+			last = start
+			idx = start
+		}
+		// Insert any trailing content after the last processed segment
+		if last < len(textRaw) {
+			m.textarea.InsertRunesFromUserInput([]rune(textRaw[last:]))
+		}
+		if inserted > 0 {
+			return util.CmdHandler(AttachmentInsertedMsg{})
+		}
+	}
+
+	// Case 2: user typed '@' and then pasted a valid path -> replace '@' with attachment
+	at := m.textarea.LastRuneIndex('@')
+	if at != -1 && at == m.textarea.CursorColumn()-1 {
+		p := util.NormalizePastedPath(text)
+		if m.pathExists(p) {
+			cur := m.textarea.CursorColumn()
+			m.textarea.ReplaceRange(at, cur, "")
+			att := m.createAttachmentFromPath(p)
+			if att != nil {
+				m.textarea.InsertAttachment(att)
+				m.textarea.InsertString(" ")
+				return util.CmdHandler(AttachmentInsertedMsg{})
+			}
+		}
+	}
+
+	// Case 3: plain path pasted (e.g., drag-and-drop) -> attach if image or PDF
+	{
+		p := util.NormalizePastedPath(text)
+		if m.pathExists(p) {
+			mime := getMediaTypeFromExtension(strings.ToLower(filepath.Ext(p)))
+			if strings.HasPrefix(mime, "audio/") {
+				return util.CmdHandler(AudioPastedMsg{Path: p, MediaType: mime})
+			}
+			if strings.HasPrefix(mime, "image/") || mime == "application/pdf" {
+				if att := m.createAttachmentFromFile(p); att != nil {
+					m.textarea.InsertAttachment(att)
+					m.textarea.InsertString(" ")
+					return util.CmdHandler(AttachmentInsertedMsg{})
+				}
+			}
+		}
+	}
+
+	// Case 4: fallback to regular text handling
+	if m.shouldSummarizePastedText(text) {
+		m.handleLongPaste(text)
+	} else {
+		m.textarea.InsertRunesFromUserInput([]rune(text))
+	}
+	return nil
+}
+
+// clipboardMarkdown converts the HTML of the clipboard to Markdown, when
+// the pasted text is the same copy, from a page or a document
+func clipboardMarkdown(text string) (string, bool) {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return "", false
+	}
+	html := clipboard.Read(clipboard.FmtHTML)
+	if html == nil {
+		return "", false
+	}
+	markdown, ok := util.HTMLToMarkdown(string(html))
+	if !ok {
+		return "", false
+	}
+	// the clipboard may hold something copied since, unless the first and
+	// last words of the pasted text are in it
+	for _, word := range []string{words[0], words[len(words)-1]} {
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if !strings.Contains(markdown, word) {
+			return "", false
+		}
+	}
+	return markdown, true
+}
+
 // shouldSummarizePastedText determines if pasted text should be summarized
 func (m *editorComponent) shouldSummarizePastedText(text string) bool {
 	lines := strings.Split(text, "\n")
@@ -755,6 +1116,118 @@ func (m *editorComponent) handleLongPaste(text string) {
 	m.textarea.InsertString(" ")
 }
 
+// AttachSymbol inserts the symbol as an attachment at the cursor
+func (m *editorComponent) AttachSymbol(symbol opencode.Symbol) {
+	parts := strings.Split(symbol.Name, ".")
+	lastPart := parts[len(parts)-1]
+	start := int(symbol.Location.Range.Start.Line)
+	end := int(symbol.Location.Range.End.Line)
+	m.textarea.InsertAttachment(&attachment.Attachment{
+		ID:        uuid.NewString(),
+		Type:      "symbol",
+		Display:   "@" + lastPart,
+		URL:       fmt.Sprintf("%s?start=%d&end=%d", symbol.Location.Uri, start, end),
+		Filename:  lastPart,
+		MediaType: "text/plain",
+		Source: &attachment.SymbolSource{
+			Path: symbol.Location.Uri,
+			Name: symbol.Name,
+			Kind: int(symbol.Kind),
+			Range: attachment.SymbolRange{
+				Start: attachment.Position{
+					Line: start,
+					Char: int(symbol.Location.Range.Start.Character),
+				},
+				End: attachment.Position{
+					Line: end,
+					Char: int(symbol.Location.Range.End.Character),
+				},
+			},
+		},
+	})
+	m.textarea.InsertString(" ")
+}
+
+// AttachmentAtCursor returns the attachment the cursor is on, nil when there
+// is none
+func (m *editorComponent) AttachmentAtCursor() *attachment.Attachment {
+	return m.textarea.AttachmentAtCursor()
+}
+
+// AttachText inserts the text as a plain text attachment at the cursor
+func (m *editorComponent) AttachText(name string, text string) {
+	lineCount := len(strings.Split(text, "\n"))
+	base64EncodedText := base64.StdEncoding.EncodeToString([]byte(text))
+
+	attachment := &attachment.Attachment{
+		ID:        uuid.NewString(),
+		Type:      "text",
+		MediaType: "text/plain",
+		Display:   fmt.Sprintf("[%s %d lines]", name, lineCount),
+		URL:       fmt.Sprintf("data:text/plain;base64,%s", base64EncodedText),
+		Filename:  name + ".txt",
+		Source: &attachment.TextSource{
+			Value: text,
+		},
+	}
+
+	if m.textarea.Length() > 0 && !strings.HasSuffix(m.textarea.Value(), " ") {
+		m.textarea.InsertString(" ")
+	}
+	m.textarea.InsertAttachment(attachment)
+	m.textarea.InsertString(" ")
+}
+
+// InsertText inserts the text at the cursor as typed, however long it is
+func (m *editorComponent) InsertText(text string) {
+	m.textarea.InsertRunesFromUserInput([]rune(text))
+}
+
+// resourceReadMsg carries the text of an MCP resource to attach
+type resourceReadMsg struct {
+	name string
+	text string
+}
+
+// readResource reads the text of an MCP resource for attaching it
+func (m *editorComponent) readResource(resource opencode.McpResource) tea.Cmd {
+	return func() tea.Msg {
+		text, err := m.app.Client.Mcp.Read(context.Background(), opencode.McpReadParams{
+			Server: opencode.F(resource.Server),
+			Uri:    opencode.F(resource.Uri),
+		})
+		if err != nil {
+			slog.Error("Failed to read MCP resource", "error", err)
+			return toast.NewErrorToast("Failed to read " + resource.Name)()
+		}
+		return resourceReadMsg{name: resource.Name, text: *text}
+	}
+}
+
+// AttachFileData inserts the file as an attachment at the cursor, with its
+// content read now rather than when the prompt is sent
+func (m *editorComponent) AttachFileData(filePath string) {
+	att := m.createAttachmentFromFile(filePath)
+	if att == nil {
+		return
+	}
+	if m.textarea.Length() > 0 && !strings.HasSuffix(m.textarea.Value(), " ") {
+		m.textarea.InsertString(" ")
+	}
+	m.textarea.InsertAttachment(att)
+	m.textarea.InsertString(" ")
+}
+
+// AttachFile inserts the file, relative to the working directory, as an
+// attachment at the cursor
+func (m *editorComponent) AttachFile(filePath string) {
+	if m.textarea.Length() > 0 && !strings.HasSuffix(m.textarea.Value(), " ") {
+		m.textarea.InsertString(" ")
+	}
+	m.textarea.InsertAttachment(m.createAttachmentFromPath(filePath))
+	m.textarea.InsertString(" ")
+}
+
 func updateTextareaStyles(ta textarea.Model) textarea.Model {
 	t := theme.CurrentTheme()
 	bgColor := t.BackgroundElement()
@@ -814,6 +1287,7 @@ func NewEditorComponent(app *app.App) EditorComponent {
 		app:                    app,
 		textarea:               ta,
 		spinner:                s,
+		thumbnails:             make(map[string]string),
 		interruptKeyInDebounce: false,
 		historyIndex:           -1,
 		pasteCounter:           0,
@@ -840,15 +1314,31 @@ func (m *editorComponent) RestoreFromPrompt(prompt app.Prompt) {
 	}
 
 	for _, att := range attachmentsCopy {
-		m.textarea.SetCursorColumn(att.StartIndex)
-		m.textarea.ReplaceRange(att.StartIndex, att.EndIndex, "")
+		// the indices are byte offsets in the text, which may span rows
+		m.textarea.SetCursorOffset(att.StartIndex)
+		col := m.textarea.CursorColumn()
+		m.textarea.ReplaceRange(col, col+utf8.RuneCountInString(att.Display), "")
 		m.textarea.InsertAttachment(att)
 	}
 }
 
-// RestoreFromHistory restores a message from history at the given index
+// historyLength returns the length of the history of the editor's mode
+func (m *editorComponent) historyLength() int {
+	if m.shellMode {
+		return len(m.app.State.ShellHistory)
+	}
+	return len(m.app.State.MessageHistory)
+}
+
+// RestoreFromHistory restores a message, or a shell command in shell mode,
+// from history at the given index
 func (m *editorComponent) RestoreFromHistory(index int) {
-	if index < 0 || index >= len(m.app.State.MessageHistory) {
+	if index < 0 || index >= m.historyLength() {
+		return
+	}
+	if m.shellMode {
+		m.textarea.Reset()
+		m.textarea.SetValue(m.app.State.ShellHistory[index])
 		return
 	}
 	entry := m.app.State.MessageHistory[index]
@@ -865,6 +1355,12 @@ func getMediaTypeFromExtension(ext string) string {
 		return "image/svg+xml"
 	case ".pdf":
 		return "application/pdf"
+	case ".wav":
+		return "audio/wav"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".m4a":
+		return "audio/mp4"
 	default:
 		return "text/plain"
 	}
@@ -901,21 +1397,31 @@ func (m *editorComponent) createAttachmentFromFile(filePath string) *attachment.
 		return nil
 	}
 
-	base64EncodedFile := base64.StdEncoding.EncodeToString(fileBytes)
-	url := fmt.Sprintf("data:%s;base64,%s", mediaType, base64EncodedFile)
 	attachmentCount := len(m.textarea.GetAttachments())
 	attachmentIndex := attachmentCount + 1
 	label := "File"
 	if strings.HasPrefix(mediaType, "image/") {
 		label = "Image"
+	} else if strings.HasPrefix(mediaType, "audio/") {
+		label = "Audio"
 	}
+	display := fmt.Sprintf("[%s #%d]", label, attachmentIndex)
+	filename := filePath
+	if downscaled := attachment.DownscaleImage(fileBytes, mediaType, m.imageLimits()); downscaled != nil {
+		fileBytes = downscaled.Data
+		mediaType = downscaled.MediaType
+		filename = downscaled.Filename(filePath)
+		display = fmt.Sprintf("[%s #%d %s]", label, attachmentIndex, downscaled.Label())
+	}
+	base64EncodedFile := base64.StdEncoding.EncodeToString(fileBytes)
+	url := fmt.Sprintf("data:%s;base64,%s", mediaType, base64EncodedFile)
 	return &attachment.Attachment{
 		ID:        uuid.NewString(),
 		Type:      "file",
 		MediaType: mediaType,
-		Display:   fmt.Sprintf("[%s #%d]", label, attachmentIndex),
+		Display:   display,
 		URL:       url,
-		Filename:  filePath,
+		Filename:  filename,
 		Source: &attachment.FileSource{
 			Path: absolutePath,
 			Mime: mediaType,
@@ -924,23 +1430,51 @@ func (m *editorComponent) createAttachmentFromFile(filePath string) *attachment.
 	}
 }
 
+// imageLimits are the limits above which attached images are scaled down
+func (m *editorComponent) imageLimits() attachment.ImageLimits {
+	if m.app.Config == nil {
+		return attachment.ImageLimits{}
+	}
+	images := m.app.Config.Tui.Images
+	return attachment.ImageLimits{
+		MaxDimension: int(images.MaxDimension),
+		MaxBytes:     int(images.MaxBytes),
+	}
+}
+
 func (m *editorComponent) createAttachmentFromPath(filePath string) *attachment.Attachment {
 	extension := filepath.Ext(filePath)
 	mediaType := getMediaTypeFromExtension(extension)
 	absolutePath := filePath
 	if !filepath.IsAbs(filePath) {
 		absolutePath = filepath.Join(m.app.Info.Path.Cwd, filePath)
+	} else if rel, err := filepath.Rel(m.app.Info.Path.Cwd, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+		filePath = rel
+	}
+	fileURL := fmt.Sprintf("file://./%s", url.PathEscape(filepath.ToSlash(filePath)))
+	var data []byte
+	if filepath.IsAbs(filePath) {
+		// outside the project, like a Windows drive under WSL, the server
+		// only reads files of the project so the file is sent inline
+		var err error
+		data, err = os.ReadFile(filePath)
+		if err != nil {
+			slog.Error("Failed to read file", "error", err)
+			return nil
+		}
+		fileURL = fmt.Sprintf("data:%s;base64,%s", mediaType, base64.StdEncoding.EncodeToString(data))
 	}
 	return &attachment.Attachment{
 		ID:        uuid.NewString(),
 		Type:      "file",
 		Display:   "@" + filePath,
-		URL:       fmt.Sprintf("file://./%s", url.PathEscape(filePath)),
+		URL:       fileURL,
 		Filename:  filePath,
 		MediaType: mediaType,
 		Source: &attachment.FileSource{
 			Path: absolutePath,
 			Mime: mediaType,
+			Data: data,
 		},
 	}
 }
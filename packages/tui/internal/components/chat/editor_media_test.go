@@ -0,0 +1,22 @@
+package chat
+
+import "testing"
+
+func TestGetMediaTypeFromExtension(t *testing.T) {
+	tests := map[string]string{
+		".png":  "image/png",
+		".JPG":  "image/jpeg",
+		".pdf":  "application/pdf",
+		".wav":  "audio/wav",
+		".mp3":  "audio/mpeg",
+		".M4A":  "audio/mp4",
+		".go":   "text/plain",
+		"":      "text/plain",
+		".webp": "image/webp",
+	}
+	for ext, want := range tests {
+		if got := getMediaTypeFromExtension(ext); got != want {
+			t.Errorf("getMediaTypeFromExtension(%q) = %q, want %q", ext, got, want)
+		}
+	}
+}
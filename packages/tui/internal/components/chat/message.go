@@ -191,6 +191,22 @@ func renderContentBlock(
 	return content
 }
 
+// attribution names the model and agent that produced an assistant message,
+// or returns an empty string when attribution is hidden
+func attribution(app *app.App, message opencode.AssistantMessage) string {
+	if app.State.HideAttribution {
+		return ""
+	}
+	model := message.ModelID
+	if message.ProviderID != "" {
+		model = message.ProviderID + "/" + model
+	}
+	if message.Mode == "" {
+		return model
+	}
+	return model + " · " + message.Mode
+}
+
 func renderText(
 	app *app.App,
 	message opencode.MessageUnion,
@@ -213,6 +229,7 @@ func renderText(
 	case opencode.UserMessage:
 		ts = time.UnixMilli(int64(casted.Time.Created))
 		base := styles.NewStyle().Foreground(t.Text()).Background(backgroundColor)
+		text = theme.Marker(theme.MarkerUser) + text
 		text = ansi.WordwrapWc(text, width-6, " -")
 		lines := strings.Split(text, "\n")
 		for i, line := range lines {
@@ -238,6 +255,9 @@ func renderText(
 		timestamp = timestamp[12:]
 	}
 	info := fmt.Sprintf("%s (%s)", author, timestamp)
+	if author == "" {
+		info = timestamp
+	}
 	info = styles.NewStyle().Foreground(t.TextMuted()).Render(info)
 
 	if !showToolDetails && toolCalls != nil && len(toolCalls) > 0 {
@@ -247,6 +267,7 @@ func renderText(
 			style := styles.NewStyle()
 			if toolCall.State.Status == opencode.ToolPartStateStatusError {
 				style = style.Foreground(t.Error())
+				title = theme.Marker(theme.MarkerError) + title
 			}
 			title = style.Render(title)
 			title = "∟ " + title + "\n"
@@ -409,7 +430,7 @@ func renderToolDetails(
 						streamingIndicator = indicator
 					} else {
 						// Enhanced streaming indicators with animation
-						streamingIndicator = "⚡ Running..."
+						streamingIndicator = styles.IconRunning.String() + " Running..."
 					}
 
 					// Add progress information with better formatting
@@ -491,7 +512,7 @@ func renderToolDetails(
 			// Build command header with status indicator
 			commandHeader := fmt.Sprintf("$ %s", command)
 			if isRunning {
-				commandHeader += " ⚡ Running..."
+				commandHeader += " " + styles.IconRunning.String() + " Running..."
 			} else if toolCall.State.Status == opencode.ToolPartStateStatusCompleted {
 				if metadata != nil {
 					if exitCode, ok := metadata["exitCode"].(float64); ok {
@@ -598,8 +619,12 @@ func renderToolDetails(
 
 	if error != "" {
 		// Enhanced error formatting with better visual hierarchy
-		errorIcon := "❌ "
+		errorIcon := styles.IconError.String() + " "
 		errorTitle := "Error"
+		if theme.CurrentThemeUsesMarkers() {
+			errorIcon = ""
+			errorTitle = theme.MarkerError
+		}
 
 		// Format error with icon and title
 		formattedError := fmt.Sprintf("%s%s\n%s", errorIcon, errorTitle, error)
@@ -682,6 +707,14 @@ func getTodoTitle(toolCall opencode.ToolPart) string {
 func renderToolTitle(
 	toolCall opencode.ToolPart,
 	width int,
+) string {
+	marker := theme.Marker(theme.MarkerTool)
+	return marker + toolTitle(toolCall, width-len(marker))
+}
+
+func toolTitle(
+	toolCall opencode.ToolPart,
+	width int,
 ) string {
 	if toolCall.State.Status == opencode.ToolPartStateStatusPending {
 		title := renderToolAction(toolCall.Tool)
@@ -920,9 +953,9 @@ func renderThinkingBlock(
 	// Enhanced thinking header with better visual indicators
 	var header string
 	if expanded {
-		header = "🧠 Thinking ▼"
+		header = styles.IconThinking.String() + " Thinking ▼"
 	} else {
-		header = "🧠 Thinking ▶"
+		header = styles.IconThinking.String() + " Thinking ▶"
 	}
 
 	// Add content preview when collapsed
@@ -29,20 +29,25 @@ type MessagesComponent interface {
 	PageDown() (tea.Model, tea.Cmd)
 	HalfPageUp() (tea.Model, tea.Cmd)
 	HalfPageDown() (tea.Model, tea.Cmd)
+	LineUp() (tea.Model, tea.Cmd)
+	LineDown() (tea.Model, tea.Cmd)
+	Selecting() bool
 	ToolDetailsVisible() bool
 	GotoTop() (tea.Model, tea.Cmd)
 	GotoBottom() (tea.Model, tea.Cmd)
 	CopyLastMessage() (tea.Model, tea.Cmd)
 	UndoLastMessage() (tea.Model, tea.Cmd)
 	RedoLastMessage() (tea.Model, tea.Cmd)
+	SetWidth(width int) tea.Cmd
 }
 
 type messagesComponent struct {
-	width, height   int
-	app             *app.App
-	header          string
-	viewport        viewport.Model
-	clipboard       []string
+	width, height int
+	app           *app.App
+	header        string
+	viewport      viewport.Model
+	// lines are the rendered messages without styles, to copy selections from
+	lines           []string
 	cache           *PartCache
 	loading         bool
 	showToolDetails bool
@@ -52,46 +57,28 @@ type messagesComponent struct {
 	partCount       int
 	lineCount       int
 	selection       *selection
+	// edits are the lines of the edit and write tool calls, which can be
+	// reverted one at a time while selecting
+	edits []editLines
+	// dragging is set while the mouse button that started the selection is held
+	dragging       bool
+	loadingEarlier bool
+	// anchor keeps the lines from the bottom of the view in place when
+	// earlier messages are prepended, 0 when unset
+	anchor int
 }
 
-type selection struct {
-	startX int
-	endX   int
-	startY int
-	endY   int
-}
-
-func (s selection) coords(offset int) *selection {
-	// selecting backwards
-	if s.startY > s.endY && s.endY >= 0 {
-		return &selection{
-			startX: max(0, s.endX-1),
-			startY: s.endY - offset,
-			endX:   s.startX + 1,
-			endY:   s.startY - offset,
-		}
-	}
-
-	// selecting backwards same line
-	if s.startY == s.endY && s.startX >= s.endX {
-		return &selection{
-			startY: s.startY - offset,
-			startX: max(0, s.endX-1),
-			endY:   s.endY - offset,
-			endX:   s.startX + 1,
-		}
-	}
-
-	return &selection{
-		startX: s.startX,
-		startY: s.startY - offset,
-		endX:   s.endX,
-		endY:   s.endY - offset,
-	}
+// editLines are the lines an edit or write tool call is rendered on
+type editLines struct {
+	start, end int
+	part       opencode.ToolPart
 }
 
 type ToggleToolDetailsMsg struct{}
 
+// ToggleAttributionMsg shows or hides the model and agent of assistant messages
+type ToggleAttributionMsg struct{}
+
 func (m *messagesComponent) Init() tea.Cmd {
 	return tea.Batch(m.viewport.Init())
 }
@@ -102,42 +89,46 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
 	case tea.MouseClickMsg:
-		slog.Info("mouse", "x", msg.X, "y", msg.Y, "offset", m.viewport.YOffset)
-		y := msg.Y + m.viewport.YOffset
-		if y > 0 {
-			m.selection = &selection{
-				startY: y,
-				startX: msg.X,
-				endY:   -1,
-				endX:   -1,
-			}
-
-			slog.Info("mouse selection", "start", fmt.Sprintf("%d,%d", m.selection.startX, m.selection.startY), "end", fmt.Sprintf("%d,%d", m.selection.endX, m.selection.endY))
-			return m, m.renderView()
+		if msg.Button != tea.MouseLeft {
+			break
+		}
+		m.selection = nil
+		if pos, ok := m.position(msg.X, msg.Y); ok {
+			m.selection = &selection{anchor: pos, head: pos}
+			m.dragging = true
 		}
+		return m, nil
 
 	case tea.MouseMotionMsg:
-		if m.selection != nil {
-			m.selection = &selection{
-				startX: m.selection.startX,
-				startY: m.selection.startY,
-				endX:   msg.X + 1,
-				endY:   msg.Y + m.viewport.YOffset,
+		if m.dragging {
+			// keep selecting past the edges by scrolling
+			top := lipgloss.Height(m.header)
+			if msg.Y < top {
+				m.viewport.LineUp(1)
+			} else if msg.Y >= top+m.viewport.Height() {
+				m.viewport.LineDown(1)
 			}
-			return m, m.renderView()
+			y := min(max(msg.Y, top), top+m.viewport.Height()-1)
+			x := min(max(msg.X, 0), m.width-1)
+			if pos, ok := m.position(x, y); ok {
+				m.selection.head = pos
+			}
+			return m, nil
 		}
 
 	case tea.MouseReleaseMsg:
-		if m.selection != nil && len(m.clipboard) > 0 {
-			content := strings.Join(m.clipboard, "\n")
-			m.selection = nil
-			m.clipboard = []string{}
-			return m, tea.Sequence(
-				m.renderView(),
-				app.SetClipboard(content),
-				toast.NewSuccessToast("Copied to clipboard"),
-			)
+		if m.dragging {
+			m.dragging = false
+			if m.selection.empty() {
+				m.selection = nil
+				return m, nil
+			}
+			return m, m.copySelection()
 		}
+
+	case tea.KeyPressMsg:
+		return m, m.selectWithKeys(msg)
+
 	case tea.WindowSizeMsg:
 		effectiveWidth := msg.Width - 4
 		// Clear cache on resize since width affects rendering
@@ -160,6 +151,10 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ToggleToolDetailsMsg:
 		m.showToolDetails = !m.showToolDetails
 		return m, m.renderView()
+	case ToggleAttributionMsg:
+		m.app.State.HideAttribution = !m.app.State.HideAttribution
+		m.cache.Clear()
+		return m, tea.Batch(m.renderView(), m.app.SaveState())
 	case app.SessionLoadedMsg, app.SessionClearedMsg:
 		m.cache.Clear()
 		m.tail = true
@@ -177,6 +172,25 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.tail = true
 			return m, m.renderView()
 		}
+	case app.EarlierMessagesLoadedMsg:
+		m.loadingEarlier = false
+		if msg.Err != nil && msg.SessionID == m.app.Session.ID {
+			return m, toast.NewErrorToast("Failed to load earlier messages")
+		}
+		if m.app.RestoreEarlierMessages(msg) {
+			m.anchor = m.viewport.TotalLineCount() - m.viewport.YOffset
+			return m, m.renderView()
+		}
+		return m, nil
+	case app.SessionResyncedMsg:
+		if msg.Err != nil {
+			return m, toast.NewErrorToast("Failed to resync the session after reconnecting")
+		}
+		if m.app.ApplyResync(msg) {
+			m.cache.Clear()
+			return m, m.renderView()
+		}
+		return m, nil
 
 	case opencode.EventListResponseEventSessionUpdated:
 		if msg.Properties.Info.ID == m.app.Session.ID {
@@ -199,9 +213,10 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.partCount = msg.partCount
 		m.lineCount = msg.lineCount
 		m.rendering = false
-		m.clipboard = msg.clipboard
+		m.lines = msg.lines
+		m.edits = msg.edits
 		m.loading = false
-		m.tail = m.viewport.AtBottom()
+		m.tail = !m.app.State.ScrollLock
 		m.viewport = msg.viewport
 		m.header = msg.header
 		if m.dirty {
@@ -209,17 +224,37 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	m.tail = m.viewport.AtBottom()
+	// follow new output unless scrolling is locked
+	m.tail = !m.app.State.ScrollLock
 	viewport, cmd := m.viewport.Update(msg)
 	m.viewport = viewport
-	cmds = append(cmds, cmd)
+	cmds = append(cmds, cmd, m.loadEarlier(), m.evictEarlier())
 
 	return m, tea.Batch(cmds...)
 }
 
+// loadEarlier reloads evicted messages once the view is scrolled to the top
+func (m *messagesComponent) loadEarlier() tea.Cmd {
+	if m.loading || m.loadingEarlier || m.app.Evicted.Count == 0 || !m.viewport.AtTop() {
+		return nil
+	}
+	m.loadingEarlier = true
+	return m.app.LoadEarlierMessages()
+}
+
+// evictEarlier reapplies the cap on loaded messages once the view is back at
+// the bottom, after earlier ones were reloaded
+func (m *messagesComponent) evictEarlier() tea.Cmd {
+	if m.loading || m.loadingEarlier || !m.viewport.AtBottom() || !m.app.EvictMessages() {
+		return nil
+	}
+	return m.renderView()
+}
+
 type renderCompleteMsg struct {
 	viewport  viewport.Model
-	clipboard []string
+	lines     []string
+	edits     []editLines
 	header    string
 	partCount int
 	lineCount int
@@ -238,6 +273,9 @@ func (m *messagesComponent) renderView() tea.Cmd {
 
 	viewport := m.viewport
 	tail := m.tail
+	anchor := m.anchor
+	m.anchor = 0
+	evicted := m.app.Evicted.Count
 
 	return func() tea.Msg {
 		header := m.renderHeader()
@@ -246,6 +284,8 @@ func (m *messagesComponent) renderView() tea.Cmd {
 
 		t := theme.CurrentTheme()
 		blocks := make([]string, 0)
+		// edit and write tool calls by the block they are rendered in
+		editBlocks := map[int]opencode.ToolPart{}
 		partCount := 0
 		lineCount := 0
 
@@ -253,6 +293,21 @@ func (m *messagesComponent) renderView() tea.Cmd {
 
 		width := m.width // always use full width
 
+		if evicted > 0 {
+			plural := ""
+			if evicted != 1 {
+				plural = "s"
+			}
+			content := styles.NewStyle().
+				Background(t.Background()).
+				Foreground(t.TextMuted()).
+				Width(width).
+				Align(lipgloss.Center).
+				Render(fmt.Sprintf("%d earlier message%s, scroll up to load", evicted, plural))
+			blocks = append(blocks, content)
+			lineCount += lipgloss.Height(content) + 1
+		}
+
 		reverted := false
 		revertedMessageCount := 0
 		revertedToolCount := 0
@@ -435,7 +490,7 @@ func (m *messagesComponent) renderView() tea.Cmd {
 									m.app,
 									message.Info,
 									part.Text,
-									casted.ModelID,
+									attribution(m.app, casted),
 									m.showToolDetails,
 									width,
 									"",
@@ -454,7 +509,7 @@ func (m *messagesComponent) renderView() tea.Cmd {
 								m.app,
 								message.Info,
 								part.Text,
-								casted.ModelID,
+								attribution(m.app, casted),
 								m.showToolDetails,
 								width,
 								"",
@@ -520,6 +575,9 @@ func (m *messagesComponent) renderView() tea.Cmd {
 							)
 						}
 						if content != "" {
+							if part.Tool == "edit" || part.Tool == "write" {
+								editBlocks[len(blocks)] = part
+							}
 							partCount++
 							lineCount += lipgloss.Height(content) + 1
 							blocks = append(blocks, content)
@@ -601,60 +659,30 @@ func (m *messagesComponent) renderView() tea.Cmd {
 		}
 
 		final := []string{}
-		clipboard := []string{}
-		var selection *selection
-		if m.selection != nil {
-			selection = m.selection.coords(lipgloss.Height(header) + 1)
-		}
-		for _, block := range blocks {
-			lines := strings.Split(block, "\n")
-			for index, line := range lines {
-				if selection == nil || index == 0 || index == len(lines)-1 {
-					final = append(final, line)
-					continue
-				}
-				y := len(final)
-				if y >= selection.startY && y <= selection.endY {
-					left := 3
-					if y == selection.startY {
-						left = selection.startX - 2
-					}
-					left = max(3, left)
-
-					width := ansi.StringWidth(line)
-					right := width - 1
-					if y == selection.endY {
-						right = min(selection.endX-2, right)
-					}
-
-					prefix := ansi.Cut(line, 0, left)
-					middle := strings.TrimRight(ansi.Strip(ansi.Cut(line, left, right)), " ")
-					suffix := ansi.Cut(line, left+ansi.StringWidth(middle), width)
-					clipboard = append(clipboard, middle)
-					line = prefix + styles.NewStyle().
-						Background(t.Accent()).
-						Foreground(t.BackgroundPanel()).
-						Render(ansi.Strip(middle)) +
-						suffix
-				}
-				final = append(final, line)
-			}
-			y := len(final)
-			if selection != nil && y >= selection.startY && y < selection.endY {
-				clipboard = append(clipboard, "")
+		edits := []editLines{}
+		// the content starts with an empty line
+		line := 1
+		for i, block := range blocks {
+			final = append(final, block, "")
+			height := lipgloss.Height(block)
+			if part, ok := editBlocks[i]; ok {
+				edits = append(edits, editLines{start: line, end: line + height, part: part})
 			}
-			final = append(final, "")
+			line += height + 1
 		}
 		content := "\n" + strings.Join(final, "\n")
 		viewport.SetHeight(m.height - lipgloss.Height(header))
 		viewport.SetContent(content)
-		if tail {
+		if anchor > 0 {
+			viewport.SetYOffset(max(viewport.TotalLineCount()-anchor, 0))
+		} else if tail {
 			viewport.GotoBottom()
 		}
 
 		return renderCompleteMsg{
 			header:    header,
-			clipboard: clipboard,
+			lines:     strings.Split(ansi.Strip(content), "\n"),
+			edits:     edits,
 			viewport:  viewport,
 			partCount: partCount,
 			lineCount: lineCount,
@@ -674,25 +702,12 @@ func (m *messagesComponent) renderHeader() string {
 	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
 
 	sessionInfo := ""
-	tokens := float64(0)
-	cost := float64(0)
-	contextWindow := m.app.Model.Limit.Context
+	cost := m.app.Evicted.Usage.Cost
+	tokens, contextWindow := m.app.ContextUsage()
 
 	for _, message := range m.app.Messages {
 		if assistant, ok := message.Info.(opencode.AssistantMessage); ok {
 			cost += assistant.Cost
-			usage := assistant.Tokens
-			if usage.Output > 0 {
-				if assistant.Summary {
-					tokens = usage.Output
-					continue
-				}
-				tokens = (usage.Input +
-					usage.Cache.Write +
-					usage.Cache.Read +
-					usage.Output +
-					usage.Reasoning)
-			}
 		}
 	}
 
@@ -774,24 +789,7 @@ func formatTokensAndCost(
 	cost float64,
 	isSubscriptionModel bool,
 ) string {
-	// Format tokens in human-readable format (e.g., 110K, 1.2M)
-	var formattedTokens string
-	switch {
-	case tokens >= 1_000_000:
-		formattedTokens = fmt.Sprintf("%.1fM", float64(tokens)/1_000_000)
-	case tokens >= 1_000:
-		formattedTokens = fmt.Sprintf("%.1fK", float64(tokens)/1_000)
-	default:
-		formattedTokens = fmt.Sprintf("%d", int(tokens))
-	}
-
-	// Remove .0 suffix if present
-	if strings.HasSuffix(formattedTokens, ".0K") {
-		formattedTokens = strings.Replace(formattedTokens, ".0K", "K", 1)
-	}
-	if strings.HasSuffix(formattedTokens, ".0M") {
-		formattedTokens = strings.Replace(formattedTokens, ".0M", "M", 1)
-	}
+	formattedTokens := util.FormatTokens(tokens)
 
 	percentage := 0.0
 	if contextWindow > 0 {
@@ -830,30 +828,163 @@ func (m *messagesComponent) View() string {
 
 	measure := util.Measure("messages.View")
 	viewport := m.viewport.View()
+	if m.selection != nil {
+		lines := strings.Split(viewport, "\n")
+		for i, line := range lines {
+			if index := m.viewport.YOffset + i; index < len(m.lines) {
+				lines[i] = m.selection.highlight(index, line, m.lines[index])
+			}
+		}
+		viewport = strings.Join(lines, "\n")
+	}
 	measure()
 	return styles.NewStyle().
 		Background(t.Background()).
 		Render(m.header + "\n" + viewport)
 }
 
+// SetWidth resizes the messages pane, e.g. to make room for the file viewer
+func (m *messagesComponent) SetWidth(width int) tea.Cmd {
+	if m.width == width {
+		return nil
+	}
+	m.width = width
+	m.viewport.SetWidth(width)
+	return m.renderView()
+}
+
 func (m *messagesComponent) PageUp() (tea.Model, tea.Cmd) {
 	m.viewport.ViewUp()
-	return m, nil
+	return m, m.loadEarlier()
 }
 
 func (m *messagesComponent) PageDown() (tea.Model, tea.Cmd) {
 	m.viewport.ViewDown()
-	return m, nil
+	return m, m.evictEarlier()
 }
 
 func (m *messagesComponent) HalfPageUp() (tea.Model, tea.Cmd) {
 	m.viewport.HalfViewUp()
-	return m, nil
+	return m, m.loadEarlier()
 }
 
 func (m *messagesComponent) HalfPageDown() (tea.Model, tea.Cmd) {
 	m.viewport.HalfViewDown()
-	return m, nil
+	return m, m.evictEarlier()
+}
+
+// position returns the cell of the messages at x, y of the view
+func (m *messagesComponent) position(x, y int) (position, bool) {
+	top := lipgloss.Height(m.header)
+	if m.loading || x < 0 || x >= m.width || y < top || y >= top+m.viewport.Height() {
+		return position{}, false
+	}
+	line := y - top + m.viewport.YOffset
+	if line >= len(m.lines) {
+		return position{}, false
+	}
+	return position{line: line, col: x}, true
+}
+
+// Selecting reports whether lines are being selected with the keyboard
+func (m *messagesComponent) Selecting() bool {
+	return m.selection != nil && m.selection.lines
+}
+
+// selectWithKeys starts selecting lines on v, from the last one in view, then
+// moves the selection with the arrow keys and copies it on y or enter
+func (m *messagesComponent) selectWithKeys(msg tea.KeyPressMsg) tea.Cmd {
+	if !m.Selecting() {
+		if msg.String() == "v" && len(m.lines) > 0 {
+			line := min(m.viewport.YOffset+m.viewport.Height(), len(m.lines)) - 1
+			m.selection = &selection{anchor: position{line: line}, head: position{line: line}, lines: true}
+		}
+		return nil
+	}
+	head := m.selection.head.line
+	switch msg.String() {
+	case "up", "k":
+		head--
+	case "down", "j":
+		head++
+	case "pgup":
+		head -= m.viewport.Height()
+	case "pgdown":
+		head += m.viewport.Height()
+	case "y", "enter":
+		return m.copySelection()
+	case "r":
+		return m.revertEdit(head)
+	case "esc":
+		m.selection = nil
+		return nil
+	}
+	head = min(max(head, 0), len(m.lines)-1)
+	m.selection.head.line = head
+	if head < m.viewport.YOffset {
+		m.viewport.SetYOffset(head)
+	} else if bottom := m.viewport.YOffset + m.viewport.Height(); head >= bottom {
+		m.viewport.SetYOffset(head - m.viewport.Height() + 1)
+	}
+	return nil
+}
+
+// revertEdit undoes the change of the edit or write tool call rendered at
+// line, later changes to the file are kept
+func (m *messagesComponent) revertEdit(line int) tea.Cmd {
+	for _, edit := range m.edits {
+		if line < edit.start || line >= edit.end {
+			continue
+		}
+		if edit.part.State.Status != opencode.ToolPartStateStatusCompleted {
+			return toast.NewInfoToast("Only a completed change can be reverted")
+		}
+		if m.app.ReadOnly {
+			return toast.NewInfoToast(app.ReadOnlyMessage)
+		}
+		m.selection = nil
+		part := edit.part
+		return func() tea.Msg {
+			file, err := m.app.Client.Session.RevertPart(
+				context.Background(),
+				part.SessionID,
+				part.MessageID,
+				part.ID,
+			)
+			if err != nil {
+				slog.Error("Failed to revert change", "error", err)
+				return toast.NewErrorToast("Failed to revert the change: " + err.Error())()
+			}
+			return toast.NewSuccessToast(
+				"Reverted the change to "+*file,
+				toast.WithAction("Open file", dialog.FindSelectedMsg{FilePath: *file}),
+			)()
+		}
+	}
+	return toast.NewInfoToast("Select an edit or write to revert it")
+}
+
+// copySelection copies the text of the selection and clears it
+func (m *messagesComponent) copySelection() tea.Cmd {
+	text := m.selection.text(m.lines)
+	m.selection = nil
+	if text == "" {
+		return nil
+	}
+	return tea.Sequence(
+		m.app.SetClipboard(text),
+		toast.NewSuccessToast("Copied to clipboard"),
+	)
+}
+
+func (m *messagesComponent) LineUp() (tea.Model, tea.Cmd) {
+	m.viewport.LineUp(1)
+	return m, m.loadEarlier()
+}
+
+func (m *messagesComponent) LineDown() (tea.Model, tea.Cmd) {
+	m.viewport.LineDown(1)
+	return m, m.evictEarlier()
 }
 
 func (m *messagesComponent) ToolDetailsVisible() bool {
@@ -862,12 +993,12 @@ func (m *messagesComponent) ToolDetailsVisible() bool {
 
 func (m *messagesComponent) GotoTop() (tea.Model, tea.Cmd) {
 	m.viewport.GotoTop()
-	return m, nil
+	return m, m.loadEarlier()
 }
 
 func (m *messagesComponent) GotoBottom() (tea.Model, tea.Cmd) {
 	m.viewport.GotoBottom()
-	return m, nil
+	return m, m.evictEarlier()
 }
 
 func (m *messagesComponent) CopyLastMessage() (tea.Model, tea.Cmd) {
@@ -885,7 +1016,7 @@ func (m *messagesComponent) CopyLastMessage() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	var cmds []tea.Cmd
-	cmds = append(cmds, app.SetClipboard(lastTextPart.Text))
+	cmds = append(cmds, m.app.SetClipboard(lastTextPart.Text))
 	cmds = append(cmds, toast.NewSuccessToast("Message copied to clipboard"))
 	return m, tea.Batch(cmds...)
 }
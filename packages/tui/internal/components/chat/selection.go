@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// blockBorder is the border drawn on the sides of message blocks
+const blockBorder = "┃"
+
+// position is a cell of the rendered messages, by content line and column
+type position struct {
+	line, col int
+}
+
+// selection is the region of the rendered messages between where it was
+// started and where it was moved to, both ends included
+type selection struct {
+	anchor, head position
+	// lines selects whole lines, as selecting with the keyboard does
+	lines bool
+}
+
+// bounds returns the ends of the selection in reading order
+func (s selection) bounds() (position, position) {
+	if s.head.line < s.anchor.line || (s.head.line == s.anchor.line && s.head.col < s.anchor.col) {
+		return s.head, s.anchor
+	}
+	return s.anchor, s.head
+}
+
+// empty reports whether the mouse has not moved since the selection started
+func (s selection) empty() bool {
+	return !s.lines && s.anchor == s.head
+}
+
+// columns returns the selected cells of the plain line, leaving out the
+// border and padding of its message block
+func (s selection) columns(index int, line string) (int, int, bool) {
+	start, end := s.bounds()
+	if index < start.line || index > end.line {
+		return 0, 0, false
+	}
+	from, to := blockText(line)
+	if !s.lines {
+		if index == start.line {
+			from = max(from, start.col)
+		}
+		if index == end.line {
+			to = min(to, end.col+1)
+		}
+	}
+	return from, to, from < to
+}
+
+// text returns the selected text of the plain lines, unstyled and without
+// the blank padding between message blocks
+func (s selection) text(lines []string) string {
+	start, end := s.bounds()
+	var out []string
+	for i := max(start.line, 0); i <= end.line && i < len(lines); i++ {
+		text := ""
+		if from, to, ok := s.columns(i, lines[i]); ok {
+			text = strings.TrimRight(ansi.Cut(lines[i], from, to), " ")
+		}
+		if text == "" && len(out) > 0 && out[len(out)-1] == "" {
+			continue
+		}
+		out = append(out, text)
+	}
+	return strings.Trim(strings.Join(out, "\n"), "\n")
+}
+
+// highlight styles the selected cells of a rendered line, plain being the
+// same line without styles
+func (s selection) highlight(index int, line, plain string) string {
+	from, to, ok := s.columns(index, plain)
+	if !ok {
+		return line
+	}
+	t := theme.CurrentTheme()
+	selected := styles.NewStyle().
+		Background(t.Accent()).
+		Foreground(t.BackgroundPanel()).
+		Render(ansi.Strip(ansi.Cut(line, from, to)))
+	return ansi.Cut(line, 0, from) + selected + ansi.Cut(line, to, ansi.StringWidth(line))
+}
+
+// blockText returns the cells of a plain line holding text, inside the
+// border and padding of its message block
+func blockText(line string) (int, int) {
+	from, to := 0, ansi.StringWidth(line)
+	rest, ok := strings.CutPrefix(line, blockBorder)
+	if !ok {
+		return from, to
+	}
+	from = 1 + min(2, len(rest)-len(strings.TrimLeft(rest, " ")))
+	if trimmed := strings.TrimRight(line, " "); trimmed != blockBorder && strings.HasSuffix(trimmed, blockBorder) {
+		inner := strings.TrimSuffix(trimmed, blockBorder)
+		padding := len(inner) - len(strings.TrimRight(inner, " "))
+		to = ansi.StringWidth(inner) - min(2, padding)
+	}
+	return from, max(from, to)
+}
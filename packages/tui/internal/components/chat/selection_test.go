@@ -0,0 +1,58 @@
+package chat
+
+import "testing"
+
+func TestSelectionText(t *testing.T) {
+	lines := []string{
+		"",
+		"┃                 ┃",
+		"┃  hello world    ┃",
+		"┃  second line    ┃",
+		"┃                 ┃",
+		"",
+		"┃                 ┃",
+		"┃  next block     ┃",
+		"┃                 ┃",
+	}
+
+	tests := []struct {
+		name      string
+		selection selection
+		expected  string
+	}{
+		{
+			name:      "within a line",
+			selection: selection{anchor: position{2, 3}, head: position{2, 7}},
+			expected:  "hello",
+		},
+		{
+			name:      "backwards across lines",
+			selection: selection{anchor: position{3, 8}, head: position{2, 9}},
+			expected:  "world\nsecond",
+		},
+		{
+			name:      "starting on the border",
+			selection: selection{anchor: position{2, 0}, head: position{2, 4}},
+			expected:  "he",
+		},
+		{
+			name:      "whole lines across blocks",
+			selection: selection{anchor: position{7, 0}, head: position{2, 0}, lines: true},
+			expected:  "hello world\nsecond line\n\nnext block",
+		},
+	}
+	for _, test := range tests {
+		if got := test.selection.text(lines); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestSelectionEmpty(t *testing.T) {
+	if !(selection{anchor: position{1, 4}, head: position{1, 4}}).empty() {
+		t.Error("expected a click without a drag to select nothing")
+	}
+	if (selection{anchor: position{1, 4}, head: position{1, 4}, lines: true}).empty() {
+		t.Error("expected a line selection to hold its line")
+	}
+}
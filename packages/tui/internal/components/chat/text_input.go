@@ -2,6 +2,7 @@ package chat
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/textinput"
@@ -18,7 +19,9 @@ type TextInputMessage struct {
 	Placeholder string
 	Value       string
 	Submitted   bool
+	Cancelled   bool
 	input       textinput.Model
+	masked      bool
 }
 
 // TextInputMsg is sent when text input is needed
@@ -26,12 +29,16 @@ type TextInputMsg struct {
 	ID          string
 	Prompt      string
 	Placeholder string
+	// Masked hides the typed value, for secrets
+	Masked bool
 }
 
-// TextInputAnswerMsg is sent when the user submits input
+// TextInputAnswerMsg is sent when the user submits input, or cancels it with
+// Cancelled set
 type TextInputAnswerMsg struct {
-	ID    string
-	Value string
+	ID        string
+	Value     string
+	Cancelled bool
 }
 
 // NewTextInputMessage creates a new text input message
@@ -40,6 +47,7 @@ func NewTextInputMessage(id, prompt, placeholder string) *TextInputMessage {
 	ti.Placeholder = placeholder
 	ti.Focus()
 	ti.CharLimit = 500
+	ti.Styles.Cursor.Blink = !styles.ReduceMotion
 
 	return &TextInputMessage{
 		ID:          id,
@@ -50,6 +58,22 @@ func NewTextInputMessage(id, prompt, placeholder string) *TextInputMessage {
 	}
 }
 
+// IsMasked reports whether the typed value is hidden
+func (t *TextInputMessage) IsMasked() bool {
+	return t.masked
+}
+
+// SetMasked hides the typed value, for secrets like API keys
+func (t *TextInputMessage) SetMasked(masked bool) {
+	t.masked = masked
+	if masked {
+		t.input.EchoMode = textinput.EchoPassword
+		t.input.EchoCharacter = '•'
+	} else {
+		t.input.EchoMode = textinput.EchoNormal
+	}
+}
+
 // Update handles input for the text input
 func (t *TextInputMessage) Update(msg tea.Msg) (*TextInputMessage, tea.Cmd) {
 	if t.Submitted {
@@ -68,8 +92,9 @@ func (t *TextInputMessage) Update(msg tea.Msg) (*TextInputMessage, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
 			t.Value = ""
 			t.Submitted = true
+			t.Cancelled = true
 			return t, func() tea.Msg {
-				return TextInputAnswerMsg{ID: t.ID, Value: ""}
+				return TextInputAnswerMsg{ID: t.ID, Cancelled: true}
 			}
 		}
 	}
@@ -94,8 +119,12 @@ func (t *TextInputMessage) View(width int) string {
 	if t.Submitted {
 		// Show the submitted value
 		valueText := t.Value
-		if valueText == "" {
+		if t.Cancelled {
 			valueText = "(cancelled)"
+		} else if valueText == "" {
+			valueText = "(empty)"
+		} else if t.masked {
+			valueText = strings.Repeat("•", min(len(valueText), 8))
 		}
 		valueStyle := baseStyle.
 			Foreground(theme.TextMuted()).
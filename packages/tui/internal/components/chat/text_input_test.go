@@ -0,0 +1,23 @@
+package chat
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func TestTextInputCancel(t *testing.T) {
+	input := NewTextInputMessage("in", "Name?", "")
+	_, cmd := input.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	answer, ok := cmd().(TextInputAnswerMsg)
+	if !ok || !answer.Cancelled || answer.ID != "in" {
+		t.Errorf("expected esc to cancel, got %+v", answer)
+	}
+
+	input = NewTextInputMessage("in", "Name?", "")
+	_, cmd = input.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	answer, ok = cmd().(TextInputAnswerMsg)
+	if !ok || answer.Cancelled || answer.Value != "" {
+		t.Errorf("expected enter to submit an empty value, got %+v", answer)
+	}
+}
@@ -2,15 +2,30 @@ package chat
 
 import (
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/diff"
+	"github.com/sst/opencode/internal/i18n"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/shellrisk"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+	"github.com/sst/opencode/internal/viewport"
 )
 
+// maxApprovalPreviewLines caps the height of the diff preview, longer diffs
+// scroll
+const maxApprovalPreviewLines = 15
+
 // ToolApprovalMessage represents a tool approval request in the chat
 type ToolApprovalMessage struct {
 	ID          string
@@ -20,14 +35,62 @@ type ToolApprovalMessage struct {
 	Selected    int // 0 for approve, 1 for deny
 	Answered    bool
 	Approved    bool
+	// Remote is set when the request was answered from a notification
+	Remote bool
+	// Type is the permission type, the tool an "always" rule applies to
+	Type string
+	// Pattern is what the server matches "always" approvals against, the
+	// start of the command for bash
+	Pattern string
+	// SessionID is the session asking, a sub-agent's own session for
+	// requests from sub-agents
+	SessionID string
+	// Queued counts the requests waiting behind this one
+	Queued int
+	// Snapshot describes the workspace snapshot taken for this request, if any
+	Snapshot string
+	// Risks are what a bash command could damage, most severe first
+	Risks []shellrisk.Risk
+	// Diff is the proposed change for edit and write requests as a unified
+	// diff, empty for other tools
+	Diff     string
+	filename string
+	preview  viewport.Model
+	// previewWidth is the width the preview was last rendered at
+	previewWidth int
+
+	// choosingScope is set while picking what an "always" answer covers
+	choosingScope bool
+	scopes        []approvalScope
+	scopeIndex    int
+	glob          textinput.Model
+}
+
+// approvalScope is one choice of what an "always" answer covers
+type approvalScope struct {
+	label string
+	rule  opencode.PermissionRule
+	// editable scopes take their pattern from the glob input
+	editable bool
+	// exact scopes match their pattern literally rather than as a glob
+	exact bool
 }
 
+// Clickable zones of the approval buttons
+const (
+	ZoneApprove = "approval:approve"
+	ZoneDeny    = "approval:deny"
+)
+
 // ToolApprovalMsg is sent when tool approval is needed
 type ToolApprovalMsg struct {
 	ID          string
 	ToolName    string
 	Description string
 	Metadata    map[string]interface{}
+	Type        string
+	Pattern     string
+	SessionID   string
 }
 
 // ToolApprovalAnswerMsg is sent when the user responds
@@ -35,10 +98,31 @@ type ToolApprovalAnswerMsg struct {
 	ID       string
 	Approved bool
 	Response string // "once", "always", or "reject"
+	// Rule is the scope chosen for an "always" answer
+	Rule *opencode.PermissionRule
+	// Exact is set when Rule.Pattern is a command rather than a glob
+	Exact bool
+}
+
+// ToolApprovalSnapshotMsg is sent when the user asks for a workspace snapshot
+// before answering
+type ToolApprovalSnapshotMsg struct {
+	ID          string
+	ToolName    string
+	Description string
 }
 
 // NewToolApprovalMessage creates a new tool approval message
 func NewToolApprovalMessage(id, toolName, description string, metadata map[string]interface{}) *ToolApprovalMessage {
+	filename, patch := proposedDiff(metadata)
+	var risks []shellrisk.Risk
+	if command, ok := metadata["command"].(string); ok {
+		root := util.CwdPath
+		if root == "" {
+			root = util.RootPath
+		}
+		risks = shellrisk.Analyze(command, root)
+	}
 	return &ToolApprovalMessage{
 		ID:          id,
 		ToolName:    toolName,
@@ -46,7 +130,156 @@ func NewToolApprovalMessage(id, toolName, description string, metadata map[strin
 		Metadata:    metadata,
 		Selected:    0,
 		Answered:    false,
+		Risks:       risks,
+		Diff:        patch,
+		filename:    filename,
+		preview:     viewport.New(),
+	}
+}
+
+// proposedDiff works out the change an edit or write request would make from
+// its metadata. Edits are applied to the file on disk when it still holds
+// the old text so the diff carries real line numbers.
+func proposedDiff(metadata map[string]interface{}) (string, string) {
+	filename, _ := metadata["filePath"].(string)
+	if filename == "" {
+		return "", ""
+	}
+	if patch, ok := metadata["diff"].(string); ok && patch != "" {
+		return filename, patch
+	}
+
+	var current string
+	if data, err := os.ReadFile(filename); err == nil {
+		current = string(data)
+	}
+	if oldString, ok := metadata["oldString"].(string); ok {
+		newString, _ := metadata["newString"].(string)
+		if oldString != "" && strings.Contains(current, oldString) {
+			return filename, diff.GenerateUnifiedDiff(
+				filename,
+				current,
+				strings.Replace(current, oldString, newString, 1),
+			)
+		}
+		return filename, diff.GenerateUnifiedDiff(filename, oldString, newString)
+	}
+	if content, ok := metadata["content"].(string); ok {
+		if exists, _ := metadata["exists"].(bool); !exists {
+			current = ""
+		}
+		return filename, diff.GenerateUnifiedDiff(filename, current, content)
+	}
+	return filename, ""
+}
+
+// permissionType is the tool an "always" rule applies to
+func (t *ToolApprovalMessage) permissionType() string {
+	if t.Type != "" {
+		return t.Type
+	}
+	return t.ToolName
+}
+
+// approvalScopes lists what an "always" answer can cover, narrowest first.
+// Bash requests can be allowed for the exact command or a glob of commands.
+func (t *ToolApprovalMessage) approvalScopes() []approvalScope {
+	tool := t.permissionType()
+	rule := func(pattern string, scope opencode.PermissionRuleScope) opencode.PermissionRule {
+		return opencode.PermissionRule{
+			Action:  opencode.PermissionRuleActionAllow,
+			Scope:   scope,
+			Tool:    tool,
+			Pattern: pattern,
+		}
+	}
+
+	var scopes []approvalScope
+	if command, ok := t.Metadata["command"].(string); ok && command != "" {
+		scopes = append(scopes,
+			approvalScope{
+				label: "This exact command",
+				rule:  rule(command, opencode.PermissionRuleScopeSession),
+				exact: true,
+			},
+			approvalScope{
+				label:    "Commands matching",
+				rule:     rule("", opencode.PermissionRuleScopeSession),
+				editable: true,
+			},
+		)
+	}
+	return append(scopes,
+		approvalScope{
+			label: fmt.Sprintf("Any %s call for this session", tool),
+			rule:  rule("", opencode.PermissionRuleScopeSession),
+		},
+		approvalScope{
+			label: fmt.Sprintf("Any %s call in every project", tool),
+			rule:  rule("", opencode.PermissionRuleScopeGlobal),
+		},
+	)
+}
+
+// chooseScope switches to picking the scope of an "always" answer
+func (t *ToolApprovalMessage) chooseScope() tea.Cmd {
+	t.choosingScope = true
+	t.scopes = t.approvalScopes()
+	t.scopeIndex = 0
+	t.glob = textinput.New()
+	t.glob.Placeholder = "npm test*"
+	t.glob.CharLimit = 200
+	t.glob.Styles.Cursor.Blink = !styles.ReduceMotion
+	if t.Pattern != "" {
+		t.glob.SetValue(t.Pattern + "*")
+	} else if command, ok := t.Metadata["command"].(string); ok {
+		t.glob.SetValue(strings.SplitN(command, " ", 2)[0] + " *")
+	}
+	return t.focusScope()
+}
+
+// focusScope focuses the glob input when the selected scope is editable
+func (t *ToolApprovalMessage) focusScope() tea.Cmd {
+	if t.scopes[t.scopeIndex].editable {
+		return t.glob.Focus()
+	}
+	t.glob.Blur()
+	return nil
+}
+
+// updateScope handles input while picking the scope of an "always" answer
+func (t *ToolApprovalMessage) updateScope(msg tea.KeyMsg) (*ToolApprovalMessage, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "ctrl+p", "shift+tab"))):
+		t.scopeIndex = (t.scopeIndex + len(t.scopes) - 1) % len(t.scopes)
+		return t, t.focusScope()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("down", "ctrl+n", "tab"))):
+		t.scopeIndex = (t.scopeIndex + 1) % len(t.scopes)
+		return t, t.focusScope()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		t.choosingScope = false
+		return t, nil
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		scope := t.scopes[t.scopeIndex]
+		rule := scope.rule
+		if scope.editable {
+			rule.Pattern = strings.TrimSpace(t.glob.Value())
+			if rule.Pattern == "" {
+				return t, nil
+			}
+		}
+		t.Answered = true
+		t.Approved = true
+		return t, func() tea.Msg {
+			return ToolApprovalAnswerMsg{ID: t.ID, Approved: true, Response: "always", Rule: &rule, Exact: scope.exact}
+		}
 	}
+	if t.scopes[t.scopeIndex].editable {
+		var cmd tea.Cmd
+		t.glob, cmd = t.glob.Update(msg)
+		return t, cmd
+	}
+	return t, nil
 }
 
 // Update handles input for the tool approval
@@ -54,6 +287,9 @@ func (t *ToolApprovalMessage) Update(msg tea.Msg) (*ToolApprovalMessage, tea.Cmd
 	if t.Answered {
 		return t, nil
 	}
+	if msg, ok := msg.(tea.KeyMsg); ok && t.choosingScope {
+		return t.updateScope(msg)
+	}
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -64,6 +300,14 @@ func (t *ToolApprovalMessage) Update(msg tea.Msg) (*ToolApprovalMessage, tea.Cmd
 			t.Selected = 1
 		case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
 			t.Selected = (t.Selected + 1) % 2
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			t.preview.LineUp(1)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			t.preview.LineDown(1)
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgup"))):
+			t.preview.ViewUp()
+		case key.Matches(msg, key.NewBinding(key.WithKeys("pgdown"))):
+			t.preview.ViewDown()
 		// Upstream-compatible keyboard shortcuts
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			// Enter = Accept once (upstream behavior)
@@ -73,12 +317,8 @@ func (t *ToolApprovalMessage) Update(msg tea.Msg) (*ToolApprovalMessage, tea.Cmd
 				return ToolApprovalAnswerMsg{ID: t.ID, Approved: true, Response: "once"}
 			}
 		case key.Matches(msg, key.NewBinding(key.WithKeys("a", "A"))):
-			// A = Accept always (upstream behavior)
-			t.Answered = true
-			t.Approved = true
-			return t, func() tea.Msg {
-				return ToolApprovalAnswerMsg{ID: t.ID, Approved: true, Response: "always"}
-			}
+			// A = Accept always, once the scope is chosen
+			return t, t.chooseScope()
 		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
 			// Esc = Reject (upstream behavior)
 			t.Answered = true
@@ -86,6 +326,14 @@ func (t *ToolApprovalMessage) Update(msg tea.Msg) (*ToolApprovalMessage, tea.Cmd
 			return t, func() tea.Msg {
 				return ToolApprovalAnswerMsg{ID: t.ID, Approved: false, Response: "reject"}
 			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("s", "S"))):
+			if t.Snapshot != "" {
+				return t, nil
+			}
+			t.Snapshot = "Saving workspace snapshot..."
+			return t, func() tea.Msg {
+				return ToolApprovalSnapshotMsg{ID: t.ID, ToolName: t.ToolName, Description: t.Description}
+			}
 		// Legacy shortcuts for compatibility
 		case key.Matches(msg, key.NewBinding(key.WithKeys("d"))):
 			t.Answered = true
@@ -98,6 +346,22 @@ func (t *ToolApprovalMessage) Update(msg tea.Msg) (*ToolApprovalMessage, tea.Cmd
 	return t, nil
 }
 
+// Click answers with the clicked button, approving once or rejecting
+func (t *ToolApprovalMessage) Click(zone string) (*ToolApprovalMessage, tea.Cmd) {
+	if t.choosingScope {
+		return t, nil
+	}
+	switch zone {
+	case ZoneApprove:
+		t.Selected = 0
+		return t.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	case ZoneDeny:
+		t.Selected = 1
+		return t.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	}
+	return t, nil
+}
+
 // View renders the tool approval message
 func (t *ToolApprovalMessage) View(width int) string {
 	theme := theme.CurrentTheme()
@@ -108,44 +372,43 @@ func (t *ToolApprovalMessage) View(width int) string {
 		Foreground(theme.Warning()).
 		Bold(true).
 		Padding(1, 2, 0, 2)
-	title := titleStyle.Render("🔒 kuuzuki Permission Required")
+	title := titleStyle.Render(styles.IconLock.String() + " " + i18n.T("kuuzuki Permission Required"))
+	if t.Queued > 0 {
+		badge := baseStyle.
+			Foreground(theme.TextMuted()).
+			Padding(1, 0, 0, 0).
+			Render(i18n.T("+%d waiting", t.Queued))
+		title = lipgloss.JoinHorizontal(lipgloss.Top, title, badge)
+	}
 
 	// Tool info with icon
-	toolIcon := "🔧"
+	toolIcon := styles.IconTool
 	switch t.ToolName {
 	case "bash":
-		toolIcon = "⚡"
+		toolIcon = styles.IconBash
 	case "edit":
-		toolIcon = "✏️"
+		toolIcon = styles.IconEdit
 	case "write":
-		toolIcon = "📝"
+		toolIcon = styles.IconWrite
 	case "read":
-		toolIcon = "📖"
+		toolIcon = styles.IconRead
 	}
 
 	toolStyle := baseStyle.
 		Foreground(theme.Primary()).
 		Padding(0, 2)
-	toolInfo := toolStyle.Render(fmt.Sprintf("%s Tool: %s", toolIcon, t.ToolName))
+	toolInfo := toolStyle.Render(i18n.T("%s Tool: %s", toolIcon, t.ToolName))
 
-	// Description with danger detection
+	// Description, flagged when the command is risky
 	descColor := theme.TextMuted()
-	isDangerous := false
-	if cmd, ok := t.Metadata["command"].(string); ok && t.ToolName == "bash" {
-		if strings.Contains(cmd, "rm ") || strings.Contains(cmd, "delete") || strings.Contains(cmd, "DROP") {
-			isDangerous = true
-			descColor = theme.Warning()
-		}
+	description := t.Description
+	if len(t.Risks) > 0 {
+		descColor = theme.Warning()
+		description = styles.IconWarning.String() + " " + description
 	}
-
 	descStyle := baseStyle.
 		Foreground(descColor).
 		Padding(0, 2)
-
-	description := t.Description
-	if isDangerous {
-		description = "⚠️  " + description
-	}
 	desc := descStyle.Render(description)
 
 	if t.Answered {
@@ -156,6 +419,10 @@ func (t *ToolApprovalMessage) View(width int) string {
 			answerText = "Approved"
 			answerColor = theme.Success()
 		}
+		if t.Remote {
+			answerText += " remotely"
+		}
+		answerText = i18n.T(answerText)
 		answerStyle := baseStyle.
 			Foreground(answerColor).
 			Padding(0, 2, 1, 2)
@@ -163,6 +430,10 @@ func (t *ToolApprovalMessage) View(width int) string {
 		return lipgloss.JoinVertical(lipgloss.Left, title, toolInfo, desc, answer)
 	}
 
+	if t.choosingScope {
+		return t.renderFrame(width, title, toolInfo, desc, t.renderScopes(width-8))
+	}
+
 	// Approve/Deny buttons
 	approveStyle := baseStyle
 	denyStyle := baseStyle
@@ -183,30 +454,48 @@ func (t *ToolApprovalMessage) View(width int) string {
 			Foreground(theme.Success())
 	}
 
-	approve := approveStyle.Padding(0, 3).Render("Approve")
-	deny := denyStyle.Padding(0, 3).Render("Deny")
+	approve := layout.Mark(ZoneApprove, approveStyle.Padding(0, 3).Render(i18n.T("Approve")))
+	deny := layout.Mark(ZoneDeny, denyStyle.Padding(0, 3).Render(i18n.T("Deny")))
 
 	buttons := lipgloss.JoinHorizontal(lipgloss.Left, approve, baseStyle.Render("  "), deny)
 	buttonsContainer := baseStyle.Padding(1, 2, 0, 2).Render(buttons)
 
 	// Help text with upstream-compatible shortcuts
 	helpStyle := baseStyle.Foreground(theme.TextMuted()).Italic(true)
-	help := helpStyle.Padding(0, 2, 1, 2).Render("⚡ [Enter] Accept Once    🔄 [A] Always Allow    ❌ [Esc] Reject")
+	help := helpStyle.Padding(0, 2, 1, 2).Render(i18n.T(
+		"%s [Enter] Accept Once    %s [A] Always Allow    %s [S] Snapshot    %s [Esc] Reject",
+		styles.IconRunning, styles.IconRepeat, styles.IconSnapshot, styles.IconError,
+	))
 
 	// Combine all parts
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		title,
-		toolInfo,
-		desc,
-		buttonsContainer,
-		help,
-	)
+	parts := []string{title, toolInfo, desc}
+	if risks := t.renderRisks(width - 8); risks != "" {
+		parts = append(parts, risks)
+	}
+	if preview := t.renderPreview(width - 14); preview != "" {
+		parts = append(parts, preview)
+	}
+	if t.Snapshot != "" {
+		parts = append(parts, baseStyle.Foreground(theme.Success()).Padding(0, 2).Render(styles.IconSnapshot.String()+" "+t.Snapshot))
+	}
+	parts = append(parts, buttonsContainer, help)
+	return t.renderFrame(width, parts...)
+}
+
+// renderFrame stacks the parts inside the approval border
+func (t *ToolApprovalMessage) renderFrame(width int, parts ...string) string {
+	theme := theme.CurrentTheme()
+	baseStyle := styles.NewStyle().Foreground(theme.Text())
+	content := lipgloss.JoinVertical(lipgloss.Left, parts...)
 
 	// Add a border around the whole thing with kuuzuki accent colors
 	borderColor := theme.Accent() // Use kuuzuki accent color
-	if isDangerous {
-		borderColor = theme.Warning() // Use warning color for dangerous operations
+	if severity, risky := shellrisk.Worst(t.Risks); risky {
+		// Use warning and error colors for risky commands
+		borderColor = theme.Warning()
+		if severity == shellrisk.Danger {
+			borderColor = theme.Error()
+		}
 	}
 
 	borderStyle := baseStyle.
@@ -219,3 +508,99 @@ func (t *ToolApprovalMessage) View(width int) string {
 
 	return borderStyle.Render(content)
 }
+
+// renderRisks summarizes the risks of a bash command by category
+func (t *ToolApprovalMessage) renderRisks(width int) string {
+	if len(t.Risks) == 0 {
+		return ""
+	}
+	theme := theme.CurrentTheme()
+	baseStyle := styles.NewStyle().Foreground(theme.Text())
+	mutedStyle := baseStyle.Foreground(theme.TextMuted())
+
+	var categories []shellrisk.Category
+	for _, risk := range t.Risks {
+		if !slices.Contains(categories, risk.Category) {
+			categories = append(categories, risk.Category)
+		}
+	}
+	lines := []string{baseStyle.Bold(true).Render("Risks")}
+	for _, category := range categories {
+		for _, risk := range t.Risks {
+			if risk.Category != category {
+				continue
+			}
+			style := baseStyle.Foreground(theme.Warning())
+			if risk.Severity == shellrisk.Danger {
+				style = baseStyle.Foreground(theme.Error())
+			}
+			line := style.Render("• "+string(category)+": ") + mutedStyle.Render(risk.Reason)
+			lines = append(lines, ansi.Truncate(line, width, "…"))
+		}
+	}
+	return baseStyle.Padding(1, 2, 0, 2).Render(strings.Join(lines, "\n"))
+}
+
+// renderPreview renders the proposed diff, side by side when there is room,
+// scrolling when it is longer than maxApprovalPreviewLines
+func (t *ToolApprovalMessage) renderPreview(width int) string {
+	if t.Diff == "" || width <= 0 {
+		return ""
+	}
+	theme := theme.CurrentTheme()
+	if width != t.previewWidth {
+		var formatted string
+		var err error
+		if width < 120 {
+			formatted, err = diff.FormatUnifiedDiff(t.filename, t.Diff, diff.WithWidth(width))
+		} else {
+			formatted, err = diff.FormatDiff(t.filename, t.Diff, diff.WithWidth(width))
+		}
+		if err != nil {
+			formatted = t.Diff
+		}
+		formatted = strings.TrimSpace(formatted)
+		t.preview.SetWidth(width)
+		t.preview.SetHeight(min(lipgloss.Height(formatted), maxApprovalPreviewLines))
+		t.preview.SetContent(formatted)
+		t.previewWidth = width
+	}
+
+	mutedStyle := styles.NewStyle().
+		Foreground(theme.TextMuted()).
+		Background(theme.BackgroundPanel())
+	header := util.Relative(t.filename)
+	if t.preview.TotalLineCount() > t.preview.Height() {
+		header += fmt.Sprintf("  ↑/↓ scroll %d%%", int(t.preview.ScrollPercent()*100))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, mutedStyle.Render(header), t.preview.View())
+	return styles.NewStyle().Padding(1, 2, 0, 2).Render(body)
+}
+
+// renderScopes renders the choices of what an "always" answer covers
+func (t *ToolApprovalMessage) renderScopes(width int) string {
+	theme := theme.CurrentTheme()
+	baseStyle := styles.NewStyle().Foreground(theme.Text())
+	mutedStyle := baseStyle.Foreground(theme.TextMuted())
+
+	lines := []string{baseStyle.Bold(true).Render("Always allow")}
+	for i, scope := range t.scopes {
+		style := baseStyle
+		marker := "  "
+		if i == t.scopeIndex {
+			style = style.Foreground(theme.Primary()).Bold(true)
+			marker = "> "
+		}
+		line := style.Render(marker + scope.label)
+		switch {
+		case scope.editable:
+			t.glob.SetWidth(max(10, width-lipgloss.Width(line)-4))
+			line += baseStyle.Render(" ") + t.glob.View()
+		case scope.rule.Pattern != "":
+			line += mutedStyle.Render(": " + scope.rule.Pattern)
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	lines = append(lines, "", mutedStyle.Italic(true).Render("↑/↓ choose    [Enter] Allow    [Esc] Back"))
+	return baseStyle.Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
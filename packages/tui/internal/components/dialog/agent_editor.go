@@ -0,0 +1,404 @@
+package dialog
+
+import (
+	"errors"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/textarea"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	agentEditorWidth       = 76
+	agentEditorLabelWidth  = 13
+	agentEditorPromptLines = 8
+)
+
+// agentTools are the built-in tools offered in the allowlist, the same ones
+// `kuuzuki agent create` asks about
+var agentTools = []string{
+	"bash", "read", "write", "edit", "list", "glob", "grep",
+	"webfetch", "task", "todowrite", "todoread",
+}
+
+var agentModes = []opencode.AgentMode{
+	opencode.AgentModeAll,
+	opencode.AgentModePrimary,
+	opencode.AgentModeSubagent,
+}
+
+var agentNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// OpenAgentEditorMsg asks for the agent editor, for a new agent when Agent
+// is nil
+type OpenAgentEditorMsg struct {
+	Agent *opencode.Agent
+}
+
+// AgentEditorDialog creates or modifies a custom agent
+type AgentEditorDialog interface {
+	layout.Modal
+}
+
+type agentField int
+
+const (
+	agentFieldName agentField = iota
+	agentFieldDescription
+	agentFieldModel
+	agentFieldMode
+	agentFieldTools
+	agentFieldPrompt
+	agentFieldCount
+)
+
+type agentEditorDialog struct {
+	app         *app.App
+	modal       *modal.Modal
+	focus       agentField
+	name        textinput.Model
+	description textinput.Model
+	model       textinput.Model
+	prompt      textarea.Model
+	mode        int
+	tools       map[string]bool
+	toolCursor  int
+	err         string
+}
+
+func (a *agentEditorDialog) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (a *agentEditorDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			return a, util.CmdHandler(modal.CloseModalMsg{})
+		case "ctrl+s":
+			return a, a.save()
+		case "tab":
+			return a, a.setFocus((a.focus + 1) % agentFieldCount)
+		case "shift+tab":
+			return a, a.setFocus((a.focus + agentFieldCount - 1) % agentFieldCount)
+		}
+		switch a.focus {
+		case agentFieldMode:
+			if msg.String() == "enter" {
+				return a, a.setFocus(a.focus + 1)
+			}
+			a.mode = cycle(a.mode, len(agentModes), msg.String())
+			return a, nil
+		case agentFieldTools:
+			switch msg.String() {
+			case "left", "h":
+				a.toolCursor = max(a.toolCursor-1, 0)
+			case "right", "l":
+				a.toolCursor = min(a.toolCursor+1, len(agentTools)-1)
+			case "space", " ":
+				tool := agentTools[a.toolCursor]
+				a.tools[tool] = !a.toolEnabled(tool)
+			case "enter":
+				return a, a.setFocus(a.focus + 1)
+			}
+			return a, nil
+		case agentFieldName, agentFieldDescription, agentFieldModel:
+			if msg.String() == "enter" {
+				return a, a.setFocus(a.focus + 1)
+			}
+		}
+	}
+	return a, a.updateFocused(msg)
+}
+
+func (a *agentEditorDialog) updateFocused(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch a.focus {
+	case agentFieldName:
+		a.name, cmd = a.name.Update(msg)
+	case agentFieldDescription:
+		a.description, cmd = a.description.Update(msg)
+	case agentFieldModel:
+		a.model, cmd = a.model.Update(msg)
+	case agentFieldPrompt:
+		a.prompt, cmd = a.prompt.Update(msg)
+	}
+	return cmd
+}
+
+func (a *agentEditorDialog) setFocus(field agentField) tea.Cmd {
+	a.focus = field
+	a.name.Blur()
+	a.description.Blur()
+	a.model.Blur()
+	a.prompt.Blur()
+	switch field {
+	case agentFieldName:
+		return a.name.Focus()
+	case agentFieldDescription:
+		return a.description.Focus()
+	case agentFieldModel:
+		return a.model.Focus()
+	case agentFieldPrompt:
+		return a.prompt.Focus()
+	}
+	return nil
+}
+
+func (a *agentEditorDialog) toolEnabled(tool string) bool {
+	enabled, ok := a.tools[tool]
+	return !ok || enabled
+}
+
+func (a *agentEditorDialog) save() tea.Cmd {
+	// every listed tool is sent on or off as shown, the server's defaults
+	// for custom agents differ for some of them
+	tools := map[string]bool{}
+	maps.Copy(tools, a.tools)
+	for _, tool := range agentTools {
+		tools[tool] = a.toolEnabled(tool)
+	}
+	params, err := agentParams(
+		a.name.Value(),
+		a.description.Value(),
+		a.model.Value(),
+		a.prompt.Value(),
+		agentModes[a.mode],
+		tools,
+	)
+	if err != nil {
+		a.err = err.Error()
+		return nil
+	}
+	return tea.Sequence(
+		util.CmdHandler(modal.CloseModalMsg{}),
+		a.app.SaveAgent(params),
+	)
+}
+
+// agentParams validates the editor's fields and turns them into the request
+// saving the agent
+func agentParams(
+	name, description, model, prompt string,
+	mode opencode.AgentMode,
+	tools map[string]bool,
+) (opencode.AppSaveAgentParams, error) {
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	model = strings.TrimSpace(model)
+	if !agentNamePattern.MatchString(name) {
+		return opencode.AppSaveAgentParams{}, errors.New("name: use lowercase letters, digits, - and _")
+	}
+	if description == "" {
+		return opencode.AppSaveAgentParams{}, errors.New("description: required")
+	}
+	if model != "" {
+		providerID, modelID, ok := strings.Cut(model, "/")
+		if !ok || providerID == "" || modelID == "" {
+			return opencode.AppSaveAgentParams{}, errors.New("model: use provider/model")
+		}
+	}
+
+	params := opencode.AppSaveAgentParams{
+		Name:        opencode.F(name),
+		Description: opencode.F(description),
+		Mode:        opencode.F(mode),
+		Prompt:      opencode.F(strings.TrimSpace(prompt)),
+		Tools:       opencode.F(tools),
+	}
+	if model != "" {
+		params.Model = opencode.F(model)
+	}
+	return params, nil
+}
+
+func (a *agentEditorDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	bg := t.BackgroundPanel()
+	labelStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(bg).Width(agentEditorLabelWidth)
+	focusedLabelStyle := labelStyle.Foreground(t.Primary()).Bold(true)
+	textStyle := styles.NewStyle().Foreground(t.Text()).Background(bg)
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(bg)
+	selectedStyle := styles.NewStyle().Foreground(t.Primary()).Background(bg).Bold(true)
+
+	inputWidth := agentEditorWidth - agentEditorLabelWidth - 6
+	a.name.SetWidth(inputWidth)
+	a.description.SetWidth(inputWidth)
+	a.model.SetWidth(inputWidth)
+	a.prompt.SetWidth(agentEditorWidth - 6)
+
+	label := func(field agentField, text string) string {
+		if a.focus == field {
+			return focusedLabelStyle.Render(text)
+		}
+		return labelStyle.Render(text)
+	}
+
+	mode := string(agentModes[a.mode])
+	if a.focus == agentFieldMode {
+		mode = selectedStyle.Render("‹ " + mode + " ›")
+	} else {
+		mode = textStyle.Render(mode)
+	}
+
+	var tools []string
+	for i, tool := range agentTools {
+		box := "[ ]"
+		if a.toolEnabled(tool) {
+			box = "[x]"
+		}
+		item := box + " " + tool
+		if a.focus == agentFieldTools && i == a.toolCursor {
+			tools = append(tools, selectedStyle.Render(item))
+		} else {
+			tools = append(tools, textStyle.Render(item))
+		}
+	}
+	toolLines := wrapItems(tools, inputWidth, mutedStyle.Render("  "))
+	for i := 1; i < len(toolLines); i++ {
+		toolLines[i] = labelStyle.Render("") + toolLines[i]
+	}
+
+	lines := []string{
+		label(agentFieldName, "Name") + a.name.View(),
+		label(agentFieldDescription, "Description") + a.description.View(),
+		label(agentFieldModel, "Model") + a.model.View(),
+		label(agentFieldMode, "Mode") + mode,
+		label(agentFieldTools, "Tools") + strings.Join(toolLines, "\n"),
+		label(agentFieldPrompt, "Prompt"),
+		a.prompt.View(),
+	}
+	if a.err != "" {
+		lines = append(lines, "", styles.NewStyle().Foreground(t.Error()).Background(bg).Render(a.err))
+	}
+
+	hint := textStyle.Render("tab") + mutedStyle.Render(" next field  ") +
+		textStyle.Render("space") + mutedStyle.Render(" toggle  ") +
+		textStyle.Render("ctrl+s") + mutedStyle.Render(" save")
+	lines = append(lines, "", hint)
+
+	return a.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+// wrapItems joins rendered items with sep, breaking lines at width
+func wrapItems(items []string, width int, sep string) []string {
+	var lines []string
+	line := ""
+	for _, item := range items {
+		if line != "" && lipgloss.Width(line+sep+item) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += sep
+		}
+		line += item
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (a *agentEditorDialog) Close() tea.Cmd {
+	return nil
+}
+
+// newFormInput is a single line field of a form dialog
+func newFormInput(placeholder, value string) textinput.Model {
+	t := theme.CurrentTheme()
+	bgColor := t.BackgroundElement()
+	textColor := t.Text()
+	textMutedColor := t.TextMuted()
+
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.Styles.Blurred.Placeholder = styles.NewStyle().Foreground(textMutedColor).Background(bgColor).Lipgloss()
+	ti.Styles.Blurred.Text = styles.NewStyle().Foreground(textMutedColor).Background(bgColor).Lipgloss()
+	ti.Styles.Blurred.Prompt = styles.NewStyle().Background(bgColor).Lipgloss()
+	ti.Styles.Focused.Placeholder = styles.NewStyle().Foreground(textMutedColor).Background(bgColor).Lipgloss()
+	ti.Styles.Focused.Text = styles.NewStyle().Foreground(textColor).Background(bgColor).Lipgloss()
+	ti.Styles.Focused.Prompt = styles.NewStyle().Background(bgColor).Lipgloss()
+	ti.Styles.Cursor.Color = t.Primary()
+	ti.Styles.Cursor.Blink = !styles.ReduceMotion
+	ti.VirtualCursor = true
+	ti.Prompt = " "
+	ti.CharLimit = -1
+	ti.SetValue(value)
+	return ti
+}
+
+// NewAgentEditorDialog opens the editor on an existing agent, or on a blank
+// one when agent is nil
+func NewAgentEditorDialog(app *app.App, agent *opencode.Agent) AgentEditorDialog {
+	t := theme.CurrentTheme()
+	title := "New Agent"
+	var existing opencode.Agent
+	if agent != nil {
+		title = "Edit Agent"
+		existing = *agent
+	}
+
+	model := ""
+	if existing.Model.ProviderID != "" {
+		model = existing.Model.ProviderID + "/" + existing.Model.ModelID
+	}
+
+	prompt := textarea.New()
+	prompt.Prompt = " "
+	prompt.Placeholder = "System prompt for the agent..."
+	prompt.ShowLineNumbers = false
+	prompt.CharLimit = -1
+	prompt.SetHeight(agentEditorPromptLines)
+	prompt.Styles.Blurred.Base = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Blurred.CursorLine = styles.NewStyle().Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Blurred.Placeholder = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Blurred.Text = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Focused.Base = styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Focused.CursorLine = styles.NewStyle().Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Focused.Placeholder = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Focused.Text = styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement()).Lipgloss()
+	prompt.Styles.Cursor.Color = t.Primary()
+	prompt.SetValue(existing.Prompt)
+
+	mode := 0
+	for i, m := range agentModes {
+		if existing.Mode == m {
+			mode = i
+		}
+	}
+
+	tools := map[string]bool{}
+	maps.Copy(tools, existing.Tools)
+
+	d := &agentEditorDialog{
+		app:         app,
+		name:        newFormInput("my-agent", existing.Name),
+		description: newFormInput("When should this agent be used?", existing.Description),
+		model:       newFormInput("provider/model, empty for the default", model),
+		prompt:      prompt,
+		mode:        mode,
+		tools:       tools,
+		modal: modal.New(
+			modal.WithTitle(title),
+			modal.WithMaxWidth(agentEditorWidth),
+		),
+	}
+	d.setFocus(agentFieldName)
+	if agent != nil {
+		d.setFocus(agentFieldDescription)
+	}
+	return d
+}
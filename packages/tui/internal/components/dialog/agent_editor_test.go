@@ -0,0 +1,37 @@
+package dialog
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestAgentParams(t *testing.T) {
+	tools := map[string]bool{"bash": false}
+	params, err := agentParams(" reviewer ", "Reviews diffs", "anthropic/claude-sonnet-4", "Be thorough.\n", opencode.AgentModeSubagent, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Name.Value != "reviewer" || params.Model.Value != "anthropic/claude-sonnet-4" || params.Prompt.Value != "Be thorough." {
+		t.Errorf("unexpected params %+v", params)
+	}
+
+	params, err = agentParams("docs", "Writes docs", "", "", opencode.AgentModeAll, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Model.Present {
+		t.Error("empty model sent instead of the default")
+	}
+
+	invalid := []struct{ name, description, model string }{
+		{"My Agent", "Reviews diffs", ""},
+		{"reviewer", "  ", ""},
+		{"reviewer", "Reviews diffs", "claude-sonnet-4"},
+	}
+	for _, tc := range invalid {
+		if _, err := agentParams(tc.name, tc.description, tc.model, "", opencode.AgentModeAll, tools); err == nil {
+			t.Errorf("agentParams(%q, %q, %q) accepted", tc.name, tc.description, tc.model)
+		}
+	}
+}
@@ -39,10 +39,10 @@ func (a agentItem) Render(
 	var statusIcon string
 
 	if a.isCurrentAgent {
-		statusIcon = "🟢 " // Green circle for active agent
+		statusIcon = styles.IconActive.String() + " "
 		text = statusIcon + a.agent.Name
 	} else {
-		statusIcon = "⚪ " // White circle for inactive agent
+		statusIcon = styles.IconInactive.String() + " "
 		text = statusIcon + a.agent.Name
 	}
 
@@ -133,6 +133,12 @@ func (a *agentsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					a.app.SaveState(),
 				)
 			}
+		case "ctrl+a":
+			return a, util.CmdHandler(OpenAgentEditorMsg{})
+		case "ctrl+e":
+			if _, idx := a.list.GetSelectedItem(); idx >= 0 && idx < len(a.agents) {
+				return a, util.CmdHandler(OpenAgentEditorMsg{Agent: &a.agents[idx]})
+			}
 		}
 	}
 
@@ -149,7 +155,9 @@ func (a *agentsDialog) Render(background string) string {
 	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
 	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
 
-	helpText := keyStyle("enter") + mutedStyle(" select agent")
+	helpText := keyStyle("enter") + mutedStyle(" select agent  ") +
+		keyStyle("ctrl+a") + mutedStyle(" new  ") +
+		keyStyle("ctrl+e") + mutedStyle(" edit")
 
 	bgColor := t.BackgroundPanel()
 	helpSection := layout.Render(layout.FlexOptions{
@@ -0,0 +1,234 @@
+package dialog
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/attachment"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	// attachmentPreviewLines is the height of the preview, longer text
+	// scrolls
+	attachmentPreviewLines = 16
+	// attachmentPreviewBytes is how much of a referenced file is read for
+	// its preview
+	attachmentPreviewBytes = 64 * 1024
+)
+
+// AttachmentDialog previews an attachment of the prompt, to check it is the
+// right one before sending
+type AttachmentDialog interface {
+	layout.Modal
+}
+
+type attachmentDialog struct {
+	modal *modal.Modal
+	att   *attachment.Attachment
+	rows  [][2]string
+	// preview is the lines of text, or of the thumbnail of an image
+	preview []string
+	// note is shown when there is nothing to preview
+	note   string
+	offset int
+}
+
+func (d *attachmentDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *attachmentDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter", "q":
+			return d, util.CmdHandler(modal.CloseModalMsg{})
+		case "up", "k":
+			d.offset = max(d.offset-1, 0)
+		case "down", "j":
+			d.offset = min(d.offset+1, max(len(d.preview)-attachmentPreviewLines, 0))
+		case "pgup":
+			d.offset = max(d.offset-attachmentPreviewLines, 0)
+		case "pgdown", "space":
+			d.offset = min(d.offset+attachmentPreviewLines, max(len(d.preview)-attachmentPreviewLines, 0))
+		}
+	}
+	return d, nil
+}
+
+func (d *attachmentDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	labelStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Width(10)
+	valueStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel())
+	width := attachmentPreviewWidth()
+
+	var lines []string
+	for _, row := range d.rows {
+		lines = append(lines, labelStyle.Render(row[0])+valueStyle.Render(ansi.Truncate(row[1], width-10, "…")))
+	}
+	lines = append(lines, "")
+	switch {
+	case d.note != "":
+		lines = append(lines, mutedStyle.Render(d.note))
+	default:
+		end := min(d.offset+attachmentPreviewLines, len(d.preview))
+		for _, line := range d.preview[d.offset:end] {
+			lines = append(lines, valueStyle.Render(line))
+		}
+		if len(d.preview) > attachmentPreviewLines {
+			lines = append(lines, mutedStyle.Render(fmt.Sprintf("lines %d-%d of %d", d.offset+1, end, len(d.preview))))
+		}
+	}
+
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	help := keyStyle("enter") + mutedStyle.Render(" close")
+	if len(d.preview) > attachmentPreviewLines {
+		help = keyStyle("↑↓") + mutedStyle.Render(" scroll  ") + help
+	}
+	lines = append(lines, "", help)
+	return d.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+func (d *attachmentDialog) Close() tea.Cmd {
+	return nil
+}
+
+// attachmentPreviewWidth is the width of the dialog's content
+func attachmentPreviewWidth() int {
+	return max(min(72, layout.Current.Container.Width-14), 20)
+}
+
+// load fills in the details of the attachment and its preview
+func (d *attachmentDialog) load(providerID string) {
+	att := d.att
+	width := attachmentPreviewWidth()
+	d.rows = append(d.rows, [2]string{"Name", att.GetFileIcon() + " " + att.Display})
+	tokens := fmt.Sprintf("~%s", util.FormatTokens(float64(attachment.EstimateTokens(att, providerID))))
+
+	if source, ok := att.GetTextSource(); ok {
+		d.rows = append(d.rows,
+			[2]string{"Type", "text"},
+			[2]string{"Size", fmt.Sprintf("%d lines, %s", strings.Count(source.Value, "\n")+1, attachment.FormatBytes(int64(len(source.Value))))},
+			[2]string{"Tokens", tokens},
+		)
+		d.preview = previewText(source.Value, width)
+		return
+	}
+
+	if source, ok := att.GetSymbolSource(); ok {
+		path := strings.TrimPrefix(source.Path, "file://")
+		d.rows = append(d.rows,
+			[2]string{"Type", "symbol"},
+			[2]string{"Path", util.Relative(path)},
+			[2]string{"Lines", fmt.Sprintf("%d-%d", source.Range.Start.Line+1, source.Range.End.Line+1)},
+			[2]string{"Tokens", tokens},
+		)
+		text, err := readPreview(path)
+		if err != nil {
+			d.note = "Failed to read " + util.Relative(path)
+			return
+		}
+		lines := strings.Split(text, "\n")
+		start := min(max(source.Range.Start.Line, 0), len(lines))
+		end := min(max(source.Range.End.Line+1, start), len(lines))
+		d.preview = previewText(strings.Join(lines[start:end], "\n"), width)
+		return
+	}
+
+	source, ok := att.GetFileSource()
+	if !ok {
+		d.note = "Nothing to preview"
+		return
+	}
+	d.rows = append(d.rows,
+		[2]string{"Type", source.Mime},
+		[2]string{"Path", util.Relative(source.Path)},
+	)
+	data := source.Data
+	switch {
+	case len(data) > 0:
+		size := att.GetFormattedSize()
+		if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			size = fmt.Sprintf("%dx%d, %s", config.Width, config.Height, size)
+		}
+		d.rows = append(d.rows, [2]string{"Size", size})
+	default:
+		if info, err := os.Stat(source.Path); err == nil {
+			d.rows = append(d.rows, [2]string{"Size", attachment.FormatBytes(info.Size())})
+		}
+	}
+	d.rows = append(d.rows, [2]string{"Tokens", tokens})
+
+	switch {
+	case strings.HasPrefix(source.Mime, "image/"):
+		if len(data) == 0 {
+			data, _ = os.ReadFile(source.Path)
+		}
+		thumbnail, ok := util.RenderThumbnail(data, width, attachmentPreviewLines)
+		if !ok {
+			d.note = "No preview for " + source.Mime
+			return
+		}
+		d.preview = strings.Split(thumbnail, "\n")
+	case source.Mime == "text/plain":
+		text, err := readPreview(source.Path)
+		if err != nil {
+			d.note = "Failed to read " + util.Relative(source.Path)
+			return
+		}
+		d.preview = previewText(text, width)
+	default:
+		d.note = "No preview for " + source.Mime
+	}
+}
+
+// readPreview reads the start of the file, enough for its preview
+func readPreview(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, attachmentPreviewBytes))
+	return string(data), err
+}
+
+// previewText splits the text into lines cut to the width
+func previewText(text string, width int) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(strings.ReplaceAll(line, "\t", "    "), width, "…")
+	}
+	return lines
+}
+
+// NewAttachmentDialog creates a dialog previewing the attachment's content
+// with its details
+func NewAttachmentDialog(app *app.App, att *attachment.Attachment) AttachmentDialog {
+	d := &attachmentDialog{
+		att: att,
+		modal: modal.New(
+			modal.WithTitle("Attachment"),
+			modal.WithMaxWidth(80),
+		),
+	}
+	providerID := ""
+	if app.Provider != nil {
+		providerID = app.Provider.ID
+	}
+	d.load(providerID)
+	return d
+}
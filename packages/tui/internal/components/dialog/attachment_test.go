@@ -0,0 +1,44 @@
+package dialog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sst/opencode/internal/attachment"
+)
+
+func TestAttachmentPreview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tprintln(1)\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	symbol := &attachment.Attachment{
+		Type:    "symbol",
+		Display: "@main",
+		Source: &attachment.SymbolSource{
+			Path:  "file://" + path,
+			Name:  "main",
+			Range: attachment.SymbolRange{Start: attachment.Position{Line: 2}, End: attachment.Position{Line: 4}},
+		},
+	}
+	d := &attachmentDialog{att: symbol}
+	d.load("")
+	want := []string{"func main() {", "    println(1)", "}"}
+	if !slices.Equal(d.preview, want) {
+		t.Errorf("symbol preview = %q, want %q", d.preview, want)
+	}
+
+	audio := &attachment.Attachment{
+		Type:    "file",
+		Display: "[Audio #1]",
+		Source:  &attachment.FileSource{Path: path, Mime: "audio/wav", Data: []byte("RIFF")},
+	}
+	d = &attachmentDialog{att: audio}
+	d.load("")
+	if d.note != "No preview for audio/wav" || len(d.preview) != 0 {
+		t.Errorf("audio preview = %q, note %q, want only a note", d.preview, d.note)
+	}
+}
@@ -0,0 +1,214 @@
+package dialog
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	numVisibleAuthProviders = 10
+	authDialogWidth         = 60
+)
+
+// providerIDPattern matches the ids of providers, as used by models.dev
+var providerIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// AuthDialog lists the providers and their credentials, for entering or
+// rotating API keys
+type AuthDialog interface {
+	layout.Modal
+}
+
+type authDialog struct {
+	app    *app.App
+	auth   map[string]opencode.AuthListResponse
+	search *SearchDialog
+	modal  *modal.Modal
+}
+
+type authItem struct {
+	id   string
+	name string
+	// status describes where the provider's credentials come from
+	status string
+	// add is set for the item entering a key for a provider not listed
+	add bool
+}
+
+func (a authItem) Render(selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+	mutedStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+
+	if a.add {
+		return baseStyle.
+			Background(t.BackgroundPanel()).
+			PaddingLeft(1).
+			Render(itemStyle.Render("+ add a key for ") + mutedStyle.Render(a.id))
+	}
+	text := itemStyle.Render(a.name)
+	if a.name != a.id {
+		text += mutedStyle.Render(" " + a.id)
+	}
+	if a.status != "" {
+		text += mutedStyle.Faint(true).Render(" · " + a.status)
+	}
+	return baseStyle.
+		Background(t.BackgroundPanel()).
+		PaddingLeft(1).
+		Render(text)
+}
+
+func (a authItem) Selectable() bool {
+	return true
+}
+
+func (d *authDialog) Init() tea.Cmd {
+	return d.search.Init()
+}
+
+func (d *authDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(authItem); ok {
+			name := item.name
+			if item.add {
+				name = item.id
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(app.ProviderKeyRequestedMsg{ProviderID: item.id, Name: name}),
+			)
+		}
+		return d, util.CmdHandler(modal.CloseModalMsg{})
+	case SearchCancelledMsg:
+		return d, util.CmdHandler(modal.CloseModalMsg{})
+	case SearchRemoveItemMsg:
+		item, ok := msg.Item.(authItem)
+		if !ok {
+			return d, nil
+		}
+		if _, stored := d.auth[item.id]; !stored {
+			return d, nil
+		}
+		delete(d.auth, item.id)
+		d.search.SetItems(d.items(d.search.GetQuery()))
+		return d, d.app.RemoveProviderKey(item.id)
+	case SearchQueryChangedMsg:
+		d.search.SetItems(d.items(msg.Query))
+		return d, nil
+	}
+
+	updated, cmd := d.search.Update(msg)
+	d.search = updated.(*SearchDialog)
+	return d, cmd
+}
+
+// items lists the providers known to the server and those with stored
+// credentials, matching the query, plus an item to add a key for the query
+// as a provider ID
+func (d *authDialog) items(query string) []list.Item {
+	providers := map[string]authItem{}
+	for _, provider := range d.app.Providers {
+		providers[provider.ID] = authItem{id: provider.ID, name: provider.Name, status: "environment or config"}
+	}
+	for id, kind := range d.auth {
+		item, ok := providers[id]
+		if !ok {
+			item = authItem{id: id, name: id}
+		}
+		switch kind {
+		case opencode.AuthListResponseAPI:
+			item.status = "api key"
+		case opencode.AuthListResponseOauth:
+			item.status = "oauth"
+		default:
+			item.status = string(kind)
+		}
+		providers[id] = item
+	}
+
+	query = strings.TrimSpace(query)
+	sorted := []authItem{}
+	exact := false
+	for _, item := range providers {
+		if query != "" && !fuzzy.MatchFold(query, item.name+" "+item.id) {
+			continue
+		}
+		exact = exact || item.id == query
+		sorted = append(sorted, item)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].name) < strings.ToLower(sorted[j].name)
+	})
+
+	items := []list.Item{}
+	for _, item := range sorted {
+		items = append(items, item)
+	}
+	// the server only takes keys of providers it knows, ids look like these
+	if !exact && providerIDPattern.MatchString(query) {
+		items = append(items, authItem{id: query, add: true})
+	}
+	return items
+}
+
+func (d *authDialog) View() string {
+	t := theme.CurrentTheme()
+	hint := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		PaddingLeft(1).
+		Render("enter set key  ctrl+x remove stored key")
+	return d.search.View() + "\n" + hint
+}
+
+func (d *authDialog) Render(background string) string {
+	return d.modal.Render(d.View(), background)
+}
+
+func (d *authDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewAuthDialog lists the providers with how they are authenticated
+func NewAuthDialog(app *app.App) AuthDialog {
+	auth, err := app.ListProviderAuth(context.Background())
+	if err != nil {
+		slog.Error("Failed to list provider credentials", "error", err)
+		auth = map[string]opencode.AuthListResponse{}
+	}
+	d := &authDialog{
+		app:  app,
+		auth: auth,
+	}
+	d.search = NewSearchDialog("Search providers or type an ID...", numVisibleAuthProviders)
+	d.search.SetWidth(authDialogWidth)
+	d.search.SetItems(d.items(""))
+	d.modal = modal.New(
+		modal.WithTitle("Provider Keys"),
+		modal.WithMaxWidth(authDialogWidth+4),
+	)
+	return d
+}
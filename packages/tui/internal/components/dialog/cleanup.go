@@ -0,0 +1,208 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// SessionCleanupDialog interface for the session cleanup dialog
+type SessionCleanupDialog interface {
+	layout.Modal
+}
+
+// cleanupItem is a list item for a session that would be deleted
+type cleanupItem struct {
+	title   string
+	updated time.Time
+}
+
+func (c cleanupItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	date := c.updated.Format("02 Jan 2006")
+	titleWidth := max(width-len(date)-3, 1)
+	title := truncate.StringWithTail(c.title, uint(titleWidth), "...")
+	spacer := strings.Repeat(" ", max(width-len(title)-len(date)-2, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	dateStyle := baseStyle.Foreground(t.TextMuted())
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Error()).
+			Foreground(t.BackgroundElement())
+		dateStyle = dateStyle.
+			Background(t.Error()).
+			Foreground(t.BackgroundElement())
+	}
+
+	return itemStyle.Render(title+spacer) + dateStyle.Render(date)
+}
+
+func (c cleanupItem) Selectable() bool {
+	return true
+}
+
+type sessionCleanupDialog struct {
+	width    int
+	height   int
+	modal    *modal.Modal
+	app      *app.App
+	sessions []opencode.Session
+	list     list.List[cleanupItem]
+	// err is set when the sessions could not be listed
+	err error
+}
+
+func (s *sessionCleanupDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (s *sessionCleanupDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		s.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			if len(s.sessions) == 0 {
+				return s, util.CmdHandler(modal.CloseModalMsg{})
+			}
+			return s, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				PruneSessionsCmd(s.app, s.sessions),
+			)
+		}
+	}
+
+	listModel, cmd := s.list.Update(msg)
+	s.list = listModel.(list.List[cleanupItem])
+	return s, cmd
+}
+
+func (s *sessionCleanupDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	var summary string
+	switch {
+	case s.err != nil:
+		summary = mutedStyle("Failed to list sessions: " + s.err.Error())
+	case !s.app.RetentionPolicy().Enabled():
+		summary = mutedStyle("No retention policy configured. Set tui.session_retention in your config.")
+	case len(s.sessions) == 0:
+		summary = mutedStyle("No sessions match the retention policy.")
+	default:
+		plural := "s"
+		if len(s.sessions) == 1 {
+			plural = ""
+		}
+		summary = mutedStyle(fmt.Sprintf("%d session%s will be deleted:", len(s.sessions), plural))
+	}
+
+	helpText := keyStyle("enter") + mutedStyle(" delete sessions")
+	if len(s.sessions) == 0 {
+		helpText = keyStyle("enter") + mutedStyle(" close")
+	}
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	sections := []string{summary}
+	if len(s.sessions) > 0 {
+		sections = append(sections, "", s.list.View())
+	}
+	sections = append(sections, helpSection)
+
+	return s.modal.Render(strings.Join(sections, "\n"), background)
+}
+
+func (s *sessionCleanupDialog) Close() tea.Cmd {
+	return nil
+}
+
+// PruneSessionsCmd deletes the given sessions and reports the result as a toast
+func PruneSessionsCmd(app *app.App, sessions []opencode.Session) tea.Cmd {
+	return func() tea.Msg {
+		deleted, err := app.PruneSessions(context.Background(), sessions)
+		if err != nil {
+			return toast.NewErrorToast("Failed to delete sessions: " + err.Error())()
+		}
+		plural := "s"
+		if deleted == 1 {
+			plural = ""
+		}
+		return toast.NewSuccessToast(fmt.Sprintf("Deleted %d old session%s", deleted, plural))()
+	}
+}
+
+// NewSessionCleanupDialog creates a dialog previewing the sessions the
+// retention policy would delete
+func NewSessionCleanupDialog(app *app.App) SessionCleanupDialog {
+	sessions, err := app.ListPrunableSessions(context.Background())
+	if err != nil {
+		slog.Error("Failed to list sessions for cleanup", "error", err)
+	}
+
+	var items []cleanupItem
+	for _, sess := range sessions {
+		items = append(items, cleanupItem{
+			title:   sess.Title,
+			updated: time.UnixMilli(int64(sess.Time.Updated)),
+		})
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[cleanupItem](10),
+		list.WithFallbackMessage[cleanupItem]("No sessions to delete"),
+		list.WithAlphaNumericKeys[cleanupItem](true),
+		list.WithRenderFunc(
+			func(item cleanupItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item cleanupItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &sessionCleanupDialog{
+		app:      app,
+		sessions: sessions,
+		list:     listComponent,
+		err:      err,
+		modal: modal.New(
+			modal.WithTitle("Clean Up Sessions"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -0,0 +1,212 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/textarea"
+	"github.com/sst/opencode/internal/git"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	commitDialogWidth  = 76
+	commitMessageLines = 6
+	commitFilesShown   = 8
+)
+
+// CommitDialog shows the changes a commit would record, has the model
+// write its message and runs the commit once the message is approved
+type CommitDialog interface {
+	layout.Modal
+}
+
+// commitChangesMsg carries the changes read when the dialog opened
+type commitChangesMsg struct {
+	changes git.Changes
+	err     error
+}
+
+type commitDialog struct {
+	app     *app.App
+	modal   *modal.Modal
+	changes *git.Changes
+	message textarea.Model
+	writing bool
+	err     string
+}
+
+func (c *commitDialog) Init() tea.Cmd {
+	root := c.app.Info.Path.Cwd
+	return func() tea.Msg {
+		changes, err := git.Pending(root)
+		return commitChangesMsg{changes: changes, err: err}
+	}
+}
+
+func (c *commitDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case commitChangesMsg:
+		if msg.err != nil {
+			c.err = msg.err.Error()
+			return c, nil
+		}
+		c.changes = &msg.changes
+		if c.changes.Empty() {
+			return c, nil
+		}
+		return c, c.write()
+	case app.CommitMessageMsg:
+		c.writing = false
+		if msg.Err != nil {
+			c.err = "Failed to write the message: " + msg.Err.Error()
+		} else if c.message.Value() == "" {
+			c.message.SetValue(msg.Message)
+		}
+		return c, nil
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			return c, util.CmdHandler(modal.CloseModalMsg{})
+		case "ctrl+r":
+			if c.changes != nil && !c.changes.Empty() && !c.writing {
+				c.message.SetValue("")
+				return c, c.write()
+			}
+			return c, nil
+		case "ctrl+s":
+			return c, c.commit()
+		}
+	}
+	var cmd tea.Cmd
+	c.message, cmd = c.message.Update(msg)
+	return c, cmd
+}
+
+// write asks the model for the message
+func (c *commitDialog) write() tea.Cmd {
+	c.writing = true
+	c.err = ""
+	return c.app.WriteCommitMessage(*c.changes)
+}
+
+// commit commits the changes with the message
+func (c *commitDialog) commit() tea.Cmd {
+	if c.changes == nil || c.changes.Empty() {
+		return nil
+	}
+	message := strings.TrimSpace(c.message.Value())
+	if message == "" {
+		c.err = "Write a commit message first"
+		return nil
+	}
+	return tea.Sequence(
+		util.CmdHandler(modal.CloseModalMsg{}),
+		c.app.Commit(*c.changes, message),
+	)
+}
+
+func (c *commitDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	bg := t.BackgroundPanel()
+	textStyle := styles.NewStyle().Foreground(t.Text()).Background(bg)
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(bg)
+	addedStyle := styles.NewStyle().Foreground(t.Success()).Background(bg)
+	removedStyle := styles.NewStyle().Foreground(t.Error()).Background(bg)
+	errorStyle := styles.NewStyle().Foreground(t.Error()).Background(bg)
+
+	c.message.SetWidth(commitDialogWidth - 6)
+
+	var lines []string
+	switch {
+	case c.changes == nil && c.err == "":
+		lines = append(lines, mutedStyle.Render("Reading the changes..."))
+	case c.changes != nil && c.changes.Empty():
+		lines = append(lines, mutedStyle.Render("Nothing to commit"))
+	case c.changes != nil:
+		if c.changes.Staged {
+			lines = append(lines, mutedStyle.Render("Staged changes"))
+		} else {
+			lines = append(lines, mutedStyle.Render("All changes, new files included"))
+		}
+		for i, file := range c.changes.Files {
+			if i == commitFilesShown {
+				more := len(c.changes.Files) - commitFilesShown
+				lines = append(lines, mutedStyle.Render(fmt.Sprintf("  and %d more", more)))
+				break
+			}
+			path := truncate.StringWithTail(file.Path, commitDialogWidth-20, "...")
+			line := textStyle.Render("  " + path + " ")
+			switch {
+			case file.Untracked:
+				line += addedStyle.Render("new")
+			case file.Binary:
+				line += mutedStyle.Render("binary")
+			default:
+				line += addedStyle.Render(fmt.Sprintf("+%d", file.Added)) +
+					mutedStyle.Render(" ") +
+					removedStyle.Render(fmt.Sprintf("-%d", file.Removed))
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "")
+		if c.writing {
+			lines = append(lines, mutedStyle.Render("Writing a commit message..."))
+		} else {
+			lines = append(lines, textStyle.Render("Message"))
+		}
+		lines = append(lines, c.message.View())
+	}
+	if c.err != "" {
+		lines = append(lines, "", errorStyle.Render(c.err))
+	}
+
+	hint := textStyle.Render("ctrl+s") + mutedStyle.Render(" commit  ") +
+		textStyle.Render("ctrl+r") + mutedStyle.Render(" rewrite  ") +
+		textStyle.Render("esc") + mutedStyle.Render(" cancel")
+	lines = append(lines, "", hint)
+
+	return c.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+func (c *commitDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewCommitDialog reads the pending changes and has the model write their
+// commit message
+func NewCommitDialog(app *app.App) CommitDialog {
+	t := theme.CurrentTheme()
+	message := textarea.New()
+	message.Prompt = " "
+	message.Placeholder = "Commit message..."
+	message.ShowLineNumbers = false
+	message.CharLimit = -1
+	message.SetHeight(commitMessageLines)
+	message.Styles.Blurred.Base = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Blurred.CursorLine = styles.NewStyle().Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Blurred.Placeholder = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Blurred.Text = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Focused.Base = styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Focused.CursorLine = styles.NewStyle().Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Focused.Placeholder = styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Focused.Text = styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement()).Lipgloss()
+	message.Styles.Cursor.Color = t.Primary()
+	message.Focus()
+
+	return &commitDialog{
+		app:     app,
+		message: message,
+		modal: modal.New(
+			modal.WithTitle("Commit"),
+			modal.WithMaxWidth(commitDialogWidth),
+		),
+	}
+}
@@ -29,11 +29,21 @@ type CompletionDialogCompleteItemMsg struct {
 
 type CompletionDialogCloseMsg struct{}
 
+// CompletionDescendMsg replaces the text typed after the trigger with the
+// directory descended into, or gone up to
+type CompletionDescendMsg struct {
+	Trigger rune
+	Value   string
+}
+
 type CompletionDialog interface {
 	tea.Model
 	tea.ViewModel
 	SetWidth(width int)
 	IsEmpty() bool
+	// SetSpaces lets the query hold spaces rather than closing on one, for
+	// completing whole commands
+	SetSpaces(spaces bool)
 }
 
 type completionDialogComponent struct {
@@ -44,6 +54,7 @@ type completionDialogComponent struct {
 	pseudoSearchTextArea textarea.Model
 	list                 list.List[completions.CompletionSuggestion]
 	trigger              string
+	spaces               bool
 }
 
 type completionDialogKeyMap struct {
@@ -106,6 +117,9 @@ func (c *completionDialogComponent) getAllCompletions(query string) tea.Cmd {
 			for _, match := range matches {
 				rankedItems = append(rankedItems, allItems[match.OriginalIndex])
 			}
+			sort.SliceStable(rankedItems, func(i, j int) bool {
+				return rankedItems[i].Boost > rankedItems[j].Boost
+			})
 
 			return rankedItems
 		}
@@ -128,6 +142,12 @@ func (c *completionDialogComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				fullValue := c.pseudoSearchTextArea.Value()
 				query := strings.TrimPrefix(fullValue, c.trigger)
 
+				// backspace in a directory descended into goes up a level
+				back := msg.String() == "backspace" || msg.String() == "ctrl+h"
+				if back && strings.HasSuffix(c.query, "/") && query != c.query {
+					return c, c.descend(parentDir(c.query))
+				}
+
 				if query != c.query {
 					c.query = query
 					cmds = append(cmds, c.getAllCompletions(query))
@@ -145,6 +165,8 @@ func (c *completionDialogComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return c, nil
 				}
 				return c, c.complete(item)
+			case c.spaces && (msg.String() == "space" || msg.String() == " "):
+				// the space is part of the command being completed
 			case key.Matches(msg, completionDialogKeys.Cancel):
 				value := c.pseudoSearchTextArea.Value()
 				width := lipgloss.Width(value)
@@ -174,7 +196,7 @@ func (c *completionDialogComponent) View() string {
 
 	// Add loading indicator if list is empty and we're searching
 	if c.list.IsEmpty() && c.query != "" {
-		loadingText := "🔍 Searching..."
+		loadingText := styles.IconSearch.String() + " Searching..."
 		loadingStyle := styles.NewStyle().
 			Foreground(t.TextMuted()).
 			Background(t.BackgroundElement()).
@@ -203,11 +225,18 @@ func (c *completionDialogComponent) SetWidth(width int) {
 	c.width = width
 }
 
+func (c *completionDialogComponent) SetSpaces(spaces bool) {
+	c.spaces = spaces
+}
+
 func (c *completionDialogComponent) IsEmpty() bool {
 	return c.list.IsEmpty()
 }
 
 func (c *completionDialogComponent) complete(item completions.CompletionSuggestion) tea.Cmd {
+	if item.Descend {
+		return c.descend(item.Value)
+	}
 	value := c.pseudoSearchTextArea.Value()
 	return tea.Batch(
 		util.CmdHandler(CompletionSelectedMsg{
@@ -218,6 +247,26 @@ func (c *completionDialogComponent) complete(item completions.CompletionSuggesti
 	)
 }
 
+// descend lists the entries of a directory, keeping the dialog open
+func (c *completionDialogComponent) descend(dir string) tea.Cmd {
+	c.query = dir
+	c.pseudoSearchTextArea.SetValue(c.trigger + dir)
+	return tea.Batch(
+		util.CmdHandler(CompletionDescendMsg{Trigger: []rune(c.trigger)[0], Value: dir}),
+		c.getAllCompletions(dir),
+	)
+}
+
+// parentDir returns the directory above dir, both ending in a slash, or
+// empty at the top
+func parentDir(dir string) string {
+	dir = strings.TrimSuffix(dir, "/")
+	if i := strings.LastIndex(dir, "/"); i >= 0 {
+		return dir[:i+1]
+	}
+	return ""
+}
+
 func (c *completionDialogComponent) close() tea.Cmd {
 	c.pseudoSearchTextArea.Reset()
 	c.pseudoSearchTextArea.Blur()
@@ -228,6 +277,12 @@ func NewCompletionDialogComponent(
 	trigger string,
 	providers ...completions.CompletionProvider,
 ) CompletionDialog {
+	for _, provider := range providers {
+		if snapshotter, ok := provider.(completions.Snapshotter); ok {
+			snapshotter.Snapshot()
+		}
+	}
+
 	ti := textarea.New()
 	ti.SetValue(trigger)
 
@@ -0,0 +1,17 @@
+package dialog
+
+import "testing"
+
+func TestParentDir(t *testing.T) {
+	tests := map[string]string{
+		"src/":               "",
+		"src/components/":    "src/",
+		"src/components/ui/": "src/components/",
+		"":                   "",
+	}
+	for dir, want := range tests {
+		if got := parentDir(dir); got != want {
+			t.Errorf("parentDir(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// ContextDropMsg is sent to drop a message, and everything after it, from the
+// session's context
+type ContextDropMsg struct {
+	MessageID string
+}
+
+// ContextDialog interface for the context composition dialog
+type ContextDialog interface {
+	layout.Modal
+}
+
+// contextItem is a list item for a piece of the context window
+type contextItem struct {
+	item app.ContextItem
+}
+
+func (c contextItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	kind := fmt.Sprintf("%-8s", c.item.Kind)
+	tokens := "~" + util.FormatTokens(float64(c.item.Tokens))
+	label := truncate.StringWithTail(
+		strings.ReplaceAll(c.item.Label, "\n", " "),
+		uint(max(width-len(kind)-len(tokens)-4, 1)),
+		"...",
+	)
+	spacer := strings.Repeat(" ", max(width-len(kind)-ansi.StringWidth(label)-len(tokens)-2, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(kind + label + spacer + tokens)
+	}
+	kindStyle := baseStyle.Foreground(t.TextMuted())
+	if c.item.Kind == app.ContextPinned {
+		kindStyle = baseStyle.Foreground(t.Accent())
+	}
+	return kindStyle.PaddingLeft(1).Render(kind) +
+		baseStyle.Render(label+spacer) +
+		baseStyle.Foreground(t.TextMuted()).Render(tokens)
+}
+
+func (c contextItem) Selectable() bool {
+	return true
+}
+
+type contextDialog struct {
+	width  int
+	height int
+	modal  *modal.Modal
+	app    *app.App
+	items  []app.ContextItem
+	list   list.List[contextItem]
+}
+
+func (c *contextDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (c *contextDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		c.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		_, idx := c.list.GetSelectedItem()
+		if idx < 0 || idx >= len(c.items) {
+			break
+		}
+		item := c.items[idx]
+		switch msg.String() {
+		case "d", "x":
+			if c.app.ReadOnly {
+				return c, toast.NewInfoToast(app.ReadOnlyMessage)
+			}
+			switch item.Kind {
+			case app.ContextPinned:
+				if item.Path != "" {
+					if _, err := c.app.TogglePinnedFile(item.Path); err != nil {
+						return c, toast.NewErrorToast("Failed to unpin " + item.Path + ": " + err.Error())
+					}
+					c.refresh(idx)
+					return c, nil
+				}
+				c.app.UnpinContext(item.Pin)
+				c.refresh(idx)
+				return c, c.app.SaveState()
+			case app.ContextMessage, app.ContextFile:
+				return c, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(ContextDropMsg{MessageID: item.MessageID}),
+				)
+			case app.ContextEvicted:
+				return c, toast.NewInfoToast("Scroll up to load the earlier messages before dropping them")
+			default:
+				return c, toast.NewInfoToast("The system prompt can't be dropped")
+			}
+		case "p":
+			if item.Kind != app.ContextMessage || item.Text == "" {
+				return c, toast.NewInfoToast("Only messages with text can be pinned")
+			}
+			c.app.PinContext(item.Label, item.Text)
+			c.refresh(idx)
+			return c, tea.Batch(c.app.SaveState(), toast.NewSuccessToast("Pinned to the session's context"))
+		}
+	}
+
+	listModel, cmd := c.list.Update(msg)
+	c.list = listModel.(list.List[contextItem])
+	return c, cmd
+}
+
+// refresh recomputes the items after pinning or unpinning, keeping the
+// selection close to where it was
+func (c *contextDialog) refresh(selected int) {
+	c.items = c.app.ContextItems()
+	c.list.SetItems(contextItems(c.items))
+	c.list.SetSelectedIndex(min(selected, len(c.items)-1))
+}
+
+func contextItems(items []app.ContextItem) []contextItem {
+	var listItems []contextItem
+	for _, item := range items {
+		listItems = append(listItems, contextItem{item: item})
+	}
+	return listItems
+}
+
+func (c *contextDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	estimated := 0
+	for _, item := range c.items {
+		estimated += item.Tokens
+	}
+	summary := mutedStyle("estimated ") + keyStyle("~"+util.FormatTokens(float64(estimated)))
+	if used, window := c.app.ContextUsage(); used > 0 {
+		summary += mutedStyle("  last response used ") + keyStyle(util.FormatTokens(used))
+		if window > 0 {
+			summary += mutedStyle(" of " + util.FormatTokens(window))
+		}
+	}
+
+	helpText := keyStyle("p") + mutedStyle(" pin  ") +
+		keyStyle("d") + mutedStyle(" drop from here / unpin")
+
+	bgColor := t.BackgroundPanel()
+	flex := layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}
+	summarySection := styles.NewStyle().PaddingLeft(1).PaddingBottom(1).Render(
+		layout.Render(flex, layout.FlexItem{View: summary}),
+	)
+	helpSection := styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(
+		layout.Render(flex, layout.FlexItem{View: helpText}),
+	)
+
+	content := strings.Join([]string{summarySection, c.list.View(), helpSection}, "\n")
+	return c.modal.Render(content, background)
+}
+
+func (c *contextDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewContextDialog creates a dialog breaking down what occupies the context
+// window, to pin or drop items from it
+func NewContextDialog(app *app.App) ContextDialog {
+	items := app.ContextItems()
+
+	listComponent := list.NewListComponent(
+		list.WithItems(contextItems(items)),
+		list.WithMaxVisibleHeight[contextItem](12),
+		list.WithFallbackMessage[contextItem]("Nothing in context yet"),
+		list.WithRenderFunc(
+			func(item contextItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item contextItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &contextDialog{
+		app:   app,
+		items: items,
+		list:  listComponent,
+		modal: modal.New(
+			modal.WithTitle("Context"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -0,0 +1,172 @@
+package dialog
+
+import (
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/filetree"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	numVisibleFileTreeRows = 15
+	fileTreeDialogWidth    = 76
+)
+
+// AttachFileMsg asks the editor to attach a file to the prompt
+type AttachFileMsg struct {
+	FilePath string
+}
+
+// FileTreeDialog browses the working directory, for opening files in the
+// file viewer or attaching them to the prompt
+type FileTreeDialog interface {
+	layout.Modal
+}
+
+type fileTreeDialog struct {
+	tree   *filetree.Tree
+	search *SearchDialog
+	modal  *modal.Modal
+}
+
+type fileTreeItem struct {
+	row filetree.Row
+}
+
+func (f fileTreeItem) Render(selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+	mutedStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+
+	indent := strings.Repeat("  ", f.row.Depth)
+	text := mutedStyle.Render(indent+"  ") + itemStyle.Render(f.row.Name)
+	if f.row.Dir {
+		marker := "▸ "
+		if f.row.Open {
+			marker = "▾ "
+		}
+		text = mutedStyle.Render(indent+marker) + itemStyle.Render(f.row.Name+"/")
+	}
+	return baseStyle.
+		Background(t.BackgroundPanel()).
+		PaddingLeft(1).
+		Render(text)
+}
+
+func (f fileTreeItem) Selectable() bool {
+	return true
+}
+
+func (d *fileTreeDialog) Init() tea.Cmd {
+	return d.search.Init()
+}
+
+func (d *fileTreeDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if msg.String() == "tab" {
+			item, _ := d.search.list.GetSelectedItem()
+			if item, ok := item.(fileTreeItem); ok && !item.row.Dir {
+				return d, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(AttachFileMsg{FilePath: item.row.Path}),
+				)
+			}
+			return d, nil
+		}
+	case SearchSelectionMsg:
+		item, ok := msg.Item.(fileTreeItem)
+		if !ok {
+			return d, nil
+		}
+		if !item.row.Dir {
+			return d, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(FindSelectedMsg{FilePath: item.row.Path}),
+			)
+		}
+		// a directory shown open for the query can't be collapsed, its
+		// matches are listed until the query changes
+		if d.search.GetQuery() != "" && item.row.Open {
+			return d, nil
+		}
+		if err := d.tree.Toggle(item.row.Node); err != nil {
+			slog.Error("Failed to read directory", "path", item.row.Path, "error", err)
+			return d, toast.NewErrorToast("Failed to read " + item.row.Path + ": " + err.Error())
+		}
+		d.search.SetItems(d.items(d.search.GetQuery()))
+		d.search.list.SetSelectedIndex(msg.Index)
+		return d, nil
+	case SearchCancelledMsg:
+		return d, util.CmdHandler(modal.CloseModalMsg{})
+	case SearchQueryChangedMsg:
+		d.search.SetItems(d.items(msg.Query))
+		return d, nil
+	}
+
+	updated, cmd := d.search.Update(msg)
+	d.search = updated.(*SearchDialog)
+	return d, cmd
+}
+
+func (d *fileTreeDialog) items(query string) []list.Item {
+	items := []list.Item{}
+	for _, row := range d.tree.Rows(query) {
+		items = append(items, fileTreeItem{row: row})
+	}
+	return items
+}
+
+func (d *fileTreeDialog) View() string {
+	t := theme.CurrentTheme()
+	hint := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		PaddingLeft(1).
+		Render("enter open or expand  tab attach  typing filters directories opened so far")
+	return d.search.View() + "\n" + hint
+}
+
+func (d *fileTreeDialog) Render(background string) string {
+	return d.modal.Render(d.View(), background)
+}
+
+func (d *fileTreeDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewFileTreeDialog browses the working directory, reading each directory
+// when it's first expanded
+func NewFileTreeDialog(app *app.App) FileTreeDialog {
+	tree, err := filetree.New(app.Info.Path.Cwd)
+	if err != nil {
+		slog.Error("Failed to read working directory", "error", err)
+		tree = &filetree.Tree{Root: app.Info.Path.Cwd}
+	}
+	d := &fileTreeDialog{tree: tree}
+	d.search = NewSearchDialog("Filter files...", numVisibleFileTreeRows)
+	d.search.SetWidth(fileTreeDialogWidth)
+	d.search.list.SetEmptyMessage(" No files")
+	d.search.SetItems(d.items(""))
+	d.modal = modal.New(
+		modal.WithTitle("Files"),
+		modal.WithMaxWidth(fileTreeDialogWidth+4),
+	)
+	return d
+}
@@ -19,6 +19,8 @@ const (
 
 type FindSelectedMsg struct {
 	FilePath string
+	// Pin is set when the file was picked to pin it rather than to open it
+	Pin bool
 }
 
 type FindDialogCloseMsg struct{}
@@ -70,6 +72,14 @@ type findDialogComponent struct {
 	modal              *modal.Modal
 	searchDialog       *SearchDialog
 	dialogWidth        int
+	pin                bool
+}
+
+func (f *findDialogComponent) title() string {
+	if f.pin {
+		return "Pin File"
+	}
+	return "Find Files"
 }
 
 func (f *findDialogComponent) Init() tea.Cmd {
@@ -112,7 +122,7 @@ func (f *findDialogComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Update modal with calculated width
 		f.modal = modal.New(
-			modal.WithTitle("Find Files"),
+			modal.WithTitle(f.title()),
 			modal.WithMaxWidth(f.dialogWidth+4),
 		)
 
@@ -159,7 +169,7 @@ func (f *findDialogComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			f.searchDialog.SetWidth(f.dialogWidth)
 			// Update modal max width too
 			f.modal = modal.New(
-				modal.WithTitle("Find Files"),
+				modal.WithTitle(f.title()),
 				modal.WithMaxWidth(f.dialogWidth+4),
 			)
 		}
@@ -202,6 +212,7 @@ func (f *findDialogComponent) selectFile(item completions.CompletionSuggestion)
 		f.Close(),
 		util.CmdHandler(FindSelectedMsg{
 			FilePath: item.Value,
+			Pin:      f.pin,
 		}),
 	)
 }
@@ -234,3 +245,14 @@ func NewFindDialog(completionProvider completions.CompletionProvider) FindDialog
 
 	return component
 }
+
+// NewPinFileDialog is the find dialog picking a file to pin to the session
+func NewPinFileDialog(completionProvider completions.CompletionProvider) FindDialog {
+	component := NewFindDialog(completionProvider).(*findDialogComponent)
+	component.pin = true
+	component.modal = modal.New(
+		modal.WithTitle(component.title()),
+		modal.WithMaxWidth(findDialogWidth+4),
+	)
+	return component
+}
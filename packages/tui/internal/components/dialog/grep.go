@@ -0,0 +1,238 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	grepDialogWidth = 96
+	// grepMaxMatches caps the matches listed for a pattern found everywhere
+	grepMaxMatches = 200
+)
+
+// GrepAttachMsg is sent to attach every match of a search to the prompt
+type GrepAttachMsg struct {
+	Name string
+	Text string
+}
+
+// GrepDialog searches the text of the project's files
+type GrepDialog interface {
+	layout.Modal
+}
+
+// grepResultMsg carries the matches found for a pattern
+type grepResultMsg struct {
+	pattern string
+	matches []opencode.FindTextResponse
+	err     error
+}
+
+// grepItem is a list item for a matching line
+type grepItem struct {
+	match opencode.FindTextResponse
+}
+
+// location returns the file and line of the match
+func (g grepItem) location() string {
+	return fmt.Sprintf("%s:%d", g.match.Path.Text, int(g.match.LineNumber))
+}
+
+func (g grepItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	location := truncate.StringWithTail(g.location(), uint(max(width/3, 1)), "...")
+	text := strings.TrimSpace(strings.ReplaceAll(g.match.Lines.Text, "\t", "  "))
+	text = truncate.StringWithTail(text, uint(max(width-ansi.StringWidth(location)-4, 1)), "...")
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(location + "  " + text)
+	}
+	return itemStyle.Foreground(t.TextMuted()).Render(location+"  ") +
+		baseStyle.Render(text)
+}
+
+func (g grepItem) Selectable() bool {
+	return true
+}
+
+type grepDialog struct {
+	app     *app.App
+	modal   *modal.Modal
+	search  *SearchDialog
+	matches []opencode.FindTextResponse
+	status  string
+}
+
+func (g *grepDialog) Init() tea.Cmd {
+	return g.search.Init()
+}
+
+func (g *grepDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case grepResultMsg:
+		if msg.pattern != g.search.GetQuery() {
+			return g, nil
+		}
+		g.matches = msg.matches
+		switch {
+		case msg.err != nil:
+			g.status = "Search failed: " + msg.err.Error()
+		case len(g.matches) > grepMaxMatches:
+			g.status = fmt.Sprintf("First %d matches", grepMaxMatches)
+			g.matches = g.matches[:grepMaxMatches]
+		default:
+			g.status = fmt.Sprintf("%d matches", len(g.matches))
+		}
+		items := []list.Item{}
+		for _, match := range g.matches {
+			items = append(items, grepItem{match: match})
+		}
+		g.search.SetItems(items)
+		return g, nil
+	case SearchQueryChangedMsg:
+		return g, g.find(msg.Query)
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(grepItem); ok {
+			return g, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(app.OpenFileMsg{
+					Path: item.match.Path.Text,
+					Line: int(item.match.LineNumber) - 1,
+				}),
+			)
+		}
+		return g, nil
+	case SearchCancelledMsg:
+		return g, util.CmdHandler(modal.CloseModalMsg{})
+	case tea.KeyPressMsg:
+		if msg.String() == "ctrl+e" {
+			if item, idx := g.search.SelectedItem(); idx >= 0 {
+				match := item.(grepItem).match
+				return g, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(app.EditFileMsg{
+						Path: match.Path.Text,
+						Line: int(match.LineNumber) - 1,
+					}),
+				)
+			}
+			return g, nil
+		}
+		if msg.String() == "ctrl+a" {
+			if len(g.matches) == 0 {
+				return g, nil
+			}
+			return g, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(GrepAttachMsg{
+					Name: "grep " + g.search.GetQuery(),
+					Text: grepText(g.matches),
+				}),
+			)
+		}
+	}
+
+	updated, cmd := g.search.Update(msg)
+	g.search = updated.(*SearchDialog)
+	return g, cmd
+}
+
+// grepText lists the matches like grep -n does
+func grepText(matches []opencode.FindTextResponse) string {
+	var text strings.Builder
+	for _, match := range matches {
+		line := strings.TrimRight(match.Lines.Text, "\r\n")
+		fmt.Fprintf(&text, "%s:%d:%s\n", match.Path.Text, int(match.LineNumber), line)
+	}
+	return text.String()
+}
+
+// find searches the files for pattern
+func (g *grepDialog) find(pattern string) tea.Cmd {
+	if strings.TrimSpace(pattern) == "" {
+		g.matches = nil
+		g.status = ""
+		g.search.SetItems([]list.Item{})
+		return nil
+	}
+	client := g.app.Client
+	return func() tea.Msg {
+		matches, err := client.Find.Text(
+			context.Background(),
+			opencode.FindTextParams{Pattern: opencode.F(pattern)},
+		)
+		if err != nil {
+			slog.Error("Failed to search files", "error", err)
+			return grepResultMsg{pattern: pattern, err: err}
+		}
+		if matches == nil {
+			return grepResultMsg{pattern: pattern}
+		}
+		return grepResultMsg{pattern: pattern, matches: *matches}
+	}
+}
+
+func (g *grepDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	helpText := keyStyle("enter") + mutedStyle(" open  ") +
+		keyStyle("ctrl+e") + mutedStyle(" edit  ") +
+		keyStyle("ctrl+a") + mutedStyle(" attach all")
+	if g.status != "" {
+		helpText = mutedStyle(g.status+"  ") + helpText
+	}
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      grepDialogWidth - 2,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+
+	content := strings.Join([]string{g.search.View(), "", helpSection}, "\n")
+	return g.modal.Render(content, background)
+}
+
+func (g *grepDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewGrepDialog creates a dialog for searching the text of the project
+func NewGrepDialog(app *app.App) GrepDialog {
+	search := NewSearchDialog("Search text (regular expression)...", 14)
+	search.SetWidth(grepDialogWidth)
+	return &grepDialog{
+		app:    app,
+		search: search,
+		modal: modal.New(
+			modal.WithTitle("Grep"),
+			modal.WithMaxWidth(grepDialogWidth+4),
+		),
+	}
+}
@@ -0,0 +1,26 @@
+package dialog
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestGrepText(t *testing.T) {
+	matches := []opencode.FindTextResponse{
+		{
+			Path:       opencode.FindTextResponsePath{Text: "src/app.go"},
+			LineNumber: 12,
+			Lines:      opencode.FindTextResponseLines{Text: "\tfunc main() {\n"},
+		},
+		{
+			Path:       opencode.FindTextResponsePath{Text: "README.md"},
+			LineNumber: 3,
+			Lines:      opencode.FindTextResponseLines{Text: "run main\r\n"},
+		},
+	}
+	want := "src/app.go:12:\tfunc main() {\nREADME.md:3:run main\n"
+	if got := grepText(matches); got != want {
+		t.Errorf("grepText() = %q, want %q", got, want)
+	}
+}
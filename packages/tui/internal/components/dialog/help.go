@@ -1,56 +1,283 @@
 package dialog
 
 import (
+	"slices"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode/internal/app"
-	commandsComponent "github.com/sst/opencode/internal/components/commands"
+	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/components/list"
 	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/i18n"
 	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
-	"github.com/sst/opencode/internal/viewport"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	numVisibleHelpRows = 16
+	helpDialogWidth    = 76
+	helpKeyWidth       = 22
 )
 
+// HelpContext is the state of the UI when help was opened, it decides which
+// bindings are shown as active
+type HelpContext struct {
+	Busy              bool
+	InputEmpty        bool
+	CompletionOpen    bool
+	ScratchpadFocused bool
+	FileViewerFocused bool
+	FileViewerOpen    bool
+}
+
+// helpEntry is one binding, either a command from the registry or a key
+// handled by a pane or dialog directly
+type helpEntry struct {
+	command     *commands.Command
+	keys        string
+	description string
+	trigger     string
+	// where is set for keys that only apply in one pane or dialog
+	where  string
+	active bool
+	// reason says why an inactive binding doesn't apply right now
+	reason string
+}
+
+// contextKeys are handled by the pane or dialog that has focus rather than
+// the commands registry
+var contextKeys = []struct {
+	where       string
+	keys        string
+	description string
+}{
+	{"dialogs", "up/down, ctrl+p/n", "move the selection"},
+	{"dialogs", "enter", "select"},
+	{"dialogs", "esc", "close"},
+	{"completions", "tab, enter", "complete"},
+	{"completions", "up/down, ctrl+p/n", "move the selection"},
+	{"completions", "esc", "close"},
+	{"scratchpad", "ctrl+s", "send as a prompt"},
+	{"scratchpad", "ctrl+t", "attach to the prompt"},
+	{"scratchpad", "esc", "back to the editor"},
+	{"file viewer", "up/down, pgup/pgdn", "scroll"},
+	{"file viewer", "esc", "back to the editor"},
+}
+
+// fileViewerCommands do nothing without a file open
+var fileViewerCommands = []commands.CommandName{
+	commands.FileCloseCommand,
+	commands.FileFocusCommand,
+	commands.FileGrowCommand,
+	commands.FileShrinkCommand,
+	commands.FileDiffToggleCommand,
+}
+
+// helpEntries lists every command in the registry followed by the keys of
+// each pane and dialog, marking which ones apply in ctx. Help is itself a
+// dialog, so the dialog keys are always active.
+func helpEntries(registry commands.CommandRegistry, leader string, ctx HelpContext) []helpEntry {
+	var entries []helpEntry
+	paneFocused := ""
+	if ctx.ScratchpadFocused {
+		paneFocused = "scratchpad"
+	} else if ctx.FileViewerFocused {
+		paneFocused = "file viewer"
+	}
+
+	for _, cmd := range registry.Sorted() {
+		entry := helpEntry{
+			command:     &cmd,
+			description: i18n.T(cmd.Description),
+			active:      true,
+		}
+		var keys []string
+		leaderOnly := true
+		for _, kb := range cmd.Keybindings {
+			if kb.RequiresLeader {
+				keys = append(keys, leader+" "+kb.Key)
+			} else {
+				keys = append(keys, kb.Key)
+				leaderOnly = false
+			}
+		}
+		entry.keys = strings.Join(keys, ", ")
+		if cmd.HasTrigger() {
+			entry.trigger = "/" + cmd.PrimaryTrigger()
+		}
+
+		switch {
+		case cmd.Name == commands.SessionInterruptCommand && !ctx.Busy:
+			entry.active, entry.reason = false, i18n.T("only while busy")
+		case cmd.Name == commands.InputClearCommand && ctx.InputEmpty:
+			entry.active, entry.reason = false, i18n.T("input is empty")
+		case slices.Contains(fileViewerCommands, cmd.Name) && !ctx.FileViewerOpen:
+			entry.active, entry.reason = false, i18n.T("no file open")
+		case ctx.CompletionOpen:
+			// keys go to the editor and the completions until they close
+			entry.active, entry.reason = false, i18n.T("completions open")
+		case paneFocused != "" && (len(cmd.Keybindings) == 0 || !leaderOnly):
+			// a focused pane takes every key but the leader
+			entry.active, entry.reason = false, i18n.T("%s has focus", i18n.T(paneFocused))
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, key := range contextKeys {
+		entry := helpEntry{
+			keys:        key.keys,
+			description: i18n.T(key.description),
+			where:       key.where,
+		}
+		switch key.where {
+		case "dialogs":
+			entry.active = true
+		case "completions":
+			entry.active = ctx.CompletionOpen
+		default:
+			entry.active = key.where == paneFocused
+		}
+		if !entry.active {
+			entry.reason = i18n.T("in the %s", i18n.T(key.where))
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// matches reports whether the query is found in the entry's keys,
+// description, trigger or command name
+func (e helpEntry) matches(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	fields := []string{e.keys, e.description, e.trigger, e.where, i18n.T(e.where)}
+	if e.command != nil {
+		fields = append(fields, string(e.command.Name))
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+type helpItem struct {
+	entry helpEntry
+}
+
+func (h helpItem) Render(selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	baseStyle = baseStyle.Background(t.BackgroundPanel())
+	keyStyle := baseStyle.Foreground(t.Primary()).Bold(true)
+	textStyle := baseStyle.Foreground(t.Text())
+	mutedStyle := baseStyle.Foreground(t.TextMuted())
+	if !h.entry.active {
+		keyStyle = mutedStyle
+		textStyle = mutedStyle
+	}
+	if selected {
+		textStyle = textStyle.Foreground(t.Primary())
+	}
+
+	keys := h.entry.keys
+	if keys == "" {
+		keys = h.entry.trigger
+	}
+	keys = truncate.StringWithTail(keys, helpKeyWidth-2, "…")
+	text := keyStyle.Render(keys) +
+		mutedStyle.Render(strings.Repeat(" ", max(1, helpKeyWidth-ansi.StringWidth(keys)))) +
+		textStyle.Render(h.entry.description)
+	if h.entry.keys != "" && h.entry.trigger != "" {
+		text += mutedStyle.Render(" " + h.entry.trigger)
+	}
+	if h.entry.active && h.entry.where != "" {
+		text += mutedStyle.Render(" (" + i18n.T(h.entry.where) + ")")
+	}
+	if !h.entry.active {
+		text += mutedStyle.Render(" (" + h.entry.reason + ")")
+	}
+	return baseStyle.PaddingLeft(1).Render(text)
+}
+
+func (h helpItem) Selectable() bool {
+	return true
+}
+
 type helpDialog struct {
-	width             int
-	height            int
-	modal             *modal.Modal
-	app               *app.App
-	commandsComponent commandsComponent.CommandsComponent
-	viewport          viewport.Model
+	entries []helpEntry
+	search  *SearchDialog
+	modal   *modal.Modal
 }
 
 func (h *helpDialog) Init() tea.Cmd {
-	return h.viewport.Init()
+	return h.search.Init()
 }
 
 func (h *helpDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
 	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		h.width = msg.Width
-		h.height = msg.Height
-		// Set viewport size with some padding for the modal, but cap at reasonable width
-		maxWidth := min(80, msg.Width-8)
-		h.viewport = viewport.New(viewport.WithWidth(maxWidth-4), viewport.WithHeight(msg.Height-6))
-		h.commandsComponent.SetSize(maxWidth-4, msg.Height-6)
+	case SearchSelectionMsg:
+		item, ok := msg.Item.(helpItem)
+		if !ok || item.entry.command == nil || !item.entry.active {
+			return h, nil
+		}
+		return h, tea.Sequence(
+			util.CmdHandler(modal.CloseModalMsg{}),
+			util.CmdHandler(commands.ExecuteCommandMsg(*item.entry.command)),
+		)
+	case SearchCancelledMsg:
+		return h, util.CmdHandler(modal.CloseModalMsg{})
+	case SearchQueryChangedMsg:
+		h.search.SetItems(h.items(msg.Query))
+		return h, nil
 	}
 
-	// Update viewport content
-	h.viewport.SetContent(h.commandsComponent.View())
-
-	// Update viewport
-	var vpCmd tea.Cmd
-	h.viewport, vpCmd = h.viewport.Update(msg)
-	cmds = append(cmds, vpCmd)
+	updated, cmd := h.search.Update(msg)
+	h.search = updated.(*SearchDialog)
+	return h, cmd
+}
 
-	return h, tea.Batch(cmds...)
+// items lists the entries matching the query, the ones that apply right now
+// first
+func (h *helpDialog) items(query string) []list.Item {
+	var active, inactive []list.Item
+	for _, entry := range h.entries {
+		if !entry.matches(query) {
+			continue
+		}
+		if entry.active {
+			active = append(active, helpItem{entry: entry})
+		} else {
+			inactive = append(inactive, helpItem{entry: entry})
+		}
+	}
+	items := []list.Item{}
+	if len(active) > 0 {
+		items = append(items, list.HeaderItem(i18n.T("Active now")))
+		items = append(items, active...)
+	}
+	if len(inactive) > 0 {
+		items = append(items, list.HeaderItem(i18n.T("Not available here")))
+		items = append(items, inactive...)
+	}
+	return items
 }
 
 func (h *helpDialog) View() string {
 	t := theme.CurrentTheme()
-	h.commandsComponent.SetBackgroundColor(t.BackgroundPanel())
-	return h.viewport.View()
+	hint := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		PaddingLeft(1).
+		Render(i18n.T("enter run the command  esc close"))
+	return h.search.View() + "\n" + hint
 }
 
 func (h *helpDialog) Render(background string) string {
@@ -65,16 +292,19 @@ type HelpDialog interface {
 	layout.Modal
 }
 
-func NewHelpDialog(app *app.App) HelpDialog {
-	vp := viewport.New(viewport.WithHeight(12))
-	return &helpDialog{
-		app: app,
-		commandsComponent: commandsComponent.New(app,
-			commandsComponent.WithBackground(theme.CurrentTheme().BackgroundPanel()),
-			commandsComponent.WithShowAll(true),
-			commandsComponent.WithKeybinds(true),
-		),
-		modal:    modal.New(modal.WithTitle("Help"), modal.WithMaxWidth(80)),
-		viewport: vp,
+// NewHelpDialog lists the bindings from the commands registry and the
+// focused pane, searchable and split by whether they apply in ctx
+func NewHelpDialog(app *app.App, ctx HelpContext) HelpDialog {
+	h := &helpDialog{
+		entries: helpEntries(app.Commands, app.Config.Keybinds.Leader, ctx),
 	}
+	h.search = NewSearchDialog(i18n.T("Search keys and commands..."), numVisibleHelpRows)
+	h.search.SetWidth(helpDialogWidth)
+	h.search.list.SetEmptyMessage(i18n.T(" No matching keys"))
+	h.search.SetItems(h.items(""))
+	h.modal = modal.New(
+		modal.WithTitle(i18n.T("Help")),
+		modal.WithMaxWidth(helpDialogWidth+4),
+	)
+	return h
 }
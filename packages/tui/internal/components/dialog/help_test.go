@@ -0,0 +1,64 @@
+package dialog
+
+import (
+	"testing"
+
+	"github.com/sst/opencode/internal/commands"
+)
+
+func TestHelpEntries(t *testing.T) {
+	registry := commands.CommandRegistry{
+		commands.SessionInterruptCommand: {
+			Name:        commands.SessionInterruptCommand,
+			Description: "interrupt session",
+			Keybindings: []commands.Keybinding{{Key: "esc"}},
+		},
+		commands.SessionNewCommand: {
+			Name:        commands.SessionNewCommand,
+			Description: "new session",
+			Keybindings: []commands.Keybinding{{Key: "n", RequiresLeader: true}},
+			Trigger:     []string{"new"},
+		},
+		commands.ModelListCommand: {
+			Name:        commands.ModelListCommand,
+			Description: "list models",
+			Keybindings: []commands.Keybinding{{Key: "ctrl+m"}},
+		},
+	}
+	find := func(entries []helpEntry, description string) helpEntry {
+		for _, entry := range entries {
+			if entry.description == description {
+				return entry
+			}
+		}
+		t.Fatalf("no entry %q", description)
+		return helpEntry{}
+	}
+
+	entries := helpEntries(registry, "ctrl+x", HelpContext{})
+	newSession := find(entries, "new session")
+	if !newSession.active || newSession.keys != "ctrl+x n" || newSession.trigger != "/new" {
+		t.Errorf("unexpected entry %+v", newSession)
+	}
+	if find(entries, "interrupt session").active {
+		t.Error("interrupt active while idle")
+	}
+	if find(entries, "send as a prompt").active {
+		t.Error("scratchpad key active without focus")
+	}
+
+	entries = helpEntries(registry, "ctrl+x", HelpContext{Busy: true, ScratchpadFocused: true})
+	if !find(entries, "new session").active {
+		t.Error("leader binding inactive in the scratchpad")
+	}
+	if models := find(entries, "list models"); models.active || models.reason != "scratchpad has focus" {
+		t.Errorf("unexpected entry %+v", models)
+	}
+	if !find(entries, "send as a prompt").active {
+		t.Error("scratchpad key inactive with focus")
+	}
+
+	if !newSession.matches("NEW") || !newSession.matches("session_new") || newSession.matches("model") {
+		t.Error("unexpected query match")
+	}
+}
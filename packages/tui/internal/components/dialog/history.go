@@ -0,0 +1,138 @@
+package dialog
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// CommandHistoryDialog interface for the recent commands dialog
+type CommandHistoryDialog interface {
+	layout.Modal
+}
+
+// commandItem is a list item for a recently run command
+type commandItem struct {
+	command commands.Command
+	key     string
+}
+
+func (c commandItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	description := truncate.StringWithTail(c.command.Description, uint(max(width-len(c.key)-4, 1)), "...")
+	spacer := strings.Repeat(" ", max(width-ansi.StringWidth(description)-len(c.key)-2, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(description + spacer + c.key)
+	}
+	return itemStyle.Render(description+spacer) +
+		baseStyle.Foreground(t.TextMuted()).Render(c.key)
+}
+
+func (c commandItem) Selectable() bool {
+	return true
+}
+
+type commandHistoryDialog struct {
+	width  int
+	height int
+	modal  *modal.Modal
+	items  []commandItem
+	list   list.List[commandItem]
+}
+
+func (c *commandHistoryDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (c *commandHistoryDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		c.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		if msg.String() == "enter" {
+			if _, idx := c.list.GetSelectedItem(); idx >= 0 && idx < len(c.items) {
+				return c, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(commands.ExecuteCommandMsg(c.items[idx].command)),
+				)
+			}
+		}
+	}
+
+	listModel, cmd := c.list.Update(msg)
+	c.list = listModel.(list.List[commandItem])
+	return c, cmd
+}
+
+func (c *commandHistoryDialog) Render(background string) string {
+	return c.modal.Render(c.list.View(), background)
+}
+
+func (c *commandHistoryDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewCommandHistoryDialog creates a dialog for running one of the recent
+// commands again
+func NewCommandHistoryDialog(app *app.App, history commands.History) CommandHistoryDialog {
+	var items []commandItem
+	for _, name := range history.Names() {
+		command, ok := app.Commands[name]
+		if !ok {
+			continue
+		}
+		item := commandItem{command: command}
+		if len(command.Keybindings) > 0 {
+			item.key = app.Keybind(name)
+		} else if command.HasTrigger() {
+			item.key = "/" + command.PrimaryTrigger()
+		}
+		items = append(items, item)
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[commandItem](10),
+		list.WithFallbackMessage[commandItem]("No commands run yet"),
+		list.WithRenderFunc(
+			func(item commandItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item commandItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &commandHistoryDialog{
+		items: items,
+		list:  listComponent,
+		modal: modal.New(
+			modal.WithTitle("Recent Commands"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -0,0 +1,223 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// RunLimitsDialog interface for the run limits dialog
+type RunLimitsDialog interface {
+	layout.Modal
+}
+
+var (
+	toolCallPresets = []float64{0, 10, 25, 50, 100, 200, 500}
+	secondsPresets  = []float64{0, 60, 300, 600, 1800, 3600}
+	costPresets     = []float64{0, 0.5, 1, 2, 5, 10, 25}
+)
+
+// limitItem is a list item for a single adjustable limit
+type limitItem struct {
+	label string
+	value string
+}
+
+func (l limitItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	value := "‹ " + l.value + " ›"
+	spacer := strings.Repeat(" ", max(width-len(l.label)-ansi.StringWidth(value)-2, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+	}
+	return itemStyle.Render(l.label + spacer + value)
+}
+
+func (l limitItem) Selectable() bool {
+	return true
+}
+
+type runLimitsDialog struct {
+	width  int
+	height int
+	modal  *modal.Modal
+	app    *app.App
+	limits app.RunLimits
+	list   list.List[limitItem]
+}
+
+func (r *runLimitsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (r *runLimitsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		r.width = msg.Width
+		r.height = msg.Height
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "left", "h":
+			return r, r.adjust(-1)
+		case "right", "l":
+			return r, r.adjust(1)
+		case "r":
+			r.app.State.RunLimits = nil
+			r.limits = r.app.RunLimits()
+			r.refresh()
+			return r, r.app.SaveState()
+		case "enter":
+			return r, util.CmdHandler(modal.CloseModalMsg{})
+		}
+	}
+
+	listModel, cmd := r.list.Update(msg)
+	r.list = listModel.(list.List[limitItem])
+	return r, cmd
+}
+
+// adjust moves the selected limit to the next preset in the given direction
+func (r *runLimitsDialog) adjust(direction int) tea.Cmd {
+	_, idx := r.list.GetSelectedItem()
+	switch idx {
+	case 0:
+		r.limits.MaxToolCalls = int(nextPreset(toolCallPresets, float64(r.limits.MaxToolCalls), direction))
+	case 1:
+		r.limits.MaxSeconds = int(nextPreset(secondsPresets, float64(r.limits.MaxSeconds), direction))
+	case 2:
+		r.limits.MaxCost = nextPreset(costPresets, r.limits.MaxCost, direction)
+	default:
+		return nil
+	}
+	limits := r.limits
+	r.app.State.RunLimits = &limits
+	r.refresh()
+	return r.app.SaveState()
+}
+
+func (r *runLimitsDialog) refresh() {
+	_, idx := r.list.GetSelectedItem()
+	r.list.SetItems(limitItems(r.limits))
+	r.list.SetSelectedIndex(idx)
+}
+
+func nextPreset(presets []float64, current float64, direction int) float64 {
+	if direction > 0 {
+		for _, preset := range presets {
+			if preset > current {
+				return preset
+			}
+		}
+		return presets[len(presets)-1]
+	}
+	for i := len(presets) - 1; i >= 0; i-- {
+		if presets[i] < current {
+			return presets[i]
+		}
+	}
+	return presets[0]
+}
+
+func limitItems(limits app.RunLimits) []limitItem {
+	toolCalls, duration, cost := "off", "off", "off"
+	if limits.MaxToolCalls > 0 {
+		toolCalls = fmt.Sprintf("%d", limits.MaxToolCalls)
+	}
+	if limits.MaxSeconds > 0 {
+		duration = limits.MaxDuration().String()
+	}
+	if limits.MaxCost > 0 {
+		cost = fmt.Sprintf("$%.2f", limits.MaxCost)
+	}
+	return []limitItem{
+		{label: "Max tool calls", value: toolCalls},
+		{label: "Max run time", value: duration},
+		{label: "Max cost", value: cost},
+	}
+}
+
+func (r *runLimitsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	summary := mutedStyle("Each turn pauses and asks to continue once a limit is reached.")
+	if user, usage := app.CurrentTurn(r.app.Messages); user != nil && r.app.TurnInProgress() {
+		elapsed := time.Since(time.UnixMilli(int64(user.Time.Created))).Round(time.Second)
+		summary = mutedStyle(fmt.Sprintf(
+			"Current turn: %d tool calls, %s, $%.2f",
+			usage.TotalToolCalls(),
+			elapsed,
+			usage.Cost,
+		))
+	}
+
+	helpText := keyStyle("←/→") + mutedStyle(" adjust  ") +
+		keyStyle("r") + mutedStyle(" reset to config  ") +
+		keyStyle("enter") + mutedStyle(" close")
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	content := strings.Join([]string{summary, "", r.list.View(), helpSection}, "\n")
+	return r.modal.Render(content, background)
+}
+
+func (r *runLimitsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewRunLimitsDialog creates a dialog for adjusting the per-turn run limits
+func NewRunLimitsDialog(app *app.App) RunLimitsDialog {
+	limits := app.RunLimits()
+
+	listComponent := list.NewListComponent(
+		list.WithItems(limitItems(limits)),
+		list.WithMaxVisibleHeight[limitItem](3),
+		list.WithFallbackMessage[limitItem](""),
+		list.WithRenderFunc(
+			func(item limitItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item limitItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &runLimitsDialog{
+		app:    app,
+		limits: limits,
+		list:   listComponent,
+		modal: modal.New(
+			modal.WithTitle("Run Limits"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -3,11 +3,16 @@ package dialog
 import (
 	"context"
 	"fmt"
+	"math"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/lithammer/fuzzysearch/fuzzy"
 	opencode "github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
@@ -72,8 +77,9 @@ func (m modelItem) Render(
 
 	modelPart := itemStyle.Render(m.model.Model.Name)
 	providerPart := providerStyle.Render(fmt.Sprintf(" %s", m.model.Provider.Name))
+	detailsPart := providerStyle.Faint(true).Render(" " + modelDetails(m.model.Model))
 
-	combinedText := modelPart + providerPart
+	combinedText := modelPart + providerPart + detailsPart
 	return baseStyle.
 		Background(t.BackgroundPanel()).
 		PaddingLeft(1).
@@ -153,16 +159,23 @@ func (m *modelDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *modelDialog) View() string {
-	return m.searchDialog.View()
+	t := theme.CurrentTheme()
+	hint := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		Width(m.dialogWidth).
+		PaddingLeft(1).
+		Render(ansi.Truncate("filter: provider:name context:128k vision price:"+strings.Join(priceTiers, "|"), m.dialogWidth-1, "…"))
+	return m.searchDialog.View() + "\n" + hint
 }
 
 func (m *modelDialog) calculateOptimalWidth(models []ModelWithProvider) int {
 	maxWidth := minDialogWidth
 
 	for _, model := range models {
-		// Calculate the width needed for this item: "ModelName (ProviderName)"
-		// Add 4 for the parentheses, space, and some padding
-		itemWidth := len(model.Model.Name) + len(model.Provider.Name) + 4
+		// Calculate the width needed for this item: "ModelName ProviderName details"
+		// Add 4 for the spaces and some padding
+		itemWidth := len(model.Model.Name) + len(model.Provider.Name) + len(modelDetails(model.Model)) + 4
 		if itemWidth > maxWidth {
 			maxWidth = itemWidth
 		}
@@ -262,19 +275,31 @@ func (m *modelDialog) getModelUsageTime(providerID, modelID string) time.Time {
 	return time.Time{}
 }
 
-// buildDisplayList creates the list items based on search query
+// buildDisplayList creates the list items based on search query, applying
+// the filters it contains first
 func (m *modelDialog) buildDisplayList(query string) []list.Item {
-	if query != "" {
+	filter, text := parseModelQuery(query)
+	models := m.allModels
+	if filter != (modelFilter{}) {
+		models = []ModelWithProvider{}
+		for _, model := range m.allModels {
+			if filter.matches(model) {
+				models = append(models, model)
+			}
+		}
+	}
+
+	if text != "" {
 		// Search mode: use fuzzy matching
-		return m.buildSearchResults(query)
+		return m.buildSearchResults(models, text)
 	} else {
 		// Grouped mode: show Recent section and provider groups
-		return m.buildGroupedResults()
+		return m.buildGroupedResults(models)
 	}
 }
 
 // buildSearchResults creates a flat list of search results using fuzzy matching
-func (m *modelDialog) buildSearchResults(query string) []list.Item {
+func (m *modelDialog) buildSearchResults(models []ModelWithProvider, query string) []list.Item {
 	type modelMatch struct {
 		model ModelWithProvider
 		score int
@@ -284,7 +309,7 @@ func (m *modelDialog) buildSearchResults(query string) []list.Item {
 	modelMap := make(map[string]ModelWithProvider)
 
 	// Create search strings and perform fuzzy matching
-	for _, model := range m.allModels {
+	for _, model := range models {
 		searchStr := fmt.Sprintf("%s %s", model.Model.Name, model.Provider.Name)
 		modelNames = append(modelNames, searchStr)
 		modelMap[searchStr] = model
@@ -315,11 +340,11 @@ func (m *modelDialog) buildSearchResults(query string) []list.Item {
 }
 
 // buildGroupedResults creates a grouped list with Recent section and provider groups
-func (m *modelDialog) buildGroupedResults() []list.Item {
+func (m *modelDialog) buildGroupedResults(models []ModelWithProvider) []list.Item {
 	var items []list.Item
 
 	// Add Recent section
-	recentModels := m.getRecentModels(maxRecentModels)
+	recentModels := recentModels(m.app.State.RecentlyUsedModels, models, maxRecentModels)
 	if len(recentModels) > 0 {
 		items = append(items, list.HeaderItem("Recent"))
 		for _, model := range recentModels {
@@ -329,7 +354,7 @@ func (m *modelDialog) buildGroupedResults() []list.Item {
 
 	// Group models by provider
 	providerGroups := make(map[string][]ModelWithProvider)
-	for _, model := range m.allModels {
+	for _, model := range models {
 		providerName := model.Provider.Name
 		providerGroups[providerName] = append(providerGroups[providerName], model)
 	}
@@ -390,24 +415,138 @@ func (m *modelDialog) buildGroupedResults() []list.Item {
 
 // getRecentModels returns the most recently used models
 func (m *modelDialog) getRecentModels(limit int) []ModelWithProvider {
-	var recentModels []ModelWithProvider
+	return recentModels(m.app.State.RecentlyUsedModels, m.allModels, limit)
+}
 
-	// Get recent models from app state
-	for _, usage := range m.app.State.RecentlyUsedModels {
-		if len(recentModels) >= limit {
+// recentModels returns the models among the given ones in the order they were
+// last used, as recorded by State.UpdateModelUsage
+func recentModels(usages []app.ModelUsage, models []ModelWithProvider, limit int) []ModelWithProvider {
+	var recent []ModelWithProvider
+	for _, usage := range usages {
+		if len(recent) >= limit {
 			break
 		}
 
 		// Find the corresponding model
-		for _, model := range m.allModels {
+		for _, model := range models {
 			if model.Provider.ID == usage.ProviderID && model.Model.ID == usage.ModelID {
-				recentModels = append(recentModels, model)
+				recent = append(recent, model)
 				break
 			}
 		}
 	}
+	return recent
+}
+
+// priceTiers group models by their input price per million tokens
+var priceTiers = []string{"free", "low", "mid", "high"}
+
+// priceTier returns the tier of the model's input price: free, low up to $1,
+// mid up to $5 and high above that, per million tokens
+func priceTier(model opencode.Model) string {
+	switch price := model.Cost.Input; {
+	case price <= 0:
+		return "free"
+	case price <= 1:
+		return "low"
+	case price <= 5:
+		return "mid"
+	}
+	return "high"
+}
+
+// modelDetails summarizes the context window, vision support and price of a
+// model, e.g. "200k vision $3/$15"
+func modelDetails(model opencode.Model) string {
+	details := []string{}
+	if model.Limit.Context > 0 {
+		details = append(details, formatContextSize(model.Limit.Context))
+	}
+	if model.Attachment {
+		details = append(details, "vision")
+	}
+	if model.Cost.Input > 0 || model.Cost.Output > 0 {
+		details = append(details, fmt.Sprintf("$%g/$%g", model.Cost.Input, model.Cost.Output))
+	} else {
+		details = append(details, "free")
+	}
+	return strings.Join(details, " ")
+}
+
+func formatContextSize(tokens float64) string {
+	switch {
+	case tokens >= 1_000_000:
+		return fmt.Sprintf("%gm", math.Round(tokens/100_000)/10)
+	case tokens >= 1_000:
+		return fmt.Sprintf("%gk", math.Round(tokens/1_000))
+	}
+	return fmt.Sprintf("%g", tokens)
+}
+
+// modelFilter narrows the model list by capabilities, written in the search
+// query as provider:<name>, context:<min size>, vision and price:<tier>
+type modelFilter struct {
+	provider   string
+	minContext float64
+	vision     bool
+	price      string
+}
+
+// parseModelQuery splits the filters out of a search query, returning them
+// and the remaining text to fuzzy match
+func parseModelQuery(query string) (modelFilter, string) {
+	var filter modelFilter
+	text := []string{}
+	for _, field := range strings.Fields(query) {
+		name, value, _ := strings.Cut(strings.ToLower(field), ":")
+		switch {
+		case name == "provider" && value != "":
+			filter.provider = value
+		case name == "context" && value != "":
+			if size, ok := parseContextSize(value); ok {
+				filter.minContext = size
+			} else {
+				text = append(text, field)
+			}
+		case name == "vision" && value == "":
+			filter.vision = true
+		case name == "price" && slices.Contains(priceTiers, value):
+			filter.price = value
+		default:
+			text = append(text, field)
+		}
+	}
+	return filter, strings.Join(text, " ")
+}
+
+// parseContextSize parses sizes like 128k, 1m or 32000
+func parseContextSize(value string) (float64, bool) {
+	multiplier := float64(1)
+	if number, ok := strings.CutSuffix(value, "k"); ok {
+		value, multiplier = number, 1_000
+	} else if number, ok := strings.CutSuffix(value, "m"); ok {
+		value, multiplier = number, 1_000_000
+	}
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size * multiplier, true
+}
 
-	return recentModels
+func (f modelFilter) matches(model ModelWithProvider) bool {
+	if f.provider != "" &&
+		!strings.HasPrefix(strings.ToLower(model.Provider.ID), f.provider) &&
+		!strings.HasPrefix(strings.ToLower(model.Provider.Name), f.provider) {
+		return false
+	}
+	if f.minContext > 0 && model.Model.Limit.Context < f.minContext {
+		return false
+	}
+	if f.vision && !model.Model.Attachment {
+		return false
+	}
+	return f.price == "" || priceTier(model.Model) == f.price
 }
 
 func (m *modelDialog) isModelInRecentSection(model ModelWithProvider, index int) bool {
@@ -0,0 +1,46 @@
+package dialog
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestParseModelQuery(t *testing.T) {
+	filter, text := parseModelQuery("sonnet provider:Anthropic context:128k vision price:mid context:lots")
+	want := modelFilter{provider: "anthropic", minContext: 128_000, vision: true, price: "mid"}
+	if filter != want {
+		t.Errorf("filter = %+v, want %+v", filter, want)
+	}
+	if text != "sonnet context:lots" {
+		t.Errorf("text = %q, want %q", text, "sonnet context:lots")
+	}
+}
+
+func TestModelFilterMatches(t *testing.T) {
+	model := ModelWithProvider{
+		Provider: opencode.Provider{ID: "anthropic", Name: "Anthropic"},
+		Model: opencode.Model{
+			Attachment: true,
+			Cost:       opencode.ModelCost{Input: 3, Output: 15},
+			Limit:      opencode.ModelLimit{Context: 200_000},
+		},
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"provider:anth", true},
+		{"provider:openai", false},
+		{"context:200k", true},
+		{"context:1m", false},
+		{"vision price:mid", true},
+		{"price:low", false},
+	}
+	for _, tt := range tests {
+		filter, _ := parseModelQuery(tt.query)
+		if got := filter.matches(model); got != tt.want {
+			t.Errorf("%q matches = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/i18n"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// NotificationSelectedMsg asks to run the actions of a notification
+type NotificationSelectedMsg struct {
+	Toast toast.Toast
+}
+
+// NotificationsDialog lists the toasts shown lately, so that ones that
+// faded can still be read and acted on
+type NotificationsDialog interface {
+	layout.Modal
+}
+
+// notificationItem is a list item for a toast
+type notificationItem struct {
+	toast toast.Toast
+}
+
+// text is the toast's title and message on one line
+func (n notificationItem) text() string {
+	text := strings.Join(strings.Fields(n.toast.Message), " ")
+	if n.toast.Title != nil && *n.toast.Title != "" {
+		text = *n.toast.Title + ": " + text
+	}
+	if n.toast.Count > 1 {
+		text += fmt.Sprintf(" (×%d)", n.toast.Count)
+	}
+	return text
+}
+
+// detail is the time of the toast and its actions
+func (n notificationItem) detail() string {
+	detail := ""
+	for _, action := range n.toast.Actions {
+		detail += "[" + action.Label + "] "
+	}
+	return detail + n.toast.CreatedAt.Format("15:04")
+}
+
+func (n notificationItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	icon := n.toast.Icon()
+	if icon != "" {
+		icon += " "
+	}
+	detail := n.detail()
+	text := truncate.StringWithTail(
+		n.text(),
+		uint(max(width-ansi.StringWidth(icon)-ansi.StringWidth(detail)-4, 1)),
+		"...",
+	)
+	spacer := strings.Repeat(" ", max(width-ansi.StringWidth(icon+text)-ansi.StringWidth(detail)-2, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(icon + text + spacer + detail)
+	}
+	return itemStyle.Render(baseStyle.Foreground(n.toast.Color).Render(icon)+text+spacer) +
+		baseStyle.Foreground(t.TextMuted()).Render(detail)
+}
+
+func (n notificationItem) Selectable() bool {
+	return true
+}
+
+type notificationsDialog struct {
+	app   *app.App
+	modal *modal.Modal
+	items []notificationItem
+	list  list.List[notificationItem]
+}
+
+func (n *notificationsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (n *notificationsDialog) selected() (notificationItem, bool) {
+	if _, idx := n.list.GetSelectedItem(); idx >= 0 && idx < len(n.items) {
+		return n.items[idx], true
+	}
+	return notificationItem{}, false
+}
+
+func (n *notificationsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		n.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			if item, ok := n.selected(); ok && len(item.toast.Actions) > 0 {
+				return n, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(NotificationSelectedMsg{Toast: item.toast}),
+				)
+			}
+			return n, nil
+		case "y":
+			if item, ok := n.selected(); ok {
+				return n, tea.Sequence(
+					n.app.SetClipboard(item.text()),
+					toast.NewSuccessToast(i18n.T("Copied to clipboard")),
+				)
+			}
+			return n, nil
+		}
+	}
+
+	listModel, cmd := n.list.Update(msg)
+	n.list = listModel.(list.List[notificationItem])
+	return n, cmd
+}
+
+func (n *notificationsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+	width := layout.Current.Container.Width - 14
+
+	sections := []string{n.list.View()}
+
+	// the whole of the selected notification, which the list cuts short
+	if item, ok := n.selected(); ok && ansi.StringWidth(item.text()) > width-ansi.StringWidth(item.detail())-6 {
+		full := styles.NewStyle().
+			Foreground(t.Text()).
+			Background(t.BackgroundPanel()).
+			PaddingLeft(1).
+			PaddingTop(1).
+			Width(width).
+			Render(item.text())
+		sections = append(sections, full)
+	}
+
+	helpText := keyStyle("enter") + mutedStyle(" "+i18n.T("run action")+"  ") +
+		keyStyle("y") + mutedStyle(" "+i18n.T("copy")+"  ") +
+		keyStyle("esc") + mutedStyle(" "+i18n.T("close"))
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      width,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	sections = append(sections, styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection))
+
+	return n.modal.Render(strings.Join(sections, "\n"), background)
+}
+
+func (n *notificationsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewNotificationsDialog creates a dialog listing the toasts, the most
+// recent first
+func NewNotificationsDialog(app *app.App, toasts []toast.Toast) NotificationsDialog {
+	var items []notificationItem
+	for _, t := range toasts {
+		items = append(items, notificationItem{toast: t})
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[notificationItem](12),
+		list.WithFallbackMessage[notificationItem](i18n.T("No notifications yet")),
+		list.WithRenderFunc(
+			func(item notificationItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item notificationItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &notificationsDialog{
+		app:   app,
+		items: items,
+		list:  listComponent,
+		modal: modal.New(
+			modal.WithTitle(i18n.T("Notifications")),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
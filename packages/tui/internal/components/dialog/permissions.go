@@ -0,0 +1,404 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	numVisiblePermissionRules = 10
+	permissionsDialogWidth    = 64
+)
+
+var permissionActions = []opencode.PermissionRuleAction{
+	opencode.PermissionRuleActionAllow,
+	opencode.PermissionRuleActionAsk,
+	opencode.PermissionRuleActionDeny,
+}
+
+var permissionScopes = []opencode.PermissionRuleScope{
+	opencode.PermissionRuleScopeSession,
+	opencode.PermissionRuleScopeProject,
+	opencode.PermissionRuleScopeGlobal,
+}
+
+// PermissionsDialog lists the standing permission rules, for adding, editing
+// and revoking them
+type PermissionsDialog interface {
+	layout.Modal
+}
+
+type permissionsDialog struct {
+	app    *app.App
+	rules  []opencode.PermissionRule
+	search *SearchDialog
+	modal  *modal.Modal
+	// form is set while a rule is added or edited
+	form *permissionRuleForm
+}
+
+type permissionRuleItem struct {
+	rule opencode.PermissionRule
+	// add is set for the item adding a rule
+	add bool
+}
+
+func (p permissionRuleItem) Render(selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+	mutedStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+
+	if p.add {
+		return baseStyle.
+			Background(t.BackgroundPanel()).
+			PaddingLeft(1).
+			Render(itemStyle.Render("+ add a rule"))
+	}
+
+	actionColor := t.Success()
+	switch p.rule.Action {
+	case opencode.PermissionRuleActionAsk:
+		actionColor = t.Warning()
+	case opencode.PermissionRuleActionDeny:
+		actionColor = t.Error()
+	}
+	action := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(actionColor).
+		Width(6).
+		Render(string(p.rule.Action))
+
+	text := action + itemStyle.Render(p.rule.Tool)
+	if p.rule.Pattern != "" {
+		text += mutedStyle.Render(" " + p.rule.Pattern)
+	}
+	text += mutedStyle.Faint(true).Render(" · " + string(p.rule.Scope))
+	return baseStyle.
+		Background(t.BackgroundPanel()).
+		PaddingLeft(1).
+		Render(text)
+}
+
+func (p permissionRuleItem) Selectable() bool {
+	return true
+}
+
+func (d *permissionsDialog) Init() tea.Cmd {
+	return d.search.Init()
+}
+
+func (d *permissionsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(app.PermissionRulesMsg); ok {
+		d.rules = msg.Rules
+		d.search.SetItems(d.items(d.search.GetQuery()))
+		return d, nil
+	}
+
+	if d.form != nil {
+		return d, d.updateForm(msg)
+	}
+
+	switch msg := msg.(type) {
+	case SearchSelectionMsg:
+		item, ok := msg.Item.(permissionRuleItem)
+		if !ok {
+			return d, nil
+		}
+		if item.add {
+			d.form = newPermissionRuleForm(nil)
+		} else {
+			d.form = newPermissionRuleForm(&item.rule)
+		}
+		d.search.Blur()
+		return d, textinput.Blink
+	case SearchCancelledMsg:
+		return d, util.CmdHandler(modal.CloseModalMsg{})
+	case SearchRemoveItemMsg:
+		item, ok := msg.Item.(permissionRuleItem)
+		if !ok || item.add {
+			return d, nil
+		}
+		return d, d.app.RemovePermissionRule(item.rule)
+	case SearchQueryChangedMsg:
+		d.search.SetItems(d.items(msg.Query))
+		return d, nil
+	}
+
+	updated, cmd := d.search.Update(msg)
+	d.search = updated.(*SearchDialog)
+	return d, cmd
+}
+
+func (d *permissionsDialog) updateForm(msg tea.Msg) tea.Cmd {
+	if key, ok := msg.(tea.KeyPressMsg); ok {
+		switch key.String() {
+		case "esc":
+			d.form = nil
+			d.search.Focus()
+			return nil
+		case "ctrl+s":
+			return d.saveForm()
+		case "enter":
+			if d.form.focus == permissionFieldScope {
+				return d.saveForm()
+			}
+		}
+	}
+	return d.form.update(msg)
+}
+
+func (d *permissionsDialog) saveForm() tea.Cmd {
+	rule, err := d.form.rule()
+	if err != nil {
+		d.form.err = err.Error()
+		return nil
+	}
+	previous := d.form.previous
+	d.form = nil
+	d.search.Focus()
+	return d.app.SetPermissionRule(rule, previous)
+}
+
+// items lists the rules matching the query, followed by the item adding one
+func (d *permissionsDialog) items(query string) []list.Item {
+	query = strings.TrimSpace(query)
+	items := []list.Item{}
+	for _, rule := range d.rules {
+		text := strings.Join([]string{string(rule.Action), rule.Tool, rule.Pattern, string(rule.Scope)}, " ")
+		if query != "" && !fuzzy.MatchFold(query, text) {
+			continue
+		}
+		items = append(items, permissionRuleItem{rule: rule})
+	}
+	return append(items, permissionRuleItem{add: true})
+}
+
+func (d *permissionsDialog) View() string {
+	t := theme.CurrentTheme()
+	hintStyle := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		PaddingLeft(1)
+	if d.form != nil {
+		return d.form.view() + "\n\n" +
+			hintStyle.Render("tab next field  space change  ctrl+s save  esc back")
+	}
+	return d.search.View() + "\n" + hintStyle.Render("enter edit  ctrl+x revoke")
+}
+
+func (d *permissionsDialog) Render(background string) string {
+	return d.modal.Render(d.View(), background)
+}
+
+func (d *permissionsDialog) Close() tea.Cmd {
+	return nil
+}
+
+type permissionField int
+
+const (
+	permissionFieldTool permissionField = iota
+	permissionFieldPattern
+	permissionFieldAction
+	permissionFieldScope
+	permissionFieldCount
+)
+
+type permissionRuleForm struct {
+	previous *opencode.PermissionRule
+	focus    permissionField
+	tool     textinput.Model
+	pattern  textinput.Model
+	action   int
+	scope    int
+	err      string
+}
+
+func newPermissionRuleForm(rule *opencode.PermissionRule) *permissionRuleForm {
+	existing := opencode.PermissionRule{
+		Action: opencode.PermissionRuleActionAllow,
+		Scope:  opencode.PermissionRuleScopeProject,
+	}
+	if rule != nil {
+		existing = *rule
+	}
+	f := &permissionRuleForm{
+		previous: rule,
+		tool:     newFormInput("bash, edit, webfetch, mcp_*...", existing.Tool),
+		pattern:  newFormInput("command pattern such as git *, empty for any", existing.Pattern),
+	}
+	for i, action := range permissionActions {
+		if action == existing.Action {
+			f.action = i
+		}
+	}
+	for i, scope := range permissionScopes {
+		if scope == existing.Scope {
+			f.scope = i
+		}
+	}
+	f.tool.SetWidth(permissionsDialogWidth - 16)
+	f.pattern.SetWidth(permissionsDialogWidth - 16)
+	f.setFocus(permissionFieldTool)
+	return f
+}
+
+func (f *permissionRuleForm) setFocus(field permissionField) tea.Cmd {
+	f.focus = field
+	f.tool.Blur()
+	f.pattern.Blur()
+	switch field {
+	case permissionFieldTool:
+		return f.tool.Focus()
+	case permissionFieldPattern:
+		return f.pattern.Focus()
+	}
+	return nil
+}
+
+func (f *permissionRuleForm) update(msg tea.Msg) tea.Cmd {
+	if key, ok := msg.(tea.KeyPressMsg); ok {
+		switch key.String() {
+		case "tab", "enter":
+			return f.setFocus((f.focus + 1) % permissionFieldCount)
+		case "shift+tab":
+			return f.setFocus((f.focus + permissionFieldCount - 1) % permissionFieldCount)
+		}
+		switch f.focus {
+		case permissionFieldAction:
+			f.action = cycle(f.action, len(permissionActions), key.String())
+			return nil
+		case permissionFieldScope:
+			f.scope = cycle(f.scope, len(permissionScopes), key.String())
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	switch f.focus {
+	case permissionFieldTool:
+		f.tool, cmd = f.tool.Update(msg)
+	case permissionFieldPattern:
+		f.pattern, cmd = f.pattern.Update(msg)
+	}
+	return cmd
+}
+
+// cycle moves through n choices with the arrow keys and space
+func cycle(index, n int, key string) int {
+	switch key {
+	case "left", "h":
+		return (index + n - 1) % n
+	case "right", "l", "space", " ":
+		return (index + 1) % n
+	}
+	return index
+}
+
+func (f *permissionRuleForm) rule() (opencode.PermissionRule, error) {
+	return permissionRule(
+		f.tool.Value(),
+		f.pattern.Value(),
+		permissionActions[f.action],
+		permissionScopes[f.scope],
+	)
+}
+
+// permissionRule validates the form's fields. The permission config only
+// takes patterns for bash, and the session only remembers approvals.
+func permissionRule(
+	tool, pattern string,
+	action opencode.PermissionRuleAction,
+	scope opencode.PermissionRuleScope,
+) (opencode.PermissionRule, error) {
+	tool = strings.TrimSpace(tool)
+	pattern = strings.TrimSpace(pattern)
+	if tool == "" || strings.ContainsAny(tool, " \t") {
+		return opencode.PermissionRule{}, errors.New("tool: a single tool name or pattern")
+	}
+	if scope == opencode.PermissionRuleScopeSession && action != opencode.PermissionRuleActionAllow {
+		return opencode.PermissionRule{}, errors.New("session rules can only allow")
+	}
+	if pattern != "" && tool != "bash" && scope != opencode.PermissionRuleScopeSession {
+		return opencode.PermissionRule{}, errors.New("pattern: only bash rules take a pattern in the config")
+	}
+	return opencode.PermissionRule{
+		Tool:    tool,
+		Pattern: pattern,
+		Action:  action,
+		Scope:   scope,
+	}, nil
+}
+
+func (f *permissionRuleForm) view() string {
+	t := theme.CurrentTheme()
+	bg := t.BackgroundPanel()
+	labelStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(bg).Width(10).PaddingLeft(1)
+	focusedLabelStyle := labelStyle.Foreground(t.Primary()).Bold(true)
+	textStyle := styles.NewStyle().Foreground(t.Text()).Background(bg)
+	selectedStyle := styles.NewStyle().Foreground(t.Primary()).Background(bg).Bold(true)
+
+	label := func(field permissionField, text string) string {
+		if f.focus == field {
+			return focusedLabelStyle.Render(text)
+		}
+		return labelStyle.Render(text)
+	}
+	choice := func(field permissionField, value string) string {
+		if f.focus == field {
+			return selectedStyle.Render("‹ " + value + " ›")
+		}
+		return textStyle.Render(value)
+	}
+
+	lines := []string{
+		label(permissionFieldTool, "Tool") + f.tool.View(),
+		label(permissionFieldPattern, "Pattern") + f.pattern.View(),
+		label(permissionFieldAction, "Action") + choice(permissionFieldAction, string(permissionActions[f.action])),
+		label(permissionFieldScope, "Scope") + choice(permissionFieldScope, string(permissionScopes[f.scope])),
+	}
+	if f.err != "" {
+		lines = append(lines, "", styles.NewStyle().Foreground(t.Error()).Background(bg).PaddingLeft(1).Render(f.err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewPermissionsDialog lists the standing permission rules
+func NewPermissionsDialog(app *app.App) PermissionsDialog {
+	rules, err := app.ListPermissionRules(context.Background())
+	if err != nil {
+		slog.Error("Failed to list permission rules", "error", err)
+	}
+	d := &permissionsDialog{
+		app:   app,
+		rules: rules,
+	}
+	d.search = NewSearchDialog("Search rules...", numVisiblePermissionRules)
+	d.search.SetWidth(permissionsDialogWidth)
+	d.search.SetItems(d.items(""))
+	d.modal = modal.New(
+		modal.WithTitle("Permission Rules"),
+		modal.WithMaxWidth(permissionsDialogWidth+4),
+	)
+	return d
+}
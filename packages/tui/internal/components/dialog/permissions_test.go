@@ -0,0 +1,37 @@
+package dialog
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestPermissionRule(t *testing.T) {
+	rule, err := permissionRule(" bash ", " git * ", opencode.PermissionRuleActionAllow, opencode.PermissionRuleScopeProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Tool != "bash" || rule.Pattern != "git *" {
+		t.Errorf("unexpected rule %+v", rule)
+	}
+
+	if _, err := permissionRule("edit", "src/*", opencode.PermissionRuleActionAllow, opencode.PermissionRuleScopeSession); err != nil {
+		t.Errorf("session approval with a pattern rejected: %v", err)
+	}
+
+	invalid := []struct {
+		tool, pattern string
+		action        opencode.PermissionRuleAction
+		scope         opencode.PermissionRuleScope
+	}{
+		{"", "", opencode.PermissionRuleActionAllow, opencode.PermissionRuleScopeGlobal},
+		{"web fetch", "", opencode.PermissionRuleActionAllow, opencode.PermissionRuleScopeGlobal},
+		{"bash", "rm *", opencode.PermissionRuleActionDeny, opencode.PermissionRuleScopeSession},
+		{"edit", "src/*", opencode.PermissionRuleActionAsk, opencode.PermissionRuleScopeProject},
+	}
+	for _, tc := range invalid {
+		if _, err := permissionRule(tc.tool, tc.pattern, tc.action, tc.scope); err == nil {
+			t.Errorf("permissionRule(%q, %q, %s, %s) accepted", tc.tool, tc.pattern, tc.action, tc.scope)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package dialog
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// ProjectSelectedMsg is sent when a recent project is picked to open
+type ProjectSelectedMsg struct {
+	Project app.RecentProject
+}
+
+// ProjectsDialog interface for the project launcher dialog
+type ProjectsDialog interface {
+	layout.Modal
+}
+
+// projectItem is a list item for a recent project
+type projectItem struct {
+	project app.RecentProject
+	current bool
+}
+
+func (p projectItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	name := filepath.Base(p.project.Root)
+	if p.current {
+		name += " (current)"
+	}
+	when := p.project.LastUsed.Format("Jan 2 15:04")
+	title := p.project.SessionTitle
+	if title == "" {
+		title = "no sessions"
+	}
+	title = truncate.StringWithTail(
+		title,
+		uint(max(width-ansi.StringWidth(name)-len(when)-6, 1)),
+		"...",
+	)
+	spacer := strings.Repeat(" ", max(width-ansi.StringWidth(name)-ansi.StringWidth(title)-len(when)-4, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(name + "  " + title + spacer + when)
+	}
+	mutedStyle := baseStyle.Foreground(t.TextMuted())
+	return itemStyle.Render(name+"  ") +
+		mutedStyle.Render(title+spacer+when)
+}
+
+func (p projectItem) Selectable() bool {
+	return true
+}
+
+type projectsDialog struct {
+	width    int
+	height   int
+	modal    *modal.Modal
+	projects []app.RecentProject
+	list     list.List[projectItem]
+}
+
+func (p *projectsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (p *projectsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+		p.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			if _, idx := p.list.GetSelectedItem(); idx >= 0 && idx < len(p.projects) {
+				return p, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(ProjectSelectedMsg{Project: p.projects[idx]}),
+				)
+			}
+		}
+	}
+
+	listModel, cmd := p.list.Update(msg)
+	p.list = listModel.(list.List[projectItem])
+	return p, cmd
+}
+
+func (p *projectsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	helpText := keyStyle("enter") + mutedStyle(" open  ") +
+		keyStyle("esc") + mutedStyle(" stay here")
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	content := strings.Join([]string{p.list.View(), helpSection}, "\n")
+	return p.modal.Render(content, background)
+}
+
+func (p *projectsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewProjectsDialog creates the launcher listing recent projects with their
+// latest sessions
+func NewProjectsDialog(app *app.App) ProjectsDialog {
+	projects := app.State.RecentProjects
+
+	var items []projectItem
+	for _, project := range projects {
+		items = append(items, projectItem{
+			project: project,
+			current: project.Root == app.Info.Path.Root,
+		})
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[projectItem](10),
+		list.WithFallbackMessage[projectItem]("No recent projects"),
+		list.WithRenderFunc(
+			func(item projectItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item projectItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &projectsDialog{
+		projects: projects,
+		list:     listComponent,
+		modal: modal.New(
+			modal.WithTitle("Recent Projects"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -0,0 +1,237 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/git"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// ReviewFileSelectedMsg is sent when a changed file is picked for reading its
+// diff in the file viewer
+type ReviewFileSelectedMsg struct {
+	Change opencode.SessionChange
+}
+
+// ReviewDialog lists every file the session changed, each can be opened,
+// accepted by staging it, or reverted to how it was before the session
+type ReviewDialog interface {
+	layout.Modal
+}
+
+// reviewChangesMsg carries the changes read when the dialog opened
+type reviewChangesMsg struct {
+	changes []opencode.SessionChange
+	err     error
+}
+
+// reviewItem is a list item for a changed file
+type reviewItem struct {
+	change opencode.SessionChange
+}
+
+func (r reviewItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	added := fmt.Sprintf("+%d", int(r.change.Additions))
+	removed := fmt.Sprintf("-%d", int(r.change.Deletions))
+	counts := len(added) + len(removed) + 1
+	path := truncate.StringWithTail(r.change.Path, uint(max(width-counts-4, 1)), "...")
+	spacer := strings.Repeat(" ", max(width-ansi.StringWidth(path)-counts-2, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(path + spacer + added + " " + removed)
+	}
+	return itemStyle.Render(path+spacer) +
+		baseStyle.Foreground(t.Success()).Render(added) +
+		baseStyle.Render(" ") +
+		baseStyle.Foreground(t.Error()).Render(removed)
+}
+
+func (r reviewItem) Selectable() bool {
+	return true
+}
+
+type reviewDialog struct {
+	app      *app.App
+	modal    *modal.Modal
+	changes  []opencode.SessionChange
+	list     list.List[reviewItem]
+	selected string
+	loaded   bool
+	err      string
+}
+
+func (r *reviewDialog) Init() tea.Cmd {
+	client := r.app.Client
+	sessionID := r.app.Session.ID
+	return func() tea.Msg {
+		changes, err := client.Session.Changes(context.Background(), sessionID)
+		if err != nil {
+			return reviewChangesMsg{err: err}
+		}
+		return reviewChangesMsg{changes: *changes}
+	}
+}
+
+func (r *reviewDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case reviewChangesMsg:
+		r.loaded = true
+		if msg.err != nil {
+			r.err = "Failed to read the session's changes: " + msg.err.Error()
+			return r, nil
+		}
+		r.changes = msg.changes
+		r.list.SetItems(reviewItems(r.changes))
+		for i, change := range r.changes {
+			if change.Path == r.selected {
+				r.list.SetSelectedIndex(i)
+			}
+		}
+		return r, nil
+	case tea.WindowSizeMsg:
+		r.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		_, idx := r.list.GetSelectedItem()
+		if idx < 0 || idx >= len(r.changes) {
+			break
+		}
+		change := r.changes[idx]
+		switch msg.String() {
+		case "enter":
+			return r, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(ReviewFileSelectedMsg{Change: change}),
+			)
+		case "a":
+			if r.app.ReadOnly {
+				return r, toast.NewInfoToast(app.ReadOnlyMessage)
+			}
+			if err := git.Stage(r.app.Info.Path.Cwd, change.Path); err != nil {
+				return r, toast.NewErrorToast("Failed to stage " + change.Path + ": " + err.Error())
+			}
+			r.remove(idx)
+			return r, toast.NewSuccessToast("Staged " + change.Path)
+		case "r":
+			if r.app.ReadOnly {
+				return r, toast.NewInfoToast(app.ReadOnlyMessage)
+			}
+			_, err := r.app.Client.Session.RevertFile(
+				context.Background(),
+				r.app.Session.ID,
+				opencode.SessionRevertFileParams{Path: opencode.F(change.Path)},
+			)
+			if err != nil {
+				return r, toast.NewErrorToast("Failed to revert " + change.Path + ": " + err.Error())
+			}
+			r.remove(idx)
+			return r, toast.NewSuccessToast("Reverted " + change.Path)
+		}
+	}
+
+	listModel, cmd := r.list.Update(msg)
+	r.list = listModel.(list.List[reviewItem])
+	return r, cmd
+}
+
+// remove drops a file that was accepted or reverted from the list
+func (r *reviewDialog) remove(idx int) {
+	r.changes = append(r.changes[:idx], r.changes[idx+1:]...)
+	r.list.SetItems(reviewItems(r.changes))
+	r.list.SetSelectedIndex(min(idx, len(r.changes)-1))
+}
+
+func reviewItems(changes []opencode.SessionChange) []reviewItem {
+	var items []reviewItem
+	for _, change := range changes {
+		items = append(items, reviewItem{change: change})
+	}
+	return items
+}
+
+func (r *reviewDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	var body string
+	switch {
+	case r.err != "":
+		body = styles.NewStyle().Foreground(t.Error()).Background(t.BackgroundPanel()).PaddingLeft(1).Render(r.err)
+	case !r.loaded:
+		body = mutedStyle(" Reading the session's changes...")
+	default:
+		body = r.list.View()
+	}
+
+	helpText := keyStyle("enter") + mutedStyle(" view diff  ") +
+		keyStyle("a") + mutedStyle(" accept  ") +
+		keyStyle("r") + mutedStyle(" revert")
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	content := strings.Join([]string{body, helpSection}, "\n")
+	return r.modal.Render(content, background)
+}
+
+func (r *reviewDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewReviewDialog creates a dialog for reviewing the session's changes,
+// selected is the path of the file to start on
+func NewReviewDialog(app *app.App, selected string) ReviewDialog {
+	listComponent := list.NewListComponent(
+		list.WithItems([]reviewItem{}),
+		list.WithMaxVisibleHeight[reviewItem](12),
+		list.WithFallbackMessage[reviewItem]("The session hasn't changed any files"),
+		list.WithRenderFunc(
+			func(item reviewItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item reviewItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &reviewDialog{
+		app:      app,
+		list:     listComponent,
+		selected: selected,
+		modal: modal.New(
+			modal.WithTitle("Review Changes"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -99,6 +99,7 @@ func NewSearchDialog(placeholder string, maxVisibleHeight int) *SearchDialog {
 		Background(bgColor).
 		Lipgloss()
 	ti.Styles.Cursor.Color = t.Primary()
+	ti.Styles.Cursor.Blink = !styles.ReduceMotion
 	ti.VirtualCursor = true
 
 	ti.Prompt = " "
@@ -224,6 +225,11 @@ func (s *SearchDialog) SetItems(items []list.Item) {
 	s.list.SetItems(items)
 }
 
+// SelectedItem returns the selected item and its index, -1 when there is none
+func (s *SearchDialog) SelectedItem() (list.Item, int) {
+	return s.list.GetSelectedItem()
+}
+
 // GetQuery returns the current search query
 func (s *SearchDialog) GetQuery() string {
 	return s.textInput.Value()
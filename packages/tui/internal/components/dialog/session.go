@@ -172,12 +172,15 @@ func (s *sessionDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "n":
 			s.app.Session = &opencode.Session{}
-			s.app.Messages = []app.Message{}
+			s.app.SetMessages([]app.Message{})
 			return s, tea.Sequence(
 				util.CmdHandler(modal.CloseModalMsg{}),
 				util.CmdHandler(app.SessionClearedMsg{}),
 			)
 		case "x", "delete", "backspace":
+			if s.app.ReadOnly {
+				return s, toast.NewInfoToast(app.ReadOnlyMessage)
+			}
 			if _, idx := s.list.GetSelectedItem(); idx >= 0 && idx < len(s.sessions) {
 				if s.deleteConfirmation == idx {
 					// Second press - actually delete the session
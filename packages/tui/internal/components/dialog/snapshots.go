@@ -0,0 +1,168 @@
+package dialog
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/snapshot"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// SnapshotSelectedMsg is sent when a workspace snapshot is picked for restoring
+type SnapshotSelectedMsg struct {
+	Snapshot snapshot.Snapshot
+}
+
+// SnapshotsDialog interface for the workspace snapshots dialog
+type SnapshotsDialog interface {
+	layout.Modal
+}
+
+// snapshotItem is a list item for a workspace snapshot
+type snapshotItem struct {
+	snapshot snapshot.Snapshot
+}
+
+func (s snapshotItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	when := s.snapshot.Time.Format("Jan 2 15:04:05")
+	message := truncate.StringWithTail(s.snapshot.Message, uint(max(width-len(when)-4, 1)), "...")
+	spacer := strings.Repeat(" ", max(width-ansi.StringWidth(message)-len(when)-2, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(message + spacer + when)
+	}
+	return itemStyle.Render(message+spacer) +
+		baseStyle.Foreground(t.TextMuted()).Render(when)
+}
+
+func (s snapshotItem) Selectable() bool {
+	return true
+}
+
+type snapshotsDialog struct {
+	width     int
+	height    int
+	modal     *modal.Modal
+	app       *app.App
+	snapshots []snapshot.Snapshot
+	list      list.List[snapshotItem]
+}
+
+func (s *snapshotsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (s *snapshotsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		s.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			if _, idx := s.list.GetSelectedItem(); idx >= 0 && idx < len(s.snapshots) {
+				return s, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(SnapshotSelectedMsg{Snapshot: s.snapshots[idx]}),
+				)
+			}
+		case "x":
+			if _, idx := s.list.GetSelectedItem(); idx >= 0 && idx < len(s.snapshots) {
+				if err := snapshot.Delete(s.app.Info.Path.Cwd, s.snapshots[idx]); err != nil {
+					return s, toast.NewErrorToast(err.Error())
+				}
+				s.snapshots = append(s.snapshots[:idx], s.snapshots[idx+1:]...)
+				s.list.SetItems(snapshotItems(s.snapshots))
+				s.list.SetSelectedIndex(min(idx, len(s.snapshots)-1))
+				return s, nil
+			}
+		}
+	}
+
+	listModel, cmd := s.list.Update(msg)
+	s.list = listModel.(list.List[snapshotItem])
+	return s, cmd
+}
+
+func snapshotItems(snapshots []snapshot.Snapshot) []snapshotItem {
+	var items []snapshotItem
+	for _, snapshot := range snapshots {
+		items = append(items, snapshotItem{snapshot: snapshot})
+	}
+	return items
+}
+
+func (s *snapshotsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	helpText := keyStyle("enter") + mutedStyle(" restore  ") +
+		keyStyle("x") + mutedStyle(" delete")
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	content := strings.Join([]string{s.list.View(), helpSection}, "\n")
+	return s.modal.Render(content, background)
+}
+
+func (s *snapshotsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewSnapshotsDialog creates a dialog for restoring a workspace snapshot
+func NewSnapshotsDialog(app *app.App) SnapshotsDialog {
+	snapshots, _ := snapshot.List(app.Info.Path.Cwd)
+
+	listComponent := list.NewListComponent(
+		list.WithItems(snapshotItems(snapshots)),
+		list.WithMaxVisibleHeight[snapshotItem](10),
+		list.WithFallbackMessage[snapshotItem]("No snapshots. Press s when approving a tool to take one."),
+		list.WithRenderFunc(
+			func(item snapshotItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item snapshotItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &snapshotsDialog{
+		app:       app,
+		snapshots: snapshots,
+		list:      listComponent,
+		modal: modal.New(
+			modal.WithTitle("Workspace Snapshots"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -0,0 +1,339 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/completions"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	symbolsDialogWidth = 76
+	symbolPreviewLines = 4
+)
+
+// SymbolAttachMsg is sent when a symbol is picked for attaching to the prompt
+type SymbolAttachMsg struct {
+	Symbol opencode.Symbol
+}
+
+// SymbolsDialog browses the workspace symbols of the language servers
+type SymbolsDialog interface {
+	layout.Modal
+}
+
+// symbolKindFilter is a group of symbol kinds the dialog can be narrowed to
+type symbolKindFilter struct {
+	label string
+	kinds []completions.SymbolKind
+}
+
+var symbolKindFilters = []symbolKindFilter{
+	{label: "all"},
+	{label: "functions", kinds: []completions.SymbolKind{completions.SymbolKindFunction}},
+	{label: "methods", kinds: []completions.SymbolKind{completions.SymbolKindMethod, completions.SymbolKindConstructor}},
+	{label: "types", kinds: []completions.SymbolKind{
+		completions.SymbolKindClass,
+		completions.SymbolKindInterface,
+		completions.SymbolKindStruct,
+		completions.SymbolKindEnum,
+	}},
+	{label: "variables", kinds: []completions.SymbolKind{completions.SymbolKindVariable, completions.SymbolKindConstant}},
+}
+
+func (f symbolKindFilter) matches(symbol opencode.Symbol) bool {
+	if len(f.kinds) == 0 {
+		return true
+	}
+	for _, kind := range f.kinds {
+		if completions.SymbolKind(symbol.Kind) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// symbolKindLabel names the kinds of symbols the servers return
+func symbolKindLabel(kind completions.SymbolKind) string {
+	switch kind {
+	case completions.SymbolKindFunction:
+		return "func"
+	case completions.SymbolKindMethod:
+		return "method"
+	case completions.SymbolKindConstructor:
+		return "constructor"
+	case completions.SymbolKindClass:
+		return "class"
+	case completions.SymbolKindInterface:
+		return "interface"
+	case completions.SymbolKindStruct:
+		return "struct"
+	case completions.SymbolKindEnum:
+		return "enum"
+	case completions.SymbolKindVariable:
+		return "var"
+	case completions.SymbolKindConstant:
+		return "const"
+	}
+	return "symbol"
+}
+
+// symbolPath returns the path of the symbol's file relative to the project
+func symbolPath(symbol opencode.Symbol) string {
+	return util.Relative(strings.TrimPrefix(symbol.Location.Uri, "file://"))
+}
+
+// symbolsResultMsg carries the symbols found for a query
+type symbolsResultMsg struct {
+	query   string
+	symbols []opencode.Symbol
+}
+
+// symbolItem is a list item for a workspace symbol
+type symbolItem struct {
+	symbol opencode.Symbol
+}
+
+func (s symbolItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	kind := symbolKindLabel(completions.SymbolKind(s.symbol.Kind))
+	location := fmt.Sprintf("%s:%d", symbolPath(s.symbol), int(s.symbol.Location.Range.Start.Line)+1)
+	name := truncate.StringWithTail(s.symbol.Name, uint(max(width/2, 1)), "...")
+	location = truncate.StringWithTail(location, uint(max(width-ansi.StringWidth(name)-len(kind)-5, 1)), "...")
+	spacer := strings.Repeat(" ", max(width-ansi.StringWidth(name)-len(kind)-ansi.StringWidth(location)-4, 1))
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(name + " " + kind + spacer + location)
+	}
+	return itemStyle.Render(name+" ") +
+		baseStyle.Foreground(t.Accent()).Render(kind) +
+		baseStyle.Render(spacer) +
+		baseStyle.Foreground(t.TextMuted()).Render(location)
+}
+
+func (s symbolItem) Selectable() bool {
+	return true
+}
+
+type symbolsDialog struct {
+	app     *app.App
+	modal   *modal.Modal
+	search  *SearchDialog
+	symbols []opencode.Symbol
+	filter  int
+	// preview holds the first lines of the selected symbol
+	preview  string
+	selected int
+}
+
+func (s *symbolsDialog) Init() tea.Cmd {
+	return s.search.Init()
+}
+
+func (s *symbolsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case symbolsResultMsg:
+		if msg.query != s.search.GetQuery() {
+			return s, nil
+		}
+		s.symbols = msg.symbols
+		s.refresh()
+		return s, nil
+	case SearchQueryChangedMsg:
+		return s, s.find(msg.Query)
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(symbolItem); ok {
+			return s, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(app.OpenFileMsg{
+					Path: symbolPath(item.symbol),
+					Line: int(item.symbol.Location.Range.Start.Line),
+				}),
+			)
+		}
+		return s, nil
+	case SearchCancelledMsg:
+		return s, util.CmdHandler(modal.CloseModalMsg{})
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "tab":
+			s.filter = (s.filter + 1) % len(symbolKindFilters)
+			s.refresh()
+			return s, nil
+		case "shift+tab":
+			s.filter = (s.filter + len(symbolKindFilters) - 1) % len(symbolKindFilters)
+			s.refresh()
+			return s, nil
+		case "ctrl+e":
+			if item, idx := s.search.SelectedItem(); idx >= 0 {
+				symbol := item.(symbolItem).symbol
+				return s, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(app.EditFileMsg{
+						Path: symbolPath(symbol),
+						Line: int(symbol.Location.Range.Start.Line),
+					}),
+				)
+			}
+			return s, nil
+		case "ctrl+a":
+			if item, idx := s.search.SelectedItem(); idx >= 0 {
+				return s, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(SymbolAttachMsg{Symbol: item.(symbolItem).symbol}),
+				)
+			}
+			return s, nil
+		}
+	}
+
+	updated, cmd := s.search.Update(msg)
+	s.search = updated.(*SearchDialog)
+	s.updatePreview()
+	return s, cmd
+}
+
+// find asks the language servers for the symbols matching query
+func (s *symbolsDialog) find(query string) tea.Cmd {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		s.symbols = nil
+		s.refresh()
+		return nil
+	}
+	client := s.app.Client
+	raw := s.search.GetQuery()
+	return func() tea.Msg {
+		symbols, err := client.Find.Symbols(
+			context.Background(),
+			opencode.FindSymbolsParams{Query: opencode.F(query)},
+		)
+		if err != nil || symbols == nil {
+			slog.Error("Failed to find symbols", "error", err)
+			return symbolsResultMsg{query: raw}
+		}
+		return symbolsResultMsg{query: raw, symbols: *symbols}
+	}
+}
+
+// refresh lists the symbols of the selected kinds
+func (s *symbolsDialog) refresh() {
+	filter := symbolKindFilters[s.filter]
+	items := []list.Item{}
+	for _, symbol := range s.symbols {
+		if filter.matches(symbol) {
+			items = append(items, symbolItem{symbol: symbol})
+		}
+	}
+	s.search.SetItems(items)
+	s.selected = -1
+	s.updatePreview()
+}
+
+// updatePreview reads the first lines of the selected symbol when the
+// selection moves
+func (s *symbolsDialog) updatePreview() {
+	item, idx := s.search.SelectedItem()
+	if idx == s.selected {
+		return
+	}
+	s.selected = idx
+	s.preview = ""
+	if idx < 0 {
+		return
+	}
+	symbol := item.(symbolItem).symbol
+	content, err := os.ReadFile(strings.TrimPrefix(symbol.Location.Uri, "file://"))
+	if err != nil {
+		return
+	}
+	lines := strings.Split(string(content), "\n")
+	start := min(int(symbol.Location.Range.Start.Line), len(lines))
+	end := min(start+symbolPreviewLines, len(lines))
+	s.preview = strings.ReplaceAll(strings.Join(lines[start:end], "\n"), "\t", "  ")
+}
+
+func (s *symbolsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	var kinds []string
+	for i, filter := range symbolKindFilters {
+		if i == s.filter {
+			kinds = append(kinds, styles.NewStyle().Foreground(t.Primary()).Background(t.BackgroundPanel()).Bold(true).Render(filter.label))
+		} else {
+			kinds = append(kinds, mutedStyle(filter.label))
+		}
+	}
+	kindsRow := " " + strings.Join(kinds, mutedStyle("  "))
+
+	previewStyle := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundElement()).
+		Width(symbolsDialogWidth - 2).
+		Height(symbolPreviewLines).
+		PaddingLeft(1)
+	var previewLines []string
+	for line := range strings.SplitSeq(s.preview, "\n") {
+		previewLines = append(previewLines, truncate.StringWithTail(line, symbolsDialogWidth-4, "..."))
+	}
+	preview := previewStyle.Render(strings.Join(previewLines, "\n"))
+
+	helpText := keyStyle("tab") + mutedStyle(" kind  ") +
+		keyStyle("enter") + mutedStyle(" open  ") +
+		keyStyle("ctrl+e") + mutedStyle(" edit  ") +
+		keyStyle("ctrl+a") + mutedStyle(" attach")
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      symbolsDialogWidth - 2,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+
+	content := strings.Join([]string{kindsRow, "", s.search.View(), "", preview, "", helpSection}, "\n")
+	return s.modal.Render(content, background)
+}
+
+func (s *symbolsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewSymbolsDialog creates a dialog for browsing the workspace symbols
+func NewSymbolsDialog(app *app.App) SymbolsDialog {
+	search := NewSearchDialog("Search symbols...", 10)
+	search.SetWidth(symbolsDialogWidth)
+	return &symbolsDialog{
+		app:      app,
+		search:   search,
+		selected: -1,
+		modal: modal.New(
+			modal.WithTitle("Workspace Symbols"),
+			modal.WithMaxWidth(symbolsDialogWidth+4),
+		),
+	}
+}
@@ -0,0 +1,37 @@
+package dialog
+
+import (
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/completions"
+)
+
+func TestSymbolKindFilters(t *testing.T) {
+	symbol := func(kind completions.SymbolKind) opencode.Symbol {
+		return opencode.Symbol{Name: "x", Kind: float64(kind)}
+	}
+	tests := []struct {
+		filter string
+		kind   completions.SymbolKind
+		want   bool
+	}{
+		{"all", completions.SymbolKindField, true},
+		{"functions", completions.SymbolKindFunction, true},
+		{"functions", completions.SymbolKindMethod, false},
+		{"methods", completions.SymbolKindMethod, true},
+		{"types", completions.SymbolKindStruct, true},
+		{"types", completions.SymbolKindVariable, false},
+		{"variables", completions.SymbolKindConstant, true},
+	}
+	for _, tt := range tests {
+		for _, filter := range symbolKindFilters {
+			if filter.label != tt.filter {
+				continue
+			}
+			if got := filter.matches(symbol(tt.kind)); got != tt.want {
+				t.Errorf("%s.matches(kind %d) = %v, want %v", tt.filter, tt.kind, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package dialog
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// TemplatesDialog interface for the new-from-template dialog
+type TemplatesDialog interface {
+	layout.Modal
+}
+
+// templateItem is a list item for a session template
+type templateItem struct {
+	template app.Template
+}
+
+func (t templateItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	th := theme.CurrentTheme()
+
+	details := []string{}
+	if t.template.Agent != "" {
+		details = append(details, t.template.Agent)
+	}
+	if t.template.Model != "" {
+		details = append(details, t.template.Model)
+	}
+
+	text := t.template.Name
+	if len(details) > 0 {
+		text += " (" + strings.Join(details, ", ") + ")"
+	}
+	text = truncate.StringWithTail(text, uint(width-1), "...")
+
+	description := ""
+	if t.template.Description != "" {
+		description = "\n" + truncate.StringWithTail("  "+t.template.Description, uint(width-1), "...")
+	}
+
+	itemStyle := baseStyle.Width(width).PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(th.Primary()).
+			Foreground(th.BackgroundElement())
+		return itemStyle.Render(text + description)
+	}
+
+	mutedStyle := baseStyle.Foreground(th.TextMuted()).PaddingLeft(1)
+	if description == "" {
+		return itemStyle.Render(text)
+	}
+	return itemStyle.Render(text) + "\n" + mutedStyle.Render(strings.TrimPrefix(description, "\n"))
+}
+
+func (t templateItem) Selectable() bool {
+	return true
+}
+
+type templatesDialog struct {
+	width     int
+	height    int
+	modal     *modal.Modal
+	app       *app.App
+	templates []app.Template
+	list      list.List[templateItem]
+}
+
+func (t *templatesDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (t *templatesDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		t.width = msg.Width
+		t.height = msg.Height
+		t.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			if _, idx := t.list.GetSelectedItem(); idx >= 0 && idx < len(t.templates) {
+				updated, cmd := t.app.StartFromTemplate(t.templates[idx])
+				t.app = updated
+				return t, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					cmd,
+				)
+			}
+		}
+	}
+
+	listModel, cmd := t.list.Update(msg)
+	t.list = listModel.(list.List[templateItem])
+	return t, cmd
+}
+
+func (t *templatesDialog) Render(background string) string {
+	th := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(th.Text()).Background(th.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(th.TextMuted()).Background(th.BackgroundPanel()).Render
+
+	helpText := keyStyle("enter") + mutedStyle(" start session")
+
+	bgColor := th.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	content := strings.Join([]string{t.list.View(), helpSection}, "\n")
+	return t.modal.Render(content, background)
+}
+
+func (t *templatesDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewTemplatesDialog creates a dialog for starting a session from a template
+func NewTemplatesDialog(app *app.App) TemplatesDialog {
+	templates := app.Templates()
+
+	var items []templateItem
+	for _, template := range templates {
+		items = append(items, templateItem{template: template})
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[templateItem](10),
+		list.WithFallbackMessage[templateItem]("No templates configured. Add them under tui.templates in your config."),
+		list.WithAlphaNumericKeys[templateItem](true),
+		list.WithRenderFunc(
+			func(item templateItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item templateItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &templatesDialog{
+		app:       app,
+		templates: templates,
+		list:      listComponent,
+		modal: modal.New(
+			modal.WithTitle("New from Template"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
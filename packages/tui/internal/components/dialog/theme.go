@@ -1,9 +1,13 @@
 package dialog
 
 import (
+	"path/filepath"
+
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/app"
 	list "github.com/sst/opencode/internal/components/list"
 	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
 	"github.com/sst/opencode/internal/layout"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
@@ -13,6 +17,9 @@ import (
 // ThemeSelectedMsg is sent when the theme is changed
 type ThemeSelectedMsg struct {
 	ThemeName string
+	// Preview is set while browsing themes, or when the current theme is
+	// reloaded, so the selection isn't saved
+	Preview bool
 }
 
 // ThemeDialog interface for the theme switching dialog
@@ -21,6 +28,7 @@ type ThemeDialog interface {
 }
 
 type themeDialog struct {
+	app    *app.App
 	width  int
 	height int
 
@@ -56,7 +64,13 @@ func (t *themeDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					)
 				}
 			}
-
+		case "ctrl+e":
+			if item, idx := t.list.GetSelectedItem(); idx >= 0 {
+				if stringItem, ok := item.(list.StringItem); ok {
+					return t, t.customize(string(stringItem))
+				}
+			}
+			return t, nil
 		}
 	}
 
@@ -69,26 +83,66 @@ func (t *themeDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if item, newIdx := t.list.GetSelectedItem(); newIdx >= 0 && newIdx != prevIdx {
 		if stringItem, ok := item.(list.StringItem); ok {
 			theme.SetTheme(string(stringItem))
-			return t, util.CmdHandler(ThemeSelectedMsg{ThemeName: string(stringItem)})
+			return t, util.CmdHandler(ThemeSelectedMsg{ThemeName: string(stringItem), Preview: true})
 		}
 	}
 	return t, cmd
 }
 
+// customize copies the theme into the user themes directory to be edited,
+// selecting the copy so edits to it show as they are saved
+func (t *themeDialog) customize(name string) tea.Cmd {
+	dir := filepath.Join(t.app.Info.Path.Config, "themes")
+	copyPath, err := theme.CopyTheme(name, dir)
+	if err != nil {
+		return toast.NewErrorToast("Failed to copy theme: " + err.Error())
+	}
+	copyName, err := theme.LoadThemeFile(copyPath)
+	if err != nil {
+		return toast.NewErrorToast("Failed to load theme: " + err.Error())
+	}
+	t.list.SetItems(themeItems())
+	for i, item := range themeItems() {
+		if item == list.StringItem(copyName) {
+			t.list.SetSelectedIndex(i)
+		}
+	}
+	theme.SetTheme(copyName)
+	return tea.Batch(
+		util.CmdHandler(ThemeSelectedMsg{ThemeName: copyName, Preview: true}),
+		toast.NewInfoToast("Edit "+copyPath+", changes apply as you save"),
+	)
+}
+
 func (t *themeDialog) Render(background string) string {
-	return t.modal.Render(t.list.View(), background)
+	tm := theme.CurrentTheme()
+	hint := styles.NewStyle().
+		Foreground(tm.TextMuted()).
+		Background(tm.BackgroundPanel()).
+		Render("ctrl+e customize")
+	return t.modal.Render(t.list.View()+"\n\n"+hint, background)
 }
 
 func (t *themeDialog) Close() tea.Cmd {
 	if !t.themeApplied {
 		theme.SetTheme(t.originalTheme)
-		return util.CmdHandler(ThemeSelectedMsg{ThemeName: t.originalTheme})
+		return util.CmdHandler(ThemeSelectedMsg{ThemeName: t.originalTheme, Preview: true})
 	}
 	return nil
 }
 
-// NewThemeDialog creates a new theme switching dialog
-func NewThemeDialog() ThemeDialog {
+func themeItems() []list.Item {
+	themes := theme.AvailableThemes()
+	items := make([]list.Item, len(themes))
+	for i, name := range themes {
+		items[i] = list.StringItem(name)
+	}
+	return items
+}
+
+// NewThemeDialog creates a new theme switching dialog, previewing each theme
+// as it is selected
+func NewThemeDialog(app *app.App) ThemeDialog {
 	themes := theme.AvailableThemes()
 	currentTheme := theme.CurrentThemeName()
 
@@ -100,10 +154,7 @@ func NewThemeDialog() ThemeDialog {
 	}
 
 	// Convert themes to list items
-	items := make([]list.Item, len(themes))
-	for i, theme := range themes {
-		items[i] = list.StringItem(theme)
-	}
+	items := themeItems()
 
 	listComponent := list.NewListComponent(
 		list.WithItems(items),
@@ -124,6 +175,7 @@ func NewThemeDialog() ThemeDialog {
 	// Set the max width for the list to match the modal width
 	listComponent.SetMaxWidth(36) // 40 (modal max width) - 4 (modal padding)
 	return &themeDialog{
+		app:           app,
 		list:          listComponent,
 		modal:         modal.New(modal.WithTitle("Select Theme"), modal.WithMaxWidth(40)),
 		originalTheme: currentTheme,
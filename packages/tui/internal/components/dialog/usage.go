@@ -0,0 +1,169 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// UsageDialog interface for the session usage summary dialog
+type UsageDialog interface {
+	layout.Modal
+}
+
+// allSessionsUsageMsg carries the usage aggregated across every session
+type allSessionsUsageMsg struct {
+	usage app.Usage
+	err   error
+}
+
+type usageDialog struct {
+	width   int
+	height  int
+	modal   *modal.Modal
+	app     *app.App
+	current app.Usage
+	all     *app.Usage
+	showAll bool
+	loading bool
+	err     error
+}
+
+func (u *usageDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (u *usageDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		u.width = msg.Width
+		u.height = msg.Height
+	case allSessionsUsageMsg:
+		u.loading = false
+		u.err = msg.err
+		if msg.err == nil {
+			u.all = &msg.usage
+		}
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			return u, util.CmdHandler(modal.CloseModalMsg{})
+		case "a":
+			u.showAll = !u.showAll
+			if u.showAll && u.all == nil && !u.loading {
+				u.loading = true
+				return u, u.loadAllSessions()
+			}
+		}
+	}
+	return u, nil
+}
+
+func (u *usageDialog) loadAllSessions() tea.Cmd {
+	return func() tea.Msg {
+		usage, err := u.app.AllSessionsUsage(context.Background())
+		return allSessionsUsageMsg{usage: usage, err: err}
+	}
+}
+
+func (u *usageDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	var body string
+	switch {
+	case u.showAll && u.loading:
+		body = mutedStyle("Loading usage for all sessions...")
+	case u.showAll && u.err != nil:
+		body = mutedStyle("Failed to load sessions: " + u.err.Error())
+	case u.showAll && u.all != nil:
+		body = u.renderUsage(*u.all, true)
+	case u.app.Session.ID == "":
+		body = mutedStyle("No active session.")
+	default:
+		body = u.renderUsage(u.current, false)
+	}
+
+	toggle := " all sessions"
+	if u.showAll {
+		toggle = " current session"
+	}
+	helpText := keyStyle("a") + mutedStyle(toggle) + mutedStyle("  ") + keyStyle("enter") + mutedStyle(" close")
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	return u.modal.Render(strings.Join([]string{body, helpSection}, "\n"), background)
+}
+
+func (u *usageDialog) renderUsage(usage app.Usage, all bool) string {
+	t := theme.CurrentTheme()
+	labelStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Width(18).PaddingLeft(1)
+	valueStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+
+	row := func(label, value string) string {
+		return labelStyle.Render(label) + valueStyle.Render(value)
+	}
+
+	var rows []string
+	if all {
+		rows = append(rows, row("Sessions", fmt.Sprintf("%d", usage.Sessions)))
+	}
+	rows = append(rows,
+		row("Messages", fmt.Sprintf("%d sent, %d received", usage.UserMessages, usage.AssistantMessages)),
+		row("Input tokens", util.FormatTokens(usage.InputTokens)),
+		row("Output tokens", util.FormatTokens(usage.OutputTokens)),
+	)
+	if usage.ReasoningTokens > 0 {
+		rows = append(rows, row("Reasoning tokens", util.FormatTokens(usage.ReasoningTokens)))
+	}
+	if usage.CacheReadTokens > 0 || usage.CacheWriteTokens > 0 {
+		rows = append(rows, row("Cache", fmt.Sprintf(
+			"%s read, %s written",
+			util.FormatTokens(usage.CacheReadTokens),
+			util.FormatTokens(usage.CacheWriteTokens),
+		)))
+	}
+	rows = append(rows,
+		row("Total tokens", util.FormatTokens(usage.TotalTokens())),
+		row("Cost", fmt.Sprintf("$%.2f", usage.Cost)),
+		row("Agent time", usage.AgentTime.Round(time.Second).String()),
+		row("Tool calls", fmt.Sprintf("%d", usage.TotalToolCalls())),
+	)
+	for _, name := range usage.ToolNames() {
+		rows = append(rows, row("  "+name, fmt.Sprintf("%d", usage.ToolCalls[name])))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (u *usageDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewUsageDialog creates a dialog summarizing token, cost and tool usage
+func NewUsageDialog(app *app.App) UsageDialog {
+	return &usageDialog{
+		app:     app,
+		current: app.SessionUsage(),
+		modal: modal.New(
+			modal.WithTitle("Usage"),
+			modal.WithMaxWidth(60),
+		),
+	}
+}
@@ -0,0 +1,145 @@
+package dialog
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// ViewedFilesDialog switches between the files viewed recently
+type ViewedFilesDialog interface {
+	layout.Modal
+}
+
+// viewedItem is a list item for a file viewed
+type viewedItem struct {
+	path string
+	open bool
+}
+
+func (v viewedItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	label := ""
+	if v.open {
+		label = " (open)"
+	}
+	path := truncate.StringWithTail(v.path, uint(max(width-len(label)-2, 1)), "...")
+
+	itemStyle := baseStyle.PaddingLeft(1)
+	if selected {
+		itemStyle = itemStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement())
+		return itemStyle.Render(path + label)
+	}
+	return itemStyle.Render(path) + baseStyle.Foreground(t.TextMuted()).Render(label)
+}
+
+func (v viewedItem) Selectable() bool {
+	return true
+}
+
+type viewedFilesDialog struct {
+	modal *modal.Modal
+	paths []string
+	list  list.List[viewedItem]
+}
+
+func (v *viewedFilesDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (v *viewedFilesDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			if _, idx := v.list.GetSelectedItem(); idx >= 0 && idx < len(v.paths) {
+				return v, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(FindSelectedMsg{FilePath: v.paths[idx]}),
+				)
+			}
+		}
+	}
+
+	listModel, cmd := v.list.Update(msg)
+	v.list = listModel.(list.List[viewedItem])
+	return v, cmd
+}
+
+func (v *viewedFilesDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	helpText := keyStyle("enter") + mutedStyle(" open  ") +
+		keyStyle("esc") + mutedStyle(" cancel")
+
+	bgColor := t.BackgroundPanel()
+	helpSection := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifyCenter,
+		Width:      layout.Current.Container.Width - 14,
+		Background: &bgColor,
+	}, layout.FlexItem{View: helpText})
+	helpSection = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpSection)
+
+	content := strings.Join([]string{v.list.View(), helpSection}, "\n")
+	return v.modal.Render(content, background)
+}
+
+func (v *viewedFilesDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewViewedFilesDialog creates a dialog listing the files viewed, the most
+// recent first. open is the file in the viewer, the one before it starts
+// selected for switching back and forth.
+func NewViewedFilesDialog(paths []string, open string) ViewedFilesDialog {
+	var items []viewedItem
+	for _, path := range paths {
+		items = append(items, viewedItem{path: path, open: path == open})
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[viewedItem](12),
+		list.WithFallbackMessage[viewedItem]("No files viewed yet"),
+		list.WithRenderFunc(
+			func(item viewedItem, selected bool, width int, baseStyle styles.Style) string {
+				return item.Render(selected, width, baseStyle)
+			},
+		),
+		list.WithSelectableFunc(func(item viewedItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+	if len(items) > 1 && items[0].open {
+		listComponent.SetSelectedIndex(1)
+	}
+
+	return &viewedFilesDialog{
+		paths: paths,
+		list:  listComponent,
+		modal: modal.New(
+			modal.WithTitle("Viewed Files"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -208,6 +208,117 @@ func ParseUnifiedDiff(diff string) (DiffResult, error) {
 	return result, scanner.Err()
 }
 
+// contextLines is how many unchanged lines GenerateUnifiedDiff keeps around
+// each change
+const contextLines = 3
+
+// genLine is a line of a generated diff with the line it sits at in the old
+// and new file
+type genLine struct {
+	kind     LineType
+	text     string
+	old, new int
+}
+
+// GenerateUnifiedDiff builds a unified diff turning before into after, in the
+// form ParseUnifiedDiff reads. It is empty when nothing changed.
+func GenerateUnifiedDiff(filename, before, after string) string {
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+
+	var lines []genLine
+	oldLine, newLine := 1, 1
+	for _, d := range diffs {
+		text := strings.TrimSuffix(d.Text, "\n")
+		if d.Text == "" {
+			continue
+		}
+		for _, content := range strings.Split(text, "\n") {
+			line := genLine{text: content, old: oldLine, new: newLine}
+			switch d.Type {
+			case diffmatchpatch.DiffDelete:
+				line.kind = LineRemoved
+				oldLine++
+			case diffmatchpatch.DiffInsert:
+				line.kind = LineAdded
+				newLine++
+			default:
+				line.kind = LineContext
+				oldLine++
+				newLine++
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(lines); {
+		if lines[i].kind == LineContext {
+			i++
+			continue
+		}
+		start := max(0, i-contextLines)
+		end := i
+		// changes closer than twice the context share a hunk
+		for end < len(lines) {
+			if lines[end].kind != LineContext {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].kind == LineContext {
+				run++
+			}
+			if run < len(lines) && run-end <= 2*contextLines {
+				end = run
+				continue
+			}
+			end = min(run, end+contextLines)
+			break
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", filename, filename)
+		}
+		writeGeneratedHunk(&sb, lines[start:end])
+		i = end
+	}
+	return sb.String()
+}
+
+func writeGeneratedHunk(sb *strings.Builder, lines []genLine) {
+	oldCount, newCount := 0, 0
+	for _, line := range lines {
+		if line.kind != LineAdded {
+			oldCount++
+		}
+		if line.kind != LineRemoved {
+			newCount++
+		}
+	}
+	// an empty range starts at the line before it
+	oldStart, newStart := lines[0].old, lines[0].new
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range lines {
+		switch line.kind {
+		case LineAdded:
+			sb.WriteString("+")
+		case LineRemoved:
+			sb.WriteString("-")
+		default:
+			sb.WriteString(" ")
+		}
+		sb.WriteString(line.text)
+		sb.WriteString("\n")
+	}
+}
+
 // HighlightIntralineChanges updates lines in a hunk to show character-level differences
 func HighlightIntralineChanges(h *Hunk) {
 	var updated []DiffLine
@@ -303,9 +414,26 @@ func pairLines(lines []DiffLine) []linePair {
 
 // SyntaxHighlight applies syntax highlighting to text based on file extension
 func SyntaxHighlight(w io.Writer, source, fileName, formatter string, bg color.Color) error {
-	t := theme.CurrentTheme()
+	l := SyntaxLexer(fileName, source)
+
+	// Get the formatter
+	f := formatters.Get(formatter)
+	if f == nil {
+		f = formatters.Fallback
+	}
+
+	// Tokenize and format
+	it, err := l.Tokenise(nil, source)
+	if err != nil {
+		return err
+	}
+
+	return f.Format(w, SyntaxStyle(bg), it)
+}
 
-	// Determine the language lexer to use
+// SyntaxLexer returns the lexer for the language of the file, guessed from
+// its source when the name doesn't tell
+func SyntaxLexer(fileName, source string) chroma.Lexer {
 	l := lexers.Match(fileName)
 	if l == nil {
 		l = lexers.Analyse(source)
@@ -313,13 +441,13 @@ func SyntaxHighlight(w io.Writer, source, fileName, formatter string, bg color.C
 	if l == nil {
 		l = lexers.Fallback
 	}
-	l = chroma.Coalesce(l)
+	return chroma.Coalesce(l)
+}
 
-	// Get the formatter
-	f := formatters.Get(formatter)
-	if f == nil {
-		f = formatters.Fallback
-	}
+// SyntaxStyle returns the highlighting style of the current theme on the
+// given background
+func SyntaxStyle(bg color.Color) *chroma.Style {
+	t := theme.CurrentTheme()
 
 	// Dynamic theme based on current theme values
 	syntaxThemeXml := fmt.Sprintf(`
@@ -503,14 +631,7 @@ func SyntaxHighlight(w io.Writer, source, fileName, formatter string, bg color.C
 	if err != nil {
 		s = styles.Fallback
 	}
-
-	// Tokenize and format
-	it, err := l.Tokenise(nil, source)
-	if err != nil {
-		return err
-	}
-
-	return f.Format(w, s, it)
+	return s
 }
 
 // getColor returns the appropriate hex color string based on terminal background
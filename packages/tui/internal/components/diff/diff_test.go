@@ -0,0 +1,31 @@
+package diff
+
+import "testing"
+
+func TestGenerateUnifiedDiff(t *testing.T) {
+	before := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	after := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	patch := GenerateUnifiedDiff("numbers.txt", before, after)
+	want := "--- a/numbers.txt\n+++ b/numbers.txt\n" +
+		"@@ -1,6 +1,6 @@\n one\n two\n-three\n+THREE\n four\n five\n six\n" +
+		"@@ -8,3 +8,4 @@\n eight\n nine\n ten\n+eleven\n"
+	if patch != want {
+		t.Errorf("unexpected diff:\n%s", patch)
+	}
+
+	result, err := ParseUnifiedDiff(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Hunks) != 2 || result.Hunks[1].Lines[3].NewLineNo != 11 {
+		t.Errorf("unexpected parse %+v", result.Hunks)
+	}
+
+	if patch := GenerateUnifiedDiff("new.txt", "", "hello\n"); patch != "--- a/new.txt\n+++ b/new.txt\n@@ -0,0 +1,1 @@\n+hello\n" {
+		t.Errorf("unexpected diff for a new file:\n%s", patch)
+	}
+	if patch := GenerateUnifiedDiff("same.txt", before, before); patch != "" {
+		t.Errorf("expected no diff, got:\n%s", patch)
+	}
+}
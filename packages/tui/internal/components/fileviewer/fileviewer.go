@@ -1,15 +1,25 @@
 package fileviewer
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"strings"
+	"unicode"
 
+	"github.com/charmbracelet/bubbles/v2/textinput"
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	opencode "github.com/sst/opencode-sdk-go"
 
 	"github.com/sst/opencode/internal/app"
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/components/dialog"
 	"github.com/sst/opencode/internal/components/diff"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/git"
 	"github.com/sst/opencode/internal/layout"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
@@ -32,18 +42,75 @@ type Model struct {
 	content       *string
 	isDiff        *bool
 	diffStyle     DiffStyle
+	focused       bool
+	// total is the number of lines of a file read a page at a time, or 0 when
+	// the whole file is loaded
+	total   int
+	loading bool
+	// hunks are the git hunks of a changed file, shown in place of its patch
+	// so each can be staged on its own
+	hunks []git.Hunk
+	// hunk is the selected hunk
+	hunk int
+	// hunkViews are the rendered hunks and hunkOffsets the lines they start at
+	hunkViews   []string
+	hunkOffsets []int
+	// clicked is the name clicked last, the one goto definition looks up
+	// rather than the selected match of a search
+	clicked *position
+	// line is the source line to scroll to once the file is rendered, or
+	// the row of a diff, -1 when there is none
+	line int
+	// source is the file laid out for the view, nil for diffs
+	source *source
+	// prompt is the find or go to line prompt typed into, input its text
+	prompt promptKind
+	input  textinput.Model
+	// query is the text found in the file
+	query string
+	// history is the files viewed in order, at the index of the open one,
+	// and positions where each was scrolled to when left
+	history   []string
+	at        int
+	positions map[string]int
 }
 
+// PageSize is the number of lines read at a time from large files
+const PageSize = 2000
+
 type fileRenderedMsg struct {
 	content string
+	// source is set instead of content for a file that isn't a diff
+	source *source
+	// hunks are set instead of content when the file is shown by hunks
+	hunks []string
+}
+
+// hunksLoadedMsg carries the git hunks of a changed file
+type hunksLoadedMsg struct {
+	filename string
+	hunks    []git.Hunk
+	err      error
+}
+
+// pageLoadedMsg carries the next page of a file read a page at a time
+type pageLoadedMsg struct {
+	filename string
+	offset   int
+	content  string
+	err      error
 }
 
 func New(app *app.App) Model {
 	vp := viewport.New()
+	// keys scroll the viewer through the commands of its keymap
+	vp.KeyMap = viewport.KeyMap{}
 	m := Model{
 		app:       app,
 		viewport:  vp,
 		diffStyle: DiffStyleUnified,
+		line:      -1,
+		positions: map[string]int{},
 	}
 	if app.State.SplitDiff {
 		m.diffStyle = DiffStyleSplit
@@ -60,39 +127,134 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case fileRenderedMsg:
-		m.viewport.SetContent(msg.content)
-		return m, util.CmdHandler(app.FileRenderedMsg{
+		var cmd tea.Cmd
+		m.setSource(msg.source)
+		switch {
+		case msg.hunks != nil:
+			m.hunkViews = msg.hunks
+			m.layoutHunks()
+			m.scrollToRow()
+		case msg.source != nil:
+			m.hunkViews = nil
+			m.hunkOffsets = nil
+			m.viewport.SetContentLines(msg.source.Rows())
+			if m.line >= 0 {
+				line := m.line
+				m.line = -1
+				cmd = m.gotoLine(line)
+			}
+		default:
+			m.hunkViews = nil
+			m.hunkOffsets = nil
+			m.viewport.SetContent(msg.content)
+			m.scrollToRow()
+		}
+		m.refind()
+		return m, tea.Batch(cmd, util.CmdHandler(app.FileRenderedMsg{
 			FilePath: *m.filename,
-		})
+		}))
+	case pageLoadedMsg:
+		m.loading = false
+		if m.filename == nil || *m.filename != msg.filename || msg.offset != m.loadedLines() {
+			return m, nil
+		}
+		if msg.err != nil {
+			slog.Error("Failed to read file page", "file", msg.filename, "offset", msg.offset, "error", msg.err)
+			return m, nil
+		}
+		content := *m.content + "\n" + msg.content
+		m.content = &content
+		return m, m.render()
+	case hunksLoadedMsg:
+		if m.filename == nil || *m.filename != msg.filename {
+			return m, nil
+		}
+		if msg.err != nil {
+			slog.Debug("Failed to read the hunks of the file", "file", msg.filename, "error", msg.err)
+		}
+		m.hunks = msg.hunks
+		m.hunk = min(m.hunk, max(len(m.hunks)-1, 0))
+		return m, m.render()
 	case dialog.ThemeSelectedMsg:
 		return m, m.render()
+	case tea.KeyPressMsg:
+		if !m.focused {
+			return m, nil
+		}
+		if m.Prompting() {
+			return m.updatePrompt(msg)
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		// TODO
+		// keys only scroll the viewer while its pane has focus
+		if !m.focused {
+			return m, nil
 		}
 	}
 
+	if m.Prompting() {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	vp, cmd := m.viewport.Update(msg)
 	m.viewport = vp
-	cmds = append(cmds, cmd)
+	cmds = append(cmds, cmd, m.loadMore())
 
 	return m, tea.Batch(cmds...)
 }
 
+// loadedLines returns the number of lines of the file loaded so far
+func (m Model) loadedLines() int {
+	if m.content == nil {
+		return 0
+	}
+	return strings.Count(*m.content, "\n") + 1
+}
+
+// HasMore reports whether a large file has lines left to load
+func (m Model) HasMore() bool {
+	return m.total > 0 && m.loadedLines() < m.total
+}
+
+// loadMore reads the next page of a large file once scrolling nears the end
+// of what is loaded
+func (m *Model) loadMore() tea.Cmd {
+	if m.viewport.ScrollPercent() < 0.8 {
+		return nil
+	}
+	return m.loadPage()
+}
+
+// loadPage reads the next page of a large file
+func (m *Model) loadPage() tea.Cmd {
+	if m.loading || !m.HasMore() {
+		return nil
+	}
+	m.loading = true
+	filename := *m.filename
+	offset := m.loadedLines()
+	client := m.app.Client
+	return func() tea.Msg {
+		response, err := client.File.Read(context.Background(), opencode.FileReadParams{
+			Path:   opencode.F(filename),
+			Offset: opencode.F(int64(offset)),
+			Limit:  opencode.F(int64(PageSize)),
+		})
+		if err != nil {
+			return pageLoadedMsg{filename: filename, offset: offset, err: err}
+		}
+		return pageLoadedMsg{filename: filename, offset: offset, content: response.Content}
+	}
+}
+
 func (m Model) View() string {
 	if !m.HasFile() {
 		return ""
 	}
 
-	header := *m.filename
-	header = styles.NewStyle().
-		Padding(1, 2).
-		Width(m.width).
-		Background(theme.CurrentTheme().BackgroundElement()).
-		Foreground(theme.CurrentTheme().Text()).
-		Render(header)
-
 	t := theme.CurrentTheme()
+	header := m.header()
 
 	close := m.app.Key(commands.FileCloseCommand)
 	diffToggle := m.app.Key(commands.FileDiffToggleCommand)
@@ -100,6 +262,11 @@ func (m Model) View() string {
 		diffToggle = ""
 	}
 	layoutToggle := m.app.Key(commands.MessagesLayoutToggleCommand)
+	focus := m.app.Key(commands.FileFocusCommand)
+	stage := ""
+	if m.showsHunks() && m.focused {
+		stage = m.app.Key(commands.FileHunkStageCommand)
+	}
 
 	background := t.Background()
 	footer := layout.Render(
@@ -120,16 +287,91 @@ func (m Model) View() string {
 		layout.FlexItem{
 			View: diffToggle,
 		},
+		layout.FlexItem{
+			View: focus,
+		},
+		layout.FlexItem{
+			View: stage,
+		},
 	)
 	footer = styles.NewStyle().Background(t.Background()).Padding(0, 1).Render(footer)
+	if m.Prompting() {
+		footer = styles.NewStyle().
+			Background(t.BackgroundElement()).
+			Width(m.width).
+			Padding(0, 1).
+			Render(m.input.View())
+	}
 
 	return header + "\n" + m.viewport.View() + "\n" + footer
 }
 
+// header renders the name of the file above its content
+func (m Model) header() string {
+	t := theme.CurrentTheme()
+	headerStyle := styles.NewStyle().
+		Padding(1, 2).
+		Width(m.width).
+		Background(t.BackgroundElement()).
+		Foreground(t.Text())
+	if m.focused {
+		headerStyle = headerStyle.Foreground(t.Primary()).Bold(true)
+	}
+	title := *m.filename
+	if m.total > 0 {
+		title += fmt.Sprintf(" (%d of %d lines)", m.loadedLines(), m.total)
+	}
+	if m.showsHunks() {
+		title += fmt.Sprintf(" (hunk %d of %d)", m.hunk+1, len(m.hunks))
+	}
+	if m.query != "" {
+		switch count := m.viewport.HighlightCount(); {
+		case count == 0:
+			title += " (no matches)"
+		case m.viewport.HighlightIndex() >= 0:
+			title += fmt.Sprintf(" (match %d of %d)", m.viewport.HighlightIndex()+1, count)
+		default:
+			title += fmt.Sprintf(" (%d matches)", count)
+		}
+	}
+	return headerStyle.Render(title)
+}
+
+// setSource shows the rows of a file laid out by newSource, or the rendered
+// content of a diff when source is nil
+func (m *Model) setSource(source *source) {
+	m.source = source
+	if source == nil {
+		m.viewport.LeftGutterFunc = viewport.NoGutter
+		m.viewport.RenderLineFunc = nil
+		return
+	}
+	m.viewport.LeftGutterFunc = source.Gutter
+	m.viewport.RenderLineFunc = source.Render
+}
+
+// scrollToRow scrolls a diff back to where it was left
+func (m *Model) scrollToRow() {
+	if m.line >= 0 {
+		m.viewport.SetYOffset(m.line)
+		m.line = -1
+	}
+}
+
 func (m *Model) Clear() (Model, tea.Cmd) {
+	m.remember()
+	m.focused = false
 	m.filename = nil
 	m.content = nil
 	m.isDiff = nil
+	m.total = 0
+	m.loading = false
+	m.hunks = nil
+	m.hunk = 0
+	m.clicked = nil
+	m.line = -1
+	m.prompt = promptNone
+	m.query = ""
 	return *m, m.render()
 }
 
@@ -143,6 +385,18 @@ func (m *Model) ToggleDiff() (Model, tea.Cmd) {
 	return *m, m.render()
 }
 
+// SetFocused sets whether key presses scroll the viewer
+func (m *Model) SetFocused(focused bool) {
+	m.focused = focused
+	if !focused {
+		m.prompt = promptNone
+	}
+}
+
+func (m Model) Focused() bool {
+	return m.focused
+}
+
 func (m *Model) DiffStyle() DiffStyle {
 	return m.diffStyle
 }
@@ -170,51 +424,98 @@ func (m *Model) SetSize(width, height int) (Model, tea.Cmd) {
 }
 
 func (m *Model) SetFile(filename string, content string, isDiff bool) (Model, tea.Cmd) {
+	// a file opened again keeps its selected hunk and search, another one
+	// opens where it was left
+	if m.filename == nil || *m.filename != filename {
+		m.remember()
+		m.visit(filename)
+		m.hunk = 0
+		m.prompt = promptNone
+		m.query = ""
+		m.line = -1
+		if position, ok := m.positions[filename]; ok {
+			m.line = position
+		}
+	}
 	m.filename = &filename
 	m.content = &content
 	m.isDiff = &isDiff
-	return *m, m.render()
+	m.total = 0
+	m.loading = false
+	m.hunks = nil
+	m.clicked = nil
+	if !isDiff {
+		return *m, m.render()
+	}
+	return *m, tea.Batch(m.render(), m.loadHunks())
+}
+
+// loadHunks reads the git hunks of the open file
+func (m *Model) loadHunks() tea.Cmd {
+	filename := *m.filename
+	root := m.app.Info.Path.Cwd
+	return func() tea.Msg {
+		hunks, err := git.FileHunks(root, filename)
+		return hunksLoadedMsg{filename: filename, hunks: hunks, err: err}
+	}
+}
+
+// showsHunks reports whether the file is shown by its git hunks
+func (m Model) showsHunks() bool {
+	return m.isDiff != nil && *m.isDiff && len(m.hunks) > 0
+}
+
+// SetTotalLines marks the file as read a page at a time, the rest of its
+// lines are loaded as the viewer scrolls
+func (m *Model) SetTotalLines(total int) {
+	m.total = total
 }
 
 func (m *Model) render() tea.Cmd {
 	if m.filename == nil || m.content == nil {
+		m.setSource(nil)
 		m.viewport.SetContent("")
 		return nil
 	}
 
+	if m.showsHunks() {
+		return m.renderHunks()
+	}
+
+	if m.isDiff == nil || !*m.isDiff {
+		filename := *m.filename
+		content := *m.content
+		width := m.width
+		return func() tea.Msg {
+			return fileRenderedMsg{source: newSource(filename, content, width)}
+		}
+	}
+
 	return func() tea.Msg {
 		t := theme.CurrentTheme()
 		var rendered string
 
-		if m.isDiff != nil && *m.isDiff {
-			diffResult := ""
-			var err error
-			if m.diffStyle == DiffStyleSplit {
-				diffResult, err = diff.FormatDiff(
-					*m.filename,
-					*m.content,
-					diff.WithWidth(m.width),
-				)
-			} else if m.diffStyle == DiffStyleUnified {
-				diffResult, err = diff.FormatUnifiedDiff(
-					*m.filename,
-					*m.content,
-					diff.WithWidth(m.width),
-				)
-			}
-			if err != nil {
-				rendered = styles.NewStyle().
-					Foreground(t.Error()).
-					Render(fmt.Sprintf("Error rendering diff: %v", err))
-			} else {
-				rendered = strings.TrimRight(diffResult, "\n")
-			}
-		} else {
-			rendered = util.RenderFile(
+		diffResult := ""
+		var err error
+		if m.diffStyle == DiffStyleSplit {
+			diffResult, err = diff.FormatDiff(
 				*m.filename,
 				*m.content,
-				m.width,
+				diff.WithWidth(m.width),
 			)
+		} else if m.diffStyle == DiffStyleUnified {
+			diffResult, err = diff.FormatUnifiedDiff(
+				*m.filename,
+				*m.content,
+				diff.WithWidth(m.width),
+			)
+		}
+		if err != nil {
+			rendered = styles.NewStyle().
+				Foreground(t.Error()).
+				Render(fmt.Sprintf("Error rendering diff: %v", err))
+		} else {
+			rendered = strings.TrimRight(diffResult, "\n")
 		}
 
 		rendered = styles.NewStyle().
@@ -228,6 +529,128 @@ func (m *Model) render() tea.Cmd {
 	}
 }
 
+// renderHunks renders each hunk on its own, the labels marking the staged
+// and selected ones are added by layoutHunks
+func (m *Model) renderHunks() tea.Cmd {
+	filename := *m.filename
+	content := *m.content
+	hunks := m.hunks
+	width := m.width
+	split := m.diffStyle == DiffStyleSplit
+	return func() tea.Msg {
+		views := make([]string, len(hunks))
+		for i, hunk := range hunks {
+			patch := hunk.Patch
+			if hunk.Untracked() {
+				// a new file is the patch the server made for it
+				patch = content
+			}
+			parsed, err := diff.ParseUnifiedDiff(patch)
+			if err != nil {
+				views[i] = err.Error()
+				continue
+			}
+			var b strings.Builder
+			for _, h := range parsed.Hunks {
+				if split {
+					b.WriteString(diff.RenderSideBySideHunk(filename, h, diff.WithWidth(width)))
+				} else {
+					b.WriteString(diff.RenderUnifiedHunk(filename, h, diff.WithWidth(width)))
+				}
+			}
+			views[i] = strings.TrimRight(b.String(), "\n")
+		}
+		return fileRenderedMsg{hunks: views}
+	}
+}
+
+// layoutHunks puts the rendered hunks below their labels and notes the line
+// each starts at
+func (m *Model) layoutHunks() {
+	t := theme.CurrentTheme()
+	if len(m.hunkViews) != len(m.hunks) {
+		return
+	}
+	labelStyle := styles.NewStyle().
+		Width(m.width).
+		Padding(0, 1).
+		Background(t.BackgroundElement()).
+		Foreground(t.TextMuted())
+	var b strings.Builder
+	m.hunkOffsets = make([]int, len(m.hunks))
+	line := 0
+	for i, hunk := range m.hunks {
+		state := "unstaged"
+		switch {
+		case hunk.Staged:
+			state = "staged"
+		case hunk.Untracked():
+			state = "untracked"
+		}
+		style := labelStyle
+		if hunk.Staged {
+			style = style.Foreground(t.Success())
+		}
+		if i == m.hunk {
+			style = style.Background(t.Primary()).Foreground(t.BackgroundPanel()).Bold(true)
+		}
+		label := style.Render(state + "  " + hunk.Header)
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		m.hunkOffsets[i] = line
+		b.WriteString(label + "\n" + m.hunkViews[i])
+		line += strings.Count(m.hunkViews[i], "\n") + 2
+	}
+	m.viewport.SetContent(styles.NewStyle().
+		Width(m.width).
+		Background(t.BackgroundPanel()).
+		Render(b.String()))
+}
+
+// NextHunk selects the next hunk of the file and scrolls to it
+func (m *Model) NextHunk() (Model, tea.Cmd) {
+	return m.selectHunk(m.hunk + 1)
+}
+
+// PreviousHunk selects the previous hunk of the file and scrolls to it
+func (m *Model) PreviousHunk() (Model, tea.Cmd) {
+	return m.selectHunk(m.hunk - 1)
+}
+
+func (m *Model) selectHunk(index int) (Model, tea.Cmd) {
+	if !m.showsHunks() || index < 0 || index >= len(m.hunks) {
+		return *m, nil
+	}
+	m.hunk = index
+	m.layoutHunks()
+	if index < len(m.hunkOffsets) {
+		m.viewport.SetYOffset(m.hunkOffsets[index])
+	}
+	return *m, nil
+}
+
+// ToggleHunk stages the selected hunk, or unstages it when it is staged,
+// then reads the hunks again
+func (m *Model) ToggleHunk() (Model, tea.Cmd) {
+	if !m.showsHunks() {
+		return *m, nil
+	}
+	if m.app.ReadOnly {
+		return *m, toast.NewInfoToast(app.ReadOnlyMessage)
+	}
+	hunk := m.hunks[m.hunk]
+	root := m.app.Info.Path.Cwd
+	filename := *m.filename
+	return *m, func() tea.Msg {
+		if err := git.Toggle(root, hunk); err != nil {
+			return toast.NewErrorToast(err.Error())()
+		}
+		hunks, err := git.FileHunks(root, filename)
+		return hunksLoadedMsg{filename: filename, hunks: hunks, err: err}
+	}
+}
+
 func (m *Model) ScrollTo(line int) {
 	m.viewport.SetYOffset(line)
 }
@@ -240,6 +663,26 @@ func (m *Model) ScrollToTop() {
 	m.viewport.GotoTop()
 }
 
+func (m *Model) LineUp() (Model, tea.Cmd) {
+	m.viewport.LineUp(1)
+	return *m, nil
+}
+
+func (m *Model) LineDown() (Model, tea.Cmd) {
+	m.viewport.LineDown(1)
+	return *m, m.loadMore()
+}
+
+func (m *Model) GotoTop() (Model, tea.Cmd) {
+	m.viewport.GotoTop()
+	return *m, nil
+}
+
+func (m *Model) GotoBottom() (Model, tea.Cmd) {
+	m.viewport.GotoBottom()
+	return *m, m.loadMore()
+}
+
 func (m *Model) PageUp() (Model, tea.Cmd) {
 	m.viewport.ViewUp()
 	return *m, nil
@@ -247,7 +690,7 @@ func (m *Model) PageUp() (Model, tea.Cmd) {
 
 func (m *Model) PageDown() (Model, tea.Cmd) {
 	m.viewport.ViewDown()
-	return *m, nil
+	return *m, m.loadMore()
 }
 
 func (m *Model) HalfPageUp() (Model, tea.Cmd) {
@@ -257,7 +700,7 @@ func (m *Model) HalfPageUp() (Model, tea.Cmd) {
 
 func (m *Model) HalfPageDown() (Model, tea.Cmd) {
 	m.viewport.HalfViewDown()
-	return *m, nil
+	return *m, m.loadMore()
 }
 
 func (m Model) AtTop() bool {
@@ -279,3 +722,136 @@ func (m Model) TotalLineCount() int {
 func (m Model) VisibleLineCount() int {
 	return m.viewport.VisibleLineCount()
 }
+
+// CurrentLine returns the 0-based line of the file at the top of the view,
+// or the line the selected hunk of a diff starts at
+func (m Model) CurrentLine() int {
+	if m.source != nil && m.viewport.YOffset < len(m.source.rows) {
+		return m.source.rows[m.viewport.YOffset].line
+	}
+	if m.showsHunks() {
+		return m.hunks[m.hunk].Line()
+	}
+	return 0
+}
+
+// GotoLine scrolls to the 0-based source line once the file is rendered,
+// diffs are left where they are
+func (m *Model) GotoLine(line int) {
+	if m.isDiff != nil && *m.isDiff {
+		return
+	}
+	m.line = max(line, 0)
+}
+
+// position is a name in the file, where language servers look it up
+type position struct {
+	line      int
+	character int
+	word      string
+}
+
+// Click notes the name at x, y of the view, goto definition looks it up
+func (m *Model) Click(x, y int) {
+	m.clicked = nil
+	if !m.HasFile() || m.source == nil {
+		return
+	}
+	row := y - lipgloss.Height(m.header())
+	lines := strings.Split(m.viewport.View(), "\n")
+	if row < 0 || row >= len(lines) || x < m.source.gutterWidth() {
+		return
+	}
+	word := wordAt(ansi.Strip(lines[row]), x)
+	if word == "" {
+		return
+	}
+	line, character, ok := m.source.Position(m.viewport.YOffset+row, x-m.source.gutterWidth())
+	if ok {
+		m.clicked = &position{line: line, character: character, word: word}
+	}
+}
+
+// target is the name goto definition looks up, the one clicked last or else
+// the selected match of a search
+func (m *Model) target() (position, bool) {
+	if m.clicked != nil {
+		return *m.clicked, true
+	}
+	row, col, ok := m.viewport.HighlightPosition()
+	if !ok || row >= len(m.source.rows) {
+		return position{}, false
+	}
+	line, character, ok := m.source.Position(row, col)
+	if !ok {
+		return position{}, false
+	}
+	return position{line: line, character: character, word: wordAt(m.source.rows[row].text, col)}, true
+}
+
+// wordAt returns the identifier at column col of line
+func wordAt(line string, col int) string {
+	runes := []rune(line)
+	if col < 0 || col >= len(runes) || !isWordRune(runes[col]) {
+		return ""
+	}
+	start, end := col, col
+	for start > 0 && isWordRune(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isWordRune(runes[end]) {
+		end++
+	}
+	return string(runes[start:end])
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// GotoDefinition asks the language servers where the name clicked last, or
+// the selected match of a search, is defined and opens it there
+func (m *Model) GotoDefinition() tea.Cmd {
+	if !m.HasFile() {
+		return nil
+	}
+	if m.source == nil {
+		return toast.NewInfoToast("Go to definition works in files, not diffs")
+	}
+	target, ok := m.target()
+	if !ok {
+		return toast.NewInfoToast("Click a name or find it with / first")
+	}
+	name := target.word
+	if name == "" {
+		name = fmt.Sprintf("line %d", target.line+1)
+	}
+	filename := *m.filename
+	client := m.app.Client
+	return func() tea.Msg {
+		locations, err := client.Find.Definition(
+			context.Background(),
+			opencode.FindDefinitionParams{
+				Path:      opencode.F(filename),
+				Line:      opencode.F(int64(target.line)),
+				Character: opencode.F(int64(target.character)),
+			},
+		)
+		if err != nil {
+			slog.Error("Failed to find definition", "error", err)
+			return toast.NewErrorToast("Failed to find the definition of " + name)()
+		}
+		if locations == nil || len(*locations) == 0 {
+			return toast.NewInfoToast("No definition found for " + name)()
+		}
+		location := (*locations)[0]
+		path := strings.TrimPrefix(location.Uri, "file://")
+		if uri, err := url.Parse(location.Uri); err == nil && uri.Scheme == "file" {
+			path = uri.Path
+		}
+		return app.OpenFileMsg{
+			Path: util.Relative(path),
+			Line: int(location.Range.Start.Line),
+		}
+	}
+}
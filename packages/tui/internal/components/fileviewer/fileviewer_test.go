@@ -0,0 +1,142 @@
+package fileviewer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/viewport"
+)
+
+func TestWordAt(t *testing.T) {
+	tests := []struct {
+		line string
+		col  int
+		want string
+	}{
+		{"  return app.OpenFileMsg{Path: path}", 10, "app"},
+		{"  return app.OpenFileMsg{Path: path}", 14, "OpenFileMsg"},
+		{"  return app.OpenFileMsg{Path: path}", 12, ""},
+		{"const $el = snake_case", 7, "$el"},
+		{"const $el = snake_case", 16, "snake_case"},
+		{"short", 40, ""},
+	}
+	for _, tt := range tests {
+		if got := wordAt(tt.line, tt.col); got != tt.want {
+			t.Errorf("wordAt(%q, %d) = %q, want %q", tt.line, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		t.Fatal(err)
+	}
+	theme.SetTheme("opencode")
+
+	content := "package main\n\nfunc main() {\n\tprintln(\"" + strings.Repeat("x", 30) + "\")\n}"
+	s := newSource("main.go", content, 24)
+	if s.width != 21 {
+		t.Fatalf("width = %d, want 21", s.width)
+	}
+	rows := s.Rows()
+	if len(rows) != 7 {
+		t.Fatalf("got %d rows, want 7: %q", len(rows), rows)
+	}
+	if got := s.Row(4); got != 6 {
+		t.Errorf("Row(4) = %d, want 6", got)
+	}
+	if got := ansi.Strip(s.Gutter(viewport.GutterContext{Index: 4})); got != "   " {
+		t.Errorf("the gutter of a wrapped row = %q, want blank", got)
+	}
+	if got := ansi.Strip(s.Gutter(viewport.GutterContext{Index: 6})); got != " 5 " {
+		t.Errorf("the gutter of line 5 = %q", got)
+	}
+	if s.tokens == nil {
+		t.Fatal("the file wasn't tokenised")
+	}
+	for i, row := range rows {
+		rendered := s.Render(i, row)
+		if got := ansi.Strip(rendered); strings.TrimRight(got, " ") != strings.TrimRight(row, " ") {
+			t.Errorf("row %d renders %q, want %q", i, got, row)
+		}
+		if ansi.StringWidth(rendered) != s.width {
+			t.Errorf("row %d is %d cells wide, want %d", i, ansi.StringWidth(rendered), s.width)
+		}
+	}
+
+	tabbed := newSource("tabs.go", "\tx := \"é\" + y", 80)
+	positions := []struct {
+		col, character int
+		ok             bool
+	}{
+		{0, 0, true},
+		{2, 1, true},
+		{7, 6, true},
+		{12, 11, true},
+		{40, 0, false},
+	}
+	for _, tt := range positions {
+		_, character, ok := tabbed.Position(0, tt.col)
+		if character != tt.character || ok != tt.ok {
+			t.Errorf("Position(0, %d) = %d, %v, want %d, %v", tt.col, character, ok, tt.character, tt.ok)
+		}
+	}
+
+	large := newSource("large.go", strings.Repeat("x", highlightMaxBytes+1), 80)
+	if large.tokens != nil {
+		t.Error("a file above the cutoff was tokenised")
+	}
+}
+
+func TestFindMatches(t *testing.T) {
+	content := "Model\nfunc (m Model) View()\nmodel := New()"
+	tests := []struct {
+		query string
+		want  [][]int
+	}{
+		{"model", [][]int{{0, 5}, {14, 19}, {28, 33}}},
+		{"Model", [][]int{{0, 5}, {14, 19}}},
+		{"()", [][]int{{25, 27}, {40, 42}}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		if got := findMatches(content, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("findMatches(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	m := Model{line: -1, positions: map[string]int{}}
+	for _, path := range []string{"a.go", "b.go", "c.go"} {
+		m.SetFile(path, "package main", false)
+	}
+	if path, ok := m.Back(); !ok || path != "b.go" {
+		t.Fatalf("Back() = %q, %v, want b.go", path, ok)
+	}
+	m.SetFile("b.go", "package main", false)
+	if got := m.History(); !reflect.DeepEqual(got, []string{"c.go", "b.go", "a.go"}) {
+		t.Errorf("History() = %v", got)
+	}
+	if path, ok := m.Forward(); !ok || path != "c.go" {
+		t.Fatalf("Forward() = %q, %v, want c.go", path, ok)
+	}
+	m.SetFile("c.go", "package main", false)
+	if _, ok := m.Forward(); ok {
+		t.Error("went forward past the last file")
+	}
+
+	// opening a file after going back drops the files gone back from
+	m.Back()
+	m.SetFile("b.go", "package main", false)
+	m.SetFile("d.go", "package main", false)
+	if got := m.history; !reflect.DeepEqual(got, []string{"a.go", "b.go", "d.go"}) {
+		t.Errorf("history = %v", got)
+	}
+	if _, ok := m.positions["b.go"]; !ok {
+		t.Error("the position of b.go wasn't kept")
+	}
+}
@@ -0,0 +1,183 @@
+package fileviewer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// promptKind is what the line typed below the file is for
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptFind
+	promptLine
+)
+
+// Prompting reports whether keys are typed into the find or go to line
+// prompt
+func (m Model) Prompting() bool {
+	return m.prompt != promptNone
+}
+
+// StartFind opens the prompt for searching the text of the file
+func (m *Model) StartFind() (Model, tea.Cmd) {
+	if !m.HasFile() {
+		return *m, nil
+	}
+	return *m, m.startPrompt(promptFind, "/", m.query)
+}
+
+// StartGotoLine opens the prompt for a line to go to
+func (m *Model) StartGotoLine() (Model, tea.Cmd) {
+	if !m.HasFile() {
+		return *m, nil
+	}
+	if m.source == nil {
+		return *m, toast.NewInfoToast("Go to line works in files, not diffs")
+	}
+	return *m, m.startPrompt(promptLine, ":", "")
+}
+
+func (m *Model) startPrompt(kind promptKind, prompt, value string) tea.Cmd {
+	t := theme.CurrentTheme()
+	input := textinput.New()
+	input.Prompt = prompt
+	input.Styles.Focused.Prompt = styles.NewStyle().
+		Foreground(t.Primary()).
+		Background(t.BackgroundElement()).
+		Lipgloss()
+	input.Styles.Focused.Text = styles.NewStyle().
+		Foreground(t.Text()).
+		Background(t.BackgroundElement()).
+		Lipgloss()
+	input.Styles.Cursor.Color = t.Primary()
+	input.Styles.Cursor.Blink = !styles.ReduceMotion
+	input.VirtualCursor = true
+	input.CharLimit = -1
+	input.SetWidth(max(m.width-4, 1))
+	input.SetValue(value)
+	input.CursorEnd()
+	m.input = input
+	m.prompt = kind
+	return m.input.Focus()
+}
+
+// updatePrompt types into the prompt, the file is searched as the text
+// changes. enter goes to the line or keeps the matches, esc cancels.
+func (m Model) updatePrompt(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		if m.prompt == promptFind {
+			m.find("")
+		}
+		m.prompt = promptNone
+		return m, nil
+	case "enter":
+		kind := m.prompt
+		m.prompt = promptNone
+		if kind == promptLine {
+			line, err := strconv.Atoi(strings.TrimSpace(m.input.Value()))
+			if err != nil || line < 1 {
+				return m, toast.NewInfoToast("Type the number of a line")
+			}
+			return m, m.gotoLine(line - 1)
+		}
+		if m.query != "" && m.viewport.HighlightCount() == 0 {
+			return m, toast.NewInfoToast("No matches for " + m.query)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.prompt == promptFind && m.input.Value() != m.query {
+		m.clicked = nil
+		m.find(m.input.Value())
+	}
+	return m, cmd
+}
+
+// gotoLine scrolls to the 0-based line, reading the pages of a large file up
+// to it first
+func (m *Model) gotoLine(line int) tea.Cmd {
+	if m.source == nil {
+		return nil
+	}
+	if line >= m.loadedLines() && m.HasMore() {
+		m.line = line
+		return m.loadPage()
+	}
+	m.viewport.SetYOffset(m.source.Row(line))
+	return nil
+}
+
+// find highlights every match of query, ignoring case unless it has upper
+// case letters
+func (m *Model) find(query string) {
+	m.query = query
+	m.viewport.ClearHighlights()
+	if query == "" {
+		return
+	}
+	t := theme.CurrentTheme()
+	m.viewport.HighlightStyle = styles.NewStyle().
+		Foreground(t.BackgroundPanel()).
+		Background(t.Accent()).
+		Lipgloss()
+	m.viewport.SelectedHighlightStyle = styles.NewStyle().
+		Foreground(t.BackgroundPanel()).
+		Background(t.Primary()).
+		Bold(true).
+		Lipgloss()
+	m.viewport.SetHighlights(findMatches(ansi.Strip(m.viewport.GetContent()), query))
+}
+
+// refind highlights the matches again once the file is rendered again,
+// leaving the view where it is
+func (m *Model) refind() {
+	if m.query == "" {
+		return
+	}
+	offset := m.viewport.YOffset
+	m.find(m.query)
+	m.viewport.SetYOffset(offset)
+}
+
+// findMatches returns the byte ranges of the matches of query in content
+func findMatches(content, query string) [][]int {
+	pattern := regexp.QuoteMeta(query)
+	if !strings.ContainsFunc(query, unicode.IsUpper) {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.MustCompile(pattern).FindAllStringIndex(content, -1)
+}
+
+// FindNext moves to the next match
+func (m *Model) FindNext() (Model, tea.Cmd) {
+	if m.query == "" {
+		return m.StartFind()
+	}
+	m.clicked = nil
+	m.viewport.HighlightNext()
+	return *m, m.loadMore()
+}
+
+// FindPrevious moves to the previous match
+func (m *Model) FindPrevious() (Model, tea.Cmd) {
+	if m.query == "" {
+		return m.StartFind()
+	}
+	m.clicked = nil
+	m.viewport.HighlightPrevious()
+	return *m, nil
+}
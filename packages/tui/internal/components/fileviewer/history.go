@@ -0,0 +1,63 @@
+package fileviewer
+
+// maxHistory is the number of files viewed kept for going back to
+const maxHistory = 50
+
+// History returns the paths of the files viewed, the most recent first
+func (m Model) History() []string {
+	var paths []string
+	seen := map[string]bool{}
+	for i := len(m.history) - 1; i >= 0; i-- {
+		if !seen[m.history[i]] {
+			seen[m.history[i]] = true
+			paths = append(paths, m.history[i])
+		}
+	}
+	return paths
+}
+
+// Back returns the file viewed before the one history is at, moving back
+// to it
+func (m *Model) Back() (string, bool) {
+	if m.at <= 0 || m.at > len(m.history)-1 {
+		return "", false
+	}
+	m.at--
+	return m.history[m.at], true
+}
+
+// Forward returns the file viewed after the one history is at, moving
+// forward to it
+func (m *Model) Forward() (string, bool) {
+	if m.at >= len(m.history)-1 {
+		return "", false
+	}
+	m.at++
+	return m.history[m.at], true
+}
+
+// visit adds a file opened to the history, dropping the files gone back
+// from. A file history is already at was opened going back or forward.
+func (m *Model) visit(filename string) {
+	if m.at < len(m.history) && m.history[m.at] == filename {
+		return
+	}
+	m.history = append(m.history[:min(m.at+1, len(m.history))], filename)
+	if len(m.history) > maxHistory {
+		m.history = m.history[len(m.history)-maxHistory:]
+	}
+	m.at = len(m.history) - 1
+}
+
+// remember notes where the open file is scrolled to, so it opens there
+// again: the first line in view of a file, or the row of a diff
+func (m *Model) remember() {
+	if m.filename == nil {
+		return
+	}
+	position := m.viewport.YOffset
+	if m.source != nil && position < len(m.source.rows) {
+		position = m.source.rows[position].line
+	}
+	m.positions[*m.filename] = position
+}
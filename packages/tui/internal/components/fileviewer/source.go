@@ -0,0 +1,179 @@
+package fileviewer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/components/diff"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/viewport"
+)
+
+// highlightMaxBytes is the size above which files are shown without syntax
+// highlighting, tokenising them would hold up the viewer
+const highlightMaxBytes = 512 * 1024
+
+// source is a file laid out in rows of the viewer's width. The file is
+// tokenised once, a line is only colored when one of its rows comes into
+// view.
+type source struct {
+	lines []string
+	// raw are the lines as in the file, before tabs are expanded
+	raw []string
+	// tokens are the tokens of each line, nil when the file isn't highlighted
+	tokens [][]chroma.Token
+	style  *chroma.Style
+	// rows are the rows of the view, long lines wrap over more than one
+	rows []sourceRow
+	// first is the first row of each line
+	first  []int
+	width  int
+	digits int
+	// colored caches the lines colored so far
+	colored map[int]string
+}
+
+// sourceRow is a row of the view, the part of a line starting at a cell
+type sourceRow struct {
+	line  int
+	start int
+	text  string
+}
+
+// newSource lays out content in rows of width cells, including the line
+// numbers, and tokenises it when it isn't too large
+func newSource(filename, content string, width int) *source {
+	t := theme.CurrentTheme()
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	s := &source{colored: map[int]string{}}
+	for line := range strings.SplitSeq(content, "\n") {
+		s.raw = append(s.raw, line)
+		s.lines = append(s.lines, expandTabs(line))
+	}
+	s.digits = len(fmt.Sprint(len(s.lines)))
+	s.width = max(width-s.gutterWidth(), 1)
+	for i, line := range s.lines {
+		s.first = append(s.first, len(s.rows))
+		if ansi.StringWidth(line) <= s.width {
+			s.rows = append(s.rows, sourceRow{line: i, text: line})
+			continue
+		}
+		start := 0
+		for text := range strings.SplitSeq(ansi.Hardwrap(line, s.width, true), "\n") {
+			s.rows = append(s.rows, sourceRow{line: i, start: start, text: text})
+			start += ansi.StringWidth(text)
+		}
+	}
+
+	if len(content) > highlightMaxBytes {
+		return s
+	}
+	tokens, err := diff.SyntaxLexer(filename, content).Tokenise(nil, content)
+	if err != nil {
+		return s
+	}
+	s.style = diff.SyntaxStyle(t.BackgroundPanel())
+	for _, line := range chroma.SplitTokensIntoLines(tokens.Tokens()) {
+		for i := range line {
+			line[i].Value = expandTabs(strings.TrimRight(line[i].Value, "\n"))
+		}
+		s.tokens = append(s.tokens, line)
+	}
+	return s
+}
+
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", "  ")
+}
+
+// Rows returns the plain text of each row
+func (s *source) Rows() []string {
+	rows := make([]string, len(s.rows))
+	for i, row := range s.rows {
+		rows[i] = row.text
+	}
+	return rows
+}
+
+// Row returns the row of the view the line starts on
+func (s *source) Row(line int) int {
+	if len(s.first) == 0 {
+		return 0
+	}
+	return s.first[min(max(line, 0), len(s.first)-1)]
+}
+
+// Position returns the line of a cell of the view and its offset in that
+// line of the file, in UTF-16 code units like language servers count them
+func (s *source) Position(row, col int) (line, character int, ok bool) {
+	if row < 0 || row >= len(s.rows) || col < 0 {
+		return 0, 0, false
+	}
+	line = s.rows[row].line
+	cell := s.rows[row].start + col
+	width := 0
+	for _, r := range s.raw[line] {
+		w := ansi.StringWidth(string(r))
+		if r == '\t' {
+			w = len(expandTabs("\t"))
+		}
+		if width+w > cell {
+			return line, character, true
+		}
+		width += w
+		character += utf16.RuneLen(r)
+	}
+	return 0, 0, false
+}
+
+// Render colors a row as it comes into view
+func (s *source) Render(index int, plain string) string {
+	if index < 0 || index >= len(s.rows) {
+		return plain
+	}
+	t := theme.CurrentTheme()
+	row := s.rows[index]
+	line := ansi.Cut(s.color(row.line), row.start, row.start+s.width)
+	padding := max(s.width-ansi.StringWidth(line), 0)
+	return line + styles.NewStyle().Background(t.BackgroundPanel()).Render(strings.Repeat(" ", padding))
+}
+
+// color returns a line colored by its tokens
+func (s *source) color(index int) string {
+	if colored, ok := s.colored[index]; ok {
+		return colored
+	}
+	t := theme.CurrentTheme()
+	colored := styles.NewStyle().
+		Foreground(t.Text()).
+		Background(t.BackgroundPanel()).
+		Render(s.lines[index])
+	if index < len(s.tokens) {
+		var b strings.Builder
+		err := formatters.TTY16m.Format(&b, s.style, chroma.Literator(s.tokens[index]...))
+		if err == nil {
+			colored = b.String()
+		}
+	}
+	s.colored[index] = colored
+	return colored
+}
+
+func (s *source) gutterWidth() int {
+	return s.digits + 2
+}
+
+// Gutter numbers the first row of each line
+func (s *source) Gutter(ctx viewport.GutterContext) string {
+	t := theme.CurrentTheme()
+	style := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel())
+	if ctx.Index >= len(s.rows) || s.rows[ctx.Index].start > 0 {
+		return style.Render(strings.Repeat(" ", s.gutterWidth()))
+	}
+	return style.Render(fmt.Sprintf(" %*d ", s.digits, s.rows[ctx.Index].line+1))
+}
@@ -1,12 +1,14 @@
 package list
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/layout"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 )
@@ -373,12 +375,48 @@ func (c *listComponent[T]) View() string {
 		}
 
 		title := c.renderItem(item, i == c.selectedIdx, maxWidth, c.baseStyle)
+		if c.isSelectable(item) {
+			title = layout.Mark(RowZone(c.stepsTo(i)), title)
+		}
 		listItems = append(listItems, title)
 	}
 
 	return strings.Join(listItems, "\n")
 }
 
+// RowZone is the clickable zone of the row the given number of selectable
+// rows below the selected one, negative above it
+func RowZone(steps int) string {
+	return "list:" + strconv.Itoa(steps)
+}
+
+// ParseRowZone returns the steps of a row zone
+func ParseRowZone(zone string) (int, bool) {
+	steps, ok := strings.CutPrefix(zone, "list:")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(steps)
+	return n, err == nil
+}
+
+// stepsTo counts the moves from the selected item to the item at index,
+// skipping items that cannot be selected like moving with the keys does
+func (c *listComponent[T]) stepsTo(index int) int {
+	steps := 0
+	for i := c.selectedIdx + 1; i <= index; i++ {
+		if c.isSelectable(c.items[i]) {
+			steps++
+		}
+	}
+	for i := index; i < c.selectedIdx; i++ {
+		if c.isSelectable(c.items[i]) {
+			steps--
+		}
+	}
+	return steps
+}
+
 // calculateViewport determines which items to show based on available space
 func (c *listComponent[T]) calculateViewport() (startIdx, endIdx int) {
 	items := c.items
@@ -208,3 +208,17 @@ func TestEmptyList(t *testing.T) {
 		t.Error("Expected IsEmpty() to return true for empty list")
 	}
 }
+
+func TestRowZones(t *testing.T) {
+	list := createTestList()
+	list.SetSelectedIndex(1)
+	for index, expected := range []int{-1, 0, 1} {
+		steps, ok := ParseRowZone(RowZone(list.stepsTo(index)))
+		if !ok || steps != expected {
+			t.Errorf("Expected %d steps to item %d, got %d", expected, index, steps)
+		}
+	}
+	if _, ok := ParseRowZone("status:cwd"); ok {
+		t.Error("Expected other zones not to parse as rows")
+	}
+}
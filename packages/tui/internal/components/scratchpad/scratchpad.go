@@ -0,0 +1,224 @@
+package scratchpad
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/dialog"
+	"github.com/sst/opencode/internal/components/textarea"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const height = 8
+
+// AttachMsg asks the editor to attach the scratchpad content to the prompt
+type AttachMsg struct {
+	Text string
+}
+
+// Model is a notes panel shown above the prompt editor. Its content is kept
+// per session in the app state.
+type Model struct {
+	app       *app.App
+	width     int
+	visible   bool
+	sessionID string
+	textarea  textarea.Model
+}
+
+func New(app *app.App) Model {
+	ta := textarea.New()
+	ta.Prompt = " "
+	ta.Placeholder = "Notes and snippets for this session..."
+	ta.ShowLineNumbers = false
+	ta.CharLimit = -1
+	ta.SetHeight(height)
+	ta = updateTextareaStyles(ta)
+
+	m := Model{
+		app:      app,
+		textarea: ta,
+	}
+	m.load(app.Session.ID)
+	return m
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case dialog.ThemeSelectedMsg:
+		m.textarea = updateTextareaStyles(m.textarea)
+		return m, nil
+	case app.SessionCreatedMsg:
+		// notes taken before the first prompt move to the new session
+		if m.sessionID == "" {
+			delete(m.app.State.Scratchpads, "")
+			m.sessionID = msg.Session.ID
+			return m, m.Save()
+		}
+		return m, nil
+	case app.SessionLoadedMsg, app.SessionClearedMsg:
+		if m.app.Session.ID == m.sessionID {
+			return m, nil
+		}
+		cmd := m.Save()
+		m.load(m.app.Session.ID)
+		return m, cmd
+	case tea.KeyPressMsg:
+		if !m.Focused() {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			m.textarea.Blur()
+			return m, m.Save()
+		case "ctrl+s":
+			return m.send()
+		case "ctrl+t":
+			return m.attach()
+		}
+		var cmd tea.Cmd
+		m.textarea, cmd = m.textarea.Update(msg)
+		return m, cmd
+	case tea.PasteMsg:
+		if !m.Focused() {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.textarea, cmd = m.textarea.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement()).Render
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Render
+
+	m.textarea.SetWidth(max(m.width-4, 1))
+
+	hints := muted("Scratchpad")
+	if m.Focused() {
+		hints += muted("   ") + base("ctrl+s") + muted(" send   ") +
+			base("ctrl+t") + muted(" attach   ") + base("esc") + muted(" back")
+	}
+	header := styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Width(m.width - 2).
+		PaddingLeft(1).
+		Render(hints)
+
+	borderForeground := t.Border()
+	if m.Focused() {
+		borderForeground = t.Primary()
+	}
+	return styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Width(m.width).
+		PaddingTop(1).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderForeground(borderForeground).
+		BorderBackground(t.Background()).
+		BorderLeft(true).
+		BorderRight(true).
+		Render(strings.Join([]string{header, m.textarea.View()}, "\n"))
+}
+
+func (m Model) Visible() bool {
+	return m.visible
+}
+
+func (m Model) Focused() bool {
+	return m.visible && m.textarea.Focused()
+}
+
+func (m Model) Value() string {
+	return m.textarea.Value()
+}
+
+// SetWidth sets the width of the panel, matching the prompt editor
+func (m *Model) SetWidth(width int) {
+	m.width = width
+}
+
+// Toggle shows and focuses the panel, or hides it when it already has focus
+func (m *Model) Toggle() (Model, tea.Cmd) {
+	if m.Focused() {
+		m.visible = false
+		m.textarea.Blur()
+		return *m, m.Save()
+	}
+	m.visible = true
+	return *m, m.textarea.Focus()
+}
+
+// Save stores the scratchpad content for the current session in the app state
+func (m *Model) Save() tea.Cmd {
+	value := m.textarea.Value()
+	if m.app.State.Scratchpads[m.sessionID] == value {
+		return nil
+	}
+	if strings.TrimSpace(value) == "" {
+		delete(m.app.State.Scratchpads, m.sessionID)
+	} else {
+		m.app.State.Scratchpads[m.sessionID] = value
+	}
+	return m.app.SaveState()
+}
+
+func (m *Model) load(sessionID string) {
+	m.sessionID = sessionID
+	m.textarea.Reset()
+	m.textarea.SetValue(m.app.State.Scratchpads[sessionID])
+}
+
+func (m Model) send() (Model, tea.Cmd) {
+	value := strings.TrimSpace(m.textarea.Value())
+	if value == "" {
+		return m, nil
+	}
+	m.textarea.Blur()
+	return m, tea.Batch(
+		m.Save(),
+		util.CmdHandler(app.SendPrompt(app.Prompt{Text: value})),
+	)
+}
+
+func (m Model) attach() (Model, tea.Cmd) {
+	value := strings.TrimSpace(m.textarea.Value())
+	if value == "" {
+		return m, nil
+	}
+	m.textarea.Blur()
+	return m, tea.Batch(
+		m.Save(),
+		util.CmdHandler(AttachMsg{Text: value}),
+	)
+}
+
+func updateTextareaStyles(ta textarea.Model) textarea.Model {
+	t := theme.CurrentTheme()
+	bgColor := t.BackgroundElement()
+	textColor := t.Text()
+	textMutedColor := t.TextMuted()
+
+	ta.Styles.Blurred.Base = styles.NewStyle().Foreground(textMutedColor).Background(bgColor).Lipgloss()
+	ta.Styles.Blurred.CursorLine = styles.NewStyle().Background(bgColor).Lipgloss()
+	ta.Styles.Blurred.Placeholder = styles.NewStyle().Foreground(textMutedColor).Background(bgColor).Lipgloss()
+	ta.Styles.Blurred.Text = styles.NewStyle().Foreground(textMutedColor).Background(bgColor).Lipgloss()
+	ta.Styles.Focused.Base = styles.NewStyle().Foreground(textColor).Background(bgColor).Lipgloss()
+	ta.Styles.Focused.CursorLine = styles.NewStyle().Background(bgColor).Lipgloss()
+	ta.Styles.Focused.Placeholder = styles.NewStyle().Foreground(textMutedColor).Background(bgColor).Lipgloss()
+	ta.Styles.Focused.Text = styles.NewStyle().Foreground(textColor).Background(bgColor).Lipgloss()
+	ta.Styles.Cursor.Color = t.Primary()
+	return ta
+}
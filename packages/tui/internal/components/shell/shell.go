@@ -0,0 +1,290 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+	"github.com/sst/opencode/internal/viewport"
+)
+
+// height is how many lines of output the panel shows at most
+const height = 12
+
+// AttachMsg asks the editor to attach the command's output to the prompt
+type AttachMsg struct {
+	Text string
+}
+
+// Model is a panel above the prompt editor streaming the output of the last
+// ! command of the session, colors included
+type Model struct {
+	app      *app.App
+	width    int
+	visible  bool
+	focused  bool
+	partID   string
+	command  string
+	output   string
+	status   opencode.ToolPartStateStatus
+	exitCode int
+	viewport viewport.Model
+}
+
+func New(app *app.App) Model {
+	vp := viewport.New()
+	// keys scroll the output while the panel has focus, see Update
+	vp.KeyMap = viewport.KeyMap{}
+	return Model{app: app, viewport: vp}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// as wide as the prompt editor
+		m.SetWidth(msg.Width - 4)
+	case opencode.EventListResponseEventMessagePartUpdated:
+		part := msg.Properties.Part
+		if part.SessionID != m.app.Session.ID || part.Type != opencode.PartTypeTool || part.Tool != "shell" {
+			return m, nil
+		}
+		if tool, ok := part.AsUnion().(opencode.ToolPart); ok {
+			m.update(tool)
+		}
+	case app.SessionLoadedMsg, app.SessionClearedMsg:
+		m.reset()
+	case tea.KeyPressMsg:
+		if !m.Focused() {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			m.Hide()
+		case "up", "k":
+			m.viewport.LineUp(1)
+		case "down", "j":
+			m.viewport.LineDown(1)
+		case "pgup":
+			m.viewport.ViewUp()
+		case "pgdown", "space":
+			m.viewport.ViewDown()
+		case "home", "g":
+			m.viewport.GotoTop()
+		case "end", "G":
+			m.viewport.GotoBottom()
+		case "a", "ctrl+t":
+			return m.attach()
+		}
+	}
+	return m, nil
+}
+
+// update follows the command's part, starting over when a new command runs
+func (m *Model) update(part opencode.ToolPart) {
+	if part.ID != m.partID {
+		m.reset()
+		m.partID = part.ID
+		m.visible = true
+	}
+	if input, ok := part.State.Input.(map[string]any); ok {
+		m.command, _ = input["command"].(string)
+	}
+	m.status = part.State.Status
+	m.output = part.State.Output
+	if metadata, ok := part.State.Metadata.(map[string]any); ok {
+		if output, ok := metadata["output"].(string); ok {
+			m.output = output
+		}
+		if exitCode, ok := metadata["exitCode"].(float64); ok {
+			m.exitCode = int(exitCode)
+		}
+	}
+	m.refresh()
+}
+
+// reset forgets the command, keeping the panel's width
+func (m *Model) reset() {
+	width := m.width
+	*m = New(m.app)
+	m.width = width
+}
+
+// refresh lays out the output for the panel's width, following the end of
+// the output unless scrolled back
+func (m *Model) refresh() {
+	following := m.viewport.AtBottom()
+	content := Clean(m.output, max(m.width-4, 1))
+	m.viewport.SetWidth(max(m.width-4, 1))
+	m.viewport.SetHeight(max(min(lipgloss.Height(content), height), 1))
+	m.viewport.SetContent(content)
+	if following {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m Model) running() bool {
+	return m.status == opencode.ToolPartStateStatusRunning || m.status == opencode.ToolPartStateStatusPending
+}
+
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement()).Render
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement()).Render
+
+	state := muted(" running…")
+	switch {
+	case m.running():
+	case m.status == opencode.ToolPartStateStatusError:
+		state = styles.NewStyle().Foreground(t.Error()).Background(t.BackgroundElement()).Render(" ✗ error")
+	case m.exitCode != 0:
+		state = styles.NewStyle().Foreground(t.Error()).Background(t.BackgroundElement()).
+			Render(fmt.Sprintf(" ✗ exit %d", m.exitCode))
+	default:
+		state = styles.NewStyle().Foreground(t.Success()).Background(t.BackgroundElement()).Render(" ✓")
+	}
+	header := base("$ "+ansi.Truncate(m.command, max(m.width-40, 10), "…")) + state
+
+	var hints string
+	switch {
+	case m.Focused() && m.running():
+		hints = base("↑↓") + muted(" scroll   ") + base("esc") + muted(" close")
+	case m.Focused():
+		hints = base("↑↓") + muted(" scroll   ") + base("a") + muted(" attach to prompt   ") +
+			base("esc") + muted(" close")
+	case !m.running() && len(m.app.Commands[commands.ShellOutputCommand].Keybindings) > 0:
+		hints = base(m.app.Keybind(commands.ShellOutputCommand)) + muted(" attach to prompt")
+	}
+	if hints != "" {
+		header += muted("   ") + hints
+	}
+	header = styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Width(m.width - 2).
+		PaddingLeft(1).
+		Render(ansi.Truncate(header, m.width-3, "…"))
+
+	body := styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Width(m.width - 2).
+		PaddingLeft(1).
+		Render(m.viewport.View())
+
+	borderForeground := t.Border()
+	if m.Focused() {
+		borderForeground = t.Primary()
+	}
+	return styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Width(m.width).
+		PaddingTop(1).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderForeground(borderForeground).
+		BorderBackground(t.Background()).
+		BorderLeft(true).
+		BorderRight(true).
+		Render(strings.Join([]string{header, body}, "\n"))
+}
+
+func (m Model) Visible() bool {
+	return m.visible
+}
+
+func (m Model) Focused() bool {
+	return m.visible && m.focused
+}
+
+// SetWidth sets the width of the panel, matching the prompt editor
+func (m *Model) SetWidth(width int) {
+	if width == m.width {
+		return
+	}
+	m.width = width
+	m.refresh()
+}
+
+// Toggle shows and focuses the panel, or hides it when it already has focus
+func (m *Model) Toggle() (Model, tea.Cmd) {
+	if m.Focused() {
+		m.Hide()
+		return *m, nil
+	}
+	if m.partID == "" {
+		return *m, toast.NewInfoToast("No shell command run yet")
+	}
+	m.visible = true
+	m.focused = true
+	return *m, nil
+}
+
+// Hide closes the panel, the output stays until the next command
+func (m *Model) Hide() {
+	m.visible = false
+	m.focused = false
+}
+
+func (m Model) attach() (Model, tea.Cmd) {
+	if m.running() {
+		return m, nil
+	}
+	text := "$ " + m.command + "\n" + ansi.Strip(Clean(m.output, 0))
+	m.Hide()
+	return m, util.CmdHandler(AttachMsg{Text: text})
+}
+
+// Clean keeps the text and colors of command output, dropping other escape
+// sequences and what carriage returns wrote over, and wraps it to the width
+// unless it is 0
+func Clean(output string, width int) string {
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for i, line := range lines {
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			line = line[j+1:]
+		}
+		line = keepColors(line)
+		if width > 0 {
+			line = ansi.Hardwrap(line, width, true)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keepColors drops the escape sequences of the line other than colors,
+// expanding tabs and resetting the colors at its end
+func keepColors(line string) string {
+	var b strings.Builder
+	var state byte
+	colored := false
+	for rest := line; len(rest) > 0; {
+		seq, width, n, newState := ansi.DecodeSequence(rest, state, nil)
+		state = newState
+		rest = rest[n:]
+		switch {
+		case width > 0:
+			b.WriteString(seq)
+		case seq == "\t":
+			b.WriteString("    ")
+		case strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m"):
+			b.WriteString(seq)
+			colored = true
+		}
+	}
+	if colored {
+		b.WriteString(ansi.ResetStyle)
+	}
+	return b.String()
+}
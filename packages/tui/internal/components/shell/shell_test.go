@@ -0,0 +1,23 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestClean(t *testing.T) {
+	output := "\x1b[32mok\x1b[0m\tpkg\r\n" +
+		"progress 10%\rprogress 100%\n" +
+		"\x1b]8;;https://example.com\x07link\x1b]8;;\x07 \x1b[2Kdone\n"
+
+	got := Clean(output, 0)
+	expected := "\x1b[32mok\x1b[0m    pkg" + ansi.ResetStyle + "\nprogress 100%\nlink done"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if wrapped := Clean("abcdef", 4); wrapped != "abcd\nef" {
+		t.Errorf("expected the output wrapped at 4 cells, got %q", wrapped)
+	}
+}
@@ -1,18 +1,26 @@
 package status
 
 import (
+	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/charmbracelet/lipgloss/v2/compat"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/fsnotify/fsnotify"
+	opencode "github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
 	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/git"
+	"github.com/sst/opencode/internal/layout"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
@@ -20,8 +28,48 @@ import (
 
 type GitBranchUpdatedMsg struct {
 	Branch string
+	// Dirty is set when tracked files have uncommitted changes
+	Dirty bool
+	// Ahead and Behind count the commits relative to the upstream branch
+	Ahead  int
+	Behind int
 }
 
+// gitRefreshMsg recomputes the git status after files changed
+type gitRefreshMsg struct{}
+
+// gitRefreshedMsg carries a refreshed git status, without restarting the
+// watcher like GitBranchUpdatedMsg does
+type gitRefreshedMsg GitBranchUpdatedMsg
+
+// gitRefreshDelay debounces git status refreshes after file changes
+const gitRefreshDelay = 500 * time.Millisecond
+
+// ConnectionState is the state of the event stream from the server
+type ConnectionState int
+
+const (
+	ConnectionConnecting ConnectionState = iota
+	ConnectionConnected
+	ConnectionReconnecting
+	ConnectionDisconnected
+)
+
+// ServerConnectionMsg is sent when the event stream from the server connects,
+// drops or stays down past the reconnection attempts
+type ServerConnectionMsg struct {
+	State ConnectionState
+	// Attempt counts the reconnection attempts while reconnecting
+	Attempt int
+	// Reconnected is set when the stream is back after dropping, the events
+	// in between were missed
+	Reconnected bool
+}
+
+type clockTickMsg struct{}
+
+const clockTickInterval = 15 * time.Second
+
 type StatusComponent interface {
 	tea.Model
 	tea.ViewModel
@@ -32,14 +80,39 @@ type statusComponent struct {
 	app        *app.App
 	width      int
 	cwd        string
-	branch     string
+	git        GitBranchUpdatedMsg
 	watcher    *fsnotify.Watcher
 	done       chan struct{}
 	lastUpdate time.Time
+	// state of the event stream from the server
+	connection ServerConnectionMsg
+	// set while a git status refresh is scheduled
+	gitRefreshPending bool
 }
 
 func (m *statusComponent) Init() tea.Cmd {
-	return m.startGitWatcher()
+	left, right := m.statusLine()
+	cmds := []tea.Cmd{m.startGitWatcher()}
+	if slices.Contains(left, "clock") || slices.Contains(right, "clock") {
+		cmds = append(cmds, clockTick())
+	}
+	var unknown []string
+	for _, name := range append(left, right...) {
+		if !slices.Contains(segmentNames, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slog.Warn("Unknown status line segments", "segments", unknown)
+		cmds = append(cmds, toast.NewWarningToast("Unknown status_line segments: "+strings.Join(unknown, ", ")))
+	}
+	return tea.Batch(cmds...)
+}
+
+func clockTick() tea.Cmd {
+	return tea.Tick(clockTickInterval, func(time.Time) tea.Msg {
+		return clockTickMsg{}
+	})
 }
 
 func (m *statusComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -48,11 +121,29 @@ func (m *statusComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		return m, nil
 	case GitBranchUpdatedMsg:
-		if m.branch != msg.Branch {
-			m.branch = msg.Branch
-		}
+		m.git = msg
 		// Continue watching for changes (persistent watcher)
 		return m, m.watchForGitChanges()
+	case opencode.EventListResponseEventFileWatcherUpdated:
+		if m.gitRefreshPending || m.git.Branch == "" {
+			return m, nil
+		}
+		m.gitRefreshPending = true
+		return m, tea.Tick(gitRefreshDelay, func(time.Time) tea.Msg {
+			return gitRefreshMsg{}
+		})
+	case gitRefreshMsg:
+		m.gitRefreshPending = false
+		root := m.app.Info.Path.Root
+		return m, func() tea.Msg {
+			return gitRefreshedMsg(getGitStatus(root))
+		}
+	case gitRefreshedMsg:
+		m.git = GitBranchUpdatedMsg(msg)
+	case ServerConnectionMsg:
+		m.connection = msg
+	case clockTickMsg:
+		return m, clockTick()
 	}
 	return m, nil
 }
@@ -75,31 +166,203 @@ func (m statusComponent) logo() string {
 		Render(kuu + zuki + version)
 }
 
-func (m statusComponent) View() string {
+const gaugeWidth = 10
+
+// contextGauge renders a bar showing how full the model's context window is
+func (m statusComponent) contextGauge() string {
+	if m.app.Session.ID == "" {
+		return ""
+	}
+	percent := m.app.ContextPercent()
+	if percent < 0 {
+		return ""
+	}
+
 	t := theme.CurrentTheme()
-	logo := m.logo()
+	color := t.TextMuted()
+	switch {
+	case percent >= 95:
+		color = t.Error()
+	case percent >= m.app.CompactThreshold():
+		color = t.Warning()
+	}
+
+	filled := min(int(percent/100*gaugeWidth+0.5), gaugeWidth)
+	bar := styles.NewStyle().Foreground(color).Background(t.BackgroundPanel()).
+		Render(strings.Repeat("█", filled))
+	bar += styles.NewStyle().Foreground(t.BackgroundElement()).Background(t.BackgroundPanel()).
+		Render(strings.Repeat("█", gaugeWidth-filled))
+	label := styles.NewStyle().Foreground(color).Background(t.BackgroundPanel()).
+		Render(fmt.Sprintf(" %d%%", int(percent)))
+
+	return styles.NewStyle().
+		Background(t.BackgroundPanel()).
+		Padding(0, 1).
+		Render(bar + label)
+}
+
+// scrollLock renders an indicator while the messages don't follow new output
+func (m statusComponent) scrollLock() string {
+	if m.app.Session.ID == "" || !m.app.State.ScrollLock {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
+		Foreground(t.Warning()).
+		Background(t.BackgroundPanel()).
+		Padding(0, 1).
+		Render("scroll locked")
+}
 
-	// Add branch suffix if we have one
-	branchSuffix := ""
-	if m.branch != "" {
-		branchSuffix = ":" + m.branch
+// follow renders a marker while edited files open in the file viewer
+func (m statusComponent) follow() string {
+	if m.app.Session.ID == "" || !m.app.State.FollowEdits {
+		return ""
 	}
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
+		Foreground(t.Accent()).
+		Background(t.BackgroundPanel()).
+		Padding(0, 1).
+		Render("following")
+}
 
-	cwdDisplay := m.cwd
-	if m.branch != "" {
-		cwdDisplay += styles.NewStyle().
-			Faint(true).
-			Background(t.BackgroundPanel()).
-			Foreground(t.TextMuted()).
-			Render(branchSuffix)
+// budget renders what is left of the budget closest to running out
+func (m statusComponent) budget() string {
+	status, ok := m.app.BudgetStatus(time.Now())
+	if !ok {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	color := t.TextMuted()
+	text := fmt.Sprintf("%s $%.2f left", status.Cap, status.Remaining)
+	switch status.Level {
+	case app.BudgetWarning:
+		color = t.Warning()
+	case app.BudgetExceeded:
+		color = t.Error()
+		text = status.Cap + " budget spent"
 	}
+	return styles.NewStyle().
+		Foreground(color).
+		Background(t.BackgroundPanel()).
+		Padding(0, 1).
+		Render(text)
+}
 
-	cwd := styles.NewStyle().
+// usage renders the session's token usage and cost, as set by the
+// status_usage config option
+func (m statusComponent) usage() string {
+	format := m.app.Config.Tui.StatusUsage
+	if m.app.Session.ID == "" || !format.IsKnown() {
+		return ""
+	}
+	usage := m.app.SessionUsage()
+	tokens := util.FormatTokens(usage.TotalTokens()) + " tokens"
+	cost := fmt.Sprintf("$%.2f", usage.Cost)
+
+	var text string
+	switch format {
+	case opencode.ConfigTuiStatusUsageTokens:
+		text = tokens
+	case opencode.ConfigTuiStatusUsageCost:
+		text = cost
+	default:
+		text = tokens + " · " + cost
+	}
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
 		Foreground(t.TextMuted()).
 		Background(t.BackgroundPanel()).
 		Padding(0, 1).
-		Render(cwdDisplay)
+		Render(text)
+}
+
+// fallback renders a badge while the latest prompt runs on a fallback model
+func (m statusComponent) fallback() string {
+	if m.app.Session.ID == "" || m.app.Fallback == nil {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
+		Foreground(t.Warning()).
+		Background(t.BackgroundPanel()).
+		Padding(0, 1).
+		Render("fallback: " + m.app.Fallback.To)
+}
+
+// defaultStatusLine lays out the status bar when status_line isn't set
+const defaultStatusLine = "logo cwd branch keymap tool | health fallback scroll_lock follow budget context usage agent"
+
+// keymapSegment renders the keymap keys run in, while a pane other than the
+// editor has focus
+func (m statusComponent) keymapSegment() string {
+	if m.app.Keymap == commands.KeymapGlobal || m.app.Keymap == commands.KeymapEditor {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
+		Foreground(t.BackgroundPanel()).
+		Background(t.Accent()).
+		Padding(0, 1).
+		Render(string(m.app.Keymap))
+}
+
+// cwdSegment renders the working directory, without the space after it when
+// the branch follows as cwd:branch
+func (m statusComponent) cwdSegment(joined bool) string {
+	t := theme.CurrentTheme()
+	style := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		Padding(0, 1)
+	if joined {
+		style = style.PaddingRight(0)
+	}
+	return style.Render(m.cwd)
+}
 
+// branchSegment renders the current git branch, marked with * when dirty and
+// with the commits ahead of and behind its upstream. A sandbox branch stands
+// out, edits on it are yet to be merged back. Joined to the working directory
+// it renders as :branch.
+func (m statusComponent) branchSegment(joined bool) string {
+	if m.git.Branch == "" {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	branch := m.git.Branch
+	sandbox, ok := m.app.Sandbox()
+	inSandbox := ok && sandbox.Branch == branch
+	if inSandbox {
+		branch = "sandbox " + strings.TrimPrefix(branch, git.SandboxPrefix)
+	}
+	if m.git.Dirty {
+		branch += "*"
+	}
+	if m.git.Ahead > 0 {
+		branch += fmt.Sprintf("↑%d", m.git.Ahead)
+	}
+	if m.git.Behind > 0 {
+		branch += fmt.Sprintf("↓%d", m.git.Behind)
+	}
+	style := styles.NewStyle().
+		Faint(true).
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted()).
+		PaddingRight(1)
+	if inSandbox {
+		style = style.Faint(false).Foreground(t.Warning())
+	}
+	if joined {
+		branch = ":" + branch
+	}
+	return style.Render(branch)
+}
+
+// agent renders the current agent along with the key that switches it
+func (m statusComponent) agent() string {
+	t := theme.CurrentTheme()
 	var modeBackground compat.AdaptiveColor
 	var modeForeground compat.AdaptiveColor
 	switch m.app.AgentIndex {
@@ -148,29 +411,192 @@ func (m statusComponent) View() string {
 		BorderBackground(t.BackgroundPanel()).
 		Render(mode)
 
-	mode = styles.NewStyle().
+	return styles.NewStyle().
 		Faint(true).
 		Background(t.BackgroundPanel()).
 		Foreground(t.TextMuted()).
 		Render(key+" ") +
 		mode
+}
+
+// model renders the selected provider and model
+func (m statusComponent) model() string {
+	if m.app.Provider == nil || m.app.Model == nil {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		Padding(0, 1).
+		Render(m.app.Provider.Name + " " + m.app.Model.Name)
+}
+
+// clock renders the time of day
+func (m statusComponent) clock() string {
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundPanel()).
+		Padding(0, 1).
+		Render(time.Now().Format("15:04"))
+}
+
+// health renders whether the TUI still receives events from the server, and
+// whether it only observes
+func (m statusComponent) health() string {
+	t := theme.CurrentTheme()
+	style := styles.NewStyle().Background(t.BackgroundPanel()).Padding(0, 1)
+	if m.app.ReadOnly {
+		return style.Foreground(t.Info()).Render("read-only") + m.connectionHealth()
+	}
+	return m.connectionHealth()
+}
+
+// connectionHealth renders the state of the event stream
+func (m statusComponent) connectionHealth() string {
+	t := theme.CurrentTheme()
+	style := styles.NewStyle().Background(t.BackgroundPanel()).Padding(0, 1)
+	switch m.connection.State {
+	case ConnectionConnecting:
+		return style.Foreground(t.TextMuted()).Render("○ connecting")
+	case ConnectionReconnecting:
+		return style.Foreground(t.Warning()).Render(fmt.Sprintf("● reconnecting (%d)", m.connection.Attempt))
+	case ConnectionDisconnected:
+		return style.Foreground(t.Error()).Render(fmt.Sprintf("● offline, retrying (%d)", m.connection.Attempt))
+	}
+	return style.Foreground(t.Success()).Render("●")
+}
+
+// toolInputKeys are the tool inputs that best describe a running call, in
+// order of preference
+var toolInputKeys = []string{"command", "filePath", "path", "pattern", "url", "description"}
 
-	space := max(
-		0,
-		m.width-lipgloss.Width(logo)-lipgloss.Width(cwd)-lipgloss.Width(mode),
-	)
+// maxToolActivityWidth keeps the tool segment from crowding out the others
+const maxToolActivityWidth = 40
+
+// tool renders the tool call the agent is running, e.g. "running bash: npm test"
+func (m statusComponent) tool() string {
+	tool, ok := m.app.RunningTool()
+	if !ok {
+		return ""
+	}
+	activity := "running " + tool.Tool
+	if input, ok := tool.State.Input.(map[string]any); ok {
+		for _, key := range toolInputKeys {
+			if value, ok := input[key].(string); ok && value != "" {
+				if key == "filePath" || key == "path" {
+					value = util.Relative(value)
+				}
+				activity += ": " + strings.Join(strings.Fields(value), " ")
+				break
+			}
+		}
+	}
+	t := theme.CurrentTheme()
+	return styles.NewStyle().
+		Background(t.BackgroundPanel()).
+		Foreground(t.Accent()).
+		PaddingRight(1).
+		Render(ansi.Truncate(activity, maxToolActivityWidth, "…"))
+}
+
+// segmentNames are the segments the status line can be composed of
+var segmentNames = []string{
+	"logo", "cwd", "branch", "keymap", "agent", "model", "usage", "context",
+	"budget", "fallback", "scroll_lock", "follow", "clock", "health", "tool",
+}
+
+// segment renders the named segment, joined tells cwd and branch they're
+// next to each other
+func (m statusComponent) segment(name string, joined bool) string {
+	switch name {
+	case "logo":
+		return m.logo()
+	case "cwd":
+		return m.cwdSegment(joined)
+	case "branch":
+		return m.branchSegment(joined)
+	case "keymap":
+		return m.keymapSegment()
+	case "agent":
+		return m.agent()
+	case "model":
+		return m.model()
+	case "usage":
+		return m.usage()
+	case "context":
+		return m.contextGauge()
+	case "budget":
+		return m.budget()
+	case "fallback":
+		return m.fallback()
+	case "scroll_lock":
+		return m.scrollLock()
+	case "follow":
+		return m.follow()
+	case "clock":
+		return m.clock()
+	case "health":
+		return m.health()
+	case "tool":
+		return m.tool()
+	}
+	return ""
+}
+
+// SegmentZone is the clickable zone of the named segment
+func SegmentZone(name string) string {
+	return "status:" + name
+}
+
+// ParseSegmentZone returns the segment name of a segment zone
+func ParseSegmentZone(zone string) (string, bool) {
+	return strings.CutPrefix(zone, "status:")
+}
+
+// statusLine returns the segment names of the left and right sides of the
+// status bar
+func (m statusComponent) statusLine() ([]string, []string) {
+	format := m.app.Config.Tui.StatusLine
+	if strings.TrimSpace(format) == "" {
+		format = defaultStatusLine
+	}
+	left, right, _ := strings.Cut(format, "|")
+	return strings.Fields(left), strings.Fields(right)
+}
+
+func (m statusComponent) View() string {
+	t := theme.CurrentTheme()
+
+	render := func(names []string) string {
+		var b strings.Builder
+		for i, name := range names {
+			// cwd:branch as one, when there is a branch
+			joined := m.git.Branch != "" &&
+				(name == "cwd" && i+1 < len(names) && names[i+1] == "branch" ||
+					name == "branch" && i > 0 && names[i-1] == "cwd")
+			if segment := m.segment(name, joined); segment != "" {
+				b.WriteString(layout.Mark(SegmentZone(name), segment))
+			}
+		}
+		return b.String()
+	}
+	leftNames, rightNames := m.statusLine()
+	left := render(leftNames)
+	right := render(rightNames)
+
+	space := max(0, m.width-lipgloss.Width(left)-lipgloss.Width(right))
 	spacer := styles.NewStyle().Background(t.BackgroundPanel()).Width(space).Render("")
 
-	status := logo + cwd + spacer + mode
+	status := left + spacer + right
 
 	blank := styles.NewStyle().Background(t.Background()).Width(m.width).Render("")
 	return blank + "\n" + status
 }
 
 func (m *statusComponent) startGitWatcher() tea.Cmd {
-	cmd := util.CmdHandler(
-		GitBranchUpdatedMsg{Branch: getCurrentGitBranch(m.app.Info.Path.Root)},
-	)
+	cmd := util.CmdHandler(getGitStatus(m.app.Info.Path.Root))
 	if err := m.initWatcher(); err != nil {
 		return cmd
 	}
@@ -202,6 +628,12 @@ func (m *statusComponent) initWatcher() error {
 		}
 	}
 
+	// The index changes when files are staged or committed, FETCH_HEAD when
+	// the upstream is fetched
+	for _, name := range []string{"index", "FETCH_HEAD"} {
+		watcher.Add(filepath.Join(gitDir, name)) // Ignore error, they may not exist yet
+	}
+
 	m.watcher = watcher
 	m.done = make(chan struct{})
 	return nil
@@ -216,9 +648,8 @@ func (m *statusComponent) watchForGitChanges() tea.Cmd {
 		for {
 			select {
 			case event, ok := <-m.watcher.Events:
-				branch := getCurrentGitBranch(m.app.Info.Path.Root)
 				if !ok {
-					return GitBranchUpdatedMsg{Branch: branch}
+					return getGitStatus(m.app.Info.Path.Root)
 				}
 				if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
 					// Debounce updates to prevent excessive refreshes
@@ -230,7 +661,7 @@ func (m *statusComponent) watchForGitChanges() tea.Cmd {
 					if strings.HasSuffix(event.Name, "HEAD") {
 						m.updateWatchedFiles()
 					}
-					return GitBranchUpdatedMsg{Branch: branch}
+					return getGitStatus(m.app.Info.Path.Root)
 				}
 			case <-m.watcher.Errors:
 				// Continue watching even on errors
@@ -255,14 +686,39 @@ func (m *statusComponent) updateWatchedFiles() {
 	}
 }
 
-func getCurrentGitBranch(cwd string) string {
-	cmd := exec.Command("git", "branch", "--show-current")
+// getGitStatus reads the branch, dirty state and upstream divergence in one
+// git call, skipping untracked files to stay cheap in large repositories.
+// It never takes index.lock, which would fail the agent's own git commands
+// and rewrite the watched index.
+func getGitStatus(cwd string) GitBranchUpdatedMsg {
+	cmd := exec.Command("git", "--no-optional-locks", "status", "--porcelain=v2", "--branch", "--untracked-files=no")
 	cmd.Dir = cwd
 	output, err := cmd.Output()
 	if err != nil {
-		return ""
+		return GitBranchUpdatedMsg{}
+	}
+	return parseGitStatus(string(output))
+}
+
+func parseGitStatus(output string) GitBranchUpdatedMsg {
+	var status GitBranchUpdatedMsg
+	for line := range strings.SplitSeq(output, "\n") {
+		if line == "" {
+			continue
+		}
+		header, ok := strings.CutPrefix(line, "# ")
+		if !ok {
+			status.Dirty = true
+			continue
+		}
+		if head, ok := strings.CutPrefix(header, "branch.head "); ok && head != "(detached)" {
+			status.Branch = head
+		}
+		if ab, ok := strings.CutPrefix(header, "branch.ab "); ok {
+			fmt.Sscanf(ab, "+%d -%d", &status.Ahead, &status.Behind)
+		}
 	}
-	return strings.TrimSpace(string(output))
+	return status
 }
 
 func getGitRefFile(cwd string) string {
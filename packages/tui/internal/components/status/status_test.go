@@ -0,0 +1,34 @@
+package status
+
+import "testing"
+
+func TestParseGitStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   GitBranchUpdatedMsg
+	}{
+		{
+			"clean without upstream",
+			"# branch.oid 1a2b3c\n# branch.head main\n",
+			GitBranchUpdatedMsg{Branch: "main"},
+		},
+		{
+			"dirty and diverged",
+			"# branch.oid 1a2b3c\n# branch.head feature\n# branch.upstream origin/feature\n# branch.ab +2 -1\n1 .M N... 100644 100644 100644 1a2b3c 1a2b3c status.go\n",
+			GitBranchUpdatedMsg{Branch: "feature", Dirty: true, Ahead: 2, Behind: 1},
+		},
+		{
+			"detached",
+			"# branch.oid 1a2b3c\n# branch.head (detached)\n",
+			GitBranchUpdatedMsg{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseGitStatus(tt.output); got != tt.want {
+				t.Errorf("parseGitStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
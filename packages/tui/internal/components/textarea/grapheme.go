@@ -0,0 +1,130 @@
+package textarea
+
+import (
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+// cell describes an item of a row as the terminal draws it. Runes are
+// grouped into grapheme clusters, like a letter and its combining marks or
+// an emoji joined with a skin tone: the first rune of a cluster takes the
+// width of the whole cluster and the others none, so that the cursor and
+// the wrapping never split one.
+type cell struct {
+	width int
+	start bool
+}
+
+// cells describes each item of the row. Attachments are a cluster of their
+// own, as wide as their display text.
+func cells(row []any) []cell {
+	result := make([]cell, len(row))
+	for i := 0; i < len(row); {
+		if att, ok := row[i].(*attachment.Attachment); ok {
+			result[i] = cell{width: uniseg.StringWidth(att.Display), start: true}
+			i++
+			continue
+		}
+		// the run of runes up to the next attachment
+		end := i
+		var runes []rune
+		for end < len(row) {
+			r, ok := row[end].(rune)
+			if !ok {
+				break
+			}
+			runes = append(runes, r)
+			end++
+		}
+		text := string(runes)
+		state := -1
+		for text != "" {
+			var cluster string
+			var boundaries int
+			cluster, text, boundaries, state = uniseg.StepString(text, state)
+			result[i] = cell{width: boundaries >> uniseg.ShiftWidth, start: true}
+			i += utf8.RuneCountInString(cluster)
+		}
+		i = end
+	}
+	return result
+}
+
+// width returns the cells the items take in the terminal
+func width(items []any) int {
+	total := 0
+	for _, c := range cells(items) {
+		total += c.width
+	}
+	return total
+}
+
+// nextCluster returns the column after the grapheme cluster at col
+func nextCluster(row []any, col int) int {
+	if col >= len(row) {
+		return len(row)
+	}
+	c := cells(row)
+	col++
+	for col < len(row) && !c[col].start {
+		col++
+	}
+	return col
+}
+
+// prevCluster returns the column of the grapheme cluster before col
+func prevCluster(row []any, col int) int {
+	if col <= 0 {
+		return 0
+	}
+	c := cells(row)
+	col = min(col, len(row)) - 1
+	for col > 0 && !c[col].start {
+		col--
+	}
+	return col
+}
+
+// clusterStart returns col, or the start of the grapheme cluster it's in
+func clusterStart(row []any, col int) int {
+	if col <= 0 || col >= len(row) {
+		return col
+	}
+	// two ASCII characters are always apart, which spares segmenting the
+	// row on every key typed
+	if r, ok := row[col].(rune); ok && r < utf8.RuneSelf {
+		if prev, ok := row[col-1].(rune); ok && prev < utf8.RuneSelf && prev != '\r' {
+			return col
+		}
+	}
+	c := cells(row)
+	for col > 0 && !c[col].start {
+		col--
+	}
+	return col
+}
+
+// columnAtOffset returns the column of the items nearest to the cell
+// offset, at the start of a grapheme cluster
+func columnAtOffset(items []any, charOffset int) int {
+	c := cells(items)
+	offset := 0
+	for i := 0; i < len(items); {
+		next := i + 1
+		for next < len(items) && !c[next].start {
+			next++
+		}
+		if offset+c[i].width > charOffset {
+			// stick with the cluster or move past it, whichever is closer
+			if charOffset-offset > offset+c[i].width-charOffset {
+				return next
+			}
+			return i
+		}
+		offset += c[i].width
+		i = next
+	}
+	return len(items)
+}
@@ -0,0 +1,161 @@
+package textarea
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+func runes(s string) []any {
+	return runesToInterfaces([]rune(s))
+}
+
+func TestCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		row   []any
+		want  []int
+		start []bool
+	}{
+		{"combining mark", runes("e\u0301x"), []int{1, 0, 1}, []bool{true, false, true}},
+		{"wide", runes("日本"), []int{2, 2}, []bool{true, true}},
+		{"emoji with skin tone", runes("\U0001F44D\U0001F3FD!"), []int{2, 0, 1}, []bool{true, false, true}},
+		{"joined emoji", runes("\U0001F469\u200d\U0001F4BB"), []int{2, 0, 0}, []bool{true, false, false}},
+		{"emoji presentation", runes("\u270f\ufe0f"), []int{2, 0}, []bool{true, false}},
+		{"attachment", append(runes("\u00e9"), &attachment.Attachment{Display: "@日本.go"}), []int{1, 8}, []bool{true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var widths []int
+			var starts []bool
+			for _, c := range cells(tt.row) {
+				widths = append(widths, c.width)
+				starts = append(starts, c.start)
+			}
+			if !slices.Equal(widths, tt.want) || !slices.Equal(starts, tt.start) {
+				t.Errorf("cells() = %v %v, want %v %v", widths, starts, tt.want, tt.start)
+			}
+		})
+	}
+}
+
+func TestWrapWideCharacters(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"CJK without spaces", "日本語のテキストです", 8, []string{"日本語の", "テキスト", "です"}},
+		{"CJK after a word", "see 日本語です", 8, []string{"see 日本", "語です"}},
+		{"odd width", "日本語", 5, []string{"日本", "語"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"cluster at the edge", "abc\U0001F44D\U0001F3FD", 4, []string{"abc", "\U0001F44D\U0001F3FD"}},
+		{"combining marks", "cafe\u0301 cafe\u0301", 5, []string{"cafe\u0301 ", "cafe\u0301"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range wrapInterfaces(runes(tt.text), tt.width) {
+				if w := width(line); w > tt.width {
+					t.Errorf("line %q is %d cells wide", interfacesToString(line), w)
+				}
+				got = append(got, interfacesToString(line))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("wrap = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorMovesByCluster(t *testing.T) {
+	m := New()
+	m.Focus()
+	// c a f e, a combining acute, a thumbs up with a skin tone and !
+	m.InsertString("cafe\u0301\U0001F44D\U0001F3FD!")
+
+	for _, want := range []int{7, 5, 3} {
+		m.characterLeft(false)
+		if m.col != want {
+			t.Fatalf("col = %d moving left, want %d", m.col, want)
+		}
+	}
+	m.characterRight()
+	if m.col != 5 {
+		t.Fatalf("col = %d moving right, want 5", m.col)
+	}
+	m.SetCursorColumn(4)
+	if m.col != 3 {
+		t.Errorf("col = %d set inside a cluster, want its start 3", m.col)
+	}
+
+	m.SetCursorColumn(7)
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	if got := m.Value(); got != "cafe\u0301!" {
+		t.Errorf("backspace left %q", got)
+	}
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyDelete})
+	if got := m.Value(); got != "caf!" {
+		t.Errorf("delete left %q", got)
+	}
+}
+
+func TestCursorWideCharacters(t *testing.T) {
+	m := New()
+	m.Focus()
+	m.Prompt = ""
+	m.ShowLineNumbers = false
+	m.SetWidth(12)
+	m.InsertString("日本語のテキスト")
+
+	if info := m.LineInfo(); info.RowOffset != 1 || info.ColumnOffset != 2 || info.CharOffset != 4 {
+		t.Errorf("LineInfo() = %+v, want the 3rd column, 5th cell of the 2nd line", info)
+	}
+	m.CursorUp()
+	if info := m.LineInfo(); m.col != 2 || info.CharOffset != 4 {
+		t.Errorf("col = %d, offset %d after moving up, want 2, 4", m.col, info.CharOffset)
+	}
+
+	// every line of the view is as wide as the textarea
+	for _, line := range strings.Split(ansi.Strip(m.View()), "\n") {
+		if w := ansi.StringWidth(line); w != 12 {
+			t.Errorf("line %q is %d cells wide", line, w)
+		}
+	}
+}
+
+func TestAttachmentOffsets(t *testing.T) {
+	m := New()
+	m.InsertString("日本 ")
+	m.InsertAttachment(&attachment.Attachment{ID: "1", Display: "@main.go"})
+	m.InsertString(" \U0001F44D\U0001F3FD")
+	m.Newline()
+	m.InsertString("\u00e9 ")
+	m.InsertAttachment(&attachment.Attachment{ID: "2", Display: "[テキスト]"})
+
+	value := m.Value()
+	attachments := m.GetAttachments()
+	if len(attachments) != 2 {
+		t.Fatalf("got %d attachments", len(attachments))
+	}
+	for _, att := range attachments {
+		if got := value[att.StartIndex:att.EndIndex]; got != att.Display {
+			t.Errorf("value[%d:%d] = %q, want %q", att.StartIndex, att.EndIndex, got, att.Display)
+		}
+	}
+
+	m.SetCursorOffset(attachments[1].StartIndex)
+	if m.row != 1 || m.col != 2 {
+		t.Errorf("cursor at %d:%d, want 1:2", m.row, m.col)
+	}
+	m.SetCursorOffset(attachments[0].EndIndex)
+	if m.row != 0 || m.col != 4 {
+		t.Errorf("cursor at %d:%d, want 0:4", m.row, m.col)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"slices"
 
@@ -16,9 +17,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
-	rw "github.com/mattn/go-runewidth"
 	"github.com/rivo/uniseg"
 	"github.com/sst/opencode/internal/attachment"
+	"github.com/sst/opencode/internal/styles"
 )
 
 const (
@@ -79,6 +80,13 @@ func interfacesToString(items []any) string {
 	return s.String()
 }
 
+// AttachmentAtCursor returns the attachment the cursor is on or just after,
+// nil when there is none
+func (m Model) AttachmentAtCursor() *attachment.Attachment {
+	att, _, _ := m.isAttachmentAtCursor()
+	return att
+}
+
 // isAttachmentAtCursor checks if the cursor is positioned on or immediately after an attachment.
 // This allows for proper highlighting even when the cursor is technically at the position
 // after the attachment object in the underlying slice.
@@ -599,7 +607,7 @@ func DefaultStyles(isDark bool) Styles {
 	s.Cursor = CursorStyle{
 		Color: lipgloss.Color("7"),
 		Shape: tea.CursorBlock,
-		Blink: true,
+		Blink: !styles.ReduceMotion,
 	}
 	return s
 }
@@ -731,6 +739,7 @@ func (m *Model) CurrentRowLength() int {
 }
 
 // GetAttachments returns all attachments in the textarea with accurate position indices.
+// The indices are byte offsets in Value, whatever the runes before.
 func (m Model) GetAttachments() []*attachment.Attachment {
 	var attachments []*attachment.Attachment
 	position := 0 // Track absolute position in the text
@@ -766,7 +775,7 @@ func (m Model) GetAttachments() []*attachment.Attachment {
 				attachments = append(attachments, &att)
 				colPosition += displayLen
 			case rune:
-				colPosition++
+				colPosition += utf8.RuneLen(v)
 			}
 		}
 
@@ -781,6 +790,29 @@ func (m Model) GetAttachments() []*attachment.Attachment {
 	return attachments
 }
 
+// SetCursorOffset moves the cursor to the byte offset in Value, like the
+// StartIndex of an attachment
+func (m *Model) SetCursorOffset(offset int) {
+	for row, items := range m.value {
+		col := 0
+		for ; col < len(items) && offset > 0; col++ {
+			switch v := items[col].(type) {
+			case rune:
+				offset -= utf8.RuneLen(v)
+			case *attachment.Attachment:
+				offset -= len(v.Display)
+			}
+		}
+		if offset <= 0 || row == len(m.value)-1 {
+			m.row = row
+			m.SetCursorColumn(col)
+			return
+		}
+		// the newline
+		offset--
+	}
+}
+
 // InsertRunesFromUserInput inserts runes at the current cursor position.
 func (m *Model) InsertRunesFromUserInput(runes []rune) {
 	// Clean up any special characters in the input provided by the
@@ -895,14 +927,7 @@ func (m Model) Value() string {
 func (m *Model) Length() int {
 	var l int
 	for _, row := range m.value {
-		for _, item := range row {
-			switch val := item.(type) {
-			case rune:
-				l += rw.RuneWidth(val)
-			case *attachment.Attachment:
-				l += uniseg.StringWidth(val.Display)
-			}
-		}
+		l += width(row)
 	}
 	// We add len(m.value) to include the newline characters.
 	return l + len(m.value) - 1
@@ -955,31 +980,7 @@ func (m *Model) mapVisualOffsetToSliceIndex(row int, charOffset int) int {
 	if row < 0 || row >= len(m.value) {
 		return 0
 	}
-
-	offset := 0
-	// Find the slice index that corresponds to the visual offset.
-	for i, item := range m.value[row] {
-		var itemWidth int
-		switch v := item.(type) {
-		case rune:
-			itemWidth = rw.RuneWidth(v)
-		case *attachment.Attachment:
-			itemWidth = uniseg.StringWidth(v.Display)
-		}
-
-		// If the target offset falls within the current item, this is our index.
-		if offset+itemWidth > charOffset {
-			// Decide whether to stick with the previous index or move to the current
-			// one based on which is closer to the target offset.
-			if (charOffset - offset) > ((offset + itemWidth) - charOffset) {
-				return i + 1
-			}
-			return i
-		}
-		offset += itemWidth
-	}
-
-	return len(m.value[row])
+	return columnAtOffset(m.value[row], charOffset)
 }
 
 // CursorDown moves the cursor down by one line.
@@ -996,32 +997,8 @@ func (m *Model) CursorDown() {
 		grid := m.memoizedWrap(m.value[m.row], m.width)
 		targetLineContent := grid[0]
 
-		// Find position within the first wrapped line.
-		offset := 0
-		colInLine := 0
-		for i, item := range targetLineContent {
-			var itemWidth int
-			switch v := item.(type) {
-			case rune:
-				itemWidth = rw.RuneWidth(v)
-			case *attachment.Attachment:
-				itemWidth = uniseg.StringWidth(v.Display)
-			}
-			if offset+itemWidth > charOffset {
-				// Decide whether to stick with the previous index or move to the current
-				// one based on which is closer to the target offset.
-				if (charOffset - offset) > ((offset + itemWidth) - charOffset) {
-					colInLine = i + 1
-				} else {
-					colInLine = i
-				}
-				goto foundNextLine
-			}
-			offset += itemWidth
-		}
-		colInLine = len(targetLineContent)
-	foundNextLine:
-		m.col = colInLine // startCol is 0 for the first wrapped line
+		// startCol is 0 for the first wrapped line
+		m.col = columnAtOffset(targetLineContent, charOffset)
 	} else if li.RowOffset+1 < li.Height {
 		// Move to the next wrapped line within the same model line
 		grid := m.memoizedWrap(m.value[m.row], m.width)
@@ -1032,32 +1009,7 @@ func (m *Model) CursorDown() {
 			startCol += len(grid[i])
 		}
 
-		// Find position within the target wrapped line.
-		offset := 0
-		colInLine := 0
-		for i, item := range targetLineContent {
-			var itemWidth int
-			switch v := item.(type) {
-			case rune:
-				itemWidth = rw.RuneWidth(v)
-			case *attachment.Attachment:
-				itemWidth = uniseg.StringWidth(v.Display)
-			}
-			if offset+itemWidth > charOffset {
-				// Decide whether to stick with the previous index or move to the current
-				// one based on which is closer to the target offset.
-				if (charOffset - offset) > ((offset + itemWidth) - charOffset) {
-					colInLine = i + 1
-				} else {
-					colInLine = i
-				}
-				goto foundSameLine
-			}
-			offset += itemWidth
-		}
-		colInLine = len(targetLineContent)
-	foundSameLine:
-		m.col = startCol + colInLine
+		m.col = startCol + columnAtOffset(targetLineContent, charOffset)
 	}
 	m.SetCursorColumn(m.col)
 }
@@ -1078,32 +1030,7 @@ func (m *Model) CursorUp() {
 		// Find start of last wrapped line.
 		startCol := len(m.value[m.row]) - len(targetLineContent)
 
-		// Find position within the last wrapped line.
-		offset := 0
-		colInLine := 0
-		for i, item := range targetLineContent {
-			var itemWidth int
-			switch v := item.(type) {
-			case rune:
-				itemWidth = rw.RuneWidth(v)
-			case *attachment.Attachment:
-				itemWidth = uniseg.StringWidth(v.Display)
-			}
-			if offset+itemWidth > charOffset {
-				// Decide whether to stick with the previous index or move to the current
-				// one based on which is closer to the target offset.
-				if (charOffset - offset) > ((offset + itemWidth) - charOffset) {
-					colInLine = i + 1
-				} else {
-					colInLine = i
-				}
-				goto foundPrevLine
-			}
-			offset += itemWidth
-		}
-		colInLine = len(targetLineContent)
-	foundPrevLine:
-		m.col = startCol + colInLine
+		m.col = startCol + columnAtOffset(targetLineContent, charOffset)
 	} else if li.RowOffset > 0 {
 		// Move to the previous wrapped line within the same model line.
 		grid := m.memoizedWrap(m.value[m.row], m.width)
@@ -1114,40 +1041,16 @@ func (m *Model) CursorUp() {
 			startCol += len(grid[i])
 		}
 
-		// Find position within the target wrapped line.
-		offset := 0
-		colInLine := 0
-		for i, item := range targetLineContent {
-			var itemWidth int
-			switch v := item.(type) {
-			case rune:
-				itemWidth = rw.RuneWidth(v)
-			case *attachment.Attachment:
-				itemWidth = uniseg.StringWidth(v.Display)
-			}
-			if offset+itemWidth > charOffset {
-				// Decide whether to stick with the previous index or move to the current
-				// one based on which is closer to the target offset.
-				if (charOffset - offset) > ((offset + itemWidth) - charOffset) {
-					colInLine = i + 1
-				} else {
-					colInLine = i
-				}
-				goto foundSameLine
-			}
-			offset += itemWidth
-		}
-		colInLine = len(targetLineContent)
-	foundSameLine:
-		m.col = startCol + colInLine
+		m.col = startCol + columnAtOffset(targetLineContent, charOffset)
 	}
 	m.SetCursorColumn(m.col)
 }
 
 // SetCursorColumn moves the cursor to the given position. If the position is
-// out of bounds the cursor will be moved to the start or end accordingly.
+// out of bounds the cursor will be moved to the start or end accordingly, and
+// inside a grapheme cluster to its start.
 func (m *Model) SetCursorColumn(col int) {
-	m.col = clamp(col, 0, len(m.value[m.row]))
+	m.col = clusterStart(m.value[m.row], clamp(col, 0, len(m.value[m.row])))
 	// Any time that we move the cursor horizontally we need to reset the last
 	// offset so that the horizontal position when navigating is adjusted.
 	m.lastCharOffset = 0
@@ -1318,7 +1221,7 @@ func (m *Model) deleteWordRight() {
 // characterRight moves the cursor one character to the right.
 func (m *Model) characterRight() {
 	if m.col < len(m.value[m.row]) {
-		m.SetCursorColumn(m.col + 1)
+		m.SetCursorColumn(nextCluster(m.value[m.row], m.col))
 	} else {
 		if m.row < len(m.value)-1 {
 			m.row++
@@ -1339,7 +1242,7 @@ func (m *Model) characterLeft(insideLine bool) {
 		}
 	}
 	if m.col > 0 {
-		m.SetCursorColumn(m.col - 1)
+		m.SetCursorColumn(prevCluster(m.value[m.row], m.col))
 	}
 }
 
@@ -1629,8 +1532,9 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				break
 			}
 			if len(m.value[m.row]) > 0 && m.col > 0 {
-				m.value[m.row] = slices.Delete(m.value[m.row], m.col-1, m.col)
-				m.SetCursorColumn(m.col - 1)
+				start := prevCluster(m.value[m.row], m.col)
+				m.value[m.row] = slices.Delete(m.value[m.row], start, m.col)
+				m.SetCursorColumn(start)
 			}
 		case key.Matches(msg, m.KeyMap.DeleteCharacterForward):
 			// If the cursor is on an attachment, convert it to text instead of deleting
@@ -1640,7 +1544,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				}
 			}
 			if len(m.value[m.row]) > 0 && m.col < len(m.value[m.row]) {
-				m.value[m.row] = slices.Delete(m.value[m.row], m.col, m.col+1)
+				m.value[m.row] = slices.Delete(m.value[m.row], m.col, nextCluster(m.value[m.row], m.col))
 			}
 			if m.col >= len(m.value[m.row]) {
 				m.mergeLineBelow(m.row)
@@ -1793,18 +1697,21 @@ func (m Model) View() string {
 				} else if lineInfo.ColumnOffset < len(wrappedLine) {
 					// Render the item under the cursor
 					item := wrappedLine[lineInfo.ColumnOffset]
+					end := lineInfo.ColumnOffset + 1
 					if att, ok := item.(*attachment.Attachment); ok {
 						// Item at cursor is an attachment. Render it with the selection style.
 						// This becomes the "cursor" visually.
 						s.WriteString(m.Styles.SelectedAttachment.Render(att.Display))
 					} else {
-						// Item at cursor is a rune. Render it with the virtual cursor.
-						m.virtualCursor.SetChar(string(item.(rune)))
+						// Item at cursor is a rune. Render it with the virtual cursor,
+						// along with the rest of its grapheme cluster.
+						end = nextCluster(wrappedLine, lineInfo.ColumnOffset)
+						m.virtualCursor.SetChar(interfacesToString(wrappedLine[lineInfo.ColumnOffset:end]))
 						s.WriteString(style.Render(m.virtualCursor.View()))
 					}
 
 					// Render the part of the line after the cursor
-					s.WriteString(m.renderLineWithAttachments(wrappedLine[lineInfo.ColumnOffset+1:], style))
+					s.WriteString(m.renderLineWithAttachments(wrappedLine[end:], style))
 				} else {
 					// Cursor is at the end of the line
 					m.virtualCursor.SetChar(" ")
@@ -2087,16 +1994,6 @@ func (m *Model) splitLine(row, col int) {
 	m.row++
 }
 
-func itemWidth(item any) int {
-	switch v := item.(type) {
-	case rune:
-		return rw.RuneWidth(v)
-	case *attachment.Attachment:
-		return uniseg.StringWidth(v.Display)
-	}
-	return 0
-}
-
 func wrapInterfaces(content []any, width int) [][]any {
 	if width <= 0 {
 		return [][]any{content}
@@ -2109,33 +2006,39 @@ func wrapInterfaces(content []any, width int) [][]any {
 		lineW    int
 		spaceW   int
 		inSpaces bool
+		widths   = cells(content)
 	)
 
-	for _, item := range content {
-		itemW := 0
+	// endWord puts the word on the line, or on a new one when it doesn't fit
+	endWord := func() {
+		if lineW > 0 && lineW+wordW > width {
+			lines = append(lines, word)
+			lineW = wordW
+		} else {
+			lines[len(lines)-1] = append(lines[len(lines)-1], word...)
+			lineW += wordW
+		}
+		word = nil
+		wordW = 0
+	}
+
+	for i, item := range content {
+		itemW := widths[i].width
 		isSpace := false
+		breakAfter := false
 
 		if r, ok := item.(rune); ok {
 			if unicode.IsSpace(r) {
 				isSpace = true
 			}
-			itemW = rw.RuneWidth(r)
-		} else if att, ok := item.(*attachment.Attachment); ok {
-			itemW = uniseg.StringWidth(att.Display)
+			// CJK text has no spaces and may be broken after any character
+			breakAfter = unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
 		}
 
 		if isSpace {
 			if !inSpaces {
 				// End of a word
-				if lineW > 0 && lineW+wordW > width {
-					lines = append(lines, word)
-					lineW = wordW
-				} else {
-					lines[len(lines)-1] = append(lines[len(lines)-1], word...)
-					lineW += wordW
-				}
-				word = nil
-				wordW = 0
+				endWord()
 			}
 			inSpaces = true
 			spaceW += itemW
@@ -2154,20 +2057,25 @@ func wrapInterfaces(content []any, width int) [][]any {
 				spaceW = 0
 			}
 			inSpaces = false
+			if wordW > 0 && wordW+itemW > width {
+				// The word is wider than a whole line, break it where it
+				// reaches the edge. Marks joined to a character don't take
+				// any width, so a grapheme cluster is never broken.
+				endWord()
+				lines = append(lines, []any{})
+				lineW = 0
+			}
 			word = append(word, item)
 			wordW += itemW
+			if breakAfter && (i+1 == len(content) || widths[i+1].start) {
+				endWord()
+			}
 		}
 	}
 
 	// Handle any remaining word/spaces at the end of the content.
 	if wordW > 0 {
-		if lineW > 0 && lineW+wordW > width {
-			lines = append(lines, word)
-			lineW = wordW
-		} else {
-			lines[len(lines)-1] = append(lines[len(lines)-1], word...)
-			lineW += wordW
-		}
+		endWord()
 	}
 	if spaceW > 0 {
 		// There are trailing spaces. Add them.
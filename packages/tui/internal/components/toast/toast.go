@@ -2,6 +2,7 @@ package toast
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,6 +20,42 @@ type ShowToastMsg struct {
 	Title    *string
 	Color    compat.AdaptiveColor
 	Duration time.Duration
+	// Marker is shown instead of the icon by themes that use text markers
+	Marker string
+	// Actions are what the toast offers to do about it
+	Actions []Action
+}
+
+// Action is something a toast offers to do, like restarting after an update,
+// run by the toast_action keybind or from the notifications
+type Action struct {
+	Label string
+	// Msg is sent when the action is run
+	Msg tea.Msg
+}
+
+// Plain is the toast as a line of text, for screen readers, with its kind
+// in words since its color doesn't reach them
+func (m ShowToastMsg) Plain() string {
+	parts := []string{}
+	switch m.Marker {
+	case theme.MarkerError:
+		parts = append(parts, "Error:")
+	case theme.MarkerWarning:
+		parts = append(parts, "Warning:")
+	}
+	if m.Title != nil && *m.Title != "" {
+		parts = append(parts, *m.Title+":")
+	}
+	parts = append(parts, m.Message)
+	if len(m.Actions) > 0 {
+		labels := make([]string, 0, len(m.Actions))
+		for _, action := range m.Actions {
+			labels = append(labels, action.Label)
+		}
+		parts = append(parts, "("+strings.Join(labels, ", ")+")")
+	}
+	return strings.Join(parts, " ")
 }
 
 // DismissToastMsg is a message to dismiss a specific toast
@@ -32,13 +69,51 @@ type Toast struct {
 	Message   string
 	Title     *string
 	Color     compat.AdaptiveColor
+	Marker    string
 	CreatedAt time.Time
 	Duration  time.Duration
+	// Count is how many times the same toast was shown while it was visible
+	Count   int
+	Actions []Action
+}
+
+// key identifies toasts that are the same notification
+func (t Toast) key() string {
+	title := ""
+	if t.Title != nil {
+		title = *t.Title
+	}
+	return t.Marker + "\x00" + title + "\x00" + t.Message
 }
 
+// Icon returns the icon of the toast's kind, or its text marker for themes
+// that use them
+func (t Toast) Icon() string {
+	if theme.CurrentThemeUsesMarkers() {
+		return t.Marker
+	}
+	current := theme.CurrentTheme()
+	return getToastIcon(t.Color, &current)
+}
+
+// maxToasts is the number of toasts shown at once, older ones are dismissed
+// early when more arrive
+const maxToasts = 5
+
+// maxHistory is the number of toasts kept for the notifications after they
+// fade
+const maxHistory = 50
+
 // ToastManager manages multiple toast notifications
 type ToastManager struct {
 	toasts []Toast
+	// history holds the toasts shown lately, the oldest first
+	history []Toast
+	// sequence makes toast IDs unique even when created in the same instant
+	sequence int
+	// actionKey is the keybind running the action of a toast, shown on
+	// the toasts with one
+	actionKey string
 }
 
 // NewToastManager creates a new toast manager
@@ -57,16 +132,48 @@ func (tm *ToastManager) Init() tea.Cmd {
 func (tm *ToastManager) Update(msg tea.Msg) (*ToastManager, tea.Cmd) {
 	switch msg := msg.(type) {
 	case ShowToastMsg:
+		tm.sequence++
 		toast := Toast{
-			ID:        fmt.Sprintf("toast-%d", time.Now().UnixNano()),
+			ID:        fmt.Sprintf("toast-%d-%d", time.Now().UnixNano(), tm.sequence),
 			Title:     msg.Title,
 			Message:   msg.Message,
 			Color:     msg.Color,
+			Marker:    msg.Marker,
 			CreatedAt: time.Now(),
 			Duration:  msg.Duration,
+			Count:     1,
+			Actions:   msg.Actions,
+		}
+
+		// Collapse repeats of a visible toast into it, moving it to the end
+		// and restarting its timer. The new ID makes the pending dismissal of
+		// the old one a no-op.
+		for i, existing := range tm.toasts {
+			if existing.key() == toast.key() {
+				toast.Count = existing.Count + 1
+				tm.toasts = append(tm.toasts[:i], tm.toasts[i+1:]...)
+				break
+			}
 		}
 
 		tm.toasts = append(tm.toasts, toast)
+		if len(tm.toasts) > maxToasts {
+			tm.toasts = tm.toasts[len(tm.toasts)-maxToasts:]
+		}
+
+		// a repeat replaces its earlier entry in the history too
+		if toast.Count > 1 {
+			for i := len(tm.history) - 1; i >= 0; i-- {
+				if tm.history[i].key() == toast.key() {
+					tm.history = append(tm.history[:i], tm.history[i+1:]...)
+					break
+				}
+			}
+		}
+		tm.history = append(tm.history, toast)
+		if len(tm.history) > maxHistory {
+			tm.history = tm.history[len(tm.history)-maxHistory:]
+		}
 
 		// Return command to dismiss after duration
 		return tm, tea.Tick(toast.Duration, func(t time.Time) tea.Msg {
@@ -86,6 +193,30 @@ func (tm *ToastManager) Update(msg tea.Msg) (*ToastManager, tea.Cmd) {
 	return tm, nil
 }
 
+// History returns the toasts shown lately, including the ones that faded,
+// the most recent first
+func (tm *ToastManager) History() []Toast {
+	history := slices.Clone(tm.history)
+	slices.Reverse(history)
+	return history
+}
+
+// Actionable returns the most recent toast on screen with actions
+func (tm *ToastManager) Actionable() (Toast, bool) {
+	for i := len(tm.toasts) - 1; i >= 0; i-- {
+		if len(tm.toasts[i].Actions) > 0 {
+			return tm.toasts[i], true
+		}
+	}
+	return Toast{}, false
+}
+
+// SetActionKey sets the keybind shown on toasts with actions, none when
+// it's empty
+func (tm *ToastManager) SetActionKey(key string) {
+	tm.actionKey = key
+}
+
 // renderSingleToast renders a single toast notification
 func (tm *ToastManager) renderSingleToast(toast Toast) string {
 	t := theme.CurrentTheme()
@@ -104,9 +235,7 @@ func (tm *ToastManager) renderSingleToast(toast Toast) string {
 	// Build content with enhanced formatting
 	var content strings.Builder
 
-	// Add icon based on toast type
-	icon := getToastIcon(toast.Color, &t)
-	if icon != "" {
+	if icon := toast.Icon(); icon != "" {
 		content.WriteString(icon + " ")
 	}
 
@@ -117,14 +246,31 @@ func (tm *ToastManager) renderSingleToast(toast Toast) string {
 		content.WriteString("\n")
 	}
 
+	message := toast.Message
+	if toast.Count > 1 {
+		message += fmt.Sprintf(" (×%d)", toast.Count)
+	}
+
 	// Wrap message text with better formatting
 	messageStyle := styles.NewStyle().
 		Foreground(t.Text())
-	contentWidth := lipgloss.Width(toast.Message)
+	contentWidth := lipgloss.Width(message)
 	if contentWidth > contentMaxWidth {
 		messageStyle = messageStyle.Width(contentMaxWidth)
 	}
-	content.WriteString(messageStyle.Render(toast.Message))
+	content.WriteString(messageStyle.Render(message))
+
+	if len(toast.Actions) > 0 {
+		labels := make([]string, 0, len(toast.Actions))
+		for _, action := range toast.Actions {
+			labels = append(labels, action.Label)
+		}
+		content.WriteString("\n\n")
+		if tm.actionKey != "" {
+			content.WriteString(styles.NewStyle().Foreground(t.Text()).Bold(true).Render(tm.actionKey) + " ")
+		}
+		content.WriteString(styles.NewStyle().Foreground(t.TextMuted()).Render(strings.Join(labels, " / ")))
+	}
 
 	// Render toast with max width and enhanced styling
 	return baseStyle.MaxWidth(maxWidth).Render(content.String())
@@ -138,18 +284,18 @@ func getToastIcon(color compat.AdaptiveColor, t *theme.Theme) string {
 
 	// Check for common error color patterns
 	if strings.Contains(strings.ToLower(colorStr), "red") {
-		return "❌"
+		return styles.IconError.String()
 	}
 	if strings.Contains(strings.ToLower(colorStr), "orange") || strings.Contains(strings.ToLower(colorStr), "yellow") {
-		return "⚠️"
+		return styles.IconWarning.String()
 	}
 	if strings.Contains(strings.ToLower(colorStr), "green") {
-		return "✅"
+		return styles.IconSuccess.String()
 	}
 	if strings.Contains(strings.ToLower(colorStr), "blue") {
-		return "ℹ️"
+		return styles.IconInfo.String()
 	}
-	return "📢"
+	return styles.IconNotice.String()
 }
 
 // View renders all active toasts
@@ -222,6 +368,8 @@ type toastOptions struct {
 	title    *string
 	duration *time.Duration
 	color    *compat.AdaptiveColor
+	marker   string
+	actions  []Action
 }
 
 type ToastOption func(*toastOptions)
@@ -243,6 +391,19 @@ func WithColor(color compat.AdaptiveColor) ToastOption {
 	}
 }
 
+// WithAction offers to send msg from the toast, under the label
+func WithAction(label string, msg tea.Msg) ToastOption {
+	return func(t *toastOptions) {
+		t.actions = append(t.actions, Action{Label: label, Msg: msg})
+	}
+}
+
+func withMarker(marker string) ToastOption {
+	return func(t *toastOptions) {
+		t.marker = marker
+	}
+}
+
 func NewToast(message string, options ...ToastOption) tea.Cmd {
 	t := theme.CurrentTheme()
 	duration := 5 * time.Second
@@ -262,12 +423,14 @@ func NewToast(message string, options ...ToastOption) tea.Cmd {
 			Title:    opts.title,
 			Duration: *opts.duration,
 			Color:    *opts.color,
+			Marker:   opts.marker,
+			Actions:  opts.actions,
 		}
 	}
 }
 
 func NewInfoToast(message string, options ...ToastOption) tea.Cmd {
-	options = append(options, WithColor(theme.CurrentTheme().Info()))
+	options = append(options, WithColor(theme.CurrentTheme().Info()), withMarker(theme.MarkerInfo))
 	return NewToast(
 		message,
 		options...,
@@ -275,7 +438,7 @@ func NewInfoToast(message string, options ...ToastOption) tea.Cmd {
 }
 
 func NewSuccessToast(message string, options ...ToastOption) tea.Cmd {
-	options = append(options, WithColor(theme.CurrentTheme().Success()))
+	options = append(options, WithColor(theme.CurrentTheme().Success()), withMarker(theme.MarkerSuccess))
 	return NewToast(
 		message,
 		options...,
@@ -283,7 +446,7 @@ func NewSuccessToast(message string, options ...ToastOption) tea.Cmd {
 }
 
 func NewWarningToast(message string, options ...ToastOption) tea.Cmd {
-	options = append(options, WithColor(theme.CurrentTheme().Warning()))
+	options = append(options, WithColor(theme.CurrentTheme().Warning()), withMarker(theme.MarkerWarning))
 	return NewToast(
 		message,
 		options...,
@@ -291,7 +454,7 @@ func NewWarningToast(message string, options ...ToastOption) tea.Cmd {
 }
 
 func NewErrorToast(message string, options ...ToastOption) tea.Cmd {
-	options = append(options, WithColor(theme.CurrentTheme().Error()))
+	options = append(options, WithColor(theme.CurrentTheme().Error()), withMarker(theme.MarkerError))
 	return NewToast(
 		message,
 		options...,
@@ -0,0 +1,123 @@
+package toast
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sst/opencode/internal/theme"
+)
+
+func show(tm *ToastManager, message string) {
+	tm.Update(ShowToastMsg{Message: message, Duration: time.Second})
+}
+
+func TestToastDeduplication(t *testing.T) {
+	tm := NewToastManager()
+	show(tm, "reconnect failed")
+	first := tm.toasts[0].ID
+	show(tm, "other")
+	show(tm, "reconnect failed")
+	show(tm, "reconnect failed")
+
+	if len(tm.toasts) != 2 {
+		t.Fatalf("len(toasts) = %d, want 2", len(tm.toasts))
+	}
+	last := tm.toasts[1]
+	if last.Message != "reconnect failed" || last.Count != 3 {
+		t.Errorf("last toast = %q x%d, want %q x3", last.Message, last.Count, "reconnect failed")
+	}
+
+	// the dismissal scheduled for the first occurrence no longer applies
+	tm.Update(DismissToastMsg{ID: first})
+	if len(tm.toasts) != 2 {
+		t.Errorf("stale dismissal removed the repeated toast")
+	}
+	tm.Update(DismissToastMsg{ID: last.ID})
+	if len(tm.toasts) != 1 {
+		t.Errorf("len(toasts) after dismissal = %d, want 1", len(tm.toasts))
+	}
+}
+
+func TestToastLimit(t *testing.T) {
+	tm := NewToastManager()
+	for i := range maxToasts + 3 {
+		show(tm, fmt.Sprintf("toast %d", i))
+	}
+	if len(tm.toasts) != maxToasts {
+		t.Fatalf("len(toasts) = %d, want %d", len(tm.toasts), maxToasts)
+	}
+	if tm.toasts[0].Message != "toast 3" {
+		t.Errorf("oldest toast = %q, want %q", tm.toasts[0].Message, "toast 3")
+	}
+}
+
+func TestPlain(t *testing.T) {
+	title := "Budget running out"
+	msg := ShowToastMsg{Message: "80% of today's budget", Title: &title, Marker: theme.MarkerWarning}
+	if got, want := msg.Plain(), "Warning: Budget running out: 80% of today's budget"; got != want {
+		t.Errorf("Plain() = %q, want %q", got, want)
+	}
+	if got := (ShowToastMsg{Message: "Saved", Marker: theme.MarkerSuccess}).Plain(); got != "Saved" {
+		t.Errorf("Plain() = %q, want %q", got, "Saved")
+	}
+	updated := ShowToastMsg{Message: "kuuzuki updated", Actions: []Action{{Label: "Restart now"}}}
+	if got, want := updated.Plain(), "kuuzuki updated (Restart now)"; got != want {
+		t.Errorf("Plain() = %q, want %q", got, want)
+	}
+}
+
+func TestToastHistory(t *testing.T) {
+	tm := NewToastManager()
+	for i := range maxHistory + 2 {
+		show(tm, fmt.Sprintf("toast %d", i))
+	}
+	show(tm, "reconnect failed")
+	show(tm, "reconnect failed")
+	for _, toast := range tm.toasts {
+		tm.Update(DismissToastMsg{ID: toast.ID})
+	}
+
+	history := tm.History()
+	if len(history) != maxHistory {
+		t.Fatalf("len(History()) = %d, want %d", len(history), maxHistory)
+	}
+	if history[0].Message != "reconnect failed" || history[0].Count != 2 {
+		t.Errorf("History()[0] = %q x%d, want %q x2", history[0].Message, history[0].Count, "reconnect failed")
+	}
+	if history[1].Message != fmt.Sprintf("toast %d", maxHistory+1) {
+		t.Errorf("History()[1] = %q, want the last toast before the repeats", history[1].Message)
+	}
+}
+
+type restartMsg struct{}
+
+func TestToastActions(t *testing.T) {
+	tm := NewToastManager()
+	if _, ok := tm.Actionable(); ok {
+		t.Fatal("Actionable() = true without toasts")
+	}
+	tm.Update(ShowToastMsg{
+		Message:  "kuuzuki updated",
+		Duration: time.Second,
+		Actions:  []Action{{Label: "Restart now", Msg: restartMsg{}}},
+	})
+	show(tm, "saved")
+
+	toast, ok := tm.Actionable()
+	if !ok || toast.Message != "kuuzuki updated" {
+		t.Fatalf("Actionable() = %q, %v, want the update toast", toast.Message, ok)
+	}
+	if len(toast.Actions) != 1 || toast.Actions[0].Msg != (restartMsg{}) {
+		t.Errorf("Actions = %+v, want Restart now", toast.Actions)
+	}
+
+	// actions of faded toasts are run from the notifications only
+	tm.Update(DismissToastMsg{ID: toast.ID})
+	if _, ok := tm.Actionable(); ok {
+		t.Error("Actionable() = true after the toast faded")
+	}
+	if got := tm.History()[1]; len(got.Actions) != 1 {
+		t.Errorf("History()[1].Actions = %+v, want the action kept", got.Actions)
+	}
+}
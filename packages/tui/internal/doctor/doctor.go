@@ -0,0 +1,318 @@
+// Package doctor checks the environment the TUI runs in, the terminal, the
+// server, git and the editor, and reports what is missing or misconfigured.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+	"github.com/sst/opencode/internal/clipboard"
+	"github.com/sst/opencode/internal/launch"
+	"github.com/sst/opencode/internal/styles"
+)
+
+// probeTimeout bounds the check of the server
+const probeTimeout = 3 * time.Second
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK Status = "ok"
+	// StatusWarn is for what degrades the TUI without breaking it
+	StatusWarn Status = "warn"
+	// StatusFail is for what keeps the TUI from working
+	StatusFail Status = "fail"
+)
+
+// Check is the result of one diagnostic
+type Check struct {
+	Name   string
+	Status Status
+	Detail string
+}
+
+// Options configure a diagnostics run
+type Options struct {
+	// ServerURL is the server the TUI was given, empty to look for one of Cwd
+	ServerURL string
+	Cwd       string
+	// Terminal is the terminal to query, nil when it is not one
+	Terminal *Terminal
+	// Getenv reads the environment, os.Getenv when nil
+	Getenv func(string) string
+}
+
+// Run runs every check
+func Run(ctx context.Context, opts Options) []Check {
+	if opts.Getenv == nil {
+		opts.Getenv = os.Getenv
+	}
+	var replies Replies
+	queried := false
+	if opts.Terminal != nil {
+		var err error
+		replies, err = opts.Terminal.Query()
+		queried = err == nil
+	}
+	checks := []Check{colorCheck(opts.Getenv), renderingCheck(opts.Getenv, runtime.GOOS)}
+	checks = append(checks, terminalChecks(replies, queried)...)
+	checks = append(checks,
+		clipboardCheck(opts.Getenv),
+		serverCheck(ctx, opts.ServerURL, opts.Cwd),
+		gitCheck(opts.Cwd),
+		editorCheck(opts.Getenv),
+	)
+	return checks
+}
+
+// Failed reports whether a check failed
+func Failed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Report writes the checks, one per line
+func Report(w io.Writer, checks []Check) {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+	for _, check := range checks {
+		mark := "✓"
+		switch check.Status {
+		case StatusWarn:
+			mark = "!"
+		case StatusFail:
+			mark = "✗"
+		}
+		fmt.Fprintf(w, "%s %-*s  %s\n", mark, width, check.Name, check.Detail)
+	}
+}
+
+// colorCheck tells whether themes render in truecolor, terminals announce it
+// in COLORTERM
+func colorCheck(getenv func(string) string) Check {
+	check := Check{Name: "truecolor"}
+	colorterm := strings.ToLower(getenv("COLORTERM"))
+	term := getenv("TERM")
+	switch {
+	case colorterm == "truecolor" || colorterm == "24bit":
+		check.Status = StatusOK
+		check.Detail = "COLORTERM=" + colorterm
+	case strings.HasSuffix(term, "-direct"):
+		check.Status = StatusOK
+		check.Detail = "TERM=" + term
+	case term == "" || term == "dumb":
+		check.Status = StatusFail
+		check.Detail = "TERM is not set to a color terminal"
+	default:
+		check.Status = StatusWarn
+		check.Detail = "COLORTERM is not truecolor, theme colors are approximated; set COLORTERM=truecolor if the terminal supports it"
+	}
+	return check
+}
+
+// renderingCheck tells whether the TUI draws unicode borders or falls back
+// to ASCII in 16 colors
+func renderingCheck(getenv func(string) string, goos string) Check {
+	check := Check{Name: "rendering"}
+	if styles.DetectFancy(getenv, goos) {
+		check.Status = StatusOK
+		check.Detail = "unicode borders and symbols"
+		return check
+	}
+	check.Status = StatusWarn
+	check.Detail = "ASCII borders in 16 colors, the terminal or its locale lacks unicode or truecolor; use a UTF-8 locale and set COLORTERM=truecolor if it supports them"
+	return check
+}
+
+// terminalChecks reports the focus events and the kitty keyboard protocol
+// from the replies of the terminal
+func terminalChecks(replies Replies, queried bool) []Check {
+	focus := Check{Name: "focus events"}
+	keyboard := Check{Name: "kitty keyboard"}
+	if !queried {
+		detail := "stdin is not a terminal, run in the terminal to check"
+		focus.Status, focus.Detail = StatusWarn, detail
+		keyboard.Status, keyboard.Detail = StatusWarn, detail
+		return []Check{focus, keyboard}
+	}
+
+	switch replies.Focus {
+	case ModeSet, ModeReset, ModePermanentlySet:
+		focus.Status = StatusOK
+		focus.Detail = "supported"
+	case ModeNotRecognized, ModePermanentlyReset:
+		focus.Status = StatusWarn
+		focus.Detail = "not supported, pasted files are accepted in every open instance"
+	default:
+		focus.Status = StatusWarn
+		focus.Detail = "the terminal did not answer, focus events may not be supported"
+	}
+
+	if replies.KittyKeyboard {
+		keyboard.Status = StatusOK
+		keyboard.Detail = "supported"
+	} else {
+		keyboard.Status = StatusWarn
+		keyboard.Detail = "not supported, keys like shift+enter and ctrl+i may be indistinguishable from enter and tab"
+	}
+	if !replies.Answered {
+		keyboard.Detail = "the terminal did not answer its queries"
+	}
+	return []Check{focus, keyboard}
+}
+
+// osc52Terminals are terminals known to let programs set the clipboard with
+// OSC 52, by TERM_PROGRAM or TERM
+var osc52Terminals = []string{
+	"iterm.app", "wezterm", "ghostty", "kitty", "alacritty", "foot",
+	"vscode", "tabby", "rio", "contour", "windows terminal",
+}
+
+// clipboardCheck looks for a clipboard tool, and for OSC 52 which copying
+// also goes through
+func clipboardCheck(getenv func(string) string) Check {
+	check := Check{Name: "clipboard"}
+	var tool string
+	if err := clipboard.Init(); err == nil {
+		tool = "system clipboard available"
+	}
+
+	osc52 := getenv("WT_SESSION") != ""
+	names := strings.ToLower(getenv("TERM_PROGRAM") + " " + getenv("TERM"))
+	for _, name := range osc52Terminals {
+		if strings.Contains(names, name) {
+			osc52 = true
+		}
+	}
+	multiplexer := ""
+	if getenv("TMUX") != "" {
+		multiplexer = "tmux, OSC 52 needs set -g allow-passthrough on"
+	} else if strings.HasPrefix(getenv("TERM"), "screen") {
+		multiplexer = "screen, which may drop OSC 52"
+	}
+
+	var details []string
+	if tool != "" {
+		details = append(details, tool)
+	}
+	switch {
+	case multiplexer != "":
+		details = append(details, "running in "+multiplexer)
+	case osc52:
+		details = append(details, "OSC 52 supported")
+	default:
+		details = append(details, "OSC 52 support unknown")
+	}
+
+	check.Status = StatusWarn
+	if tool != "" || (osc52 && multiplexer == "") {
+		check.Status = StatusOK
+	} else {
+		details = append(details, "install xclip, xsel or wl-clipboard")
+	}
+	check.Detail = strings.Join(details, ", ")
+	return check
+}
+
+// serverCheck reaches the server the TUI would use, without starting one
+func serverCheck(ctx context.Context, url, cwd string) Check {
+	check := Check{Name: "server"}
+	if url == "" {
+		if url = launch.Discover(ctx, cwd); url == "" {
+			bin, err := launch.Binary()
+			if err != nil {
+				check.Status = StatusFail
+				check.Detail = "no server is running for this directory and none can be started: " + err.Error()
+				return check
+			}
+			check.Status = StatusOK
+			check.Detail = "no server is running for this directory, the TUI starts one with " + bin
+			return check
+		}
+	}
+	client := opencode.NewClient(
+		option.WithBaseURL(url),
+		option.WithMaxRetries(0),
+		option.WithRequestTimeout(probeTimeout),
+	)
+	app, err := client.App.Get(ctx)
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = url + " is unreachable: " + err.Error()
+		return check
+	}
+	check.Status = StatusOK
+	check.Detail = url + " serving " + app.Path.Cwd
+	return check
+}
+
+// gitCheck looks for git and whether the directory is in a repository,
+// which the file changes and snapshots of sessions depend on
+func gitCheck(cwd string) Check {
+	check := Check{Name: "git"}
+	bin, err := exec.LookPath("git")
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = "git not found on the PATH"
+		return check
+	}
+	version, err := exec.Command(bin, "--version").Output()
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = "git --version failed: " + err.Error()
+		return check
+	}
+	check.Detail = strings.TrimSpace(string(version))
+
+	cmd := exec.Command(bin, "rev-parse", "--show-toplevel")
+	cmd.Dir = cwd
+	root, err := cmd.Output()
+	if err != nil {
+		check.Status = StatusWarn
+		check.Detail += ", " + filepath.Base(cwd) + " is not in a git repository"
+		return check
+	}
+	check.Status = StatusOK
+	check.Detail += ", repository at " + strings.TrimSpace(string(root))
+	return check
+}
+
+// editorCheck finds the EDITOR the TUI opens files and prompts in
+func editorCheck(getenv func(string) string) Check {
+	check := Check{Name: "editor"}
+	editor := getenv("EDITOR")
+	if editor == "" {
+		check.Status = StatusWarn
+		check.Detail = "EDITOR is not set, opening the prompt in an editor is disabled"
+		if visual := getenv("VISUAL"); visual != "" {
+			check.Detail += ", VISUAL is not used"
+		}
+		return check
+	}
+	// EDITOR may carry arguments, e.g. "code --wait"
+	name := strings.Fields(editor)[0]
+	if _, err := exec.LookPath(name); err != nil {
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("EDITOR=%s, %s not found on the PATH", editor, name)
+		return check
+	}
+	check.Status = StatusOK
+	check.Detail = "EDITOR=" + editor
+	return check
+}
@@ -0,0 +1,97 @@
+package doctor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestParseReplies(t *testing.T) {
+	replies := parseReplies([]byte("\x1b[?1004;2$y\x1b[?1u\x1b[?62;22c"))
+	if replies.Focus != ModeReset || !replies.KittyKeyboard || !replies.Answered {
+		t.Errorf("expected every reply to be read, got %+v", replies)
+	}
+
+	// terminals without the kitty protocol only answer the device attributes
+	replies = parseReplies([]byte("\x1b[?1;2c"))
+	if replies.Focus != ModeUnknown || replies.KittyKeyboard || !replies.Answered {
+		t.Errorf("expected only the device attributes, got %+v", replies)
+	}
+
+	replies = parseReplies(nil)
+	if replies.Answered {
+		t.Error("expected no answer without replies")
+	}
+}
+
+func TestTerminalChecks(t *testing.T) {
+	checks := terminalChecks(Replies{Focus: ModeSet, KittyKeyboard: true, Answered: true}, true)
+	for _, check := range checks {
+		if check.Status != StatusOK {
+			t.Errorf("expected %s to pass, got %s", check.Name, check.Detail)
+		}
+	}
+
+	checks = terminalChecks(Replies{Focus: ModeNotRecognized, Answered: true}, true)
+	for _, check := range checks {
+		if check.Status != StatusWarn {
+			t.Errorf("expected %s to warn, got %s", check.Name, check.Status)
+		}
+	}
+}
+
+func TestColorCheck(t *testing.T) {
+	tests := []struct {
+		vars   map[string]string
+		status Status
+	}{
+		{map[string]string{"COLORTERM": "truecolor", "TERM": "xterm-256color"}, StatusOK},
+		{map[string]string{"TERM": "xterm-direct"}, StatusOK},
+		{map[string]string{"TERM": "xterm-256color"}, StatusWarn},
+		{map[string]string{"TERM": "dumb"}, StatusFail},
+	}
+	for _, test := range tests {
+		if check := colorCheck(env(test.vars)); check.Status != test.status {
+			t.Errorf("%v: expected %s, got %s (%s)", test.vars, test.status, check.Status, check.Detail)
+		}
+	}
+}
+
+func TestRenderingCheck(t *testing.T) {
+	if check := renderingCheck(env(map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"}), "linux"); check.Status != StatusOK {
+		t.Errorf("expected ok, got %s (%s)", check.Status, check.Detail)
+	}
+	if check := renderingCheck(env(map[string]string{"TERM": "xterm"}), "linux"); check.Status != StatusWarn {
+		t.Errorf("expected a plain xterm to warn, got %s", check.Status)
+	}
+}
+
+func TestEditorCheck(t *testing.T) {
+	if check := editorCheck(env(nil)); check.Status != StatusWarn {
+		t.Errorf("expected a missing EDITOR to warn, got %s", check.Status)
+	}
+	check := editorCheck(env(map[string]string{"EDITOR": "kuuzuki-no-such-editor --wait"}))
+	if check.Status != StatusFail || !strings.Contains(check.Detail, "kuuzuki-no-such-editor not found") {
+		t.Errorf("expected an editor off the PATH to fail, got %s", check.Detail)
+	}
+}
+
+func TestReport(t *testing.T) {
+	checks := []Check{
+		{Name: "git", Status: StatusOK, Detail: "git version 2.43.0"},
+		{Name: "editor", Status: StatusFail, Detail: "EDITOR=nope"},
+	}
+	var out bytes.Buffer
+	Report(&out, checks)
+	expected := "✓ git     git version 2.43.0\n✗ editor  EDITOR=nope\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	if !Failed(checks) {
+		t.Error("expected the report to have failed")
+	}
+}
@@ -0,0 +1,118 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// queryTimeout bounds the wait for the replies of the terminal, terminals
+// that do not know a query stay silent
+const queryTimeout = 2 * time.Second
+
+// queries asks for the focus events mode (DECRQM 1004) and the kitty keyboard
+// flags, then for the device attributes (DA1) which every terminal answers
+// last
+const queries = "\x1b[?1004$p" + "\x1b[?u" + "\x1b[c"
+
+// Mode is the state of a terminal mode as reported by DECRPM
+type Mode int
+
+const (
+	// ModeUnknown is for a terminal that did not reply
+	ModeUnknown Mode = iota - 1
+	ModeNotRecognized
+	ModeSet
+	ModeReset
+	ModePermanentlySet
+	ModePermanentlyReset
+)
+
+// Replies is what the terminal answered to the queries
+type Replies struct {
+	Focus         Mode
+	KittyKeyboard bool
+	// Answered is set when the device attributes came back
+	Answered bool
+}
+
+var (
+	modeReply  = regexp.MustCompile(`\x1b\[\?1004;(\d)\$y`)
+	kittyReply = regexp.MustCompile(`\x1b\[\?\d+u`)
+	da1Reply   = regexp.MustCompile(`\x1b\[\?[\d;]*c`)
+)
+
+// parseReplies reads the replies to the queries from the terminal input
+func parseReplies(data []byte) Replies {
+	replies := Replies{Focus: ModeUnknown}
+	if match := modeReply.FindSubmatch(data); match != nil {
+		mode, _ := strconv.Atoi(string(match[1]))
+		replies.Focus = Mode(mode)
+	}
+	replies.KittyKeyboard = kittyReply.Match(data)
+	replies.Answered = da1Reply.Match(data)
+	return replies
+}
+
+// Terminal is the terminal the TUI draws to
+type Terminal struct {
+	in  *os.File
+	out *os.File
+}
+
+// NewTerminal returns the terminal of in and out, nil when they are not one
+func NewTerminal(in, out *os.File) *Terminal {
+	if !term.IsTerminal(in.Fd()) || !term.IsTerminal(out.Fd()) {
+		return nil
+	}
+	return &Terminal{in: in, out: out}
+}
+
+// Query sends the queries and collects the replies until the device
+// attributes arrive or the timeout passes
+func (t *Terminal) Query() (Replies, error) {
+	state, err := term.MakeRaw(t.in.Fd())
+	if err != nil {
+		return Replies{}, err
+	}
+	defer term.Restore(t.in.Fd(), state)
+	if _, err := t.out.WriteString(queries); err != nil {
+		return Replies{}, err
+	}
+
+	chunks := make(chan []byte, 8)
+	go func() {
+		// the read is left pending on timeout, the process exits after the
+		// report
+		for {
+			buf := make([]byte, 256)
+			n, err := t.in.Read(buf)
+			if err != nil {
+				close(chunks)
+				return
+			}
+			chunks <- buf[:n]
+		}
+	}()
+
+	var data []byte
+	timeout := time.After(queryTimeout)
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return parseReplies(data), errors.New("the terminal closed")
+			}
+			data = append(data, chunk...)
+			if da1Reply.Match(data) {
+				return parseReplies(data), nil
+			}
+		case <-timeout:
+			return parseReplies(data), nil
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package e2e drives the full TUI headlessly against a server, typically the
+// SDK's mock server, so flows spanning the model, its components and the API
+// can be scripted and asserted on.
+//
+// The scripted tests are behind the e2e build tag. Start the mock server with
+// packages/sdk/go/scripts/mock --daemon, then run
+//
+//	go test -tags e2e ./internal/e2e
+//
+// TEST_API_BASE_URL points the tests at another server.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/tui"
+)
+
+// DefaultCmdTimeout is how long a command may run before its message is
+// dropped, long enough for API calls but shorter than timers like toast
+// dismissal
+const DefaultCmdTimeout = 500 * time.Millisecond
+
+// Options configure a driver
+type Options struct {
+	// BaseURL of the server, e.g. the mock server at http://localhost:4010
+	BaseURL string
+	// Dir replaces the project and state paths reported by the server, so
+	// the driver never writes outside of it
+	Dir    string
+	Width  int
+	Height int
+	// CmdTimeout overrides DefaultCmdTimeout
+	CmdTimeout time.Duration
+}
+
+// Driver runs the TUI model, feeding it scripted messages and running the
+// commands they return until the model settles
+type Driver struct {
+	App     *app.App
+	model   tea.Model
+	timeout time.Duration
+	quit    bool
+}
+
+// New starts the TUI against the server, sending the initial window size and
+// processing the commands of Init
+func New(ctx context.Context, opts Options) (*Driver, error) {
+	client := opencode.NewClient(option.WithBaseURL(opts.BaseURL))
+
+	info, err := client.App.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app info: %w", err)
+	}
+	agents, err := client.App.Modes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modes: %w", err)
+	}
+	if agents == nil || len(*agents) == 0 {
+		return nil, fmt.Errorf("server returned no modes")
+	}
+	if opts.Dir != "" {
+		info.Path.Root = opts.Dir
+		info.Path.Cwd = opts.Dir
+		info.Path.Config = opts.Dir
+		info.Path.Data = opts.Dir
+		info.Path.State = opts.Dir
+	}
+
+	a, err := app.New(ctx, "dev", *info, *agents, client, nil, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create app: %w", err)
+	}
+
+	d := &Driver{
+		App:     a,
+		model:   tui.NewModel(a),
+		timeout: opts.CmdTimeout,
+	}
+	if d.timeout <= 0 {
+		d.timeout = DefaultCmdTimeout
+	}
+	width, height := opts.Width, opts.Height
+	if width <= 0 || height <= 0 {
+		width, height = 120, 40
+	}
+	d.Send(tea.WindowSizeMsg{Width: width, Height: height})
+	d.run(d.model.Init())
+	return d, nil
+}
+
+// Send updates the model with each message in turn, running the commands
+// they return
+func (d *Driver) Send(msgs ...tea.Msg) {
+	for _, msg := range msgs {
+		d.update(msg)
+	}
+}
+
+func (d *Driver) update(msg tea.Msg) {
+	if msg == nil || d.quit {
+		return
+	}
+	if _, ok := msg.(tea.QuitMsg); ok {
+		d.quit = true
+		return
+	}
+	// batched and sequenced commands arrive as slices of commands, the
+	// sequence type being unexported
+	if value := reflect.ValueOf(msg); value.Kind() == reflect.Slice &&
+		value.Type().Elem() == reflect.TypeOf((tea.Cmd)(nil)) {
+		for i := range value.Len() {
+			d.run(value.Index(i).Interface().(tea.Cmd))
+		}
+		return
+	}
+	var cmd tea.Cmd
+	d.model, cmd = d.model.Update(msg)
+	d.run(cmd)
+}
+
+// run runs a command and feeds its message back to the model, dropping it if
+// the command outlasts the timeout
+func (d *Driver) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	result := make(chan tea.Msg, 1)
+	go func() {
+		result <- cmd()
+	}()
+	select {
+	case msg := <-result:
+		d.update(msg)
+	case <-time.After(d.timeout):
+	}
+}
+
+// Press sends key presses by name, e.g. "enter", "esc", "ctrl+x" or "a"
+func (d *Driver) Press(keys ...string) {
+	for _, key := range keys {
+		d.Send(ParseKey(key))
+	}
+}
+
+// Type sends a key press for each character of the text
+func (d *Driver) Type(text string) {
+	for _, r := range text {
+		d.Send(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+// Frame returns the current frame without styling
+func (d *Driver) Frame() string {
+	return ansi.Strip(d.model.(tea.ViewModel).View())
+}
+
+// FrameContains reports whether the current frame shows the text
+func (d *Driver) FrameContains(text string) bool {
+	return strings.Contains(d.Frame(), text)
+}
+
+// Quit reports whether the model asked to quit
+func (d *Driver) Quit() bool {
+	return d.quit
+}
+
+var namedKeys = map[string]rune{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEscape,
+	"tab":       tea.KeyTab,
+	"backspace": tea.KeyBackspace,
+	"space":     tea.KeySpace,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+}
+
+// ParseKey builds the key press for a key name with optional ctrl+, alt+ and
+// shift+ modifiers
+func ParseKey(name string) tea.KeyPressMsg {
+	var mod tea.KeyMod
+	for {
+		switch {
+		case strings.HasPrefix(name, "ctrl+"):
+			mod |= tea.ModCtrl
+			name = strings.TrimPrefix(name, "ctrl+")
+			continue
+		case strings.HasPrefix(name, "alt+"):
+			mod |= tea.ModAlt
+			name = strings.TrimPrefix(name, "alt+")
+			continue
+		case strings.HasPrefix(name, "shift+"):
+			mod |= tea.ModShift
+			name = strings.TrimPrefix(name, "shift+")
+			continue
+		}
+		break
+	}
+	if code, ok := namedKeys[name]; ok {
+		return tea.KeyPressMsg{Code: code, Mod: mod}
+	}
+	code := []rune(name)[0]
+	if mod != 0 {
+		return tea.KeyPressMsg{Code: code, Mod: mod}
+	}
+	return tea.KeyPressMsg{Code: code, Text: name}
+}
@@ -0,0 +1,28 @@
+package e2e
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want tea.KeyPressMsg
+	}{
+		{"a", tea.KeyPressMsg{Code: 'a', Text: "a"}},
+		{"enter", tea.KeyPressMsg{Code: tea.KeyEnter}},
+		{"ctrl+x", tea.KeyPressMsg{Code: 'x', Mod: tea.ModCtrl}},
+		{"ctrl+alt+up", tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl | tea.ModAlt}},
+	}
+	for _, tt := range tests {
+		got := ParseKey(tt.name)
+		if got.Code != tt.want.Code || got.Mod != tt.want.Mod || got.Text != tt.want.Text {
+			t.Errorf("ParseKey(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if got.String() != tt.name {
+			t.Errorf("ParseKey(%q) reads back as %q", tt.name, got.String())
+		}
+	}
+}
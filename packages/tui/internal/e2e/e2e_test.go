@@ -0,0 +1,91 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/chat"
+)
+
+func newDriver(t *testing.T) *Driver {
+	t.Helper()
+	baseURL := "http://localhost:4010"
+	if env, ok := os.LookupEnv("TEST_API_BASE_URL"); ok {
+		baseURL = env
+	}
+	if _, err := http.Get(baseURL); err != nil {
+		t.Skipf("no server running at %s: %v", baseURL, err)
+	}
+	d, err := New(context.Background(), Options{BaseURL: baseURL, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestStartup(t *testing.T) {
+	d := newDriver(t)
+	if !d.FrameContains("AGENT") {
+		t.Errorf("status bar missing from the first frame:\n%s", d.Frame())
+	}
+	if d.App.Model == nil {
+		t.Error("no model selected once the providers loaded")
+	}
+}
+
+func TestToolApproval(t *testing.T) {
+	d := newDriver(t)
+	d.Send(chat.ToolApprovalMsg{
+		ID:          "per_1",
+		ToolName:    "bash",
+		Description: "Permission requested",
+		Metadata:    map[string]any{"command": "ls"},
+	})
+	if !d.FrameContains("Permission Required") {
+		t.Fatalf("approval not shown:\n%s", d.Frame())
+	}
+
+	d.Press("enter")
+	if d.FrameContains("Permission Required") {
+		t.Errorf("approval still shown after accepting:\n%s", d.Frame())
+	}
+}
+
+func TestSessionSwitching(t *testing.T) {
+	d := newDriver(t)
+	sessions, err := d.App.ListSessions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) == 0 {
+		t.Skip("server returned no sessions")
+	}
+
+	d.Send(app.SessionSelectedMsg(&sessions[0]))
+	if d.App.Session.ID != sessions[0].ID {
+		t.Errorf("session = %q, want %q", d.App.Session.ID, sessions[0].ID)
+	}
+
+	d.Press("ctrl+x", "n")
+	if d.App.Session.ID != "" {
+		t.Errorf("session = %q after starting a new one", d.App.Session.ID)
+	}
+}
+
+func TestCommandCompletions(t *testing.T) {
+	d := newDriver(t)
+	d.Type("/hel")
+	if !d.FrameContains("help") {
+		t.Fatalf("command completions not shown:\n%s", d.Frame())
+	}
+
+	d.Press("esc")
+	if d.Quit() {
+		t.Error("dismissing completions quit the app")
+	}
+}
@@ -0,0 +1,174 @@
+// Package filetree lists a directory tree one directory at a time, leaving
+// out what git ignores.
+package filetree
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// Node is a file or directory, its children are read on first expansion
+type Node struct {
+	Name     string
+	Path     string // relative to the tree root, slash separated
+	Dir      bool
+	Depth    int
+	Expanded bool
+	Loaded   bool
+	Children []*Node
+}
+
+// Tree is a directory tree rooted at Root
+type Tree struct {
+	Root  string
+	Nodes []*Node
+}
+
+// Row is a node as shown, with the query matching it or a descendant
+type Row struct {
+	*Node
+	// Open is set for directories shown expanded, either by the user or
+	// because the query matches something below them
+	Open bool
+}
+
+// New reads the top level of root
+func New(root string) (*Tree, error) {
+	nodes, err := readDir(root, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{Root: root, Nodes: nodes}, nil
+}
+
+// Toggle expands or collapses a directory, reading it the first time
+func (t *Tree) Toggle(node *Node) error {
+	if !node.Dir {
+		return nil
+	}
+	if !node.Loaded {
+		children, err := readDir(t.Root, node.Path, node.Depth+1)
+		if err != nil {
+			return err
+		}
+		node.Children = children
+		node.Loaded = true
+	}
+	node.Expanded = !node.Expanded
+	return nil
+}
+
+// Rows lists the visible nodes in order. With a query, only the loaded nodes
+// matching it and the directories leading to them are listed.
+func (t *Tree) Rows(query string) []Row {
+	query = strings.TrimSpace(query)
+	var rows []Row
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, node := range nodes {
+			if query == "" {
+				rows = append(rows, Row{Node: node, Open: node.Expanded})
+				if node.Expanded {
+					walk(node.Children)
+				}
+				continue
+			}
+			if !matches(node, query) {
+				continue
+			}
+			descendant := node.Dir && anyMatches(node.Children, query)
+			rows = append(rows, Row{Node: node, Open: descendant})
+			if descendant {
+				walk(node.Children)
+			}
+		}
+	}
+	walk(t.Nodes)
+	return rows
+}
+
+func matches(node *Node, query string) bool {
+	return fuzzy.MatchFold(query, node.Path) || (node.Dir && anyMatches(node.Children, query))
+}
+
+func anyMatches(nodes []*Node, query string) bool {
+	for _, node := range nodes {
+		if matches(node, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// readDir lists a directory, directories first, leaving out .git and the
+// entries git ignores
+func readDir(root, rel string, depth int) ([]*Node, error) {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*Node
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		dir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel), entry.Name())); err == nil {
+				dir = info.IsDir()
+			}
+		}
+		nodes = append(nodes, &Node{
+			Name:  entry.Name(),
+			Path:  strings.TrimPrefix(rel+"/"+entry.Name(), "/"),
+			Dir:   dir,
+			Depth: depth,
+		})
+	}
+
+	ignored := ignoredPaths(root, nodes)
+	kept := nodes[:0]
+	for _, node := range nodes {
+		if !ignored[node.Path] {
+			kept = append(kept, node)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Dir != kept[j].Dir {
+			return kept[i].Dir
+		}
+		return strings.ToLower(kept[i].Name) < strings.ToLower(kept[j].Name)
+	})
+	return kept, nil
+}
+
+// ignoredPaths asks git which of the nodes it ignores, outside a repository
+// nothing is
+func ignoredPaths(root string, nodes []*Node) map[string]bool {
+	ignored := map[string]bool{}
+	if len(nodes) == 0 {
+		return ignored
+	}
+	var input bytes.Buffer
+	for _, node := range nodes {
+		input.WriteString(node.Path)
+		input.WriteByte(0)
+	}
+	cmd := exec.Command("git", "check-ignore", "-z", "--stdin")
+	cmd.Dir = root
+	cmd.Stdin = &input
+	// exits with 1 when nothing is ignored
+	output, _ := cmd.Output()
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			ignored[path] = true
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,84 @@
+package filetree
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"src/main.go", "src/util/strings.go", "build/out.bin", "README.md", ".gitignore"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitIgnores := false
+	if _, err := exec.LookPath("git"); err == nil {
+		cmd := exec.Command("git", "init", "-q")
+		cmd.Dir = root
+		gitIgnores = cmd.Run() == nil
+	}
+
+	tree, err := New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := func(rows []Row) []string {
+		var result []string
+		for _, row := range rows {
+			result = append(result, row.Path)
+		}
+		return result
+	}
+
+	// build/ is only left out inside a git repository
+	var build []string
+	if !gitIgnores {
+		build = []string{"build"}
+	}
+	want := append(build, "src", ".gitignore", "README.md")
+	if got := paths(tree.Rows("")); !equal(got, want) {
+		t.Fatalf("top level = %v, want %v", got, want)
+	}
+
+	src := tree.Rows("")[len(build)].Node
+	if err := tree.Toggle(src); err != nil {
+		t.Fatal(err)
+	}
+	want = append(build, "src", "src/util", "src/main.go", ".gitignore", "README.md")
+	if got := paths(tree.Rows("")); !equal(got, want) {
+		t.Errorf("expanded = %v, want %v", got, want)
+	}
+
+	// src/util isn't loaded yet, so its files don't match
+	if got := paths(tree.Rows("main")); !equal(got, []string{"src", "src/main.go"}) {
+		t.Errorf("filtered = %v", got)
+	}
+	if err := tree.Toggle(src); err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(tree.Rows("main")); !equal(got, []string{"src", "src/main.go"}) {
+		t.Errorf("filtered while collapsed = %v", got)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
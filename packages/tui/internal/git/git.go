@@ -0,0 +1,176 @@
+// Package git reads the working tree for the commit and review flows, with
+// the same git binary the agent's own commands run.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// maxPatch caps the diff handed to the model, large diffs are summarized by
+// their file list
+const maxPatch = 48 * 1024
+
+func git(root string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-c", "core.quotePath=false"}, args...)...)
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// Ignored reports whether git ignores the path
+func Ignored(root, path string) bool {
+	_, err := git(root, "check-ignore", "-q", "--", path)
+	return err == nil
+}
+
+// IgnoredPaths returns which of the paths git ignores
+func IgnoredPaths(root string, paths []string) map[string]bool {
+	ignored := map[string]bool{}
+	if len(paths) == 0 {
+		return ignored
+	}
+	// check-ignore fails when none of the paths are ignored
+	output, _ := git(root, append([]string{"check-ignore", "--"}, paths...)...)
+	for line := range strings.Lines(output) {
+		ignored[strings.TrimRight(line, "\n")] = true
+	}
+	return ignored
+}
+
+// FileStat is a changed file and its counts of changed lines
+type FileStat struct {
+	Path    string
+	Added   int
+	Removed int
+	// Binary files have no line counts
+	Binary bool
+	// Untracked files are new to git, their lines aren't counted
+	Untracked bool
+}
+
+// Changes are what a commit of the working tree would record
+type Changes struct {
+	// Staged is set when the index holds changes, only those are committed.
+	// Otherwise every change is, untracked files included.
+	Staged bool
+	Files  []FileStat
+	// Patch is the diff of the changes, cut at maxPatch bytes
+	Patch string
+}
+
+// Empty reports whether there is nothing to commit
+func (c Changes) Empty() bool {
+	return len(c.Files) == 0
+}
+
+// Commit records the changes with the message, every change staged first
+// unless some already were, and returns git's summary of the commit. The
+// message goes through a file, not a shell.
+func (c Changes) Commit(root, message string) (string, error) {
+	file, err := os.CreateTemp("", "kuuzuki-commit-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(message)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if !c.Staged {
+		if _, err := git(root, "add", "-A"); err != nil {
+			return "", err
+		}
+	}
+	output, err := git(root, "commit", "-F", file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Pending returns the changes a commit would record: the staged ones, or
+// every change when nothing is staged
+func Pending(root string) (Changes, error) {
+	staged, err := git(root, "diff", "--cached", "--numstat")
+	if err != nil {
+		return Changes{}, err
+	}
+	if files := parseNumstat(staged); len(files) > 0 {
+		patch, err := git(root, "diff", "--cached")
+		if err != nil {
+			return Changes{}, err
+		}
+		return Changes{Staged: true, Files: files, Patch: cut(patch)}, nil
+	}
+	return workingTree(root)
+}
+
+// workingTree returns every change to the working tree, staged or not,
+// untracked files included
+func workingTree(root string) (Changes, error) {
+	var changes Changes
+	// a repository without commits has no tracked changes yet
+	if _, err := git(root, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		numstat, err := git(root, "diff", "HEAD", "--numstat")
+		if err != nil {
+			return Changes{}, err
+		}
+		changes.Files = parseNumstat(numstat)
+		if changes.Patch, err = git(root, "diff", "HEAD"); err != nil {
+			return Changes{}, err
+		}
+	}
+	untracked, err := git(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return Changes{}, err
+	}
+	for path := range strings.SplitSeq(untracked, "\n") {
+		if path == "" {
+			continue
+		}
+		changes.Files = append(changes.Files, FileStat{Path: path, Untracked: true})
+		changes.Patch += "new file " + path + "\n"
+	}
+	changes.Patch = cut(changes.Patch)
+	return changes, nil
+}
+
+// parseNumstat reads the output of git diff --numstat
+func parseNumstat(output string) []FileStat {
+	var files []FileStat
+	for line := range strings.SplitSeq(output, "\n") {
+		added, rest, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		removed, path, ok := strings.Cut(rest, "\t")
+		if !ok {
+			continue
+		}
+		file := FileStat{Path: path, Binary: added == "-"}
+		file.Added, _ = strconv.Atoi(added)
+		file.Removed, _ = strconv.Atoi(removed)
+		files = append(files, file)
+	}
+	return files
+}
+
+func cut(patch string) string {
+	if len(patch) <= maxPatch {
+		return patch
+	}
+	return patch[:maxPatch] + "\n[diff cut]\n"
+}
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPending(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("tracked.txt", "one\ntwo\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	changes, err := Pending(root)
+	if err != nil || !changes.Empty() {
+		t.Fatalf("Pending() = %+v, %v, want no changes", changes, err)
+	}
+
+	write("tracked.txt", "one\nthree\nfour\n")
+	write("untracked.txt", "new")
+	changes, err = Pending(root)
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	want := []FileStat{
+		{Path: "tracked.txt", Added: 2, Removed: 1},
+		{Path: "untracked.txt", Untracked: true},
+	}
+	if changes.Staged || len(changes.Files) != len(want) {
+		t.Fatalf("Pending() = %+v, want every change", changes)
+	}
+	for i := range want {
+		if changes.Files[i] != want[i] {
+			t.Errorf("Files[%d] = %+v, want %+v", i, changes.Files[i], want[i])
+		}
+	}
+	if !strings.Contains(changes.Patch, "+three") || !strings.Contains(changes.Patch, "new file untracked.txt") {
+		t.Errorf("Patch = %q, want the diff and the new file", changes.Patch)
+	}
+
+	run("add", "untracked.txt")
+	changes, err = Pending(root)
+	if err != nil || !changes.Staged || len(changes.Files) != 1 || changes.Files[0].Path != "untracked.txt" {
+		t.Fatalf("Pending() = %+v, %v, want only the staged file", changes, err)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	if _, err := git(root, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// quotes and substitutions are taken literally
+	message := "Fix the parser\n\nIt's no longer confused by $(quotes) or `ticks`"
+	changes, err := Pending(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := changes.Commit(root, message)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if !strings.Contains(summary, "Fix the parser") {
+		t.Errorf("Commit() = %q, want the summary of the commit", summary)
+	}
+	logged, err := git(root, "log", "-1", "--format=%B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(logged) != message {
+		t.Errorf("commit message = %q, want %q", logged, message)
+	}
+	if changes, err := Pending(root); err != nil || !changes.Empty() {
+		t.Errorf("Pending() = %+v, %v, want every change committed", changes, err)
+	}
+}
+
+func TestIgnoredPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	if output, err := exec.Command("git", "-C", root, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("dist/\n*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ignored := IgnoredPaths(root, []string{"dist/", "src/", "debug.log", "main.go"})
+	if len(ignored) != 2 || !ignored["dist/"] || !ignored["debug.log"] {
+		t.Errorf("IgnoredPaths() = %v, want dist/ and debug.log", ignored)
+	}
+	if ignored := IgnoredPaths(root, []string{"main.go"}); len(ignored) != 0 {
+		t.Errorf("IgnoredPaths() = %v, want none", ignored)
+	}
+}
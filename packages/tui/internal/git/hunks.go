@@ -0,0 +1,140 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Hunk is a change to a file that can be staged or unstaged on its own, as
+// git add -p does
+type Hunk struct {
+	// Path is the file relative to the top of the working tree
+	Path string
+	// Patch is the hunk with the file header git apply needs, empty for an
+	// untracked file, which is staged whole
+	Patch string
+	// Header is the @@ line of the hunk
+	Header string
+	Staged bool
+}
+
+// Untracked reports whether the hunk is a file git doesn't track yet
+func (h Hunk) Untracked() bool {
+	return h.Patch == "" && !h.Staged
+}
+
+// Line returns the 0-based line of the file the hunk starts at
+func (h Hunk) Line() int {
+	_, after, ok := strings.Cut(h.Header, " +")
+	if !ok {
+		return 0
+	}
+	var start int
+	if _, err := fmt.Sscanf(after, "%d", &start); err != nil {
+		return 0
+	}
+	return max(start-1, 0)
+}
+
+// toplevel returns the top of the working tree and path relative to it
+func toplevel(root, path string) (string, string, error) {
+	top, err := git(root, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", err
+	}
+	top = filepath.Clean(strings.TrimSpace(top))
+	rel, err := filepath.Rel(top, filepath.Join(root, path))
+	if err != nil {
+		return "", "", err
+	}
+	return top, filepath.ToSlash(rel), nil
+}
+
+// FileHunks returns the unstaged then the staged hunks of the file at path,
+// relative to root
+func FileHunks(root, path string) ([]Hunk, error) {
+	top, rel, err := toplevel(root, path)
+	if err != nil {
+		return nil, err
+	}
+	unstaged, err := git(top, "diff", "--no-color", "--no-ext-diff", "--", rel)
+	if err != nil {
+		return nil, err
+	}
+	staged, err := git(top, "diff", "--cached", "--no-color", "--no-ext-diff", "--", rel)
+	if err != nil {
+		return nil, err
+	}
+	hunks := splitHunks(rel, unstaged, false)
+	if len(hunks) == 0 {
+		untracked, err := git(top, "ls-files", "--others", "--exclude-standard", "--", rel)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(untracked) != "" {
+			hunks = append(hunks, Hunk{Path: rel})
+		}
+	}
+	return append(hunks, splitHunks(rel, staged, true)...), nil
+}
+
+// splitHunks cuts the diff of one file into its hunks, each with a copy of
+// the file header
+func splitHunks(path, diff string, staged bool) []Hunk {
+	header, rest, ok := strings.Cut(diff, "\n@@")
+	if !ok {
+		return nil
+	}
+	header += "\n"
+	var hunks []Hunk
+	for _, chunk := range strings.Split("@@"+rest, "\n@@") {
+		if !strings.HasPrefix(chunk, "@@") {
+			chunk = "@@" + chunk
+		}
+		chunk = strings.TrimSuffix(chunk, "\n") + "\n"
+		first, _, _ := strings.Cut(chunk, "\n")
+		hunks = append(hunks, Hunk{
+			Path:   path,
+			Patch:  header + chunk,
+			Header: first,
+			Staged: staged,
+		})
+	}
+	return hunks
+}
+
+// Toggle stages an unstaged hunk or unstages a staged one. The index is
+// changed alone, the working tree is left as it is.
+func Toggle(root string, hunk Hunk) error {
+	top, err := git(root, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	top = strings.TrimSpace(top)
+	if hunk.Untracked() {
+		_, err := git(top, "add", "--", hunk.Path)
+		return err
+	}
+	args := []string{"apply", "--cached", "--whitespace=nowarn"}
+	if hunk.Staged {
+		args = append(args, "--reverse")
+	}
+	cmd := exec.Command("git", append(args, "-")...)
+	cmd.Dir = top
+	cmd.Stdin = strings.NewReader(hunk.Patch)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git apply: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Stage adds the whole file at path, relative to root, to the index
+func Stage(root, path string) error {
+	_, err := git(root, "add", "--all", "--", path)
+	return err
+}
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileHunks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+		return string(output)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lines []string
+	for i := range 20 {
+		lines = append(lines, "line "+string(rune('a'+i)))
+	}
+	run("init", "-q")
+	write("src/file.txt", strings.Join(lines, "\n")+"\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	// two changes far enough apart to be separate hunks
+	lines[1] = "changed b"
+	lines[18] = "changed s"
+	write("src/file.txt", strings.Join(lines, "\n")+"\n")
+
+	// paths are relative to the directory kuuzuki runs in
+	dir := filepath.Join(root, "src")
+	hunks, err := FileHunks(dir, "file.txt")
+	if err != nil || len(hunks) != 2 {
+		t.Fatalf("FileHunks() = %+v, %v, want two hunks", hunks, err)
+	}
+	if hunks[0].Staged || hunks[0].Path != "src/file.txt" || !strings.HasPrefix(hunks[0].Header, "@@ -1,") {
+		t.Errorf("hunks[0] = %+v, want the first unstaged hunk", hunks[0])
+	}
+
+	if err := Toggle(dir, hunks[1]); err != nil {
+		t.Fatalf("Toggle() error = %v", err)
+	}
+	if staged := run("diff", "--cached"); !strings.Contains(staged, "+changed s") || strings.Contains(staged, "+changed b") {
+		t.Fatalf("staged diff = %q, want only the second hunk", staged)
+	}
+	hunks, err = FileHunks(dir, "file.txt")
+	if err != nil || len(hunks) != 2 || hunks[0].Staged || !hunks[1].Staged {
+		t.Fatalf("FileHunks() = %+v, %v, want an unstaged and a staged hunk", hunks, err)
+	}
+
+	if err := Toggle(dir, hunks[1]); err != nil {
+		t.Fatalf("Toggle() error = %v", err)
+	}
+	if staged := run("diff", "--cached"); staged != "" {
+		t.Fatalf("staged diff = %q, want the hunk unstaged", staged)
+	}
+
+	write("src/new.txt", "new\n")
+	hunks, err = FileHunks(dir, "new.txt")
+	if err != nil || len(hunks) != 1 || !hunks[0].Untracked() {
+		t.Fatalf("FileHunks() = %+v, %v, want the untracked file", hunks, err)
+	}
+	if err := Toggle(dir, hunks[0]); err != nil {
+		t.Fatalf("Toggle() error = %v", err)
+	}
+	hunks, err = FileHunks(dir, "new.txt")
+	if err != nil || len(hunks) != 1 || !hunks[0].Staged {
+		t.Fatalf("FileHunks() = %+v, %v, want the new file staged", hunks, err)
+	}
+}
+
+func TestHunkLine(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"@@ -10,6 +12,8 @@ func main() {", 11},
+		{"@@ -1 +1 @@", 0},
+		{"@@ -0,0 +1,3 @@", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := (Hunk{Header: tt.header}).Line(); got != tt.want {
+			t.Errorf("Line() of %q = %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package git
+
+import "strings"
+
+// IsPatch reports whether the text looks like a unified diff: a git diff
+// header, or file headers followed by a hunk
+func IsPatch(text string) bool {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			return true
+		}
+		if !strings.HasPrefix(line, "--- ") || i+2 >= len(lines) {
+			continue
+		}
+		if strings.HasPrefix(lines[i+1], "+++ ") && strings.HasPrefix(lines[i+2], "@@ ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package git
+
+import "testing"
+
+func TestIsPatch(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"git diff", "diff --git a/main.go b/main.go\nindex 1..2 100644\n", true},
+		{"unified diff", "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n", true},
+		{"after prose", "this fixes it:\n--- main.go\n+++ main.go\n@@ -1 +1 @@\n", true},
+		{"crlf", "--- a/main.go\r\n+++ b/main.go\r\n@@ -1 +1 @@\r\n", true},
+		{"no hunk", "--- a/main.go\n+++ b/main.go\n", false},
+		{"markdown rule", "title\n---\n+++ not a diff\n", false},
+		{"text", "fix the @@ in main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPatch(tt.text); got != tt.want {
+				t.Errorf("IsPatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxCommits is the number of recent commits offered for attaching
+const maxCommits = 20
+
+// Ref is something whose changes can be attached to a prompt: the working
+// tree for the zero Ref, a branch or a commit
+type Ref struct {
+	// Name is the name of the branch or the short hash of the commit
+	Name string
+	// Subject is the subject of the commit, or of the last commit of the
+	// branch
+	Subject string
+	Branch  bool
+}
+
+// Label names the changes of the ref, for attachments
+func (r Ref) Label() string {
+	switch {
+	case r.Name == "":
+		return "diff"
+	case r.Branch:
+		return "diff-" + strings.ReplaceAll(r.Name, "/", "-")
+	default:
+		return "commit-" + r.Name
+	}
+}
+
+// Patch returns the changes of the ref: those of the working tree, those
+// of HEAD since it branched off a branch, or those of a commit along with
+// its message
+func (r Ref) Patch(root string) (string, error) {
+	switch {
+	case r.Name == "":
+		changes, err := workingTree(root)
+		return changes.Patch, err
+	case r.Branch:
+		patch, err := git(root, "diff", r.Name+"...HEAD")
+		return cut(patch), err
+	default:
+		patch, err := git(root, "show", r.Name)
+		return cut(patch), err
+	}
+}
+
+// Branches returns the local branches, the most recently committed to first
+func Branches(root string) ([]Ref, error) {
+	output, err := git(root, "for-each-ref", "--sort=-committerdate",
+		"--format=%(refname:short)%09%(subject)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	return parseRefs(output, true), nil
+}
+
+// Commits returns the last commits of HEAD, the most recent first
+func Commits(root string) ([]Ref, error) {
+	// a repository without commits has no log
+	if _, err := git(root, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil, nil
+	}
+	output, err := git(root, "log", "-n", strconv.Itoa(maxCommits), "--format=%h%x09%s")
+	if err != nil {
+		return nil, err
+	}
+	return parseRefs(output, false), nil
+}
+
+// parseRefs reads lines of a name and a subject split by a tab
+func parseRefs(output string, branch bool) []Ref {
+	var refs []Ref
+	for line := range strings.SplitSeq(output, "\n") {
+		name, subject, _ := strings.Cut(line, "\t")
+		if name == "" {
+			continue
+		}
+		refs = append(refs, Ref{Name: name, Subject: subject, Branch: branch})
+	}
+	return refs
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRefs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	if commits, err := Commits(root); err != nil || len(commits) != 0 {
+		t.Fatalf("Commits() = %v, %v, want none before the first commit", commits, err)
+	}
+	write("app.txt", "one\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	run("checkout", "-q", "-b", "feature/login")
+	write("app.txt", "one\ntwo\n")
+	run("commit", "-q", "-am", "add two")
+	write("app.txt", "one\ntwo\nthree\n")
+
+	branches, err := Branches(root)
+	if err != nil || len(branches) != 2 {
+		t.Fatalf("Branches() = %v, %v, want main and feature/login", branches, err)
+	}
+	commits, err := Commits(root)
+	if err != nil || len(commits) != 2 || commits[0].Subject != "add two" {
+		t.Fatalf("Commits() = %v, %v, want the two commits, the last first", commits, err)
+	}
+
+	tests := []struct {
+		ref   Ref
+		label string
+		want  string
+	}{
+		{Ref{}, "diff", "+three"},
+		{Ref{Name: "main", Branch: true}, "diff-main", "+two"},
+		{commits[0], "commit-" + commits[0].Name, "add two"},
+	}
+	for _, tt := range tests {
+		if got := tt.ref.Label(); got != tt.label {
+			t.Errorf("Label() = %q, want %q", got, tt.label)
+		}
+		patch, err := tt.ref.Patch(root)
+		if err != nil || !strings.Contains(patch, tt.want) {
+			t.Errorf("Patch() of %q = %q, %v, want %q in it", tt.label, patch, err, tt.want)
+		}
+	}
+	if patch, _ := (Ref{Name: "main", Branch: true}).Patch(root); strings.Contains(patch, "+three") {
+		t.Errorf("Patch() of a branch = %q, want only the commits since it", patch)
+	}
+	if got := (Ref{Name: "feature/login", Branch: true}).Label(); got != "diff-feature-login" {
+		t.Errorf("Label() = %q, want diff-feature-login", got)
+	}
+}
@@ -0,0 +1,127 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sst/opencode/internal/snapshot"
+)
+
+// SandboxPrefix starts the names of sandbox branches
+const SandboxPrefix = "kuuzuki/sandbox/"
+
+// Sandbox is a branch the agent's edits are made on, away from the branch
+// the user was on, until it is merged back or discarded
+type Sandbox struct {
+	Branch string `toml:"branch"`
+	// Base is the branch the sandbox was started from
+	Base string `toml:"base"`
+	// Snapshot records the working tree when the sandbox started, discarding
+	// puts it back
+	Snapshot    string `toml:"snapshot"`
+	SnapshotRef string `toml:"snapshot_ref"`
+}
+
+// StartSandbox switches the working tree at root to a new sandbox branch
+// named after name. Uncommitted changes come along.
+func StartSandbox(root, name string) (Sandbox, error) {
+	base, err := git(root, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return Sandbox{}, errors.New("a sandbox starts from a branch, HEAD is detached")
+	}
+	base = strings.TrimSpace(base)
+	saved, err := snapshot.Create(root, "before sandbox "+name)
+	if err != nil {
+		return Sandbox{}, err
+	}
+	sandbox := Sandbox{
+		Branch:      SandboxPrefix + name,
+		Base:        base,
+		Snapshot:    saved.Commit,
+		SnapshotRef: saved.Ref,
+	}
+	if _, err := git(root, "switch", "--quiet", "--create", sandbox.Branch); err != nil {
+		snapshot.Delete(root, saved)
+		return Sandbox{}, err
+	}
+	return sandbox, nil
+}
+
+// Exists reports whether the sandbox branch is still there, it may have
+// been deleted outside kuuzuki
+func (s Sandbox) Exists(root string) bool {
+	_, err := git(root, "rev-parse", "--verify", "--quiet", "refs/heads/"+s.Branch)
+	return err == nil
+}
+
+// Merge switches back to the base branch with the sandbox's commits and
+// uncommitted changes, then deletes the sandbox branch
+func (s Sandbox) Merge(root string) error {
+	if _, err := git(root, "merge-base", "--is-ancestor", s.Base, s.Branch); err == nil {
+		// the base hasn't moved: it takes the sandbox's commits without the
+		// working tree being touched
+		if _, err := git(root, "branch", "--force", s.Base, s.Branch); err != nil {
+			return err
+		}
+		if _, err := git(root, "switch", "--quiet", s.Base); err != nil {
+			return err
+		}
+	} else {
+		if _, err := git(root, "switch", "--quiet", s.Base); err != nil {
+			return err
+		}
+		if _, err := git(root, "merge", "--no-edit", s.Branch); err != nil {
+			return err
+		}
+	}
+	if _, err := git(root, "branch", "--delete", s.Branch); err != nil {
+		return err
+	}
+	return s.deleteSnapshot(root)
+}
+
+// Discard switches back to the base branch with the working tree as it was
+// when the sandbox started, then deletes the sandbox branch. Files created
+// since are removed, ignored ones are left alone.
+func (s Sandbox) Discard(root string) error {
+	top, err := git(root, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	top = filepath.Clean(strings.TrimSpace(top))
+	if _, err := git(top, "switch", "--quiet", "--discard-changes", s.Base); err != nil {
+		return err
+	}
+	kept, err := git(top, "ls-tree", "-r", "--name-only", s.Snapshot)
+	if err != nil {
+		return err
+	}
+	existed := map[string]bool{}
+	for path := range strings.SplitSeq(kept, "\n") {
+		existed[path] = true
+	}
+	untracked, err := git(top, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return err
+	}
+	for path := range strings.SplitSeq(untracked, "\n") {
+		if path != "" && !existed[path] {
+			if err := os.Remove(filepath.Join(top, path)); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := git(top, "restore", "--source="+s.Snapshot, "--worktree", "--", "."); err != nil {
+		return err
+	}
+	if _, err := git(top, "branch", "--delete", "--force", s.Branch); err != nil {
+		return err
+	}
+	return s.deleteSnapshot(top)
+}
+
+func (s Sandbox) deleteSnapshot(root string) error {
+	return snapshot.Delete(root, snapshot.Snapshot{Ref: s.SnapshotRef})
+}
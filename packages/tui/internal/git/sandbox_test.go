@@ -0,0 +1,102 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSandbox(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			return ""
+		}
+		return string(content)
+	}
+
+	run("init", "-q", "-b", "main")
+	write("tracked.txt", "original")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	// the user's own uncommitted change survives a discarded sandbox
+	write("tracked.txt", "mine")
+	sandbox, err := StartSandbox(root, "one")
+	if err != nil {
+		t.Fatalf("StartSandbox() error = %v", err)
+	}
+	if branch := run("branch", "--show-current"); branch != SandboxPrefix+"one" || sandbox.Base != "main" {
+		t.Fatalf("branch = %s, base = %s, want the sandbox started from main", branch, sandbox.Base)
+	}
+	write("tracked.txt", "agent")
+	write("created.txt", "agent")
+	if err := sandbox.Discard(root); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if branch := run("branch", "--show-current"); branch != "main" {
+		t.Errorf("branch = %s, want main", branch)
+	}
+	if got := read("tracked.txt"); got != "mine" {
+		t.Errorf("tracked.txt = %q, want the change from before the sandbox", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "created.txt")); !os.IsNotExist(err) {
+		t.Errorf("created.txt was kept, want it removed")
+	}
+	if branches := run("branch", "--list", SandboxPrefix+"*"); branches != "" {
+		t.Errorf("branches = %q, want the sandbox deleted", branches)
+	}
+
+	// merging brings commits and uncommitted changes back
+	sandbox, err = StartSandbox(root, "two")
+	if err != nil {
+		t.Fatalf("StartSandbox() error = %v", err)
+	}
+	write("committed.txt", "agent")
+	run("add", "committed.txt")
+	run("commit", "-q", "-m", "agent commit")
+	write("tracked.txt", "agent")
+	if err := sandbox.Merge(root); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if branch := run("branch", "--show-current"); branch != "main" {
+		t.Errorf("branch = %s, want main", branch)
+	}
+	if subject := run("log", "-1", "--format=%s"); subject != "agent commit" {
+		t.Errorf("last commit = %q, want the sandbox's commit", subject)
+	}
+	if got := read("tracked.txt"); got != "agent" {
+		t.Errorf("tracked.txt = %q, want the sandbox's change", got)
+	}
+	if branches := run("branch", "--list", SandboxPrefix+"*"); branches != "" {
+		t.Errorf("branches = %q, want the sandbox deleted", branches)
+	}
+}
@@ -0,0 +1,150 @@
+package headless
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// batchSeparator is the line between the prompts of a batch file
+const batchSeparator = "---"
+
+// Transcript records a batch run
+type Transcript struct {
+	SessionID  string    `json:"sessionID"`
+	ProviderID string    `json:"providerID"`
+	ModelID    string    `json:"modelID"`
+	Approval   string    `json:"approval"`
+	Started    time.Time `json:"started"`
+	Finished   time.Time `json:"finished"`
+	Entries    []Entry   `json:"entries"`
+}
+
+// Entry is a prompt of a batch and its answer
+type Entry struct {
+	Prompt       string       `json:"prompt"`
+	MessageID    string       `json:"messageID,omitempty"`
+	Answer       string       `json:"answer"`
+	Tools        []ToolCall   `json:"tools,omitempty"`
+	Permissions  []Permission `json:"permissions,omitempty"`
+	Cost         float64      `json:"cost"`
+	InputTokens  float64      `json:"inputTokens"`
+	OutputTokens float64      `json:"outputTokens"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// ToolCall is a tool the answer called
+type ToolCall struct {
+	Tool   string `json:"tool"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Permission is a permission request answered by the approval policy
+type Permission struct {
+	Tool     string `json:"tool"`
+	Title    string `json:"title"`
+	Response string `json:"response"`
+}
+
+// addParts fills the answer and the tool calls from the parts of the answer
+func (e *Entry) addParts(parts []opencode.Part) {
+	var answer []string
+	for _, part := range parts {
+		switch part := part.AsUnion().(type) {
+		case opencode.TextPart:
+			if !part.Synthetic {
+				answer = append(answer, part.Text)
+			}
+		case opencode.ToolPart:
+			e.Tools = append(e.Tools, ToolCall{
+				Tool:   part.Tool,
+				Title:  part.State.Title,
+				Status: string(part.State.Status),
+				Error:  part.State.Error,
+			})
+		}
+	}
+	e.Answer = strings.Join(answer, "\n")
+}
+
+// ReadBatch splits a batch file into its prompts, separated by lines of
+// three dashes, skipping empty ones
+func ReadBatch(r io.Reader) ([]string, error) {
+	var prompts []string
+	var block []string
+	flush := func() {
+		if prompt := strings.TrimSpace(strings.Join(block, "\n")); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+		block = nil
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == batchSeparator {
+			flush()
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return prompts, nil
+}
+
+// RunBatch sends the prompts one after the other in one session, streaming
+// the answers like Run and writing the transcript once done. It stops at the
+// first prompt that fails, returning its exit code.
+func RunBatch(ctx context.Context, opts Options, prompts []string, transcript io.Writer) int {
+	if len(prompts) == 0 {
+		fmt.Fprintln(opts.Stderr, "error: the batch has no prompts")
+		return ExitUsage
+	}
+	r, code := start(ctx, opts)
+	if r == nil {
+		return code
+	}
+	t := Transcript{
+		SessionID:  r.sessionID,
+		ProviderID: r.providerID,
+		ModelID:    r.modelID,
+		Approval:   string(r.approval),
+		Started:    time.Now(),
+	}
+	for i, prompt := range prompts {
+		if r.out.format == FormatText {
+			r.out.note("[%d/%d] %s", i+1, len(prompts), firstLine(prompt))
+		}
+		var entry Entry
+		entry, code = r.send(ctx, prompt)
+		t.Entries = append(t.Entries, entry)
+		if code != ExitOK {
+			break
+		}
+	}
+	t.Finished = time.Now()
+
+	encoder := json.NewEncoder(transcript)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(t); err != nil {
+		fmt.Fprintln(opts.Stderr, "error: failed to write the transcript: "+err.Error())
+		return ExitFailed
+	}
+	return code
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
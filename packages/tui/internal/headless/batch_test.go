@@ -0,0 +1,53 @@
+package headless
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestReadBatch(t *testing.T) {
+	file := `Summarize the README
+
+---
+Fix the failing test
+
+Keep the public API.
+ ---
+
+---
+Write a changelog entry
+`
+	prompts, err := ReadBatch(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Summarize the README",
+		"Fix the failing test\n\nKeep the public API.",
+		"Write a changelog entry",
+	}
+	if !reflect.DeepEqual(prompts, want) {
+		t.Errorf("got %q, want %q", prompts, want)
+	}
+}
+
+func TestEntryParts(t *testing.T) {
+	parts := []opencode.Part{
+		part(t, `{"id":"p1","sessionID":"ses","messageID":"m","type":"text","text":"Looking"}`),
+		part(t, `{"id":"p2","sessionID":"ses","messageID":"m","type":"tool","callID":"c","tool":"bash","state":{"status":"error","error":"exit 2"}}`),
+		part(t, `{"id":"p3","sessionID":"ses","messageID":"m","type":"text","text":"context","synthetic":true}`),
+		part(t, `{"id":"p4","sessionID":"ses","messageID":"m","type":"text","text":"Done"}`),
+	}
+	var entry Entry
+	entry.addParts(parts)
+	if entry.Answer != "Looking\nDone" {
+		t.Errorf("expected the text without synthetic parts, got %q", entry.Answer)
+	}
+	want := []ToolCall{{Tool: "bash", Status: "error", Error: "exit 2"}}
+	if !reflect.DeepEqual(entry.Tools, want) {
+		t.Errorf("got tools %+v, want %+v", entry.Tools, want)
+	}
+}
@@ -0,0 +1,385 @@
+// Package headless runs a prompt without the TUI, streaming the answer to
+// stdout so kuuzuki can be used from scripts and CI.
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/id"
+)
+
+// Format is how the answer is written to stdout
+type Format string
+
+const (
+	// FormatText writes the text of the answer as it streams in
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per line for every text delta, tool
+	// call, permission request and the final result
+	FormatJSON Format = "json"
+)
+
+// Exit codes of a headless run
+const (
+	ExitOK = 0
+	// ExitFailed is returned when the request could not be sent or the
+	// answer ended in an error
+	ExitFailed = 1
+	// ExitUsage is returned for invalid options, e.g. a missing prompt
+	ExitUsage = 2
+	// ExitInterrupted is returned when the run was cancelled
+	ExitInterrupted = 130
+)
+
+// Options configure a headless run
+type Options struct {
+	Client *opencode.Client
+	Prompt string
+	// Model is provider/model, empty for the configured default
+	Model string
+	// Agent is the agent to prompt, empty for the server default
+	Agent string
+	// SessionID continues a session, empty starts a new one
+	SessionID string
+	Format    Format
+	// Approval answers the permission requests of the run, reject unless set
+	Approval opencode.PermissionResponse
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// Record is a line of the JSON output
+type Record struct {
+	Type string `json:"type"`
+	// SessionID and MessageID identify the answer in result records
+	SessionID string `json:"sessionID,omitempty"`
+	MessageID string `json:"messageID,omitempty"`
+	// Text is the new text of a text record
+	Text string `json:"text,omitempty"`
+	// Tool, Status and Title describe a tool record, and the tool of a
+	// permission record
+	Tool   string `json:"tool,omitempty"`
+	Status string `json:"status,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// Response is how a permission record was answered
+	Response string `json:"response,omitempty"`
+	// Cost and tokens of the answer in result records
+	Cost         float64 `json:"cost,omitempty"`
+	InputTokens  float64 `json:"inputTokens,omitempty"`
+	OutputTokens float64 `json:"outputTokens,omitempty"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// output writes the events of one session in the chosen format
+type output struct {
+	format    Format
+	stdout    io.Writer
+	stderr    io.Writer
+	sessionID string
+	// prompt is the ID of the user message being answered, whose parts are
+	// not echoed
+	prompt string
+	// printed is the length of the text already written per part
+	printed map[string]int
+	// tools is the last status written per tool part
+	tools map[string]opencode.ToolPartStateStatus
+	// newline is set when the text written so far does not end a line
+	newline bool
+}
+
+func newOutput(opts Options, sessionID string) *output {
+	return &output{
+		format:    opts.Format,
+		stdout:    opts.Stdout,
+		stderr:    opts.Stderr,
+		sessionID: sessionID,
+		printed:   map[string]int{},
+		tools:     map[string]opencode.ToolPartStateStatus{},
+	}
+}
+
+func (o *output) record(r Record) {
+	data, _ := json.Marshal(r)
+	fmt.Fprintln(o.stdout, string(data))
+}
+
+// note writes a line about the run apart from the answer
+func (o *output) note(format string, args ...any) {
+	if o.newline {
+		fmt.Fprintln(o.stdout)
+		o.newline = false
+	}
+	fmt.Fprintf(o.stderr, format+"\n", args...)
+}
+
+// part writes what is new in a part of the answer
+func (o *output) part(part opencode.Part) {
+	if part.SessionID != o.sessionID || part.MessageID == o.prompt {
+		return
+	}
+	switch part := part.AsUnion().(type) {
+	case opencode.TextPart:
+		if part.Synthetic || len(part.Text) <= o.printed[part.ID] {
+			return
+		}
+		text := part.Text[o.printed[part.ID]:]
+		o.printed[part.ID] = len(part.Text)
+		if o.format == FormatJSON {
+			o.record(Record{Type: "text", MessageID: part.MessageID, Text: text})
+			return
+		}
+		fmt.Fprint(o.stdout, text)
+		o.newline = !strings.HasSuffix(text, "\n")
+	case opencode.ToolPart:
+		status := part.State.Status
+		if status == opencode.ToolPartStateStatusPending || o.tools[part.ID] == status {
+			return
+		}
+		o.tools[part.ID] = status
+		if o.format == FormatJSON {
+			o.record(Record{Type: "tool", Tool: part.Tool, Status: string(status), Title: part.State.Title})
+			return
+		}
+		switch status {
+		case opencode.ToolPartStateStatusRunning:
+			o.note("→ %s %s", part.Tool, part.State.Title)
+		case opencode.ToolPartStateStatusError:
+			o.note("✗ %s: %s", part.Tool, part.State.Error)
+		}
+	}
+}
+
+// permission reports a permission request answered by the approval policy,
+// nobody is there to be asked
+func (o *output) permission(permission opencode.Permission, response opencode.PermissionResponse) {
+	if o.format == FormatJSON {
+		o.record(Record{Type: "permission", Tool: permission.Type, Title: permission.Title, Response: string(response)})
+		return
+	}
+	if response == opencode.PermissionResponseReject {
+		o.note("✗ rejected %s: %s", permission.Type, permission.Title)
+		return
+	}
+	o.note("✓ allowed %s: %s", permission.Type, permission.Title)
+}
+
+// result writes the end of the answer
+func (o *output) result(message *opencode.AssistantMessage, failure string) {
+	if o.format == FormatJSON {
+		r := Record{Type: "result", SessionID: o.sessionID, Error: failure}
+		if message != nil {
+			r.MessageID = message.ID
+			r.Cost = message.Cost
+			r.InputTokens = message.Tokens.Input
+			r.OutputTokens = message.Tokens.Output
+		}
+		o.record(r)
+		return
+	}
+	if o.newline {
+		fmt.Fprintln(o.stdout)
+		o.newline = false
+	}
+	if failure != "" {
+		fmt.Fprintln(o.stderr, "error: "+failure)
+	}
+}
+
+// messageError describes the error an answer ended in, "" if it has none
+func messageError(message opencode.AssistantMessage) string {
+	switch err := message.Error.AsUnion().(type) {
+	case opencode.AssistantMessageErrorMessageOutputLengthError:
+		return "Message output length exceeded"
+	case opencode.ProviderAuthError:
+		return err.Data.Message
+	case opencode.MessageAbortedError:
+		return "Request was aborted"
+	case opencode.UnknownError:
+		return err.Data.Message
+	}
+	return ""
+}
+
+// resolveModel picks the provider and model of the run, the given
+// provider/model, the configured model or the default of the first provider
+func resolveModel(ctx context.Context, client *opencode.Client, model string) (string, string, error) {
+	if model == "" {
+		if config, err := client.Config.Get(ctx); err == nil {
+			model = config.Model
+		}
+	}
+	if providerID, modelID, ok := strings.Cut(model, "/"); ok {
+		return providerID, modelID, nil
+	}
+	if model != "" {
+		return "", "", fmt.Errorf("model %q is not in the provider/model format", model)
+	}
+	providers, err := client.App.Providers(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	for _, provider := range providers.Providers {
+		if modelID, ok := providers.Default[provider.ID]; ok {
+			return provider.ID, modelID, nil
+		}
+	}
+	return "", "", errors.New("no provider is configured, run `kuuzuki auth login`")
+}
+
+// runner sends prompts to one session
+type runner struct {
+	client     *opencode.Client
+	agent      string
+	approval   opencode.PermissionResponse
+	providerID string
+	modelID    string
+	sessionID  string
+	out        *output
+}
+
+// start validates the options, then picks the model and the session,
+// returning a non-zero exit code on failure
+func start(ctx context.Context, opts Options) (*runner, int) {
+	if opts.Format == "" {
+		opts.Format = FormatText
+	}
+	if opts.Format != FormatText && opts.Format != FormatJSON {
+		fmt.Fprintf(opts.Stderr, "error: unknown output format %q, use text or json\n", opts.Format)
+		return nil, ExitUsage
+	}
+	if opts.Approval == "" {
+		opts.Approval = opencode.PermissionResponseReject
+	}
+	if !opts.Approval.IsKnown() {
+		fmt.Fprintf(opts.Stderr, "error: unknown approval %q, use reject, once or always\n", opts.Approval)
+		return nil, ExitUsage
+	}
+	client := opts.Client
+
+	providerID, modelID, err := resolveModel(ctx, client, opts.Model)
+	if err != nil {
+		fmt.Fprintln(opts.Stderr, "error: "+err.Error())
+		return nil, ExitUsage
+	}
+	sessionID := opts.SessionID
+	if sessionID == "" {
+		session, err := client.Session.New(ctx)
+		if err != nil {
+			fmt.Fprintln(opts.Stderr, "error: failed to create a session: "+err.Error())
+			return nil, ExitFailed
+		}
+		sessionID = session.ID
+	}
+	return &runner{
+		client:     client,
+		agent:      opts.Agent,
+		approval:   opts.Approval,
+		providerID: providerID,
+		modelID:    modelID,
+		sessionID:  sessionID,
+		out:        newOutput(opts, sessionID),
+	}, ExitOK
+}
+
+// send sends one prompt and streams its answer, returning the answer as a
+// transcript entry and the exit code
+func (r *runner) send(ctx context.Context, prompt string) (Entry, int) {
+	client := r.client
+	messageID := id.Ascending(id.Message)
+	r.out.prompt = messageID
+	entry := Entry{Prompt: prompt}
+
+	// the answer streams in as events while the chat request is pending
+	streamCtx, stopStream := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		stream := client.Event.ListStreaming(streamCtx)
+		for stream.Next() {
+			switch evt := stream.Current().AsUnion().(type) {
+			case opencode.EventListResponseEventMessagePartUpdated:
+				r.out.part(evt.Properties.Part)
+			case opencode.EventListResponseEventPermissionUpdated:
+				if evt.Properties.SessionID == r.sessionID {
+					r.out.permission(evt.Properties, r.approval)
+					entry.Permissions = append(entry.Permissions, Permission{
+						Tool:     evt.Properties.Type,
+						Title:    evt.Properties.Title,
+						Response: string(r.approval),
+					})
+					go client.Session.RespondPermission(streamCtx, r.sessionID, evt.Properties.ID, r.approval, nil)
+				}
+			}
+		}
+	}()
+
+	params := opencode.SessionChatParams{
+		ProviderID: opencode.F(r.providerID),
+		ModelID:    opencode.F(r.modelID),
+		MessageID:  opencode.F(messageID),
+		Parts: opencode.F([]opencode.SessionChatParamsPartUnion{
+			opencode.TextPartInputParam{
+				Type: opencode.F(opencode.TextPartInputTypeText),
+				Text: opencode.F(prompt),
+			},
+		}),
+	}
+	if r.agent != "" {
+		params.Agent = opencode.F(r.agent)
+	}
+	message, err := client.Session.Chat(ctx, r.sessionID, params)
+	stopStream()
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		client.Session.Abort(context.Background(), r.sessionID)
+		r.out.result(nil, "interrupted")
+		entry.Error = "interrupted"
+		return entry, ExitInterrupted
+	}
+	if err != nil {
+		r.out.result(nil, err.Error())
+		entry.Error = err.Error()
+		return entry, ExitFailed
+	}
+
+	// text that arrived after the stream closed is taken from the answer
+	if full, err := client.Session.Message(ctx, r.sessionID, message.ID); err == nil {
+		for _, part := range full.Parts {
+			r.out.part(part)
+		}
+		entry.addParts(full.Parts)
+	}
+	entry.MessageID = message.ID
+	entry.Cost = message.Cost
+	entry.InputTokens = message.Tokens.Input
+	entry.OutputTokens = message.Tokens.Output
+	entry.Error = messageError(*message)
+	r.out.result(message, entry.Error)
+	if entry.Error != "" {
+		return entry, ExitFailed
+	}
+	return entry, ExitOK
+}
+
+// Run sends the prompt and streams the answer, returning the exit code
+func Run(ctx context.Context, opts Options) int {
+	if strings.TrimSpace(opts.Prompt) == "" {
+		fmt.Fprintln(opts.Stderr, "error: a prompt is needed, pass --prompt or pipe it on stdin")
+		return ExitUsage
+	}
+	r, code := start(ctx, opts)
+	if r == nil {
+		return code
+	}
+	_, code = r.send(ctx, opts.Prompt)
+	return code
+}
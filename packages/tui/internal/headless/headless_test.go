@@ -0,0 +1,90 @@
+package headless
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func part(t *testing.T, raw string) opencode.Part {
+	t.Helper()
+	var p opencode.Part
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func textPart(t *testing.T, message, text string) opencode.Part {
+	return part(t, `{"id":"prt_1","sessionID":"ses","messageID":"`+message+`","type":"text","text":`+mustJSON(text)+`}`)
+}
+
+func mustJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func TestTextOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	out := newOutput(Options{Format: FormatText, Stdout: &stdout, Stderr: &stderr}, "ses")
+	out.prompt = "msg_prompt"
+
+	out.part(textPart(t, "msg_prompt", "the prompt"))
+	out.part(textPart(t, "msg_answer", "Hello"))
+	out.part(textPart(t, "msg_answer", "Hello, world"))
+	// repeated updates add nothing
+	out.part(textPart(t, "msg_answer", "Hello, world"))
+	out.part(part(t, `{"id":"prt_2","sessionID":"ses","messageID":"msg_answer","type":"tool","callID":"c","tool":"bash","state":{"status":"running","title":"ls"}}`))
+	out.result(nil, "")
+
+	if stdout.String() != "Hello, world\n" {
+		t.Errorf("unexpected stdout %q", stdout.String())
+	}
+	if stderr.String() != "→ bash ls\n" {
+		t.Errorf("unexpected stderr %q", stderr.String())
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	out := newOutput(Options{Format: FormatJSON, Stdout: &stdout, Stderr: &stderr}, "ses")
+	out.prompt = "msg_prompt"
+
+	out.part(textPart(t, "msg_answer", "Hi"))
+	out.part(textPart(t, "msg_answer", "Hi there"))
+	// parts of other sessions are skipped
+	other := part(t, `{"id":"prt_3","sessionID":"other","messageID":"m","type":"text","text":"nope"}`)
+	out.part(other)
+	out.result(&opencode.AssistantMessage{ID: "msg_answer", Cost: 0.5}, "")
+
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid json line %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d: %s", len(records), stdout.String())
+	}
+	if records[0].Text != "Hi" || records[1].Text != " there" {
+		t.Errorf("expected text deltas, got %q and %q", records[0].Text, records[1].Text)
+	}
+	if records[2].Type != "result" || records[2].MessageID != "msg_answer" || records[2].Cost != 0.5 {
+		t.Errorf("unexpected result %+v", records[2])
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected nothing on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunNeedsPrompt(t *testing.T) {
+	var stderr bytes.Buffer
+	if code := Run(t.Context(), Options{Prompt: "  ", Stderr: &stderr}); code != ExitUsage {
+		t.Errorf("expected the usage exit code, got %d", code)
+	}
+}
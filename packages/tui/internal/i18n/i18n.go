@@ -0,0 +1,113 @@
+// Package i18n translates the strings of the TUI. Catalogs map the English
+// strings to their translation, a string missing from the catalog of the
+// locale is shown in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// English is the locale of the strings in the code, it has no catalog
+const English = "en"
+
+var (
+	mu      sync.RWMutex
+	locale  = English
+	catalog map[string]string
+)
+
+// Locales lists the locales with a catalog, and English
+func Locales() []string {
+	locales := []string{English}
+	entries, _ := localesFS.ReadDir("locales")
+	for _, entry := range entries {
+		locales = append(locales, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// SetLocale selects the catalog of the locale, like de or de-AT, falling
+// back to the one of its language and then to English. It returns the
+// locale selected.
+func SetLocale(name string) (string, error) {
+	name = Normalize(name)
+	candidates := []string{name}
+	if language, _, ok := strings.Cut(name, "-"); ok {
+		candidates = append(candidates, language)
+	}
+	for _, candidate := range candidates {
+		if candidate == English {
+			break
+		}
+		data, err := localesFS.ReadFile(path.Join("locales", candidate+".json"))
+		if err != nil {
+			continue
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return English, fmt.Errorf("failed to parse the catalog of %s: %w", candidate, err)
+		}
+		mu.Lock()
+		locale, catalog = candidate, messages
+		mu.Unlock()
+		return candidate, nil
+	}
+	mu.Lock()
+	locale, catalog = English, nil
+	mu.Unlock()
+	return English, nil
+}
+
+// Locale returns the selected locale
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return locale
+}
+
+// T translates the text, then formats it with the args like fmt.Sprintf
+// when there are any
+func T(text string, args ...any) string {
+	mu.RLock()
+	if translated, ok := catalog[text]; ok && translated != "" {
+		text = translated
+	}
+	mu.RUnlock()
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Detect returns the locale set in the environment by KUUZUKI_LOCALE, or
+// else by LC_ALL, LC_MESSAGES or LANG, English when none is
+func Detect(getenv func(string) string) string {
+	for _, name := range []string{"KUUZUKI_LOCALE", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(name); value != "" {
+			return Normalize(value)
+		}
+	}
+	return English
+}
+
+// Normalize turns a locale like de_AT.UTF-8@euro into de-at, and the C and
+// POSIX locales into English
+func Normalize(name string) string {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"))
+	if name == "" || name == "c" || name == "posix" {
+		return English
+	}
+	return name
+}
@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetLocale(t *testing.T) {
+	defer SetLocale(English)
+
+	for name, want := range map[string]string{
+		"de":          "de",
+		"de_AT.UTF-8": "de",
+		"fr":          English,
+		"C":           English,
+		"":            English,
+	} {
+		if got, err := SetLocale(name); err != nil || got != want {
+			t.Errorf("SetLocale(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	SetLocale("de")
+	if got := T("Approve"); got != "Erlauben" {
+		t.Errorf("got %q", got)
+	}
+	if got := T("+%d waiting", 2); got != "+2 wartend" {
+		t.Errorf("got %q", got)
+	}
+	if got := T("not in the catalog"); got != "not in the catalog" {
+		t.Errorf("got %q", got)
+	}
+
+	SetLocale(English)
+	if got := T("+%d waiting", 2); got != "+2 waiting" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+	tests := []struct {
+		vars map[string]string
+		want string
+	}{
+		{map[string]string{}, English},
+		{map[string]string{"LANG": "de_DE.UTF-8"}, "de-de"},
+		{map[string]string{"LANG": "de_DE.UTF-8", "LC_ALL": "C"}, English},
+		{map[string]string{"LC_MESSAGES": "de_AT@euro", "LANG": "en_US.UTF-8"}, "de-at"},
+		{map[string]string{"KUUZUKI_LOCALE": "de", "LC_ALL": "en_US.UTF-8"}, "de"},
+	}
+	for _, tt := range tests {
+		if got := Detect(env(tt.vars)); got != tt.want {
+			t.Errorf("Detect(%v) = %q, want %q", tt.vars, got, tt.want)
+		}
+	}
+}
+
+// TestCatalogs checks every catalog keeps the format verbs of the strings
+// it translates
+func TestCatalogs(t *testing.T) {
+	for _, locale := range Locales() {
+		if locale == English {
+			continue
+		}
+		data, err := localesFS.ReadFile("locales/" + locale + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			t.Fatalf("%s: %v", locale, err)
+		}
+		for text, translated := range messages {
+			if !slices.Equal(verbs(text), verbs(translated)) {
+				t.Errorf("%s: %q translated to %q", locale, text, translated)
+			}
+		}
+	}
+}
+
+func verbs(text string) []string {
+	var found []string
+	for _, part := range strings.Split(text, "%")[1:] {
+		if part != "" {
+			found = append(found, part[:1])
+		}
+	}
+	return found
+}
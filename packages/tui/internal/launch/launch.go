@@ -0,0 +1,213 @@
+// Package launch finds or starts the kuuzuki server when the TUI runs on its
+// own, instead of being spawned by `kuuzuki tui` with the server in its
+// environment.
+package launch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	opencode "github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+const (
+	// probeTimeout bounds the check of a discovered server
+	probeTimeout = time.Second
+	// startTimeout bounds the wait for a started server to listen
+	startTimeout = 30 * time.Second
+	// stopTimeout is how long a started server gets to shut down before it
+	// is killed
+	stopTimeout = 5 * time.Second
+)
+
+// listening matches the line `kuuzuki serve` prints once it listens
+var listening = regexp.MustCompile(`listening on (https?://\S+)`)
+
+// Server is the kuuzuki server the TUI talks to
+type Server struct {
+	URL string
+	// cmd is the server process when it was started by Connect
+	cmd *exec.Cmd
+}
+
+// Started reports whether the server was started rather than discovered
+func (s *Server) Started() bool {
+	return s.cmd != nil
+}
+
+// Close stops the server if it was started by Connect, a discovered one
+// keeps running
+func (s *Server) Close() error {
+	if s.cmd == nil || s.cmd.Process == nil {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.cmd.Wait() }()
+	// interrupts let the server clean up, they are not supported on windows
+	if err := s.cmd.Process.Signal(os.Interrupt); err != nil {
+		return s.cmd.Process.Kill()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(stopTimeout):
+		return s.cmd.Process.Kill()
+	}
+}
+
+// Connect attaches to a running server of the directory, or starts one
+func Connect(ctx context.Context, cwd string) (*Server, error) {
+	if url := Discover(ctx, cwd); url != "" {
+		return &Server{URL: url}, nil
+	}
+	return Start(ctx, cwd)
+}
+
+// StateDir is where servers record how to reach them, the state directory
+// of the kuuzuki CLI
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "kuuzuki")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "kuuzuki")
+}
+
+// Discover returns the URL of a running server serving cwd, or "" when
+// there is none
+func Discover(ctx context.Context, cwd string) string {
+	dir := StateDir()
+	if dir == "" {
+		return ""
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "server-*.json"))
+	files = append(files, filepath.Join(dir, "server.json"))
+	seen := map[string]bool{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var info struct {
+			URL string `json:"url"`
+		}
+		if json.Unmarshal(data, &info) != nil || info.URL == "" || seen[info.URL] {
+			continue
+		}
+		seen[info.URL] = true
+		if serves(ctx, info.URL, cwd) {
+			return info.URL
+		}
+	}
+	return ""
+}
+
+// serves reports whether the server at url is up and serving cwd, servers
+// of other directories and stale records of stopped ones are skipped
+func serves(ctx context.Context, url, cwd string) bool {
+	client := opencode.NewClient(
+		option.WithBaseURL(url),
+		option.WithMaxRetries(0),
+		option.WithRequestTimeout(probeTimeout),
+	)
+	app, err := client.App.Get(ctx)
+	if err != nil || app == nil {
+		return false
+	}
+	return filepath.Clean(app.Path.Cwd) == filepath.Clean(cwd)
+}
+
+// Binary finds the kuuzuki CLI, KUUZUKI_BIN overrides the lookup on the PATH,
+// which skips the TUI binary itself
+func Binary() (string, error) {
+	if bin := os.Getenv("KUUZUKI_BIN"); bin != "" {
+		return bin, nil
+	}
+	bin, err := exec.LookPath("kuuzuki")
+	if err != nil {
+		return "", errors.New("kuuzuki not found on the PATH, set KUUZUKI_BIN to the kuuzuki CLI")
+	}
+	if self, err := os.Executable(); err == nil && sameFile(self, bin) {
+		return "", errors.New("kuuzuki on the PATH is this TUI, set KUUZUKI_BIN to the kuuzuki CLI")
+	}
+	return bin, nil
+}
+
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// Start runs `kuuzuki serve` in cwd on a free port and waits until it
+// listens
+func Start(ctx context.Context, cwd string) (*Server, error) {
+	bin, err := Binary()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(bin, "serve", "--port", "0")
+	cmd.Dir = cwd
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start the kuuzuki server: %w", err)
+	}
+	server := &Server{cmd: cmd}
+
+	found := make(chan string, 1)
+	go func() {
+		url := waitListening(stdout)
+		found <- url
+		// keep reading so the server never blocks on a full pipe
+		io.Copy(io.Discard, stdout)
+	}()
+	select {
+	case url := <-found:
+		if url == "" {
+			cmd.Wait()
+			return nil, errors.New("the kuuzuki server exited before listening, is a provider configured?")
+		}
+		server.URL = url
+		return server, nil
+	case <-time.After(startTimeout):
+		server.Close()
+		return nil, errors.New("timed out waiting for the kuuzuki server to listen")
+	case <-ctx.Done():
+		server.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// waitListening reads the server output until it prints its URL, returning
+// "" if the output ends first
+func waitListening(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if match := listening.FindStringSubmatch(scanner.Text()); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package launch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeServer answers /app as a server of cwd
+func fakeServer(t *testing.T, cwd string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"path": map[string]string{"cwd": cwd, "root": cwd},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeInfo(t *testing.T, dir, name, url string) {
+	t.Helper()
+	data := fmt.Sprintf(`{"url": %q, "pid": 1}`, url)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	dir := filepath.Join(state, "kuuzuki")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if url := Discover(context.Background(), "/work/a"); url != "" {
+		t.Errorf("expected no server without records, got %s", url)
+	}
+
+	other := fakeServer(t, "/work/b")
+	mine := fakeServer(t, "/work/a")
+	writeInfo(t, dir, "server-1.json", "http://127.0.0.1:1")
+	writeInfo(t, dir, "server-2.json", other.URL)
+	writeInfo(t, dir, "server.json", mine.URL)
+
+	if url := Discover(context.Background(), "/work/a"); url != mine.URL {
+		t.Errorf("expected the server of the directory, got %q", url)
+	}
+	if url := Discover(context.Background(), "/work/c"); url != "" {
+		t.Errorf("expected no server of another directory, got %s", url)
+	}
+}
+
+func TestWaitListening(t *testing.T) {
+	output := "starting\nkuuzuki server listening on http://127.0.0.1:40123\nmore\n"
+	if url := waitListening(strings.NewReader(output)); url != "http://127.0.0.1:40123" {
+		t.Errorf("unexpected url %q", url)
+	}
+	if url := waitListening(strings.NewReader("needs a provider\n")); url != "" {
+		t.Errorf("expected no url when the server exits, got %q", url)
+	}
+}
@@ -0,0 +1,156 @@
+package layout
+
+import (
+	"image"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// zone markers are CSI sequences the terminal never sees: views wrap
+// clickable parts in them with Mark, and Zones.Scan strips them from the
+// finished screen, noting where they were. Like any escape sequence they take
+// no width, so overlays and truncation keep them in place.
+const (
+	zoneStart = "\x1b[1;"
+	zoneEnd   = "\x1b[2;"
+)
+
+var (
+	zoneMu    sync.Mutex
+	zoneIDs   = map[string]int{}
+	zoneNames []string
+)
+
+func zoneNumber(id string) int {
+	zoneMu.Lock()
+	defer zoneMu.Unlock()
+	n, ok := zoneIDs[id]
+	if !ok {
+		n = len(zoneNames)
+		zoneIDs[id] = n
+		zoneNames = append(zoneNames, id)
+	}
+	return n
+}
+
+func zoneName(n int) (string, bool) {
+	zoneMu.Lock()
+	defer zoneMu.Unlock()
+	if n < 0 || n >= len(zoneNames) {
+		return "", false
+	}
+	return zoneNames[n], true
+}
+
+// Mark makes s a clickable zone named id
+func Mark(id, s string) string {
+	n := strconv.Itoa(zoneNumber(id))
+	return zoneStart + n + "z" + s + zoneEnd + n + "z"
+}
+
+// Zones are the clickable parts of the last screen
+type Zones struct {
+	mu    sync.Mutex
+	zones map[string]image.Rectangle
+}
+
+// NewZones returns an empty set of zones
+func NewZones() *Zones {
+	return &Zones{zones: map[string]image.Rectangle{}}
+}
+
+// Scan notes the zones marked in the screen and returns it without the
+// markers. Zones whose start or end was covered by an overlay are dropped.
+func (z *Zones) Scan(screen string) string {
+	if !strings.Contains(screen, zoneStart) {
+		z.mu.Lock()
+		z.zones = map[string]image.Rectangle{}
+		z.mu.Unlock()
+		return screen
+	}
+
+	zones := map[string]image.Rectangle{}
+	starts := map[int]image.Point{}
+	var b strings.Builder
+	b.Grow(len(screen))
+	x, y := 0, 0
+	var state byte
+	for rest := screen; len(rest) > 0; {
+		seq, width, n, newState := ansi.DecodeSequence(rest, state, nil)
+		state = newState
+		rest = rest[n:]
+		if seq == "\n" {
+			x, y = 0, y+1
+			b.WriteString(seq)
+			continue
+		}
+		if start, number, ok := parseZoneMarker(seq); ok {
+			if start {
+				starts[number] = image.Pt(x, y)
+			} else if from, ok := starts[number]; ok {
+				if id, ok := zoneName(number); ok {
+					// blocks spanning lines are as wide as their last line
+					zones[id] = image.Rect(from.X, from.Y, max(x, from.X+1), y+1)
+				}
+				delete(starts, number)
+			}
+			continue
+		}
+		x += width
+		b.WriteString(seq)
+	}
+
+	z.mu.Lock()
+	z.zones = zones
+	z.mu.Unlock()
+	return b.String()
+}
+
+// parseZoneMarker reads a zone marker, reporting whether it starts the zone
+func parseZoneMarker(seq string) (bool, int, bool) {
+	if !strings.HasSuffix(seq, "z") {
+		return false, 0, false
+	}
+	var start bool
+	switch {
+	case strings.HasPrefix(seq, zoneStart):
+		start = true
+	case strings.HasPrefix(seq, zoneEnd):
+	default:
+		return false, 0, false
+	}
+	number, err := strconv.Atoi(seq[len(zoneStart) : len(seq)-1])
+	if err != nil {
+		return false, 0, false
+	}
+	return start, number, true
+}
+
+// Hit returns the zone at x, y, the smallest one when zones overlap
+func (z *Zones) Hit(x, y int) (string, bool) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	point := image.Pt(x, y)
+	var hit string
+	var area int
+	for id, rect := range z.zones {
+		if !point.In(rect) {
+			continue
+		}
+		if a := rect.Dx() * rect.Dy(); hit == "" || a < area || (a == area && id < hit) {
+			hit, area = id, a
+		}
+	}
+	return hit, hit != ""
+}
+
+// Rect returns where the zone was on the last screen
+func (z *Zones) Rect(id string) (image.Rectangle, bool) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	rect, ok := z.zones[id]
+	return rect, ok
+}
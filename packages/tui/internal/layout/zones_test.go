@@ -0,0 +1,60 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+func TestZonesScan(t *testing.T) {
+	bold := lipgloss.NewStyle().Bold(true)
+	screen := "title\n  " + Mark("yes", bold.Render("Yes")) + "  " + Mark("no", "No")
+	zones := NewZones()
+	out := zones.Scan(screen)
+	if strings.Contains(out, zoneStart) || strings.Contains(out, zoneEnd) {
+		t.Fatalf("expected the markers to be stripped, got %q", out)
+	}
+	if width := lipgloss.Width(out); width != 9 {
+		t.Errorf("expected the screen width to be kept, got %d", width)
+	}
+
+	tests := []struct {
+		x, y int
+		want string
+	}{
+		{2, 1, "yes"},
+		{4, 1, "yes"},
+		{5, 1, ""},
+		{7, 1, "no"},
+		{2, 0, ""},
+	}
+	for _, tt := range tests {
+		got, _ := zones.Hit(tt.x, tt.y)
+		if got != tt.want {
+			t.Errorf("Hit(%d, %d) = %q, want %q", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestZonesOverlay(t *testing.T) {
+	background := Mark("messages", "aaaaaa\nbbbbbb\ncccccc")
+	screen := PlaceOverlay(1, 1, Mark("button", "OK"), background)
+	zones := NewZones()
+	zones.Scan(screen)
+
+	// the smallest zone wins where zones overlap
+	if got, _ := zones.Hit(1, 1); got != "button" {
+		t.Errorf("expected the overlay on top, got %q", got)
+	}
+	if got, _ := zones.Hit(0, 2); got != "messages" {
+		t.Errorf("expected the block below the overlay, got %q", got)
+	}
+
+	// an overlay covering the end of a zone drops it
+	screen = PlaceOverlay(4, 0, "XX", Mark("cut", "abcdef"))
+	zones.Scan(screen)
+	if _, ok := zones.Hit(0, 0); ok {
+		t.Error("expected a covered zone to be dropped")
+	}
+}
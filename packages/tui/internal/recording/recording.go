@@ -0,0 +1,197 @@
+// Package recording writes the server events and keystrokes of a TUI session
+// to a file with their timings, and plays them back, for demos and bug
+// reports.
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+// Kind is what an entry of a recording holds
+type Kind string
+
+const (
+	// KindEvent is an event of the server, as streamed
+	KindEvent Kind = "event"
+	// KindKey is a key press
+	KindKey Kind = "key"
+	// KindPaste is pasted text
+	KindPaste Kind = "paste"
+	// KindSession is the session the TUI switched to
+	KindSession Kind = "session"
+)
+
+// Entry is a line of a recording
+type Entry struct {
+	// Time is the offset from the start of the recording in milliseconds
+	Time int64           `json:"t"`
+	Kind Kind            `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Recorder appends entries to a recording file, it is safe for concurrent
+// use
+type Recorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	w     *bufio.Writer
+	start time.Time
+}
+
+// Create starts a recording at path, replacing any file there
+func Create(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: file, w: bufio.NewWriter(file), start: time.Now()}, nil
+}
+
+func (r *Recorder) write(kind Kind, data []byte) {
+	entry := Entry{
+		Time: time.Since(r.start).Milliseconds(),
+		Kind: kind,
+		Data: data,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.w.Write(append(line, '\n'))
+}
+
+// Event records a server event from its raw JSON
+func (r *Recorder) Event(raw string) {
+	if !json.Valid([]byte(raw)) {
+		return
+	}
+	r.write(KindEvent, []byte(raw))
+}
+
+// Key records a key press
+func (r *Recorder) Key(key tea.KeyPressMsg) {
+	data, _ := json.Marshal(tea.Key(key))
+	r.write(KindKey, data)
+}
+
+// Paste records pasted text
+func (r *Recorder) Paste(text string) {
+	data, _ := json.Marshal(text)
+	r.write(KindPaste, data)
+}
+
+// Session records the session the TUI switched to
+func (r *Recorder) Session(session opencode.Session) {
+	raw := session.JSON.RawJSON()
+	if raw == "" {
+		data, err := json.Marshal(session)
+		if err != nil {
+			return
+		}
+		raw = string(data)
+	}
+	r.write(KindSession, []byte(raw))
+}
+
+// Close flushes and closes the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Read parses a recording
+func Read(reader io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// SessionMsg switches the TUI to a recorded session, without loading it from
+// the server
+type SessionMsg struct {
+	Session opencode.Session
+}
+
+// FinishedMsg is sent once a replay played its last entry
+type FinishedMsg struct{}
+
+// Msg turns an entry back into the message the TUI received, nil for
+// entries it cannot decode
+func (e Entry) Msg() tea.Msg {
+	switch e.Kind {
+	case KindEvent:
+		var event opencode.EventListResponse
+		if json.Unmarshal(e.Data, &event) != nil {
+			return nil
+		}
+		return event.AsUnion()
+	case KindKey:
+		var key tea.Key
+		if json.Unmarshal(e.Data, &key) != nil {
+			return nil
+		}
+		return tea.KeyPressMsg(key)
+	case KindPaste:
+		var text string
+		if json.Unmarshal(e.Data, &text) != nil {
+			return nil
+		}
+		return tea.PasteMsg(text)
+	case KindSession:
+		var session opencode.Session
+		if json.Unmarshal(e.Data, &session) != nil {
+			return nil
+		}
+		return SessionMsg{Session: session}
+	}
+	return nil
+}
+
+// Play sends the messages of the entries at their recorded times, sped up by
+// speed, then a FinishedMsg
+func Play(ctx context.Context, entries []Entry, speed float64, send func(tea.Msg)) {
+	if speed <= 0 {
+		speed = 1
+	}
+	start := time.Now()
+	for _, entry := range entries {
+		at := time.Duration(float64(entry.Time) / speed * float64(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(start.Add(at))):
+		}
+		if msg := entry.Msg(); msg != nil {
+			send(msg)
+		}
+	}
+	send(FinishedMsg{})
+}
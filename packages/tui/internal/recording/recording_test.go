@@ -0,0 +1,72 @@
+package recording
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	opencode "github.com/sst/opencode-sdk-go"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Session(opencode.Session{ID: "ses_1", Title: "Demo"})
+	recorder.Key(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	recorder.Paste("pasted")
+	recorder.Event(`{"type":"session.idle","properties":{"sessionID":"ses_1"}}`)
+	recorder.Event(`not json`)
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	entries, err := Read(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+
+	if msg, ok := entries[0].Msg().(SessionMsg); !ok || msg.Session.ID != "ses_1" || msg.Session.Title != "Demo" {
+		t.Errorf("unexpected session entry %#v", entries[0].Msg())
+	}
+	if key, ok := entries[1].Msg().(tea.KeyPressMsg); !ok || key.String() != "a" {
+		t.Errorf("unexpected key entry %#v", entries[1].Msg())
+	}
+	if paste, ok := entries[2].Msg().(tea.PasteMsg); !ok || string(paste) != "pasted" {
+		t.Errorf("unexpected paste entry %#v", entries[2].Msg())
+	}
+	if idle, ok := entries[3].Msg().(opencode.EventListResponseEventSessionIdle); !ok || idle.Properties.SessionID != "ses_1" {
+		t.Errorf("unexpected event entry %#v", entries[3].Msg())
+	}
+}
+
+func TestPlay(t *testing.T) {
+	entries := []Entry{
+		{Time: 0, Kind: KindPaste, Data: []byte(`"one"`)},
+		{Time: 40, Kind: KindKey, Data: []byte(`{"Code":13}`)},
+		{Time: 50, Kind: "unknown", Data: []byte(`{}`)},
+	}
+	var sent []tea.Msg
+	Play(context.Background(), entries, 10, func(msg tea.Msg) { sent = append(sent, msg) })
+	if len(sent) != 3 {
+		t.Fatalf("expected 2 messages and the end, got %d", len(sent))
+	}
+	if _, ok := sent[1].(tea.KeyPressMsg); !ok {
+		t.Errorf("expected a key press, got %#v", sent[1])
+	}
+	if _, ok := sent[2].(FinishedMsg); !ok {
+		t.Errorf("expected the replay to finish, got %#v", sent[2])
+	}
+}
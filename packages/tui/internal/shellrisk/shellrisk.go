@@ -0,0 +1,431 @@
+// Package shellrisk flags shell commands that can do lasting damage, so the
+// approval dialog can say what a command risks before it runs.
+package shellrisk
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Category groups risks in the approval summary
+type Category string
+
+const (
+	Destructive Category = "destructive"
+	History     Category = "git history"
+	RemoteCode  Category = "remote code"
+	Permissions Category = "permissions"
+	OutsideRoot Category = "outside the project"
+)
+
+// Severity is how much a risk should worry the reader
+type Severity int
+
+const (
+	Warning Severity = iota
+	Danger
+)
+
+// Risk is one reason to think twice about a command
+type Risk struct {
+	Category Category
+	Severity Severity
+	Reason   string
+}
+
+// shells run a script read from their input
+var shells = []string{"sh", "bash", "zsh", "dash", "ksh", "fish", "python", "python3", "perl", "ruby", "node"}
+
+// wrappers run the command that follows them
+var wrappers = []string{"sudo", "doas", "env", "nohup", "time", "command", "exec", "xargs", "nice"}
+
+// command is a simple command: its words, without redirections, and the
+// files it redirects output to
+type command struct {
+	words   []string
+	outputs []string
+}
+
+// Analyze lists the risks of running line from root, most severe first
+func Analyze(line, root string) []Risk {
+	a := analysis{root: root}
+	for _, pipeline := range parse(line) {
+		a.pipeline(pipeline)
+	}
+	// a download fed to a shell through process substitution
+	if strings.Contains(line, "<(curl") || strings.Contains(line, "<(wget") ||
+		strings.Contains(line, "$(curl") || strings.Contains(line, "$(wget") {
+		for _, shell := range shells {
+			if strings.Contains(line, shell+" ") {
+				a.add(RemoteCode, Danger, "runs a script downloaded from the network")
+				break
+			}
+		}
+	}
+	slices.SortStableFunc(a.risks, func(x, y Risk) int {
+		return int(y.Severity) - int(x.Severity)
+	})
+	return a.risks
+}
+
+// Worst is the highest severity among risks, and false when there are none
+func Worst(risks []Risk) (Severity, bool) {
+	if len(risks) == 0 {
+		return Warning, false
+	}
+	worst := Warning
+	for _, risk := range risks {
+		worst = max(worst, risk.Severity)
+	}
+	return worst, true
+}
+
+type analysis struct {
+	root  string
+	risks []Risk
+}
+
+func (a *analysis) add(category Category, severity Severity, reason string) {
+	risk := Risk{Category: category, Severity: severity, Reason: reason}
+	if !slices.Contains(a.risks, risk) {
+		a.risks = append(a.risks, risk)
+	}
+}
+
+func (a *analysis) pipeline(commands []command) {
+	downloads := false
+	for _, cmd := range commands {
+		words := a.unwrap(cmd.words)
+		for _, output := range cmd.outputs {
+			a.write(output, "redirects output to")
+		}
+		if len(words) == 0 {
+			continue
+		}
+		name := filepath.Base(words[0])
+		if downloads && slices.Contains(shells, name) {
+			a.add(RemoteCode, Danger, "pipes a download straight into "+name)
+		}
+		if name == "curl" || name == "wget" {
+			downloads = true
+		}
+		a.command(name, words[1:])
+	}
+}
+
+// unwrap drops wrappers like sudo and leading variable assignments, noting
+// what running as root means
+func (a *analysis) unwrap(words []string) []string {
+	for len(words) > 0 {
+		name := filepath.Base(words[0])
+		switch {
+		case name == "sudo" || name == "doas":
+			a.add(Permissions, Warning, "runs as root with "+name)
+		case slices.Contains(wrappers, name):
+		case strings.Contains(words[0], "=") && !strings.HasPrefix(words[0], "-"):
+		default:
+			return words
+		}
+		words = words[1:]
+		// options of the wrapper itself
+		for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+func (a *analysis) command(name string, args []string) {
+	flags, operands := split(args)
+	switch name {
+	case "rm":
+		recursive := hasFlag(flags, 'r', "--recursive") || hasFlag(flags, 'R', "")
+		force := hasFlag(flags, 'f', "--force")
+		switch {
+		case recursive && force:
+			a.add(Destructive, Danger, "rm -rf deletes recursively without asking")
+		case recursive:
+			a.add(Destructive, Warning, "rm -r deletes whole directories")
+		}
+		for _, operand := range operands {
+			if operand == "/" || operand == "~" || operand == "*" || operand == "/*" || operand == "." || operand == ".." {
+				a.add(Destructive, Danger, "rm targets "+operand)
+			}
+			a.write(operand, "deletes")
+		}
+	case "mv":
+		if len(operands) > 1 {
+			a.write(operands[len(operands)-1], "moves files to")
+		}
+	case "cp":
+		if len(operands) > 1 {
+			a.write(operands[len(operands)-1], "copies files to")
+		}
+	case "tee", "touch", "mkdir":
+		for _, operand := range operands {
+			a.write(operand, "writes to")
+		}
+	case "dd":
+		for _, arg := range args {
+			if target, ok := strings.CutPrefix(arg, "of="); ok {
+				a.add(Destructive, Danger, "dd overwrites "+target)
+				a.write(target, "writes to")
+			}
+		}
+	case "shred", "wipefs", "fdisk", "parted":
+		a.add(Destructive, Danger, name+" destroys data")
+	case "chmod":
+		for _, operand := range operands {
+			if operand == "777" || operand == "666" || operand == "0777" || operand == "a+rwx" ||
+				operand == "o+w" || operand == "a+w" || strings.HasSuffix(operand, "o+rwx") {
+				a.add(Permissions, Danger, "chmod "+operand+" makes files writable by everyone")
+				break
+			}
+		}
+		if hasFlag(flags, 'R', "--recursive") {
+			a.add(Permissions, Warning, "chmod -R changes permissions of whole directories")
+		}
+	case "chown":
+		if hasFlag(flags, 'R', "--recursive") {
+			a.add(Permissions, Warning, "chown -R changes the owner of whole directories")
+		}
+	case "git":
+		a.git(args)
+	default:
+		if strings.HasPrefix(name, "mkfs") {
+			a.add(Destructive, Danger, name+" formats a device")
+		}
+	}
+}
+
+func (a *analysis) git(args []string) {
+	// skip options of git itself, like -C dir
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-C" || args[0] == "-c" {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return
+	}
+	flags, operands := split(args[1:])
+	switch args[0] {
+	case "push":
+		force := hasFlag(flags, 'f', "--force") || slices.Contains(flags, "--force-with-lease")
+		for _, operand := range operands {
+			if strings.HasPrefix(operand, "+") {
+				force = true
+			}
+		}
+		if force {
+			a.add(History, Danger, "force push rewrites the remote history")
+		}
+		if slices.Contains(flags, "--delete") || hasFlag(flags, 'd', "") {
+			a.add(History, Danger, "push --delete removes a remote branch")
+		}
+	case "reset":
+		if slices.Contains(flags, "--hard") {
+			a.add(History, Warning, "reset --hard discards uncommitted changes")
+		}
+	case "clean":
+		if hasFlag(flags, 'f', "--force") {
+			a.add(Destructive, Warning, "git clean deletes untracked files")
+		}
+	case "branch":
+		if hasFlag(flags, 'D', "") {
+			a.add(History, Warning, "branch -D deletes unmerged branches")
+		}
+	case "checkout", "restore":
+		if slices.Contains(operands, ".") {
+			a.add(History, Warning, "git "+args[0]+" . discards uncommitted changes")
+		}
+	}
+}
+
+// write flags a write to a path outside the project root. Temporary
+// directories and devices like /dev/null are fine.
+func (a *analysis) write(path, verb string) {
+	if a.root == "" || path == "" || strings.HasPrefix(path, "$") {
+		return
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if path == "~" {
+			path = home
+		} else if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			path = filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(a.root, path)
+	}
+	path = filepath.Clean(path)
+	for _, safe := range []string{a.root, os.TempDir(), "/tmp", "/dev/null", "/dev/stdout", "/dev/stderr"} {
+		if path == safe || strings.HasPrefix(path, strings.TrimSuffix(safe, "/")+"/") {
+			return
+		}
+	}
+	severity := Warning
+	if verb == "deletes" {
+		severity = Danger
+	}
+	a.add(OutsideRoot, severity, verb+" "+path)
+}
+
+// split separates options from operands, everything after -- is an operand
+func split(args []string) (flags, operands []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return flags, append(operands, args[i+1:]...)
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			flags = append(flags, arg)
+		} else {
+			operands = append(operands, arg)
+		}
+	}
+	return flags, operands
+}
+
+// hasFlag reports whether the short option is set, alone or combined like
+// -rf, or the long option is given
+func hasFlag(flags []string, short rune, long string) bool {
+	for _, flag := range flags {
+		if strings.HasPrefix(flag, "--") {
+			if long != "" && flag == long {
+				return true
+			}
+			continue
+		}
+		if strings.ContainsRune(flag[1:], short) {
+			return true
+		}
+	}
+	return false
+}
+
+// parse splits a command line into pipelines of simple commands. It knows
+// quoting, escapes, the control operators and output redirections, which is
+// enough to tell commands apart; expansions are left as they are.
+func parse(line string) [][]command {
+	var pipelines [][]command
+	var pipeline []command
+	var cmd command
+	var word strings.Builder
+	inWord := false
+	redirect := false
+
+	endWord := func() {
+		if !inWord {
+			return
+		}
+		if redirect {
+			cmd.outputs = append(cmd.outputs, word.String())
+			redirect = false
+		} else {
+			cmd.words = append(cmd.words, word.String())
+		}
+		word.Reset()
+		inWord = false
+	}
+	endCommand := func() {
+		endWord()
+		if len(cmd.words) > 0 || len(cmd.outputs) > 0 {
+			pipeline = append(pipeline, cmd)
+		}
+		cmd = command{}
+	}
+	endPipeline := func() {
+		endCommand()
+		if len(pipeline) > 0 {
+			pipelines = append(pipelines, pipeline)
+		}
+		pipeline = nil
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == '\'':
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case r == '#' && !inWord:
+			// a comment runs to the end of the line
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			endPipeline()
+		case r == ' ' || r == '\t':
+			endWord()
+		case r == '\n' || r == ';':
+			endPipeline()
+		case r == '&':
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				i++
+			} else if i+1 < len(runes) && runes[i+1] == '>' {
+				// &> redirects both streams
+				i++
+				if i+1 < len(runes) && runes[i+1] == '>' {
+					i++
+				}
+				endWord()
+				redirect = true
+				continue
+			}
+			endPipeline()
+		case r == '|':
+			if i+1 < len(runes) && runes[i+1] == '|' {
+				i++
+				endPipeline()
+			} else {
+				endCommand()
+			}
+		case r == '>':
+			// a file descriptor number before it belongs to the redirection
+			if inWord && isDigits(word.String()) {
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			if i+1 < len(runes) && runes[i+1] == '>' {
+				i++
+			}
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				// >&2 duplicates a descriptor rather than writing a file
+				i++
+				for i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9' {
+					i++
+				}
+				continue
+			}
+			redirect = true
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	endPipeline()
+	return pipelines
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
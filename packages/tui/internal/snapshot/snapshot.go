@@ -0,0 +1,138 @@
+// Package snapshot saves and restores the state of a git working tree under
+// refs/kuuzuki/snapshots, without touching the index, stash or branches.
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const refPrefix = "refs/kuuzuki/snapshots/"
+
+type Snapshot struct {
+	Ref     string
+	Commit  string
+	Message string
+	Time    time.Time
+}
+
+func git(root string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// toplevel returns the root of the working tree containing dir, snapshots
+// always cover the whole working tree
+func toplevel(dir string) (string, error) {
+	top, err := git(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(top), nil
+}
+
+// Create records every tracked and untracked (but not ignored) file in the
+// working tree as a commit referenced by a new snapshot ref
+func Create(root, message string) (Snapshot, error) {
+	root, err := toplevel(root)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	dir, err := os.MkdirTemp("", "kuuzuki-snapshot-*")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	// stage into a throwaway index so the user's index is left alone
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(dir, "index")}
+	if _, err := git(root, env, "add", "--all", "."); err != nil {
+		return Snapshot{}, err
+	}
+	tree, err := git(root, env, "write-tree")
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if head, err := git(root, nil, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil && head != "" {
+		args = append(args, "-p", head)
+	}
+	commit, err := git(root, nil, args...)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	now := time.Now()
+	ref := refPrefix + strconv.FormatInt(now.UnixNano(), 10)
+	if _, err := git(root, nil, "update-ref", ref, commit); err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Ref: ref, Commit: commit, Message: message, Time: now}, nil
+}
+
+// List returns the snapshots of the repository, newest first
+func List(root string) ([]Snapshot, error) {
+	output, err := git(root, nil,
+		"for-each-ref",
+		"--sort=-refname",
+		"--format=%(refname)%09%(objectname)%09%(subject)",
+		refPrefix,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		nanos, err := strconv.ParseInt(strings.TrimPrefix(fields[0], refPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Ref:     fields[0],
+			Commit:  fields[1],
+			Message: fields[2],
+			Time:    time.Unix(0, nanos),
+		})
+	}
+	return snapshots, nil
+}
+
+// Restore puts the working tree files back to their state in the snapshot.
+// Tracked files that didn't exist at the time are removed, untracked files
+// created since are kept.
+func Restore(root string, snapshot Snapshot) error {
+	root, err := toplevel(root)
+	if err != nil {
+		return err
+	}
+	_, err = git(root, nil, "restore", "--source="+snapshot.Commit, "--worktree", "--", ".")
+	return err
+}
+
+// Delete removes the snapshot ref
+func Delete(root string, snapshot Snapshot) error {
+	_, err := git(root, nil, "update-ref", "-d", snapshot.Ref)
+	return err
+}
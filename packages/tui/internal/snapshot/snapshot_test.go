@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateAndRestore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			return ""
+		}
+		return string(content)
+	}
+
+	run("init", "-q")
+	write("tracked.txt", "original")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	write("tracked.txt", "modified")
+	write("untracked.txt", "new")
+
+	snapshot, err := Create(root, "before edit")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	snapshots, err := List(root)
+	if err != nil || len(snapshots) != 1 || snapshots[0].Message != "before edit" {
+		t.Fatalf("List() = %v, %v, want the snapshot", snapshots, err)
+	}
+
+	os.Remove(filepath.Join(root, "tracked.txt"))
+	write("untracked.txt", "clobbered")
+
+	if err := Restore(root, snapshot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if got := read("tracked.txt"); got != "modified" {
+		t.Errorf("tracked.txt = %q, want modified", got)
+	}
+	if got := read("untracked.txt"); got != "new" {
+		t.Errorf("untracked.txt = %q, want new", got)
+	}
+
+	if err := Delete(root, snapshot); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if snapshots, _ := List(root); len(snapshots) != 0 {
+		t.Errorf("List() after Delete() = %v, want none", snapshots)
+	}
+}
@@ -0,0 +1,122 @@
+package styles
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// Fancy is false in terminals without unicode or truecolor, like a plain
+// xterm over SSH or the Windows console host, where the views are drawn
+// with ASCII borders and symbols in the 16 colors of the terminal
+var Fancy = true
+
+// basicTerms are the TERM of terminals that render neither truecolor nor,
+// reliably, the box drawing characters
+var basicTerms = map[string]bool{
+	"dumb": true, "linux": true, "xterm": true, "xterm-color": true,
+	"vt100": true, "vt102": true, "vt220": true, "ansi": true, "cons25": true,
+}
+
+// DetectFancy tells from the environment whether the terminal renders
+// unicode and truecolor
+func DetectFancy(getenv func(string) string, goos string) bool {
+	if goos == "windows" {
+		// Windows Terminal, VS Code and mintty announce themselves, the
+		// console host doesn't
+		return getenv("WT_SESSION") != "" || getenv("TERM_PROGRAM") != "" || getenv("TERM") != ""
+	}
+	if !unicodeLocale(getenv) {
+		return false
+	}
+	colorterm := strings.ToLower(getenv("COLORTERM"))
+	if colorterm == "truecolor" || colorterm == "24bit" {
+		return true
+	}
+	return !basicTerms[strings.ToLower(getenv("TERM"))]
+}
+
+// unicodeLocale reports false for a locale with a legacy codeset, like
+// en_US.ISO-8859-1. Without one the terminal is taken to be UTF-8, locales
+// are often not passed over SSH or into containers.
+func unicodeLocale(getenv func(string) string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := getenv(name)
+		if locale == "" {
+			continue
+		}
+		_, codeset, ok := strings.Cut(locale, ".")
+		if !ok {
+			return true
+		}
+		codeset, _, _ = strings.Cut(strings.ToLower(codeset), "@")
+		return codeset == "utf-8" || codeset == "utf8"
+	}
+	return true
+}
+
+// asciiSymbols are the ASCII stand-ins of the symbols drawn by the views
+var asciiSymbols = map[rune]string{
+	'─': "-", '━': "-", '═': "=", '│': "|", '┃': "|", '║': "|",
+	'█': "#", '▓': "#", '▒': ":", '░': ":", '▀': "\"", '▄': "_",
+	'…': ".", '·': ".", '•': "*", '●': "*", '○': "o", '⚪': "o", '🟢': "*",
+	'«': "<", '»': ">", '‹': "<", '›': ">", '❯': ">",
+	'→': ">", '←': "<", '↑': "^", '↓': "v",
+	'▶': ">", '▸': ">", '▼': "v", '▾': "v",
+	'✓': "+", '✅': "+", '✗': "x", '❌': "x", '×': "x",
+	'⚠': "!", '🚨': "!", 'ℹ': "i", '∟': "L", '≤': "<",
+}
+
+// ASCII replaces the box drawing characters, arrows, shapes and emoji of
+// the text with ASCII of the same width, leaving letters and the escape
+// sequences as they are
+func ASCII(text string) string {
+	ascii := true
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	state := -1
+	for text != "" {
+		var cluster string
+		var boundaries int
+		cluster, text, boundaries, state = uniseg.StepString(text, state)
+		width := boundaries >> uniseg.ShiftWidth
+		r, _ := utf8.DecodeRuneInString(cluster)
+		replacement, ok := asciiSymbols[r]
+		switch {
+		case ok:
+		case r >= 0x2500 && r <= 0x257f:
+			replacement = "+"
+		case r >= 0x2580 && r <= 0x259f:
+			replacement = "#"
+		case isSymbol(r):
+			replacement = "*"
+		default:
+			b.WriteString(cluster)
+			continue
+		}
+		b.WriteString(replacement)
+		if width > 1 {
+			b.WriteString(strings.Repeat(" ", width-1))
+		}
+	}
+	return b.String()
+}
+
+// isSymbol reports whether r is an arrow, a shape, a dingbat or an emoji
+func isSymbol(r rune) bool {
+	return (r >= 0x2190 && r <= 0x21ff) ||
+		(r >= 0x25a0 && r <= 0x27bf) ||
+		(r >= 0x2b00 && r <= 0x2bff) ||
+		(r >= 0x1f000 && r <= 0x1faff)
+}
@@ -0,0 +1,56 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDetectFancy(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		vars map[string]string
+		want bool
+	}{
+		{"truecolor", "linux", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor", "LANG": "en_US.UTF-8"}, true},
+		{"256 colors", "linux", map[string]string{"TERM": "xterm-256color"}, true},
+		{"plain xterm", "linux", map[string]string{"TERM": "xterm", "LANG": "en_US.UTF-8"}, false},
+		{"plain xterm with truecolor", "linux", map[string]string{"TERM": "xterm", "COLORTERM": "24bit"}, true},
+		{"linux console", "linux", map[string]string{"TERM": "linux"}, false},
+		{"latin1 locale", "linux", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor", "LC_ALL": "de_DE.ISO-8859-1"}, false},
+		{"utf8 locale with modifier", "darwin", map[string]string{"TERM": "xterm-256color", "LC_CTYPE": "de_DE.utf8@euro"}, true},
+		{"console host", "windows", map[string]string{}, false},
+		{"windows terminal", "windows", map[string]string{"WT_SESSION": "0d4f"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.vars[key] }
+			if got := DetectFancy(getenv, tt.goos); got != tt.want {
+				t.Errorf("DetectFancy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestASCII(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"╭──╮\n┃ok│\n╰──╯", "+--+\n|ok|\n+--+"},
+		{"✓ done → next…", "+ done > next."},
+		{"📄 notes, café", "*  notes, café"},
+		{"\x1b[38;2;255;0;0m●\x1b[0m 日本", "\x1b[38;2;255;0;0m*\x1b[0m 日本"},
+	}
+	for _, tt := range tests {
+		got := ASCII(tt.text)
+		if got != tt.want {
+			t.Errorf("ASCII(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if ansi.StringWidth(got) != ansi.StringWidth(tt.text) {
+			t.Errorf("ASCII(%q) changed the width from %d to %d", tt.text, ansi.StringWidth(tt.text), ansi.StringWidth(got))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package styles
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// IconSet is the set of glyphs the views draw their icons with
+type IconSet string
+
+const (
+	// IconsEmoji are emoji drawn two cells wide by default, left without
+	// variation selectors, which terminals and fonts disagree on the width of
+	IconsEmoji IconSet = "emoji"
+	// IconsNerdFont are the glyphs of a patched Nerd Font
+	IconsNerdFont IconSet = "nerd_font"
+	// IconsASCII are plain characters, for fonts without either
+	IconsASCII IconSet = "ascii"
+)
+
+// Icons is the icon set of the views, ASCII whatever it's set to in
+// terminals that aren't Fancy
+var Icons = IconsEmoji
+
+// Icon is one of the icons drawn by the views
+type Icon int
+
+const (
+	IconLock Icon = iota
+	IconTool
+	IconBash
+	IconEdit
+	IconWrite
+	IconRead
+	IconRunning
+	IconRepeat
+	IconSnapshot
+	IconSearch
+	IconThinking
+	IconSuccess
+	IconError
+	IconWarning
+	IconInfo
+	IconNotice
+	IconActive
+	IconInactive
+	IconFile
+	IconFileGo
+	IconFileScript
+	IconFilePython
+	IconFileRust
+	IconFileJava
+	IconFileC
+	IconFileHTML
+	IconFileCSS
+	IconFileJSON
+	IconFileMarkdown
+	IconFilePDF
+	IconFileImage
+	IconFileArchive
+	iconCount
+)
+
+// IconWidth is the cells every icon takes, narrower glyphs are padded with
+// spaces so rows line up whatever the set
+const IconWidth = 2
+
+var iconSets = map[IconSet][iconCount]string{
+	IconsEmoji: {
+		IconLock: "🔒", IconTool: "🔧", IconBash: "⚡", IconEdit: "📝", IconWrite: "📄", IconRead: "📖",
+		IconRunning: "⚡", IconRepeat: "🔄", IconSnapshot: "💾", IconSearch: "🔍", IconThinking: "🧠",
+		IconSuccess: "✅", IconError: "❌", IconWarning: "🚨", IconInfo: "💬", IconNotice: "📢",
+		IconActive: "🟢", IconInactive: "⚪",
+		IconFile: "📄", IconFileGo: "🐹", IconFileScript: "📜", IconFilePython: "🐍", IconFileRust: "🦀",
+		IconFileJava: "☕", IconFileC: "🔩", IconFileHTML: "🌐", IconFileCSS: "🎨", IconFileJSON: "📋",
+		IconFileMarkdown: "📝", IconFilePDF: "📕", IconFileImage: "📷", IconFileArchive: "📦",
+	},
+	IconsNerdFont: {
+		IconLock: "\uf023", IconTool: "\uf0ad", IconBash: "\uf120", IconEdit: "\uf044", IconWrite: "\uf15b", IconRead: "\uf02d",
+		IconRunning: "\uf0e7", IconRepeat: "\uf021", IconSnapshot: "\uf0c7", IconSearch: "\uf002", IconThinking: "\uf0eb",
+		IconSuccess: "\uf00c", IconError: "\uf00d", IconWarning: "\uf071", IconInfo: "\uf05a", IconNotice: "\uf0a1",
+		IconActive: "\uf111", IconInactive: "\uf10c",
+		IconFile: "\uf15b", IconFileGo: "\ue627", IconFileScript: "\ue74e", IconFilePython: "\ue73c", IconFileRust: "\ue7a8",
+		IconFileJava: "\ue738", IconFileC: "\ue61e", IconFileHTML: "\ue736", IconFileCSS: "\ue749", IconFileJSON: "\ue60b",
+		IconFileMarkdown: "\ue73e", IconFilePDF: "\uf1c1", IconFileImage: "\uf1c5", IconFileArchive: "\uf1c6",
+	},
+	IconsASCII: {
+		IconLock: "#", IconTool: "*", IconBash: "$", IconEdit: "~", IconWrite: "+", IconRead: ">",
+		IconRunning: ">", IconRepeat: "@", IconSnapshot: "#", IconSearch: "/", IconThinking: "~",
+		IconSuccess: "+", IconError: "x", IconWarning: "!", IconInfo: "i", IconNotice: "*",
+		IconActive: "*", IconInactive: "o",
+		IconFile: "-", IconFileGo: "-", IconFileScript: "-", IconFilePython: "-", IconFileRust: "-",
+		IconFileJava: "-", IconFileC: "-", IconFileHTML: "-", IconFileCSS: "-", IconFileJSON: "-",
+		IconFileMarkdown: "-", IconFilePDF: "-", IconFileImage: "-", IconFileArchive: "-",
+	},
+}
+
+// ParseIconSet returns the icon set of the name, false if there's none
+func ParseIconSet(name string) (IconSet, bool) {
+	set := IconSet(strings.ToLower(strings.TrimSpace(name)))
+	_, ok := iconSets[set]
+	return set, ok
+}
+
+// String returns the icon in the current set, padded to IconWidth
+func (i Icon) String() string {
+	set := Icons
+	if !Fancy {
+		set = IconsASCII
+	}
+	return i.In(set)
+}
+
+// In returns the icon in the set, padded to IconWidth
+func (i Icon) In(set IconSet) string {
+	icons, ok := iconSets[set]
+	if !ok {
+		icons = iconSets[IconsEmoji]
+	}
+	glyph := icons[i]
+	return glyph + strings.Repeat(" ", max(IconWidth-ansi.StringWidth(glyph), 0))
+}
@@ -0,0 +1,52 @@
+package styles
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestIconWidths(t *testing.T) {
+	for set, icons := range iconSets {
+		for i, glyph := range icons {
+			icon := Icon(i)
+			if glyph == "" {
+				t.Errorf("%s: icon %d missing", set, i)
+				continue
+			}
+			// a single code point, as variation selectors and joiners are
+			// where terminals disagree on the width
+			if utf8.RuneCountInString(glyph) != 1 {
+				t.Errorf("%s: icon %d %q is more than one code point", set, i, glyph)
+			}
+			if width := ansi.StringWidth(icon.In(set)); width != IconWidth {
+				t.Errorf("%s: icon %d %q is %d cells wide", set, i, glyph, width)
+			}
+		}
+	}
+}
+
+func TestIconSet(t *testing.T) {
+	defer func(icons IconSet, fancy bool) { Icons, Fancy = icons, fancy }(Icons, Fancy)
+
+	if set, ok := ParseIconSet(" Nerd_Font "); !ok || set != IconsNerdFont {
+		t.Errorf("ParseIconSet() = %q, %v", set, ok)
+	}
+	if _, ok := ParseIconSet("wingdings"); ok {
+		t.Error("parsed an unknown icon set")
+	}
+
+	Icons, Fancy = IconsEmoji, true
+	if got := IconError.String(); got != "❌" {
+		t.Errorf("got %q", got)
+	}
+	Icons = IconsNerdFont
+	if got := IconError.String(); got != "\uf00d " {
+		t.Errorf("got %q", got)
+	}
+	Fancy = false
+	if got := IconError.String(); got != "x " {
+		t.Errorf("got %q in a basic terminal", got)
+	}
+}
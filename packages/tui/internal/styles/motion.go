@@ -0,0 +1,6 @@
+package styles
+
+// ReduceMotion shows spinners still and cursors without blinking, for
+// users sensitive to motion, screen readers that would announce every
+// frame and slow SSH links
+var ReduceMotion = false
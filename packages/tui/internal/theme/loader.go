@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"image/color"
+	"log/slog"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/BurntSushi/toml"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/charmbracelet/lipgloss/v2/compat"
 )
@@ -18,8 +21,9 @@ import (
 var themesFS embed.FS
 
 type JSONTheme struct {
-	Defs  map[string]any `json:"defs,omitempty"`
-	Theme map[string]any `json:"theme"`
+	Defs    map[string]any `json:"defs,omitempty"`
+	Theme   map[string]any `json:"theme"`
+	Markers bool           `json:"markers,omitempty"`
 }
 
 type LoadedTheme struct {
@@ -61,17 +65,19 @@ func LoadThemesFromJSON() error {
 	return nil
 }
 
-// LoadThemesFromDirectories loads themes from user directories in the correct override order.
-// The hierarchy is (from lowest to highest priority):
-// 1. Built-in themes (embedded)
-// 2. USER_CONFIG/opencode/themes/*.json
-// 3. PROJECT_ROOT/.opencode/themes/*.json
-// 4. CWD/.opencode/themes/*.json
-func LoadThemesFromDirectories(userConfig, projectRoot, cwd string) error {
-	if err := LoadThemesFromJSON(); err != nil {
-		return fmt.Errorf("failed to load built-in themes: %w", err)
-	}
+// themeParsers parse theme files by extension
+var themeParsers = map[string]func(name string, data []byte) (Theme, error){
+	".json": parseJSONTheme,
+	".toml": parseTOMLTheme,
+}
+
+// themeFiles maps the names of the themes loaded from user directories to
+// their files
+var themeFiles sync.Map
 
+// ThemeDirectories returns the user theme directories from lowest to highest
+// priority
+func ThemeDirectories(userConfig, projectRoot, cwd string) []string {
 	dirs := []string{
 		filepath.Join(userConfig, "themes"),
 		filepath.Join(projectRoot, ".opencode", "themes"),
@@ -79,10 +85,23 @@ func LoadThemesFromDirectories(userConfig, projectRoot, cwd string) error {
 	if cwd != projectRoot {
 		dirs = append(dirs, filepath.Join(cwd, ".opencode", "themes"))
 	}
+	return dirs
+}
+
+// LoadThemesFromDirectories loads themes from user directories in the correct override order.
+// The hierarchy is (from lowest to highest priority):
+// 1. Built-in themes (embedded)
+// 2. USER_CONFIG/opencode/themes/*.{json,toml}
+// 3. PROJECT_ROOT/.opencode/themes/*.{json,toml}
+// 4. CWD/.opencode/themes/*.{json,toml}
+func LoadThemesFromDirectories(userConfig, projectRoot, cwd string) error {
+	if err := LoadThemesFromJSON(); err != nil {
+		return fmt.Errorf("failed to load built-in themes: %w", err)
+	}
 
-	for _, dir := range dirs {
+	for _, dir := range ThemeDirectories(userConfig, projectRoot, cwd) {
 		if err := loadThemesFromDirectory(dir); err != nil {
-			fmt.Printf("Warning: Failed to load themes from %s: %v\n", dir, err)
+			slog.Warn("Failed to load themes", "dir", dir, "error", err)
 		}
 	}
 
@@ -100,29 +119,94 @@ func loadThemesFromDirectory(dir string) error {
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+		if entry.IsDir() || !IsThemeFile(entry.Name()) {
 			continue
 		}
-
-		themeName := strings.TrimSuffix(entry.Name(), ".json")
 		filePath := filepath.Join(dir, entry.Name())
-
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			fmt.Printf("Warning: Failed to read theme file %s: %v\n", filePath, err)
-			continue
+		if _, err := LoadThemeFile(filePath); err != nil {
+			slog.Warn("Failed to load theme", "path", filePath, "error", err)
 		}
+	}
 
-		theme, err := parseJSONTheme(themeName, data)
-		if err != nil {
-			fmt.Printf("Warning: Failed to parse theme %s: %v\n", filePath, err)
-			continue
+	return nil
+}
+
+// LoadThemeFile registers the theme defined by a JSON or TOML file, named
+// after the file, and returns its name
+func LoadThemeFile(filePath string) (string, error) {
+	ext := filepath.Ext(filePath)
+	parse, ok := themeParsers[ext]
+	if !ok {
+		return "", fmt.Errorf("unsupported theme file type %s", ext)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read theme file: %w", err)
+	}
+	themeName := strings.TrimSuffix(filepath.Base(filePath), ext)
+	theme, err := parse(themeName, data)
+	if err != nil {
+		return "", err
+	}
+	RegisterTheme(themeName, theme)
+	themeFiles.Store(themeName, filePath)
+	return themeName, nil
+}
+
+// unloadThemeFile forgets a theme whose file was removed, bringing back the
+// built-in theme of the same name if it overrode one
+func unloadThemeFile(name string) {
+	file, ok := themeFiles.Load(name)
+	if !ok {
+		return
+	}
+	if _, err := os.Stat(file.(string)); err == nil {
+		return
+	}
+	themeFiles.Delete(name)
+	if data, err := themesFS.ReadFile(path.Join("themes", name+".json")); err == nil {
+		if theme, err := parseJSONTheme(name, data); err == nil {
+			RegisterTheme(name, theme)
+			return
 		}
+	}
+	UnregisterTheme(name)
+}
 
-		RegisterTheme(themeName, theme)
+// IsThemeFile reports whether the file is one themes are loaded from
+func IsThemeFile(name string) bool {
+	_, ok := themeParsers[filepath.Ext(name)]
+	return ok
+}
+
+// CopyTheme writes the definition of a built-in or user theme to a new file
+// in the directory, named after the theme with a -custom suffix, so it can be
+// edited. An existing copy is kept. It returns the path of the copy.
+func CopyTheme(name, dir string) (string, error) {
+	var data []byte
+	var err error
+	ext := ".json"
+	if file, ok := themeFiles.Load(name); ok {
+		ext = filepath.Ext(file.(string))
+		data, err = os.ReadFile(file.(string))
+	} else {
+		data, err = themesFS.ReadFile(path.Join("themes", name+".json"))
+	}
+	if err != nil {
+		return "", fmt.Errorf("theme %s has no definition to copy: %w", name, err)
 	}
 
-	return nil
+	copyPath := filepath.Join(dir, strings.TrimSuffix(name, "-custom")+"-custom"+ext)
+	if _, err := os.Stat(copyPath); err == nil {
+		return copyPath, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(copyPath, data, 0o644); err != nil {
+		return "", err
+	}
+	return copyPath, nil
 }
 
 func parseJSONTheme(name string, data []byte) (Theme, error) {
@@ -133,6 +217,7 @@ func parseJSONTheme(name string, data []byte) (Theme, error) {
 	theme := &LoadedTheme{
 		name: name,
 	}
+	theme.MarkersEnabled = jsonTheme.Markers
 	colorMap := make(map[string]*colorRef)
 	for key, value := range jsonTheme.Defs {
 		colorMap[key] = &colorRef{value: value, resolved: false}
@@ -161,6 +246,21 @@ func parseJSONTheme(name string, data []byte) (Theme, error) {
 	return theme, nil
 }
 
+// parseTOMLTheme parses a theme with the same structure as the JSON themes
+func parseTOMLTheme(name string, data []byte) (Theme, error) {
+	var raw map[string]any
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TOML: %w", err)
+	}
+	// going through JSON also turns TOML integers into the float64 ANSI
+	// color codes the resolver expects
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONTheme(name, data)
+}
+
 type colorResolver struct {
 	colors  map[string]*colorRef
 	visited map[string]bool
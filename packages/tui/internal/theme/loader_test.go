@@ -139,3 +139,111 @@ func TestLoadThemesFromDirectories(t *testing.T) {
 		t.Error("Override theme not properly loaded")
 	}
 }
+
+func TestParseJSONThemeMarkers(t *testing.T) {
+	plain, err := parseJSONTheme("plain", []byte(`{"theme": {"primary": "#ffffff"}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse theme: %v", err)
+	}
+	if plain.Markers() {
+		t.Error("Expected markers to be disabled by default")
+	}
+
+	marked, err := parseJSONTheme("marked", []byte(`{"markers": true, "theme": {"primary": "#ffffff"}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse theme: %v", err)
+	}
+	if !marked.Markers() {
+		t.Error("Expected markers to be enabled")
+	}
+}
+
+func TestLoadThemeFileTOML(t *testing.T) {
+	themeFile := filepath.Join(t.TempDir(), "toml-test.toml")
+	os.WriteFile(themeFile, []byte(`
+markers = true
+
+[defs]
+base = "#101010"
+
+[theme]
+background = "base"
+text = 7
+primary = { dark = "#ffffff", light = "#000000" }
+`), 0644)
+
+	name, err := LoadThemeFile(themeFile)
+	if err != nil {
+		t.Fatalf("Failed to load theme: %v", err)
+	}
+	if name != "toml-test" {
+		t.Errorf("Expected theme name toml-test, got %s", name)
+	}
+	loaded := GetTheme(name)
+	if loaded == nil || !loaded.Markers() {
+		t.Fatal("Expected the theme to be registered with markers")
+	}
+	if background := loaded.Background(); background.Dark == nil || background.Light == nil {
+		t.Error("Background color reference not resolved")
+	}
+	if text := loaded.Text(); text.Dark == nil {
+		t.Error("ANSI text color not parsed")
+	}
+}
+
+func TestCopyTheme(t *testing.T) {
+	if err := LoadThemesFromJSON(); err != nil {
+		t.Fatalf("Failed to load themes: %v", err)
+	}
+	dir := filepath.Join(t.TempDir(), "themes")
+
+	copyPath, err := CopyTheme("tokyonight", dir)
+	if err != nil {
+		t.Fatalf("Failed to copy theme: %v", err)
+	}
+	if copyPath != filepath.Join(dir, "tokyonight-custom.json") {
+		t.Errorf("Unexpected copy path %s", copyPath)
+	}
+	if _, err := LoadThemeFile(copyPath); err != nil {
+		t.Errorf("Failed to load the copy: %v", err)
+	}
+
+	// copying the copy keeps editing the same file
+	again, err := CopyTheme("tokyonight-custom", dir)
+	if err != nil || again != copyPath {
+		t.Errorf("Expected %s again, got %s (%v)", copyPath, again, err)
+	}
+}
+
+func TestUnloadThemeFile(t *testing.T) {
+	dir := t.TempDir()
+	themeFile := filepath.Join(dir, "unload-test.json")
+	os.WriteFile(themeFile, []byte(`{"theme": {"primary": "#ffffff"}}`), 0644)
+	if _, err := LoadThemeFile(themeFile); err != nil {
+		t.Fatalf("Failed to load theme: %v", err)
+	}
+
+	// a theme whose file is still there stays
+	unloadThemeFile("unload-test")
+	if GetTheme("unload-test") == nil {
+		t.Fatal("Expected the theme to stay registered")
+	}
+
+	os.Remove(themeFile)
+	unloadThemeFile("unload-test")
+	if GetTheme("unload-test") != nil || slices.Contains(AvailableThemes(), "unload-test") {
+		t.Error("Expected the deleted theme to be unregistered")
+	}
+
+	// deleting an override brings back the built-in theme
+	override := filepath.Join(dir, "tokyonight.json")
+	os.WriteFile(override, []byte(`{"theme": {"primary": "#ffffff"}}`), 0644)
+	if _, err := LoadThemeFile(override); err != nil {
+		t.Fatalf("Failed to load theme: %v", err)
+	}
+	os.Remove(override)
+	unloadThemeFile("tokyonight")
+	if builtin := GetTheme("tokyonight"); builtin == nil || builtin.Primary().Dark == nil {
+		t.Error("Expected the built-in tokyonight theme back")
+	}
+}
@@ -44,6 +44,24 @@ func RegisterTheme(name string, theme Theme) {
 	}
 }
 
+// UnregisterTheme removes a theme from the registry.
+// If it was the active theme, the default one takes its place.
+func UnregisterTheme(name string) {
+	globalManager.mu.Lock()
+	defer globalManager.mu.Unlock()
+
+	delete(globalManager.themes, name)
+
+	if globalManager.currentName == name {
+		globalManager.currentName = ""
+		globalManager.currentUsesAnsiCache = false
+		if theme, exists := globalManager.themes["opencode"]; exists {
+			globalManager.currentName = "opencode"
+			globalManager.currentUsesAnsiCache = themeUsesAnsiColors(theme)
+		}
+	}
+}
+
 // SetTheme changes the active theme to the one with the specified name.
 // Returns an error if the theme doesn't exist.
 func SetTheme(name string) error {
@@ -0,0 +1,27 @@
+package theme
+
+// Text markers used alongside color by themes that enable markers, so meaning
+// is not conveyed by color alone
+const (
+	MarkerError   = "[ERROR]"
+	MarkerWarning = "[WARN]"
+	MarkerSuccess = "[OK]"
+	MarkerInfo    = "[INFO]"
+	MarkerTool    = "[TOOL]"
+	MarkerUser    = ">>"
+)
+
+// CurrentThemeUsesMarkers returns true if the current theme uses text markers
+func CurrentThemeUsesMarkers() bool {
+	t := CurrentTheme()
+	return t != nil && t.Markers()
+}
+
+// Marker returns the marker followed by a space if the current theme uses
+// markers, or an empty string otherwise
+func Marker(marker string) string {
+	if !CurrentThemeUsesMarkers() {
+		return ""
+	}
+	return marker + " "
+}
@@ -10,6 +10,10 @@ import (
 type Theme interface {
 	Name() string
 
+	// Markers reports whether the theme conveys meaning with text markers
+	// such as [ERROR] instead of relying on color alone
+	Markers() bool
+
 	// Background colors
 	Background() compat.AdaptiveColor        // Radix 1
 	BackgroundPanel() compat.AdaptiveColor   // Radix 2
@@ -80,6 +84,9 @@ type Theme interface {
 // BaseTheme provides a default implementation of the Theme interface
 // that can be embedded in concrete theme implementations.
 type BaseTheme struct {
+	// Use text markers in addition to color
+	MarkersEnabled bool
+
 	// Background colors
 	BackgroundColor        compat.AdaptiveColor
 	BackgroundPanelColor   compat.AdaptiveColor
@@ -148,6 +155,8 @@ type BaseTheme struct {
 }
 
 // Implement the Theme interface for BaseTheme
+func (t *BaseTheme) Markers() bool { return t.MarkersEnabled }
+
 func (t *BaseTheme) Primary() compat.AdaptiveColor   { return t.PrimaryColor }
 func (t *BaseTheme) Secondary() compat.AdaptiveColor { return t.SecondaryColor }
 func (t *BaseTheme) Accent() compat.AdaptiveColor    { return t.AccentColor }
@@ -0,0 +1,87 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the events of an editor saving a file
+const watchDebounce = 200 * time.Millisecond
+
+// Watcher reloads the user themes when their files change
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	dirs    []string
+}
+
+// NewWatcher watches the user theme directories, and the parents of those
+// that don't exist yet so they are picked up once created
+func NewWatcher(userConfig, projectRoot, cwd string) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		watcher: watcher,
+		dirs:    ThemeDirectories(userConfig, projectRoot, cwd),
+	}
+	for _, dir := range w.dirs {
+		if err := watcher.Add(dir); err != nil {
+			if _, err := os.Stat(filepath.Dir(dir)); err == nil {
+				watcher.Add(filepath.Dir(dir)) // Ignore error, the directory is optional
+			}
+		}
+	}
+	return w, nil
+}
+
+// Wait blocks until theme files change, reloads the themes and returns the
+// names of the changed ones. It returns false once the watcher is closed.
+func (w *Watcher) Wait() ([]string, bool) {
+	changed := []string{}
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil, false
+			}
+			if slices.Contains(w.dirs, event.Name) && event.Has(fsnotify.Create) {
+				w.watcher.Add(event.Name)
+				continue
+			}
+			if !IsThemeFile(event.Name) || !slices.Contains(w.dirs, filepath.Dir(event.Name)) {
+				continue
+			}
+			name := strings.TrimSuffix(filepath.Base(event.Name), filepath.Ext(event.Name))
+			if !slices.Contains(changed, name) {
+				changed = append(changed, name)
+			}
+			debounce = time.After(watchDebounce)
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil, false
+			}
+		case <-debounce:
+			// themes whose files were removed go away, unless another
+			// directory has one of the same name
+			for _, name := range changed {
+				unloadThemeFile(name)
+			}
+			for _, dir := range w.dirs {
+				loadThemesFromDirectory(dir)
+			}
+			return changed, true
+		}
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
@@ -0,0 +1,15 @@
+package tui
+
+import "testing"
+
+func TestTerminalTitle(t *testing.T) {
+	if got := terminalTitle("Fix the parser", ""); got != "kuuzuki: Fix the parser" {
+		t.Errorf("unexpected idle title %q", got)
+	}
+	if got := terminalTitle("Fix the parser", "working"); got != "kuuzuki: Fix the parser [working]" {
+		t.Errorf("unexpected busy title %q", got)
+	}
+	if got := (Model{}).windowTitle(); got != "kuuzuki" {
+		t.Errorf("expected the plain title without a session, got %q", got)
+	}
+}
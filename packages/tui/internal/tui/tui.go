@@ -2,11 +2,14 @@ package tui
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
@@ -14,20 +17,27 @@ import (
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 
 	opencode "github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/api"
 	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/attachment"
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/completions"
 	"github.com/sst/opencode/internal/components/chat"
 	cmdcomp "github.com/sst/opencode/internal/components/commands"
 	"github.com/sst/opencode/internal/components/dialog"
 	"github.com/sst/opencode/internal/components/fileviewer"
+	"github.com/sst/opencode/internal/components/list"
 	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/scratchpad"
+	"github.com/sst/opencode/internal/components/shell"
 	"github.com/sst/opencode/internal/components/status"
 	"github.com/sst/opencode/internal/components/toast"
 	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/recording"
+	"github.com/sst/opencode/internal/snapshot"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
@@ -42,6 +52,38 @@ type ExitDebounceTimeoutMsg struct{}
 // FocusDetectionTimeoutMsg is sent when focus detection timeout expires
 type FocusDetectionTimeoutMsg struct{}
 
+// KeyboardDetectionTimeoutMsg is sent once the terminal had time to report
+// its keyboard enhancements
+type KeyboardDetectionTimeoutMsg struct{}
+
+// WhichKeyTimeoutMsg is sent once the leader key has been held back long
+// enough to show the keys that can follow it
+type WhichKeyTimeoutMsg struct {
+	Press int
+}
+
+// RunLimitTickMsg is sent periodically while a turn runs with a time limit
+type RunLimitTickMsg struct{}
+
+// SnapshotCreatedMsg is sent once a workspace snapshot requested from a tool
+// approval has been saved
+type SnapshotCreatedMsg struct {
+	ApprovalID string
+	Err        error
+}
+
+// RemoteApprovalShownMsg is sent once an approval answered remotely has
+// been shown long enough
+type RemoteApprovalShownMsg struct {
+	ApprovalID string
+}
+
+// SnapshotRestoredMsg is sent once a workspace snapshot has been restored
+type SnapshotRestoredMsg struct {
+	Snapshot snapshot.Snapshot
+	Err      error
+}
+
 // InterruptKeyState tracks the state of interrupt key presses for debouncing
 type InterruptKeyState int
 
@@ -60,20 +102,50 @@ const (
 
 const interruptDebounceTimeout = 1 * time.Second
 const exitDebounceTimeout = 1 * time.Second
+const remoteApprovalTimeout = 2 * time.Second
 const focusDetectionTimeout = 3 * time.Second
+const keyboardDetectionTimeout = 3 * time.Second
+const whichKeyDelay = 400 * time.Millisecond
+const runLimitTickInterval = 1 * time.Second
+
+// themesReloadedMsg is sent when user theme files changed and were reloaded
+type themesReloadedMsg struct {
+	Names []string
+}
+
+// watchThemes waits for user theme files to change
+func (a Model) watchThemes() tea.Cmd {
+	if a.themeWatcher == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		names, ok := a.themeWatcher.Wait()
+		if !ok {
+			return nil
+		}
+		return themesReloadedMsg{Names: names}
+	}
+}
+
+// providerKeyInput prefixes the ID of the text input asking for a provider's
+// API key
+const providerKeyInput = "provider-key:"
+
+// serverInput prefixes the ID of a text input the server asked for, answered
+// through the server
+const serverInput = "input:"
 
 type Model struct {
-	width, height        int
-	app                  *app.App
-	modal                layout.Modal
-	status               status.StatusComponent
-	editor               chat.EditorComponent
-	messages             chat.MessagesComponent
-	completions          dialog.CompletionDialog
-	commandProvider      completions.CompletionProvider
-	fileProvider         completions.CompletionProvider
-	symbolsProvider      completions.CompletionProvider
-	agentsProvider       completions.CompletionProvider
+	width, height int
+	app           *app.App
+	modal         layout.Modal
+	status        status.StatusComponent
+	editor        chat.EditorComponent
+	messages      chat.MessagesComponent
+	completions   dialog.CompletionDialog
+	fileProvider  completions.CompletionProvider
+	// triggers are the completions opened by typing their character
+	triggers             map[string]completions.Trigger
 	showCompletionDialog bool
 	leaderBinding        *key.Binding
 	// isLeaderSequence     bool
@@ -82,13 +154,69 @@ type Model struct {
 	exitKeyState        ExitKeyState
 	messagesRight       bool
 	fileViewer          fileviewer.Model
+	scratchpad          scratchpad.Model
+	shell               shell.Model
 	pendingConfirmation *chat.ConfirmationMsg
 	activeConfirmation  *chat.ConfirmationMessage
-	activeToolApproval  *chat.ToolApprovalMessage
+	activeChoice        *chat.ChoiceMessage
+	// Questions waiting behind the active choice
+	queuedChoices      []chat.ChoiceMsg
+	activeToolApproval *chat.ToolApprovalMessage
+	// Permission requests waiting behind the active approval
+	queuedToolApprovals []chat.ToolApprovalMsg
 	activeTextInput     *chat.TextInputMessage
+	// Text inputs waiting behind the active one
+	queuedTextInputs []chat.TextInputMsg
+	// Terminal title last set
+	title string
+	// Clickable parts of the last rendered screen
+	zones *layout.Zones
+	// Set once the messages are clicked, the scrolling keys then move them
+	messagesFocused bool
+	// Commands run lately, for repeating them
+	commandHistory commands.History
+	// ID of the user message whose turn was paused by a run limit
+	pausedTurnID string
+	// ID of the session last warned about a full context window
+	contextWarnedSession string
+	// Fallback models tried for the latest prompt, and the one offered next
+	fallbackTried   map[string]bool
+	pendingFallback *app.Fallback
+	// Message to drop from the context once confirmed
+	pendingDrop string
+	// Diff pasted into the editor, waiting to know what to do with it
+	pendingPatch string
+	// Actions of a toast, waiting to know which one to run
+	pendingToastActions []toast.Action
+	// Audio file dropped into the editor, waiting to be attached or
+	// transcribed
+	pendingAudio chat.AudioPastedMsg
+	// File last opened from the review of the session's changes
+	reviewPath string
+	// Budget warnings already shown, by budget and session or day
+	budgetWarned map[string]bool
+	// Day the cost of other sessions was last loaded for the daily budget
+	budgetDay string
+	// Prompt held back by a spent budget, and whether sending it was confirmed
+	budgetPrompt    *app.Prompt
+	budgetConfirmed bool
+	// Reloads user theme files as they change
+	themeWatcher *theme.Watcher
+	// Leader key presses so far, and whether the keys that can follow the
+	// latest one are shown
+	leaderPresses int
+	showWhichKey  bool
+	// Key press to render latency, shown in the performance overlay
+	latency         *util.LatencyTracker
+	showPerformance bool
+	// Files pinned to the session, cached for the sidebar
+	pinnedFiles   []app.PinnedFile
+	pinnedSession string
 	// Focus state tracking for multi-instance drag-and-drop filtering
 	hasFocus       bool
 	focusSupported bool
+	// Prints the conversation as plain lines when inline
+	announcer app.Announcer
 }
 
 func (a Model) Init() tea.Cmd {
@@ -106,14 +234,27 @@ func (a Model) Init() tea.Cmd {
 	cmds = append(cmds, tea.Tick(focusDetectionTimeout, func(time.Time) tea.Msg {
 		return FocusDetectionTimeoutMsg{}
 	}))
+	cmds = append(cmds, tea.Tick(keyboardDetectionTimeout, func(time.Time) tea.Msg {
+		return KeyboardDetectionTimeoutMsg{}
+	}))
 
 	cmds = append(cmds, a.app.InitializeProvider())
+	cmds = append(cmds, a.app.LoadDailySpend())
 	cmds = append(cmds, a.editor.Init())
 	cmds = append(cmds, a.messages.Init())
 	cmds = append(cmds, a.status.Init())
 	cmds = append(cmds, a.completions.Init())
 	cmds = append(cmds, a.toastManager.Init())
 	cmds = append(cmds, a.fileViewer.Init())
+	cmds = append(cmds, a.watchThemes())
+
+	if a.app.Config.Tui.SessionRetention.PruneOnStartup {
+		cmds = append(cmds, a.pruneExpiredSessions())
+	}
+
+	if a.app.ShouldShowLauncher() {
+		cmds = append(cmds, util.CmdHandler(commands.ExecuteCommandMsg(a.app.Commands[commands.ProjectListCommand])))
+	}
 
 	// Check if we should show the init dialog
 	cmds = append(cmds, func() tea.Msg {
@@ -124,7 +265,24 @@ func (a Model) Init() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// Update handles the message, then retitles the terminal when the session or
+// its state changed
 func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := a.update(msg)
+	model, ok := updated.(Model)
+	if !ok {
+		return updated, cmd
+	}
+	model.app.Keymap = model.keymap()
+	title := model.windowTitle()
+	if title == model.title {
+		return model, cmd
+	}
+	model.title = title
+	return model, tea.Batch(cmd, tea.SetWindowTitle(title))
+}
+
+func (a Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	measure := util.Measure("app.Update")
 	defer measure("from", fmt.Sprintf("%T", msg))
 
@@ -134,6 +292,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
 		keyString := msg.String()
+		a.latency.KeyPressed(keyString)
 
 		// 1. Handle active modal
 		if a.modal != nil {
@@ -167,6 +326,16 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 
+		// Handle active choice
+		if a.activeChoice != nil {
+			updated, cmd := a.activeChoice.Update(msg)
+			a.activeChoice = updated
+			if cmd != nil {
+				return a, cmd
+			}
+			return a, nil
+		}
+
 		// Handle active tool approval
 		if a.activeToolApproval != nil {
 			updated, cmd := a.activeToolApproval.Update(msg)
@@ -189,43 +358,97 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// 2. Check for commands that require leader
 		if a.app.IsLeaderSequence {
-			matches := a.app.Commands.Matches(msg, a.app.IsLeaderSequence)
+			matches := a.app.Commands.Matches(msg, a.app.IsLeaderSequence, a.keymap())
 			a.app.IsLeaderSequence = false
+			a.showWhichKey = false
 			if len(matches) > 0 {
 				return a, util.CmdHandler(commands.ExecuteCommandsMsg(matches))
 			}
 		}
 
-		// 3. Handle completions trigger
-		if keyString == "/" &&
-			!a.showCompletionDialog &&
-			a.editor.Value() == "" {
-			a.showCompletionDialog = true
+		// Route keys to the scratchpad while it has focus, keeping the leader key
+		// available so commands can still toggle it
+		if a.scratchpad.Focused() {
+			if a.leaderBinding != nil && key.Matches(msg, *a.leaderBinding) {
+				return a, a.startLeaderSequence()
+			}
+			a.scratchpad, cmd = a.scratchpad.Update(msg)
+			if !a.scratchpad.Focused() {
+				updated, focusCmd := a.editor.Focus()
+				a.editor = updated.(chat.EditorComponent)
+				return a, tea.Batch(cmd, focusCmd)
+			}
+			return a, cmd
+		}
 
-			updated, cmd := a.editor.Update(msg)
-			a.editor = updated.(chat.EditorComponent)
-			cmds = append(cmds, cmd)
+		// Route keys to the shell output while it has focus, the same way
+		if a.shell.Focused() {
+			if a.leaderBinding != nil && key.Matches(msg, *a.leaderBinding) {
+				return a, a.startLeaderSequence()
+			}
+			a.shell, cmd = a.shell.Update(msg)
+			if !a.shell.Focused() {
+				updated, focusCmd := a.editor.Focus()
+				a.editor = updated.(chat.EditorComponent)
+				return a, tea.Batch(cmd, focusCmd)
+			}
+			return a, cmd
+		}
 
-			// Set command provider for command completion
-			a.completions = dialog.NewCompletionDialogComponent("/", a.commandProvider)
-			updated, cmd = a.completions.Update(msg)
-			a.completions = updated.(dialog.CompletionDialog)
-			cmds = append(cmds, cmd)
+		// Keys run the file viewer's keymap while its pane has focus, other
+		// keys their global keybind, and esc hands focus back to the editor.
+		// Its find and go to line prompts take every key.
+		if a.fileViewer.Focused() {
+			if a.fileViewer.Prompting() {
+				a.fileViewer, cmd = a.fileViewer.Update(msg)
+				return a, cmd
+			}
+			if a.leaderBinding != nil && key.Matches(msg, *a.leaderBinding) {
+				return a, a.startLeaderSequence()
+			}
+			if keyString == "esc" {
+				return a, a.focusFileViewer(false)
+			}
+			if matches := a.app.Commands.Matches(msg, false, commands.KeymapFileViewer); len(matches) > 0 {
+				return a, util.CmdHandler(commands.ExecuteCommandsMsg(matches))
+			}
+			return a, nil
+		}
 
-			return a, tea.Sequence(cmds...)
+		// Keys run the messages' keymap while they have focus, v selects lines
+		// to copy or revert and any other key hands focus back to the editor
+		if a.messagesFocused && !a.editor.Focused() {
+			if keyString == "v" || a.messages.Selecting() {
+				updated, cmd := a.messages.Update(msg)
+				a.messages = updated.(chat.MessagesComponent)
+				return a, cmd
+			}
+			if keyString == "esc" {
+				return a, a.focusMessages(false)
+			}
+			if matches := a.keymapMatches(msg, commands.KeymapMessages); len(matches) > 0 {
+				return a, util.CmdHandler(commands.ExecuteCommandsMsg(matches))
+			}
+			matches := a.app.Commands.Matches(msg, false, commands.KeymapMessages)
+			if len(matches) > 0 && scrollsMessages[matches[0].Name] {
+				return a, util.CmdHandler(commands.ExecuteCommandsMsg(matches))
+			}
+			cmds = append(cmds, a.focusMessages(false))
 		}
 
-		// Handle file completions trigger
-		if keyString == "@" &&
-			!a.showCompletionDialog {
+		// 3. Handle completions triggers, shell mode takes them as typed
+		if trigger, ok := a.triggers[keyString]; ok &&
+			!a.showCompletionDialog &&
+			!a.editor.ShellMode() &&
+			trigger.Opens(a.editor.Value()) {
 			a.showCompletionDialog = true
 
 			updated, cmd := a.editor.Update(msg)
 			a.editor = updated.(chat.EditorComponent)
 			cmds = append(cmds, cmd)
 
-			// Set file, symbols, and agents providers for @ completion
-			a.completions = dialog.NewCompletionDialogComponent("@", a.fileProvider, a.symbolsProvider, a.agentsProvider)
+			a.completions = dialog.NewCompletionDialogComponent(keyString, trigger.Providers...)
+			a.completions.SetSpaces(trigger.Spaces)
 			updated, cmd = a.completions.Update(msg)
 			a.completions = updated.(dialog.CompletionDialog)
 			cmds = append(cmds, cmd)
@@ -233,6 +456,16 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tea.Sequence(cmds...)
 		}
 
+		// a leading @model:<name> names a model, not a file to complete
+		if a.showCompletionDialog && app.TypingModelOverride(a.editor.Value()) {
+			a.showCompletionDialog = false
+		}
+
+		// enter sends the prompt or runs the command when nothing matches
+		if a.showCompletionDialog && keyString == "enter" && a.completions.IsEmpty() {
+			a.showCompletionDialog = false
+		}
+
 		if a.showCompletionDialog {
 			switch keyString {
 			case "tab", "enter", "esc", "ctrl+c", "up", "down", "ctrl+p", "ctrl+n":
@@ -245,6 +478,10 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			updated, cmd := a.editor.Update(msg)
 			a.editor = updated.(chat.EditorComponent)
 			cmds = append(cmds, cmd)
+			if app.TypingModelOverride(a.editor.Value()) {
+				a.showCompletionDialog = false
+				return a, tea.Batch(cmds...)
+			}
 
 			updated, cmd = a.completions.Update(msg)
 			a.completions = updated.(dialog.CompletionDialog)
@@ -265,8 +502,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.leaderBinding != nil &&
 			!a.app.IsLeaderSequence &&
 			key.Matches(msg, *a.leaderBinding) {
-			a.app.IsLeaderSequence = true
-			return a, nil
+			return a, a.startLeaderSequence()
 		}
 
 		// 6 Handle input clear command
@@ -320,7 +556,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// 9. Check again for commands that don't require leader (excluding interrupt when busy and exit when in debounce)
-		matches := a.app.Commands.Matches(msg, a.app.IsLeaderSequence)
+		matches := a.app.Commands.Matches(msg, a.app.IsLeaderSequence, a.keymap())
 		if len(matches) > 0 {
 			// Skip interrupt key if we're in debounce mode and app is busy (but not for ESC which interrupts immediately)
 			if interruptCommand.Matches(msg, a.app.IsLeaderSequence) && a.app.IsBusy() && a.interruptKeyState != InterruptKeyIdle && msg.String() != "esc" {
@@ -338,6 +574,10 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		updatedEditor, cmd := a.editor.Update(msg)
 		a.editor = updatedEditor.(chat.EditorComponent)
 		return a, cmd
+	case tea.MouseClickMsg:
+		if cmd, handled := a.click(msg); handled {
+			return a, cmd
+		}
 	case tea.MouseWheelMsg:
 		if a.modal != nil {
 			u, cmd := a.modal.Update(msg)
@@ -372,7 +612,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		slog.Debug("TUI gained focus - drag-and-drop enabled")
 
 		// Enhanced focus management - ensure editor gets focus when TUI gains focus
-		if a.modal == nil && a.activeConfirmation == nil && a.activeToolApproval == nil && a.activeTextInput == nil {
+		if a.modal == nil && a.activeConfirmation == nil && a.activeChoice == nil && a.activeToolApproval == nil && a.activeTextInput == nil {
 			updated, cmd := a.editor.Focus()
 			a.editor = updated.(chat.EditorComponent)
 			return a, cmd
@@ -396,10 +636,14 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmd = a.modal.Close()
 		}
 		a.modal = nil
+		// dialogs like the context inspector can unpin files
+		a.refreshPinnedFiles()
 		return a, cmd
 	case commands.ExecuteCommandMsg:
 		updated, cmd := a.executeCommand(commands.Command(msg))
 		return updated, cmd
+	case commands.CommandExecutedMsg:
+		a.commandHistory.Add(msg.Name)
 	case commands.ExecuteCommandsMsg:
 		for _, command := range msg {
 			updated, cmd := a.executeCommand(command)
@@ -411,13 +655,45 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, toast.NewErrorToast(msg.Error())
 	case app.SendPrompt:
 		a.showCompletionDialog = false
+		if a.app.ReadOnly {
+			return a, toast.NewInfoToast(app.ReadOnlyMessage)
+		}
+		if cmd := a.holdForBudget(msg); cmd != nil {
+			return a, cmd
+		}
+		if msg.ModelID == "" {
+			a.fallbackTried = nil
+		}
 		a.app, cmd = a.app.SendPrompt(context.Background(), msg)
 		cmds = append(cmds, cmd)
+		a.shell.Hide()
+		a.refreshPinnedFiles()
+		if a.app.RunLimits().MaxSeconds > 0 {
+			cmds = append(cmds, runLimitTick())
+		}
+	case RunLimitTickMsg:
+		cmds = append(cmds, a.checkRunLimits())
+		if a.app.TurnInProgress() && a.app.RunLimits().MaxSeconds > 0 {
+			cmds = append(cmds, runLimitTick())
+		}
 	case app.ExecuteShellCommand:
 		a.showCompletionDialog = false
+		if a.app.ReadOnly {
+			return a, toast.NewInfoToast(app.ReadOnlyMessage)
+		}
+		// like prompts, the first command starts the session
+		if msg.SessionID == "" {
+			session, err := a.app.CreateSession(context.Background())
+			if err != nil {
+				return a, toast.NewErrorToast(err.Error())
+			}
+			a.app.Session = session
+			msg.SessionID = session.ID
+			cmds = append(cmds, util.CmdHandler(app.SessionCreatedMsg{Session: session}))
+		}
 		// Execute shell command asynchronously
 		cmds = append(cmds, func() tea.Msg {
-			_, err := a.app.ExecuteShellCommand(context.Background(), msg.SessionID, msg.Command)
+			_, err := a.app.ExecuteShellCommand(context.Background(), msg.SessionID, msg.Command, msg.Persistent)
 			if err != nil {
 				return toast.NewErrorToast(fmt.Sprintf("Shell command failed: %v", err))
 			}
@@ -434,10 +710,35 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case chat.AttachmentInsertedMsg:
 		// Close completion dialog when the editor inserts an attachment
 		a.showCompletionDialog = false
+	case chat.AudioPastedMsg:
+		a.pendingAudio = msg
+		cmds = append(cmds, util.CmdHandler(chat.ChoiceMsg{
+			ID:       "audio",
+			Question: "Attach " + filepath.Base(msg.Path) + "?",
+			Choices: []chat.Choice{
+				{Key: "a", Label: "Attach", Description: "Send the audio to the model"},
+				{Key: "t", Label: "Transcribe", Description: "Write what it says into the prompt"},
+			},
+		}))
+	case transcribedMsg:
+		a.editor.InsertText(msg.text)
+	case chat.PatchPastedMsg:
+		a.pendingPatch = msg.Text
+		cmds = append(cmds, util.CmdHandler(chat.ChoiceMsg{
+			ID:       "patch",
+			Question: "The pasted text is a diff",
+			Choices: []chat.Choice{
+				{Key: "a", Label: "Attach", Description: "Add it to the prompt as an attachment"},
+				{Key: "p", Label: "Apply", Description: "Apply it to the working tree"},
+				{Key: "i", Label: "Insert as text"},
+			},
+		}))
 	case opencode.EventListResponseEventInstallationUpdated:
 		return a, toast.NewSuccessToast(
 			"kuuzuki updated to "+msg.Properties.Version+", restart to apply.",
 			toast.WithTitle("New version installed"),
+			toast.WithAction("Restart now", restartMsg{}),
+			toast.WithDuration(15*time.Second),
 		)
 	case opencode.EventListResponseEventIdeInstalled:
 		return a, toast.NewSuccessToast(
@@ -447,12 +748,17 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case opencode.EventListResponseEventSessionDeleted:
 		if a.app.Session != nil && msg.Properties.Info.ID == a.app.Session.ID {
 			a.app.Session = &opencode.Session{}
-			a.app.Messages = []app.Message{}
+			a.app.SetMessages([]app.Message{})
 		}
 		return a, toast.NewSuccessToast("Session deleted successfully")
 	case opencode.EventListResponseEventSessionUpdated:
 		if msg.Properties.Info.ID == a.app.Session.ID {
 			a.app.Session = &msg.Properties.Info
+			// pins may be changed by another client
+			a.refreshPinnedFiles()
+			if a.app.RecordProject() {
+				cmds = append(cmds, a.app.SaveState())
+			}
 		}
 	case opencode.EventListResponseEventMessagePartUpdated:
 		slog.Info("message part updated", "message", msg.Properties.Part.MessageID, "part", msg.Properties.Part.ID)
@@ -490,7 +796,13 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					message.Parts = append(message.Parts, msg.Properties.Part.AsUnion())
 				}
 				a.app.Messages[messageIndex] = message
+				role := opencode.MessageRoleUser
+				if _, ok := message.Info.(opencode.AssistantMessage); ok {
+					role = opencode.MessageRoleAssistant
+				}
+				cmds = append(cmds, a.announce(a.announcer.Part(msg.Properties.Part.AsUnion(), role)...))
 			}
+			cmds = append(cmds, a.checkRunLimits())
 		}
 	case opencode.EventListResponseEventMessageUpdated:
 		if msg.Properties.Info.SessionID == a.app.Session.ID {
@@ -512,30 +824,105 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-			if matchIndex == -1 {
+			// evicted messages are finished, late updates to them are dropped
+			evicted := a.app.Evicted.Count > 0 && len(a.app.Messages) > 0 &&
+				msg.Properties.Info.ID < a.app.Messages[0].ID()
+			if matchIndex == -1 && !evicted {
 				a.app.Messages = append(a.app.Messages, app.Message{
 					Info:  msg.Properties.Info.AsUnion(),
 					Parts: []opencode.PartUnion{},
 				})
+				a.app.EvictMessages()
 			}
+			cmds = append(cmds, a.checkRunLimits())
+			cmds = append(cmds, a.checkContextUsage())
+			cmds = append(cmds, a.checkBudget())
+		}
+	case opencode.EventListResponseEventSessionIdle:
+		if msg.Properties.SessionID == a.app.Session.ID {
+			cmds = append(cmds, a.notifyComplete(), a.announce("Done."))
 		}
 	case opencode.EventListResponseEventSessionError:
+		if msg.Properties.SessionID == a.app.Session.ID {
+			if _, aborted := msg.Properties.Error.AsUnion().(opencode.MessageAbortedError); !aborted && msg.Properties.Error.Name != "" {
+				cmds = append(cmds, a.notify(app.NotifyError, "Request failed in "+a.app.Session.Title))
+			}
+		}
 		switch err := msg.Properties.Error.AsUnion().(type) {
 		case nil:
 		case opencode.ProviderAuthError:
 			slog.Error("Failed to authenticate with provider", "error", err.Data.Message)
-			return a, toast.NewErrorToast("Provider error: " + err.Data.Message)
+			if msg.Properties.SessionID == a.app.Session.ID {
+				if cmd := a.offerFallback("authentication failed", err.Data.ProviderID); cmd != nil {
+					return a, tea.Batch(append(cmds, toast.NewErrorToast("Provider error: "+err.Data.Message), cmd)...)
+				}
+			}
+			return a, tea.Batch(append(cmds, toast.NewErrorToast(
+				"Provider error: "+err.Data.Message+" (/auth to update the API key)",
+				toast.WithAction("Update API key", commands.ExecuteCommandMsg{Name: commands.ProviderAuthCommand}),
+			))...)
 		case opencode.UnknownError:
 			slog.Error("Server error", "name", err.Name, "message", err.Data.Message)
-			return a, toast.NewErrorToast(err.Data.Message, toast.WithTitle(string(err.Name)))
+			if msg.Properties.SessionID == a.app.Session.ID && app.IsRateLimitError(int(err.Data.StatusCode), err.Data.Message) {
+				if cmd := a.offerFallback("rate limited", ""); cmd != nil {
+					return a, tea.Batch(append(cmds, toast.NewErrorToast(err.Data.Message, toast.WithTitle(string(err.Name))), cmd)...)
+				}
+			}
+			options := []toast.ToastOption{toast.WithTitle(string(err.Name))}
+			if msg.Properties.SessionID == a.app.Session.ID {
+				options = append(options, toast.WithAction("Retry", commands.ExecuteCommandMsg{Name: commands.MessagesRetryCommand}))
+			}
+			return a, tea.Batch(append(cmds, toast.NewErrorToast(err.Data.Message, options...))...)
 		}
 	case opencode.EventListResponseEventFileWatcherUpdated:
-		if a.fileViewer.HasFile() {
-			if a.fileViewer.Filename() == msg.Properties.File {
-				return a.openFile(msg.Properties.File)
+		if a.app.IsFilePinned(msg.Properties.File) {
+			a.refreshPinnedFiles()
+		}
+		// no early return, the status bar refreshes the git dirty state below
+		if a.fileViewer.HasFile() && a.fileViewer.Filename() == msg.Properties.File {
+			if a.following() {
+				cmds = append(cmds, a.followFile(msg.Properties.File))
+			} else {
+				updated, cmd := a.openFile(msg.Properties.File)
+				a = updated.(Model)
+				cmds = append(cmds, cmd)
 			}
 		}
+	case opencode.EventListResponseEventFileEdited:
+		path := util.Relative(msg.Properties.File)
+		touch := a.app.TouchFile(path)
+		// edits by other sessions on the server are left to their own TUI
+		if a.following() && msg.Properties.SessionID == a.app.Session.ID {
+			return a, tea.Batch(touch, a.followFile(path))
+		}
+		cmds = append(cmds, touch)
+	case fileFollowedMsg:
+		if !a.following() {
+			return a, nil
+		}
+		if msg.change == nil {
+			return a.openFile(msg.path)
+		}
+		var cmd tea.Cmd
+		a.fileViewer, cmd = a.fileViewer.SetFile(msg.change.Path, msg.change.Patch, true)
+		return a, tea.Batch(cmd, a.resizePanes())
 	case opencode.EventListResponseEventPermissionUpdated:
+		// an observer cannot approve, the request of the session it watches
+		// is rejected right away, others are left to their own TUI. A replayed
+		// request was answered when it was recorded.
+		if a.app.ReadOnly {
+			if !a.app.Replaying && msg.Properties.SessionID == a.app.Session.ID {
+				cmds = append(cmds,
+					a.respondPermission(msg.Properties.SessionID, chat.ToolApprovalAnswerMsg{ID: msg.Properties.ID}),
+					toast.NewInfoToast("Rejected "+msg.Properties.Title+", tools cannot be approved in read-only mode"),
+				)
+			}
+			break
+		}
+		cmds = append(cmds,
+			a.notify(app.NotifyPermission, msg.Properties.Title+" needs approval"),
+			a.announce("Permission required: "+msg.Properties.Title),
+		)
 		// Convert permission event to tool approval message
 		cmds = append(cmds, func() tea.Msg {
 			return chat.ToolApprovalMsg{
@@ -543,8 +930,51 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				ToolName:    msg.Properties.Title,
 				Description: "Permission requested",
 				Metadata:    msg.Properties.Metadata,
+				Type:        msg.Properties.Type,
+				Pattern:     msg.Properties.Pattern,
+				SessionID:   msg.Properties.SessionID,
+			}
+		})
+	case opencode.EventListResponseEventPermissionReplied:
+		// answered elsewhere, by an "always" answer covering it or by timing out
+		a.queuedToolApprovals = slices.DeleteFunc(a.queuedToolApprovals, func(queued chat.ToolApprovalMsg) bool {
+			return queued.ID == msg.Properties.PermissionID
+		})
+		if a.activeToolApproval != nil && a.activeToolApproval.ID == msg.Properties.PermissionID {
+			if msg.Properties.Source != opencode.EventListResponseEventPermissionRepliedPropertiesSourceRemote {
+				a.nextToolApproval()
+				break
 			}
+			// show the remote answer for a moment before moving on
+			approval := a.activeToolApproval
+			approval.Answered = true
+			approval.Approved = msg.Properties.Response != "reject"
+			approval.Remote = true
+			cmds = append(cmds, tea.Tick(remoteApprovalTimeout, func(time.Time) tea.Msg {
+				return RemoteApprovalShownMsg{ApprovalID: approval.ID}
+			}))
+		} else if a.activeToolApproval != nil {
+			a.activeToolApproval.Queued = len(a.queuedToolApprovals)
+		}
+	case opencode.EventListResponseEventTuiInputRequested:
+		if msg.Properties.SessionID != "" && msg.Properties.SessionID != a.app.Session.ID {
+			break
+		}
+		cmds = append(cmds, util.CmdHandler(chat.TextInputMsg{
+			ID:          serverInput + msg.Properties.ID,
+			Prompt:      msg.Properties.Prompt,
+			Placeholder: msg.Properties.Placeholder,
+			Masked:      msg.Properties.Masked,
+		}))
+	case opencode.EventListResponseEventTuiInputReplied:
+		// answered in another TUI, cancelled by the caller or timed out
+		id := serverInput + msg.Properties.ID
+		a.queuedTextInputs = slices.DeleteFunc(a.queuedTextInputs, func(queued chat.TextInputMsg) bool {
+			return queued.ID == id
 		})
+		if a.activeTextInput != nil && a.activeTextInput.ID == id && !a.activeTextInput.Submitted {
+			a.nextTextInput()
+		}
 	case tea.WindowSizeMsg:
 		msg.Height -= 2 // Make space for the status bar
 		a.width, a.height = msg.Width, msg.Height
@@ -565,15 +995,62 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, toast.NewErrorToast("Failed to open session")
 		}
 		a.app.Session = msg
-		a.app.Messages = messages
+		a.app.SetMessages(messages)
+		a.app.PendingSystem = ""
+		a.app.PendingPins = nil
+		cmds = append(cmds, a.announce(append([]string{"Session: " + msg.Title}, a.announcer.Messages(a.app.Messages)...)...))
+		a.refreshPinnedFiles()
+		a.budgetDay = ""
+		cmds = append(cmds, a.checkBudget())
+		if a.app.RecordProject() {
+			cmds = append(cmds, a.app.SaveState())
+		}
+		return a, tea.Batch(append(cmds, util.CmdHandler(app.SessionLoadedMsg{}))...)
+	case status.ServerConnectionMsg:
+		// the events missed while the stream was down are fetched afresh
+		if msg.State == status.ConnectionConnected && msg.Reconnected {
+			cmds = append(cmds, a.app.ResyncSession())
+		}
+	case app.SessionClearedMsg:
+		// the previous session now counts towards today's other sessions
+		a.budgetDay = ""
+		cmds = append(cmds, a.checkBudget())
+	case recording.SessionMsg:
+		// a replayed session is shown from its recorded events alone
+		session := msg.Session
+		a.app.Session = &session
+		a.app.SetMessages(nil)
 		return a, util.CmdHandler(app.SessionLoadedMsg{})
+	case recording.FinishedMsg:
+		return a, toast.NewInfoToast("Replay finished")
 	case app.SessionCreatedMsg:
 		a.app.Session = msg.Session
-		return a, util.CmdHandler(app.SessionLoadedMsg{})
+		a.scratchpad, cmd = a.scratchpad.Update(msg)
+		return a, tea.Batch(cmd, util.CmdHandler(app.SessionLoadedMsg{}))
+	case dialog.AttachFileMsg:
+		a.editor.AttachFile(msg.FilePath)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+	case scratchpad.AttachMsg:
+		a.editor.AttachText("scratchpad", msg.Text)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+	case shell.AttachMsg:
+		a.editor.AttachText("output", msg.Text)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
 	case app.MessageRevertedMsg:
 		if msg.Session.ID == a.app.Session.ID {
 			a.app.Session = &msg.Session
 		}
+	case app.DailySpendLoadedMsg:
+		a.app.SetDailySpend(msg)
+		return a, a.checkBudget()
+	case app.ProvidersLoadedMsg:
+		return a, a.app.SetProviders(msg.Response)
 	case app.ModelSelectedMsg:
 		a.app.Provider = &msg.Provider
 		a.app.Model = &msg.Model
@@ -584,12 +1061,28 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.app.State.UpdateModelUsage(msg.Provider.ID, msg.Model.ID)
 		cmds = append(cmds, a.app.SaveState())
 	case dialog.ThemeSelectedMsg:
-		a.app.State.Theme = msg.ThemeName
-		cmds = append(cmds, a.app.SaveState())
+		if !msg.Preview {
+			a.app.State.Theme = msg.ThemeName
+			cmds = append(cmds, a.app.SaveState())
+		}
+	case themesReloadedMsg:
+		// a deleted theme is replaced by the default one, which is shown
+		// in its place until another is picked
+		current := theme.CurrentThemeName()
+		if slices.Contains(msg.Names, current) || slices.Contains(msg.Names, a.app.State.Theme) {
+			theme.SetTheme(current)
+			cmds = append(cmds, util.CmdHandler(dialog.ThemeSelectedMsg{ThemeName: current, Preview: true}))
+		}
+		cmds = append(cmds, a.watchThemes())
 	case toast.ShowToastMsg:
 		tm, cmd := a.toastManager.Update(msg)
 		a.toastManager = tm
 		cmds = append(cmds, cmd)
+		// inline, notifications are printed rather than overlaid, and kept
+		// for the notifications and their actions only
+		if a.app.Inline {
+			cmds = append(cmds, a.announce(msg.Plain()))
+		}
 	case toast.DismissToastMsg:
 		tm, cmd := a.toastManager.Update(msg)
 		a.toastManager = tm
@@ -602,6 +1095,15 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Reset exit key state after timeout
 		a.exitKeyState = ExitKeyIdle
 		a.editor.SetExitKeyInDebounce(false)
+	case tea.KeyboardEnhancementsMsg:
+		a.app.KeyDisambiguation = msg.SupportsKeyDisambiguation()
+		slog.Debug("Keyboard enhancements", "disambiguation", a.app.KeyDisambiguation)
+	case WhichKeyTimeoutMsg:
+		a.showWhichKey = a.app.IsLeaderSequence && msg.Press == a.leaderPresses
+	case KeyboardDetectionTimeoutMsg:
+		if !a.app.KeyDisambiguation {
+			return a, a.warnUnreachableKeybinds()
+		}
 	case FocusDetectionTimeoutMsg:
 		// If no focus events received within timeout, disable focus filtering
 		if !a.focusSupported {
@@ -611,7 +1113,29 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.editor.SetFocusState(a.hasFocus, a.focusSupported)
 		}
 	case dialog.FindSelectedMsg:
+		if msg.Pin {
+			return a, a.togglePinnedFile(msg.FilePath)
+		}
 		return a.openFile(msg.FilePath)
+	case dialog.GrepAttachMsg:
+		a.editor.AttachText(msg.Name, msg.Text)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		return a, cmd
+	case dialog.SymbolAttachMsg:
+		a.editor.AttachSymbol(msg.Symbol)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		return a, cmd
+	case app.FileTouchedMsg:
+		return a, a.app.RecordTouchedFile(msg)
+	case app.OpenFileMsg:
+		updated, cmd := a.openFile(msg.Path)
+		a = updated.(Model)
+		a.fileViewer.GotoLine(msg.Line)
+		return a, cmd
+	case app.EditFileMsg:
+		return a, a.editFile(msg.Path, msg.Line)
 	case dialog.ShowInitDialogMsg:
 		if msg.Show && a.app.Session == nil {
 			// Create the init dialog modal
@@ -636,57 +1160,263 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.ID == "init-project" && msg.Answer {
 			cmds = append(cmds, a.app.InitializeProject(context.Background()))
 		}
+		if msg.ID == "run-limit" {
+			if msg.Answer {
+				cmds = append(cmds, util.CmdHandler(app.SendPrompt(app.Prompt{Text: "continue"})))
+			} else {
+				cmds = append(cmds, toast.NewInfoToast("Session paused"))
+			}
+		}
+		if msg.ID == "budget" {
+			if msg.Answer && a.budgetPrompt != nil {
+				a.budgetConfirmed = true
+				cmds = append(cmds, util.CmdHandler(app.SendPrompt(*a.budgetPrompt)))
+			} else if a.budgetPrompt != nil {
+				// give the held prompt back rather than losing it
+				a.editor.RestoreFromPrompt(*a.budgetPrompt)
+			}
+			a.budgetPrompt = nil
+		}
+		if msg.ID == "compact" && msg.Answer {
+			cmds = append(cmds, util.CmdHandler(commands.ExecuteCommandMsg(a.app.Commands[commands.SessionCompactCommand])))
+		}
+		if msg.ID == "context-drop" {
+			if msg.Answer && a.pendingDrop != "" {
+				cmds = append(cmds, a.dropContext(a.pendingDrop))
+			}
+			a.pendingDrop = ""
+		}
+		if msg.ID == "model-fallback" {
+			if msg.Answer && a.pendingFallback != nil {
+				cmds = append(cmds, a.retryWithFallback(*a.pendingFallback))
+			}
+			a.pendingFallback = nil
+		}
 		a.activeConfirmation = nil
 		a.editor.Focus() // Return focus to editor
+	case chat.ChoiceMsg:
+		if a.activeChoice != nil && a.activeChoice.ID != msg.ID {
+			// a question asked again replaces the queued one, whose pending
+			// state it overwrote
+			queued := slices.IndexFunc(a.queuedChoices, func(queued chat.ChoiceMsg) bool {
+				return queued.ID == msg.ID
+			})
+			if queued >= 0 {
+				a.queuedChoices[queued] = msg
+			} else {
+				a.queuedChoices = append(a.queuedChoices, msg)
+			}
+			break
+		}
+		a.showChoice(msg)
+	case chat.ChoiceAnswerMsg:
+		if msg.ID == "sandbox" && (msg.Choice.Key == "m" || msg.Choice.Key == "d") && msg.Index >= 0 {
+			cmds = append(cmds, a.app.FinishSandbox(msg.Choice.Key == "m"))
+		}
+		if msg.ID == "audio" {
+			switch msg.Choice.Key {
+			case "a":
+				a.editor.AttachFileData(a.pendingAudio.Path)
+			case "t":
+				cmds = append(cmds, a.transcribe(a.pendingAudio))
+			}
+			a.pendingAudio = chat.AudioPastedMsg{}
+		}
+		if msg.ID == "toast-action" {
+			if msg.Index >= 0 && msg.Index < len(a.pendingToastActions) {
+				cmds = append(cmds, util.CmdHandler(a.pendingToastActions[msg.Index].Msg))
+			}
+			a.pendingToastActions = nil
+		}
+		if msg.ID == "patch" {
+			switch msg.Choice.Key {
+			case "a":
+				a.editor.AttachText("patch", a.pendingPatch)
+			case "p":
+				cmds = append(cmds, a.applyPatch(a.pendingPatch))
+			case "i":
+				a.editor.InsertText(a.pendingPatch)
+			}
+			a.pendingPatch = ""
+		}
+		if id, ok := strings.CutPrefix(msg.ID, "ask:"); ok {
+			// a question asked through the server, which waits for the answer
+			answer := map[string]any{"index": msg.Index}
+			if msg.Index >= 0 {
+				answer["choice"] = msg.Choice
+			}
+			cmds = append(cmds, func() tea.Msg {
+				if err := a.app.Client.Post(context.Background(), "/tui/control/answer/"+id, answer, nil); err != nil {
+					slog.Error("Failed to send the answer", "error", err)
+				}
+				return nil
+			})
+		}
+		a.nextChoice()
 	case chat.ToolApprovalMsg:
-		// Create a new tool approval message
-		a.activeToolApproval = chat.NewToolApprovalMessage(msg.ID, msg.ToolName, msg.Description, msg.Metadata)
-		a.editor.Blur() // Remove focus from editor
+		if a.activeToolApproval != nil {
+			// queue it behind the one being answered
+			queued := slices.ContainsFunc(a.queuedToolApprovals, func(queued chat.ToolApprovalMsg) bool {
+				return queued.ID == msg.ID
+			})
+			if a.activeToolApproval.ID != msg.ID && !queued {
+				a.queuedToolApprovals = append(a.queuedToolApprovals, msg)
+				a.activeToolApproval.Queued = len(a.queuedToolApprovals)
+			}
+			break
+		}
+		a.showToolApproval(msg)
+	case chat.ToolApprovalSnapshotMsg:
+		root := a.app.Info.Path.Cwd
+		return a, func() tea.Msg {
+			message := fmt.Sprintf("before %s: %s", msg.ToolName, msg.Description)
+			_, err := snapshot.Create(root, message)
+			return SnapshotCreatedMsg{ApprovalID: msg.ID, Err: err}
+		}
+	case RemoteApprovalShownMsg:
+		if a.activeToolApproval != nil && a.activeToolApproval.ID == msg.ApprovalID {
+			a.nextToolApproval()
+		}
+	case SnapshotCreatedMsg:
+		approval := a.activeToolApproval
+		if approval == nil || approval.ID != msg.ApprovalID {
+			approval = nil
+		}
+		if msg.Err != nil {
+			slog.Error("Failed to snapshot workspace", "error", msg.Err)
+			if approval != nil {
+				approval.Snapshot = ""
+			}
+			return a, toast.NewErrorToast("Failed to snapshot workspace: " + msg.Err.Error())
+		}
+		if approval != nil {
+			approval.Snapshot = "Workspace snapshot saved, use /restore to roll back"
+		}
+		return a, toast.NewSuccessToast("Workspace snapshot saved")
+	case dialog.NotificationSelectedMsg:
+		return a, a.runToastActions(msg.Toast)
+	case restartMsg:
+		if err := a.app.Restart(); err != nil {
+			slog.Error("Failed to restart", "error", err)
+			return a, toast.NewErrorToast(err.Error())
+		}
+		return a, tea.Quit
+	case dialog.ProjectSelectedMsg:
+		if msg.Project.Root == a.app.Info.Path.Root {
+			return a, nil
+		}
+		if err := a.app.OpenProject(msg.Project); err != nil {
+			slog.Error("Failed to open project", "root", msg.Project.Root, "error", err)
+			return a, toast.NewErrorToast(err.Error())
+		}
+		return a, tea.Quit
+	case dialog.ContextDropMsg:
+		if a.app.ReadOnly {
+			return a, toast.NewInfoToast(app.ReadOnlyMessage)
+		}
+		if a.app.IsBusy() {
+			return a, toast.NewWarningToast("Wait for the response to finish before dropping messages")
+		}
+		index := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
+			return m.ID() == msg.MessageID
+		})
+		if index < 0 {
+			return a, nil
+		}
+		a.pendingDrop = msg.MessageID
+		return a, util.CmdHandler(chat.ConfirmationMsg{
+			ID: "context-drop",
+			Question: fmt.Sprintf(
+				"Drop %d messages from the context? File changes they made are reverted too, redo brings them back.",
+				len(a.app.Messages)-index,
+			),
+		})
+	case dialog.ReviewFileSelectedMsg:
+		a.reviewPath = msg.Change.Path
+		var cmd tea.Cmd
+		a.fileViewer, cmd = a.fileViewer.SetFile(msg.Change.Path, msg.Change.Patch, true)
+		return a, tea.Batch(cmd, a.resizePanes())
+	case dialog.SnapshotSelectedMsg:
+		root := a.app.Info.Path.Cwd
+		return a, func() tea.Msg {
+			// keep the current state too, so restoring can itself be undone
+			if _, err := snapshot.Create(root, "before restoring: "+msg.Snapshot.Message); err != nil {
+				return SnapshotRestoredMsg{Snapshot: msg.Snapshot, Err: err}
+			}
+			err := snapshot.Restore(root, msg.Snapshot)
+			return SnapshotRestoredMsg{Snapshot: msg.Snapshot, Err: err}
+		}
+	case SnapshotRestoredMsg:
+		if msg.Err != nil {
+			slog.Error("Failed to restore workspace snapshot", "error", msg.Err)
+			return a, toast.NewErrorToast("Failed to restore snapshot: " + msg.Err.Error())
+		}
+		return a, toast.NewSuccessToast(
+			"Restored the workspace, its previous state was saved as a snapshot",
+			toast.WithTitle("Snapshot restored"),
+		)
 	case chat.ToolApprovalAnswerMsg:
 		// Handle tool approval response - send to server
 		if a.activeToolApproval != nil {
-			// Send permission response to server
-			go func() {
-				ctx := context.Background()
-				sessionID := a.app.Session.ID
-				permissionID := msg.ID
-
-				// Map approval to permission response
-				var response string
-				if msg.Approved {
-					response = "once" // Could be "always" for future enhancement
-				} else {
-					response = "reject"
-				}
-
-				// Make HTTP request to permission endpoint
-				url := fmt.Sprintf("/session/%s/permissions/%s", sessionID, permissionID)
-				body := map[string]interface{}{
-					"response": response,
-				}
-
-				var result bool
-				err := a.app.Client.Post(ctx, url, body, &result)
-				if err != nil {
-					slog.Error("Failed to send permission response", "error", err, "sessionID", sessionID, "permissionID", permissionID)
-				} else {
-					slog.Info("Permission response sent", "sessionID", sessionID, "permissionID", permissionID, "response", response)
+			// sub-agents ask in their own session
+			sessionID := a.activeToolApproval.SessionID
+			if sessionID == "" {
+				sessionID = a.app.Session.ID
+			}
+			cmds = append(cmds, a.respondPermission(sessionID, msg))
+		}
+
+		// Show the next queued request, or return focus to the editor
+		a.nextToolApproval()
+		if msg.Approved && msg.Response == "always" {
+			allowed := "Allowed for the rest of the session"
+			if msg.Rule != nil {
+				switch {
+				case msg.Rule.Scope == opencode.PermissionRuleScopeGlobal:
+					allowed = "Allowed in every project"
+				case msg.Rule.Pattern != "":
+					allowed = fmt.Sprintf("Allowed %s for the rest of the session", msg.Rule.Pattern)
 				}
-			}()
+			}
+			cmds = append(cmds, toast.NewInfoToast(allowed+", /permissions lists standing rules"))
 		}
-
-		// Clear the approval dialog and return focus
-		a.activeToolApproval = nil
-		a.editor.Focus() // Return focus to editor
 	case chat.TextInputMsg:
-		// Create a new text input message
-		a.activeTextInput = chat.NewTextInputMessage(msg.ID, msg.Prompt, msg.Placeholder)
-		a.editor.Blur() // Remove focus from editor
+		if a.activeTextInput != nil && !a.activeTextInput.Submitted {
+			a.queuedTextInputs = append(a.queuedTextInputs, msg)
+			break
+		}
+		a.showTextInput(msg)
 	case chat.TextInputAnswerMsg:
-		// Handle text input response
-		// TODO: Send input response to server
-		a.activeTextInput = nil
-		a.editor.Focus() // Return focus to editor
+		a.nextTextInput()
+		if inputID, ok := strings.CutPrefix(msg.ID, serverInput); ok {
+			cmds = append(cmds, a.respondInput(inputID, msg))
+		}
+		if providerID, ok := strings.CutPrefix(msg.ID, providerKeyInput); ok {
+			if key := strings.TrimSpace(msg.Value); key != "" {
+				cmds = append(cmds, a.app.SetProviderKey(providerID, key))
+			}
+		}
+	case app.ProviderKeyRequestedMsg:
+		return a, util.CmdHandler(chat.TextInputMsg{
+			ID:          providerKeyInput + msg.ProviderID,
+			Prompt:      "API key for " + msg.Name,
+			Placeholder: "paste the key",
+			Masked:      true,
+		})
+	case dialog.OpenAgentEditorMsg:
+		a.modal = dialog.NewAgentEditorDialog(a.app, msg.Agent)
+		return a, a.modal.Init()
+	case app.AgentSavedMsg:
+		a.app.UpdateAgent(msg.Agent)
+		return a, toast.NewSuccessToast("Saved agent " + msg.Agent.Name)
+	case app.ProviderAuthUpdatedMsg:
+		if msg.Providers != nil {
+			a.app.Providers = msg.Providers
+		}
+		if msg.Removed {
+			return a, toast.NewSuccessToast("Removed the API key for " + msg.ProviderID)
+		}
+		return a, toast.NewSuccessToast("Saved the API key for " + msg.ProviderID)
 
 	// API
 	case api.Request:
@@ -699,7 +1429,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				slog.Warn("Attempted to create help modal during active chat")
 				break
 			}
-			helpDialog := dialog.NewHelpDialog(a.app)
+			helpDialog := dialog.NewHelpDialog(a.app, a.helpContext())
 			a.modal = helpDialog
 		case "/tui/append-prompt":
 			var body struct {
@@ -712,6 +1442,23 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				text = " " + text
 			}
 			a.editor.SetValueWithAttachments(existing + text + " ")
+		case "/tui/ask":
+			var body struct {
+				ID       string        `json:"id"`
+				Question string        `json:"question"`
+				Choices  []chat.Choice `json:"choices"`
+				Default  int           `json:"default"`
+			}
+			if err := json.Unmarshal(msg.Body, &body); err != nil || len(body.Choices) == 0 {
+				response = false
+				break
+			}
+			cmds = append(cmds, util.CmdHandler(chat.ChoiceMsg{
+				ID:       "ask:" + body.ID,
+				Question: body.Question,
+				Choices:  body.Choices,
+				Default:  body.Default,
+			}))
 		default:
 			break
 		}
@@ -722,11 +1469,27 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	cmds = append(cmds, cmd)
 	a.status = s.(status.StatusComponent)
 
-	u, cmd := a.editor.Update(msg)
-	a.editor = u.(chat.EditorComponent)
+	sp, cmd := a.scratchpad.Update(msg)
+	a.scratchpad = sp
+	cmds = append(cmds, cmd)
+
+	a.shell, cmd = a.shell.Update(msg)
 	cmds = append(cmds, cmd)
 
-	u, cmd = a.messages.Update(msg)
+	// pastes go to an active text input, or to the scratchpad while it has
+	// focus, instead of the editor
+	if _, ok := msg.(tea.PasteMsg); ok && a.activeTextInput != nil {
+		updated, cmd := a.activeTextInput.Update(msg)
+		a.activeTextInput = updated
+		return a, tea.Batch(append(cmds, cmd)...)
+	}
+	if _, ok := msg.(tea.PasteMsg); !ok || !a.scratchpad.Focused() {
+		u, cmd := a.editor.Update(msg)
+		a.editor = u.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+	}
+
+	u, cmd := a.messages.Update(a.messagesMouse(msg))
 	a.messages = u.(chat.MessagesComponent)
 	cmds = append(cmds, cmd)
 
@@ -746,21 +1509,104 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	a.fileViewer = fv
 	cmds = append(cmds, cmd)
 
+	if _, ok := msg.(tea.WindowSizeMsg); ok {
+		cmds = append(cmds, a.resizePanes())
+	}
+
 	return a, tea.Batch(cmds...)
 }
 
+// warnUnreachableKeybinds warns about commands bound only to keys the
+// terminal sends the same as others
+func (a *Model) warnUnreachableKeybinds() tea.Cmd {
+	needing := a.app.Commands.NeedingKeyDisambiguation()
+	if len(needing) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(needing))
+	for _, command := range needing {
+		keys = append(keys, command.Keybindings[0].Key)
+	}
+	return toast.NewWarningToast(
+		"This terminal sends " + strings.Join(keys, ", ") + " like other keys. Turn on its kitty keyboard protocol, or extended-keys in tmux, or bind other keys.",
+	)
+}
+
 // hasActiveChat checks if the user is in an active chat session
 func (a *Model) hasActiveChat() bool {
 	// Check if we have an active session and any interactive elements
 	return a.app != nil && a.app.Session.ID != "" &&
-		(a.activeConfirmation != nil || a.activeToolApproval != nil ||
-			a.activeTextInput != nil)
+		(a.activeConfirmation != nil || a.activeChoice != nil ||
+			a.activeToolApproval != nil || a.activeTextInput != nil)
+}
+
+// showToolApproval makes msg the active approval
+func (a *Model) showToolApproval(msg chat.ToolApprovalMsg) {
+	a.activeToolApproval = chat.NewToolApprovalMessage(msg.ID, msg.ToolName, msg.Description, msg.Metadata)
+	a.activeToolApproval.Type = msg.Type
+	a.activeToolApproval.Pattern = msg.Pattern
+	a.activeToolApproval.SessionID = msg.SessionID
+	a.activeToolApproval.Queued = len(a.queuedToolApprovals)
+	a.editor.Blur() // Remove focus from editor
+}
+
+// nextToolApproval replaces the active approval with the first queued
+// request, returning focus to the editor once the queue is empty
+func (a *Model) nextToolApproval() {
+	if len(a.queuedToolApprovals) == 0 {
+		a.activeToolApproval = nil
+		a.editor.Focus()
+		return
+	}
+	next := a.queuedToolApprovals[0]
+	a.queuedToolApprovals = a.queuedToolApprovals[1:]
+	a.showToolApproval(next)
+}
+
+// windowTitle is the terminal title, naming the session and whether it waits
+// for an approval or works, to tell terminal tabs apart
+func (a Model) windowTitle() string {
+	if a.app == nil || a.app.Session == nil || a.app.Session.ID == "" {
+		return "kuuzuki"
+	}
+	var state string
+	switch {
+	case a.activeToolApproval != nil && !a.activeToolApproval.Answered:
+		state = "approval"
+	case a.app.IsBusy():
+		state = "working"
+	}
+	return terminalTitle(a.app.Session.Title, state)
+}
+
+func terminalTitle(session, state string) string {
+	title := "kuuzuki: " + session
+	if state != "" {
+		title += " [" + state + "]"
+	}
+	return title
+}
+
+// helpContext captures which panes have focus for the help dialog
+func (a *Model) helpContext() dialog.HelpContext {
+	return dialog.HelpContext{
+		Busy:              a.app.IsBusy(),
+		InputEmpty:        a.editor.Length() == 0,
+		CompletionOpen:    a.showCompletionDialog,
+		ScratchpadFocused: a.scratchpad.Focused(),
+		FileViewerFocused: a.fileViewer.Focused(),
+		FileViewerOpen:    a.fileViewer.HasFile(),
+	}
 }
 
 func (a Model) View() string {
 	measure := util.Measure("app.View")
 	defer measure()
+	defer a.latency.Rendered()
 	t := theme.CurrentTheme()
+	if a.app.Inline {
+		return a.zones.Scan(a.inlineView())
+	}
 
 	var mainLayout string
 
@@ -787,32 +1633,1041 @@ func (a Model) View() string {
 	if a.modal != nil && !a.hasActiveChat() {
 		mainLayout = a.modal.Render(mainLayout)
 	}
+	if a.showWhichKey && a.app.IsLeaderSequence && !a.app.ScreenReader {
+		panel := a.whichKeyPanel()
+		mainLayout = layout.PlaceOverlay(
+			max((a.width-lipgloss.Width(panel))/2, 0),
+			max(a.height-lipgloss.Height(panel)-2, 0),
+			panel,
+			mainLayout,
+		)
+	}
+	if a.showPerformance && !a.app.ScreenReader {
+		panel := a.performancePanel()
+		mainLayout = layout.PlaceOverlay(
+			a.width-lipgloss.Width(panel)-2,
+			max(a.height-lipgloss.Height(panel)-1, 0),
+			panel,
+			mainLayout,
+		)
+	}
 	mainLayout = a.toastManager.RenderOverlay(mainLayout)
 
 	if theme.CurrentThemeUsesAnsiColors() {
 		mainLayout = util.ConvertRGBToAnsi16Colors(mainLayout)
 	}
-	return mainLayout + "\n" + a.status.View()
+	view := mainLayout + "\n" + a.status.View()
+	if !styles.Fancy {
+		view = styles.ASCII(view)
+	}
+	return a.zones.Scan(view)
 }
 
-func (a Model) openFile(filepath string) (tea.Model, tea.Cmd) {
+// inlineView is the prompt with what waits for an answer, the conversation
+// is printed above it as it goes on
+func (a Model) inlineView() string {
+	width := a.width - 4
+	var views []string
+	switch {
+	case a.modal != nil:
+		views = append(views, a.modal.Render(""))
+	case a.activeToolApproval != nil:
+		views = append(views, a.activeToolApproval.View(width))
+	case a.activeConfirmation != nil:
+		views = append(views, a.activeConfirmation.View(width))
+	case a.activeChoice != nil:
+		views = append(views, a.activeChoice.View(width))
+	case a.activeTextInput != nil:
+		views = append(views, a.activeTextInput.View(width))
+	}
+	if a.showCompletionDialog {
+		views = append(views, a.completions.View())
+	}
+	views = append(views, a.editor.Content(), a.status.View())
+	view := strings.Join(views, "\n")
+	if !styles.Fancy {
+		view = styles.ASCII(view)
+	}
+	return view
+}
+
+// announce prints the lines above the prompt when inline, where screen
+// readers read them as they come
+func (a Model) announce(lines ...string) tea.Cmd {
+	if !a.app.Inline || len(lines) == 0 {
+		return nil
+	}
+	return tea.Println(strings.Join(lines, "\n"))
+}
+
+func runLimitTick() tea.Cmd {
+	return tea.Tick(runLimitTickInterval, func(time.Time) tea.Msg {
+		return RunLimitTickMsg{}
+	})
+}
+
+// checkRunLimits pauses the running turn once it reaches one of the configured
+// run limits and asks whether to continue
+func (a *Model) checkRunLimits() tea.Cmd {
+	turnID, reason := a.app.CheckRunLimits(time.Now())
+	if reason == "" || turnID == a.pausedTurnID {
+		return nil
+	}
+	a.pausedTurnID = turnID
+	slog.Info("Run limit reached", "session", a.app.Session.ID, "limit", reason)
+
+	// Mark the last message as completed so the busy state clears immediately
+	if len(a.app.Messages) > 0 {
+		lastMessage := &a.app.Messages[len(a.app.Messages)-1]
+		if casted, ok := lastMessage.Info.(opencode.AssistantMessage); ok && casted.Time.Completed == 0 {
+			casted.Time.Completed = float64(time.Now().UnixMilli())
+			lastMessage.Info = casted
+		}
+	}
+
+	sessionID := a.app.Session.ID
+	return tea.Batch(
+		func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := a.app.Client.Session.Abort(ctx, sessionID); err != nil {
+				slog.Error("Failed to pause session", "error", err, "session_id", sessionID)
+			}
+			return nil
+		},
+		util.CmdHandler(chat.ConfirmationMsg{
+			ID:       "run-limit",
+			Question: "Paused after reaching the limit of " + reason + ". Continue?",
+		}),
+	)
+}
+
+// notify notifies the event unless the terminal has focus, terminals that do
+// not report focus are never notified
+func (a *Model) notify(event app.NotifyEvent, body string) tea.Cmd {
+	if !a.focusSupported || a.hasFocus {
+		return nil
+	}
+	return a.app.Notify(event, "kuuzuki", body)
+}
+
+// notifyComplete notifies that the session went idle after a request that ran
+// long enough to have been left alone
+func (a *Model) notifyComplete() tea.Cmd {
+	for i := len(a.app.Messages) - 1; i >= 0; i-- {
+		message, ok := a.app.Messages[i].Info.(opencode.UserMessage)
+		if !ok {
+			continue
+		}
+		started := time.UnixMilli(int64(message.Time.Created))
+		if time.Since(started) < a.app.NotifyMinDuration() {
+			return nil
+		}
+		return a.notify(app.NotifyComplete, "Finished in "+a.app.Session.Title)
+	}
+	return nil
+}
+
+// checkBudget reloads the cost of today's other sessions when the day or
+// session changed, and warns once a budget is nearly or fully spent
+func (a *Model) checkBudget() tea.Cmd {
+	var cmds []tea.Cmd
+	now := time.Now()
+	if today := now.Format(time.DateOnly); a.budgetDay != today {
+		a.budgetDay = today
+		cmds = append(cmds, a.app.LoadDailySpend())
+	}
+
+	status, ok := a.app.BudgetStatus(now)
+	if !ok || status.Level == app.BudgetOK {
+		return tea.Batch(cmds...)
+	}
+	key := fmt.Sprintf("%s:%d:%s", status.Cap, status.Level, a.budgetDay)
+	if status.Cap == "session" {
+		key = fmt.Sprintf("%s:%d:%s", status.Cap, status.Level, a.app.Session.ID)
+	}
+	if a.budgetWarned[key] {
+		return tea.Batch(cmds...)
+	}
+	if a.budgetWarned == nil {
+		a.budgetWarned = map[string]bool{}
+	}
+	a.budgetWarned[key] = true
+	if status.Level == app.BudgetExceeded {
+		cmds = append(cmds, toast.NewErrorToast(
+			status.String()+", prompts now need confirmation",
+			toast.WithTitle("Budget spent"),
+		))
+	} else {
+		cmds = append(cmds, toast.NewWarningToast(status.String(), toast.WithTitle("Budget running out")))
+	}
+	return tea.Batch(cmds...)
+}
+
+// holdForBudget holds a prompt back once a budget is spent, asking whether
+// to send it anyway
+func (a *Model) holdForBudget(prompt app.Prompt) tea.Cmd {
+	if a.budgetConfirmed {
+		a.budgetConfirmed = false
+		return nil
+	}
+	status, ok := a.app.BudgetStatus(time.Now())
+	if !ok || status.Level != app.BudgetExceeded {
+		return nil
+	}
+	a.budgetPrompt = &prompt
+	question := fmt.Sprintf(
+		"The %s budget is spent ($%.2f of $%.2f). Send this prompt anyway?",
+		status.Cap,
+		status.Spent,
+		status.Limit,
+	)
+	return util.CmdHandler(chat.ConfirmationMsg{
+		ID:       "budget",
+		Question: question,
+	})
+}
+
+// showChoice makes msg the active choice
+func (a *Model) showChoice(msg chat.ChoiceMsg) {
+	a.activeChoice = chat.NewChoiceMessage(msg.ID, msg.Question, msg.Choices, msg.Default)
+	a.editor.Blur() // Remove focus from editor
+}
+
+// nextChoice shows the next queued choice, or returns focus to the editor
+// when none is left
+func (a *Model) nextChoice() {
+	if len(a.queuedChoices) > 0 {
+		next := a.queuedChoices[0]
+		a.queuedChoices = a.queuedChoices[1:]
+		a.showChoice(next)
+		return
+	}
+	a.activeChoice = nil
+	a.editor.Focus() // Return focus to editor
+}
+
+// showTextInput makes msg the active text input
+func (a *Model) showTextInput(msg chat.TextInputMsg) {
+	a.activeTextInput = chat.NewTextInputMessage(msg.ID, msg.Prompt, msg.Placeholder)
+	a.activeTextInput.SetMasked(msg.Masked)
+	a.editor.Blur() // Remove focus from editor
+}
+
+// nextTextInput shows the next queued text input, or returns focus to the
+// editor when none is left
+func (a *Model) nextTextInput() {
+	if len(a.queuedTextInputs) > 0 {
+		next := a.queuedTextInputs[0]
+		a.queuedTextInputs = a.queuedTextInputs[1:]
+		a.showTextInput(next)
+		return
+	}
+	a.activeTextInput = nil
+	a.editor.Focus() // Return focus to editor
+}
+
+// respondPermission sends the answer of a tool approval to the server
+func (a *Model) respondPermission(sessionID string, msg chat.ToolApprovalAnswerMsg) tea.Cmd {
+	return func() tea.Msg {
+		response := opencode.PermissionResponseReject
+		if msg.Approved {
+			response = opencode.PermissionResponseOnce
+			if msg.Response == "always" {
+				response = opencode.PermissionResponseAlways
+			}
+		}
+		var rule *opencode.SessionPermissionRespondParamsRule
+		if msg.Rule != nil {
+			rule = &opencode.SessionPermissionRespondParamsRule{
+				Scope: opencode.F(msg.Rule.Scope),
+			}
+			if msg.Rule.Pattern != "" {
+				rule.Pattern = opencode.F(msg.Rule.Pattern)
+			}
+			if msg.Exact {
+				rule.Exact = opencode.F(true)
+			}
+		}
+		err := a.app.Client.Session.RespondPermission(context.Background(), sessionID, msg.ID, response, rule)
+		switch {
+		case errors.Is(err, opencode.ErrPermissionNotPending):
+			return toast.NewInfoToast("The request was already answered or timed out")()
+		case err != nil:
+			slog.Error("Failed to send permission response", "error", err, "sessionID", sessionID, "permissionID", msg.ID)
+			return toast.NewErrorToast("Failed to send the permission answer")()
+		}
+		slog.Info("Permission response sent", "sessionID", sessionID, "permissionID", msg.ID, "response", response)
+		return nil
+	}
+}
+
+// CapturingSecret reports whether keys go to a masked text input, which
+// recordings leave out
+func (a Model) CapturingSecret() bool {
+	return a.activeTextInput != nil && !a.activeTextInput.Submitted && a.activeTextInput.IsMasked()
+}
+
+// respondInput sends the answer of a text input the server asked for
+func (a *Model) respondInput(inputID string, msg chat.TextInputAnswerMsg) tea.Cmd {
+	// a replayed input was answered when it was recorded
+	if a.app.Replaying {
+		return nil
+	}
+	return func() tea.Msg {
+		params := opencode.TuiRespondInputParams{
+			Cancelled: opencode.F(msg.Cancelled),
+		}
+		if !msg.Cancelled {
+			params.Value = opencode.F(msg.Value)
+		}
+		if _, err := a.app.Client.Tui.RespondInput(context.Background(), inputID, params); err != nil {
+			slog.Error("Failed to send the text input answer", "error", err)
+			return toast.NewErrorToast("Failed to send the answer")()
+		}
+		return nil
+	}
+}
+
+// offerFallback retries the latest prompt with the next model of the
+// configured fallback chain, asking first unless fallbacks are automatic.
+// Models of excludeProvider are skipped, e.g. after it failed to authenticate.
+func (a *Model) offerFallback(reason, excludeProvider string) tea.Cmd {
+	if a.app.Config == nil || len(a.app.Config.Tui.ModelFallback.Models) == 0 {
+		return nil
+	}
+	if a.fallbackTried == nil {
+		a.fallbackTried = map[string]bool{}
+	}
+	from := a.app.LastModel()
+	a.fallbackTried[from] = true
+
+	provider, model, ok := app.NextFallbackModel(
+		a.app.Providers,
+		a.app.Config.Tui.ModelFallback.Models,
+		a.fallbackTried,
+		excludeProvider,
+	)
+	if !ok {
+		return toast.NewWarningToast("No fallback models left to try", toast.WithTitle("Model fallback"))
+	}
+	fallback := app.Fallback{From: from, To: provider.ID + "/" + model.ID, Reason: reason}
+	a.fallbackTried[fallback.To] = true
+	slog.Info("Model fallback available", "from", fallback.From, "to", fallback.To, "reason", reason)
+
+	if a.app.Config.Tui.ModelFallback.Auto {
+		return a.retryWithFallback(fallback)
+	}
+	a.pendingFallback = &fallback
+	return util.CmdHandler(chat.ConfirmationMsg{
+		ID:       "model-fallback",
+		Question: fmt.Sprintf("%s %s. Retry with %s?", from, reason, model.Name),
+	})
+}
+
+// retryWithFallback resends the latest prompt with the fallback model
+func (a *Model) retryWithFallback(fallback app.Fallback) tea.Cmd {
+	prompt, err := app.LastPrompt(a.app.Messages, a.app.Session.Revert.MessageID)
+	if err != nil {
+		return toast.NewErrorToast(err.Error())
+	}
+	prompt.ProviderID, prompt.ModelID, _ = strings.Cut(fallback.To, "/")
+	a.app.Fallback = &fallback
+	return tea.Batch(
+		toast.NewWarningToast(
+			fmt.Sprintf("%s %s, retrying with %s", fallback.From, fallback.Reason, fallback.To),
+			toast.WithTitle("Model fallback"),
+		),
+		util.CmdHandler(app.SendPrompt(*prompt)),
+	)
+}
+
+// restartMsg asks to restart kuuzuki, for an update to take effect
+type restartMsg struct{}
+
+// runToastActions runs the action of the toast, asking which one when it
+// offers several, and dismisses the toast
+func (a *Model) runToastActions(t toast.Toast) tea.Cmd {
+	if len(t.Actions) == 0 {
+		return nil
+	}
+	dismiss := util.CmdHandler(toast.DismissToastMsg{ID: t.ID})
+	if len(t.Actions) == 1 {
+		return tea.Batch(dismiss, util.CmdHandler(t.Actions[0].Msg))
+	}
+	a.pendingToastActions = t.Actions
+	choices := make([]chat.Choice, 0, len(t.Actions))
+	for _, action := range t.Actions {
+		choices = append(choices, chat.Choice{Label: action.Label})
+	}
+	return tea.Batch(dismiss, util.CmdHandler(chat.ChoiceMsg{
+		ID:       "toast-action",
+		Question: t.Message,
+		Choices:  choices,
+	}))
+}
+
+// transcribedMsg carries the text of a transcribed audio file
+type transcribedMsg struct {
+	text string
+}
+
+// transcribe turns the audio file into text through the server, for the
+// prompt
+func (a *Model) transcribe(audio chat.AudioPastedMsg) tea.Cmd {
+	name := filepath.Base(audio.Path)
+	return tea.Batch(
+		toast.NewInfoToast("Transcribing "+name),
+		func() tea.Msg {
+			data, err := os.ReadFile(audio.Path)
+			if err != nil {
+				return toast.NewErrorToast("Failed to read " + name)()
+			}
+			text, err := a.app.Client.File.Transcribe(context.Background(), opencode.FileTranscribeParams{
+				Audio: opencode.F(base64.StdEncoding.EncodeToString(data)),
+				Mime:  opencode.F(audio.MediaType),
+			})
+			if err != nil {
+				slog.Error("Failed to transcribe", "error", err)
+				return toast.NewErrorToast("Failed to transcribe "+name, toast.WithTitle("Check transcription_model"))()
+			}
+			return transcribedMsg{text: strings.TrimSpace(*text)}
+		},
+	)
+}
+
+// applyPatch applies a pasted diff to the working tree through the server
+func (a *Model) applyPatch(patch string) tea.Cmd {
+	if a.app.ReadOnly {
+		return toast.NewInfoToast(app.ReadOnlyMessage)
+	}
+	return func() tea.Msg {
+		files, err := a.app.Client.File.Patch(context.Background(), opencode.FilePatchParams{
+			Patch: opencode.F(patch),
+		})
+		if err != nil {
+			slog.Error("Failed to apply the patch", "error", err)
+			return toast.NewErrorToast("The patch does not apply to the working tree")()
+		}
+		if len(*files) == 1 {
+			return toast.NewSuccessToast(
+				"Patched "+(*files)[0],
+				toast.WithAction("Open file", dialog.FindSelectedMsg{FilePath: (*files)[0]}),
+			)()
+		}
+		return toast.NewSuccessToast(fmt.Sprintf("Patched %d files", len(*files)))()
+	}
+}
+
+// togglePinnedFile pins or unpins a file for the current session
+func (a *Model) togglePinnedFile(path string) tea.Cmd {
+	if a.app.ReadOnly {
+		return toast.NewInfoToast(app.ReadOnlyMessage)
+	}
+	pinned, err := a.app.TogglePinnedFile(path)
+	if err != nil {
+		return toast.NewErrorToast("Failed to pin " + path + ": " + err.Error())
+	}
+	message := "Unpinned " + path
+	if pinned {
+		message = "Pinned " + path + " to the session's context"
+	}
+	a.refreshPinnedFiles()
+	return toast.NewInfoToast(message)
+}
+
+// refreshPinnedFiles re-reads the token cost of the pinned files
+func (a *Model) refreshPinnedFiles() {
+	a.pinnedFiles = a.app.PinnedFiles()
+	a.pinnedSession = a.app.Session.ID
+}
+
+// pinnedFilesPanel renders the sidebar listing the pinned files with their
+// token cost
+func (a Model) pinnedFilesPanel() string {
+	if len(a.pinnedFiles) == 0 || a.pinnedSession != a.app.Session.ID {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement())
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement())
+	const width = 32
+
+	lines := []string{muted.Render("pinned files")}
+	total := 0
+	for _, file := range a.pinnedFiles {
+		total += file.Tokens
+		cost := "~" + util.FormatTokens(float64(file.Tokens))
+		if file.Err != nil {
+			cost = "missing"
+		}
+		name := ansi.Truncate(file.Path, width-lipgloss.Width(cost)-1, "…")
+		spacer := strings.Repeat(" ", max(width-lipgloss.Width(name)-lipgloss.Width(cost), 1))
+		style := base
+		if file.Err != nil {
+			style = style.Foreground(t.Error())
+		}
+		lines = append(lines, style.Render(name)+muted.Render(spacer+cost))
+	}
+	lines = append(lines, muted.Render("total ~"+util.FormatTokens(float64(total))))
+
+	return styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Width(width+2).
+		Padding(0, 1).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderForeground(t.Border()).
+		BorderBackground(t.Background()).
+		BorderLeft(true).
+		Render(strings.Join(lines, "\n"))
+}
+
+// performancePanel renders the overlay with a histogram of the key press to
+// render latency
+func (a Model) performancePanel() string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement())
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement())
+	const barWidth = 16
+
+	stats := a.latency.Stats()
+	peak := max(slices.Max(stats.Buckets), 1)
+	lines := []string{muted.Render(fmt.Sprintf("input latency, budget %dms", stats.Budget.Milliseconds()))}
+	for i, count := range stats.Buckets {
+		label := ">" + util.LatencyBuckets[len(util.LatencyBuckets)-1].String()
+		if i < len(util.LatencyBuckets) {
+			label = "≤" + util.LatencyBuckets[i].String()
+		}
+		color := t.Success()
+		if i >= len(util.LatencyBuckets) || util.LatencyBuckets[i] > stats.Budget {
+			color = t.Warning()
+		}
+		filled := count * barWidth / peak
+		if count > 0 {
+			filled = max(filled, 1)
+		}
+		bar := styles.NewStyle().Foreground(color).Background(t.BackgroundElement()).
+			Render(strings.Repeat("█", filled))
+		lines = append(lines, muted.Render(fmt.Sprintf("%-7s", label))+bar+
+			muted.Render(strings.Repeat(" ", barWidth-filled)+fmt.Sprintf(" %d", count)))
+	}
+	lines = append(lines,
+		base.Render(fmt.Sprintf("p50 %s  p95 %s  max %s",
+			stats.P50.Round(time.Millisecond/10),
+			stats.P95.Round(time.Millisecond/10),
+			stats.Max.Round(time.Millisecond/10),
+		)),
+		muted.Render(fmt.Sprintf("%d of %d over budget", stats.Violations, stats.Count)),
+	)
+
+	return styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Padding(0, 1).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderForeground(t.Border()).
+		BorderBackground(t.Background()).
+		BorderLeft(true).
+		Render(strings.Join(lines, "\n"))
+}
+
+// startLeaderSequence waits for the key following the leader, showing the
+// keys that can follow it unless one comes quickly
+func (a *Model) startLeaderSequence() tea.Cmd {
+	a.app.IsLeaderSequence = true
+	a.leaderPresses++
+	press := a.leaderPresses
+	return tea.Tick(whichKeyDelay, func(time.Time) tea.Msg {
+		return WhichKeyTimeoutMsg{Press: press}
+	})
+}
+
+// leaderKey is a key that can follow the leader and what it does
+type leaderKey struct {
+	key, description string
+}
+
+// leaderKeys returns the keys that can follow the leader, in key order
+func leaderKeys(registry commands.CommandRegistry) []leaderKey {
+	var keys []leaderKey
+	for _, command := range registry.Sorted() {
+		for _, binding := range command.Keybindings {
+			if binding.RequiresLeader {
+				keys = append(keys, leaderKey{key: binding.Key, description: command.Description})
+			}
+		}
+	}
+	slices.SortStableFunc(keys, func(a, b leaderKey) int {
+		return strings.Compare(a.key, b.key)
+	})
+	return keys
+}
+
+// whichKeyPanel lists the keys that can follow the leader in columns
+func (a Model) whichKeyPanel() string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Accent()).Background(t.BackgroundElement()).Bold(true)
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundElement())
+	const maxDescription = 24
+
+	keys := leaderKeys(a.app.Commands)
+	keyWidth := 0
+	for _, k := range keys {
+		keyWidth = max(keyWidth, ansi.StringWidth(k.key))
+	}
+	columnWidth := keyWidth + 1 + maxDescription + 2
+	columns := max(1, min(4, (a.width-8)/columnWidth))
+	rows := (len(keys) + columns - 1) / columns
+
+	lines := make([]string, rows)
+	for i, k := range keys {
+		cell := keyStyle.Render(fmt.Sprintf("%-*s", keyWidth, k.key)) +
+			muted.Render(" "+fmt.Sprintf("%-*s", maxDescription+2, ansi.Truncate(k.description, maxDescription, "…")))
+		lines[i%rows] += cell
+	}
+	title := muted.Render(a.app.Config.Keybinds.Leader + " …")
+
+	return styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Padding(0, 1).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderForeground(t.Accent()).
+		BorderBackground(t.Background()).
+		BorderLeft(true).
+		Render(title + "\n" + strings.Join(lines, "\n"))
+}
+
+// dropContext reverts the session to the message, dropping it and everything
+// after it from the context
+func (a *Model) dropContext(messageID string) tea.Cmd {
+	index := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
+		return m.ID() == messageID
+	})
+	if index < 0 {
+		return nil
+	}
+	message := a.app.Messages[index]
+	sessionID := a.app.Session.ID
+	return func() tea.Msg {
+		response, err := a.app.Client.Session.Revert(
+			context.Background(),
+			sessionID,
+			opencode.SessionRevertParams{MessageID: opencode.F(messageID)},
+		)
+		if err != nil || response == nil {
+			slog.Error("Failed to drop messages from context", "error", err)
+			return toast.NewErrorToast("Failed to drop messages")()
+		}
+		return app.MessageRevertedMsg{Session: *response, Message: message}
+	}
+}
+
+// checkContextUsage offers to compact the session once a response leaves the
+// context window fuller than the configured threshold
+func (a *Model) checkContextUsage() tea.Cmd {
+	if a.app.ReadOnly || a.app.IsBusy() || a.activeConfirmation != nil {
+		return nil
+	}
+	percent := a.app.ContextPercent()
+	if percent < a.app.CompactThreshold() {
+		// allow warning again once the session has been compacted
+		if a.contextWarnedSession == a.app.Session.ID {
+			a.contextWarnedSession = ""
+		}
+		return nil
+	}
+	if a.contextWarnedSession == a.app.Session.ID {
+		return nil
+	}
+	a.contextWarnedSession = a.app.Session.ID
+
+	return util.CmdHandler(chat.ConfirmationMsg{
+		ID:       "compact",
+		Question: fmt.Sprintf("The context window is %d%% full. Compact the session now?", int(percent)),
+	})
+}
+
+// pruneExpiredSessions deletes the sessions that fall outside the configured
+// retention policy
+func (a Model) pruneExpiredSessions() tea.Cmd {
+	return func() tea.Msg {
+		sessions, err := a.app.ListPrunableSessions(context.Background())
+		if err != nil {
+			slog.Error("Failed to list sessions for pruning", "error", err)
+			return nil
+		}
+		if len(sessions) == 0 {
+			return nil
+		}
+		return dialog.PruneSessionsCmd(a.app, sessions)()
+	}
+}
+
+// openAttachment opens the file of a file or symbol attachment, symbols at
+// the line they start on
+func openAttachment(att *attachment.Attachment) tea.Cmd {
+	if att == nil {
+		return toast.NewInfoToast("Move the cursor onto an attachment to open it")
+	}
+	if source, ok := att.GetSymbolSource(); ok {
+		return util.CmdHandler(app.OpenFileMsg{
+			Path: util.Relative(strings.TrimPrefix(source.Path, "file://")),
+			Line: source.Range.Start.Line,
+		})
+	}
+	if source, ok := att.GetFileSource(); ok && !strings.HasPrefix(att.MediaType, "image/") {
+		return util.CmdHandler(app.OpenFileMsg{Path: util.Relative(source.Path)})
+	}
+	return toast.NewInfoToast("Only file and symbol attachments can be opened")
+}
+
+// editFile opens a file at the 0-based line in the IDE kuuzuki runs in, or
+// else in $EDITOR, which takes over the terminal until it exits
+func (a Model) editFile(path string, line int) tea.Cmd {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(a.app.Info.Path.Cwd, path)
+	}
+	c, gui, err := util.EditCommand(path, line+1)
+	if err != nil {
+		return toast.NewErrorToast(err.Error())
+	}
+	if gui {
+		return func() tea.Msg {
+			if err := c.Start(); err != nil {
+				slog.Error("Failed to open editor", "error", err)
+				return toast.NewErrorToast("Failed to open " + c.Path)()
+			}
+			go c.Wait()
+			return nil
+		}
+	}
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		if err != nil {
+			slog.Error("Failed to open editor", "error", err)
+		}
+		return nil
+	})
+}
+
+// fileFollowedMsg carries what the session changed in a file it edited,
+// change is nil when the file isn't among the session's changes
+type fileFollowedMsg struct {
+	path   string
+	change *opencode.SessionChange
+}
+
+// following reports whether files the agent edits open in the file viewer
+func (a Model) following() bool {
+	return a.app.State.FollowEdits && a.app.Session.ID != ""
+}
+
+// followFile reads what the session changed in a file it edited, so it is
+// shown as a diff from before the session, like in the review
+func (a Model) followFile(path string) tea.Cmd {
+	client := a.app.Client
+	sessionID := a.app.Session.ID
+	return func() tea.Msg {
+		changes, err := client.Session.Changes(context.Background(), sessionID)
+		if err != nil || changes == nil {
+			slog.Error("Failed to read the session's changes", "error", err)
+			return fileFollowedMsg{path: path}
+		}
+		for _, change := range *changes {
+			if change.Path == path {
+				return fileFollowedMsg{path: path, change: &change}
+			}
+		}
+		return fileFollowedMsg{path: path}
+	}
+}
+
+func (a Model) openFile(filepath string) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	response, err := a.app.Client.File.Read(
 		context.Background(),
 		opencode.FileReadParams{
-			Path: opencode.F(filepath),
+			Path:  opencode.F(filepath),
+			Limit: opencode.F(int64(fileviewer.PageSize)),
 		},
 	)
 	if err != nil {
 		slog.Error("Failed to read file", "error", err)
 		return a, toast.NewErrorToast("Failed to read file")
 	}
-	a.fileViewer, cmd = a.fileViewer.SetFile(
-		filepath,
-		response.Content,
-		response.Type == "patch",
-	)
-	return a, cmd
+	var touch tea.Cmd
+	if a.fileViewer.Filename() != filepath {
+		touch = a.app.TouchFile(filepath)
+	}
+	a.fileViewer, cmd = a.fileViewer.SetFile(
+		filepath,
+		response.Content,
+		response.Type == "patch",
+	)
+	a.fileViewer.SetTotalLines(int(response.Total))
+	return a, tea.Batch(cmd, touch, a.resizePanes())
+}
+
+const (
+	defaultSplitRatio = 50
+	minSplitRatio     = 20
+	maxSplitRatio     = 80
+	splitRatioStep    = 10
+)
+
+// splitRatio returns the percentage of the width given to the messages pane
+// while a file is open
+func (a Model) splitRatio() int {
+	if a.app.State.SplitRatio == 0 {
+		return defaultSplitRatio
+	}
+	return min(max(a.app.State.SplitRatio, minSplitRatio), maxSplitRatio)
+}
+
+// resizePanes splits the width between the messages and the file viewer while
+// a file is open, and gives it all back to the messages once it is closed
+func (a *Model) resizePanes() tea.Cmd {
+	width := a.width - 4
+	if !a.fileViewer.HasFile() {
+		return a.messages.SetWidth(width)
+	}
+
+	messagesWidth := width * a.splitRatio() / 100
+	var cmd tea.Cmd
+	// leave a column for the divider between the panes
+	a.fileViewer, cmd = a.fileViewer.SetSize(width-messagesWidth-1, a.height-5)
+	return tea.Batch(cmd, a.messages.SetWidth(messagesWidth))
+}
+
+// focusFileViewer moves focus between the file viewer and the editor
+func (a *Model) focusFileViewer(focused bool) tea.Cmd {
+	a.messagesFocused = false
+	a.fileViewer.SetFocused(focused)
+	if focused {
+		a.editor.Blur()
+		return nil
+	}
+	updated, cmd := a.editor.Focus()
+	a.editor = updated.(chat.EditorComponent)
+	return cmd
+}
+
+// scrollsMessages are the global commands that keep the messages focused
+var scrollsMessages = map[commands.CommandName]bool{
+	commands.MessagesPageUpCommand:       true,
+	commands.MessagesPageDownCommand:     true,
+	commands.MessagesHalfPageUpCommand:   true,
+	commands.MessagesHalfPageDownCommand: true,
+	commands.MessagesPreviousCommand:     true,
+	commands.MessagesNextCommand:         true,
+	commands.MessagesFirstCommand:        true,
+	commands.MessagesLastCommand:         true,
+}
+
+// runCustomCommand runs a command defined in the tui config
+func (a Model) runCustomCommand(command commands.Command) tea.Cmd {
+	custom := command.Custom
+	switch {
+	case custom.Run != "":
+		target, ok := a.app.Commands[commands.CommandName(custom.Run)]
+		if !ok || target.Custom != nil {
+			return toast.NewErrorToast("Unknown command: " + custom.Run)
+		}
+		return util.CmdHandler(commands.ExecuteCommandMsg(target))
+	case custom.Shell != "":
+		return util.CmdHandler(app.ExecuteShellCommand{
+			SessionID: a.app.Session.ID,
+			Command:   command.Expand(custom.Shell),
+		})
+	case custom.Prompt != "":
+		prompt := command.Expand(custom.Prompt)
+		// prompts waiting for arguments are finished in the editor
+		if custom.Edit || command.NeedsArguments() {
+			return util.CmdHandler(app.SetEditorContentMsg{Text: prompt})
+		}
+		return util.CmdHandler(app.SendPrompt{Text: prompt})
+	}
+	return nil
+}
+
+// keymap returns the keymap of the focused pane
+func (a Model) keymap() commands.Keymap {
+	switch {
+	case a.fileViewer.Focused():
+		return commands.KeymapFileViewer
+	case a.messagesFocused && !a.editor.Focused():
+		return commands.KeymapMessages
+	}
+	return commands.KeymapEditor
+}
+
+// keymapMatches returns the commands the keymap itself binds to the key,
+// leaving keys it doesn't bind to the global keymap
+func (a Model) keymapMatches(msg tea.KeyPressMsg, keymap commands.Keymap) []commands.Command {
+	matches := a.app.Commands.Matches(msg, false, keymap)
+	if len(matches) == 0 || matches[0].Keymap != keymap {
+		return nil
+	}
+	return matches
+}
+
+// focusMessages moves focus between the messages and the editor
+func (a *Model) focusMessages(focused bool) tea.Cmd {
+	if !focused {
+		return a.focusFileViewer(false)
+	}
+	a.fileViewer.SetFocused(false)
+	a.messagesFocused = true
+	a.editor.Blur()
+	return nil
+}
+
+// messagesMouse moves mouse events into the messages pane, leaving out
+// clicks on dialogs above it
+func (a Model) messagesMouse(msg tea.Msg) tea.Msg {
+	rect, ok := a.zones.Rect(zoneMessages)
+	if !ok {
+		return msg
+	}
+	switch msg := msg.(type) {
+	case tea.MouseClickMsg:
+		if a.modal != nil {
+			return nil
+		}
+		msg.X, msg.Y = msg.X-rect.Min.X, msg.Y-rect.Min.Y
+		return msg
+	case tea.MouseMotionMsg:
+		msg.X, msg.Y = msg.X-rect.Min.X, msg.Y-rect.Min.Y
+		return msg
+	case tea.MouseReleaseMsg:
+		msg.X, msg.Y = msg.X-rect.Min.X, msg.Y-rect.Min.Y
+		return msg
+	}
+	return msg
+}
+
+// Zones of the panes that take focus when clicked
+const (
+	zoneEditor     = "editor"
+	zoneMessages   = "messages"
+	zoneFileViewer = "fileviewer"
+)
+
+// click handles a click on one of the zones of the last screen, reporting
+// false when the click should go on to the components
+func (a *Model) click(msg tea.MouseClickMsg) (tea.Cmd, bool) {
+	if msg.Button != tea.MouseLeft {
+		return nil, false
+	}
+	zone, ok := a.zones.Hit(msg.X, msg.Y)
+	if !ok {
+		return nil, false
+	}
+
+	// dialogs and completions only take keys, so move to the clicked row
+	// and select it like the keyboard would
+	if steps, ok := list.ParseRowZone(zone); ok {
+		if a.modal == nil && !a.showCompletionDialog {
+			return nil, false
+		}
+		code := tea.KeyDown
+		if steps < 0 {
+			code, steps = tea.KeyUp, -steps
+		}
+		keys := make([]tea.Cmd, 0, steps+1)
+		for range steps {
+			keys = append(keys, util.CmdHandler(tea.KeyPressMsg{Code: code}))
+		}
+		keys = append(keys, util.CmdHandler(tea.KeyPressMsg{Code: tea.KeyEnter}))
+		return tea.Sequence(keys...), true
+	}
+	if a.modal != nil {
+		return nil, false
+	}
+
+	if a.activeToolApproval != nil && (zone == chat.ZoneApprove || zone == chat.ZoneDeny) {
+		updated, cmd := a.activeToolApproval.Click(zone)
+		a.activeToolApproval = updated
+		return cmd, true
+	}
+	if a.activeConfirmation != nil && (zone == chat.ZoneYes || zone == chat.ZoneNo) {
+		updated, cmd := a.activeConfirmation.Click(zone)
+		a.activeConfirmation = updated
+		return cmd, true
+	}
+	if a.activeChoice != nil {
+		updated, cmd := a.activeChoice.Click(zone)
+		a.activeChoice = updated
+		if cmd != nil {
+			return cmd, true
+		}
+	}
+	if segment, ok := status.ParseSegmentZone(zone); ok {
+		return a.clickSegment(segment), true
+	}
+
+	// panes only take focus while nothing waits for an answer
+	if a.activeConfirmation != nil || a.activeChoice != nil || a.activeToolApproval != nil || a.activeTextInput != nil {
+		return nil, false
+	}
+	switch zone {
+	case zoneEditor:
+		return a.focusMessages(false), true
+	case zoneMessages:
+		// the messages still get the click to start a selection
+		a.focusMessages(true)
+	case zoneFileViewer:
+		if rect, ok := a.zones.Rect(zoneFileViewer); ok {
+			a.fileViewer.Click(msg.X-rect.Min.X, msg.Y-rect.Min.Y)
+		}
+		return a.focusFileViewer(true), true
+	}
+	return nil, false
+}
+
+// segmentCommands are the commands run by clicking status bar segments
+var segmentCommands = map[string]commands.CommandName{
+	"logo":        commands.AppHelpCommand,
+	"cwd":         commands.ProjectListCommand,
+	"agent":       commands.SwitchAgentCommand,
+	"model":       commands.ModelListCommand,
+	"fallback":    commands.ModelListCommand,
+	"usage":       commands.SessionUsageCommand,
+	"budget":      commands.SessionUsageCommand,
+	"context":     commands.SessionContextCommand,
+	"scroll_lock": commands.MessagesScrollLockCommand,
+	"follow":      commands.FileFollowCommand,
+	"tool":        commands.ToolDetailsCommand,
+}
+
+// clickSegment runs the command behind a status bar segment
+func (a *Model) clickSegment(segment string) tea.Cmd {
+	name, ok := segmentCommands[segment]
+	if !ok {
+		return nil
+	}
+	return util.CmdHandler(commands.ExecuteCommandMsg(a.app.Commands[name]))
+}
+
+// splitView places the messages and the open file side by side
+func (a Model) splitView(messagesView string) string {
+	t := theme.CurrentTheme()
+	fileView := layout.Mark(zoneFileViewer, a.fileViewer.View())
+
+	dividerColor := t.BorderSubtle()
+	if a.fileViewer.Focused() {
+		dividerColor = t.BorderActive()
+	}
+	divider := styles.NewStyle().
+		Foreground(dividerColor).
+		Background(t.Background()).
+		Render(strings.TrimSuffix(strings.Repeat("│\n", lipgloss.Height(messagesView)), "\n"))
+
+	if a.messagesRight {
+		return lipgloss.JoinHorizontal(lipgloss.Top, fileView, divider, messagesView)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, messagesView, divider, fileView)
 }
 
 func (a Model) home() string {
@@ -913,6 +2768,26 @@ func (a Model) home() string {
 		)
 	}
 
+	if thumbnails := a.editor.Thumbnails(); thumbnails != "" && !a.scratchpad.Visible() {
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			editorY-lipgloss.Height(thumbnails)+1,
+			thumbnails,
+			mainLayout,
+		)
+	}
+
+	if a.scratchpad.Visible() {
+		a.scratchpad.SetWidth(editorWidth)
+		panel := a.scratchpad.View()
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			editorY-lipgloss.Height(panel)+1,
+			panel,
+			mainLayout,
+		)
+	}
+
 	if a.showCompletionDialog {
 		a.completions.SetWidth(editorWidth)
 		overlay := a.completions.View()
@@ -962,14 +2837,17 @@ func (a Model) chat() string {
 	t := theme.CurrentTheme()
 	editorView := a.editor.View()
 	lines := a.editor.Lines()
-	messagesView := a.messages.View()
+	messagesView := layout.Mark(zoneMessages, a.messages.View())
+	if a.fileViewer.HasFile() {
+		messagesView = a.splitView(messagesView)
+	}
 
 	editorWidth := lipgloss.Width(editorView)
 	editorHeight := max(lines, 5)
 	editorView = lipgloss.PlaceHorizontal(
 		effectiveWidth,
 		lipgloss.Center,
-		editorView,
+		layout.Mark(zoneEditor, editorView),
 		styles.WhitespaceStyle(t.Background()),
 	)
 
@@ -977,6 +2855,8 @@ func (a Model) chat() string {
 	var interactiveView string
 	if a.activeConfirmation != nil {
 		interactiveView = a.activeConfirmation.View(effectiveWidth) + "\n"
+	} else if a.activeChoice != nil {
+		interactiveView = a.activeChoice.View(effectiveWidth) + "\n"
 	} else if a.activeTextInput != nil {
 		interactiveView = a.activeTextInput.View(effectiveWidth) + "\n"
 	}
@@ -989,7 +2869,50 @@ func (a Model) chat() string {
 		mainLayout = layout.PlaceOverlay(
 			editorX,
 			editorY,
-			a.editor.Content(),
+			layout.Mark(zoneEditor, a.editor.Content()),
+			mainLayout,
+		)
+	}
+
+	if thumbnails := a.editor.Thumbnails(); thumbnails != "" && !a.scratchpad.Visible() {
+		editorY := a.height - editorHeight + 1
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			editorY-lipgloss.Height(thumbnails),
+			thumbnails,
+			mainLayout,
+		)
+	}
+
+	if panel := a.pinnedFilesPanel(); panel != "" && !a.fileViewer.HasFile() {
+		mainLayout = layout.PlaceOverlay(
+			a.width-lipgloss.Width(panel)-2,
+			1,
+			panel,
+			mainLayout,
+		)
+	}
+
+	panelsY := a.height - editorHeight + 1
+	if a.scratchpad.Visible() {
+		a.scratchpad.SetWidth(editorWidth)
+		panel := a.scratchpad.View()
+		panelsY -= lipgloss.Height(panel)
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			panelsY,
+			panel,
+			mainLayout,
+		)
+	}
+
+	if a.shell.Visible() {
+		a.shell.SetWidth(editorWidth)
+		panel := a.shell.View()
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			max(panelsY-lipgloss.Height(panel), 0),
+			panel,
 			mainLayout,
 		)
 	}
@@ -1042,6 +2965,13 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 	cmds := []tea.Cmd{
 		util.CmdHandler(commands.CommandExecutedMsg(command)),
 	}
+	if command.Custom != nil {
+		cmds = append(cmds, a.runCustomCommand(command))
+		return a, tea.Batch(cmds...)
+	}
+	if a.app.ReadOnly && command.Mutates() {
+		return a, toast.NewInfoToast(app.ReadOnlyMessage)
+	}
 	switch command.Name {
 	case commands.AppHelpCommand:
 		// Skip modal creation during active chat to prevent overlay corruption
@@ -1049,8 +2979,23 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 			slog.Warn("Attempted to create help modal during active chat")
 			return a, nil
 		}
-		helpDialog := dialog.NewHelpDialog(a.app)
+		helpDialog := dialog.NewHelpDialog(a.app, a.helpContext())
 		a.modal = helpDialog
+	case commands.CommandRepeatCommand:
+		name, ok := a.commandHistory.Last()
+		if !ok {
+			return a, toast.NewInfoToast("No command to repeat")
+		}
+		return a.executeCommand(a.app.Commands[name])
+	case commands.CommandHistoryCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create command history modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewCommandHistoryDialog(a.app, a.commandHistory)
+	case commands.AppPerformanceCommand:
+		a.showPerformance = !a.showPerformance
 	case commands.SwitchAgentCommand:
 		updated, cmd := a.app.SwitchAgent()
 		a.app = updated
@@ -1067,6 +3012,14 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		}
 		agentDialog := dialog.NewAgentsDialog(a.app)
 		a.modal = agentDialog
+	case commands.AgentEditCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create agent editor modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewAgentEditorDialog(a.app, nil)
+		cmds = append(cmds, a.modal.Init())
 	case commands.EditorOpenCommand:
 		if a.app.IsBusy() {
 			// status.Warn("Agent is working, please wait...")
@@ -1119,8 +3072,18 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 		a.app.Session = &opencode.Session{}
-		a.app.Messages = []app.Message{}
+		a.app.SetMessages([]app.Message{})
+		a.app.PendingSystem = ""
+		a.app.PendingPins = nil
 		cmds = append(cmds, util.CmdHandler(app.SessionClearedMsg{}))
+	case commands.SessionTemplateCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create template modal during active chat")
+			return a, nil
+		}
+		templatesDialog := dialog.NewTemplatesDialog(a.app)
+		a.modal = templatesDialog
 	case commands.SessionListCommand:
 		// Skip modal creation during active chat to prevent overlay corruption
 		if a.hasActiveChat() {
@@ -1129,6 +3092,95 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		}
 		sessionDialog := dialog.NewSessionDialog(a.app)
 		a.modal = sessionDialog
+	case commands.SessionCleanupCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create session cleanup modal during active chat")
+			return a, nil
+		}
+		cleanupDialog := dialog.NewSessionCleanupDialog(a.app)
+		a.modal = cleanupDialog
+	case commands.SnapshotRestoreCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create snapshots modal during active chat")
+			return a, nil
+		}
+		snapshotsDialog := dialog.NewSnapshotsDialog(a.app)
+		a.modal = snapshotsDialog
+	case commands.GitCommitCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create commit modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewCommitDialog(a.app)
+		cmds = append(cmds, a.modal.Init())
+	case commands.SessionReviewCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create review modal during active chat")
+			return a, nil
+		}
+		if a.app.Session.ID == "" {
+			return a, toast.NewInfoToast("No session to review")
+		}
+		a.modal = dialog.NewReviewDialog(a.app, a.reviewPath)
+		cmds = append(cmds, a.modal.Init())
+	case commands.SandboxStartCommand:
+		if sandbox, ok := a.app.Sandbox(); ok {
+			return a, toast.NewInfoToast("Already in the sandbox " + sandbox.Branch)
+		}
+		if err := a.app.StartSandbox(); err != nil {
+			return a, toast.NewErrorToast("Failed to start the sandbox: " + err.Error())
+		}
+		sandbox, _ := a.app.Sandbox()
+		cmds = append(cmds, a.app.SaveState(), toast.NewSuccessToast("Edits now go to "+sandbox.Branch))
+	case commands.SandboxFinishCommand:
+		sandbox, ok := a.app.Sandbox()
+		if !ok {
+			return a, toast.NewInfoToast("No sandbox is running")
+		}
+		cmds = append(cmds, util.CmdHandler(chat.ChoiceMsg{
+			ID:       "sandbox",
+			Question: fmt.Sprintf("Finish the sandbox %s?", sandbox.Branch),
+			Choices: []chat.Choice{
+				{Key: "m", Label: "Merge", Description: "Bring its commits and changes back to " + sandbox.Base},
+				{Key: "d", Label: "Discard", Description: "Go back to " + sandbox.Base + " as it was before the sandbox"},
+				{Key: "k", Label: "Keep working"},
+			},
+		}))
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create projects modal during active chat")
+			return a, nil
+		}
+		projectsDialog := dialog.NewProjectsDialog(a.app)
+		a.modal = projectsDialog
+	case commands.SessionLimitsCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create run limits modal during active chat")
+			return a, nil
+		}
+		limitsDialog := dialog.NewRunLimitsDialog(a.app)
+		a.modal = limitsDialog
+	case commands.SessionUsageCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create usage modal during active chat")
+			return a, nil
+		}
+		usageDialog := dialog.NewUsageDialog(a.app)
+		a.modal = usageDialog
+	case commands.SessionContextCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create context modal during active chat")
+			return a, nil
+		}
+		contextDialog := dialog.NewContextDialog(a.app)
+		a.modal = contextDialog
 	case commands.SessionShareCommand:
 		if a.app.Session.ID == "" {
 			return a, nil
@@ -1139,7 +3191,7 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 			return a, toast.NewErrorToast("Failed to share session")
 		}
 		shareUrl := response.Share.URL
-		cmds = append(cmds, app.SetClipboard(shareUrl))
+		cmds = append(cmds, a.app.SetClipboard(shareUrl))
 		cmds = append(cmds, toast.NewSuccessToast("Share URL copied to clipboard!"))
 	case commands.SessionUnshareCommand:
 		if a.app.Session.ID == "" {
@@ -1243,6 +3295,13 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		}
 		cmds = append(cmds, util.CmdHandler(chat.ToggleToolDetailsMsg{}))
 		cmds = append(cmds, toast.NewInfoToast(message))
+	case commands.MessagesAttributionCommand:
+		message := "Model and agent captions are now hidden"
+		if a.app.State.HideAttribution {
+			message = "Model and agent captions are now visible"
+		}
+		cmds = append(cmds, util.CmdHandler(chat.ToggleAttributionMsg{}))
+		cmds = append(cmds, toast.NewInfoToast(message))
 	case commands.ModelListCommand:
 		// Skip modal creation during active chat to prevent overlay corruption
 		if a.hasActiveChat() {
@@ -1251,14 +3310,36 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		}
 		modelDialog := dialog.NewModelDialog(a.app)
 		a.modal = modelDialog
+	case commands.ProviderAuthCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create provider auth modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewAuthDialog(a.app)
+	case commands.PermissionListCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create permission rules modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewPermissionsDialog(a.app)
 	case commands.ThemeListCommand:
 		// Skip modal creation during active chat to prevent overlay corruption
 		if a.hasActiveChat() {
 			slog.Warn("Attempted to create theme list modal during active chat")
 			return a, nil
 		}
-		themeDialog := dialog.NewThemeDialog()
+		themeDialog := dialog.NewThemeDialog(a.app)
 		a.modal = themeDialog
+	case commands.FileTreeCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create file tree modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewFileTreeDialog(a.app)
+		cmds = append(cmds, a.modal.Init())
 	// case commands.FileListCommand:
 	// 	a.editor.Blur()
 	// 	findDialog := dialog.NewFindDialog(a.fileProvider)
@@ -1267,6 +3348,24 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 	case commands.FileCloseCommand:
 		a.fileViewer, cmd = a.fileViewer.Clear()
 		cmds = append(cmds, cmd)
+		cmds = append(cmds, a.resizePanes())
+		cmds = append(cmds, a.focusFileViewer(false))
+	case commands.FileFocusCommand:
+		if !a.fileViewer.HasFile() {
+			return a, nil
+		}
+		cmds = append(cmds, a.focusFileViewer(!a.fileViewer.Focused()))
+	case commands.FileGrowCommand, commands.FileShrinkCommand:
+		if !a.fileViewer.HasFile() {
+			return a, nil
+		}
+		step := splitRatioStep
+		if command.Name == commands.FileGrowCommand {
+			step = -splitRatioStep
+		}
+		a.app.State.SplitRatio = min(max(a.splitRatio()+step, minSplitRatio), maxSplitRatio)
+		cmds = append(cmds, a.resizePanes())
+		cmds = append(cmds, a.app.SaveState())
 	case commands.FileDiffToggleCommand:
 		a.fileViewer, cmd = a.fileViewer.ToggleDiff()
 		cmds = append(cmds, cmd)
@@ -1274,6 +3373,37 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, a.app.SaveState())
 	case commands.FileSearchCommand:
 		return a, nil
+	case commands.FileGrepCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create grep modal during active chat")
+			return a, nil
+		}
+		a.editor.Blur()
+		a.modal = dialog.NewGrepDialog(a.app)
+		cmds = append(cmds, a.modal.Init())
+	case commands.SymbolListCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create symbols modal during active chat")
+			return a, nil
+		}
+		a.editor.Blur()
+		a.modal = dialog.NewSymbolsDialog(a.app)
+		cmds = append(cmds, a.modal.Init())
+	case commands.FilePinCommand:
+		if a.fileViewer.HasFile() {
+			return a, a.togglePinnedFile(a.fileViewer.Filename())
+		}
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create find modal during active chat")
+			return a, nil
+		}
+		a.editor.Blur()
+		findDialog := dialog.NewPinFileDialog(a.fileProvider)
+		cmds = append(cmds, findDialog.Init())
+		a.modal = findDialog
 	case commands.ProjectInitCommand:
 		cmds = append(cmds, a.app.InitializeProject(context.Background()))
 	case commands.InputClearCommand:
@@ -1287,6 +3417,19 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		updated, cmd := a.editor.Paste()
 		a.editor = updated.(chat.EditorComponent)
 		cmds = append(cmds, cmd)
+	case commands.InputOpenAttachmentCommand:
+		cmds = append(cmds, openAttachment(a.editor.AttachmentAtCursor()))
+	case commands.InputPreviewCommand:
+		att := a.editor.AttachmentAtCursor()
+		if att == nil {
+			return a, toast.NewInfoToast("Move the cursor onto an attachment to preview it")
+		}
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create attachment modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewAttachmentDialog(a.app, att)
 	case commands.InputSubmitCommand:
 		updated, cmd := a.editor.Submit()
 		a.editor = updated.(chat.EditorComponent)
@@ -1295,53 +3438,142 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		updated, cmd := a.editor.Newline()
 		a.editor = updated.(chat.EditorComponent)
 		cmds = append(cmds, cmd)
-	case commands.MessagesFirstCommand:
+	case commands.MessagesLineUpCommand:
+		updated, cmd := a.messages.LineUp()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
+	case commands.MessagesLineDownCommand:
+		updated, cmd := a.messages.LineDown()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
+	case commands.FileLineUpCommand:
+		a.fileViewer, cmd = a.fileViewer.LineUp()
+		cmds = append(cmds, cmd)
+	case commands.FileLineDownCommand:
+		a.fileViewer, cmd = a.fileViewer.LineDown()
+		cmds = append(cmds, cmd)
+	case commands.FilePageUpCommand:
+		a.fileViewer, cmd = a.fileViewer.PageUp()
+		cmds = append(cmds, cmd)
+	case commands.FilePageDownCommand:
+		a.fileViewer, cmd = a.fileViewer.PageDown()
+		cmds = append(cmds, cmd)
+	case commands.FileHalfPageUpCommand:
+		a.fileViewer, cmd = a.fileViewer.HalfPageUp()
+		cmds = append(cmds, cmd)
+	case commands.FileHalfPageDownCommand:
+		a.fileViewer, cmd = a.fileViewer.HalfPageDown()
+		cmds = append(cmds, cmd)
+	case commands.FileTopCommand:
+		a.fileViewer, cmd = a.fileViewer.GotoTop()
+		cmds = append(cmds, cmd)
+	case commands.FileBottomCommand:
+		a.fileViewer, cmd = a.fileViewer.GotoBottom()
+		cmds = append(cmds, cmd)
+	case commands.FileHunkNextCommand:
+		a.fileViewer, cmd = a.fileViewer.NextHunk()
+		cmds = append(cmds, cmd)
+	case commands.FileHunkPreviousCommand:
+		a.fileViewer, cmd = a.fileViewer.PreviousHunk()
+		cmds = append(cmds, cmd)
+	case commands.FileHunkStageCommand:
+		a.fileViewer, cmd = a.fileViewer.ToggleHunk()
+		cmds = append(cmds, cmd)
+	case commands.FileDefinitionCommand:
+		cmds = append(cmds, a.fileViewer.GotoDefinition())
+	case commands.FileFindCommand:
+		a.fileViewer, cmd = a.fileViewer.StartFind()
+		cmds = append(cmds, cmd)
+	case commands.FileFindNextCommand:
+		a.fileViewer, cmd = a.fileViewer.FindNext()
+		cmds = append(cmds, cmd)
+	case commands.FileFindPreviousCommand:
+		a.fileViewer, cmd = a.fileViewer.FindPrevious()
+		cmds = append(cmds, cmd)
+	case commands.FileGotoLineCommand:
+		a.fileViewer, cmd = a.fileViewer.StartGotoLine()
+		cmds = append(cmds, cmd)
+	case commands.FileBackCommand, commands.FileForwardCommand:
+		move := a.fileViewer.Back
+		if command.Name == commands.FileForwardCommand {
+			move = a.fileViewer.Forward
+		}
+		path, ok := move()
+		if !ok {
+			return a, nil
+		}
+		updated, cmd := a.openFile(path)
+		a = updated.(Model)
+		cmds = append(cmds, cmd)
+	case commands.FileEditCommand:
+		if !a.fileViewer.HasFile() {
+			return a, nil
+		}
+		cmds = append(cmds, a.editFile(a.fileViewer.Filename(), a.fileViewer.CurrentLine()))
+	case commands.ToastActionCommand:
+		current, ok := a.toastManager.Actionable()
+		if !ok {
+			return a, nil
+		}
+		cmds = append(cmds, a.runToastActions(current))
+	case commands.NotificationListCommand:
+		a.modal = dialog.NewNotificationsDialog(a.app, a.toastManager.History())
+		cmds = append(cmds, a.modal.Init())
+	case commands.FileViewedCommand:
+		// Skip modal creation during active chat to prevent overlay corruption
+		if a.hasActiveChat() {
+			slog.Warn("Attempted to create viewed files modal during active chat")
+			return a, nil
+		}
+		a.modal = dialog.NewViewedFilesDialog(a.fileViewer.History(), a.fileViewer.Filename())
+		cmds = append(cmds, a.modal.Init())
+	case commands.MessagesFirstCommand, commands.MessagesTopCommand:
 		updated, cmd := a.messages.GotoTop()
 		a.messages = updated.(chat.MessagesComponent)
 		cmds = append(cmds, cmd)
-	case commands.MessagesLastCommand:
+	case commands.MessagesLastCommand, commands.MessagesBottomCommand:
 		updated, cmd := a.messages.GotoBottom()
 		a.messages = updated.(chat.MessagesComponent)
 		cmds = append(cmds, cmd)
 	case commands.MessagesPageUpCommand:
-		if a.fileViewer.HasFile() {
-			a.fileViewer, cmd = a.fileViewer.PageUp()
-			cmds = append(cmds, cmd)
-		} else {
-			updated, cmd := a.messages.PageUp()
-			a.messages = updated.(chat.MessagesComponent)
-			cmds = append(cmds, cmd)
-		}
+		updated, cmd := a.messages.PageUp()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
 	case commands.MessagesPageDownCommand:
-		if a.fileViewer.HasFile() {
-			a.fileViewer, cmd = a.fileViewer.PageDown()
-			cmds = append(cmds, cmd)
-		} else {
-			updated, cmd := a.messages.PageDown()
-			a.messages = updated.(chat.MessagesComponent)
-			cmds = append(cmds, cmd)
-		}
+		updated, cmd := a.messages.PageDown()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
 	case commands.MessagesHalfPageUpCommand:
-		if a.fileViewer.HasFile() {
-			a.fileViewer, cmd = a.fileViewer.HalfPageUp()
-			cmds = append(cmds, cmd)
-		} else {
-			updated, cmd := a.messages.HalfPageUp()
-			a.messages = updated.(chat.MessagesComponent)
-			cmds = append(cmds, cmd)
-		}
+		updated, cmd := a.messages.HalfPageUp()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
 	case commands.MessagesHalfPageDownCommand:
-		if a.fileViewer.HasFile() {
-			a.fileViewer, cmd = a.fileViewer.HalfPageDown()
-			cmds = append(cmds, cmd)
+		updated, cmd := a.messages.HalfPageDown()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
+	case commands.MessagesLayoutToggleCommand:
+		a.messagesRight = !a.messagesRight
+		a.app.State.MessagesRight = a.messagesRight
+		cmds = append(cmds, a.app.SaveState())
+	case commands.MessagesScrollLockCommand:
+		a.app.State.ScrollLock = !a.app.State.ScrollLock
+		message := "Auto-scroll on, following new output"
+		if a.app.State.ScrollLock {
+			message = "Auto-scroll off, the view stays put"
 		} else {
-			updated, cmd := a.messages.HalfPageDown()
+			updated, cmd := a.messages.GotoBottom()
 			a.messages = updated.(chat.MessagesComponent)
 			cmds = append(cmds, cmd)
 		}
-	case commands.MessagesLayoutToggleCommand:
-		a.messagesRight = !a.messagesRight
-		a.app.State.MessagesRight = a.messagesRight
+		cmds = append(cmds, toast.NewInfoToast(message))
+		cmds = append(cmds, a.app.SaveState())
+	case commands.FileFollowCommand:
+		a.app.State.FollowEdits = !a.app.State.FollowEdits
+		message := "Following edits, files open as the agent changes them"
+		if !a.app.State.FollowEdits {
+			message = "Stopped following edits"
+		}
+		cmds = append(cmds, toast.NewInfoToast(message))
 		cmds = append(cmds, a.app.SaveState())
 	case commands.MessagesCopyCommand:
 		updated, cmd := a.messages.CopyLastMessage()
@@ -1355,6 +3587,49 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		updated, cmd := a.messages.RedoLastMessage()
 		a.messages = updated.(chat.MessagesComponent)
 		cmds = append(cmds, cmd)
+	case commands.MessagesRetryCommand:
+		if a.app.IsBusy() {
+			return a, toast.NewInfoToast("Wait for the current response to finish before retrying")
+		}
+		prompt, err := app.LastPrompt(a.app.Messages, a.app.Session.Revert.MessageID)
+		if err != nil {
+			return a, toast.NewInfoToast("No message to retry")
+		}
+		cmds = append(cmds, util.CmdHandler(app.SendPrompt(*prompt)))
+	case commands.ScratchpadToggleCommand:
+		a.scratchpad, cmd = a.scratchpad.Toggle()
+		cmds = append(cmds, cmd)
+		if a.scratchpad.Focused() {
+			a.editor.Blur()
+		} else {
+			updated, cmd := a.editor.Focus()
+			a.editor = updated.(chat.EditorComponent)
+			cmds = append(cmds, cmd)
+		}
+	case commands.ShellOutputCommand:
+		a.shell, cmd = a.shell.Toggle()
+		cmds = append(cmds, cmd)
+		if a.shell.Focused() {
+			a.editor.Blur()
+		} else {
+			updated, cmd := a.editor.Focus()
+			a.editor = updated.(chat.EditorComponent)
+			cmds = append(cmds, cmd)
+		}
+	case commands.ToolOutputAttachCommand:
+		output, ok := app.LastToolOutput(a.app.Messages)
+		if !ok {
+			return a, toast.NewInfoToast("No tool output to attach")
+		}
+		a.editor.AttachText(output.Tool, output.Text)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+	case commands.ShellModeCommand:
+		a.editor.ToggleShellMode()
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
 	case commands.AppExitCommand:
 		return a, tea.Quit
 	}
@@ -1362,14 +3637,12 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 }
 
 func NewModel(app *app.App) tea.Model {
-	commandProvider := completions.NewCommandCompletionProvider(app)
-	fileProvider := completions.NewFileContextGroup(app)
-	symbolsProvider := completions.NewSymbolsContextGroup(app)
-	agentsProvider := completions.NewAgentsContextGroup(app)
+	providers := completions.Providers(app)
+	triggers := completions.Triggers(app, providers)
 
 	messages := chat.NewMessagesComponent(app)
 	editor := chat.NewEditorComponent(app)
-	completions := dialog.NewCompletionDialogComponent("/", commandProvider)
+	completions := dialog.NewCompletionDialogComponent("/", providers["commands"])
 
 	var leaderBinding *key.Binding
 	if app.Config.Keybinds.Leader != "" {
@@ -1377,23 +3650,35 @@ func NewModel(app *app.App) tea.Model {
 		leaderBinding = &binding
 	}
 
+	toastManager := toast.NewToastManager()
+	if command, ok := app.Commands[commands.ToastActionCommand]; ok && len(command.Keybindings) > 0 {
+		binding := command.Keybindings[0]
+		if binding.RequiresLeader {
+			toastManager.SetActionKey(app.Config.Keybinds.Leader + " " + binding.Key)
+		} else {
+			toastManager.SetActionKey(binding.Key)
+		}
+	}
+
 	model := &Model{
 		status:               status.NewStatusCmp(app),
 		app:                  app,
 		editor:               editor,
 		messages:             messages,
 		completions:          completions,
-		commandProvider:      commandProvider,
-		fileProvider:         fileProvider,
-		symbolsProvider:      symbolsProvider,
-		agentsProvider:       agentsProvider,
+		fileProvider:         providers["files"],
+		triggers:             triggers,
 		leaderBinding:        leaderBinding,
 		showCompletionDialog: false,
-		toastManager:         toast.NewToastManager(),
+		toastManager:         toastManager,
+		latency:              util.NewLatencyTracker(time.Duration(app.Config.Tui.LatencyBudget * float64(time.Millisecond))),
 		interruptKeyState:    InterruptKeyIdle,
 		exitKeyState:         ExitKeyIdle,
 		fileViewer:           fileviewer.New(app),
+		scratchpad:           scratchpad.New(app),
+		shell:                shell.New(app),
 		messagesRight:        app.State.MessagesRight,
+		zones:                layout.NewZones(),
 		// Initialize focus state - assume focused on startup
 		hasFocus:       true,
 		focusSupported: false, // Will be set to true when first focus event is received
@@ -1402,6 +3687,13 @@ func NewModel(app *app.App) tea.Model {
 	// Set initial focus state in editor
 	editor.SetFocusState(model.hasFocus, model.focusSupported)
 
+	themeWatcher, err := theme.NewWatcher(app.Info.Path.Config, app.Info.Path.Root, app.Info.Path.Cwd)
+	if err != nil {
+		slog.Warn("Failed to watch theme files", "error", err)
+	} else {
+		model.themeWatcher = themeWatcher
+	}
+
 	return model
 }
 
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/sst/opencode/internal/commands"
+)
+
+func TestLeaderKeys(t *testing.T) {
+	registry := commands.CommandRegistry{
+		commands.SessionNewCommand: {
+			Name:        commands.SessionNewCommand,
+			Description: "new session",
+			Keybindings: []commands.Keybinding{{RequiresLeader: true, Key: "n"}},
+		},
+		commands.AppHelpCommand: {
+			Name:        commands.AppHelpCommand,
+			Description: "show help",
+			Keybindings: []commands.Keybinding{{RequiresLeader: true, Key: "h"}, {Key: "f1"}},
+		},
+		commands.InputSubmitCommand: {
+			Name:        commands.InputSubmitCommand,
+			Description: "submit message",
+			Keybindings: []commands.Keybinding{{Key: "enter"}},
+		},
+	}
+	keys := leaderKeys(registry)
+	expected := []leaderKey{{"h", "show help"}, {"n", "new session"}}
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("expected %v at %d, got %v", expected[i], i, keys[i])
+		}
+	}
+}
@@ -1,18 +1,23 @@
 package util
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 var SUPPORTED_IDES = []struct {
 	Search    string
 	ShortName string
+	// Command is the IDE's command line tool
+	Command string
 }{
-	{"Windsurf", "Windsurf"},
-	{"Visual Studio Code", "VS Code"},
-	{"Cursor", "Cursor"},
-	{"VSCodium", "VSCodium"},
+	{"Windsurf", "Windsurf", "windsurf"},
+	{"Visual Studio Code", "VS Code", "code"},
+	{"Cursor", "Cursor", "cursor"},
+	{"VSCodium", "VSCodium", "codium"},
 }
 
 func IsVSCode() bool {
@@ -28,3 +33,65 @@ func Ide() string {
 
 	return "unknown"
 }
+
+// ideCommand returns the command line tool of the IDE kuuzuki runs in, empty
+// when there is none or it isn't installed
+func ideCommand() string {
+	command := ""
+	if IsVSCode() {
+		command = "code"
+	}
+	for _, ide := range SUPPORTED_IDES {
+		if strings.Contains(os.Getenv("GIT_ASKPASS"), ide.Search) {
+			command = ide.Command
+		}
+	}
+	if command == "" {
+		return ""
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return ""
+	}
+	return command
+}
+
+// guiEditors are editors opening a window of their own, the rest take over
+// the terminal
+var guiEditors = map[string]bool{
+	"code":          true,
+	"code-insiders": true,
+	"codium":        true,
+	"cursor":        true,
+	"windsurf":      true,
+	"subl":          true,
+	"zed":           true,
+}
+
+// EditCommand returns the command opening path at the 1-based line, in the
+// IDE kuuzuki runs in or else in $EDITOR. gui reports whether it opens a
+// window of its own rather than taking over the terminal.
+func EditCommand(path string, line int) (cmd *exec.Cmd, gui bool, err error) {
+	editor := ideCommand()
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	parts := strings.Fields(editor)
+	if len(parts) == 0 {
+		return nil, false, fmt.Errorf("no EDITOR set, can't open %s", path)
+	}
+	args := append(parts[1:], editArgs(parts[0], path, line)...)
+	return exec.Command(parts[0], args...), guiEditors[filepath.Base(parts[0])], nil //nolint:gosec
+}
+
+// editArgs returns the arguments opening path at line in editor
+func editArgs(editor, path string, line int) []string {
+	location := fmt.Sprintf("%s:%d", path, line)
+	switch filepath.Base(editor) {
+	case "code", "code-insiders", "codium", "cursor", "windsurf":
+		return []string{"--goto", location}
+	case "subl", "zed", "hx", "helix":
+		return []string{location}
+	}
+	// vi, vim, nvim, nano, emacs, micro, kak and most others
+	return []string{fmt.Sprintf("+%d", line), path}
+}
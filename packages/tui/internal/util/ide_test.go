@@ -0,0 +1,41 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditArgs(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   []string
+	}{
+		{"nvim", []string{"+12", "main.go"}},
+		{"/usr/bin/vim", []string{"+12", "main.go"}},
+		{"code", []string{"--goto", "main.go:12"}},
+		{"hx", []string{"main.go:12"}},
+	}
+	for _, tt := range tests {
+		if got := editArgs(tt.editor, "main.go", 12); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("editArgs(%q) = %v, want %v", tt.editor, got, tt.want)
+		}
+	}
+}
+
+func TestEditCommand(t *testing.T) {
+	t.Setenv("KUUZUKI_CALLER", "")
+	t.Setenv("GIT_ASKPASS", "")
+	t.Setenv("EDITOR", "code --wait")
+	cmd, gui, err := EditCommand("main.go", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gui || !reflect.DeepEqual(cmd.Args, []string{"code", "--wait", "--goto", "main.go:3"}) {
+		t.Errorf("EditCommand() = %v, %v", cmd.Args, gui)
+	}
+
+	t.Setenv("EDITOR", "")
+	if _, _, err := EditCommand("main.go", 3); err == nil {
+		t.Error("expected an error without an EDITOR")
+	}
+}
@@ -0,0 +1,135 @@
+package util
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBudget is the key press to render time within which typing
+// feels instant, one frame at 60fps
+const DefaultLatencyBudget = 16 * time.Millisecond
+
+// LatencyBuckets are the upper bounds of the histogram buckets, the last
+// bucket holds everything slower
+var LatencyBuckets = []time.Duration{
+	4 * time.Millisecond,
+	8 * time.Millisecond,
+	16 * time.Millisecond,
+	33 * time.Millisecond,
+	66 * time.Millisecond,
+	133 * time.Millisecond,
+}
+
+// latencySamples is the number of recent samples kept for percentiles
+const latencySamples = 256
+
+// LatencyTracker measures the time from a key press to the next render
+type LatencyTracker struct {
+	mu         sync.Mutex
+	budget     time.Duration
+	pending    time.Time
+	pendingKey string
+	buckets    []int
+	samples    []time.Duration
+	next       int
+	count      int
+	violations int
+	max        time.Duration
+}
+
+// LatencyStats is a snapshot of the tracked latencies
+type LatencyStats struct {
+	Budget     time.Duration
+	Count      int
+	Violations int
+	Max        time.Duration
+	P50        time.Duration
+	P95        time.Duration
+	// Buckets counts the samples per bucket of LatencyBuckets, plus one
+	// for the samples slower than the last bucket
+	Buckets []int
+}
+
+func NewLatencyTracker(budget time.Duration) *LatencyTracker {
+	if budget <= 0 {
+		budget = DefaultLatencyBudget
+	}
+	return &LatencyTracker{
+		budget:  budget,
+		buckets: make([]int, len(LatencyBuckets)+1),
+	}
+}
+
+// KeyPressed starts timing a key press, unless one is already waiting for a
+// render, so bursts are measured from their first key
+func (l *LatencyTracker) KeyPressed(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending.IsZero() {
+		l.pending = time.Now()
+		l.pendingKey = key
+	}
+}
+
+// Rendered records the time since the pending key press, if any, logging it
+// when over budget
+func (l *LatencyTracker) Rendered() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending.IsZero() {
+		return
+	}
+	latency := time.Since(l.pending)
+	key := l.pendingKey
+	l.pending = time.Time{}
+	l.record(latency)
+	if latency > l.budget {
+		slog.Warn("Input latency over budget", "key", key, "latencyMs", latency.Milliseconds(), "budgetMs", l.budget.Milliseconds())
+	}
+}
+
+func (l *LatencyTracker) record(latency time.Duration) {
+	bucket := len(LatencyBuckets)
+	for i, bound := range LatencyBuckets {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	l.buckets[bucket]++
+	l.count++
+	if latency > l.budget {
+		l.violations++
+	}
+	l.max = max(l.max, latency)
+
+	if len(l.samples) < latencySamples {
+		l.samples = append(l.samples, latency)
+	} else {
+		l.samples[l.next] = latency
+	}
+	l.next = (l.next + 1) % latencySamples
+}
+
+// Stats returns a snapshot of the latencies recorded so far, with
+// percentiles over the most recent samples
+func (l *LatencyTracker) Stats() LatencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := LatencyStats{
+		Budget:     l.budget,
+		Count:      l.count,
+		Violations: l.violations,
+		Max:        l.max,
+		Buckets:    slices.Clone(l.buckets),
+	}
+	if len(l.samples) > 0 {
+		sorted := slices.Clone(l.samples)
+		slices.Sort(sorted)
+		stats.P50 = sorted[len(sorted)*50/100]
+		stats.P95 = sorted[min(len(sorted)*95/100, len(sorted)-1)]
+	}
+	return stats
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLatencyTrackerStats(t *testing.T) {
+	tracker := NewLatencyTracker(0)
+	if tracker.budget != DefaultLatencyBudget {
+		t.Fatalf("budget = %v, want the default", tracker.budget)
+	}
+
+	for _, latency := range []time.Duration{
+		2 * time.Millisecond,
+		3 * time.Millisecond,
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		200 * time.Millisecond,
+	} {
+		tracker.record(latency)
+	}
+
+	stats := tracker.Stats()
+	if want := []int{2, 0, 1, 1, 0, 0, 1}; !slices.Equal(stats.Buckets, want) {
+		t.Errorf("buckets = %v, want %v", stats.Buckets, want)
+	}
+	if stats.Count != 5 || stats.Violations != 2 {
+		t.Errorf("count/violations = %d/%d, want 5/2", stats.Count, stats.Violations)
+	}
+	if stats.Max != 200*time.Millisecond || stats.P50 != 10*time.Millisecond {
+		t.Errorf("max/p50 = %v/%v, want 200ms/10ms", stats.Max, stats.P50)
+	}
+}
+
+func TestLatencyTrackerMeasuresFromFirstKey(t *testing.T) {
+	tracker := NewLatencyTracker(time.Hour)
+	tracker.Rendered()
+	if tracker.Stats().Count != 0 {
+		t.Fatal("recorded a render without a key press")
+	}
+
+	tracker.KeyPressed("a")
+	first := tracker.pending
+	tracker.KeyPressed("b")
+	if tracker.pending != first || tracker.pendingKey != "a" {
+		t.Error("a second key press restarted the measurement")
+	}
+	tracker.Rendered()
+	tracker.Rendered()
+	if count := tracker.Stats().Count; count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+}
@@ -0,0 +1,280 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spaces     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToMarkdown converts HTML, like a page or a document copied to the
+// clipboard, to Markdown, keeping its headings, lists, links and code
+// blocks. It reports false when the HTML has nothing Markdown would keep
+// over the plain text, like the highlighted code copied from an editor.
+func HTMLToMarkdown(src string) (string, bool) {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return "", false
+	}
+	w := &markdownWriter{}
+	markdown := tidyMarkdown(w.children(doc))
+	return markdown, w.rich && markdown != ""
+}
+
+// markdownWriter renders HTML nodes as Markdown
+type markdownWriter struct {
+	// rich is set once an element with a Markdown equivalent is rendered
+	rich bool
+}
+
+// children renders the children of n one after the other
+func (w *markdownWriter) children(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		text := w.node(child)
+		if b.Len() == 0 || strings.HasSuffix(b.String(), "\n") {
+			text = strings.TrimLeft(text, " ")
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func (w *markdownWriter) node(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return spaces.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return w.children(n)
+	}
+
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template:
+		return ""
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.rich = true
+		level := int(n.Data[1] - '0')
+		return block(strings.Repeat("#", level) + " " + oneLine(w.children(n)))
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Main, atom.Nav, atom.Aside, atom.Figure, atom.Dl, atom.Dt, atom.Dd:
+		return block(w.children(n))
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		w.rich = true
+		return block("---")
+	case atom.Pre:
+		return w.pre(n)
+	case atom.Code, atom.Kbd, atom.Samp:
+		w.rich = true
+		return inlineCode(spaces.ReplaceAllString(textContent(n), " "))
+	case atom.A:
+		text := strings.TrimSpace(w.children(n))
+		href := strings.TrimSpace(attr(n, "href"))
+		if text == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		w.rich = true
+		if text == href {
+			return "<" + href + ">"
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Strong, atom.B:
+		return w.emphasis(n, "**")
+	case atom.Em, atom.I:
+		return w.emphasis(n, "_")
+	case atom.Del, atom.S:
+		return w.emphasis(n, "~~")
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return attr(n, "alt")
+		}
+		w.rich = true
+		return "![" + attr(n, "alt") + "](" + src + ")"
+	case atom.Ul, atom.Ol:
+		return w.list(n)
+	case atom.Blockquote:
+		w.rich = true
+		lines := strings.Split(tidyMarkdown(w.children(n)), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return block(strings.Join(lines, "\n"))
+	case atom.Table:
+		return w.table(n)
+	}
+	return w.children(n)
+}
+
+// pre renders a fenced code block, in the language named by a class of the
+// block or of its code, like language-go
+func (w *markdownWriter) pre(n *html.Node) string {
+	code := strings.Trim(textContent(n), "\n")
+	language := ""
+	for _, node := range []*html.Node{n, n.FirstChild} {
+		if node == nil || node.Type != html.ElementNode {
+			continue
+		}
+		for _, class := range strings.Fields(attr(node, "class")) {
+			for _, prefix := range []string{"language-", "lang-", "highlight-source-"} {
+				if name, ok := strings.CutPrefix(class, prefix); ok && language == "" {
+					language = name
+				}
+			}
+		}
+	}
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return "\n\n" + fence + language + "\n" + code + "\n" + fence + "\n\n"
+}
+
+// emphasis wraps the text of n in the marker, leaving its surrounding
+// spaces outside
+func (w *markdownWriter) emphasis(n *html.Node, marker string) string {
+	text := w.children(n)
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || strings.Contains(trimmed, "\n") {
+		return text
+	}
+	w.rich = true
+	start := strings.Index(text, trimmed)
+	return text[:start] + marker + trimmed + marker + text[start+len(trimmed):]
+}
+
+// list renders the items of a list, numbered for ol, with the lines of
+// each item indented under its marker
+func (w *markdownWriter) list(n *html.Node) string {
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+	var items []string
+	for item := n.FirstChild; item != nil; item = item.NextSibling {
+		if item.Type != html.ElementNode || item.DataAtom != atom.Li {
+			continue
+		}
+		w.rich = true
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+		content := blankLines.ReplaceAllString(tidyMarkdown(w.children(item)), "\n")
+		content = strings.ReplaceAll(content, "\n\n", "\n")
+		indent := "\n" + strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.ReplaceAll(content, "\n", indent))
+	}
+	return block(strings.Join(items, "\n"))
+}
+
+// table renders a table as a pipe table, its first row as the header
+func (w *markdownWriter) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(child)
+			case atom.Tr:
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Th || cell.DataAtom == atom.Td {
+						cells = append(cells, strings.ReplaceAll(oneLine(w.children(cell)), "|", `\|`))
+					}
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+	w.rich = true
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return block(strings.Join(lines, "\n"))
+}
+
+// block sets the text apart from what surrounds it with blank lines
+func block(text string) string {
+	return "\n\n" + strings.TrimSpace(text) + "\n\n"
+}
+
+// oneLine joins the lines of the text with spaces
+func oneLine(text string) string {
+	return strings.TrimSpace(spaces.ReplaceAllString(text, " "))
+}
+
+// inlineCode wraps the text in enough backticks for those it contains
+func inlineCode(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	ticks := "`"
+	for strings.Contains(text, ticks) {
+		ticks += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return ticks + text + ticks
+}
+
+// tidyMarkdown drops trailing spaces and runs of blank lines
+func tidyMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// textContent is the text of n and its descendants, as written
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.DataAtom == atom.Br {
+		return "\n"
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
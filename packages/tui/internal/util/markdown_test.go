@@ -0,0 +1,72 @@
+package util
+
+import "testing"
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+		rich bool
+	}{
+		{
+			name: "headings and paragraphs",
+			html: "<h2>Install</h2>\n<p>Run the\n  installer <strong>first</strong>.</p><p>Then restart.</p>",
+			want: "## Install\n\nRun the installer **first**.\n\nThen restart.",
+			rich: true,
+		},
+		{
+			name: "links",
+			html: `<p>See <a href="https://kuuzuki.com/docs">the docs</a> or <a href="https://kuuzuki.com">https://kuuzuki.com</a>, <a href="#top">top</a></p>`,
+			want: "See [the docs](https://kuuzuki.com/docs) or <https://kuuzuki.com>, top",
+			rich: true,
+		},
+		{
+			name: "code block",
+			html: "<p>Use <code>go test</code>:</p><pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"hi\")\n}\n</code></pre>",
+			want: "Use `go test`:\n\n```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```",
+			rich: true,
+		},
+		{
+			name: "nested lists",
+			html: "<ol><li>Build<ul><li>with <em>make</em></li></ul></li><li>Ship</li></ol>",
+			want: "1. Build\n   - with _make_\n2. Ship",
+			rich: true,
+		},
+		{
+			name: "blockquote",
+			html: "<blockquote><p>One</p><p>Two</p></blockquote>",
+			want: "> One\n>\n> Two",
+			rich: true,
+		},
+		{
+			name: "table",
+			html: "<table><tr><th>Key</th><th>Action</th></tr><tr><td>a</td><td>x | y</td></tr></table>",
+			want: "| Key | Action |\n| --- | --- |\n| a | x \\| y |",
+			rich: true,
+		},
+		{
+			name: "editor highlighting",
+			html: "<div><span>if</span> <span>ok</span> {<br><span>  return</span><br>}</div>",
+			want: "if ok {\nreturn\n}",
+			rich: false,
+		},
+		{
+			name: "code only",
+			html: "<pre><span>x := 1</span></pre><script>alert(1)</script>",
+			want: "```\nx := 1\n```",
+			rich: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rich := HTMLToMarkdown(tt.html)
+			if got != tt.want {
+				t.Errorf("HTMLToMarkdown() = %q, want %q", got, tt.want)
+			}
+			if rich != tt.rich {
+				t.Errorf("HTMLToMarkdown() rich = %v, want %v", rich, tt.rich)
+			}
+		})
+	}
+}
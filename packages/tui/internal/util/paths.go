@@ -0,0 +1,135 @@
+package util
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var (
+	// drivePath matches a Windows path starting with a drive letter
+	drivePath = regexp.MustCompile(`^([A-Za-z]):(/|$)`)
+	// mountPath matches a Windows drive mounted in WSL
+	mountPath = regexp.MustCompile(`^/mnt/([A-Za-z])(/|$)`)
+	// shellEscape matches a character escaped by a terminal when a file is
+	// dropped into it, like the spaces of a name
+	shellEscape = regexp.MustCompile(`\\([ '"()\[\]&;!$#*?{}])`)
+)
+
+// pathPlatform is what a pasted path has to be opened on
+type pathPlatform struct {
+	windows bool
+	// wsl is set on Linux under WSL, where Windows drives are under /mnt
+	wsl bool
+}
+
+// NormalizePastedPath turns a path pasted or dropped into the terminal into
+// one that can be opened here. It takes file:// URIs, and the backslashes,
+// drive letters and \\wsl$ shares of paths from Windows terminals, Explorer
+// and WSL interop.
+func NormalizePastedPath(p string) string {
+	return normalizePath(p, pathPlatform{
+		windows: runtime.GOOS == "windows",
+		wsl:     runtime.GOOS == "linux" && IsWsl(),
+	})
+}
+
+// IsWindowsPath reports whether p is a Windows path, with a drive letter or
+// a UNC share
+func IsWindowsPath(p string) bool {
+	slashed := strings.ReplaceAll(p, `\`, "/")
+	return strings.HasPrefix(slashed, "//") || drivePath.MatchString(slashed)
+}
+
+func normalizePath(p string, platform pathPlatform) string {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return p
+	}
+	if strings.HasPrefix(strings.ToLower(p), "file://") {
+		p = fromFileURI(p)
+	}
+
+	slashed := strings.ReplaceAll(p, `\`, "/")
+	if !platform.windows && !IsWindowsPath(p) {
+		if strings.HasPrefix(p, "/") || strings.HasPrefix(p, "~") {
+			// a POSIX path, its backslashes escape characters of names
+			return path.Clean(shellEscape.ReplaceAllString(p, "$1"))
+		}
+		if _, err := os.Stat(p); err == nil {
+			return path.Clean(p)
+		}
+	}
+
+	if _, rest, ok := cutWSLShare(slashed); ok {
+		if platform.windows {
+			return windowsClean(slashed)
+		}
+		// a share of this distribution, or of another one that can't be
+		// reached from here anyway
+		return path.Clean("/" + rest)
+	}
+	if match := drivePath.FindStringSubmatch(slashed); match != nil {
+		switch {
+		case platform.windows:
+			return windowsClean(slashed)
+		case platform.wsl:
+			return path.Clean("/mnt/" + strings.ToLower(match[1]) + "/" + slashed[2:])
+		}
+		return p
+	}
+	if platform.windows {
+		if match := mountPath.FindStringSubmatch(slashed); match != nil {
+			return windowsClean(strings.ToUpper(match[1]) + ":/" + slashed[len(match[0]):])
+		}
+		return windowsClean(slashed)
+	}
+	return path.Clean(slashed)
+}
+
+// fromFileURI returns the path of a file:// URI, a Windows path for one
+// with a drive letter and a UNC path for one with a host
+func fromFileURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri[len("file://"):]
+	}
+	p := u.Path
+	if drivePath.MatchString(strings.TrimPrefix(p, "/")) {
+		return strings.TrimPrefix(p, "/")
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "//" + u.Host + p
+	}
+	return p
+}
+
+// cutWSLShare splits a \\wsl$ or \\wsl.localhost path, with forward
+// slashes, into the distribution and the path inside it
+func cutWSLShare(p string) (distro string, rest string, ok bool) {
+	for _, share := range []string{"//wsl$/", "//wsl.localhost/"} {
+		if len(p) < len(share) || !strings.EqualFold(p[:len(share)], share) {
+			continue
+		}
+		distro, rest, _ = strings.Cut(p[len(share):], "/")
+		return distro, rest, distro != ""
+	}
+	return "", "", false
+}
+
+// windowsClean cleans a path with forward slashes and returns it with
+// backslashes, keeping the leading pair of a UNC path
+func windowsClean(p string) string {
+	unc := strings.HasPrefix(p, "//")
+	cleaned := path.Clean(p)
+	if unc {
+		cleaned = "/" + cleaned
+	}
+	if drivePath.MatchString(cleaned) && len(cleaned) == 2 {
+		cleaned += "/"
+	}
+	return strings.ReplaceAll(cleaned, "/", `\`)
+}
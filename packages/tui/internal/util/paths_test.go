@@ -0,0 +1,43 @@
+package util
+
+import "testing"
+
+func TestNormalizePath(t *testing.T) {
+	linux := pathPlatform{}
+	wsl := pathPlatform{wsl: true}
+	windows := pathPlatform{windows: true}
+	tests := []struct {
+		name     string
+		path     string
+		platform pathPlatform
+		want     string
+	}{
+		{"posix path", "/home/u/notes.md", linux, "/home/u/notes.md"},
+		{"escaped spaces", `/home/u/my\ file\ (1).png`, linux, "/home/u/my file (1).png"},
+		{"file uri", "file:///home/u/my%20file.png", linux, "/home/u/my file.png"},
+		{"relative backslashes", `src\main.go`, linux, "src/main.go"},
+		{"drive letter outside wsl", `C:\Users\u\a.png`, linux, `C:\Users\u\a.png`},
+		{"drive letter in wsl", `C:\Users\u\a.png`, wsl, "/mnt/c/Users/u/a.png"},
+		{"drive letter slashes in wsl", "d:/work/a.go", wsl, "/mnt/d/work/a.go"},
+		{"drive file uri in wsl", "file:///C:/Users/u/My%20Pictures/a.png", wsl, "/mnt/c/Users/u/My Pictures/a.png"},
+		{"wsl share", `\\wsl$\Ubuntu\home\u\a.go`, wsl, "/home/u/a.go"},
+		{"wsl localhost share", `\\wsl.localhost\Ubuntu\home\u\a.go`, linux, "/home/u/a.go"},
+		{"wsl share uri", "file://wsl.localhost/Ubuntu/home/u/a.go", wsl, "/home/u/a.go"},
+		{"windows path", `C:\Users\u\..\a.png`, windows, `C:\Users\a.png`},
+		{"windows forward slashes", "C:/Users/u/a.png", windows, `C:\Users\u\a.png`},
+		{"windows drive root", "C:", windows, `C:\`},
+		{"windows file uri", "file:///C:/Users/u/a%20b.png", windows, `C:\Users\u\a b.png`},
+		{"windows unc", `\\server\share\a.png`, windows, `\\server\share\a.png`},
+		{"windows unc uri", "file://server/share/a.png", windows, `\\server\share\a.png`},
+		{"windows wsl share", `\\wsl$\Ubuntu\home\u\a.go`, windows, `\\wsl$\Ubuntu\home\u\a.go`},
+		{"windows wsl mount", "/mnt/c/Users/u/a.png", windows, `C:\Users\u\a.png`},
+		{"windows relative", "src/main.go", windows, `src\main.go`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizePath(tt.path, tt.platform); got != tt.want {
+				t.Errorf("normalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
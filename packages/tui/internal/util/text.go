@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -50,3 +51,19 @@ func GetMarkdownContainerFrame() int {
 	// Markdown containers use the same styling as message containers
 	return GetMessageContainerFrame()
 }
+
+// FormatTokens formats a token count in a human-readable form (e.g. 110K, 1.2M)
+func FormatTokens(tokens float64) string {
+	var formatted string
+	switch {
+	case tokens >= 1_000_000:
+		formatted = fmt.Sprintf("%.1fM", tokens/1_000_000)
+	case tokens >= 1_000:
+		formatted = fmt.Sprintf("%.1fK", tokens/1_000)
+	default:
+		formatted = fmt.Sprintf("%d", int(tokens))
+	}
+	formatted = strings.Replace(formatted, ".0K", "K", 1)
+	formatted = strings.Replace(formatted, ".0M", "M", 1)
+	return formatted
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// RenderThumbnail draws an image with unicode half blocks, two pixels per
+// cell, scaled down to fit within width x height cells
+func RenderThumbnail(data []byte, width, height int) (string, bool) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 || width <= 0 || height <= 0 {
+		return "", false
+	}
+
+	// each cell holds one pixel horizontally and two vertically
+	scale := min(float64(width)/float64(bounds.Dx()), float64(height*2)/float64(bounds.Dy()))
+	cols := max(int(float64(bounds.Dx())*scale), 1)
+	rows := max(int(float64(bounds.Dy())*scale)/2, 1)
+
+	sample := func(x, y int) (int, int) {
+		return bounds.Min.X + x*bounds.Dx()/cols, bounds.Min.Y + y*bounds.Dy()/(rows*2)
+	}
+
+	lines := make([]string, 0, rows)
+	for row := range rows {
+		var line strings.Builder
+		for col := range cols {
+			tx, ty := sample(col, row*2)
+			bx, by := sample(col, row*2+1)
+			line.WriteString(lipgloss.NewStyle().
+				Foreground(img.At(tx, ty)).
+				Background(img.At(bx, by)).
+				Render("▀"))
+		}
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n"), true
+}
@@ -31,7 +31,9 @@ func parseMatches(
 	bytePos := 0
 
 	highlights := make([]highlightInfo, 0, len(matches))
-	gr := uniseg.NewGraphemes(ansi.Strip(content))
+	// matches are byte ranges of the content without its styles
+	content = ansi.Strip(content)
+	gr := uniseg.NewGraphemes(content)
 
 	for _, match := range matches {
 		byteStart, byteEnd := match[0], match[1]
@@ -129,6 +129,11 @@ type Model struct {
 	// The argument is the line index.
 	StyleLineFunc func(int) lipgloss.Style
 
+	// RenderLineFunc allows to replace each line as it comes into view, so
+	// costly rendering like syntax highlighting is only done for the lines
+	// shown. It gets the line index and content and must keep the width.
+	RenderLineFunc func(int, string) string
+
 	highlights []highlightInfo
 	hiIdx      int
 }
@@ -357,6 +362,7 @@ func (m Model) visibleLines() (lines []string) {
 		bottom := clamp(pos+maxHeight, top, len(m.lines))
 		lines = make([]string, bottom-top)
 		copy(lines, m.lines[top:bottom])
+		lines = m.renderLines(lines, top)
 		lines = m.styleLines(lines, top)
 		lines = m.highlightLines(lines, top)
 	}
@@ -384,6 +390,17 @@ func (m Model) visibleLines() (lines []string) {
 	return m.setupGutter(lines)
 }
 
+// renderLines replaces the lines using [Model.RenderLineFunc].
+func (m Model) renderLines(lines []string, offset int) []string {
+	if m.RenderLineFunc == nil {
+		return lines
+	}
+	for i := range lines {
+		lines[i] = m.RenderLineFunc(i+offset, lines[i])
+	}
+	return lines
+}
+
 // styleLines styles the lines using [Model.StyleLineFunc].
 func (m Model) styleLines(lines []string, offset int) []string {
 	if m.StyleLineFunc == nil {
@@ -654,6 +671,27 @@ func (m *Model) SetHighlights(matches [][]int) {
 	m.memo.Invalidate()
 }
 
+// HighlightIndex returns the index of the focused highlight, or -1 when
+// there is none.
+func (m Model) HighlightIndex() int {
+	return m.hiIdx
+}
+
+// HighlightPosition returns the line and column the focused highlight starts
+// at, and false when there is none.
+func (m Model) HighlightPosition() (int, int, bool) {
+	if m.hiIdx < 0 || m.hiIdx >= len(m.highlights) {
+		return 0, 0, false
+	}
+	line, col, _ := m.highlights[m.hiIdx].coords()
+	return line, col, true
+}
+
+// HighlightCount returns the number of highlights set.
+func (m Model) HighlightCount() int {
+	return len(m.highlights)
+}
+
 // ClearHighlights clears previously set highlights.
 func (m *Model) ClearHighlights() {
 	m.highlights = nil